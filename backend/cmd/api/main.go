@@ -6,11 +6,13 @@ import (
 	"app_padrao/internal/api/route"
 	"app_padrao/internal/cache"
 	"app_padrao/internal/config"
+	"app_padrao/internal/diagnostics"
 	"app_padrao/internal/health"
 	"app_padrao/internal/metrics"
 	"app_padrao/internal/repository"
 	"app_padrao/internal/service"
 	"app_padrao/pkg/database"
+	"app_padrao/pkg/password"
 	"app_padrao/pkg/resilience"
 	"context"
 	"fmt"
@@ -47,13 +49,45 @@ func main() {
 	// Inicializar repositórios PLC PostgreSQL
 	plcRepo := repository.NewPLCRepository(db)
 	plcTagRepo := repository.NewPLCTagRepository(db)
+	plcTagHistoryRepo := repository.NewPLCTagHistoryRepository(db)
+	tagAlarmRepo := repository.NewTagAlarmRepository(db)
+	tagAnnotationRepo := repository.NewTagAnnotationRepository(db)
+	tagDependencyRepo := repository.NewTagDependencyRepository(db)
+	tagAccessLogRepo := repository.NewTagAccessLogRepository(db)
+	plcSiteRepo := repository.NewPLCSiteRepository(db)
+	plcScanGroupRepo := repository.NewPLCScanGroupRepository(db)
+
+	// Limitar a duração das consultas de todos os repositórios
+	for _, repo := range []interface{ SetQueryTimeout(time.Duration) }{
+		userRepo, roleRepo, profileRepo, themeRepo, plcRepo, plcTagRepo, plcTagHistoryRepo, tagAlarmRepo,
+		tagAnnotationRepo, tagDependencyRepo, tagAccessLogRepo, plcSiteRepo, plcScanGroupRepo,
+	} {
+		repo.SetQueryTimeout(cfg.DB.QueryTimeout)
+	}
+
+	// Avisar se o pool de conexões é pequeno para a quantidade de PLCs ativos
+	if activePLCs, err := plcRepo.GetActivePLCs(); err == nil {
+		required := cfg.DB.MaxConnsPerPLC * len(activePLCs)
+		if cfg.DB.MaxOpenConns > 0 && cfg.DB.MaxOpenConns < required {
+			log.Printf("Aviso: DB_MAX_OPEN_CONNS (%d) menor que o recomendado para %d PLCs ativos (%d conexões)",
+				cfg.DB.MaxOpenConns, len(activePLCs), required)
+		}
+	}
 
 	// Inicializar cache Redis com valores da configuração
 	redisAddr := fmt.Sprintf("%s:6379", cfg.DB.Host) // Usando mesmo host que o DB, ajuste se necessário
-	redisCache, err := cache.NewRedisCache(
+	redisCache, err := cache.NewRedisCacheWithConfig(
 		redisAddr,
 		"", // sem senha
 		0,  // banco de dados Redis 0
+		cache.RedisConfig{
+			KeyPrefix:       cfg.Redis.KeyPrefix + "plc:",
+			Namespace:       cfg.Redis.KeyPrefix,
+			DefaultTTL:      24 * time.Hour,
+			ConnRetryCount:  3,
+			ConnRetryDelay:  2 * time.Second,
+			MaxPipelineSize: cfg.Redis.PipelineBatchSize,
+		},
 	)
 	if err != nil {
 		log.Fatalf("Falha ao conectar ao Redis: %v", err)
@@ -67,24 +101,126 @@ func main() {
 		log.Println("Verificação de saúde do Redis concluída com sucesso")
 	}
 
+	// Diagnóstico de inicialização: falhas críticas impedem a aplicação de subir
+	results, err := diagnostics.RunStartupCheck(cfg, db, redisCache)
+	fmt.Println("Diagnóstico de inicialização:")
+	diagnostics.PrintResults(os.Stdout, results)
+	for _, r := range results {
+		if r.Status != diagnostics.StatusOK && !r.Failed() {
+			log.Printf("AVISO: diagnóstico %q: %s", r.Name, r.Details)
+		}
+	}
+	if err != nil {
+		log.Printf("Inicialização abortada: %v", err)
+		os.Exit(1)
+	}
+
 	// Inicializar componentes de observabilidade e resiliência
 	metricsCollector := metrics.NewMetricsCollector()
+	metricsCollector.HistogramMaxSamples = cfg.Diagnostics.MetricsHistogramMaxSamples
+	// plc.read.latency_ms continua com amostras brutas: o dashboard usa as
+	// últimas amostras como sparkline
+	metricsCollector.RegisterHistogram("redis.pipeline.latency_ms", metrics.DefaultLatencyBucketsMs)
+	redisCache.SetMetricsCollector(metricsCollector)
 	healthChecker := health.NewHealthCheck()
+	healthChecker.SetKeyPrefix(cfg.Redis.KeyPrefix)
 
 	// Verificar saúde inicial dos componentes
 	healthChecker.CheckPostgres(db)
 	healthChecker.CheckRedis(redisCache.GetRedisClient())
+	healthChecker.CheckPostgresLatency(db, cfg.Health.DBLatencyThreshold)
+	healthChecker.CheckRedisLatency(redisCache.GetRedisClient(), cfg.Health.RedisLatencyThreshold)
 
-	// Configurar rate limiter para operações de PLC (limita 100 operações por segundo por PLC)
-	rateLimiter := resilience.NewRateLimiter(100, time.Second)
+	// Configurar rate limiter para operações de PLC (limita 100 operações por segundo por PLC,
+	// somando todas as instâncias)
+	rateLimiter := resilience.NewRedisRateLimiter(redisCache.GetRedisClient(), cfg.Redis.KeyPrefix+"ratelimit:plc", 100, time.Second)
 
 	// Registrar componentes no contexto global da aplicação
 	app := &route.Application{
 		MetricsCollector: metricsCollector,
 		HealthChecker:    healthChecker,
 		RateLimiter:      rateLimiter, // Adicionar o rate limiter à aplicação
+		DBAccessLimiter:  resilience.NewRedisRateLimiter(redisCache.GetRedisClient(), cfg.Redis.KeyPrefix+"ratelimit:plc-db", 10, time.Minute),
+		Cache:            redisCache,
+		ETagKeyPrefix:    cfg.Redis.KeyPrefix,
+
+		// Sondas no estilo Kubernetes (StartupComplete é definido com o serviço PLC)
+		DB:              db,
+		ProbePathPrefix: cfg.Health.ProbePathPrefix,
+	}
+
+	// Inicializar serviços
+	mailer := service.NewLogMailer()
+	userService := service.NewUserService(userRepo, cfg.JWT.SecretKey, cfg.JWT.ExpirationHours)
+	userService.SetMailer(mailer, cfg.Server.PublicURL)
+	userService.SetPasswordPolicy(password.PasswordPolicy{
+		MinLength:      cfg.Security.PasswordMinLength,
+		RequireUpper:   cfg.Security.PasswordRequireUpper,
+		RequireLower:   cfg.Security.PasswordRequireLower,
+		RequireDigit:   cfg.Security.PasswordRequireDigit,
+		RequireSpecial: cfg.Security.PasswordRequireSpecial,
+	}, cfg.Security.BcryptCost)
+	userService.SetAllowedEmailDomains(cfg.Security.AllowedEmailDomains, redisCache.GetRedisClient(), cfg.Redis.KeyPrefix)
+	userService.SetJWTSecretRotation(cfg.JWT.OldSecretKey, time.Duration(cfg.JWT.RotationGracePeriodHours)*time.Hour,
+		redisCache.GetRedisClient(), cfg.Redis.KeyPrefix)
+	app.TokenValidator = userService.ValidateToken
+	roleService := service.NewRoleService(roleRepo)
+	profileService := service.NewProfileService(profileRepo)
+	profileService.SetNotificationChannels(cfg.Profile.NotificationChannels)
+	profileService.SetThemeNotifier(redisCache.GetRedisClient(), cfg.Redis.KeyPrefix)
+	profileService.SetDashboardTagRepositories(plcTagRepo, plcRepo)
+	themeService := service.NewThemeService(themeRepo)
+
+	// Inicializar serviço PLC com arquitetura Redis
+	envPLC := config.LoadPLCConfig() // variáveis PLC_*, lidas uma única vez
+	plcConfig := service.DefaultPLCConfig()
+	plcConfig.RedisKeyPrefix = cfg.Redis.KeyPrefix
+	plcConfig.MaxTagsPerPLC = envPLC.MaxTagsPerPLC
+	plcConfig.MaxStreamClients = envPLC.MaxStreamClients
+	plcConfig.ConsecutiveErrorThreshold = envPLC.ConsecutiveErrorThreshold
+	plcConfig.SyncInterval = time.Duration(envPLC.SyncInterval) * time.Minute
+	plcConfig.AutoAdaptScanRates = envPLC.AutoAdaptScanRates
+	plcConfig.HistoryRetentionDays = envPLC.RetentionDays
+	plcConfig.StartupStaggerMs = envPLC.StartupStaggerMs
+	plcConfig.PingIntervalSec = envPLC.PingIntervalSec
+	plcConfig.MaxConcurrentConnections = envPLC.MaxConcurrentConnections
+	plcConfig.EnableAccessLog = envPLC.EnableAccessLog
+	plcConfig.MetadataCacheSize = envPLC.MetadataCacheSize
+	plcConfig.MetadataCacheTTLSec = envPLC.MetadataCacheTTLSec
+	plcConfig.TagNamePattern = envPLC.TagNamePattern
+	plcConfig.TagNameDescription = envPLC.TagNameDescription
+	plcConfig.EnableDBPoke = envPLC.EnableDBPoke
+	if err := service.ValidateTagNamePattern(plcConfig.TagNamePattern); err != nil {
+		log.Fatalf("PLC_TAG_NAME_PATTERN: %v", err)
+	}
+	plcService := service.NewPLCServiceWithConfig(plcRepo, plcTagRepo, redisCache, plcConfig)
+	app.StartupComplete = plcService.StartupComplete
+	plcService.SetHistoryRepository(plcTagHistoryRepo)
+	plcService.SetMetricsCollector(metricsCollector)
+	plcService.SetAlarmRepository(tagAlarmRepo)
+	plcService.SetSiteRepository(plcSiteRepo)
+	plcService.SetScanGroupRepository(plcScanGroupRepo)
+	plcService.SetTagAnnotationRepository(tagAnnotationRepo, userRepo)
+	plcService.SetTagDependencyRepository(tagDependencyRepo)
+	plcService.SetTagAccessLogRepository(tagAccessLogRepo)
+
+	// Intertravamentos de escrita
+	validatorsFile := envPLC.ValidatorsFile
+	validators, err := service.NewValidatorLoader(validatorsFile).Load()
+	if err != nil {
+		log.Fatalf("Erro ao carregar validadores de escrita: %v", err)
+	}
+	for _, v := range validators {
+		plcService.AddWriteValidator(v)
+	}
+	if len(validators) > 0 {
+		log.Printf("%d validadores de escrita carregados de %s", len(validators), validatorsFile)
 	}
 
+	// Escalonar alarmes não reconhecidos a cada minuto
+	alarmWorker := service.NewAlarmEscalationWorker(tagAlarmRepo, mailer, cfg.Alarm.EscalationEmail)
+	alarmWorker.Start()
+
 	// Iniciar verificação periódica de saúde
 	go func() {
 		ticker := time.NewTicker(30 * time.Second)
@@ -95,6 +231,9 @@ func main() {
 			case <-ticker.C:
 				healthChecker.CheckPostgres(db)
 				healthChecker.CheckRedis(redisCache.GetRedisClient())
+				healthChecker.CheckPostgresLatency(db, cfg.Health.DBLatencyThreshold)
+				healthChecker.CheckRedisLatency(redisCache.GetRedisClient(), cfg.Health.RedisLatencyThreshold)
+				healthChecker.CheckPLCConnections(plcService.GetManager())
 
 				// Registrar métricas de saúde
 				status := healthChecker.GetOverallStatus()
@@ -109,15 +248,6 @@ func main() {
 		}
 	}()
 
-	// Inicializar serviços
-	userService := service.NewUserService(userRepo, cfg.JWT.SecretKey, cfg.JWT.ExpirationHours)
-	roleService := service.NewRoleService(roleRepo)
-	profileService := service.NewProfileService(profileRepo)
-	themeService := service.NewThemeService(themeRepo)
-
-	// Inicializar serviço PLC com arquitetura Redis
-	plcService := service.NewPLCService(plcRepo, plcTagRepo, redisCache)
-
 	// Inicializar handlers
 	authHandler := handler.NewAuthHandler(userService)
 	userHandler := handler.NewUserHandler(userService)
@@ -127,6 +257,7 @@ func main() {
 
 	// Inicializar handler PLC
 	plcHandler := handler.NewPLCHandler(plcService)
+	systemHandler := handler.NewSystemHandler(plcService, metricsCollector, db, cfg.Diagnostics.MaxGoroutineLeakThreshold)
 
 	// Inicializar servidor
 	server := api.NewServer(
@@ -137,10 +268,26 @@ func main() {
 		permissionHandler,
 		profileHandler,
 		plcHandler,
+		systemHandler,
 		userRepo,
 		app, // Passar a referência para Application
 	)
 
+	// Configurar graceful shutdown
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+
+	// O servidor sobe antes do monitoramento para que as sondas respondam
+	// durante a sincronização inicial (/health/startup retorna 503 até lá)
+	go func() {
+		if err := server.Run(); err != nil {
+			log.Fatalf("Erro ao iniciar servidor: %v", err)
+		}
+	}()
+
+	log.Println("Servidor iniciado")
+	metricsCollector.IncrementCounter("server.starts", 1)
+
 	// Iniciar monitoramento de PLCs
 	log.Println("Iniciando monitoramento de PLCs...")
 	if err := plcService.StartMonitoring(); err != nil {
@@ -166,25 +313,15 @@ func main() {
 		plcService.StartDebugMonitor()
 	}
 
-	// Configurar graceful shutdown
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-
-	go func() {
-		if err := server.Run(); err != nil {
-			log.Fatalf("Erro ao iniciar servidor: %v", err)
-		}
-	}()
-
-	log.Println("Servidor iniciado")
-	metricsCollector.IncrementCounter("server.starts", 1)
-
 	// Aguardar sinal para desligar
 	<-quit
 	log.Println("Desligando servidor...")
 
+	alarmWorker.Stop()
+
 	// Parar monitoramento de PLCs antes de encerrar
 	log.Println("Parando monitoramento de PLCs...")
+	plcService.StopConfigWatch()
 	if err := plcService.StopMonitoring(); err != nil {
 		log.Printf("Erro ao parar monitoramento de PLCs: %v", err)
 		metricsCollector.IncrementCounter("plc.monitoring.stop_failures", 1)
@@ -201,6 +338,9 @@ func main() {
 		log.Fatalf("Erro ao desligar servidor: %v", err)
 	}
 
+	// Gravar os acessos às tags ainda na fila
+	plcService.StopAccessLog()
+
 	log.Println("Servidor encerrado com sucesso")
 	metricsCollector.IncrementCounter("server.graceful_shutdowns", 1)
 }
@@ -0,0 +1,105 @@
+// Command errcodes gera docs/error-codes.md a partir das constantes ErrCode*
+// de internal/domain/apierror.go. Executado por go generate ./internal/domain.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// errorCode é uma constante ErrCode* e o comentário que a documenta
+type errorCode struct {
+	Name        string
+	Code        string
+	Description string
+}
+
+func main() {
+	in := flag.String("in", "apierror.go", "arquivo com as constantes ErrCode*")
+	out := flag.String("out", "error-codes.md", "arquivo markdown gerado")
+	flag.Parse()
+
+	codes, err := parseErrorCodes(*in)
+	if err != nil {
+		log.Fatalf("Erro ao ler códigos de erro: %v", err)
+	}
+
+	if err := os.WriteFile(*out, renderMarkdown(codes), 0644); err != nil {
+		log.Fatalf("Erro ao gravar %s: %v", *out, err)
+	}
+}
+
+// parseErrorCodes lê as constantes string com prefixo ErrCode, na ordem do arquivo
+func parseErrorCodes(path string) ([]errorCode, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	var codes []errorCode
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.CONST {
+			continue
+		}
+
+		for _, spec := range gen.Specs {
+			value := spec.(*ast.ValueSpec)
+			for i, name := range value.Names {
+				if !strings.HasPrefix(name.Name, "ErrCode") || i >= len(value.Values) {
+					continue
+				}
+
+				lit, ok := value.Values[i].(*ast.BasicLit)
+				if !ok || lit.Kind != token.STRING {
+					return nil, fmt.Errorf("%s deve ser uma string literal", name.Name)
+				}
+				code, err := strconv.Unquote(lit.Value)
+				if err != nil {
+					return nil, err
+				}
+
+				description := strings.TrimSpace(value.Doc.Text())
+				if description == "" {
+					return nil, fmt.Errorf("%s não possui comentário de documentação", name.Name)
+				}
+
+				codes = append(codes, errorCode{
+					Name:        name.Name,
+					Code:        code,
+					Description: strings.Join(strings.Fields(description), " "),
+				})
+			}
+		}
+	}
+
+	return codes, nil
+}
+
+// renderMarkdown gera a tabela de códigos de erro
+func renderMarkdown(codes []errorCode) []byte {
+	var buf bytes.Buffer
+
+	buf.WriteString("<!-- Code generated by cmd/errcodes; DO NOT EDIT. -->\n\n")
+	buf.WriteString("# Códigos de erro da API\n\n")
+	buf.WriteString("Toda resposta de erro tem o formato:\n\n")
+	buf.WriteString("```json\n{\"code\": \"PLC_NOT_FOUND\", \"message\": \"...\", \"details\": {}}\n```\n\n")
+	buf.WriteString("`code` é estável entre versões e deve ser usado pelos clientes; `message` é\n")
+	buf.WriteString("apenas informativa. `details` é opcional.\n\n")
+	buf.WriteString("| Código | Constante | Descrição |\n")
+	buf.WriteString("|--------|-----------|-----------|\n")
+	for _, c := range codes {
+		fmt.Fprintf(&buf, "| `%s` | `domain.%s` | %s |\n", c.Code, c.Name, c.Description)
+	}
+
+	return buf.Bytes()
+}
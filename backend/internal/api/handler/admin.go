@@ -3,6 +3,7 @@ package handler
 
 import (
 	"app_padrao/internal/domain"
+	"errors"
 	"log"
 	"net/http"
 	"strconv"
@@ -31,12 +32,12 @@ func (h *AdminHandler) ListUsers(c *gin.Context) {
 	users, total, err := h.userService.List(page, pageSize)
 	if err != nil {
 		log.Printf("Erro ao listar usuários: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		ErrorResponse(c, errorStatus(err), errorCode(err, errorStatus(err)), err.Error(), nil)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"users":    users,
+		"users":    domain.NewUserResponses(users),
 		"total":    total,
 		"page":     page,
 		"pageSize": pageSize,
@@ -48,22 +49,22 @@ func (h *AdminHandler) GetUser(c *gin.Context) {
 
 	user, err := h.userService.GetByID(id)
 	if err != nil {
-		statusCode := http.StatusInternalServerError
+		statusCode := errorStatus(err)
 		if err == domain.ErrUserNotFound {
 			statusCode = http.StatusNotFound
 		}
-		c.JSON(statusCode, gin.H{"error": err.Error()})
+		ErrorResponse(c, statusCode, errorCode(err, statusCode), err.Error(), nil)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"user": user})
+	c.JSON(http.StatusOK, gin.H{"user": domain.NewUserResponse(user)})
 }
 
 func (h *AdminHandler) CreateUser(c *gin.Context) {
 	var input struct {
 		Username string `json:"username" binding:"required"`
 		Email    string `json:"email" binding:"required,email"`
-		Password string `json:"password" binding:"required,min=6"`
+		Password string `json:"password" binding:"required"`
 		Role     string `json:"role"`
 		IsActive bool   `json:"is_active"`
 		FullName string `json:"full_name"`
@@ -71,7 +72,7 @@ func (h *AdminHandler) CreateUser(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		ErrorResponse(c, http.StatusBadRequest, errorCode(err, http.StatusBadRequest), err.Error(), nil)
 		return
 	}
 
@@ -87,11 +88,18 @@ func (h *AdminHandler) CreateUser(c *gin.Context) {
 
 	id, err := h.userService.Register(user)
 	if err != nil {
-		statusCode := http.StatusInternalServerError
+		if respondWeakPassword(c, err) {
+			return
+		}
+
+		statusCode := errorStatus(err)
 		if err == domain.ErrEmailInUse || err == domain.ErrUsernameInUse {
 			statusCode = http.StatusBadRequest
 		}
-		c.JSON(statusCode, gin.H{"error": err.Error()})
+		if err == domain.ErrEmailDomainNotAllowed {
+			statusCode = http.StatusForbidden
+		}
+		ErrorResponse(c, statusCode, errorCode(err, statusCode), err.Error(), nil)
 		return
 	}
 
@@ -114,7 +122,7 @@ func (h *AdminHandler) UpdateUser(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		ErrorResponse(c, http.StatusBadRequest, errorCode(err, http.StatusBadRequest), err.Error(), nil)
 		return
 	}
 
@@ -130,11 +138,11 @@ func (h *AdminHandler) UpdateUser(c *gin.Context) {
 
 	err := h.userService.Update(user)
 	if err != nil {
-		statusCode := http.StatusInternalServerError
+		statusCode := errorStatus(err)
 		if err == domain.ErrUserNotFound {
 			statusCode = http.StatusNotFound
 		}
-		c.JSON(statusCode, gin.H{"error": err.Error()})
+		ErrorResponse(c, statusCode, errorCode(err, statusCode), err.Error(), nil)
 		return
 	}
 
@@ -146,11 +154,11 @@ func (h *AdminHandler) DeleteUser(c *gin.Context) {
 
 	err := h.userService.Delete(id)
 	if err != nil {
-		statusCode := http.StatusInternalServerError
+		statusCode := errorStatus(err)
 		if err == domain.ErrUserNotFound {
 			statusCode = http.StatusNotFound
 		}
-		c.JSON(statusCode, gin.H{"error": err.Error()})
+		ErrorResponse(c, statusCode, errorCode(err, statusCode), err.Error(), nil)
 		return
 	}
 
@@ -160,9 +168,81 @@ func (h *AdminHandler) DeleteUser(c *gin.Context) {
 func (h *AdminHandler) ListRoles(c *gin.Context) {
 	roles, err := h.roleService.GetAll()
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		ErrorResponse(c, errorStatus(err), errorCode(err, errorStatus(err)), err.Error(), nil)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"roles": roles})
 }
+
+// GetEffectivePermissions retorna as permissões da role incluindo as herdadas
+func (h *AdminHandler) GetEffectivePermissions(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		ErrorResponse(c, http.StatusBadRequest, domain.ErrCodeInvalidParameter, "ID inválido", nil)
+		return
+	}
+
+	permissions, err := h.roleService.GetEffectivePermissions(id)
+	if err != nil {
+		statusCode := errorStatus(err)
+		if err == domain.ErrRoleNotFound {
+			statusCode = http.StatusNotFound
+		} else if err == domain.ErrRoleHierarchyCycle {
+			statusCode = http.StatusConflict
+		}
+		ErrorResponse(c, statusCode, errorCode(err, statusCode), err.Error(), nil)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"role_id": id, "permissions": permissions})
+}
+
+// UpdateEmailDomains substitui a lista de domínios de email aceitos no
+// cadastro. Lista vazia libera qualquer domínio.
+func (h *AdminHandler) UpdateEmailDomains(c *gin.Context) {
+	var input struct {
+		Domains []string `json:"domains"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		ErrorResponse(c, http.StatusBadRequest, errorCode(err, http.StatusBadRequest), err.Error(), nil)
+		return
+	}
+
+	userID, _ := c.Get("userID")
+	uid, _ := userID.(int)
+
+	domains, err := h.userService.UpdateAllowedEmailDomains(input.Domains, uid)
+	if err != nil {
+		ErrorResponse(c, errorStatus(err), errorCode(err, errorStatus(err)), err.Error(), nil)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"domains": domains,
+		"message": "Domínios de email atualizados com sucesso",
+	})
+}
+
+// RotateJWTSecret gera um novo segredo de assinatura dos tokens. Os tokens
+// assinados com o segredo anterior continuam aceitos durante a carência.
+func (h *AdminHandler) RotateJWTSecret(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	uid, _ := userID.(int)
+
+	rotation, err := h.userService.RotateJWTSecret(uid)
+	if err != nil {
+		statusCode := errorStatus(err)
+		if errors.Is(err, domain.ErrJWTRotationNotConfigured) {
+			statusCode = http.StatusServiceUnavailable
+		}
+		ErrorResponse(c, statusCode, errorCode(err, statusCode), err.Error(), nil)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"rotation": rotation,
+		"message":  "Segredo JWT rotacionado com sucesso",
+	})
+}
@@ -3,6 +3,8 @@ package handler
 
 import (
 	"app_padrao/internal/domain"
+	"app_padrao/pkg/password"
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -21,7 +23,7 @@ func NewAuthHandler(userService domain.UserService) *AuthHandler {
 type registerRequest struct {
 	Username string `json:"username" binding:"required"`
 	Email    string `json:"email" binding:"required,email"`
-	Password string `json:"password" binding:"required,min=6"`
+	Password string `json:"password" binding:"required"`
 	Role     string `json:"role"`
 	FullName string `json:"full_name"`
 	Phone    string `json:"phone"`
@@ -33,20 +35,10 @@ type loginRequest struct {
 	Password string `json:"password" binding:"required"`
 }
 
-type userResponse struct {
-	ID       int    `json:"id"`
-	Username string `json:"username"`
-	Email    string `json:"email"`
-	Role     string `json:"role"`
-	IsActive bool   `json:"is_active"`
-	FullName string `json:"full_name"`
-	Phone    string `json:"phone"`
-}
-
 func (h *AuthHandler) Register(c *gin.Context) {
 	var req registerRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		ErrorResponse(c, http.StatusBadRequest, errorCode(err, http.StatusBadRequest), err.Error(), nil)
 		return
 	}
 
@@ -62,13 +54,20 @@ func (h *AuthHandler) Register(c *gin.Context) {
 
 	id, err := h.userService.Register(user)
 	if err != nil {
-		statusCode := http.StatusInternalServerError
+		if respondWeakPassword(c, err) {
+			return
+		}
+
+		statusCode := errorStatus(err)
 
 		if err == domain.ErrEmailInUse || err == domain.ErrUsernameInUse {
 			statusCode = http.StatusBadRequest
 		}
+		if err == domain.ErrEmailDomainNotAllowed {
+			statusCode = http.StatusForbidden
+		}
 
-		c.JSON(statusCode, gin.H{"error": err.Error()})
+		ErrorResponse(c, statusCode, errorCode(err, statusCode), err.Error(), nil)
 		return
 	}
 
@@ -81,32 +80,61 @@ func (h *AuthHandler) Register(c *gin.Context) {
 func (h *AuthHandler) Login(c *gin.Context) {
 	var req loginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		ErrorResponse(c, http.StatusBadRequest, errorCode(err, http.StatusBadRequest), err.Error(), nil)
 		return
 	}
 
 	token, user, err := h.userService.Login(req.Email, req.Password)
 	if err != nil {
-		statusCode := http.StatusInternalServerError
+		statusCode := errorStatus(err)
 
 		if err == domain.ErrInvalidCredentials {
 			statusCode = http.StatusUnauthorized
 		}
 
-		c.JSON(statusCode, gin.H{"error": err.Error()})
+		ErrorResponse(c, statusCode, errorCode(err, statusCode), err.Error(), nil)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"token": token,
-		"user": userResponse{
-			ID:       user.ID,
-			Username: user.Username,
-			Email:    user.Email,
-			Role:     user.Role,
-			IsActive: user.IsActive,
-			FullName: user.FullName,
-			Phone:    user.Phone,
-		},
+		"user":  domain.NewUserResponse(user),
 	})
 }
+
+// VerifyEmail confirma o email do usuário a partir do token enviado no cadastro
+func (h *AuthHandler) VerifyEmail(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		ErrorResponse(c, http.StatusBadRequest, domain.ErrCodeInvalidPayload, "Token não fornecido", nil)
+		return
+	}
+
+	err := h.userService.VerifyEmail(token)
+	if err != nil {
+		statusCode := errorStatus(err)
+
+		if err == domain.ErrInvalidVerifyToken {
+			statusCode = http.StatusBadRequest
+		} else if err == domain.ErrUserNotFound {
+			statusCode = http.StatusNotFound
+		}
+
+		ErrorResponse(c, statusCode, errorCode(err, statusCode), err.Error(), nil)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Email verificado com sucesso"})
+}
+
+// respondWeakPassword responde 400 com as regras violadas quando o erro é de senha fraca
+func respondWeakPassword(c *gin.Context, err error) bool {
+	var weakErr *password.WeakPasswordError
+	if !errors.As(err, &weakErr) {
+		return false
+	}
+
+	ErrorResponse(c, http.StatusBadRequest, domain.ErrCodeWeakPassword, "weak password",
+		gin.H{"violations": weakErr.Violations})
+	return true
+}
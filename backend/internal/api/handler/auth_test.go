@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"app_padrao/internal/domain"
+	"app_padrao/internal/service"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// fakeUserRepo tem um único usuário cadastrado e falha se Create for chamado
+type fakeUserRepo struct {
+	domain.UserRepository
+	existing domain.User
+	t        *testing.T
+}
+
+func (r *fakeUserRepo) GetByEmail(email string) (domain.User, error) {
+	if email == r.existing.Email {
+		return r.existing, nil
+	}
+	return domain.User{}, domain.ErrUserNotFound
+}
+
+func (r *fakeUserRepo) GetByUsername(username string) (domain.User, error) {
+	if username == r.existing.Username {
+		return r.existing, nil
+	}
+	return domain.User{}, domain.ErrUserNotFound
+}
+
+func (r *fakeUserRepo) Create(user domain.User) (int, error) {
+	r.t.Errorf("Create não deveria ser chamado para %q", user.Username)
+	return 0, nil
+}
+
+func TestRegisterDuplicateUsernameReturns400(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	repo := &fakeUserRepo{existing: domain.User{ID: 1, Username: "maria", Email: "maria@empresa.com"}, t: t}
+	userService := service.NewUserService(repo, "segredo", 1)
+
+	router := gin.New()
+	router.POST("/api/auth/register", NewAuthHandler(userService).Register)
+	router.POST("/api/admin/users", NewAdminHandler(userService, nil).CreateUser)
+
+	body := `{"username":"maria","email":"nova@empresa.com","password":"Senha123"}`
+	for _, path := range []string{"/api/auth/register", "/api/admin/users"} {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, esperado 400", path, w.Code)
+			continue
+		}
+
+		var resp domain.APIError
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("%s: resposta inválida: %v", path, err)
+		}
+		if resp.Code != domain.ErrCodeAlreadyExists || resp.Message != domain.ErrUsernameInUse.Error() {
+			t.Errorf("%s: erro = %+v, esperado %s / %q", path, resp, domain.ErrCodeAlreadyExists, domain.ErrUsernameInUse)
+		}
+	}
+}
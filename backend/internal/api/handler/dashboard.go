@@ -0,0 +1,209 @@
+// internal/api/handler/dashboard.go
+package handler
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"html/template"
+	"log"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+//go:embed templates/dashboard.html
+var dashboardFS embed.FS
+
+var dashboardTemplate = template.Must(template.ParseFS(dashboardFS, "templates/dashboard.html"))
+
+const (
+	// dashboardLatencyMetric é o histograma exibido como sparkline
+	dashboardLatencyMetric = "plc.read.latency_ms"
+	// dashboardLatencySamples é quantas amostras do histograma entram na sparkline
+	dashboardLatencySamples = 20
+	// dashboardTagsPerPLC é quantos valores (os mais recentes) são listados por PLC
+	dashboardTagsPerPLC = 10
+)
+
+// sparklineLevels vai do menor ao maior valor da série
+const sparklineLevels = "_.,-~=+*#@"
+
+type dashboardMetric struct {
+	Name  string
+	Value interface{}
+}
+
+type dashboardLatency struct {
+	Sparkline string
+	Samples   int
+	Min       float64
+	Max       float64
+	Last      float64
+}
+
+type dashboardConnection struct {
+	PLCID         int
+	Name          string
+	Status        string
+	TagCount      int
+	ReadErrors    int64
+	WriteErrors   int64
+	LastConnected time.Time
+}
+
+type dashboardTagValue struct {
+	Tag       string
+	Value     string
+	Quality   string
+	Timestamp time.Time
+}
+
+type dashboardPLC struct {
+	ID     int
+	Name   string
+	Values []dashboardTagValue
+}
+
+type dashboardData struct {
+	GeneratedAt time.Time
+	Uptime      string
+	Latency     dashboardLatency
+	Connections []dashboardConnection
+	PLCs        []dashboardPLC
+	Counters    []dashboardMetric
+	Gauges      []dashboardMetric
+}
+
+// Dashboard renderiza uma página HTML com métricas, conexões e últimos
+// valores das tags, para diagnóstico rápido sem Prometheus/Grafana
+func (h *SystemHandler) Dashboard(c *gin.Context) {
+	data := dashboardData{GeneratedAt: time.Now()}
+
+	if h.metrics != nil {
+		all := h.metrics.GetAllMetrics()
+		if uptime, ok := all["uptime_seconds"].(float64); ok {
+			data.Uptime = (time.Duration(uptime) * time.Second).String()
+		}
+		if counters, ok := all["counters"].(map[string]int64); ok {
+			for name, value := range counters {
+				data.Counters = append(data.Counters, dashboardMetric{Name: name, Value: value})
+			}
+		}
+		if gauges, ok := all["gauges"].(map[string]float64); ok {
+			for name, value := range gauges {
+				data.Gauges = append(data.Gauges, dashboardMetric{Name: name, Value: value})
+			}
+		}
+		sort.Slice(data.Counters, func(i, j int) bool { return data.Counters[i].Name < data.Counters[j].Name })
+		sort.Slice(data.Gauges, func(i, j int) bool { return data.Gauges[i].Name < data.Gauges[j].Name })
+
+		data.Latency = buildDashboardLatency(h.metrics.GetHistogramSamples(dashboardLatencyMetric, dashboardLatencySamples))
+	}
+
+	for _, conn := range h.plcService.GetPLCStats().ConnectionStats {
+		data.Connections = append(data.Connections, dashboardConnection{
+			PLCID:         conn.PLCID,
+			Name:          conn.Name,
+			Status:        conn.Status,
+			TagCount:      conn.TagCount,
+			ReadErrors:    conn.ReadErrors,
+			WriteErrors:   conn.WriteErrors,
+			LastConnected: conn.LastConnected,
+		})
+	}
+	sort.Slice(data.Connections, func(i, j int) bool { return data.Connections[i].PLCID < data.Connections[j].PLCID })
+
+	plcs, err := h.plcService.GetAll()
+	if err != nil {
+		log.Printf("Dashboard: erro ao buscar PLCs: %v", err)
+	}
+	for _, plc := range plcs {
+		data.PLCs = append(data.PLCs, dashboardPLC{
+			ID:     plc.ID,
+			Name:   plc.Name,
+			Values: h.latestTagValues(plc.ID),
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := dashboardTemplate.Execute(&buf, data); err != nil {
+		log.Printf("Dashboard: erro ao renderizar template: %v", err)
+		c.String(http.StatusInternalServerError, "Erro ao renderizar dashboard")
+		return
+	}
+
+	c.Data(http.StatusOK, "text/html; charset=utf-8", buf.Bytes())
+}
+
+// latestTagValues retorna os valores mais recentes do cache para as tags do PLC
+func (h *SystemHandler) latestTagValues(plcID int) []dashboardTagValue {
+	tags, err := h.plcService.GetPLCTags(plcID)
+	if err != nil {
+		log.Printf("Dashboard: erro ao buscar tags do PLC %d: %v", plcID, err)
+		return nil
+	}
+
+	values := []dashboardTagValue{}
+	for _, tag := range tags {
+		value, err := h.plcService.GetTagValue(plcID, tag.ID)
+		if err != nil || value == nil {
+			continue
+		}
+		values = append(values, dashboardTagValue{
+			Tag:       tag.Name,
+			Value:     fmt.Sprintf("%v", value.Value),
+			Quality:   value.Quality,
+			Timestamp: value.Timestamp,
+		})
+	}
+
+	sort.Slice(values, func(i, j int) bool { return values[i].Timestamp.After(values[j].Timestamp) })
+	if len(values) > dashboardTagsPerPLC {
+		values = values[:dashboardTagsPerPLC]
+	}
+
+	return values
+}
+
+// buildDashboardLatency resume as amostras de latência e desenha a sparkline
+func buildDashboardLatency(samples []float64) dashboardLatency {
+	if len(samples) == 0 {
+		return dashboardLatency{}
+	}
+
+	minValue, maxValue := math.Inf(1), math.Inf(-1)
+	for _, v := range samples {
+		minValue = math.Min(minValue, v)
+		maxValue = math.Max(maxValue, v)
+	}
+
+	return dashboardLatency{
+		Sparkline: asciiSparkline(samples, minValue, maxValue),
+		Samples:   len(samples),
+		Min:       minValue,
+		Max:       maxValue,
+		Last:      samples[len(samples)-1],
+	}
+}
+
+// asciiSparkline converte cada amostra em um caractere de sparklineLevels
+// proporcional à sua posição entre o mínimo e o máximo
+func asciiSparkline(samples []float64, minValue, maxValue float64) string {
+	var sb strings.Builder
+	top := len(sparklineLevels) - 1
+
+	for _, v := range samples {
+		level := top / 2
+		if maxValue > minValue {
+			level = int(math.Round((v - minValue) / (maxValue - minValue) * float64(top)))
+		}
+		sb.WriteByte(sparklineLevels[level])
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"app_padrao/internal/domain"
+	"app_padrao/internal/service"
+	"app_padrao/pkg/database"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// errorStatus retorna o status HTTP para um erro sem tratamento específico:
+// 504 quando a consulta ao banco excedeu o prazo configurado, 503 com o
+// monitoramento parado ou o cache indisponível, 404 para grupo de tags
+// inexistente e 500 nos demais casos
+func errorStatus(err error) int {
+	if database.IsTimeout(err) {
+		return http.StatusGatewayTimeout
+	}
+	if errors.Is(err, domain.ErrMonitorNotStarted) || errors.Is(err, domain.ErrCacheUnavailable) {
+		return http.StatusServiceUnavailable
+	}
+	if errors.Is(err, domain.ErrTagGroupNotFound) {
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
+
+// ErrorResponse responde com o corpo padrão de erro da API (domain.APIError).
+// details é opcional e omitido quando nil.
+func ErrorResponse(c *gin.Context, status int, code, message string, details interface{}) {
+	c.JSON(status, domain.APIError{
+		Code:    code,
+		Message: message,
+		Details: details,
+	})
+}
+
+// errorCodes associa erros conhecidos a códigos específicos, na ordem de
+// verificação
+var errorCodes = []struct {
+	err  error
+	code string
+}{
+	{domain.ErrPLCNotFound, domain.ErrCodePLCNotFound},
+	{domain.ErrPLCTagNotFound, domain.ErrCodeTagNotFound},
+	{service.ErrTagNotFound, domain.ErrCodeTagNotFound},
+	{domain.ErrUserNotFound, domain.ErrCodeUserNotFound},
+	{domain.ErrProfileNotFound, domain.ErrCodeProfileNotFound},
+	{domain.ErrRoleNotFound, domain.ErrCodeRoleNotFound},
+	{domain.ErrAlarmEventNotFound, domain.ErrCodeAlarmNotFound},
+	{domain.ErrPLCSiteNotFound, domain.ErrCodeSiteNotFound},
+	{domain.ErrTagAnnotationForbidden, domain.ErrCodePermissionDenied},
+	{domain.ErrInvalidCredentials, domain.ErrCodeInvalidCredentials},
+	{domain.ErrEmailInUse, domain.ErrCodeAlreadyExists},
+	{domain.ErrUsernameInUse, domain.ErrCodeAlreadyExists},
+	{domain.ErrEmailDomainNotAllowed, domain.ErrCodeEmailDomainNotAllowed},
+	{domain.ErrAddressConflict, domain.ErrCodeAddressConflict},
+	{domain.ErrTagLimitExceeded, domain.ErrCodeTagLimitExceeded},
+	{service.ErrWriteNotPermitted, domain.ErrCodeWriteNotPermitted},
+	{service.ErrWriteRateLimited, domain.ErrCodeRateLimited},
+	{service.ErrWriteBlocked, domain.ErrCodeWriteBlocked},
+}
+
+// errorCode escolhe o código de erro para a resposta: erros conhecidos têm
+// código próprio, os demais usam o código genérico do status HTTP. Respostas
+// 5xx sempre usam o código do status.
+func errorCode(err error, status int) string {
+	if status < http.StatusInternalServerError {
+		for _, known := range errorCodes {
+			if errors.Is(err, known.err) {
+				return known.code
+			}
+		}
+		if isTagValidationError(err) {
+			return domain.ErrCodeTagValidation
+		}
+	}
+	return codeForStatus(status)
+}
+
+// codeForStatus retorna o código genérico de um status HTTP
+func codeForStatus(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return domain.ErrCodeInvalidPayload
+	case http.StatusUnauthorized:
+		return domain.ErrCodeUnauthorized
+	case http.StatusForbidden:
+		return domain.ErrCodePermissionDenied
+	case http.StatusNotFound:
+		return domain.ErrCodeNotFound
+	case http.StatusConflict:
+		return domain.ErrCodeConflict
+	case http.StatusRequestEntityTooLarge:
+		return domain.ErrCodeRequestTooLarge
+	case http.StatusUnprocessableEntity:
+		return domain.ErrCodeUnprocessable
+	case http.StatusTooManyRequests:
+		return domain.ErrCodeRateLimited
+	case http.StatusServiceUnavailable:
+		return domain.ErrCodeServiceUnavailable
+	case http.StatusGatewayTimeout:
+		return domain.ErrCodeTimeout
+	}
+	return domain.ErrCodeInternal
+}
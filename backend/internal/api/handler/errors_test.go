@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"app_padrao/internal/cache"
+	"app_padrao/internal/domain"
+	"app_padrao/internal/service"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestErrorStatusMapsSentinels(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"monitoramento parado", service.ErrMonitoringNotActive, http.StatusServiceUnavailable},
+		{"monitoramento parado envolvido", fmt.Errorf("iniciar depuração: %w", domain.ErrMonitorNotStarted), http.StatusServiceUnavailable},
+		{"Redis desconectado", fmt.Errorf("ler valor: %w", cache.ErrRedisNotConnected), http.StatusServiceUnavailable},
+		{"grupo de tags inexistente", fmt.Errorf("grupo 3: %w", domain.ErrScanGroupNotFound), http.StatusNotFound},
+		{"erro genérico", errors.New("falha"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := errorStatus(tt.err); got != tt.want {
+				t.Errorf("errorStatus(%v) = %d, esperado %d", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestErrorCodeForWrappedPLCNotFound(t *testing.T) {
+	err := fmt.Errorf("PLC com ID 42 não encontrado: %w", domain.ErrPLCNotFound)
+	if got := errorCode(err, http.StatusNotFound); got != domain.ErrCodePLCNotFound {
+		t.Errorf("errorCode = %s, esperado %s", got, domain.ErrCodePLCNotFound)
+	}
+}
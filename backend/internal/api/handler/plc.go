@@ -3,6 +3,8 @@ package handler
 
 import (
 	"app_padrao/internal/domain"
+	"app_padrao/internal/service"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
@@ -10,6 +12,7 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 )
 
 // PLCHandler gerencia requisições relacionadas a PLCs
@@ -28,44 +31,97 @@ func NewPLCHandler(plcService domain.PLCService) *PLCHandler {
 func (h *PLCHandler) validarPLC(c *gin.Context, plc *domain.PLC) bool {
 	// Validar nome
 	if plc.Name == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Nome do PLC é obrigatório"})
+		ErrorResponse(c, http.StatusBadRequest, domain.ErrCodeInvalidPayload, "Nome do PLC é obrigatório", nil)
 		return false
 	}
 
 	// Validar endereço IP
 	if plc.IPAddress == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Endereço IP do PLC é obrigatório"})
+		ErrorResponse(c, http.StatusBadRequest, domain.ErrCodeInvalidPayload, "Endereço IP do PLC é obrigatório", nil)
 		return false
 	}
 
 	// Validar rack e slot
 	if plc.Rack < 0 || plc.Slot < 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Valores de Rack e Slot devem ser não-negativos"})
+		ErrorResponse(c, http.StatusBadRequest, domain.ErrCodeInvalidPayload, "Valores de Rack e Slot devem ser não-negativos", nil)
+		return false
+	}
+
+	// Validar variante do protocolo S7 (slot 0 no S7-1200) e TSAPs
+	if err := plc.ValidateProtocol(); err != nil {
+		ErrorResponse(c, http.StatusBadRequest, domain.ErrCodeInvalidPayload, err.Error(), nil)
 		return false
 	}
 
 	return true
 }
 
+// setProtocolNotes preenche o aviso de PUT/GET dos S7-1200/1500
+func setProtocolNotes(plc *domain.PLC) {
+	if plc.RequiresPutGet() {
+		plc.ProtocolNotes = domain.PutGetProtocolNote
+	}
+}
+
 // GetAllPLCs retorna a lista de todos os PLCs
 func (h *PLCHandler) GetAllPLCs(c *gin.Context) {
-	// Verificar se há filtro de ativos
-	activeOnly := c.Query("active")
-	var plcs []domain.PLC
-	var err error
+	siteID, ok := siteIDQuery(c)
+	if !ok {
+		return
+	}
 
-	if activeOnly == "true" {
-		plcs, err = h.plcService.GetActivePLCs()
-	} else {
-		plcs, err = h.plcService.GetAll()
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", strconv.Itoa(service.DefaultPLCPageSize)))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = service.DefaultPLCPageSize
+	} else if pageSize > service.MaxPLCPageSize {
+		pageSize = service.MaxPLCPageSize
+	}
+
+	filter := domain.PLCFilter{
+		Name:   c.Query("name"),
+		Status: c.Query("status"),
+		SiteID: siteID,
+	}
+
+	if activeStr := c.Query("active"); activeStr != "" {
+		active, err := strconv.ParseBool(activeStr)
+		if err != nil {
+			ErrorResponse(c, http.StatusBadRequest, domain.ErrCodeInvalidParameter, "active deve ser true ou false", nil)
+			return
+		}
+		filter.Active = &active
 	}
 
+	// X-Bypass-Cache: true lê direto do PostgreSQL (apenas administradores)
+	bypassCache := c.GetHeader("X-Bypass-Cache") == "true"
+	userID, _ := c.Get("userID")
+	uid, _ := userID.(int)
+
+	plcs, total, err := h.plcService.ListPLCs(c.Request.Context(), filter, page, pageSize, bypassCache, uid)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Erro ao buscar PLCs: %v", err)})
+		statusCode := errorStatus(err)
+		if errors.Is(err, service.ErrInvalidPLCStatusFilter) {
+			statusCode = http.StatusBadRequest
+		}
+		ErrorResponse(c, statusCode, errorCode(err, statusCode), fmt.Sprintf("Erro ao buscar PLCs: %v", err), nil)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"plcs": plcs})
+	for i := range plcs {
+		setProtocolNotes(&plcs[i])
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"plcs":     domain.NewPLCResponses(plcs),
+		"total":    total,
+		"page":     page,
+		"pageSize": pageSize,
+		"has_next": page*pageSize < total,
+	})
 }
 
 // GetPLC retorna um PLC específico
@@ -79,13 +135,25 @@ func (h *PLCHandler) GetPLC(c *gin.Context) {
 	// Buscar o PLC
 	plc, err := h.plcService.GetByID(id)
 	if err != nil {
-		statusCode := http.StatusInternalServerError
+		statusCode := errorStatus(err)
 
 		if errors.Is(err, domain.ErrPLCNotFound) {
 			statusCode = http.StatusNotFound
 		}
 
-		c.JSON(statusCode, gin.H{"error": err.Error()})
+		ErrorResponse(c, statusCode, errorCode(err, statusCode), err.Error(), nil)
+		return
+	}
+	plc.EffectiveStatus = h.plcService.EffectiveMonitoringStatus(plc)
+	setProtocolNotes(&plc)
+
+	// Em HTTP/2, enviar os valores das tags junto com o PLC (X-Request-Push: tags)
+	h.pushInitialTagValues(c, id)
+
+	// Quantidade de tags e limite do PLC (max_tags 0 = sem limite)
+	tagCount, maxTags, err := h.plcService.GetTagLimitStatus(plc)
+	if err != nil {
+		ErrorResponse(c, errorStatus(err), errorCode(err, errorStatus(err)), fmt.Sprintf("Erro ao contar tags do PLC: %v", err), nil)
 		return
 	}
 
@@ -95,20 +163,28 @@ func (h *PLCHandler) GetPLC(c *gin.Context) {
 		tags, err := h.plcService.GetPLCTags(id)
 		if err != nil {
 			c.JSON(http.StatusOK, gin.H{
-				"plc":        plc,
+				"plc":        domain.NewPLCResponse(plc),
+				"tag_count":  tagCount,
+				"max_tags":   maxTags,
 				"tags_error": err.Error(),
 			})
 			return
 		}
 
 		c.JSON(http.StatusOK, gin.H{
-			"plc":  plc,
-			"tags": tags,
+			"plc":       domain.NewPLCResponse(plc),
+			"tag_count": tagCount,
+			"max_tags":  maxTags,
+			"tags":      domain.NewPLCTagResponses(tags),
 		})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"plc": plc})
+	c.JSON(http.StatusOK, gin.H{
+		"plc":       domain.NewPLCResponse(plc),
+		"tag_count": tagCount,
+		"max_tags":  maxTags,
+	})
 }
 
 // CreatePLC cria um novo PLC
@@ -117,7 +193,7 @@ func (h *PLCHandler) CreatePLC(c *gin.Context) {
 
 	// Fazer binding e validar dados
 	if err := c.ShouldBindJSON(&plc); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Erro ao processar dados: %v", err)})
+		ErrorResponse(c, http.StatusBadRequest, errorCode(err, http.StatusBadRequest), fmt.Sprintf("Erro ao processar dados: %v", err), nil)
 		return
 	}
 
@@ -129,7 +205,8 @@ func (h *PLCHandler) CreatePLC(c *gin.Context) {
 	// Criar o PLC
 	id, err := h.plcService.Create(plc)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Erro ao criar PLC: %v", err)})
+		statusCode := plcSiteRefStatus(err)
+		ErrorResponse(c, statusCode, errorCode(err, statusCode), fmt.Sprintf("Erro ao criar PLC: %v", err), nil)
 		return
 	}
 
@@ -148,25 +225,44 @@ func (h *PLCHandler) UpdatePLC(c *gin.Context) {
 	}
 
 	// Buscar o PLC existente para confirmar que existe
-	_, err = h.plcService.GetByID(id)
+	existing, err := h.plcService.GetByID(id)
 	if err != nil {
-		statusCode := http.StatusInternalServerError
+		statusCode := errorStatus(err)
 
 		if errors.Is(err, domain.ErrPLCNotFound) {
 			statusCode = http.StatusNotFound
 		}
 
-		c.JSON(statusCode, gin.H{"error": fmt.Sprintf("Erro ao buscar PLC: %v", err)})
+		ErrorResponse(c, statusCode, errorCode(err, statusCode), fmt.Sprintf("Erro ao buscar PLC: %v", err), nil)
 		return
 	}
 
 	// Fazer binding dos dados de atualização
 	var plc domain.PLC
-	if err := c.ShouldBindJSON(&plc); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Erro ao processar dados: %v", err)})
+	if err := c.ShouldBindBodyWith(&plc, binding.JSON); err != nil {
+		ErrorResponse(c, http.StatusBadRequest, errorCode(err, http.StatusBadRequest), fmt.Sprintf("Erro ao processar dados: %v", err), nil)
 		return
 	}
 
+	// Sem site_id no corpo, o PLC continua no site atual
+	var fields map[string]json.RawMessage
+	if err := c.ShouldBindBodyWith(&fields, binding.JSON); err == nil {
+		if _, sent := fields["site_id"]; !sent {
+			plc.SiteID = existing.SiteID
+		}
+
+		// O mesmo vale para a configuração do protocolo S7
+		if _, sent := fields["protocol_variant"]; !sent {
+			plc.ProtocolVariant = existing.ProtocolVariant
+		}
+		if _, sent := fields["local_tsap"]; !sent {
+			plc.LocalTSAP = existing.LocalTSAP
+		}
+		if _, sent := fields["remote_tsap"]; !sent {
+			plc.RemoteTSAP = existing.RemoteTSAP
+		}
+	}
+
 	// Validar campos
 	if !h.validarPLC(c, &plc) {
 		return
@@ -175,15 +271,21 @@ func (h *PLCHandler) UpdatePLC(c *gin.Context) {
 	// Garantir que o ID é o correto
 	plc.ID = id
 
+	// O monitoramento só muda pelos endpoints /monitoring/enable e /disable
+	plc.MonitoringEnabled = existing.MonitoringEnabled
+
+	// O limite de tags só muda pelo endpoint administrativo /tag-limit
+	plc.OverrideTagLimit = existing.OverrideTagLimit
+
 	// Atualizar o PLC
 	if err := h.plcService.Update(plc); err != nil {
-		statusCode := http.StatusInternalServerError
+		statusCode := plcSiteRefStatus(err)
 
 		if errors.Is(err, domain.ErrPLCNotFound) {
 			statusCode = http.StatusNotFound
 		}
 
-		c.JSON(statusCode, gin.H{"error": fmt.Sprintf("Erro ao atualizar PLC: %v", err)})
+		ErrorResponse(c, statusCode, errorCode(err, statusCode), fmt.Sprintf("Erro ao atualizar PLC: %v", err), nil)
 		return
 	}
 
@@ -200,13 +302,13 @@ func (h *PLCHandler) DeletePLC(c *gin.Context) {
 
 	// Excluir o PLC
 	if err := h.plcService.Delete(id); err != nil {
-		statusCode := http.StatusInternalServerError
+		statusCode := errorStatus(err)
 
 		if errors.Is(err, domain.ErrPLCNotFound) {
 			statusCode = http.StatusNotFound
 		}
 
-		c.JSON(statusCode, gin.H{"error": fmt.Sprintf("Erro ao excluir PLC: %v", err)})
+		ErrorResponse(c, statusCode, errorCode(err, statusCode), fmt.Sprintf("Erro ao excluir PLC: %v", err), nil)
 		return
 	}
 
@@ -221,10 +323,20 @@ func (h *PLCHandler) GetPLCTags(c *gin.Context) {
 		return
 	}
 
-	// Buscar as tags
-	tags, err := h.plcService.GetPLCTags(id)
+	// Buscar as tags, opcionalmente com a taxa de variação
+	var tags []domain.PLCTag
+	if c.Query("include_derivative") == "true" {
+		windowMs, werr := strconv.Atoi(c.DefaultQuery("window_ms", "5000"))
+		if werr != nil || windowMs <= 0 {
+			ErrorResponse(c, http.StatusBadRequest, domain.ErrCodeInvalidParameter, "window_ms deve ser um inteiro positivo", nil)
+			return
+		}
+		tags, err = h.plcService.GetPLCTagsWithDerivative(id, windowMs)
+	} else {
+		tags, err = h.plcService.GetPLCTags(id)
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Erro ao buscar tags: %v", err)})
+		ErrorResponse(c, errorStatus(err), errorCode(err, errorStatus(err)), fmt.Sprintf("Erro ao buscar tags: %v", err), nil)
 		return
 	}
 
@@ -237,11 +349,13 @@ func (h *PLCHandler) GetPLCTags(c *gin.Context) {
 				activeTags = append(activeTags, tag)
 			}
 		}
-		c.JSON(http.StatusOK, gin.H{"tags": activeTags})
+		h.recordTagAccess(c, activeTags...)
+		c.JSON(http.StatusOK, gin.H{"tags": domain.NewPLCTagResponses(activeTags)})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"tags": tags})
+	h.recordTagAccess(c, tags...)
+	c.JSON(http.StatusOK, gin.H{"tags": domain.NewPLCTagResponses(tags)})
 }
 
 // GetTagByID retorna uma tag específica
@@ -255,44 +369,201 @@ func (h *PLCHandler) GetTagByID(c *gin.Context) {
 	// Buscar a tag
 	tag, err := h.plcService.GetTagByID(id)
 	if err != nil {
-		statusCode := http.StatusInternalServerError
+		statusCode := errorStatus(err)
+
+		if errors.Is(err, domain.ErrPLCTagNotFound) {
+			statusCode = http.StatusNotFound
+		}
+
+		ErrorResponse(c, statusCode, errorCode(err, statusCode), fmt.Sprintf("Erro ao buscar tag: %v", err), nil)
+		return
+	}
+
+	// Incluir taxa de variação se solicitado
+	if c.Query("include_derivative") == "true" {
+		windowMs, err := strconv.Atoi(c.DefaultQuery("window_ms", "5000"))
+		if err != nil || windowMs <= 0 {
+			ErrorResponse(c, http.StatusBadRequest, domain.ErrCodeInvalidParameter, "window_ms deve ser um inteiro positivo", nil)
+			return
+		}
+		if rate, err := h.plcService.GetTagDerivative(tag.PLCID, tag.ID, windowMs); err == nil {
+			tag.ChangeRate = &rate
+		}
+	}
+
+	h.attachTagAnnotations(&tag, c.Query("include_annotations") == "true")
+
+	h.recordTagAccess(c, tag)
+	c.JSON(http.StatusOK, gin.H{"tag": domain.NewPLCTagResponse(tag)})
+}
+
+// GetTagDerivative retorna a taxa de variação de uma tag na janela informada
+func (h *PLCHandler) GetTagDerivative(c *gin.Context) {
+	plcID, err := h.getIDFromParams(c)
+	if err != nil {
+		return
+	}
+
+	tagID, err := strconv.Atoi(c.Param("tagID"))
+	if err != nil || tagID <= 0 {
+		ErrorResponse(c, http.StatusBadRequest, domain.ErrCodeInvalidParameter, "ID da tag inválido", nil)
+		return
+	}
+
+	windowMs, err := strconv.Atoi(c.DefaultQuery("window_ms", "5000"))
+	if err != nil || windowMs <= 0 {
+		ErrorResponse(c, http.StatusBadRequest, domain.ErrCodeInvalidParameter, "window_ms deve ser um inteiro positivo", nil)
+		return
+	}
+
+	rate, err := h.plcService.GetTagDerivative(plcID, tagID, windowMs)
+	if err != nil {
+		statusCode := errorStatus(err)
 
 		if errors.Is(err, domain.ErrPLCTagNotFound) {
 			statusCode = http.StatusNotFound
+		} else if errors.Is(err, domain.ErrNotEnoughHistory) || errors.Is(err, domain.ErrNonNumericValue) {
+			statusCode = http.StatusUnprocessableEntity
+		}
+
+		ErrorResponse(c, statusCode, errorCode(err, statusCode), fmt.Sprintf("Erro ao calcular taxa de variação: %v", err), nil)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"plc_id":      plcID,
+		"tag_id":      tagID,
+		"window_ms":   windowMs,
+		"change_rate": rate,
+		"time":        time.Now().Format(time.RFC3339),
+	})
+}
+
+// SearchTags busca tags por texto livre e filtros opcionais
+func (h *PLCHandler) SearchTags(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", strconv.Itoa(service.DefaultTagSearchPageSize)))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = service.DefaultTagSearchPageSize
+	} else if pageSize > service.MaxTagSearchPageSize {
+		pageSize = service.MaxTagSearchPageSize
+	}
+
+	filter := domain.TagSearchFilter{
+		Query:    c.Query("q"),
+		DataType: c.Query("data_type"),
+		Page:     page,
+		PageSize: pageSize,
+	}
+
+	if plcIDStr := c.Query("plc_id"); plcIDStr != "" {
+		plcID, err := strconv.Atoi(plcIDStr)
+		if err != nil || plcID <= 0 {
+			ErrorResponse(c, http.StatusBadRequest, domain.ErrCodeInvalidParameter, "plc_id inválido", nil)
+			return
+		}
+		filter.PLCID = &plcID
+	}
+
+	if activeStr := c.Query("active"); activeStr != "" {
+		active, err := strconv.ParseBool(activeStr)
+		if err != nil {
+			ErrorResponse(c, http.StatusBadRequest, domain.ErrCodeInvalidParameter, "active deve ser true ou false", nil)
+			return
+		}
+		filter.Active = &active
+	}
+
+	tags, total, err := h.plcService.SearchTags(c.Request.Context(), filter)
+	if err != nil {
+		statusCode := errorStatus(err)
+
+		if errors.Is(err, service.ErrSearchQueryTooLong) {
+			statusCode = http.StatusBadRequest
 		}
 
-		c.JSON(statusCode, gin.H{"error": fmt.Sprintf("Erro ao buscar tag: %v", err)})
+		ErrorResponse(c, statusCode, errorCode(err, statusCode), fmt.Sprintf("Erro ao buscar tags: %v", err), nil)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"tag": tag})
+	c.JSON(http.StatusOK, gin.H{
+		"tags":     domain.NewPLCTagResponses(tags),
+		"total":    total,
+		"page":     page,
+		"pageSize": pageSize,
+	})
+}
+
+// GetTagNameConvention retorna o padrão exigido nos nomes das tags
+func (h *PLCHandler) GetTagNameConvention(c *gin.Context) {
+	c.JSON(http.StatusOK, h.plcService.GetTagNameConvention())
+}
+
+// isTagValidationError indica se o erro do serviço é de dados inválidos da tag
+func isTagValidationError(err error) bool {
+	return errors.Is(err, service.ErrInvalidTagName) ||
+		errors.Is(err, service.ErrInvalidDataType) ||
+		errors.Is(err, service.ErrInvalidWriteRate) ||
+		errors.Is(err, domain.ErrInvalidByteOffset) ||
+		errors.Is(err, domain.ErrInvalidBitOffset) ||
+		errors.Is(err, domain.ErrBitOffsetNotAllowed) ||
+		errors.Is(err, domain.ErrUnpackBitsNotAllowed) ||
+		errors.Is(err, domain.ErrInvalidBitLabel) ||
+		errors.Is(err, domain.ErrInvalidMinDelta) ||
+		errors.Is(err, domain.ErrMinDeltaNotAllowed) ||
+		errors.Is(err, domain.ErrInvalidScaleFactor) ||
+		errors.Is(err, domain.ErrScalingNotAllowed) ||
+		errors.Is(err, domain.ErrInvalidRetentionDays)
+}
+
+// respondAddressConflict responde 409 com as tags conflitantes quando o
+// endereço da tag sobrepõe outra tag do PLC
+func respondAddressConflict(c *gin.Context, err error) bool {
+	var conflictErr *domain.AddressConflictError
+	if !errors.As(err, &conflictErr) {
+		return false
+	}
+
+	ErrorResponse(c, http.StatusConflict, domain.ErrCodeAddressConflict,
+		fmt.Sprintf("%v. Use force=true para salvar mesmo assim", err),
+		gin.H{"conflicts": conflictErr.Conflicts})
+	return true
 }
 
 // validarTag valida os campos de uma tag
 func (h *PLCHandler) validarTag(c *gin.Context, tag *domain.PLCTag) bool {
 	// Validar nome
 	if tag.Name == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Nome da tag é obrigatório"})
+		ErrorResponse(c, http.StatusBadRequest, domain.ErrCodeTagValidation, "Nome da tag é obrigatório", nil)
 		return false
 	}
 
 	// Validar tipo de dados
 	if tag.DataType == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Tipo de dados da tag é obrigatório"})
+		ErrorResponse(c, http.StatusBadRequest, domain.ErrCodeTagValidation, "Tipo de dados da tag é obrigatório", nil)
 		return false
 	}
 
 	// Validar bit offset para tipo bool
 	if tag.DataType == "bool" {
 		if tag.BitOffset < 0 || tag.BitOffset > 7 {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Bit offset deve estar entre 0 e 7 para tipo bool"})
+			ErrorResponse(c, http.StatusBadRequest, domain.ErrCodeTagValidation, "Bit offset deve estar entre 0 e 7 para tipo bool", nil)
 			return false
 		}
 	}
 
 	// Validar scan rate
 	if tag.ScanRate < 100 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Taxa de scan deve ser maior ou igual a 100ms"})
+		ErrorResponse(c, http.StatusBadRequest, domain.ErrCodeTagValidation, "Taxa de scan deve ser maior ou igual a 100ms", nil)
+		return false
+	}
+
+	// Validar limite de escrita
+	if tag.WriteRateLimitHz < 0 {
+		ErrorResponse(c, http.StatusBadRequest, domain.ErrCodeTagValidation, "Limite de taxa de escrita não pode ser negativo", nil)
 		return false
 	}
 
@@ -310,7 +581,7 @@ func (h *PLCHandler) CreatePLCTag(c *gin.Context) {
 	// Fazer binding dos dados da tag
 	var tag domain.PLCTag
 	if err := c.ShouldBindJSON(&tag); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Erro ao processar dados: %v", err)})
+		ErrorResponse(c, http.StatusBadRequest, errorCode(err, http.StatusBadRequest), fmt.Sprintf("Erro ao processar dados: %v", err), nil)
 		return
 	}
 
@@ -323,9 +594,21 @@ func (h *PLCHandler) CreatePLCTag(c *gin.Context) {
 	tag.PLCID = plcID
 
 	// Criar a tag
-	id, err := h.plcService.CreateTag(tag)
+	id, err := h.plcService.CreateTag(tag, c.Query("force") == "true")
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Erro ao criar tag: %v", err)})
+		if respondAddressConflict(c, err) {
+			return
+		}
+
+		statusCode := errorStatus(err)
+
+		if isTagValidationError(err) {
+			statusCode = http.StatusBadRequest
+		} else if errors.Is(err, domain.ErrTagLimitExceeded) {
+			statusCode = http.StatusUnprocessableEntity
+		}
+
+		ErrorResponse(c, statusCode, errorCode(err, statusCode), fmt.Sprintf("Erro ao criar tag: %v", err), nil)
 		return
 	}
 
@@ -346,20 +629,20 @@ func (h *PLCHandler) UpdatePLCTag(c *gin.Context) {
 	// Buscar a tag existente para confirmar que existe
 	oldTag, err := h.plcService.GetTagByID(id)
 	if err != nil {
-		statusCode := http.StatusInternalServerError
+		statusCode := errorStatus(err)
 
 		if errors.Is(err, domain.ErrPLCTagNotFound) {
 			statusCode = http.StatusNotFound
 		}
 
-		c.JSON(statusCode, gin.H{"error": fmt.Sprintf("Erro ao buscar tag: %v", err)})
+		ErrorResponse(c, statusCode, errorCode(err, statusCode), fmt.Sprintf("Erro ao buscar tag: %v", err), nil)
 		return
 	}
 
 	// Fazer binding dos dados de atualização
 	var tag domain.PLCTag
 	if err := c.ShouldBindJSON(&tag); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Erro ao processar dados: %v", err)})
+		ErrorResponse(c, http.StatusBadRequest, errorCode(err, http.StatusBadRequest), fmt.Sprintf("Erro ao processar dados: %v", err), nil)
 		return
 	}
 
@@ -377,20 +660,121 @@ func (h *PLCHandler) UpdatePLCTag(c *gin.Context) {
 	}
 
 	// Atualizar a tag
-	if err := h.plcService.UpdateTag(tag); err != nil {
-		statusCode := http.StatusInternalServerError
+	if err := h.plcService.UpdateTag(tag, c.Query("force") == "true"); err != nil {
+		if respondAddressConflict(c, err) {
+			return
+		}
+
+		statusCode := errorStatus(err)
 
 		if errors.Is(err, domain.ErrPLCTagNotFound) {
 			statusCode = http.StatusNotFound
+		} else if isTagValidationError(err) {
+			statusCode = http.StatusBadRequest
 		}
 
-		c.JSON(statusCode, gin.H{"error": fmt.Sprintf("Erro ao atualizar tag: %v", err)})
+		ErrorResponse(c, statusCode, errorCode(err, statusCode), fmt.Sprintf("Erro ao atualizar tag: %v", err), nil)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Tag atualizada com sucesso"})
 }
 
+// GetTagAddressConflicts lista as tags de um PLC com endereços sobrepostos
+func (h *PLCHandler) GetTagAddressConflicts(c *gin.Context) {
+	plcID, err := h.getIDFromParams(c)
+	if err != nil {
+		return
+	}
+
+	conflicts, err := h.plcService.DetectAddressConflicts(plcID)
+	if err != nil {
+		ErrorResponse(c, errorStatus(err), errorCode(err, errorStatus(err)), fmt.Sprintf("Erro ao verificar conflitos de endereço: %v", err), nil)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"conflicts": conflicts,
+		"count":     len(conflicts),
+	})
+}
+
+// MigrateTagType troca o tipo de dados de uma tag em produção.
+// Corpo: {"new_data_type": "real"}
+func (h *PLCHandler) MigrateTagType(c *gin.Context) {
+	id, err := h.getIDFromParams(c)
+	if err != nil {
+		return
+	}
+
+	var req struct {
+		NewDataType string `json:"new_data_type" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ErrorResponse(c, http.StatusBadRequest, domain.ErrCodeInvalidPayload, "Dados inválidos", err.Error())
+		return
+	}
+
+	userID, _ := c.Get("userID")
+	migratorID, _ := userID.(int)
+
+	result, err := h.plcService.MigrateTagType(id, req.NewDataType, migratorID)
+	if err != nil {
+		statusCode := errorStatus(err)
+
+		if errors.Is(err, domain.ErrPLCTagNotFound) {
+			statusCode = http.StatusNotFound
+		} else if errors.Is(err, service.ErrSameDataType) || isTagValidationError(err) {
+			statusCode = http.StatusBadRequest
+		}
+
+		ErrorResponse(c, statusCode, errorCode(err, statusCode), fmt.Sprintf("Erro ao migrar tipo da tag: %v", err), nil)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// ImportWonderwareTags importa tags de um XML exportado pelo Wonderware/InTouch
+// enviado no campo "file" de um formulário multipart
+func (h *PLCHandler) ImportWonderwareTags(c *gin.Context) {
+	plcID, err := h.getIDFromParams(c)
+	if err != nil {
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		ErrorResponse(c, http.StatusBadRequest, domain.ErrCodeInvalidPayload, "Arquivo não encontrado", nil)
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		ErrorResponse(c, http.StatusBadRequest, errorCode(err, http.StatusBadRequest), fmt.Sprintf("Erro ao abrir arquivo: %v", err), nil)
+		return
+	}
+	defer file.Close()
+
+	result, err := h.plcService.ImportWonderwareTags(plcID, file)
+	if err != nil {
+		statusCode := errorStatus(err)
+
+		if errors.Is(err, domain.ErrPLCNotFound) {
+			statusCode = http.StatusNotFound
+		} else if errors.Is(err, service.ErrInvalidImportFile) {
+			statusCode = http.StatusBadRequest
+		} else if errors.Is(err, domain.ErrTagLimitExceeded) {
+			statusCode = http.StatusUnprocessableEntity
+		}
+
+		ErrorResponse(c, statusCode, errorCode(err, statusCode), fmt.Sprintf("Erro ao importar tags: %v", err), nil)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
 // DeletePLCTag exclui uma tag
 func (h *PLCHandler) DeletePLCTag(c *gin.Context) {
 	// Extrair e validar o ID
@@ -401,112 +785,600 @@ func (h *PLCHandler) DeletePLCTag(c *gin.Context) {
 
 	// Excluir a tag
 	if err := h.plcService.DeleteTag(id); err != nil {
-		statusCode := http.StatusInternalServerError
+		statusCode := errorStatus(err)
 
 		if errors.Is(err, domain.ErrPLCTagNotFound) {
 			statusCode = http.StatusNotFound
 		}
 
-		c.JSON(statusCode, gin.H{"error": fmt.Sprintf("Erro ao excluir tag: %v", err)})
+		ErrorResponse(c, statusCode, errorCode(err, statusCode), fmt.Sprintf("Erro ao excluir tag: %v", err), nil)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Tag excluída com sucesso"})
 }
 
-// WriteTagValue escreve um valor em uma tag
-func (h *PLCHandler) WriteTagValue(c *gin.Context) {
-	// Fazer binding dos dados
-	var input struct {
-		TagName string      `json:"tag_name" binding:"required"`
-		Value   interface{} `json:"value" binding:"required"`
+// BulkDeletePLCTags exclui várias tags de um PLC em duas etapas: sem
+// confirm_token retorna a prévia e o token; com o token efetiva a exclusão.
+// Tags com alarmes ativos exigem force=true (409 com os alarmes sem ele).
+func (h *PLCHandler) BulkDeletePLCTags(c *gin.Context) {
+	plcID, err := h.getIDFromParams(c)
+	if err != nil {
+		return
 	}
 
-	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Erro ao processar dados: %v", err)})
-		return
+	var req struct {
+		TagIDs       []int  `json:"tag_ids" binding:"required"`
+		ConfirmToken string `json:"confirm_token"`
 	}
 
-	// Validar tag_name
-	if input.TagName == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Nome da tag é obrigatório"})
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ErrorResponse(c, http.StatusBadRequest, domain.ErrCodeInvalidPayload, "Dados inválidos", err.Error())
 		return
 	}
 
-	// Validar value
-	if input.Value == nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Valor não pode ser nulo"})
+	if req.ConfirmToken == "" {
+		preview, err := h.plcService.PrepareBulkTagDelete(plcID, req.TagIDs)
+		if err != nil {
+			ErrorResponse(c, bulkDeleteStatus(err), errorCode(err, bulkDeleteStatus(err)), fmt.Sprintf("Erro ao preparar exclusão: %v", err), nil)
+			return
+		}
+		c.JSON(http.StatusOK, preview)
 		return
 	}
 
-	// Escrever o valor
-	if err := h.plcService.WriteTagValue(input.TagName, input.Value); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Erro ao escrever valor: %v", err)})
+	result, err := h.plcService.ConfirmBulkTagDelete(plcID, req.TagIDs, req.ConfirmToken, c.Query("force") == "true")
+	if err != nil {
+		if respondActiveAlarmConflict(c, err) {
+			return
+		}
+		ErrorResponse(c, bulkDeleteStatus(err), errorCode(err, bulkDeleteStatus(err)), fmt.Sprintf("Erro ao excluir tags: %v", err), nil)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"success": true,
-		"message": "Valor escrito com sucesso",
-		"time":    time.Now().Format(time.RFC3339),
-	})
+	c.JSON(http.StatusOK, result)
 }
 
-// GetPLCStatus retorna o status e estatísticas de monitoramento de PLCs
-func (h *PLCHandler) GetPLCStatus(c *gin.Context) {
-	// Usar o método GetPLCStats do PLCService para obter estatísticas
-	stats := h.plcService.GetPLCStats()
+// BulkUpdatePLCTags altera em massa as tags de um PLC que atendem ao filtro.
+// Corpo: {"filter": {"data_type": "real", "active": true}, "update": {"scan_rate": 500}}
+func (h *PLCHandler) BulkUpdatePLCTags(c *gin.Context) {
+	plcID, err := h.getIDFromParams(c)
+	if err != nil {
+		return
+	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"stats": stats,
-		"time":  time.Now().Format(time.RFC3339),
-	})
-}
+	var req struct {
+		Filter domain.TagFilter           `json:"filter"`
+		Update map[string]json.RawMessage `json:"update" binding:"required"`
+	}
 
-// DiagnosticTags verifica e repara problemas com as tags
-func (h *PLCHandler) DiagnosticTags(c *gin.Context) {
-	results, err := h.plcService.DiagnosticTags()
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ErrorResponse(c, http.StatusBadRequest, domain.ErrCodeInvalidPayload, "Dados inválidos", err.Error())
+		return
+	}
+
+	patch, err := domain.ParseTagPatch(req.Update)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Erro ao executar diagnóstico: %v", err)})
+		ErrorResponse(c, http.StatusBadRequest, errorCode(err, http.StatusBadRequest), err.Error(), nil)
 		return
 	}
 
-	c.JSON(http.StatusOK, results)
+	updated, err := h.plcService.BulkUpdateTags(plcID, req.Filter, patch)
+	if err != nil {
+		statusCode := errorStatus(err)
+		if errors.Is(err, domain.ErrPLCNotFound) {
+			statusCode = http.StatusNotFound
+		} else if errors.Is(err, domain.ErrEmptyTagPatch) {
+			statusCode = http.StatusBadRequest
+		}
+		ErrorResponse(c, statusCode, errorCode(err, statusCode), fmt.Sprintf("Erro ao atualizar tags: %v", err), nil)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"updated": updated})
 }
 
-// ResetPLCConnection força uma reconexão com um PLC específico
-func (h *PLCHandler) ResetPLCConnection(c *gin.Context) {
-	// Extrair e validar o ID
-	id, err := h.getIDFromParams(c)
-	if err != nil {
+// idleSinceParam lê o período sem mudanças (since_hours, padrão 24)
+func idleSinceParam(c *gin.Context) (time.Duration, bool) {
+	hours, err := strconv.Atoi(c.DefaultQuery("since_hours", "24"))
+	if err != nil || hours <= 0 {
+		ErrorResponse(c, http.StatusBadRequest, domain.ErrCodeInvalidParameter, "since_hours deve ser um inteiro positivo", nil)
+		return 0, false
+	}
+	return time.Duration(hours) * time.Hour, true
+}
+
+// GetIdleTags lista as tags sem mudança de valor no período, com a taxa de
+// leitura sugerida para cada uma
+func (h *PLCHandler) GetIdleTags(c *gin.Context) {
+	since, ok := idleSinceParam(c)
+	if !ok {
 		return
 	}
 
-	// Resetar a conexão
-	err = h.plcService.ResetPLCConnection(id)
+	idle, err := h.plcService.GetIdleTags(since)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Erro ao resetar conexão: %v", err)})
+		ErrorResponse(c, errorStatus(err), errorCode(err, errorStatus(err)), fmt.Sprintf("Erro ao buscar tags ociosas: %v", err), nil)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"message": "Reconexão com o PLC solicitada com sucesso",
-		"time":    time.Now().Format(time.RFC3339),
-	})
+	c.JSON(http.StatusOK, gin.H{"tags": idle, "total": len(idle)})
 }
 
-// GetPLCHealth retorna o status de saúde de todos os PLCs
-func (h *PLCHandler) GetPLCHealth(c *gin.Context) {
-	health, err := h.plcService.CheckPLCHealth()
+// GetAutoDisabledTags lista as tags desativadas por falhas consecutivas de
+// leitura, com o último erro
+func (h *PLCHandler) GetAutoDisabledTags(c *gin.Context) {
+	tags, err := h.plcService.GetAutoDisabledTags()
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Erro ao verificar saúde: %v", err)})
+		ErrorResponse(c, errorStatus(err), errorCode(err, errorStatus(err)), fmt.Sprintf("Erro ao buscar tags desativadas: %v", err), nil)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"health": health,
-		"time":   time.Now().Format(time.RFC3339),
-	})
+	c.JSON(http.StatusOK, gin.H{"tags": domain.NewPLCTagResponses(tags), "total": len(tags)})
+}
+
+// EnableTag reativa uma tag, inclusive as desativadas automaticamente
+func (h *PLCHandler) EnableTag(c *gin.Context) {
+	id, err := h.getIDFromParams(c)
+	if err != nil {
+		return
+	}
+
+	userID, _ := c.Get("userID")
+	uid, _ := userID.(int)
+
+	tag, err := h.plcService.EnableTag(id, uid)
+	if err != nil {
+		statusCode := errorStatus(err)
+
+		if errors.Is(err, domain.ErrPLCTagNotFound) {
+			statusCode = http.StatusNotFound
+		}
+
+		ErrorResponse(c, statusCode, errorCode(err, statusCode), fmt.Sprintf("Erro ao reativar tag: %v", err), nil)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tag": domain.NewPLCTagResponse(tag)})
+}
+
+// ApplyIdleTagSuggestions aplica a taxa de leitura sugerida a todas as tags
+// ociosas do período
+func (h *PLCHandler) ApplyIdleTagSuggestions(c *gin.Context) {
+	since, ok := idleSinceParam(c)
+	if !ok {
+		return
+	}
+
+	userID, _ := c.Get("userID")
+	uid, _ := userID.(int)
+
+	applied, err := h.plcService.ApplyIdleTagSuggestions(since, uid)
+	if err != nil {
+		ErrorResponse(c, errorStatus(err), errorCode(err, errorStatus(err)), fmt.Sprintf("Erro ao aplicar sugestões: %v", err), nil)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"updated": len(applied), "tags": applied})
+}
+
+// AdaptScanRates ajusta o scan rate das tags do PLC pela frequência de
+// mudança dos valores nas últimas 24 horas. Com dry_run=true apenas retorna
+// as sugestões.
+func (h *PLCHandler) AdaptScanRates(c *gin.Context) {
+	plcID, err := h.getIDFromParams(c)
+	if err != nil {
+		return
+	}
+
+	var suggestions []domain.ScanRateSuggestion
+	if c.Query("dry_run") == "true" {
+		suggestions, err = h.plcService.SuggestScanRates(plcID)
+	} else {
+		userID, _ := c.Get("userID")
+		uid, _ := userID.(int)
+		suggestions, err = h.plcService.AdaptScanRates(plcID, uid)
+	}
+
+	if err != nil {
+		statusCode := errorStatus(err)
+
+		if errors.Is(err, domain.ErrPLCNotFound) {
+			statusCode = http.StatusNotFound
+		} else if errors.Is(err, service.ErrHistoryNotConfigured) {
+			statusCode = http.StatusServiceUnavailable
+		}
+
+		ErrorResponse(c, statusCode, errorCode(err, statusCode), fmt.Sprintf("Erro ao adaptar scan rate: %v", err), nil)
+		return
+	}
+
+	c.JSON(http.StatusOK, suggestions)
+}
+
+// respondActiveAlarmConflict responde 409 com as ocorrências de alarme ativas
+// quando a exclusão atinge tags alarmadas
+func respondActiveAlarmConflict(c *gin.Context, err error) bool {
+	var conflictErr *domain.ActiveAlarmConflictError
+	if !errors.As(err, &conflictErr) {
+		return false
+	}
+
+	ErrorResponse(c, http.StatusConflict, domain.ErrCodeConflict,
+		fmt.Sprintf("%v. Use force=true para excluir mesmo assim", err),
+		gin.H{"alarms": conflictErr.Alarms})
+	return true
+}
+
+// bulkDeleteStatus mapeia os erros da exclusão em massa para códigos HTTP
+func bulkDeleteStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrPLCNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrBulkDeleteEmpty), errors.Is(err, service.ErrBulkDeleteTooMany):
+		return http.StatusBadRequest
+	case errors.Is(err, service.ErrInvalidConfirmToken):
+		return http.StatusConflict
+	case errors.Is(err, service.ErrBulkDeleteUnavailable):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// WriteTagValue escreve um valor em uma tag
+func (h *PLCHandler) WriteTagValue(c *gin.Context) {
+	// Fazer binding dos dados
+	var input struct {
+		TagName string      `json:"tag_name" binding:"required"`
+		Value   interface{} `json:"value" binding:"required"`
+
+		// Valores das tags dependentes, por nome (com follow_dependencies=true)
+		DependencyValues map[string]interface{} `json:"dependency_values"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		ErrorResponse(c, http.StatusBadRequest, errorCode(err, http.StatusBadRequest), fmt.Sprintf("Erro ao processar dados: %v", err), nil)
+		return
+	}
+
+	// Validar tag_name
+	if input.TagName == "" {
+		ErrorResponse(c, http.StatusBadRequest, domain.ErrCodeInvalidPayload, "Nome da tag é obrigatório", nil)
+		return
+	}
+
+	// Validar value
+	if input.Value == nil {
+		ErrorResponse(c, http.StatusBadRequest, domain.ErrCodeInvalidPayload, "Valor não pode ser nulo", nil)
+		return
+	}
+
+	// Simulação: valida a escrita sem enviar ao PLC
+	if c.Query("dry_run") == "true" {
+		preview, err := h.plcService.PreviewTagWrite(input.TagName, input.Value)
+		if err != nil {
+			statusCode := errorStatus(err)
+
+			if errors.Is(err, service.ErrTagNotFound) {
+				statusCode = http.StatusNotFound
+			} else if errors.Is(err, service.ErrWriteNotPermitted) {
+				statusCode = http.StatusForbidden
+			} else if errors.Is(err, service.ErrMonitoringNotActive) {
+				statusCode = http.StatusServiceUnavailable
+			}
+
+			ErrorResponse(c, statusCode, errorCode(err, statusCode), fmt.Sprintf("Erro ao simular escrita: %v", err), nil)
+			return
+		}
+
+		if !preview.WouldSucceed {
+			c.JSON(http.StatusUnprocessableEntity, preview)
+			return
+		}
+
+		c.JSON(http.StatusOK, preview)
+		return
+	}
+
+	userID, _ := c.Get("userID")
+	writerID, _ := userID.(int)
+
+	// Escrita em cascata: a tag e suas dependências, em ordem
+	if c.Query("follow_dependencies") == "true" {
+		if c.Query("async") == "true" {
+			ErrorResponse(c, http.StatusBadRequest, domain.ErrCodeInvalidParameter, "follow_dependencies não pode ser combinado com async", nil)
+			return
+		}
+		h.writeTagWithDependencies(c, input.TagName, input.Value, input.DependencyValues, writerID)
+		return
+	}
+
+	// Escrita assíncrona: enfileirar e responder imediatamente
+	if c.Query("async") == "true" {
+		write, err := h.plcService.QueueTagWrite(input.TagName, input.Value, writerID)
+		if err != nil {
+			statusCode := errorStatus(err)
+
+			if errors.Is(err, service.ErrTagNotFound) {
+				statusCode = http.StatusNotFound
+			} else if errors.Is(err, service.ErrWriteNotPermitted) {
+				statusCode = http.StatusForbidden
+			} else if errors.Is(err, service.ErrWriteQueueUnavailable) {
+				statusCode = http.StatusServiceUnavailable
+			} else if errors.Is(err, service.ErrWriteBlocked) {
+				statusCode = http.StatusUnprocessableEntity
+			}
+
+			ErrorResponse(c, statusCode, errorCode(err, statusCode), fmt.Sprintf("Erro ao enfileirar escrita: %v", err), nil)
+			return
+		}
+
+		c.JSON(http.StatusAccepted, gin.H{
+			"request_id": write.RequestID,
+			"status":     "queued",
+		})
+		return
+	}
+
+	// Escrever o valor
+	if err := h.plcService.WriteTagValue(input.TagName, input.Value, writerID); err != nil {
+		statusCode := errorStatus(err)
+
+		if errors.Is(err, service.ErrWriteRateLimited) {
+			statusCode = http.StatusTooManyRequests
+		} else if errors.Is(err, domain.ErrInvalidScaledValue) {
+			statusCode = http.StatusBadRequest
+		} else if errors.Is(err, service.ErrWriteBlocked) {
+			statusCode = http.StatusUnprocessableEntity
+		}
+
+		ErrorResponse(c, statusCode, errorCode(err, statusCode), fmt.Sprintf("Erro ao escrever valor: %v", err), nil)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Valor escrito com sucesso",
+		"time":    time.Now().Format(time.RFC3339),
+	})
+}
+
+// GetWriteQueue lista as escritas assíncronas pendentes de um PLC
+func (h *PLCHandler) GetWriteQueue(c *gin.Context) {
+	plcID, err := h.getIDFromParams(c)
+	if err != nil {
+		return
+	}
+
+	writes, err := h.plcService.GetWriteQueue(plcID)
+	if err != nil {
+		statusCode := errorStatus(err)
+
+		if errors.Is(err, domain.ErrPLCNotFound) {
+			statusCode = http.StatusNotFound
+		} else if errors.Is(err, service.ErrWriteQueueUnavailable) {
+			statusCode = http.StatusServiceUnavailable
+		}
+
+		ErrorResponse(c, statusCode, errorCode(err, statusCode), fmt.Sprintf("Erro ao buscar fila de escrita: %v", err), nil)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"plc_id": plcID,
+		"writes": writes,
+		"count":  len(writes),
+	})
+}
+
+// CancelQueuedWrite cancela uma escrita assíncrona pendente
+func (h *PLCHandler) CancelQueuedWrite(c *gin.Context) {
+	plcID, err := h.getIDFromParams(c)
+	if err != nil {
+		return
+	}
+
+	requestID := c.Param("requestID")
+	if err := h.plcService.CancelQueuedWrite(plcID, requestID); err != nil {
+		statusCode := errorStatus(err)
+
+		if errors.Is(err, service.ErrQueuedWriteNotFound) {
+			statusCode = http.StatusNotFound
+		} else if errors.Is(err, service.ErrWriteQueueUnavailable) {
+			statusCode = http.StatusServiceUnavailable
+		}
+
+		ErrorResponse(c, statusCode, errorCode(err, statusCode), fmt.Sprintf("Erro ao cancelar escrita: %v", err), nil)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"request_id": requestID,
+		"status":     "cancelled",
+	})
+}
+
+// GetPLCStatus retorna o status e estatísticas de monitoramento de PLCs
+func (h *PLCHandler) GetPLCStatus(c *gin.Context) {
+	// Usar o método GetPLCStats do PLCService para obter estatísticas
+	stats := h.plcService.GetPLCStats()
+
+	c.JSON(http.StatusOK, gin.H{
+		"stats": domain.NewPLCStatsResponse(stats),
+		"time":  time.Now().Format(time.RFC3339),
+	})
+}
+
+// GetPLCMetadataCacheStats retorna o tamanho e a taxa de acerto do cache em
+// memória dos PLCs
+func (h *PLCHandler) GetPLCMetadataCacheStats(c *gin.Context) {
+	c.JSON(http.StatusOK, h.plcService.GetPLCMetadataCacheStats())
+}
+
+// EnablePLCMonitoring retoma o monitoramento de um PLC pausado
+func (h *PLCHandler) EnablePLCMonitoring(c *gin.Context) {
+	h.setPLCMonitoring(c, true)
+}
+
+// DisablePLCMonitoring pausa o monitoramento de um PLC sem desativá-lo
+func (h *PLCHandler) DisablePLCMonitoring(c *gin.Context) {
+	h.setPLCMonitoring(c, false)
+}
+
+func (h *PLCHandler) setPLCMonitoring(c *gin.Context, enabled bool) {
+	id, err := h.getIDFromParams(c)
+	if err != nil {
+		return
+	}
+
+	plc, err := h.plcService.SetPLCMonitoring(id, enabled)
+	if err != nil {
+		statusCode := errorStatus(err)
+		if errors.Is(err, domain.ErrPLCNotFound) {
+			statusCode = http.StatusNotFound
+		}
+		ErrorResponse(c, statusCode, errorCode(err, statusCode), fmt.Sprintf("Erro ao alterar monitoramento do PLC: %v", err), nil)
+		return
+	}
+
+	message := "Monitoramento do PLC pausado"
+	if enabled {
+		message = "Monitoramento do PLC habilitado"
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": message,
+		"plc":     domain.NewPLCResponse(plc),
+	})
+}
+
+// SetPLCTagLimit define o limite de tags próprio do PLC.
+// Corpo: {"override_tag_limit": 1000} ou {"override_tag_limit": null} para
+// voltar ao limite global
+func (h *PLCHandler) SetPLCTagLimit(c *gin.Context) {
+	id, err := h.getIDFromParams(c)
+	if err != nil {
+		return
+	}
+
+	var req struct {
+		OverrideTagLimit *int `json:"override_tag_limit"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ErrorResponse(c, http.StatusBadRequest, domain.ErrCodeInvalidPayload, "Dados inválidos", err.Error())
+		return
+	}
+
+	plc, err := h.plcService.SetPLCTagLimit(id, req.OverrideTagLimit)
+	if err != nil {
+		statusCode := errorStatus(err)
+		if errors.Is(err, domain.ErrPLCNotFound) {
+			statusCode = http.StatusNotFound
+		} else if errors.Is(err, service.ErrInvalidTagLimit) {
+			statusCode = http.StatusBadRequest
+		}
+		ErrorResponse(c, statusCode, errorCode(err, statusCode), fmt.Sprintf("Erro ao alterar limite de tags do PLC: %v", err), nil)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Limite de tags do PLC atualizado",
+		"plc":     domain.NewPLCResponse(plc),
+	})
+}
+
+// GetPLCPerformance retorna latência (p50/p95/p99), leituras por segundo e
+// bytes lidos de um PLC. Com reset=true zera os contadores após a leitura.
+func (h *PLCHandler) GetPLCPerformance(c *gin.Context) {
+	id, err := h.getIDFromParams(c)
+	if err != nil {
+		return
+	}
+
+	reset := c.Query("reset") == "true"
+
+	stats, err := h.plcService.GetPLCPerformance(id, reset)
+	if err != nil {
+		statusCode := errorStatus(err)
+		if errors.Is(err, domain.ErrPLCNotFound) {
+			statusCode = http.StatusNotFound
+		}
+		ErrorResponse(c, statusCode, errorCode(err, statusCode), fmt.Sprintf("Erro ao buscar desempenho do PLC: %v", err), nil)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"performance": stats,
+		"reset":       reset,
+	})
+}
+
+// DiagnosticTags verifica e repara problemas com as tags
+func (h *PLCHandler) DiagnosticTags(c *gin.Context) {
+	results, err := h.plcService.DiagnosticTags()
+	if err != nil {
+		ErrorResponse(c, errorStatus(err), errorCode(err, errorStatus(err)), fmt.Sprintf("Erro ao executar diagnóstico: %v", err), nil)
+		return
+	}
+
+	c.JSON(http.StatusOK, results)
+}
+
+// ResetPLCConnection força uma reconexão com um PLC específico
+func (h *PLCHandler) ResetPLCConnection(c *gin.Context) {
+	// Extrair e validar o ID
+	id, err := h.getIDFromParams(c)
+	if err != nil {
+		return
+	}
+
+	// Resetar a conexão
+	err = h.plcService.ResetPLCConnection(id)
+	if err != nil {
+		ErrorResponse(c, errorStatus(err), errorCode(err, errorStatus(err)), fmt.Sprintf("Erro ao resetar conexão: %v", err), nil)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Reconexão com o PLC solicitada com sucesso",
+		"time":    time.Now().Format(time.RFC3339),
+	})
+}
+
+// GetPLCHealth retorna o status de saúde de todos os PLCs
+func (h *PLCHandler) GetPLCHealth(c *gin.Context) {
+	siteID, ok := siteIDQuery(c)
+	if !ok {
+		return
+	}
+
+	health, err := h.plcService.CheckPLCHealth()
+	if err != nil {
+		ErrorResponse(c, errorStatus(err), errorCode(err, errorStatus(err)), fmt.Sprintf("Erro ao verificar saúde: %v", err), nil)
+		return
+	}
+
+	// Manter apenas os PLCs do site informado
+	if siteID != nil {
+		plcs, err := h.plcService.GetPLCsBySite(*siteID)
+		if err != nil {
+			ErrorResponse(c, errorStatus(err), errorCode(err, errorStatus(err)), fmt.Sprintf("Erro ao buscar PLCs do site: %v", err), nil)
+			return
+		}
+		siteHealth := make(map[int]string, len(plcs))
+		for _, plc := range plcs {
+			if status, ok := health[plc.ID]; ok {
+				siteHealth[plc.ID] = status
+			}
+		}
+		health = siteHealth
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"health": health,
+		"time":   time.Now().Format(time.RFC3339),
+	})
 }
 
 // GetDetailedStats retorna estatísticas detalhadas do sistema
@@ -519,17 +1391,205 @@ func (h *PLCHandler) GetDetailedStats(c *gin.Context) {
 	})
 }
 
+// ForceSync dispara uma sincronização imediata PostgreSQL -> Redis
+func (h *PLCHandler) ForceSync(c *gin.Context) {
+	if err := h.plcService.SyncNow(); err != nil {
+		statusCode := errorStatus(err)
+
+		if errors.Is(err, service.ErrSyncNotRunning) {
+			statusCode = http.StatusConflict
+		}
+
+		ErrorResponse(c, statusCode, errorCode(err, statusCode), fmt.Sprintf("Erro ao solicitar sincronização: %v", err), nil)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": "Sincronização solicitada com sucesso",
+		"time":    time.Now().Format(time.RFC3339),
+	})
+}
+
+// GetSyncStatus retorna as mudanças ainda não sincronizadas com o Redis
+func (h *PLCHandler) GetSyncStatus(c *gin.Context) {
+	summary, err := h.plcService.GetSyncChangeSummary()
+	if err != nil {
+		statusCode := errorStatus(err)
+
+		if errors.Is(err, service.ErrSyncNotRunning) {
+			statusCode = http.StatusConflict
+		}
+
+		ErrorResponse(c, statusCode, errorCode(err, statusCode), fmt.Sprintf("Erro ao obter status da sincronização: %v", err), nil)
+		return
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
+
+// ClearSyncTracker descarta as mudanças pendentes de sincronização
+func (h *PLCHandler) ClearSyncTracker(c *gin.Context) {
+	if err := h.plcService.ClearSyncChangeTracker(); err != nil {
+		statusCode := errorStatus(err)
+
+		if errors.Is(err, service.ErrSyncNotRunning) {
+			statusCode = http.StatusConflict
+		}
+
+		ErrorResponse(c, statusCode, errorCode(err, statusCode), fmt.Sprintf("Erro ao limpar rastreador: %v", err), nil)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Rastreador de mudanças limpo com sucesso",
+		"time":    time.Now().Format(time.RFC3339),
+	})
+}
+
+// GetSyncErrors retorna os últimos erros de sincronização
+func (h *PLCHandler) GetSyncErrors(c *gin.Context) {
+	syncErrors, err := h.plcService.GetSyncErrorLog()
+	if err != nil {
+		statusCode := errorStatus(err)
+
+		if errors.Is(err, service.ErrSyncNotRunning) {
+			statusCode = http.StatusConflict
+		}
+
+		ErrorResponse(c, statusCode, errorCode(err, statusCode), fmt.Sprintf("Erro ao obter erros de sincronização: %v", err), nil)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"errors": syncErrors,
+		"count":  len(syncErrors),
+	})
+}
+
+// GetSyncLockStatus mostra quem detém os locks distribuídos de sincronização,
+// para diagnosticar locks presos
+func (h *PLCHandler) GetSyncLockStatus(c *gin.Context) {
+	locks, err := h.plcService.GetSyncLockStatus()
+	if err != nil {
+		statusCode := errorStatus(err)
+
+		if errors.Is(err, service.ErrSyncNotRunning) {
+			statusCode = http.StatusConflict
+		}
+
+		ErrorResponse(c, statusCode, errorCode(err, statusCode), fmt.Sprintf("Erro ao consultar locks de sincronização: %v", err), nil)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"locks": locks})
+}
+
+// SetSyncInterval altera o intervalo da sincronização periódica (mínimo de 30 segundos)
+func (h *PLCHandler) SetSyncInterval(c *gin.Context) {
+	var input struct {
+		IntervalMinutes float64 `json:"interval_minutes" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		ErrorResponse(c, http.StatusBadRequest, errorCode(err, http.StatusBadRequest), fmt.Sprintf("Erro ao processar dados: %v", err), nil)
+		return
+	}
+
+	interval := time.Duration(input.IntervalMinutes * float64(time.Minute))
+	if err := h.plcService.SetSyncInterval(interval); err != nil {
+		statusCode := errorStatus(err)
+
+		if errors.Is(err, service.ErrSyncIntervalTooShort) {
+			statusCode = http.StatusBadRequest
+		}
+
+		ErrorResponse(c, statusCode, errorCode(err, statusCode), fmt.Sprintf("Erro ao alterar intervalo: %v", err), nil)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":          "Intervalo de sincronização atualizado",
+		"interval_minutes": input.IntervalMinutes,
+	})
+}
+
+// GetPLCConfig retorna a configuração efetiva do serviço de PLCs
+func (h *PLCHandler) GetPLCConfig(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"config": h.plcService.GetRuntimeConfig()})
+}
+
+// UpdatePLCConfig altera a configuração do serviço de PLCs sem reinício. Só
+// os campos informados são alterados.
+func (h *PLCHandler) UpdatePLCConfig(c *gin.Context) {
+	var patch domain.PLCRuntimeConfigPatch
+	if err := c.ShouldBindJSON(&patch); err != nil {
+		ErrorResponse(c, http.StatusBadRequest, errorCode(err, http.StatusBadRequest), fmt.Sprintf("Erro ao processar dados: %v", err), nil)
+		return
+	}
+
+	userID, _ := c.Get("userID")
+	uid, _ := userID.(int)
+
+	config, err := h.plcService.UpdateRuntimeConfig(patch, uid)
+	if err != nil {
+		statusCode := errorStatus(err)
+
+		if errors.Is(err, service.ErrInvalidPLCConfig) {
+			statusCode = http.StatusBadRequest
+		}
+
+		ErrorResponse(c, statusCode, errorCode(err, statusCode), fmt.Sprintf("Erro ao atualizar configuração: %v", err), nil)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Configuração atualizada",
+		"config":  config,
+	})
+}
+
+// StartDebugMonitor (re)inicia o monitor de depuração com o intervalo informado
+func (h *PLCHandler) StartDebugMonitor(c *gin.Context) {
+	intervalSec, err := strconv.Atoi(c.DefaultQuery("interval_sec", "5"))
+	if err != nil || intervalSec <= 0 {
+		ErrorResponse(c, http.StatusBadRequest, domain.ErrCodeInvalidParameter, "interval_sec deve ser um inteiro positivo", nil)
+		return
+	}
+
+	if err := h.plcService.StartDebugMonitorWithInterval(intervalSec); err != nil {
+		statusCode := errorStatus(err)
+
+		if errors.Is(err, service.ErrMonitoringNotActive) {
+			statusCode = http.StatusConflict
+		}
+
+		ErrorResponse(c, statusCode, errorCode(err, statusCode), fmt.Sprintf("Erro ao iniciar monitor de depuração: %v", err), nil)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":      "Monitor de depuração iniciado",
+		"interval_sec": intervalSec,
+	})
+}
+
+// StopDebugMonitor interrompe o monitor de depuração
+func (h *PLCHandler) StopDebugMonitor(c *gin.Context) {
+	h.plcService.StopDebugMonitor()
+	c.JSON(http.StatusOK, gin.H{"message": "Monitor de depuração parado"})
+}
+
 // getIDFromParams extrai o ID dos parâmetros da URL
 func (h *PLCHandler) getIDFromParams(c *gin.Context) (int, error) {
 	idStr := c.Param("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		ErrorResponse(c, http.StatusBadRequest, domain.ErrCodeInvalidParameter, "ID inválido", nil)
 		return 0, err
 	}
 
 	if id <= 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "ID deve ser maior que zero"})
+		ErrorResponse(c, http.StatusBadRequest, domain.ErrCodeInvalidParameter, "ID deve ser maior que zero", nil)
 		return 0, fmt.Errorf("ID inválido")
 	}
 
@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"app_padrao/internal/domain"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// fakePLCService implementa apenas os métodos usados em cada teste
+type fakePLCService struct {
+	domain.PLCService
+	searchFilter domain.TagSearchFilter
+}
+
+func (s *fakePLCService) SearchTags(ctx context.Context, filter domain.TagSearchFilter) ([]domain.PLCTag, int, error) {
+	s.searchFilter = filter
+	return []domain.PLCTag{}, 0, nil
+}
+
+func TestSearchTagsEchoesNormalizedPagination(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		query        string
+		wantPage     int
+		wantPageSize int
+	}{
+		{"", 1, 20},
+		{"?page=0&pageSize=0", 1, 20},
+		{"?page=-3&pageSize=500", 1, 100},
+		{"?page=abc&pageSize=xyz", 1, 20},
+		{"?page=4&pageSize=50", 4, 50},
+	}
+
+	for _, tt := range tests {
+		svc := &fakePLCService{}
+		router := gin.New()
+		router.GET("/tags/search", NewPLCHandler(svc).SearchTags)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/tags/search"+tt.query, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("%q: status = %d, esperado 200", tt.query, w.Code)
+		}
+
+		var body struct {
+			Page     int `json:"page"`
+			PageSize int `json:"pageSize"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatalf("%q: resposta inválida: %v", tt.query, err)
+		}
+		if body.Page != tt.wantPage || body.PageSize != tt.wantPageSize {
+			t.Errorf("%q: page/pageSize = %d/%d, esperado %d/%d",
+				tt.query, body.Page, body.PageSize, tt.wantPage, tt.wantPageSize)
+		}
+		if svc.searchFilter.Page != body.Page || svc.searchFilter.PageSize != body.PageSize {
+			t.Errorf("%q: filtro enviado ao serviço = %d/%d, diferente da resposta",
+				tt.query, svc.searchFilter.Page, svc.searchFilter.PageSize)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+// internal/api/handler/plcalarm.go
+package handler
+
+import (
+	"app_padrao/internal/domain"
+	"app_padrao/internal/service"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetActiveAlarms lista as ocorrências de alarme ativas. Aceita
+// min_severity (info, warning ou critical).
+func (h *PLCHandler) GetActiveAlarms(c *gin.Context) {
+	events, err := h.plcService.GetActiveAlarms(c.Query("min_severity"))
+	if err != nil {
+		statusCode := errorStatus(err)
+		if errors.Is(err, domain.ErrInvalidAlarmSeverity) {
+			statusCode = http.StatusBadRequest
+		} else if errors.Is(err, service.ErrAlarmsNotConfigured) {
+			statusCode = http.StatusServiceUnavailable
+		}
+		ErrorResponse(c, statusCode, errorCode(err, statusCode), fmt.Sprintf("Erro ao buscar alarmes: %v", err), nil)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"alarms": events,
+		"count":  len(events),
+	})
+}
+
+// AcknowledgeAlarm reconhece uma ocorrência de alarme com um comentário opcional
+func (h *PLCHandler) AcknowledgeAlarm(c *gin.Context) {
+	eventID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || eventID <= 0 {
+		ErrorResponse(c, http.StatusBadRequest, domain.ErrCodeInvalidParameter, "ID de alarme inválido", nil)
+		return
+	}
+
+	var req struct {
+		Comment string `json:"comment"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			ErrorResponse(c, http.StatusBadRequest, errorCode(err, http.StatusBadRequest), fmt.Sprintf("Erro ao processar dados: %v", err), nil)
+			return
+		}
+	}
+
+	userID, _ := c.Get("userID")
+
+	event, err := h.plcService.AcknowledgeAlarm(eventID, userID.(int), req.Comment)
+	if err != nil {
+		statusCode := errorStatus(err)
+		if errors.Is(err, domain.ErrAlarmEventNotFound) {
+			statusCode = http.StatusNotFound
+		} else if errors.Is(err, domain.ErrAlarmAlreadyAcknowledged) {
+			statusCode = http.StatusConflict
+		} else if errors.Is(err, service.ErrAlarmsNotConfigured) {
+			statusCode = http.StatusServiceUnavailable
+		}
+		ErrorResponse(c, statusCode, errorCode(err, statusCode), fmt.Sprintf("Erro ao reconhecer alarme: %v", err), nil)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Alarme reconhecido",
+		"alarm":   event,
+	})
+}
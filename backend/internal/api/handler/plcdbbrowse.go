@@ -0,0 +1,119 @@
+// internal/api/handler/plcdbbrowse.go
+package handler
+
+import (
+	"app_padrao/internal/domain"
+	"app_padrao/internal/service"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// dbBrowseErrorStatus mapeia os erros da navegação de DBs para códigos HTTP
+func dbBrowseErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrInvalidDBRange), errors.Is(err, domain.ErrInvalidDBBytes):
+		return http.StatusBadRequest
+	case errors.Is(err, domain.ErrPLCNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrDBPokeDisabled):
+		return http.StatusForbidden
+	case errors.Is(err, service.ErrMonitoringNotActive), errors.Is(err, service.ErrPLCNotConnected):
+		return http.StatusServiceUnavailable
+	}
+	return errorStatus(err)
+}
+
+// getDBParams lê o ID do PLC e o número do DB da rota
+func (h *PLCHandler) getDBParams(c *gin.Context) (int, int, bool) {
+	plcID, err := h.getIDFromParams(c)
+	if err != nil {
+		return 0, 0, false
+	}
+
+	dbNumber, err := strconv.Atoi(c.Param("dbNumber"))
+	if err != nil || dbNumber <= 0 {
+		ErrorResponse(c, http.StatusBadRequest, domain.ErrCodeInvalidParameter, "Número do DB inválido", nil)
+		return 0, 0, false
+	}
+
+	return plcID, dbNumber, true
+}
+
+// BrowseDataBlock lê uma faixa de bytes de um DB
+// (?offset=0&length=100, no máximo domain.MaxDBBrowseLength bytes)
+func (h *PLCHandler) BrowseDataBlock(c *gin.Context) {
+	plcID, dbNumber, ok := h.getDBParams(c)
+	if !ok {
+		return
+	}
+
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil {
+		ErrorResponse(c, http.StatusBadRequest, domain.ErrCodeInvalidParameter, "offset inválido", nil)
+		return
+	}
+	length, err := strconv.Atoi(c.DefaultQuery("length", strconv.Itoa(domain.DefaultDBBrowseLength)))
+	if err != nil {
+		ErrorResponse(c, http.StatusBadRequest, domain.ErrCodeInvalidParameter, "length inválido", nil)
+		return
+	}
+
+	result, err := h.plcService.BrowseDataBlock(plcID, dbNumber, offset, length)
+	if err != nil {
+		statusCode := dbBrowseErrorStatus(err)
+		ErrorResponse(c, statusCode, errorCode(err, statusCode), fmt.Sprintf("Erro ao ler DB: %v", err), nil)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// PokeDataBlock grava bytes brutos em um DB ({"offset": 10, "bytes": "0x0A0B"}).
+// Disponível apenas com PLC_ENABLE_DB_POKE=true e fora do modo release do gin
+// (depuração e desenvolvimento).
+func (h *PLCHandler) PokeDataBlock(c *gin.Context) {
+	if gin.Mode() == gin.ReleaseMode {
+		ErrorResponse(c, http.StatusForbidden, errorCode(service.ErrDBPokeDisabled, http.StatusForbidden),
+			"Gravação de bytes brutos indisponível em modo release", nil)
+		return
+	}
+
+	plcID, dbNumber, ok := h.getDBParams(c)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		Offset *int   `json:"offset" binding:"required"`
+		Bytes  string `json:"bytes" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		ErrorResponse(c, http.StatusBadRequest, domain.ErrCodeInvalidPayload, "Dados inválidos", err.Error())
+		return
+	}
+
+	data, err := domain.ParseDBBytes(input.Bytes)
+	if err != nil {
+		ErrorResponse(c, http.StatusBadRequest, domain.ErrCodeInvalidPayload, err.Error(), nil)
+		return
+	}
+
+	userID, _ := c.Get("userID")
+	uid, _ := userID.(int)
+
+	if err := h.plcService.PokeDataBlock(plcID, dbNumber, *input.Offset, data, uid); err != nil {
+		statusCode := dbBrowseErrorStatus(err)
+		ErrorResponse(c, statusCode, errorCode(err, statusCode), fmt.Sprintf("Erro ao gravar DB: %v", err), nil)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Bytes gravados com sucesso",
+		"offset":  *input.Offset,
+		"length":  len(data),
+	})
+}
@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"app_padrao/internal/domain"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// fakePokePLCService registra as gravações de bytes brutos recebidas
+type fakePokePLCService struct {
+	domain.PLCService
+	pokes int
+}
+
+func (s *fakePokePLCService) PokeDataBlock(plcID, dbNumber, offset int, data []byte, userID int) error {
+	s.pokes++
+	return nil
+}
+
+func TestPokeDataBlockRefusedInReleaseMode(t *testing.T) {
+	t.Cleanup(func() { gin.SetMode(gin.TestMode) })
+
+	tests := []struct {
+		mode      string
+		wantCode  int
+		wantPokes int
+	}{
+		{gin.ReleaseMode, http.StatusForbidden, 0},
+		{gin.DebugMode, http.StatusOK, 1},
+		{gin.TestMode, http.StatusOK, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			gin.SetMode(tt.mode)
+			plcService := &fakePokePLCService{}
+			h := NewPLCHandler(plcService)
+
+			router := gin.New()
+			router.PUT("/plc/:id/db/:dbNumber/poke", h.PokeDataBlock)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPut, "/plc/1/db/10/poke", strings.NewReader(`{"offset":10,"bytes":"0x0A0B"}`))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantCode {
+				t.Errorf("status = %d, esperado %d: %s", w.Code, tt.wantCode, w.Body.String())
+			}
+			if plcService.pokes != tt.wantPokes {
+				t.Errorf("gravações = %d, esperado %d", plcService.pokes, tt.wantPokes)
+			}
+		})
+	}
+}
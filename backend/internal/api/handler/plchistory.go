@@ -0,0 +1,374 @@
+// internal/api/handler/plchistory.go
+package handler
+
+import (
+	"app_padrao/internal/domain"
+	"app_padrao/internal/service"
+	"app_padrao/pkg/influx"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultInfluxMeasurement é o measurement usado quando a requisição não informa um
+const defaultInfluxMeasurement = "plc_tags"
+
+// maxHistoryCSVRows é o padrão e o máximo de max_rows na exportação em CSV
+const maxHistoryCSVRows = 50000
+
+// GetHistoryQueue retorna capacidade, ocupação e taxa de descarte da fila de
+// gravação do histórico
+func (h *PLCHandler) GetHistoryQueue(c *gin.Context) {
+	c.JSON(http.StatusOK, h.plcService.GetHistoryQueueStats())
+}
+
+// GetHistoryStats retorna o tamanho do histórico de tags e a política de
+// retenção em vigor
+func (h *PLCHandler) GetHistoryStats(c *gin.Context) {
+	stats, err := h.plcService.GetHistoryStats()
+	if err != nil {
+		statusCode := errorStatus(err)
+		if errors.Is(err, service.ErrHistoryNotConfigured) {
+			statusCode = http.StatusServiceUnavailable
+		}
+
+		ErrorResponse(c, statusCode, errorCode(err, statusCode), fmt.Sprintf("Erro ao consultar histórico: %v", err), nil)
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
+// defaultHistoryWindow é o intervalo entre pontos quando a interpolação é usada
+// e a requisição não informa window
+const defaultHistoryWindow = time.Minute
+
+// GetTagHistory retorna o histórico de uma tag. Parâmetros opcionais:
+// from/to (RFC3339), interpolate (linear, stepwise ou none) e window
+// (duração Go, ex.: 30s, 5m) com o espaçamento da série interpolada
+func (h *PLCHandler) GetTagHistory(c *gin.Context) {
+	plcID, err := h.getIDFromParams(c)
+	if err != nil {
+		return
+	}
+
+	tagID, err := strconv.Atoi(c.Param("tagID"))
+	if err != nil || tagID <= 0 {
+		ErrorResponse(c, http.StatusBadRequest, domain.ErrCodeInvalidParameter, "ID da tag inválido", nil)
+		return
+	}
+
+	from, to, ok := parseHistoryRange(c)
+	if !ok {
+		return
+	}
+
+	window := defaultHistoryWindow
+	if raw := c.Query("window"); raw != "" {
+		window, err = time.ParseDuration(raw)
+		if err != nil {
+			ErrorResponse(c, http.StatusBadRequest, domain.ErrCodeInvalidParameter, "window deve ser uma duração válida (ex.: 30s, 5m)", nil)
+			return
+		}
+	}
+
+	mode := strings.ToLower(c.DefaultQuery("interpolate", domain.InterpolationNone))
+
+	values, err := h.plcService.GetTagHistory(c.Request.Context(), plcID, tagID, from, to, window, mode)
+	if err != nil {
+		statusCode := errorStatus(err)
+		if errors.Is(err, domain.ErrPLCNotFound) || errors.Is(err, domain.ErrPLCTagNotFound) {
+			statusCode = http.StatusNotFound
+		} else if errors.Is(err, service.ErrInvalidHistoryRange) ||
+			errors.Is(err, service.ErrInvalidInterpolation) ||
+			errors.Is(err, service.ErrInterpolationNotAllowed) ||
+			errors.Is(err, service.ErrInvalidHistoryWindow) ||
+			errors.Is(err, service.ErrTooManyHistoryPoints) {
+			statusCode = http.StatusBadRequest
+		} else if errors.Is(err, service.ErrHistoryNotConfigured) {
+			statusCode = http.StatusServiceUnavailable
+		}
+
+		ErrorResponse(c, statusCode, errorCode(err, statusCode), fmt.Sprintf("Erro ao buscar histórico: %v", err), nil)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"plc_id":      plcID,
+		"tag_id":      tagID,
+		"from":        from.UTC(),
+		"to":          to.UTC(),
+		"interpolate": mode,
+		"values":      values,
+	})
+}
+
+// ExportTagHistoryInflux exporta o histórico de uma tag em line protocol do InfluxDB
+func (h *PLCHandler) ExportTagHistoryInflux(c *gin.Context) {
+	plcID, err := h.getIDFromParams(c)
+	if err != nil {
+		return
+	}
+
+	tagID, err := strconv.Atoi(c.Param("tagID"))
+	if err != nil || tagID <= 0 {
+		ErrorResponse(c, http.StatusBadRequest, domain.ErrCodeInvalidParameter, "ID da tag inválido", nil)
+		return
+	}
+
+	h.streamInfluxHistory(c, []int{plcID}, tagID)
+}
+
+// ExportHistoryInflux exporta o histórico de todas as tags dos PLCs informados
+// em plc_ids (separados por vírgula) em line protocol do InfluxDB
+func (h *PLCHandler) ExportHistoryInflux(c *gin.Context) {
+	plcIDs, ok := plcIDsQuery(c)
+	if !ok {
+		return
+	}
+
+	h.streamInfluxHistory(c, plcIDs, 0)
+}
+
+// ExportTagHistoryCSV exporta o histórico bruto de uma tag em CSV
+// (recorded_at,value e, com include_quality=true, quality). O arquivo é
+// escrito lote a lote na resposta; intervalos com mais de max_rows registros
+// (padrão e máximo 50000) são recusados com 413.
+func (h *PLCHandler) ExportTagHistoryCSV(c *gin.Context) {
+	tagID, err := h.getIDFromParams(c)
+	if err != nil {
+		return
+	}
+
+	from, to, ok := parseHistoryRange(c)
+	if !ok {
+		return
+	}
+
+	maxRows := maxHistoryCSVRows
+	if raw := c.Query("max_rows"); raw != "" {
+		maxRows, err = strconv.Atoi(raw)
+		if err != nil || maxRows <= 0 || maxRows > maxHistoryCSVRows {
+			ErrorResponse(c, http.StatusBadRequest, domain.ErrCodeInvalidParameter,
+				fmt.Sprintf("max_rows deve estar entre 1 e %d", maxHistoryCSVRows), nil)
+			return
+		}
+	}
+
+	includeQuality := c.Query("include_quality") == "true"
+	writer := csv.NewWriter(c.Writer)
+	started := false
+
+	err = h.plcService.ExportTagHistoryCSV(tagID, from, to, maxRows, func(entries []domain.TagHistoryEntry) error {
+		if !started {
+			startHistoryCSV(c, writer, tagID, from, to, includeQuality)
+			started = true
+		}
+
+		for _, entry := range entries {
+			record := []string{entry.RecordedAt.UTC().Format(time.RFC3339Nano), historyCSVValue(entry.Value)}
+			if includeQuality {
+				// Apenas leituras com qualidade good são gravadas no histórico
+				record = append(record, domain.QualityGood)
+			}
+			if err := writer.Write(record); err != nil {
+				return err
+			}
+		}
+
+		writer.Flush()
+		return writer.Error()
+	})
+
+	if err == nil {
+		if !started {
+			// Nenhum registro no intervalo: apenas o cabeçalho
+			startHistoryCSV(c, writer, tagID, from, to, includeQuality)
+			writer.Flush()
+		}
+		return
+	}
+
+	if started {
+		log.Printf("Erro durante exportação de histórico em CSV: %v", err)
+		return
+	}
+
+	statusCode := errorStatus(err)
+	if errors.Is(err, domain.ErrPLCTagNotFound) || errors.Is(err, service.ErrTagNotFound) {
+		statusCode = http.StatusNotFound
+	} else if errors.Is(err, service.ErrInvalidHistoryRange) {
+		statusCode = http.StatusBadRequest
+	} else if errors.Is(err, service.ErrHistoryExportTooLarge) {
+		statusCode = http.StatusRequestEntityTooLarge
+	} else if errors.Is(err, service.ErrHistoryNotConfigured) {
+		statusCode = http.StatusServiceUnavailable
+	}
+
+	ErrorResponse(c, statusCode, errorCode(err, statusCode), fmt.Sprintf("Erro ao exportar histórico: %v", err), nil)
+}
+
+// startHistoryCSV envia os cabeçalhos HTTP e a linha de cabeçalho do CSV
+func startHistoryCSV(c *gin.Context, writer *csv.Writer, tagID int, from, to time.Time, includeQuality bool) {
+	const fileTime = "20060102T150405Z"
+	c.Header("Content-Type", "text/csv")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="tag_%d_%s_%s.csv"`,
+		tagID, from.UTC().Format(fileTime), to.UTC().Format(fileTime)))
+	c.Status(http.StatusOK)
+
+	header := []string{"recorded_at", "value"}
+	if includeQuality {
+		header = append(header, "quality")
+	}
+	writer.Write(header)
+}
+
+// historyCSVValue formata o valor de um registro do histórico para uma célula
+// do CSV. Valores compostos (tags calculadas, words com bits desempacotados)
+// são serializados como JSON.
+func historyCSVValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
+
+// plcIDsQuery lê o parâmetro obrigatório plc_ids (IDs separados por vírgula)
+func plcIDsQuery(c *gin.Context) ([]int, bool) {
+	var plcIDs []int
+	for _, part := range strings.Split(c.Query("plc_ids"), ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		id, err := strconv.Atoi(part)
+		if err != nil || id <= 0 {
+			ErrorResponse(c, http.StatusBadRequest, domain.ErrCodeInvalidParameter, fmt.Sprintf("ID de PLC inválido em plc_ids: %q", part), nil)
+			return nil, false
+		}
+		plcIDs = append(plcIDs, id)
+	}
+
+	if len(plcIDs) == 0 {
+		ErrorResponse(c, http.StatusBadRequest, domain.ErrCodeInvalidParameter, "plc_ids é obrigatório", nil)
+		return nil, false
+	}
+
+	return plcIDs, true
+}
+
+// streamInfluxHistory escreve o histórico lote a lote na resposta. Erros que
+// ocorrem antes do primeiro lote viram uma resposta JSON; depois disso o
+// streaming é apenas interrompido.
+func (h *PLCHandler) streamInfluxHistory(c *gin.Context, plcIDs []int, tagID int) {
+	from, to, ok := parseHistoryRange(c)
+	if !ok {
+		return
+	}
+
+	measurement := c.DefaultQuery("measurement", defaultInfluxMeasurement)
+	if strings.TrimSpace(measurement) == "" {
+		ErrorResponse(c, http.StatusBadRequest, domain.ErrCodeInvalidParameter, "measurement não pode ser vazio", nil)
+		return
+	}
+
+	encoder := influx.NewLineProtocolEncoder()
+	started := false
+
+	err := h.plcService.ExportTagHistory(plcIDs, tagID, from, to, func(entries []domain.TagHistoryEntry) error {
+		if !started {
+			c.Header("Content-Type", "application/octet-stream")
+			c.Status(http.StatusOK)
+			started = true
+		}
+
+		for _, entry := range entries {
+			tags := map[string]string{
+				"plc_id":   strconv.Itoa(entry.PLCID),
+				"tag_id":   strconv.Itoa(entry.TagID),
+				"tag_name": entry.TagName,
+			}
+			fields := map[string]interface{}{"value": entry.Value}
+
+			err := encoder.Encode(c.Writer, measurement, tags, fields, entry.RecordedAt)
+			if err != nil && !errors.Is(err, influx.ErrNoFields) {
+				return err
+			}
+		}
+
+		c.Writer.Flush()
+		return nil
+	})
+
+	if err == nil {
+		if !started {
+			// Nenhum registro no intervalo: resposta vazia
+			c.Header("Content-Type", "application/octet-stream")
+			c.Status(http.StatusOK)
+		}
+		return
+	}
+
+	if started {
+		log.Printf("Erro durante exportação de histórico em line protocol: %v", err)
+		return
+	}
+
+	statusCode := errorStatus(err)
+	if errors.Is(err, domain.ErrPLCNotFound) || errors.Is(err, domain.ErrPLCTagNotFound) {
+		statusCode = http.StatusNotFound
+	} else if errors.Is(err, service.ErrInvalidHistoryRange) {
+		statusCode = http.StatusBadRequest
+	} else if errors.Is(err, service.ErrHistoryNotConfigured) {
+		statusCode = http.StatusServiceUnavailable
+	}
+
+	ErrorResponse(c, statusCode, errorCode(err, statusCode), fmt.Sprintf("Erro ao exportar histórico: %v", err), nil)
+}
+
+// parseHistoryRange lê os parâmetros from/to (RFC3339). Sem to, usa o momento
+// atual; sem from, as 24 horas anteriores a to.
+func parseHistoryRange(c *gin.Context) (time.Time, time.Time, bool) {
+	to := time.Now()
+	if raw := c.Query("to"); raw != "" {
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			ErrorResponse(c, http.StatusBadRequest, domain.ErrCodeInvalidParameter, "to deve estar no formato RFC3339", nil)
+			return time.Time{}, time.Time{}, false
+		}
+		to = parsed
+	}
+
+	from := to.Add(-24 * time.Hour)
+	if raw := c.Query("from"); raw != "" {
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			ErrorResponse(c, http.StatusBadRequest, domain.ErrCodeInvalidParameter, "from deve estar no formato RFC3339", nil)
+			return time.Time{}, time.Time{}, false
+		}
+		from = parsed
+	}
+
+	return from, to, true
+}
@@ -0,0 +1,63 @@
+// internal/api/handler/plcinitialvalues.go
+package handler
+
+import (
+	"app_padrao/internal/domain"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// pushRequestHeader pede o push dos valores das tags junto com o PLC
+const pushRequestHeader = "X-Request-Push"
+
+// GetInitialTagValues retorna apenas tag_id, value e timestamp das tags do
+// PLC, sem metadados, para popular o estado de clientes recém-conectados
+func (h *PLCHandler) GetInitialTagValues(c *gin.Context) {
+	id, err := h.getIDFromParams(c)
+	if err != nil {
+		return
+	}
+
+	tags, err := h.plcService.GetPLCTags(id)
+	if err != nil {
+		ErrorResponse(c, errorStatus(err), errorCode(err, errorStatus(err)), fmt.Sprintf("Erro ao buscar valores das tags: %v", err), nil)
+		return
+	}
+
+	h.recordTagAccess(c, tags...)
+	c.JSON(http.StatusOK, gin.H{"values": domain.NewInitialTagValues(tags)})
+}
+
+// pushInitialTagValues envia por HTTP/2 server push os valores das tags do
+// PLC quando o cliente pede com X-Request-Push: tags. Sem HTTP/2 (ou com o
+// push desabilitado pelo cliente), não faz nada: o cliente busca os valores
+// normalmente.
+func (h *PLCHandler) pushInitialTagValues(c *gin.Context, plcID int) {
+	if !strings.EqualFold(c.GetHeader(pushRequestHeader), "tags") {
+		return
+	}
+
+	pusher := c.Writer.Pusher()
+	if pusher == nil {
+		return
+	}
+
+	// Mesmo prefixo da requisição (/api ou /api/v1)
+	target := strings.TrimSuffix(c.FullPath(), "/:id") + "/" + strconv.Itoa(plcID) + "/tags/values/initial"
+
+	// A requisição enviada por push passa pela autenticação como as demais
+	header := http.Header{}
+	if auth := c.GetHeader("Authorization"); auth != "" {
+		header.Set("Authorization", auth)
+	}
+
+	if err := pusher.Push(target, &http.PushOptions{Method: http.MethodGet, Header: header}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		log.Printf("Aviso: falha no push de %s: %v", target, err)
+	}
+}
@@ -0,0 +1,121 @@
+// internal/api/handler/plcscangroup.go
+package handler
+
+import (
+	"app_padrao/internal/domain"
+	"app_padrao/internal/service"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// scanGroupErrorStatus mapeia os erros de grupos de varredura para códigos HTTP
+func scanGroupErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrScanGroupNotFound), errors.Is(err, domain.ErrPLCNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrInvalidScanGroupName), errors.Is(err, domain.ErrScanGroupTagMismatch):
+		return http.StatusBadRequest
+	case errors.Is(err, domain.ErrScanGroupSpansDBs), errors.Is(err, domain.ErrScanGroupExceedsPDU):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, service.ErrScanGroupsNotConfigured):
+		return http.StatusServiceUnavailable
+	}
+	return errorStatus(err)
+}
+
+// respondScanGroupError responde com o status e o código do erro de grupo de varredura
+func respondScanGroupError(c *gin.Context, message string, err error) {
+	statusCode := scanGroupErrorStatus(err)
+	ErrorResponse(c, statusCode, errorCode(err, statusCode), fmt.Sprintf("%s: %v", message, err), nil)
+}
+
+// GetScanGroups lista os grupos de varredura do PLC e suas tags
+func (h *PLCHandler) GetScanGroups(c *gin.Context) {
+	plcID, err := h.getIDFromParams(c)
+	if err != nil {
+		return
+	}
+
+	groups, err := h.plcService.GetScanGroups(plcID)
+	if err != nil {
+		respondScanGroupError(c, "Erro ao buscar grupos de varredura", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"scan_groups": groups})
+}
+
+// CreateScanGroup cadastra um grupo de varredura vazio no PLC
+func (h *PLCHandler) CreateScanGroup(c *gin.Context) {
+	plcID, err := h.getIDFromParams(c)
+	if err != nil {
+		return
+	}
+
+	var group domain.PLCScanGroup
+	if err := c.ShouldBindJSON(&group); err != nil {
+		ErrorResponse(c, http.StatusBadRequest, domain.ErrCodeInvalidPayload, "Dados inválidos", err.Error())
+		return
+	}
+	group.PLCID = plcID
+
+	userID, _ := c.Get("userID")
+	uid, _ := userID.(int)
+
+	id, err := h.plcService.CreateScanGroup(group, uid)
+	if err != nil {
+		respondScanGroupError(c, "Erro ao criar grupo de varredura", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"id": id, "message": "Grupo de varredura criado com sucesso"})
+}
+
+// AssignScanGroupTags substitui as tags do grupo de varredura. Lista vazia
+// esvazia o grupo.
+func (h *PLCHandler) AssignScanGroupTags(c *gin.Context) {
+	id, err := h.getIDFromParams(c)
+	if err != nil {
+		return
+	}
+
+	var input struct {
+		TagIDs []int `json:"tag_ids"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		ErrorResponse(c, http.StatusBadRequest, domain.ErrCodeInvalidPayload, "Dados inválidos", err.Error())
+		return
+	}
+
+	userID, _ := c.Get("userID")
+	uid, _ := userID.(int)
+
+	group, err := h.plcService.AssignScanGroupTags(id, input.TagIDs, uid)
+	if err != nil {
+		respondScanGroupError(c, "Erro ao atribuir tags ao grupo de varredura", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"scan_group": group, "message": "Tags do grupo de varredura atualizadas com sucesso"})
+}
+
+// DeleteScanGroup remove um grupo de varredura; suas tags voltam à leitura individual
+func (h *PLCHandler) DeleteScanGroup(c *gin.Context) {
+	id, err := h.getIDFromParams(c)
+	if err != nil {
+		return
+	}
+
+	userID, _ := c.Get("userID")
+	uid, _ := userID.(int)
+
+	if err := h.plcService.DeleteScanGroup(id, uid); err != nil {
+		respondScanGroupError(c, "Erro ao excluir grupo de varredura", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Grupo de varredura excluído com sucesso"})
+}
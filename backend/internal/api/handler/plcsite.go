@@ -0,0 +1,144 @@
+// internal/api/handler/plcsite.go
+package handler
+
+import (
+	"app_padrao/internal/domain"
+	"app_padrao/internal/service"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// siteErrorStatus mapeia os erros de sites para códigos HTTP
+func siteErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrPLCSiteNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrInvalidSiteName), errors.Is(err, domain.ErrInvalidSiteTimezone):
+		return http.StatusBadRequest
+	case errors.Is(err, service.ErrSitesNotConfigured), errors.Is(err, service.ErrMonitoringNotActive):
+		return http.StatusServiceUnavailable
+	}
+	return errorStatus(err)
+}
+
+// plcSiteRefStatus mapeia os erros do site informado no cadastro de um PLC
+func plcSiteRefStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrPLCSiteNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrSitesNotConfigured):
+		return http.StatusServiceUnavailable
+	}
+	return errorStatus(err)
+}
+
+// respondSiteError responde com o status e o código do erro de site
+func respondSiteError(c *gin.Context, message string, err error) {
+	statusCode := siteErrorStatus(err)
+	ErrorResponse(c, statusCode, errorCode(err, statusCode), fmt.Sprintf("%s: %v", message, err), nil)
+}
+
+// GetPLCSites lista os sites (plantas) de PLCs
+func (h *PLCHandler) GetPLCSites(c *gin.Context) {
+	sites, err := h.plcService.GetSites()
+	if err != nil {
+		respondSiteError(c, "Erro ao buscar sites", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"sites": sites})
+}
+
+// CreatePLCSite cadastra um site. monitoring_enabled é true quando omitido.
+func (h *PLCHandler) CreatePLCSite(c *gin.Context) {
+	site := domain.PLCSite{MonitoringEnabled: true}
+	if err := c.ShouldBindJSON(&site); err != nil {
+		ErrorResponse(c, http.StatusBadRequest, domain.ErrCodeInvalidPayload, "Dados inválidos", err.Error())
+		return
+	}
+
+	id, err := h.plcService.CreateSite(site)
+	if err != nil {
+		respondSiteError(c, "Erro ao criar site", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"id": id, "message": "Site criado com sucesso"})
+}
+
+// UpdatePLCSite altera um site. monitoring_enabled = false pausa o
+// monitoramento de todos os PLCs do site.
+func (h *PLCHandler) UpdatePLCSite(c *gin.Context) {
+	id, err := h.getIDFromParams(c)
+	if err != nil {
+		return
+	}
+
+	site := domain.PLCSite{MonitoringEnabled: true}
+	if err := c.ShouldBindJSON(&site); err != nil {
+		ErrorResponse(c, http.StatusBadRequest, domain.ErrCodeInvalidPayload, "Dados inválidos", err.Error())
+		return
+	}
+	site.ID = id
+
+	if err := h.plcService.UpdateSite(site); err != nil {
+		respondSiteError(c, "Erro ao atualizar site", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Site atualizado com sucesso"})
+}
+
+// DeletePLCSite remove um site; seus PLCs ficam sem site
+func (h *PLCHandler) DeletePLCSite(c *gin.Context) {
+	id, err := h.getIDFromParams(c)
+	if err != nil {
+		return
+	}
+
+	if err := h.plcService.DeleteSite(id); err != nil {
+		respondSiteError(c, "Erro ao excluir site", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Site excluído com sucesso"})
+}
+
+// GetPLCSiteHealth agrega a saúde de todos os PLCs do site
+func (h *PLCHandler) GetPLCSiteHealth(c *gin.Context) {
+	id, err := h.getIDFromParams(c)
+	if err != nil {
+		return
+	}
+
+	health, err := h.plcService.GetSiteHealth(id)
+	if err != nil {
+		respondSiteError(c, "Erro ao verificar saúde do site", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"health": health,
+		"time":   time.Now().Format(time.RFC3339),
+	})
+}
+
+// siteIDQuery lê o filtro opcional site_id. Retorna false após responder 400.
+func siteIDQuery(c *gin.Context) (*int, bool) {
+	raw := c.Query("site_id")
+	if raw == "" {
+		return nil, true
+	}
+
+	siteID, err := strconv.Atoi(raw)
+	if err != nil || siteID <= 0 {
+		ErrorResponse(c, http.StatusBadRequest, domain.ErrCodeInvalidParameter, "site_id inválido", nil)
+		return nil, false
+	}
+	return &siteID, true
+}
@@ -0,0 +1,215 @@
+// internal/api/handler/plcstream.go
+package handler
+
+import (
+	"app_padrao/internal/domain"
+	"app_padrao/internal/service"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// sseRetryMillis é o intervalo de reconexão sugerido aos navegadores
+	sseRetryMillis = 5000
+	// sseKeepAliveInterval é o intervalo dos comentários que mantêm a conexão
+	// aberta em proxies que encerram conexões ociosas
+	sseKeepAliveInterval = 15 * time.Second
+)
+
+// StreamTags envia as leituras das tags dos PLCs em plc_ids como Server-Sent
+// Events, alternativa para clientes cujo proxy bloqueia WebSocket. O id de
+// cada evento é o timestamp da leitura em milissegundos: ao reconectar com
+// Last-Event-ID (ou last_event_id), as leituras gravadas no histórico depois
+// dele são reenviadas antes do fluxo ao vivo. Leituras próximas do momento da
+// reconexão podem chegar duplicadas; o id permite descartá-las.
+func (h *PLCHandler) StreamTags(c *gin.Context) {
+	plcIDs, ok := plcIDsQuery(c)
+	if !ok {
+		return
+	}
+
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("last_event_id")
+	}
+
+	var since time.Time
+	if lastEventID != "" {
+		millis, err := strconv.ParseInt(lastEventID, 10, 64)
+		if err != nil || millis <= 0 {
+			ErrorResponse(c, http.StatusBadRequest, domain.ErrCodeInvalidParameter, "Last-Event-ID deve ser um timestamp em milissegundos", nil)
+			return
+		}
+		since = time.UnixMilli(millis)
+	}
+
+	// Assinar antes do replay para não perder leituras entre os dois
+	values, err := h.plcService.SubscribeTagValues(c.Request.Context(), plcIDs)
+	if err != nil {
+		statusCode := errorStatus(err)
+		if errors.Is(err, domain.ErrPLCNotFound) {
+			statusCode = http.StatusNotFound
+		} else if errors.Is(err, service.ErrTooManyStreams) {
+			statusCode = http.StatusServiceUnavailable
+		}
+
+		ErrorResponse(c, statusCode, errorCode(err, statusCode), fmt.Sprintf("Erro ao iniciar streaming: %v", err), nil)
+		return
+	}
+
+	startEventStream(c)
+
+	if !since.IsZero() {
+		h.replayTagHistory(c, plcIDs, since)
+	}
+
+	keepAlive := time.NewTicker(sseKeepAliveInterval)
+	defer keepAlive.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case value, ok := <-values:
+			if !ok {
+				return false
+			}
+			if err := writeTagEvent(w, value); err != nil {
+				log.Printf("Erro ao enviar evento SSE: %v", err)
+				return false
+			}
+			return true
+		case <-keepAlive.C:
+			_, err := fmt.Fprint(w, ": keep-alive\n\n")
+			return err == nil
+		}
+	})
+}
+
+// StreamPLCEvents envia as mudanças de status e as leituras das tags do PLC
+// como Server-Sent Events (event: plc_status / event: tag_value). Cada
+// usuário pode manter apenas um streaming aberto por PLC.
+func (h *PLCHandler) StreamPLCEvents(c *gin.Context) {
+	plcID, err := h.getIDFromParams(c)
+	if err != nil {
+		return
+	}
+
+	h.streamPLCEvents(c, plcID)
+}
+
+// StreamAllPLCEvents envia os eventos de todos os PLCs (rota administrativa)
+func (h *PLCHandler) StreamAllPLCEvents(c *gin.Context) {
+	h.streamPLCEvents(c, 0)
+}
+
+// streamPLCEvents envia os eventos do PLC (0 = todos) até o cliente desconectar
+func (h *PLCHandler) streamPLCEvents(c *gin.Context, plcID int) {
+	userID, _ := c.Get("userID")
+	uid, _ := userID.(int)
+
+	plcEvents, err := h.plcService.SubscribePLCEvents(c.Request.Context(), plcID, uid)
+	if err != nil {
+		statusCode := errorStatus(err)
+		if errors.Is(err, domain.ErrPLCNotFound) {
+			statusCode = http.StatusNotFound
+		} else if errors.Is(err, service.ErrStreamAlreadyOpen) {
+			statusCode = http.StatusConflict
+		} else if errors.Is(err, service.ErrTooManyStreams) {
+			statusCode = http.StatusServiceUnavailable
+		}
+
+		ErrorResponse(c, statusCode, errorCode(err, statusCode), fmt.Sprintf("Erro ao iniciar streaming de eventos: %v", err), nil)
+		return
+	}
+
+	startEventStream(c)
+
+	keepAlive := time.NewTicker(sseKeepAliveInterval)
+	defer keepAlive.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case event, ok := <-plcEvents:
+			if !ok {
+				return false
+			}
+			data, err := json.Marshal(event.Data)
+			if err != nil {
+				log.Printf("Erro ao serializar evento SSE: %v", err)
+				return true
+			}
+			if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data); err != nil {
+				return false
+			}
+			return true
+		case <-keepAlive.C:
+			_, err := fmt.Fprint(w, ": ping\n\n")
+			return err == nil
+		}
+	})
+}
+
+// startEventStream remove o prazo de escrita da conexão, que a encerraria, e
+// envia os cabeçalhos do streaming SSE com o intervalo de reconexão
+func startEventStream(c *gin.Context) {
+	if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{}); err != nil {
+		log.Printf("Aviso: não foi possível remover o prazo de escrita do streaming: %v", err)
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	fmt.Fprintf(c.Writer, "retry: %d\n\n", sseRetryMillis)
+	c.Writer.Flush()
+}
+
+// replayTagHistory reenvia as leituras do histórico posteriores a since
+func (h *PLCHandler) replayTagHistory(c *gin.Context, plcIDs []int, since time.Time) {
+	from := since.Add(time.Millisecond)
+	to := time.Now()
+	if !from.Before(to) {
+		return
+	}
+
+	err := h.plcService.ExportTagHistory(plcIDs, 0, from, to, func(entries []domain.TagHistoryEntry) error {
+		for _, entry := range entries {
+			err := writeTagEvent(c.Writer, domain.TagValue{
+				PLCID:     entry.PLCID,
+				TagID:     entry.TagID,
+				Value:     entry.Value,
+				Timestamp: entry.RecordedAt,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		c.Writer.Flush()
+		return nil
+	})
+
+	if err != nil && !errors.Is(err, service.ErrHistoryNotConfigured) {
+		log.Printf("Erro ao reenviar histórico no streaming SSE: %v", err)
+	}
+}
+
+// writeTagEvent escreve uma leitura no formato SSE, com o timestamp em
+// milissegundos como id
+func writeTagEvent(w io.Writer, value domain.TagValue) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "id: %d\ndata: %s\n\n", value.Timestamp.UnixMilli(), data)
+	return err
+}
@@ -0,0 +1,95 @@
+// internal/api/handler/plctagaccesslog.go
+package handler
+
+import (
+	"app_padrao/internal/domain"
+	"app_padrao/internal/service"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// recordTagAccess registra no log de acesso a leitura das tags pelo usuário
+// logado. Sem log de acesso habilitado, o serviço ignora os registros.
+func (h *PLCHandler) recordTagAccess(c *gin.Context, tags ...domain.PLCTag) {
+	if len(tags) == 0 {
+		return
+	}
+	userID, _ := c.Get("userID")
+	uid, _ := userID.(int)
+
+	now := time.Now()
+	entries := make([]domain.TagAccessLog, 0, len(tags))
+	for _, tag := range tags {
+		entries = append(entries, domain.TagAccessLog{
+			UserID:     uid,
+			TagID:      tag.ID,
+			PLCID:      tag.PLCID,
+			AccessedAt: now,
+			Endpoint:   c.Request.Method + " " + c.FullPath(),
+			IPAddress:  c.ClientIP(),
+		})
+	}
+	h.plcService.RecordTagAccess(entries)
+}
+
+// GetTagAccessLog consulta o log de acesso às tags.
+// Parâmetros opcionais: user_id, tag_id, from e to (RFC3339) e limit.
+func (h *PLCHandler) GetTagAccessLog(c *gin.Context) {
+	var filter domain.TagAccessLogFilter
+
+	for _, param := range []struct {
+		name  string
+		value *int
+	}{
+		{"user_id", &filter.UserID},
+		{"tag_id", &filter.TagID},
+		{"limit", &filter.Limit},
+	} {
+		raw := c.Query(param.name)
+		if raw == "" {
+			continue
+		}
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 {
+			ErrorResponse(c, http.StatusBadRequest, domain.ErrCodeInvalidParameter, param.name+" deve ser um inteiro positivo", nil)
+			return
+		}
+		*param.value = n
+	}
+
+	for _, param := range []struct {
+		name  string
+		value *time.Time
+	}{
+		{"from", &filter.From},
+		{"to", &filter.To},
+	} {
+		raw := c.Query(param.name)
+		if raw == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			ErrorResponse(c, http.StatusBadRequest, domain.ErrCodeInvalidParameter, param.name+" deve estar no formato RFC3339", nil)
+			return
+		}
+		*param.value = parsed
+	}
+
+	entries, err := h.plcService.GetTagAccessLog(filter)
+	if err != nil {
+		statusCode := errorStatus(err)
+		if errors.Is(err, service.ErrAccessLogNotConfigured) {
+			statusCode = http.StatusServiceUnavailable
+		}
+		ErrorResponse(c, statusCode, errorCode(err, statusCode), fmt.Sprintf("Erro ao consultar log de acesso: %v", err), nil)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"entries": entries, "total": len(entries)})
+}
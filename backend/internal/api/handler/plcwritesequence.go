@@ -0,0 +1,56 @@
+// internal/api/handler/plcwritesequence.go
+package handler
+
+import (
+	"app_padrao/internal/domain"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// writeSequenceErrorStatus mapeia os erros de sequências de escritas para códigos HTTP
+func writeSequenceErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrWriteSequenceEmpty), errors.Is(err, domain.ErrWriteSequenceTooLong),
+		errors.Is(err, domain.ErrWriteSequenceInvalid), errors.Is(err, domain.ErrInvalidScaledValue):
+		return http.StatusBadRequest
+	case errors.Is(err, domain.ErrWriteSequenceNoOriginal):
+		return http.StatusConflict
+	}
+	return tagDependencyErrorStatus(err)
+}
+
+// WriteTagSequence escreve várias tags em ordem, com rollback opcional
+//
+//	{"steps": [{"tag_name": "Setpoint", "value": 10}, {"tag_name": "Partir", "value": true}],
+//	 "rollback_on_failure": true}
+//
+// Em caso de falha, o resultado da sequência vai em details.
+func (h *PLCHandler) WriteTagSequence(c *gin.Context) {
+	var input struct {
+		Steps             []domain.WriteStep `json:"steps" binding:"required"`
+		RollbackOnFailure bool               `json:"rollback_on_failure"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		ErrorResponse(c, http.StatusBadRequest, domain.ErrCodeInvalidPayload, "Dados inválidos", err.Error())
+		return
+	}
+
+	userID, _ := c.Get("userID")
+	writerID, _ := userID.(int)
+
+	result, err := h.plcService.WriteSequence(input.Steps, input.RollbackOnFailure, writerID)
+	if err != nil {
+		statusCode := writeSequenceErrorStatus(err)
+		var details interface{}
+		if result.FailedStep != nil {
+			details = result
+		}
+		ErrorResponse(c, statusCode, errorCode(err, statusCode), fmt.Sprintf("Erro ao executar sequência: %v", err), details)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
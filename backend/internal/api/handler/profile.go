@@ -3,6 +3,7 @@ package handler
 
 import (
 	"app_padrao/internal/domain"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -33,7 +34,7 @@ func NewProfileHandler(profileService domain.ProfileService, userService domain.
 func (h *ProfileHandler) GetProfile(c *gin.Context) {
 	userID, exists := c.Get("userID")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
+		ErrorResponse(c, http.StatusUnauthorized, domain.ErrCodeUnauthorized, "Usuário não autenticado", nil)
 		return
 	}
 
@@ -43,7 +44,7 @@ func (h *ProfileHandler) GetProfile(c *gin.Context) {
 	user, err := h.userService.GetByID(userID.(int))
 	if err != nil {
 		log.Printf("Erro ao buscar usuário: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Falha ao buscar dados do usuário"})
+		ErrorResponse(c, http.StatusInternalServerError, domain.ErrCodeInternal, "Falha ao buscar dados do usuário", nil)
 		return
 	}
 
@@ -56,7 +57,7 @@ func (h *ProfileHandler) GetProfile(c *gin.Context) {
 		Theme:                   "default",
 		FontSize:                "medium",
 		Language:                "pt_BR",
-		NotificationPreferences: map[string]bool{"email": true, "push": true, "sms": false},
+		NotificationPreferences: domain.DefaultNotificationChannels(),
 		CreatedAt:               time.Now(),
 	}
 
@@ -91,7 +92,36 @@ func (h *ProfileHandler) GetProfile(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
 		"profile": profile,
 		"theme":   theme,
-		"user":    user,
+		"user":    domain.NewUserResponse(user),
+	})
+}
+
+// GetCompleteness retorna o percentual de preenchimento do perfil do usuário logado
+func (h *ProfileHandler) GetCompleteness(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		ErrorResponse(c, http.StatusUnauthorized, domain.ErrCodeUnauthorized, "Usuário não autenticado", nil)
+		return
+	}
+
+	user, err := h.userService.GetByID(userID.(int))
+	if err != nil {
+		log.Printf("Erro ao buscar usuário: %v", err)
+		ErrorResponse(c, http.StatusInternalServerError, domain.ErrCodeInternal, "Falha ao buscar dados do usuário", nil)
+		return
+	}
+
+	// Perfil inexistente conta como perfil vazio
+	profile, err := h.profileService.GetByUserID(userID.(int))
+	if err != nil {
+		profile = domain.Profile{UserID: userID.(int)}
+	}
+
+	score, missing := h.profileService.CompletenessScore(profile, user)
+
+	c.JSON(http.StatusOK, gin.H{
+		"score":          score,
+		"missing_fields": missing,
 	})
 }
 
@@ -100,29 +130,36 @@ func (h *ProfileHandler) UpdateProfile(c *gin.Context) {
 	userID, _ := c.Get("userID")
 
 	var input struct {
-		Bio                     string          `json:"bio"`
-		Department              string          `json:"department"`
-		Theme                   string          `json:"theme"`
-		FontSize                string          `json:"font_size"`
-		Language                string          `json:"language"`
-		NotificationPreferences map[string]bool `json:"notification_preferences"`
-		FullName                string          `json:"full_name"`  // Adicionado
-		Phone                   string          `json:"phone"`      // Adicionado
-		AvatarURL               *string         `json:"avatar_url"` // Permitir null para remover avatar
+		Bio                     string                      `json:"bio"`
+		Department              string                      `json:"department"`
+		Theme                   string                      `json:"theme"`
+		FontSize                string                      `json:"font_size"`
+		Language                string                      `json:"language"`
+		NotificationPreferences domain.NotificationChannels `json:"notification_preferences"`
+		FullName                string                      `json:"full_name"`  // Adicionado
+		Phone                   string                      `json:"phone"`      // Adicionado
+		AvatarURL               *string                     `json:"avatar_url"` // Permitir null para remover avatar
 	}
 
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		ErrorResponse(c, http.StatusBadRequest, errorCode(err, http.StatusBadRequest), err.Error(), nil)
 		return
 	}
 
+	if input.NotificationPreferences != nil {
+		if err := h.profileService.ValidateNotificationChannels(input.NotificationPreferences); err != nil {
+			ErrorResponse(c, http.StatusBadRequest, errorCode(err, http.StatusBadRequest), err.Error(), nil)
+			return
+		}
+	}
+
 	// Buscar perfil existente ou criar um novo
 	profile, err := h.profileService.GetByUserID(userID.(int))
 	if err != nil {
 		// Se não encontrar, criar um novo perfil
 		profile = domain.Profile{
 			UserID:                  userID.(int),
-			NotificationPreferences: map[string]bool{"email": true, "push": true, "sms": false},
+			NotificationPreferences: domain.DefaultNotificationChannels(),
 			Theme:                   "default",
 			FontSize:                "medium",
 			Language:                "pt_BR",
@@ -156,15 +193,16 @@ func (h *ProfileHandler) UpdateProfile(c *gin.Context) {
 	if input.Language != "" {
 		profile.Language = input.Language
 	}
+	// Canais omitidos (ex.: configurados por outro cliente) são preservados
 	if input.NotificationPreferences != nil {
-		profile.NotificationPreferences = input.NotificationPreferences
+		profile.NotificationPreferences = profile.NotificationPreferences.Merge(input.NotificationPreferences)
 	}
 
 	profile.UpdatedAt = time.Now()
 
 	// Salvar o perfil
 	if err := h.profileService.Update(profile); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Falha ao atualizar perfil: %v", err)})
+		ErrorResponse(c, errorStatus(err), errorCode(err, errorStatus(err)), fmt.Sprintf("Falha ao atualizar perfil: %v", err), nil)
 		return
 	}
 
@@ -180,7 +218,7 @@ func (h *ProfileHandler) UpdateProfile(c *gin.Context) {
 		// Carregar usuário atual
 		user, err := h.userService.GetByID(userID.(int))
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Falha ao carregar usuário: %v", err)})
+			ErrorResponse(c, errorStatus(err), errorCode(err, errorStatus(err)), fmt.Sprintf("Falha ao carregar usuário: %v", err), nil)
 			return
 		}
 
@@ -194,7 +232,7 @@ func (h *ProfileHandler) UpdateProfile(c *gin.Context) {
 
 		err = h.userService.Update(user)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Falha ao atualizar usuário: %v", err)})
+			ErrorResponse(c, errorStatus(err), errorCode(err, errorStatus(err)), fmt.Sprintf("Falha ao atualizar usuário: %v", err), nil)
 			return
 		}
 	}
@@ -209,64 +247,123 @@ func (h *ProfileHandler) UpdateProfile(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Perfil atualizado com sucesso",
 		"profile": profile,
-		"user":    user,
+		"user":    domain.NewUserResponse(user),
 	})
 }
 
-// GetThemes retorna a lista de temas disponíveis
-func (h *ProfileHandler) GetThemes(c *gin.Context) {
-	// Temas padrão que sempre estarão disponíveis
-	defaultThemes := []domain.Theme{
-		{
-			ID:              1,
-			Name:            "default",
-			PrimaryColor:    "#4285F4",
-			SecondaryColor:  "#34A853",
-			TextColor:       "#202124",
-			BackgroundColor: "#FFFFFF",
-			AccentColor:     "#FBBC05",
-			IsDefault:       true,
-		},
-		{
-			ID:              2,
-			Name:            "dark",
-			PrimaryColor:    "#333333",
-			SecondaryColor:  "#555555",
-			TextColor:       "#FFFFFF",
-			BackgroundColor: "#121212",
-			AccentColor:     "#BB86FC",
-			IsDefault:       false,
-		},
-		{
-			ID:              3,
-			Name:            "blue",
-			PrimaryColor:    "#3498db",
-			SecondaryColor:  "#2980b9",
-			TextColor:       "#333333",
-			BackgroundColor: "#ecf0f1",
-			AccentColor:     "#e74c3c",
-			IsDefault:       false,
-		},
-		{
-			ID:              4,
-			Name:            "green",
-			PrimaryColor:    "#2ecc71",
-			SecondaryColor:  "#27ae60",
-			TextColor:       "#333333",
-			BackgroundColor: "#ecf0f1",
-			AccentColor:     "#e67e22",
-			IsDefault:       false,
-		},
-	}
-
-	// Tentar buscar temas do banco de dados
+// GetNotificationChannels retorna os canais de notificação do usuário logado
+// e a lista de canais permitidos
+func (h *ProfileHandler) GetNotificationChannels(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	channels, err := h.profileService.GetNotificationChannels(userID.(int))
+	if err != nil {
+		ErrorResponse(c, errorStatus(err), errorCode(err, errorStatus(err)), fmt.Sprintf("Falha ao buscar canais de notificação: %v", err), nil)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"channels": channels,
+		"allowed":  h.profileService.AllowedNotificationChannels(),
+	})
+}
+
+// UpdateNotificationChannels substitui os canais de notificação do usuário logado.
+// Aceita {"channels": [...]} ou, por compatibilidade, {"channels": {"email": true}}
+func (h *ProfileHandler) UpdateNotificationChannels(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	var input struct {
+		Channels domain.NotificationChannels `json:"channels" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		ErrorResponse(c, http.StatusBadRequest, errorCode(err, http.StatusBadRequest), err.Error(), nil)
+		return
+	}
+
+	channels, err := h.profileService.UpdateNotificationChannels(userID.(int), input.Channels)
+	if err != nil {
+		statusCode := errorStatus(err)
+		if errors.Is(err, domain.ErrInvalidNotificationChannel) || errors.Is(err, domain.ErrDuplicateNotificationChannel) {
+			statusCode = http.StatusBadRequest
+		}
+		ErrorResponse(c, statusCode, errorCode(err, statusCode), fmt.Sprintf("Falha ao atualizar canais de notificação: %v", err), nil)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Canais de notificação atualizados com sucesso",
+		"channels": channels,
+	})
+}
+
+// defaultThemes são os temas disponíveis quando não há temas no banco
+var defaultThemes = []domain.Theme{
+	{
+		ID:              1,
+		Name:            "default",
+		PrimaryColor:    "#4285F4",
+		SecondaryColor:  "#34A853",
+		TextColor:       "#202124",
+		BackgroundColor: "#FFFFFF",
+		AccentColor:     "#FBBC05",
+		IsDefault:       true,
+	},
+	{
+		ID:              2,
+		Name:            "dark",
+		PrimaryColor:    "#333333",
+		SecondaryColor:  "#555555",
+		TextColor:       "#FFFFFF",
+		BackgroundColor: "#121212",
+		AccentColor:     "#BB86FC",
+		IsDefault:       false,
+	},
+	{
+		ID:              3,
+		Name:            "blue",
+		PrimaryColor:    "#3498db",
+		SecondaryColor:  "#2980b9",
+		TextColor:       "#333333",
+		BackgroundColor: "#ecf0f1",
+		AccentColor:     "#e74c3c",
+		IsDefault:       false,
+	},
+	{
+		ID:              4,
+		Name:            "green",
+		PrimaryColor:    "#2ecc71",
+		SecondaryColor:  "#27ae60",
+		TextColor:       "#333333",
+		BackgroundColor: "#ecf0f1",
+		AccentColor:     "#e67e22",
+		IsDefault:       false,
+	},
+}
+
+// availableThemes retorna os temas do banco ou, se não houver, os padrão
+func (h *ProfileHandler) availableThemes() []domain.Theme {
 	themes, err := h.themeService.GetAll()
 	if err != nil || len(themes) == 0 {
-		// Se não conseguir ou não encontrar, usar os temas padrão
-		themes = defaultThemes
+		return defaultThemes
+	}
+	return themes
+}
+
+// isAvailableTheme indica se o nome corresponde a um tema disponível
+func (h *ProfileHandler) isAvailableTheme(name string) bool {
+	for _, theme := range h.availableThemes() {
+		if theme.Name == name {
+			return true
+		}
 	}
+	return false
+}
 
-	c.JSON(http.StatusOK, gin.H{"themes": themes})
+// GetThemes retorna a lista de temas disponíveis
+func (h *ProfileHandler) GetThemes(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"themes": h.availableThemes()})
 }
 
 // UploadAvatar processa o upload da foto de perfil
@@ -276,16 +373,31 @@ func (h *ProfileHandler) UploadAvatar(c *gin.Context) {
 	// Receber o arquivo de imagem
 	file, err := c.FormFile("avatar")
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Arquivo não encontrado"})
+		ErrorResponse(c, http.StatusBadRequest, domain.ErrCodeInvalidPayload, "Arquivo não encontrado", nil)
 		return
 	}
 
 	// Validar o tipo de arquivo
 	ext := filepath.Ext(file.Filename)
 	if ext != ".jpg" && ext != ".jpeg" && ext != ".png" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Formato de arquivo não suportado. Use JPG ou PNG"})
+		ErrorResponse(c, http.StatusBadRequest, domain.ErrCodeInvalidPayload, "Formato de arquivo não suportado. Use JPG ou PNG", nil)
+		return
+	}
+
+	// Buscar o perfil atual para verificar se há um avatar anterior. Só a
+	// ausência do perfil leva à criação de um novo; outros erros (ex.: prazo
+	// da consulta esgotado) encerram o upload antes de gravar o arquivo.
+	var oldAvatarURL string
+	profile, err := h.profileService.GetByUserID(userID.(int))
+	profileExists := err == nil
+	if err != nil && !errors.Is(err, domain.ErrProfileNotFound) {
+		statusCode := errorStatus(err)
+		ErrorResponse(c, statusCode, errorCode(err, statusCode), "Erro ao buscar perfil", nil)
 		return
 	}
+	if profileExists && profile.AvatarURL != "" {
+		oldAvatarURL = profile.AvatarURL
+	}
 
 	// Gerar nome único para o arquivo
 	filename := generateUniqueFilename(file.Filename)
@@ -293,24 +405,18 @@ func (h *ProfileHandler) UploadAvatar(c *gin.Context) {
 	// Usando o caminho D:\Avatar
 	avatarDir := "D:\\Avatar"
 
-	// Garantir que o diretório exista
+	// Garantir que o diretório exista (erros de arquivo não passam por
+	// errorStatus, que classifica erros do banco)
 	if err := os.MkdirAll(avatarDir, os.ModePerm); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Falha ao criar diretório de avatares: %v", err)})
+		ErrorResponse(c, http.StatusInternalServerError, domain.ErrCodeInternal, fmt.Sprintf("Falha ao criar diretório de avatares: %v", err), nil)
 		return
 	}
 
 	dstPath := filepath.Join(avatarDir, filename)
 
-	// Buscar o perfil atual para verificar se há um avatar anterior
-	var oldAvatarURL string
-	profile, err := h.profileService.GetByUserID(userID.(int))
-	if err == nil && profile.AvatarURL != "" {
-		oldAvatarURL = profile.AvatarURL
-	}
-
 	// Salvar o arquivo
 	if err := c.SaveUploadedFile(file, dstPath); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Falha ao salvar imagem: %v", err)})
+		ErrorResponse(c, http.StatusInternalServerError, domain.ErrCodeInternal, fmt.Sprintf("Falha ao salvar imagem: %v", err), nil)
 		return
 	}
 
@@ -318,12 +424,12 @@ func (h *ProfileHandler) UploadAvatar(c *gin.Context) {
 	avatarURL := fmt.Sprintf("/avatar/%s", filename)
 
 	// Atualizar o avatar_url no perfil
-	if err != nil {
+	if !profileExists {
 		// Se não existir perfil, criar um novo
 		profile = domain.Profile{
 			UserID:                  userID.(int),
 			AvatarURL:               avatarURL,
-			NotificationPreferences: map[string]bool{"email": true, "push": true, "sms": false},
+			NotificationPreferences: domain.DefaultNotificationChannels(),
 			Theme:                   "default",
 			FontSize:                "medium",
 			Language:                "pt_BR",
@@ -344,7 +450,8 @@ func (h *ProfileHandler) UploadAvatar(c *gin.Context) {
 			log.Printf("Erro ao remover arquivo após falha de atualização: %v", removeErr)
 		}
 
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Falha ao atualizar perfil"})
+		statusCode := errorStatus(err)
+		ErrorResponse(c, statusCode, errorCode(err, statusCode), "Falha ao atualizar perfil", nil)
 		return
 	}
 
@@ -368,13 +475,13 @@ func (h *ProfileHandler) DeleteAvatar(c *gin.Context) {
 	// Buscar perfil
 	profile, err := h.profileService.GetByUserID(userID.(int))
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Perfil não encontrado"})
+		ErrorResponse(c, http.StatusNotFound, domain.ErrCodeProfileNotFound, "Perfil não encontrado", nil)
 		return
 	}
 
 	// Verificar se existe um avatar para remover
 	if profile.AvatarURL == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Usuário não possui avatar"})
+		ErrorResponse(c, http.StatusBadRequest, domain.ErrCodeInvalidPayload, "Usuário não possui avatar", nil)
 		return
 	}
 
@@ -387,7 +494,7 @@ func (h *ProfileHandler) DeleteAvatar(c *gin.Context) {
 
 	err = h.profileService.Update(profile)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Falha ao atualizar perfil"})
+		ErrorResponse(c, http.StatusInternalServerError, domain.ErrCodeInternal, "Falha ao atualizar perfil", nil)
 		return
 	}
 
@@ -420,7 +527,7 @@ func deleteAvatarFile(avatarURL string) error {
 
 	// Remover o arquivo
 	if err := os.Remove(avatarPath); err != nil {
-		return fmt.Errorf("erro ao remover arquivo %s: %v", avatarPath, err)
+		return fmt.Errorf("erro ao remover arquivo %s: %w", avatarPath, err)
 	}
 
 	log.Printf("Arquivo de avatar removido com sucesso: %s", avatarPath)
@@ -443,10 +550,95 @@ func generateUniqueFilename(originalFilename string) string {
 
 // ChangePassword altera a senha do usuário
 func (h *ProfileHandler) ChangePassword(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"message": "Senha alterada com sucesso"})
+	userID, _ := c.Get("userID")
+	uid, _ := userID.(int)
+
+	var input struct {
+		CurrentPassword string `json:"current_password" binding:"required"`
+		NewPassword     string `json:"new_password" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		ErrorResponse(c, http.StatusBadRequest, domain.ErrCodeInvalidPayload, "Dados inválidos: "+err.Error(), nil)
+		return
+	}
+
+	if err := h.userService.ChangePassword(uid, input.CurrentPassword, input.NewPassword); err != nil {
+		if respondWeakPassword(c, err) {
+			return
+		}
+
+		statusCode := errorStatus(err)
+		message := "Erro ao alterar senha"
+		if errors.Is(err, domain.ErrInvalidCredentials) {
+			statusCode = http.StatusBadRequest
+			message = "Senha atual incorreta"
+		} else if errors.Is(err, domain.ErrUserNotFound) {
+			statusCode = http.StatusNotFound
+			message = "Usuário não encontrado"
+		}
+
+		ErrorResponse(c, statusCode, errorCode(err, statusCode), message, nil)
+		return
+	}
+
+	log.Printf("Auditoria: entity_type=user entity_id=%d action=password_changed", uid)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Senha alterada com sucesso. Faça login novamente"})
 }
 
 // DeleteAccount exclui a conta do usuário
 func (h *ProfileHandler) DeleteAccount(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	uid, _ := userID.(int)
+
+	var input struct {
+		Password string `json:"password" binding:"required"`
+		Confirm  string `json:"confirm" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		ErrorResponse(c, http.StatusBadRequest, domain.ErrCodeInvalidPayload, "Dados inválidos: "+err.Error(), nil)
+		return
+	}
+
+	if input.Confirm != "DELETE" {
+		ErrorResponse(c, http.StatusBadRequest, domain.ErrCodeInvalidPayload, `Confirme a exclusão enviando "confirm": "DELETE"`, nil)
+		return
+	}
+
+	if err := h.userService.DeleteAccount(uid, input.Password); err != nil {
+		statusCode := errorStatus(err)
+		message := "Erro ao excluir conta"
+		if errors.Is(err, domain.ErrInvalidCredentials) {
+			statusCode = http.StatusBadRequest
+			message = "Senha incorreta"
+		} else if errors.Is(err, domain.ErrUserNotFound) {
+			statusCode = http.StatusNotFound
+			message = "Usuário não encontrado"
+		}
+
+		ErrorResponse(c, statusCode, errorCode(err, statusCode), message, nil)
+		return
+	}
+
+	// Anonimizar o perfil: a conta já está excluída, então uma falha aqui
+	// apenas é registrada
+	profile, err := h.profileService.GetByUserID(uid)
+	if err == nil {
+		if err := h.profileService.Update(domain.Profile{UserID: uid, CreatedAt: profile.CreatedAt}); err != nil {
+			log.Printf("Erro ao anonimizar perfil do usuário %d: %v", uid, err)
+		}
+		if profile.AvatarURL != "" {
+			if err := deleteAvatarFile(profile.AvatarURL); err != nil {
+				log.Printf("Aviso: Não foi possível excluir o arquivo de avatar: %v", err)
+			}
+		}
+	} else if !errors.Is(err, domain.ErrProfileNotFound) {
+		log.Printf("Erro ao buscar perfil do usuário %d para anonimizar: %v", uid, err)
+	}
+
+	log.Printf("Auditoria: entity_type=user entity_id=%d action=account_deleted", uid)
+
 	c.JSON(http.StatusOK, gin.H{"message": "Conta excluída com sucesso"})
 }
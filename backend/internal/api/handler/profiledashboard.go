@@ -0,0 +1,73 @@
+// internal/api/handler/profiledashboard.go
+package handler
+
+import (
+	"app_padrao/internal/domain"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// dashboardErrorStatus retorna o status HTTP para erros do painel configurável
+func dashboardErrorStatus(err error) int {
+	if errors.Is(err, domain.ErrInvalidDashboardWidget) || errors.Is(err, domain.ErrDashboardTooLarge) {
+		return http.StatusBadRequest
+	}
+	return errorStatus(err)
+}
+
+// GetDashboard retorna os widgets do painel do usuário logado
+func (h *ProfileHandler) GetDashboard(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	widgets, err := h.profileService.GetDashboard(userID.(int))
+	if err != nil {
+		ErrorResponse(c, errorStatus(err), errorCode(err, errorStatus(err)), fmt.Sprintf("Falha ao buscar painel: %v", err), nil)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"widgets": widgets})
+}
+
+// UpdateDashboard substitui os widgets do painel do usuário logado
+func (h *ProfileHandler) UpdateDashboard(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	var input struct {
+		Widgets []domain.DashboardWidget `json:"widgets" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		ErrorResponse(c, http.StatusBadRequest, errorCode(err, http.StatusBadRequest), err.Error(), nil)
+		return
+	}
+
+	widgets, err := h.profileService.UpdateDashboard(userID.(int), input.Widgets)
+	if err != nil {
+		statusCode := dashboardErrorStatus(err)
+		ErrorResponse(c, statusCode, errorCode(err, statusCode), fmt.Sprintf("Falha ao atualizar painel: %v", err), nil)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Painel atualizado com sucesso",
+		"widgets": widgets,
+	})
+}
+
+// ResetDashboard esvazia o painel do usuário logado
+func (h *ProfileHandler) ResetDashboard(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	if err := h.profileService.ResetDashboard(userID.(int)); err != nil {
+		ErrorResponse(c, errorStatus(err), errorCode(err, errorStatus(err)), fmt.Sprintf("Falha ao limpar painel: %v", err), nil)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Painel limpo com sucesso",
+		"widgets": []domain.DashboardWidget{},
+	})
+}
@@ -0,0 +1,114 @@
+// internal/api/handler/profiletheme.go
+package handler
+
+import (
+	"app_padrao/internal/domain"
+	"app_padrao/internal/service"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// themeInput é o corpo das rotas de troca de tema
+type themeInput struct {
+	Theme string `json:"theme" binding:"required"`
+}
+
+// UpdateTheme troca o tema do usuário logado e avisa suas sessões abertas
+func (h *ProfileHandler) UpdateTheme(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	uid, _ := userID.(int)
+
+	h.updateTheme(c, uid, uid)
+}
+
+// UpdateUserTheme troca o tema de outro usuário (rota administrativa)
+func (h *ProfileHandler) UpdateUserTheme(c *gin.Context) {
+	targetID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || targetID <= 0 {
+		ErrorResponse(c, http.StatusBadRequest, domain.ErrCodeInvalidParameter, "ID inválido", nil)
+		return
+	}
+
+	if _, err := h.userService.GetByID(targetID); err != nil {
+		statusCode := errorStatus(err)
+		if errors.Is(err, domain.ErrUserNotFound) {
+			statusCode = http.StatusNotFound
+		}
+		ErrorResponse(c, statusCode, errorCode(err, statusCode), err.Error(), nil)
+		return
+	}
+
+	userID, _ := c.Get("userID")
+	uid, _ := userID.(int)
+
+	h.updateTheme(c, targetID, uid)
+}
+
+// updateTheme valida o tema do corpo e o grava no perfil de targetID
+func (h *ProfileHandler) updateTheme(c *gin.Context, targetID, changedBy int) {
+	var input themeInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		ErrorResponse(c, http.StatusBadRequest, errorCode(err, http.StatusBadRequest), err.Error(), nil)
+		return
+	}
+
+	if !h.isAvailableTheme(input.Theme) {
+		ErrorResponse(c, http.StatusBadRequest, errorCode(domain.ErrThemeNotFound, http.StatusBadRequest), domain.ErrThemeNotFound.Error(), nil)
+		return
+	}
+
+	if err := h.profileService.UpdateTheme(targetID, input.Theme, changedBy); err != nil {
+		ErrorResponse(c, errorStatus(err), errorCode(err, errorStatus(err)), fmt.Sprintf("Falha ao atualizar tema: %v", err), nil)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Tema atualizado com sucesso",
+		"theme":   input.Theme,
+	})
+}
+
+// StreamProfileEvents envia as trocas de tema do usuário logado como
+// Server-Sent Events (event: theme_changed), para que a interface aplique o
+// novo tema sem recarregar a página
+func (h *ProfileHandler) StreamProfileEvents(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	uid, _ := userID.(int)
+
+	themes, err := h.profileService.SubscribeThemeChanges(c.Request.Context(), uid)
+	if err != nil {
+		statusCode := errorStatus(err)
+		if errors.Is(err, service.ErrThemeEventsNotConfigured) {
+			statusCode = http.StatusServiceUnavailable
+		}
+		ErrorResponse(c, statusCode, errorCode(err, statusCode), fmt.Sprintf("Erro ao iniciar streaming de eventos: %v", err), nil)
+		return
+	}
+
+	startEventStream(c)
+
+	keepAlive := time.NewTicker(sseKeepAliveInterval)
+	defer keepAlive.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case theme, ok := <-themes:
+			if !ok {
+				return false
+			}
+			data, _ := json.Marshal(gin.H{"event": "theme_changed", "theme": theme})
+			_, err := fmt.Fprintf(w, "event: theme_changed\ndata: %s\n\n", data)
+			return err == nil
+		case <-keepAlive.C:
+			_, err := fmt.Fprint(w, ": ping\n\n")
+			return err == nil
+		}
+	})
+}
@@ -0,0 +1,183 @@
+package handler
+
+import (
+	"app_padrao/internal/domain"
+	"app_padrao/internal/service"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+)
+
+// fakeProfileRepo guarda os perfis em memória
+type fakeProfileRepo struct {
+	domain.ProfileRepository
+	profiles map[int]domain.Profile
+}
+
+func (r *fakeProfileRepo) GetByUserID(userID int) (domain.Profile, error) {
+	profile, ok := r.profiles[userID]
+	if !ok {
+		return domain.Profile{}, domain.ErrProfileNotFound
+	}
+	return profile, nil
+}
+
+func (r *fakeProfileRepo) Update(profile domain.Profile) error {
+	r.profiles[profile.UserID] = profile
+	return nil
+}
+
+// fakeThemeService não tem temas no banco, então valem os temas padrão
+type fakeThemeService struct {
+	domain.ThemeService
+}
+
+func (s *fakeThemeService) GetAll() ([]domain.Theme, error) {
+	return nil, nil
+}
+
+// fakeProfileUserService conhece apenas os usuários cadastrados em users
+type fakeProfileUserService struct {
+	domain.UserService
+	users map[int]domain.User
+}
+
+func (s *fakeProfileUserService) GetByID(id int) (domain.User, error) {
+	user, ok := s.users[id]
+	if !ok {
+		return domain.User{}, domain.ErrUserNotFound
+	}
+	return user, nil
+}
+
+func TestThemeChangePublishesEvent(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	const prefix = "app:"
+	repo := &fakeProfileRepo{profiles: map[int]domain.Profile{
+		7: {UserID: 7, Theme: "light"},
+	}}
+	profileService := service.NewProfileService(repo)
+	profileService.SetThemeNotifier(client, prefix)
+
+	userService := &fakeProfileUserService{users: map[int]domain.User{
+		1: {ID: 1, Username: "admin", Role: "admin"},
+		7: {ID: 7, Username: "operador", Role: "user"},
+	}}
+	h := NewProfileHandler(profileService, userService, &fakeThemeService{})
+
+	tests := []struct {
+		name      string
+		method    string
+		path      string
+		callerID  int
+		theme     string
+		wantCode  int
+		wantEvent bool
+	}{
+		{"próprio usuário", http.MethodPut, "/api/profile/theme", 7, "dark", http.StatusOK, true},
+		{"administrador", http.MethodPost, "/api/admin/users/7/theme", 1, "blue", http.StatusOK, true},
+		{"tema inexistente", http.MethodPut, "/api/profile/theme", 7, "roxo", http.StatusBadRequest, false},
+		{"usuário inexistente", http.MethodPost, "/api/admin/users/99/theme", 1, "dark", http.StatusNotFound, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pubsub := client.Subscribe(context.Background(), prefix+"user:7:theme_changed", prefix+"user:99:theme_changed")
+			defer pubsub.Close()
+			if _, err := pubsub.Receive(context.Background()); err != nil {
+				t.Fatalf("erro ao assinar canal: %v", err)
+			}
+			messages := pubsub.Channel()
+
+			router := gin.New()
+			router.Use(func(c *gin.Context) {
+				c.Set("userID", tt.callerID)
+				c.Next()
+			})
+			router.PUT("/api/profile/theme", h.UpdateTheme)
+			router.POST("/api/admin/users/:id/theme", h.UpdateUserTheme)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(`{"theme":"`+tt.theme+`"}`))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantCode {
+				t.Fatalf("status = %d, esperado %d: %s", w.Code, tt.wantCode, w.Body.String())
+			}
+
+			select {
+			case msg := <-messages:
+				if !tt.wantEvent {
+					t.Fatalf("evento inesperado em %s: %s", msg.Channel, msg.Payload)
+				}
+				if msg.Channel != prefix+"user:7:theme_changed" {
+					t.Errorf("canal = %q, esperado %q", msg.Channel, prefix+"user:7:theme_changed")
+				}
+				if want := `{"theme":"` + tt.theme + `"}`; msg.Payload != want {
+					t.Errorf("mensagem = %s, esperado %s", msg.Payload, want)
+				}
+			case <-time.After(500 * time.Millisecond):
+				if tt.wantEvent {
+					t.Fatal("nenhum evento publicado")
+				}
+			}
+
+			if tt.wantEvent && repo.profiles[7].Theme != tt.theme {
+				t.Errorf("tema gravado = %q, esperado %q", repo.profiles[7].Theme, tt.theme)
+			}
+		})
+	}
+}
+
+func TestSubscribeThemeChangesDeliversTheme(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	repo := &fakeProfileRepo{profiles: map[int]domain.Profile{}}
+	profileService := service.NewProfileService(repo)
+	profileService.SetThemeNotifier(client, "")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	themes, err := profileService.SubscribeThemeChanges(ctx, 7)
+	if err != nil {
+		t.Fatalf("erro ao assinar trocas de tema: %v", err)
+	}
+
+	// Sem perfil gravado, UpdateTheme cria um perfil com o novo tema
+	if err := profileService.UpdateTheme(7, "green", 7); err != nil {
+		t.Fatalf("erro ao atualizar tema: %v", err)
+	}
+
+	select {
+	case theme := <-themes:
+		if theme != "green" {
+			t.Errorf("tema recebido = %q, esperado green", theme)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("troca de tema não entregue ao assinante")
+	}
+
+	cancel()
+	select {
+	case _, ok := <-themes:
+		if ok {
+			t.Error("canal deveria ser fechado após o cancelamento")
+		}
+	case <-time.After(time.Second):
+		t.Error("canal não foi fechado após o cancelamento")
+	}
+}
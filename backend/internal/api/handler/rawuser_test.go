@@ -0,0 +1,156 @@
+package handler
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// rawUserExample é compilado junto com os handlers para confirmar que a
+// verificação encontra uma resposta com domain.User
+const rawUserExample = `package handler
+
+import (
+	"app_padrao/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+func rawUserExample(c *gin.Context, u domain.User) {
+	c.JSON(200, gin.H{"user": &u})
+}
+`
+
+// responseMethods são os métodos de gin.Context que serializam o corpo
+var responseMethods = map[string]bool{
+	"JSON": true, "IndentedJSON": true, "SecureJSON": true, "JSONP": true,
+	"AsciiJSON": true, "PureJSON": true, "AbortWithStatusJSON": true,
+}
+
+// containsDomainUser indica se o tipo é domain.User ou o contém como
+// ponteiro, slice, array ou valor de mapa
+func containsDomainUser(t types.Type) bool {
+	for {
+		switch u := t.(type) {
+		case *types.Named:
+			obj := u.Obj()
+			return obj.Pkg() != nil && obj.Pkg().Path() == "app_padrao/internal/domain" && obj.Name() == "User"
+		case *types.Pointer:
+			t = u.Elem()
+		case *types.Slice:
+			t = u.Elem()
+		case *types.Array:
+			t = u.Elem()
+		case *types.Map:
+			t = u.Elem()
+		default:
+			return false
+		}
+	}
+}
+
+// rawUserResponses retorna as posições das respostas JSON (e de
+// ErrorResponse) que recebem um domain.User em vez de domain.UserResponse
+func rawUserResponses(fset *token.FileSet, files []*ast.File, info *types.Info) []string {
+	var found []string
+	for _, file := range files {
+		ast.Inspect(file, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+
+			switch fun := call.Fun.(type) {
+			case *ast.SelectorExpr:
+				if !responseMethods[fun.Sel.Name] {
+					return true
+				}
+			case *ast.Ident:
+				if fun.Name != "ErrorResponse" {
+					return true
+				}
+			default:
+				return true
+			}
+
+			for _, arg := range call.Args {
+				ast.Inspect(arg, func(n ast.Node) bool {
+					expr, ok := n.(ast.Expr)
+					if !ok {
+						return true
+					}
+					if tv, ok := info.Types[expr]; ok && tv.Type != nil && containsDomainUser(tv.Type) {
+						found = append(found, fset.Position(expr.Pos()).String())
+						return false
+					}
+					// Só o resultado de uma chamada vai para a resposta, não seus
+					// argumentos (ex.: domain.NewUserResponse(user))
+					switch expr.(type) {
+					case *ast.CallExpr, *ast.FuncLit:
+						return false
+					}
+					return true
+				})
+			}
+			return true
+		})
+	}
+	return found
+}
+
+func TestHandlersDoNotMarshalRawUser(t *testing.T) {
+	if testing.Short() {
+		t.Skip("a verificação de tipos do pacote é lenta")
+	}
+
+	paths, err := filepath.Glob("*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for _, path := range paths {
+		if strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			t.Fatalf("erro ao ler %s: %v", path, err)
+		}
+		files = append(files, file)
+	}
+
+	example, err := parser.ParseFile(fset, "raw_user_example.go", rawUserExample, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	info := &types.Info{Types: make(map[ast.Expr]types.TypeAndValue)}
+	if _, err := conf.Check("app_padrao/internal/api/handler", fset, append(files, example), info); err != nil {
+		t.Fatalf("erro na verificação de tipos dos handlers: %v", err)
+	}
+
+	var inHandlers []string
+	exampleFound := false
+	for _, pos := range rawUserResponses(fset, append(files, example), info) {
+		if strings.HasPrefix(pos, "raw_user_example.go") {
+			exampleFound = true
+			continue
+		}
+		inHandlers = append(inHandlers, pos)
+	}
+
+	if !exampleFound {
+		t.Error("a verificação não encontrou domain.User no exemplo")
+	}
+	for _, pos := range inHandlers {
+		t.Errorf("%s: resposta com domain.User; use domain.NewUserResponse", pos)
+	}
+}
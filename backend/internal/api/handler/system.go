@@ -0,0 +1,104 @@
+// internal/api/handler/system.go
+package handler
+
+import (
+	"app_padrao/internal/cache"
+	"app_padrao/internal/domain"
+	"app_padrao/internal/metrics"
+	"app_padrao/internal/service"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"runtime"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SystemHandler expõe informações de diagnóstico do processo
+type SystemHandler struct {
+	plcService    domain.PLCService
+	metrics       *metrics.MetricsCollector
+	db            *sql.DB
+	leakThreshold int
+}
+
+func NewSystemHandler(plcService domain.PLCService, metricsCollector *metrics.MetricsCollector, db *sql.DB, leakThreshold int) *SystemHandler {
+	return &SystemHandler{
+		plcService:    plcService,
+		metrics:       metricsCollector,
+		db:            db,
+		leakThreshold: leakThreshold,
+	}
+}
+
+// GetGoroutines compara as goroutines rastreadas pelo gerenciador de PLCs com
+// o total do runtime. Uma diferença acima do limite indica possível vazamento.
+func (h *SystemHandler) GetGoroutines(c *gin.Context) {
+	tracked := h.plcService.GetTrackedGoroutines()
+	total := int64(runtime.NumGoroutine())
+	delta := total - tracked
+
+	leakSuspected := delta > int64(h.leakThreshold)
+	if leakSuspected {
+		log.Printf("AVISO: possível vazamento de goroutines - runtime: %d, rastreadas: %d, diferença: %d (limite %d)",
+			total, tracked, delta, h.leakThreshold)
+	}
+
+	if h.metrics != nil {
+		gauge := 0.0
+		if leakSuspected {
+			gauge = 1.0
+		}
+		h.metrics.SetGauge("system.goroutine.leak_suspected", gauge)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"tracked": tracked,
+		"runtime": total,
+		"delta":   delta,
+	})
+}
+
+// MigrateRedisKeys move as chaves Redis sem namespace para o REDIS_KEY_PREFIX
+// da instância
+func (h *SystemHandler) MigrateRedisKeys(c *gin.Context) {
+	migrated, total, err := h.plcService.MigrateRedisKeys()
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		if errors.Is(err, service.ErrRedisKeyPrefixNotSet) {
+			statusCode = http.StatusBadRequest
+		} else if errors.Is(err, cache.ErrRedisNotConnected) {
+			statusCode = http.StatusServiceUnavailable
+		}
+		ErrorResponse(c, statusCode, errorCode(err, statusCode), fmt.Sprintf("Erro ao migrar chaves Redis: %v", err), gin.H{
+			"migrated": migrated,
+			"total":    total,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"migrated": migrated,
+		"total":    total,
+	})
+}
+
+// GetDBPoolStats retorna o estado do pool de conexões com o PostgreSQL
+func (h *SystemHandler) GetDBPoolStats(c *gin.Context) {
+	if h.db == nil {
+		ErrorResponse(c, http.StatusServiceUnavailable, domain.ErrCodeServiceUnavailable, "Banco de dados não disponível", nil)
+		return
+	}
+
+	stats := h.db.Stats()
+	c.JSON(http.StatusOK, gin.H{
+		"max_open_connections": stats.MaxOpenConnections,
+		"open_connections":     stats.OpenConnections,
+		"in_use":               stats.InUse,
+		"idle":                 stats.Idle,
+		"wait_count":           stats.WaitCount,
+		"wait_duration_ms":     stats.WaitDuration.Milliseconds(),
+	})
+}
@@ -0,0 +1,133 @@
+// internal/api/handler/tagannotation.go
+package handler
+
+import (
+	"app_padrao/internal/domain"
+	"app_padrao/internal/service"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// tagAnnotationErrorStatus mapeia os erros de anotações para códigos HTTP
+func tagAnnotationErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrTagAnnotationNotFound), errors.Is(err, domain.ErrPLCTagNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrInvalidTagAnnotation), errors.Is(err, domain.ErrTagAnnotationTooLong):
+		return http.StatusBadRequest
+	case errors.Is(err, domain.ErrTagAnnotationForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, service.ErrTagAnnotationsNotConfigured):
+		return http.StatusServiceUnavailable
+	}
+	return errorStatus(err)
+}
+
+// respondTagAnnotationError responde com o status e o código do erro de anotação
+func respondTagAnnotationError(c *gin.Context, message string, err error) {
+	statusCode := tagAnnotationErrorStatus(err)
+	ErrorResponse(c, statusCode, errorCode(err, statusCode), fmt.Sprintf("%s: %v", message, err), nil)
+}
+
+// attachTagAnnotations preenche a contagem de anotações da tag e, se
+// solicitado, as próprias anotações. Falhas apenas omitem os campos.
+func (h *PLCHandler) attachTagAnnotations(tag *domain.PLCTag, includeAnnotations bool) {
+	if includeAnnotations {
+		annotations, err := h.plcService.GetTagAnnotations(tag.ID)
+		if err != nil {
+			if !errors.Is(err, service.ErrTagAnnotationsNotConfigured) {
+				log.Printf("Aviso: erro ao buscar anotações da tag %d: %v", tag.ID, err)
+			}
+			return
+		}
+		count := len(annotations)
+		tag.Annotations = annotations
+		tag.AnnotationCount = &count
+		return
+	}
+
+	count, err := h.plcService.CountTagAnnotations(tag.ID)
+	if err != nil {
+		if !errors.Is(err, service.ErrTagAnnotationsNotConfigured) {
+			log.Printf("Aviso: erro ao contar anotações da tag %d: %v", tag.ID, err)
+		}
+		return
+	}
+	tag.AnnotationCount = &count
+}
+
+// GetTagAnnotations lista as anotações da tag, da mais recente para a mais antiga
+func (h *PLCHandler) GetTagAnnotations(c *gin.Context) {
+	tagID, err := h.getIDFromParams(c)
+	if err != nil {
+		return
+	}
+
+	annotations, err := h.plcService.GetTagAnnotations(tagID)
+	if err != nil {
+		respondTagAnnotationError(c, "Erro ao buscar anotações", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"annotations": annotations})
+}
+
+// CreateTagAnnotation registra uma anotação do usuário na tag
+func (h *PLCHandler) CreateTagAnnotation(c *gin.Context) {
+	tagID, err := h.getIDFromParams(c)
+	if err != nil {
+		return
+	}
+
+	var input struct {
+		Text string `json:"text"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		ErrorResponse(c, http.StatusBadRequest, domain.ErrCodeInvalidPayload, "Dados inválidos", err.Error())
+		return
+	}
+
+	userID, _ := c.Get("userID")
+	uid, _ := userID.(int)
+
+	id, err := h.plcService.CreateTagAnnotation(domain.TagAnnotation{
+		TagID:  tagID,
+		UserID: uid,
+		Text:   input.Text,
+	})
+	if err != nil {
+		respondTagAnnotationError(c, "Erro ao criar anotação", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"id": id, "message": "Anotação criada com sucesso"})
+}
+
+// DeleteTagAnnotation remove uma anotação da tag (apenas o autor ou um administrador)
+func (h *PLCHandler) DeleteTagAnnotation(c *gin.Context) {
+	tagID, err := h.getIDFromParams(c)
+	if err != nil {
+		return
+	}
+
+	annotationID, err := strconv.Atoi(c.Param("annotID"))
+	if err != nil || annotationID <= 0 {
+		ErrorResponse(c, http.StatusBadRequest, domain.ErrCodeInvalidParameter, "ID da anotação inválido", nil)
+		return
+	}
+
+	userID, _ := c.Get("userID")
+	uid, _ := userID.(int)
+
+	if err := h.plcService.DeleteTagAnnotation(tagID, annotationID, uid); err != nil {
+		respondTagAnnotationError(c, "Erro ao excluir anotação", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Anotação excluída com sucesso"})
+}
@@ -0,0 +1,159 @@
+// internal/api/handler/tagdependency.go
+package handler
+
+import (
+	"app_padrao/internal/domain"
+	"app_padrao/internal/service"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// tagDependencyErrorStatus mapeia os erros de dependências para códigos HTTP
+func tagDependencyErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrTagDependencyNotFound), errors.Is(err, domain.ErrPLCTagNotFound),
+		errors.Is(err, service.ErrTagNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrInvalidTagDependency), errors.Is(err, domain.ErrInvalidTagDependencyType),
+		errors.Is(err, domain.ErrInvalidTagDependencyDelay), errors.Is(err, domain.ErrTagDependencyValueMissing),
+		errors.Is(err, domain.ErrInvalidScaledValue):
+		return http.StatusBadRequest
+	case errors.Is(err, domain.ErrTagDependencyDelayTooLong):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, context.Canceled):
+		return http.StatusGatewayTimeout
+	case errors.Is(err, domain.ErrTagDependencyCycle), errors.Is(err, domain.ErrTagDependencyExists):
+		return http.StatusConflict
+	case errors.Is(err, service.ErrWriteNotPermitted):
+		return http.StatusForbidden
+	case errors.Is(err, service.ErrWriteBlocked):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, service.ErrWriteRateLimited):
+		return http.StatusTooManyRequests
+	case errors.Is(err, service.ErrTagDependenciesNotConfigured), errors.Is(err, service.ErrMonitoringNotActive):
+		return http.StatusServiceUnavailable
+	}
+	return errorStatus(err)
+}
+
+// respondTagDependencyError responde com o status e o código do erro de dependência
+func respondTagDependencyError(c *gin.Context, message string, err error) {
+	statusCode := tagDependencyErrorStatus(err)
+	ErrorResponse(c, statusCode, errorCode(err, statusCode), fmt.Sprintf("%s: %v", message, err), nil)
+}
+
+// GetTagDependencies lista as dependências declaradas pela tag
+func (h *PLCHandler) GetTagDependencies(c *gin.Context) {
+	tagID, err := h.getIDFromParams(c)
+	if err != nil {
+		return
+	}
+
+	dependencies, err := h.plcService.GetTagDependencies(tagID)
+	if err != nil {
+		respondTagDependencyError(c, "Erro ao buscar dependências", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"dependencies": dependencies})
+}
+
+// CreateTagDependency registra uma dependência de escrita da tag
+func (h *PLCHandler) CreateTagDependency(c *gin.Context) {
+	tagID, err := h.getIDFromParams(c)
+	if err != nil {
+		return
+	}
+
+	var input struct {
+		DependsOnTagID int    `json:"depends_on_tag_id"`
+		DependencyType string `json:"dependency_type"`
+		DelayMs        int    `json:"delay_ms"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		ErrorResponse(c, http.StatusBadRequest, domain.ErrCodeInvalidPayload, "Dados inválidos", err.Error())
+		return
+	}
+
+	userID, _ := c.Get("userID")
+	uid, _ := userID.(int)
+
+	id, err := h.plcService.CreateTagDependency(domain.TagDependency{
+		TagID:          tagID,
+		DependsOnTagID: input.DependsOnTagID,
+		DependencyType: input.DependencyType,
+		DelayMs:        input.DelayMs,
+	}, uid)
+	if err != nil {
+		respondTagDependencyError(c, "Erro ao criar dependência", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"id": id, "message": "Dependência criada com sucesso"})
+}
+
+// DeleteTagDependency remove uma dependência da tag
+func (h *PLCHandler) DeleteTagDependency(c *gin.Context) {
+	tagID, err := h.getIDFromParams(c)
+	if err != nil {
+		return
+	}
+
+	dependencyID, err := strconv.Atoi(c.Param("depID"))
+	if err != nil || dependencyID <= 0 {
+		ErrorResponse(c, http.StatusBadRequest, domain.ErrCodeInvalidParameter, "ID da dependência inválido", nil)
+		return
+	}
+
+	userID, _ := c.Get("userID")
+	uid, _ := userID.(int)
+
+	if err := h.plcService.DeleteTagDependency(tagID, dependencyID, uid); err != nil {
+		respondTagDependencyError(c, "Erro ao excluir dependência", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Dependência excluída com sucesso"})
+}
+
+// writeTagWithDependencies escreve a tag seguindo sua cadeia de dependências
+// e responde com o recibo das escritas. Em caso de falha, o recibo vai em
+// details.
+func (h *PLCHandler) writeTagWithDependencies(c *gin.Context, tagName string, value interface{}, dependencyValues map[string]interface{}, userID int) {
+	ctx, cancel := responseDeadline(c)
+	defer cancel()
+
+	receipt, err := h.plcService.WriteTagWithDependencies(ctx, tagName, value, dependencyValues, userID)
+	if err != nil {
+		statusCode := tagDependencyErrorStatus(err)
+		var details interface{}
+		if len(receipt.Writes) > 0 {
+			details = receipt
+		}
+		ErrorResponse(c, statusCode, errorCode(err, statusCode), fmt.Sprintf("Erro ao escrever valor: %v", err), details)
+		return
+	}
+
+	c.JSON(http.StatusOK, receipt)
+}
+
+// responseMargin é o tempo reservado para escrever a resposta antes do
+// WriteTimeout do servidor
+const responseMargin = time.Second
+
+// responseDeadline retorna o contexto da requisição limitado ao WriteTimeout
+// do servidor, para que uma operação longa termine enquanto a resposta ainda
+// pode ser enviada. O contexto também termina se o cliente desconectar.
+func responseDeadline(c *gin.Context) (context.Context, context.CancelFunc) {
+	ctx := c.Request.Context()
+	if srv, ok := ctx.Value(http.ServerContextKey).(*http.Server); ok && srv.WriteTimeout > responseMargin {
+		return context.WithTimeout(ctx, srv.WriteTimeout-responseMargin)
+	}
+	return context.WithCancel(ctx)
+}
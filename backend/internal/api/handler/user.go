@@ -23,17 +23,17 @@ func (h *UserHandler) GetProfile(c *gin.Context) {
 
 	user, err := h.userService.GetByID(userID.(int))
 	if err != nil {
-		statusCode := http.StatusInternalServerError
+		statusCode := errorStatus(err)
 
 		if err == domain.ErrUserNotFound {
 			statusCode = http.StatusNotFound
 		}
 
-		c.JSON(statusCode, gin.H{"error": err.Error()})
+		ErrorResponse(c, statusCode, errorCode(err, statusCode), err.Error(), nil)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"user": user})
+	c.JSON(http.StatusOK, gin.H{"user": domain.NewUserResponse(user)})
 }
 
 func (h *UserHandler) UpdateProfile(c *gin.Context) {
@@ -45,14 +45,14 @@ func (h *UserHandler) UpdateProfile(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		ErrorResponse(c, http.StatusBadRequest, errorCode(err, http.StatusBadRequest), err.Error(), nil)
 		return
 	}
 
 	// Carregar usuário atual
 	user, err := h.userService.GetByID(userID.(int))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		ErrorResponse(c, errorStatus(err), errorCode(err, errorStatus(err)), err.Error(), nil)
 		return
 	}
 
@@ -62,12 +62,12 @@ func (h *UserHandler) UpdateProfile(c *gin.Context) {
 
 	err = h.userService.Update(user)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		ErrorResponse(c, errorStatus(err), errorCode(err, errorStatus(err)), err.Error(), nil)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Perfil atualizado com sucesso",
-		"user":    user,
+		"user":    domain.NewUserResponse(user),
 	})
 }
@@ -2,32 +2,37 @@
 package middleware
 
 import (
-	"app_padrao/pkg/jwt"
+	"app_padrao/internal/domain"
 	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 )
 
-func AuthMiddleware(secretKey string) gin.HandlerFunc {
+// TokenValidator valida um token de sessão e retorna o ID do usuário
+type TokenValidator func(token string) (int, error)
+
+// AuthMiddleware exige um token Bearer válido e guarda o ID do usuário no
+// contexto (userID)
+func AuthMiddleware(validate TokenValidator) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "token não fornecido"})
+			c.JSON(http.StatusUnauthorized, domain.APIError{Code: domain.ErrCodeUnauthorized, Message: "token não fornecido"})
 			c.Abort()
 			return
 		}
 
 		parts := strings.Split(authHeader, " ")
 		if len(parts) != 2 || parts[0] != "Bearer" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "formato de token inválido"})
+			c.JSON(http.StatusUnauthorized, domain.APIError{Code: domain.ErrCodeUnauthorized, Message: "formato de token inválido"})
 			c.Abort()
 			return
 		}
 
-		userID, err := jwt.ValidateToken(parts[1], secretKey)
+		userID, err := validate(parts[1])
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "token inválido"})
+			c.JSON(http.StatusUnauthorized, domain.APIError{Code: domain.ErrCodeUnauthorized, Message: "token inválido"})
 			c.Abort()
 			return
 		}
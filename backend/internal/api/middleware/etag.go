@@ -0,0 +1,209 @@
+// internal/api/middleware/etag.go
+package middleware
+
+import (
+	"app_padrao/internal/domain"
+	"bytes"
+	"context"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+)
+
+// etagBodyWriter retém a resposta para que o ETag seja calculado antes do envio
+type etagBodyWriter struct {
+	gin.ResponseWriter
+	body   bytes.Buffer
+	status int
+}
+
+func (w *etagBodyWriter) Write(data []byte) (int, error) {
+	return w.body.Write(data)
+}
+
+func (w *etagBodyWriter) WriteString(s string) (int, error) {
+	return w.body.WriteString(s)
+}
+
+func (w *etagBodyWriter) WriteHeader(code int) {
+	w.status = code
+}
+
+// ETagConfig configura o ETagger
+type ETagConfig struct {
+	// Chave do hash no Redis; "{param}" é trocado pelo parâmetro da rota
+	// (ex.: "etag:plc:{id}:tags") e a query string é anexada quando presente
+	Key string
+
+	// Contadores de versão dos quais a resposta depende ("{param}" aceito).
+	// As versões lidas fazem parte da chave do hash: incrementar um contador
+	// invalida os hashes anteriores sem varrer o Redis.
+	VersionKeys []string
+
+	// Namespace das chaves no Redis (REDIS_KEY_PREFIX)
+	KeyPrefix string
+
+	// Validade do hash no Redis
+	TTL time.Duration
+
+	// Parâmetros de query que, com valor "true", tornam a resposta volátil
+	// (ex.: include_derivative): o handler sempre executa e o hash não é
+	// guardado no Redis
+	VolatileParams []string
+
+	// A resposta inclui valores lidos do PLC (ex.: current_value, status da
+	// conexão), que mudam sem incrementar os contadores de versão. O hash
+	// continua guardado no Redis, mas o handler sempre executa e o 304 só é
+	// enviado quando o corpo atual coincide com If-None-Match.
+	LiveValues bool
+}
+
+// ETagger adiciona um ETag fraco (FNV-64a do corpo JSON) às respostas 200 e
+// responde 304 Not Modified quando If-None-Match coincide. O hash fica no
+// Redis por cfg.TTL, o que permite responder 304 sem executar o handler
+// enquanto nenhum dos contadores de versão mudar.
+func ETagger(cache domain.PLCCache, cfg ETagConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet {
+			c.Next()
+			return
+		}
+
+		ifNoneMatch := c.GetHeader("If-None-Match")
+		ctx := context.Background()
+
+		client := redisClientOf(cache)
+		if isVolatileRequest(c, cfg.VolatileParams) {
+			client = nil
+		}
+
+		cacheKey := ""
+		if client != nil {
+			key, err := versionedETagKey(ctx, client, c, cfg)
+			if err != nil {
+				log.Printf("Aviso: erro ao ler versões do ETag %s: %v", cfg.Key, err)
+				client = nil
+			}
+			cacheKey = key
+		}
+
+		// Hash ainda válido no Redis: evita executar o handler, exceto quando
+		// o cliente pede dados atualizados (X-Bypass-Cache) ou a resposta tem
+		// valores ao vivo
+		if client != nil && !cfg.LiveValues && ifNoneMatch != "" && c.GetHeader("X-Bypass-Cache") != "true" {
+			if stored, err := client.Get(ctx, cacheKey).Result(); err == nil && etagMatches(ifNoneMatch, stored) {
+				c.Header("ETag", stored)
+				c.AbortWithStatus(http.StatusNotModified)
+				return
+			}
+		}
+
+		writer := &etagBodyWriter{ResponseWriter: c.Writer, status: http.StatusOK}
+		c.Writer = writer
+		c.Next()
+		c.Writer = writer.ResponseWriter
+
+		if writer.status != http.StatusOK {
+			c.Writer.WriteHeader(writer.status)
+			c.Writer.Write(writer.body.Bytes())
+			return
+		}
+
+		hasher := fnv.New64a()
+		hasher.Write(writer.body.Bytes())
+		etag := fmt.Sprintf(`W/"%x"`, hasher.Sum64())
+
+		if client != nil {
+			if err := client.Set(ctx, cacheKey, etag, cfg.TTL).Err(); err != nil {
+				log.Printf("Aviso: erro ao armazenar ETag %s: %v", cacheKey, err)
+			}
+		}
+
+		c.Header("ETag", etag)
+		if etagMatches(ifNoneMatch, etag) {
+			c.Writer.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		c.Writer.WriteHeader(http.StatusOK)
+		c.Writer.Write(writer.body.Bytes())
+	}
+}
+
+// isVolatileRequest indica se algum dos parâmetros voláteis veio como "true"
+func isVolatileRequest(c *gin.Context, params []string) bool {
+	for _, param := range params {
+		if c.Query(param) == "true" {
+			return true
+		}
+	}
+	return false
+}
+
+// versionedETagKey monta a chave do hash com o namespace, as versões atuais
+// dos contadores (ausente = 0) e a query string
+func versionedETagKey(ctx context.Context, client *redis.Client, c *gin.Context, cfg ETagConfig) (string, error) {
+	key := cfg.KeyPrefix + replaceRouteParams(c, cfg.Key)
+
+	if len(cfg.VersionKeys) > 0 {
+		versionKeys := make([]string, len(cfg.VersionKeys))
+		for i, versionKey := range cfg.VersionKeys {
+			versionKeys[i] = cfg.KeyPrefix + replaceRouteParams(c, versionKey)
+		}
+
+		values, err := client.MGet(ctx, versionKeys...).Result()
+		if err != nil {
+			return "", err
+		}
+
+		versions := make([]string, len(values))
+		for i, value := range values {
+			versions[i] = "0"
+			if v, ok := value.(string); ok {
+				versions[i] = v
+			}
+		}
+		key += ":v" + strings.Join(versions, ".")
+	}
+
+	if query := c.Request.URL.RawQuery; query != "" {
+		key += "?" + query
+	}
+	return key, nil
+}
+
+// redisClientOf retorna o cliente Redis do cache, se houver
+func redisClientOf(cache domain.PLCCache) *redis.Client {
+	if cache == nil {
+		return nil
+	}
+	return cache.GetRedisClient()
+}
+
+// replaceRouteParams substitui "{param}" pelos parâmetros da rota
+func replaceRouteParams(c *gin.Context, key string) string {
+	for _, param := range c.Params {
+		key = strings.ReplaceAll(key, "{"+param.Key+"}", param.Value)
+	}
+	return key
+}
+
+// etagMatches compara If-None-Match (que pode listar vários ETags) com o atual
+func etagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || candidate == etag || "W/"+candidate == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,195 @@
+package middleware
+
+import (
+	"app_padrao/internal/domain"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+)
+
+// etagTestCache expõe apenas o cliente Redis usado pelo ETagger
+type etagTestCache struct {
+	domain.PLCCache
+	client *redis.Client
+}
+
+func (c etagTestCache) GetRedisClient() *redis.Client {
+	return c.client
+}
+
+// newETagRouter monta GET /plc/:id/tags com o ETagger; body informa a
+// resposta atual e calls conta as execuções do handler
+func newETagRouter(t *testing.T, cfg ETagConfig, body *string, calls *int) (*gin.Engine, *miniredis.Miniredis) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	router := gin.New()
+	router.GET("/plc/:id/tags", ETagger(etagTestCache{client: client}, cfg), func(c *gin.Context) {
+		*calls++
+		c.String(http.StatusOK, *body)
+	})
+	return router, mr
+}
+
+func doETagRequest(router *gin.Engine, path, ifNoneMatch string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if ifNoneMatch != "" {
+		req.Header.Set("If-None-Match", ifNoneMatch)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func tagsETagConfig() ETagConfig {
+	return ETagConfig{
+		Key:            domain.ETagKeyPLCTags,
+		VersionKeys:    []string{domain.ETagVersionAllTags, domain.ETagVersionPLCTags},
+		KeyPrefix:      "app1:",
+		TTL:            30 * time.Second,
+		VolatileParams: []string{"include_derivative"},
+	}
+}
+
+func TestETaggerNotModifiedFromRedis(t *testing.T) {
+	body, calls := `{"tags":[]}`, 0
+	router, _ := newETagRouter(t, tagsETagConfig(), &body, &calls)
+
+	first := doETagRequest(router, "/plc/1/tags", "")
+	etag := first.Header().Get("ETag")
+	if first.Code != http.StatusOK || etag == "" {
+		t.Fatalf("primeira requisição: status %d, ETag %q", first.Code, etag)
+	}
+
+	second := doETagRequest(router, "/plc/1/tags", etag)
+	if second.Code != http.StatusNotModified {
+		t.Fatalf("status = %d, esperado 304", second.Code)
+	}
+	if calls != 1 {
+		t.Errorf("handler executado %d vezes, esperado 1 (304 pelo hash do Redis)", calls)
+	}
+}
+
+func TestETaggerVersionBumpInvalidates(t *testing.T) {
+	tests := []struct {
+		name       string
+		versionKey string
+	}{
+		{"versão das tags do PLC", "app1:etag:version:plc:1:tags"},
+		{"versão de todas as tags", "app1:etag:version:tags"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, calls := `{"tags":[]}`, 0
+			router, mr := newETagRouter(t, tagsETagConfig(), &body, &calls)
+
+			etag := doETagRequest(router, "/plc/1/tags", "").Header().Get("ETag")
+
+			// Mudança no cadastro: o serviço incrementa o contador
+			body = `{"tags":[{"id":1}]}`
+			if _, err := mr.Incr(tt.versionKey, 1); err != nil {
+				t.Fatal(err)
+			}
+
+			w := doETagRequest(router, "/plc/1/tags", etag)
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, esperado 200 após a mudança de versão", w.Code)
+			}
+			if w.Body.String() != body {
+				t.Errorf("corpo = %q, esperado %q", w.Body.String(), body)
+			}
+			if calls != 2 {
+				t.Errorf("handler executado %d vezes, esperado 2", calls)
+			}
+		})
+	}
+}
+
+func TestETaggerOtherPLCVersionKeepsCache(t *testing.T) {
+	body, calls := `{"tags":[]}`, 0
+	router, mr := newETagRouter(t, tagsETagConfig(), &body, &calls)
+
+	etag := doETagRequest(router, "/plc/1/tags", "").Header().Get("ETag")
+	mr.Incr("app1:etag:version:plc:2:tags", 1)
+
+	if w := doETagRequest(router, "/plc/1/tags", etag); w.Code != http.StatusNotModified {
+		t.Errorf("status = %d, esperado 304: a versão de outro PLC não afeta o PLC 1", w.Code)
+	}
+}
+
+func TestETaggerVolatileRequest(t *testing.T) {
+	body, calls := `{"tags":[{"derivative":1.5}]}`, 0
+	router, mr := newETagRouter(t, tagsETagConfig(), &body, &calls)
+
+	path := "/plc/1/tags?include_derivative=true"
+	etag := doETagRequest(router, path, "").Header().Get("ETag")
+
+	// A taxa de variação muda sem aviso ao serviço de sincronização
+	body = `{"tags":[{"derivative":2.5}]}`
+	w := doETagRequest(router, path, etag)
+	if w.Code != http.StatusOK || w.Body.String() != body {
+		t.Errorf("status %d, corpo %q: esperado 200 com a taxa atual", w.Code, w.Body.String())
+	}
+	if calls != 2 {
+		t.Errorf("handler executado %d vezes, esperado 2", calls)
+	}
+
+	// Mesma resposta: 304 calculado sobre o corpo atual
+	if w := doETagRequest(router, path, w.Header().Get("ETag")); w.Code != http.StatusNotModified {
+		t.Errorf("status = %d, esperado 304 para o mesmo corpo", w.Code)
+	}
+	if keys := mr.Keys(); len(keys) != 0 {
+		t.Errorf("respostas voláteis não devem ser guardadas no Redis: %v", keys)
+	}
+}
+
+func TestETaggerKeysUsePrefix(t *testing.T) {
+	body, calls := `{"tags":[]}`, 0
+	router, mr := newETagRouter(t, tagsETagConfig(), &body, &calls)
+
+	mr.Incr("app1:etag:version:plc:1:tags", 3)
+	doETagRequest(router, "/plc/1/tags?active=true", "")
+
+	for _, key := range mr.Keys() {
+		if !strings.HasPrefix(key, "app1:") {
+			t.Errorf("chave %q sem o namespace da instância", key)
+		}
+	}
+	if !mr.Exists("app1:etag:plc:1:tags:v0.3?active=true") {
+		t.Errorf("hash não encontrado na chave versionada; chaves: %v", mr.Keys())
+	}
+}
+
+func TestETaggerLiveValuesAlwaysRunHandler(t *testing.T) {
+	cfg := tagsETagConfig()
+	cfg.LiveValues = true
+	body, calls := `{"tags":[{"current_value":{"value":21}}]}`, 0
+	router, _ := newETagRouter(t, cfg, &body, &calls)
+
+	etag := doETagRequest(router, "/plc/1/tags", "").Header().Get("ETag")
+
+	// O valor lido do PLC muda sem incrementar os contadores de versão
+	body = `{"tags":[{"current_value":{"value":22}}]}`
+	w := doETagRequest(router, "/plc/1/tags", etag)
+	if w.Code != http.StatusOK || w.Body.String() != body {
+		t.Fatalf("status %d, corpo %q: esperado 200 com o valor atual", w.Code, w.Body.String())
+	}
+
+	if w := doETagRequest(router, "/plc/1/tags", w.Header().Get("ETag")); w.Code != http.StatusNotModified {
+		t.Errorf("status = %d, esperado 304 para o mesmo corpo", w.Code)
+	}
+	if calls != 3 {
+		t.Errorf("handler executado %d vezes, esperado 3", calls)
+	}
+}
@@ -0,0 +1,59 @@
+// internal/api/middleware/ipwhitelist.go
+package middleware
+
+import (
+	"app_padrao/internal/domain"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// IPWhitelistMiddleware permite acesso apenas a clientes cujo IP pertence a uma
+// das faixas CIDR informadas. Sem faixas configuradas, todos os IPs são aceitos.
+// Uma faixa inválida bloqueia todos os IPs: um erro de digitação não pode
+// liberar as rotas de administração (ValidateConfig já recusa a configuração).
+func IPWhitelistMiddleware(cidrs []string) gin.HandlerFunc {
+	var networks []*net.IPNet
+	for _, cidr := range cidrs {
+		cidr = strings.TrimSpace(cidr)
+		if cidr == "" {
+			continue
+		}
+
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			log.Printf("ERRO: faixa CIDR inválida na whitelist de administração, todos os IPs serão bloqueados: %q: %v", cidr, err)
+			return rejectIP
+		}
+		networks = append(networks, network)
+	}
+
+	if len(networks) == 0 {
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
+
+	return func(c *gin.Context) {
+		ip := net.ParseIP(c.ClientIP())
+		if ip != nil {
+			for _, network := range networks {
+				if network.Contains(ip) {
+					c.Next()
+					return
+				}
+			}
+		}
+
+		rejectIP(c)
+	}
+}
+
+// rejectIP recusa a requisição de um IP fora da whitelist
+func rejectIP(c *gin.Context) {
+	c.JSON(http.StatusForbidden, domain.APIError{Code: domain.ErrCodeIPNotAllowed, Message: "forbidden: IP not allowed"})
+	c.Abort()
+}
@@ -0,0 +1,128 @@
+package middleware
+
+import (
+	"app_padrao/internal/domain"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newWhitelistRouter monta GET /api/admin/ping atrás da whitelist. Com
+// trustedProxies, só esses endereços podem informar X-Forwarded-For.
+func newWhitelistRouter(t *testing.T, cidrs, trustedProxies []string) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	if err := router.SetTrustedProxies(trustedProxies); err != nil {
+		t.Fatalf("erro ao configurar proxies confiáveis: %v", err)
+	}
+	router.GET("/api/admin/ping", IPWhitelistMiddleware(cidrs), func(c *gin.Context) {
+		c.String(http.StatusOK, "ok")
+	})
+	return router
+}
+
+func doWhitelistRequest(router *gin.Engine, remoteAddr, forwardedFor string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/api/admin/ping", nil)
+	req.RemoteAddr = remoteAddr
+	if forwardedFor != "" {
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestIPWhitelistMiddleware(t *testing.T) {
+	cidrs := []string{"10.0.0.0/8", " 192.168.1.0/24 ", "2001:db8::/32"}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		want       int
+	}{
+		{"IPv4 na primeira faixa", "10.20.30.40:5000", http.StatusOK},
+		{"IPv4 na faixa com espaços", "192.168.1.77:5000", http.StatusOK},
+		{"IPv4 fora das faixas", "192.168.2.1:5000", http.StatusForbidden},
+		{"IPv6 na faixa", "[2001:db8::1]:5000", http.StatusOK},
+		{"IPv6 fora da faixa", "[2001:db9::1]:5000", http.StatusForbidden},
+		{"IPv4 mapeado em IPv6", "[::ffff:10.0.0.1]:5000", http.StatusOK},
+		{"loopback fora das faixas", "127.0.0.1:5000", http.StatusForbidden},
+	}
+
+	router := newWhitelistRouter(t, cidrs, nil)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if w := doWhitelistRequest(router, tt.remoteAddr, ""); w.Code != tt.want {
+				t.Errorf("status = %d, esperado %d", w.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestIPWhitelistMiddlewareForbiddenBody(t *testing.T) {
+	router := newWhitelistRouter(t, []string{"10.0.0.0/8"}, nil)
+
+	w := doWhitelistRequest(router, "172.16.0.1:5000", "")
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("status = %d, esperado 403", w.Code)
+	}
+
+	var body domain.APIError
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("corpo inválido: %v", err)
+	}
+	if body.Code != domain.ErrCodeIPNotAllowed || body.Message != "forbidden: IP not allowed" {
+		t.Errorf("corpo = %+v, esperado código %s e mensagem de IP não permitido", body, domain.ErrCodeIPNotAllowed)
+	}
+}
+
+func TestIPWhitelistMiddlewareForwardedFor(t *testing.T) {
+	router := newWhitelistRouter(t, []string{"10.0.0.0/8"}, []string{"192.168.100.1"})
+
+	tests := []struct {
+		name         string
+		remoteAddr   string
+		forwardedFor string
+		want         int
+	}{
+		{"cliente permitido via proxy confiável", "192.168.100.1:5000", "10.1.2.3", http.StatusOK},
+		{"cliente bloqueado via proxy confiável", "192.168.100.1:5000", "203.0.113.9", http.StatusForbidden},
+		{"cadeia de proxies usa o cliente original", "192.168.100.1:5000", "10.1.2.3, 192.168.100.1", http.StatusOK},
+		{"cabeçalho ignorado de proxy não confiável", "203.0.113.9:5000", "10.1.2.3", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if w := doWhitelistRequest(router, tt.remoteAddr, tt.forwardedFor); w.Code != tt.want {
+				t.Errorf("status = %d, esperado %d", w.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestIPWhitelistMiddlewareEmptyAllowsAll(t *testing.T) {
+	for _, cidrs := range [][]string{nil, {}, {""}, {"  "}} {
+		router := newWhitelistRouter(t, cidrs, nil)
+		for _, addr := range []string{"203.0.113.9:5000", "[2001:db8::1]:5000"} {
+			if w := doWhitelistRequest(router, addr, ""); w.Code != http.StatusOK {
+				t.Errorf("whitelist %q, cliente %s: status = %d, esperado 200", cidrs, addr, w.Code)
+			}
+		}
+	}
+}
+
+func TestIPWhitelistMiddlewareInvalidCIDRDeniesAll(t *testing.T) {
+	for _, cidrs := range [][]string{{"inválido"}, {"10.0.0.0/8", "10.0.0.300/8"}, {"10.0.0.1"}} {
+		router := newWhitelistRouter(t, cidrs, nil)
+		for _, addr := range []string{"10.0.0.1:5000", "203.0.113.9:5000"} {
+			if w := doWhitelistRequest(router, addr, ""); w.Code != http.StatusForbidden {
+				t.Errorf("whitelist %q, cliente %s: status = %d, esperado 403", cidrs, addr, w.Code)
+			}
+		}
+	}
+}
@@ -12,20 +12,20 @@ func PermissionMiddleware(userRepo domain.UserRepository, permissionCode string)
 	return func(c *gin.Context) {
 		userID, exists := c.Get("userID")
 		if !exists {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "usuário não autenticado"})
+			c.JSON(http.StatusUnauthorized, domain.APIError{Code: domain.ErrCodeUnauthorized, Message: "usuário não autenticado"})
 			c.Abort()
 			return
 		}
 
 		hasPermission, err := userRepo.HasPermission(userID.(int), permissionCode)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "erro ao verificar permissão"})
+			c.JSON(http.StatusInternalServerError, domain.APIError{Code: domain.ErrCodeInternal, Message: "erro ao verificar permissão"})
 			c.Abort()
 			return
 		}
 
 		if !hasPermission {
-			c.JSON(http.StatusForbidden, gin.H{"error": "permissão negada"})
+			c.JSON(http.StatusForbidden, domain.APIError{Code: domain.ErrCodePermissionDenied, Message: "permissão negada"})
 			c.Abort()
 			return
 		}
@@ -0,0 +1,66 @@
+// internal/api/middleware/ratelimit.go
+package middleware
+
+import (
+	"app_padrao/internal/domain"
+	"app_padrao/pkg/resilience"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UserRateLimiter limita as requisições por cliente a limit por minuto. Roda
+// antes da autenticação, então o cliente é identificado pelo IP. Com limit
+// menor ou igual a zero o limite fica desativado.
+func UserRateLimiter(limit int) gin.HandlerFunc {
+	if limit <= 0 {
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
+
+	limiter := resilience.NewRateLimiter(limit, time.Minute)
+
+	return func(c *gin.Context) {
+		if !limiter.AllowOperation(c.ClientIP()) {
+			c.Header("Retry-After", "60")
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, domain.APIError{
+				Code:    domain.ErrCodeRateLimited,
+				Message: "Limite de requisições excedido. Tente novamente em instantes",
+			})
+			return
+		}
+		c.Next()
+	}
+}
+
+// LimiterMiddleware limita as requisições de uma rota com o limitador
+// informado. Roda depois da autenticação: o cliente é o usuário, ou o IP
+// quando não há usuário no contexto. name separa as chaves de rotas que
+// compartilham o limitador. Sem limitador, não limita.
+func LimiterMiddleware(limiter resilience.Limiter, name string) gin.HandlerFunc {
+	if limiter == nil {
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
+
+	return func(c *gin.Context) {
+		key := name + ":ip:" + c.ClientIP()
+		if userID, ok := c.Get("userID"); ok {
+			key = fmt.Sprintf("%s:user:%v", name, userID)
+		}
+
+		if !limiter.AllowOperation(key) {
+			c.Header("Retry-After", "60")
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, domain.APIError{
+				Code:    domain.ErrCodeRateLimited,
+				Message: "Limite de requisições excedido. Tente novamente em instantes",
+			})
+			return
+		}
+		c.Next()
+	}
+}
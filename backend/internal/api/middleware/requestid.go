@@ -0,0 +1,33 @@
+// internal/api/middleware/requestid.go
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestIDHeader é o cabeçalho usado para propagar o identificador da requisição
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength limita IDs recebidos do cliente para não poluir os logs
+const maxRequestIDLength = 64
+
+// RequestIDMiddleware atribui um identificador a cada requisição. Um
+// X-Request-ID enviado pelo cliente é reaproveitado; caso contrário um novo é
+// gerado. O ID fica no contexto ("requestID") e volta no cabeçalho da resposta.
+func RequestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if requestID == "" || len(requestID) > maxRequestIDLength {
+			buf := make([]byte, 16)
+			rand.Read(buf)
+			requestID = hex.EncodeToString(buf)
+		}
+
+		c.Set("requestID", requestID)
+		c.Header(RequestIDHeader, requestID)
+		c.Next()
+	}
+}
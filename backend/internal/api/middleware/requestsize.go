@@ -0,0 +1,139 @@
+// internal/api/middleware/requestsize.go
+package middleware
+
+import (
+	"app_padrao/internal/domain"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/render"
+)
+
+// RequestSizeLimiter limita o corpo da requisição a maxBytes. O limite é
+// verificado durante a leitura: ao ultrapassá-lo a resposta 413 é enviada e
+// a leitura retorna erro, de modo que respostas posteriores do handler são
+// descartadas. Aplicado em uma rota depois do limite global, substitui o
+// limite global para aquela rota. Com maxBytes menor ou igual a zero o
+// corpo não é limitado.
+func RequestSizeLimiter(maxBytes int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Body == nil || c.Request.Body == http.NoBody {
+			c.Next()
+			return
+		}
+
+		// Limite já instalado por um middleware anterior: apenas substitui
+		if body, ok := c.Request.Body.(*limitedBody); ok {
+			body.limit = maxBytes
+			c.Next()
+			return
+		}
+
+		body := &limitedBody{
+			rc:            c.Request.Body,
+			limit:         maxBytes,
+			contentLength: c.Request.ContentLength,
+			ctx:           c,
+			writer:        c.Writer,
+		}
+		c.Request.Body = body
+		c.Writer = &limitedWriter{ResponseWriter: c.Writer, body: body}
+		c.Next()
+	}
+}
+
+// limitedBody conta os bytes lidos do corpo e rejeita a requisição quando o
+// limite é ultrapassado
+type limitedBody struct {
+	rc            io.ReadCloser
+	limit         int64
+	read          int64
+	contentLength int64
+	checked       bool
+	rejected      bool
+	ctx           *gin.Context
+	writer        gin.ResponseWriter // Writer original, usado para enviar o 413
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.rejected {
+		return 0, &http.MaxBytesError{Limit: b.limit}
+	}
+	if b.limit <= 0 {
+		return b.rc.Read(p)
+	}
+
+	// Content-Length declarado acima do limite: rejeita sem ler o corpo
+	if !b.checked {
+		b.checked = true
+		if b.contentLength > b.limit {
+			return 0, b.reject()
+		}
+	}
+
+	// Lê no máximo um byte além do limite para detectar o excesso
+	lr := io.LimitedReader{R: b.rc, N: b.limit + 1 - b.read}
+	n, err := lr.Read(p)
+	b.read += int64(n)
+	if b.read > b.limit {
+		return 0, b.reject()
+	}
+	return n, err
+}
+
+func (b *limitedBody) Close() error {
+	return b.rc.Close()
+}
+
+// reject envia a resposta 413 (uma única vez) e interrompe a cadeia de handlers
+func (b *limitedBody) reject() error {
+	if !b.rejected {
+		b.rejected = true
+		b.ctx.Abort()
+		if !b.writer.Written() {
+			b.writer.WriteHeader(http.StatusRequestEntityTooLarge)
+			render.JSON{Data: domain.APIError{
+				Code:    domain.ErrCodeRequestTooLarge,
+				Message: fmt.Sprintf("Corpo da requisição excede o limite de %d bytes", b.limit),
+			}}.Render(b.writer)
+		}
+	}
+	return &http.MaxBytesError{Limit: b.limit}
+}
+
+// limitedWriter descarta as escritas do handler depois que a requisição foi
+// rejeitada, para que o 413 seja a única resposta
+type limitedWriter struct {
+	gin.ResponseWriter
+	body *limitedBody
+}
+
+func (w *limitedWriter) WriteHeader(code int) {
+	if w.body.rejected {
+		return
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *limitedWriter) WriteHeaderNow() {
+	if w.body.rejected {
+		return
+	}
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *limitedWriter) Write(data []byte) (int, error) {
+	if w.body.rejected {
+		return len(data), nil
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *limitedWriter) WriteString(s string) (int, error) {
+	if w.body.rejected {
+		return len(s), nil
+	}
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,125 @@
+package middleware
+
+import (
+	"app_padrao/internal/domain"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newSizeLimitRouter monta POST /upload com o limite global e, se
+// routeLimit != 0, um limite próprio da rota. O handler responde com o
+// número de bytes lidos, ou 400 se a leitura falhar.
+func newSizeLimitRouter(globalLimit, routeLimit int64) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(RequestSizeLimiter(globalLimit))
+
+	handlers := []gin.HandlerFunc{}
+	if routeLimit != 0 {
+		handlers = append(handlers, RequestSizeLimiter(routeLimit))
+	}
+	handlers = append(handlers, func(c *gin.Context) {
+		data, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.String(http.StatusOK, strconv.Itoa(len(data)))
+	})
+	router.POST("/upload", handlers...)
+	return router
+}
+
+// doSizeRequest envia size bytes; chunked omite o Content-Length
+func doSizeRequest(router *gin.Engine, size int, chunked bool) *httptest.ResponseRecorder {
+	var body io.Reader = strings.NewReader(strings.Repeat("a", size))
+	if chunked {
+		// Sem Len(), o Content-Length fica desconhecido (-1)
+		body = io.MultiReader(body)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/upload", body)
+	if chunked {
+		req.ContentLength = -1
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func assertTooLarge(t *testing.T, w *httptest.ResponseRecorder, limit int64) {
+	t.Helper()
+
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("status = %d, esperado 413 (corpo %q)", w.Code, w.Body.String())
+	}
+
+	var resp domain.APIError
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("corpo do 413 deveria ser só o erro JSON: %q: %v", w.Body.String(), err)
+	}
+	if resp.Code != domain.ErrCodeRequestTooLarge {
+		t.Errorf("código = %s, esperado %s", resp.Code, domain.ErrCodeRequestTooLarge)
+	}
+	if !strings.Contains(resp.Message, strconv.FormatInt(limit, 10)) {
+		t.Errorf("mensagem %q deveria informar o limite %d", resp.Message, limit)
+	}
+}
+
+func TestRequestSizeLimiterRejectsLargeBody(t *testing.T) {
+	router := newSizeLimitRouter(100, 0)
+
+	t.Run("Content-Length acima do limite", func(t *testing.T) {
+		assertTooLarge(t, doSizeRequest(router, 101, false), 100)
+	})
+	t.Run("corpo sem Content-Length acima do limite", func(t *testing.T) {
+		assertTooLarge(t, doSizeRequest(router, 5000, true), 100)
+	})
+}
+
+func TestRequestSizeLimiterAcceptsBodyWithinLimit(t *testing.T) {
+	router := newSizeLimitRouter(100, 0)
+
+	for _, tc := range []struct {
+		size    int
+		chunked bool
+	}{
+		{0, false},
+		{50, false},
+		{100, false},
+		{100, true},
+	} {
+		w := doSizeRequest(router, tc.size, tc.chunked)
+		if w.Code != http.StatusOK || w.Body.String() != strconv.Itoa(tc.size) {
+			t.Errorf("%d bytes (chunked=%v): status %d, corpo %q; esperado 200 com %d",
+				tc.size, tc.chunked, w.Code, w.Body.String(), tc.size)
+		}
+	}
+}
+
+func TestRequestSizeLimiterRouteOverridesGlobal(t *testing.T) {
+	// Rota de upload com limite maior que o global
+	router := newSizeLimitRouter(100, 2048)
+	if w := doSizeRequest(router, 1500, false); w.Code != http.StatusOK {
+		t.Errorf("corpo dentro do limite da rota: status = %d, esperado 200", w.Code)
+	}
+	assertTooLarge(t, doSizeRequest(router, 2049, true), 2048)
+
+	// Rota com limite menor que o global
+	router = newSizeLimitRouter(2048, 10)
+	assertTooLarge(t, doSizeRequest(router, 11, false), 10)
+}
+
+func TestRequestSizeLimiterDisabled(t *testing.T) {
+	router := newSizeLimitRouter(0, 0)
+	if w := doSizeRequest(router, 1<<20, false); w.Code != http.StatusOK {
+		t.Errorf("sem limite: status = %d, esperado 200", w.Code)
+	}
+}
@@ -0,0 +1,132 @@
+package route
+
+import (
+	"app_padrao/internal/api/handler"
+	"app_padrao/internal/domain"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+)
+
+// etagPLCService devolve o valor atual da tag e o status do PLC, que mudam
+// sem alterar o cadastro
+type etagPLCService struct {
+	domain.PLCService
+	value  interface{}
+	status string
+}
+
+func (s *etagPLCService) GetPLCTags(plcID int) ([]domain.PLCTag, error) {
+	return []domain.PLCTag{{ID: 1, PLCID: plcID, Name: "temperatura", DataType: "real", Active: true,
+		CurrentValue: &domain.TagReading{Value: s.value, Quality: "good"}}}, nil
+}
+
+func (s *etagPLCService) ListPLCs(ctx context.Context, filter domain.PLCFilter, page, pageSize int, bypassCache bool, userID int) ([]domain.PLC, int, error) {
+	return []domain.PLC{{ID: 7, Name: "CLP_Linha1", Active: true, Status: s.status}}, 1, nil
+}
+
+func (s *etagPLCService) RecordTagAccess(entries []domain.TagAccessLog) {}
+
+// etagRouteCache expõe apenas o cliente Redis usado pelo ETagger
+type etagRouteCache struct {
+	domain.PLCCache
+	client *redis.Client
+}
+
+func (c etagRouteCache) GetRedisClient() *redis.Client {
+	return c.client
+}
+
+func newETagRouteRouter(t *testing.T, plcService *etagPLCService) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	t.Setenv("AVATAR_DIRECTORY", t.TempDir())
+
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	router := gin.New()
+	SetupRoutes(router,
+		handler.NewAuthHandler(nil),
+		handler.NewUserHandler(nil),
+		handler.NewAdminHandler(nil, nil),
+		handler.NewPermissionHandler(nil),
+		handler.NewProfileHandler(nil, nil, nil),
+		handler.NewPLCHandler(plcService),
+		handler.NewSystemHandler(plcService, nil, nil, 0),
+		&versioningUserRepo{},
+		"segredo",
+		nil,
+		nil,
+		1024,
+		VersioningConfig{DisableLegacyRoutes: true},
+		&Application{
+			Cache:         etagRouteCache{client: client},
+			ETagKeyPrefix: "app1:",
+			TokenValidator: func(token string) (int, error) {
+				if token != "valido" {
+					return 0, errors.New("token inválido")
+				}
+				return 1, nil
+			},
+		},
+	)
+	return router
+}
+
+func TestLiveValuesAreNotServedFromStoredETag(t *testing.T) {
+	tests := []struct {
+		name   string
+		path   string
+		change func(s *etagPLCService)
+	}{
+		{"valor atual da tag", "/api/v1/plc/7/tags", func(s *etagPLCService) { s.value = 22.5 }},
+		{"status do PLC", "/api/v1/plc/", func(s *etagPLCService) { s.status = "offline" }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plcService := &etagPLCService{value: 21.0, status: "online"}
+			router := newETagRouteRouter(t, plcService)
+
+			request := func(ifNoneMatch string) *httptest.ResponseRecorder {
+				req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+				req.Header.Set("Authorization", "Bearer valido")
+				if ifNoneMatch != "" {
+					req.Header.Set("If-None-Match", ifNoneMatch)
+				}
+				w := httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				return w
+			}
+
+			first := request("")
+			etag := first.Header().Get("ETag")
+			if first.Code != http.StatusOK || etag == "" {
+				t.Fatalf("primeira requisição: status %d, ETag %q: %s", first.Code, etag, first.Body.String())
+			}
+
+			// Sem mudança: 304 calculado sobre o corpo atual
+			if w := request(etag); w.Code != http.StatusNotModified {
+				t.Fatalf("status = %d, esperado 304 sem mudança", w.Code)
+			}
+
+			// O valor muda sem incrementar os contadores de versão
+			tt.change(plcService)
+			w := request(etag)
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, esperado 200 após a mudança do valor", w.Code)
+			}
+			if w.Header().Get("ETag") == etag {
+				t.Error("ETag deveria mudar junto com o valor")
+			}
+		})
+	}
+}
@@ -4,14 +4,22 @@ package route
 import (
 	"app_padrao/internal/api/handler"
 	"app_padrao/internal/api/middleware"
+	"app_padrao/internal/diagnostics"
 	"app_padrao/internal/domain"
 	"app_padrao/internal/health"
 	"app_padrao/internal/metrics"
+	"app_padrao/pkg/jwt"
 	"app_padrao/pkg/resilience"
+	"context"
+	"database/sql"
 	"fmt"
 	"log"
+	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -21,7 +29,45 @@ import (
 type Application struct {
 	MetricsCollector *metrics.MetricsCollector
 	HealthChecker    *health.HealthCheck
-	RateLimiter      *resilience.RateLimiter // Campo adicionado para o rate limiter
+	RateLimiter      resilience.Limiter // Limite de operações por PLC, compartilhado entre instâncias
+	Cache            domain.PLCCache    // Armazena os ETags das listagens
+	ETagKeyPrefix    string             // Namespace das chaves dos ETags (REDIS_KEY_PREFIX)
+
+	// Limite por usuário da leitura e gravação de bytes brutos dos DBs
+	DBAccessLimiter resilience.Limiter
+
+	// Valida os tokens de sessão aceitando o segredo JWT anterior durante a
+	// rotação (nil = apenas jwtSecret)
+	TokenValidator middleware.TokenValidator
+
+	// Sondas de liveness, readiness e startup
+	DB              *sql.DB
+	StartupComplete func() bool // true após a sincronização inicial dos PLCs
+	ProbePathPrefix string      // ex.: /k8s (vazio = /health/...)
+}
+
+// probeTimeout é o prazo do ping ao banco na sonda de readiness
+const probeTimeout = 2 * time.Second
+
+// etagTTL é a validade dos ETags armazenados no Redis
+const etagTTL = 30 * time.Second
+
+// importMaxSizeBytes é o tamanho máximo dos arquivos de importação de tags
+const importMaxSizeBytes = 10 * 1024 * 1024
+
+// VersioningConfig define os prefixos registrados para a API autenticada.
+// As rotas canônicas ficam em /api/v1; os caminhos antigos em /api continuam
+// disponíveis como aliases obsoletos até LegacySunset.
+type VersioningConfig struct {
+	DisableLegacyRoutes bool
+	LegacySunset        time.Time
+}
+
+// CORSConfig define a política CORS aplicada a todas as rotas
+type CORSConfig struct {
+	AllowedOrigins []string
+	AllowedHeaders []string
+	MaxAge         int
 }
 
 // SetupRoutes configura as rotas da API
@@ -33,21 +79,34 @@ func SetupRoutes(
 	permissionHandler *handler.PermissionHandler,
 	profileHandler *handler.ProfileHandler,
 	plcHandler *handler.PLCHandler,
+	systemHandler *handler.SystemHandler,
 	userRepo domain.UserRepository,
 	jwtSecret string,
+	adminAllowedCIDRs []string,
+	dashboardAccounts gin.Accounts,
+	avatarMaxSizeBytes int64,
+	versioning VersioningConfig,
 	app *Application,
 ) {
-	// CORS - Configuração melhorada
-	router.Use(corsMiddleware())
+	// Whitelist de IPs para rotas administrativas
+	adminIPWhitelist := middleware.IPWhitelistMiddleware(adminAllowedCIDRs)
+
+	// Validação dos tokens de sessão
+	validateToken := app.TokenValidator
+	if validateToken == nil {
+		validateToken = func(token string) (int, error) {
+			return jwt.ValidateToken(token, jwtSecret)
+		}
+	}
 
-	// Configuração de diretórios estáticos
-	setupStaticDirectories(router)
+	// Limite compartilhado por /api e /api/v1 nas rotas de bytes brutos dos DBs
+	dbAccessLimit := middleware.LimiterMiddleware(app.DBAccessLimiter, "plc-db")
 
-	// Middleware de recuperação para evitar pânico
-	router.Use(gin.Recovery())
+	// Os middlewares globais (request ID, rate limit, log, recovery e CORS)
+	// são registrados em api.NewServer
 
-	// Middleware de logging personalizado
-	router.Use(requestLogger())
+	// Configuração de diretórios estáticos
+	setupStaticDirectories(router)
 
 	// Rotas para verificação de saúde da API
 	setupHealthRoutes(router, app)
@@ -55,24 +114,79 @@ func SetupRoutes(
 	// Autenticação
 	setupAuthRoutes(router, authHandler)
 
+	// Dashboard HTML de diagnóstico (Basic Auth, fora da API com JWT)
+	if len(dashboardAccounts) > 0 {
+		router.GET("/admin/dashboard", adminIPWhitelist, gin.BasicAuth(dashboardAccounts), systemHandler.Dashboard)
+	} else {
+		log.Println("Dashboard /admin/dashboard desabilitado: SERVER_DASHBOARD_USER/SERVER_DASHBOARD_PASSWORD não configurados")
+	}
+
 	// API autenticada
-	api := router.Group("/api")
-	api.Use(middleware.AuthMiddleware(jwtSecret))
-	{
-		// Perfil e permissões
-		setupProfileRoutes(api, profileHandler)
+	v1 := router.Group("/api/v1")
+	v1.Use(middleware.AuthMiddleware(validateToken))
+	RegisterV1Routes(v1, adminHandler, permissionHandler, profileHandler, plcHandler, systemHandler,
+		userRepo, adminIPWhitelist, dbAccessLimit, avatarMaxSizeBytes, app.Cache, app.ETagKeyPrefix)
+
+	// Caminhos sem versão, mantidos como aliases obsoletos de /api/v1
+	if versioning.DisableLegacyRoutes {
+		log.Println("Rotas /api sem versão desabilitadas: use /api/v1")
+		return
+	}
+	legacy := router.Group("/api")
+	legacy.Use(DeprecationMiddleware(versioning.LegacySunset), middleware.AuthMiddleware(validateToken))
+	RegisterV1Routes(legacy, adminHandler, permissionHandler, profileHandler, plcHandler, systemHandler,
+		userRepo, adminIPWhitelist, dbAccessLimit, avatarMaxSizeBytes, app.Cache, app.ETagKeyPrefix)
+}
+
+// RegisterV1Routes registra as rotas da API autenticada no grupo informado.
+// O grupo deve aplicar a autenticação JWT.
+func RegisterV1Routes(
+	rg *gin.RouterGroup,
+	adminHandler *handler.AdminHandler,
+	permissionHandler *handler.PermissionHandler,
+	profileHandler *handler.ProfileHandler,
+	plcHandler *handler.PLCHandler,
+	systemHandler *handler.SystemHandler,
+	userRepo domain.UserRepository,
+	adminIPWhitelist gin.HandlerFunc,
+	dbAccessLimit gin.HandlerFunc,
+	avatarMaxSizeBytes int64,
+	etagCache domain.PLCCache,
+	etagKeyPrefix string,
+) {
+	// Perfil e permissões
+	setupProfileRoutes(rg, profileHandler, avatarMaxSizeBytes)
 
-		// Temas
-		api.GET("/themes", profileHandler.GetThemes)
+	// Temas
+	rg.GET("/themes", middleware.ETagger(etagCache, middleware.ETagConfig{
+		Key:       "etag:themes",
+		KeyPrefix: etagKeyPrefix,
+		TTL:       etagTTL,
+	}), profileHandler.GetThemes)
 
-		// Permissões
-		api.GET("/permissions", permissionHandler.GetUserPermissions)
+	// Permissões
+	rg.GET("/permissions", permissionHandler.GetUserPermissions)
 
-		// Admin
-		setupAdminRoutes(api, adminHandler, userRepo)
+	// Admin
+	setupAdminRoutes(rg, adminHandler, profileHandler, systemHandler, userRepo, adminIPWhitelist)
+
+	// PLC routes
+	setupPLCRoutes(rg, plcHandler, userRepo, adminIPWhitelist, dbAccessLimit, etagCache, etagKeyPrefix)
+	setupPLCAdminRoutes(rg, plcHandler, userRepo, adminIPWhitelist)
+}
 
-		// PLC routes
-		setupPLCRoutes(api, plcHandler, userRepo)
+// DeprecationMiddleware marca as respostas dos caminhos obsoletos com os
+// cabeçalhos Deprecation e Sunset (RFC 8594) e indica o caminho em /api/v1
+func DeprecationMiddleware(sunsetDate time.Time) gin.HandlerFunc {
+	sunset := sunsetDate.UTC().Format(http.TimeFormat)
+
+	return func(c *gin.Context) {
+		c.Header("Deprecation", "true")
+		c.Header("Sunset", sunset)
+		if rest, ok := strings.CutPrefix(c.Request.URL.Path, "/api"); ok {
+			c.Header("Link", fmt.Sprintf("</api/v1%s>; rel=\"successor-version\"", rest))
+		}
+		c.Next()
 	}
 }
 
@@ -127,6 +241,8 @@ func setupHealthRoutes(router *gin.Engine, app *Application) {
 		})
 	})
 
+	setupProbeRoutes(router, app)
+
 	// Rota de verificação de tempo de atividade
 	router.GET("/uptime", func(c *gin.Context) {
 		c.String(200, fmt.Sprintf("Servidor iniciado em: %s", time.Now().Format(time.RFC3339)))
@@ -136,7 +252,7 @@ func setupHealthRoutes(router *gin.Engine, app *Application) {
 	router.GET("/health/detailed", func(c *gin.Context) {
 		// Verificar se a aplicação e o health checker estão disponíveis
 		if app == nil || app.HealthChecker == nil {
-			c.JSON(500, gin.H{"error": "Health checker not available"})
+			handler.ErrorResponse(c, 500, domain.ErrCodeInternal, "Health checker not available", nil)
 			return
 		}
 
@@ -147,11 +263,44 @@ func setupHealthRoutes(router *gin.Engine, app *Application) {
 		})
 	})
 
+	// Saúde apenas das conexões com PLCs
+	router.GET("/health/plcs", func(c *gin.Context) {
+		if app == nil || app.HealthChecker == nil {
+			handler.ErrorResponse(c, 500, domain.ErrCodeInternal, "Health checker not available", nil)
+			return
+		}
+
+		components := app.HealthChecker.GetPLCHealth()
+		status := health.StatusHealthy
+		for _, component := range components {
+			if component.Status != health.StatusHealthy {
+				status = health.StatusDegraded
+				break
+			}
+		}
+
+		c.JSON(200, gin.H{
+			"status":     status,
+			"components": components,
+			"timestamp":  time.Now().Format(time.RFC3339),
+		})
+	})
+
+	// Última latência medida do PostgreSQL e do Redis
+	router.GET("/health/latency", func(c *gin.Context) {
+		if app == nil || app.HealthChecker == nil {
+			handler.ErrorResponse(c, 500, domain.ErrCodeInternal, "Health checker not available", nil)
+			return
+		}
+
+		c.JSON(200, app.HealthChecker.GetLatency())
+	})
+
 	// Rota para métricas do sistema
 	router.GET("/metrics", func(c *gin.Context) {
 		// Verificar se a aplicação e o metrics collector estão disponíveis
 		if app == nil || app.MetricsCollector == nil {
-			c.JSON(500, gin.H{"error": "Metrics collector not available"})
+			handler.ErrorResponse(c, 500, domain.ErrCodeInternal, "Metrics collector not available", nil)
 			return
 		}
 
@@ -159,28 +308,82 @@ func setupHealthRoutes(router *gin.Engine, app *Application) {
 	})
 }
 
+// setupProbeRoutes configura as sondas no estilo Kubernetes, sem autenticação:
+// live (o processo responde), ready (banco acessível e sem alterações de
+// esquema pendentes) e startup (sincronização inicial dos PLCs concluída)
+func setupProbeRoutes(router *gin.Engine, app *Application) {
+	prefix := ""
+	if app != nil {
+		prefix = app.ProbePathPrefix
+	}
+
+	router.GET(prefix+"/health/live", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"status": "alive"})
+	})
+
+	router.GET(prefix+"/health/ready", func(c *gin.Context) {
+		if app == nil || app.DB == nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"status": "not_ready", "reason": "banco de dados não configurado"})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), probeTimeout)
+		defer cancel()
+		if err := app.DB.PingContext(ctx); err != nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"status": "not_ready", "reason": "banco de dados inacessível: " + err.Error()})
+			return
+		}
+
+		if diagnostics.MigrationsPending(app.DB) {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"status": "not_ready", "reason": "alterações de esquema pendentes"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"status": "ready"})
+	})
+
+	router.GET(prefix+"/health/startup", func(c *gin.Context) {
+		if app == nil || app.StartupComplete == nil || !app.StartupComplete() {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"status": "starting"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "started"})
+	})
+}
+
 // setupAuthRoutes configura as rotas de autenticação
 func setupAuthRoutes(router *gin.Engine, authHandler *handler.AuthHandler) {
 	router.POST("/register", authHandler.Register)
 	router.POST("/login", authHandler.Login)
+	// GET atende o link enviado por email; POST continua disponível para o frontend
+	router.GET("/auth/email/verify", authHandler.VerifyEmail)
+	router.POST("/auth/email/verify", authHandler.VerifyEmail)
 	// Remover rota não implementada
 	// router.POST("/refresh-token", authHandler.RefreshToken)
 }
 
 // setupProfileRoutes configura as rotas de perfil
-func setupProfileRoutes(api *gin.RouterGroup, profileHandler *handler.ProfileHandler) {
+func setupProfileRoutes(api *gin.RouterGroup, profileHandler *handler.ProfileHandler, avatarMaxSizeBytes int64) {
 	api.GET("/profile", profileHandler.GetProfile)
 	api.PUT("/profile", profileHandler.UpdateProfile)
-	api.POST("/profile/avatar", profileHandler.UploadAvatar)
+	api.GET("/profile/completeness", profileHandler.GetCompleteness)
+	api.GET("/profile/notification-channels", profileHandler.GetNotificationChannels)
+	api.PUT("/profile/notification-channels", profileHandler.UpdateNotificationChannels)
+	api.PUT("/profile/theme", profileHandler.UpdateTheme)
+	api.GET("/profile/events", profileHandler.StreamProfileEvents)
+	api.GET("/profile/dashboard", profileHandler.GetDashboard)
+	api.PUT("/profile/dashboard", profileHandler.UpdateDashboard)
+	api.DELETE("/profile/dashboard", profileHandler.ResetDashboard)
+	api.POST("/profile/avatar", middleware.RequestSizeLimiter(avatarMaxSizeBytes), profileHandler.UploadAvatar)
 	api.DELETE("/profile/avatar", profileHandler.DeleteAvatar)
 	api.PUT("/profile/password", profileHandler.ChangePassword)
 	api.DELETE("/profile", profileHandler.DeleteAccount)
 }
 
 // setupAdminRoutes configura as rotas de administração
-func setupAdminRoutes(api *gin.RouterGroup, adminHandler *handler.AdminHandler, userRepo domain.UserRepository) {
+func setupAdminRoutes(api *gin.RouterGroup, adminHandler *handler.AdminHandler, profileHandler *handler.ProfileHandler, systemHandler *handler.SystemHandler, userRepo domain.UserRepository, ipWhitelist gin.HandlerFunc) {
 	admin := api.Group("/admin")
-	admin.Use(middleware.PermissionMiddleware(userRepo, "admin_panel"))
+	admin.Use(ipWhitelist, middleware.PermissionMiddleware(userRepo, "admin_panel"))
 	{
 		// Usuários
 		admin.GET("/users", adminHandler.ListUsers)
@@ -188,56 +391,196 @@ func setupAdminRoutes(api *gin.RouterGroup, adminHandler *handler.AdminHandler,
 		admin.PUT("/users/:id", adminHandler.UpdateUser)
 		admin.DELETE("/users/:id", adminHandler.DeleteUser)
 		admin.POST("/users", adminHandler.CreateUser)
+		admin.POST("/users/:id/theme", profileHandler.UpdateUserTheme)
 
 		// Roles - Apenas a rota que existe no handler
 		admin.GET("/roles", adminHandler.ListRoles)
+		admin.GET("/roles/:id/effective-permissions", adminHandler.GetEffectivePermissions)
 		// Remover rotas não implementadas
 		// admin.GET("/roles/:id", adminHandler.GetRole)
 		// admin.POST("/roles", adminHandler.CreateRole)
 		// admin.PUT("/roles/:id", adminHandler.UpdateRole)
 		// admin.DELETE("/roles/:id", adminHandler.DeleteRole)
+
+		// Domínios de email aceitos no cadastro
+		admin.POST("/config/email-domains", adminHandler.UpdateEmailDomains)
+
+		// Rotação do segredo JWT sem reinício
+		admin.POST("/jwt/rotate", adminHandler.RotateJWTSecret)
+
+		// Diagnóstico do processo
+		admin.GET("/goroutines", systemHandler.GetGoroutines)
+		admin.GET("/db/pool-stats", systemHandler.GetDBPoolStats)
+		admin.POST("/redis/migrate-keys", systemHandler.MigrateRedisKeys)
 	}
 }
 
 // setupPLCRoutes configura as rotas de PLC
-func setupPLCRoutes(api *gin.RouterGroup, plcHandler *handler.PLCHandler, userRepo domain.UserRepository) {
+func setupPLCRoutes(api *gin.RouterGroup, plcHandler *handler.PLCHandler, userRepo domain.UserRepository, ipWhitelist, dbAccessLimit gin.HandlerFunc, etagCache domain.PLCCache, etagKeyPrefix string) {
 	plc := api.Group("/plc")
 	{
 		// Rotas básicas de PLC
-		plc.GET("/", plcHandler.GetAllPLCs)
+		plc.GET("/", middleware.ETagger(etagCache, middleware.ETagConfig{
+			Key:         domain.ETagKeyPLCList,
+			VersionKeys: []string{domain.ETagVersionPLCList},
+			KeyPrefix:   etagKeyPrefix,
+			TTL:         etagTTL,
+			LiveValues:  true, // status da conexão
+		}), plcHandler.GetAllPLCs)
 		plc.GET("/:id", plcHandler.GetPLC)
 		plc.POST("/", middleware.PermissionMiddleware(userRepo, "plc_create"), plcHandler.CreatePLC)
 		plc.PUT("/:id", middleware.PermissionMiddleware(userRepo, "plc_update"), plcHandler.UpdatePLC)
 		plc.DELETE("/:id", middleware.PermissionMiddleware(userRepo, "plc_delete"), plcHandler.DeletePLC)
 
+		// Sites (plantas)
+		plc.GET("/sites", plcHandler.GetPLCSites)
+		plc.POST("/sites", middleware.PermissionMiddleware(userRepo, "plc_create"), plcHandler.CreatePLCSite)
+		plc.PUT("/sites/:id", middleware.PermissionMiddleware(userRepo, "plc_update"), plcHandler.UpdatePLCSite)
+		plc.DELETE("/sites/:id", middleware.PermissionMiddleware(userRepo, "plc_delete"), plcHandler.DeletePLCSite)
+		plc.GET("/sites/:id/health", plcHandler.GetPLCSiteHealth)
+
+		// Grupos de varredura (tags lidas juntas)
+		plc.GET("/:id/scan-groups", plcHandler.GetScanGroups)
+		plc.POST("/:id/scan-groups", middleware.PermissionMiddleware(userRepo, "plc_tag_update"), plcHandler.CreateScanGroup)
+		plc.PUT("/scan-groups/:id/tags", middleware.PermissionMiddleware(userRepo, "plc_tag_update"), plcHandler.AssignScanGroupTags)
+		plc.DELETE("/scan-groups/:id", middleware.PermissionMiddleware(userRepo, "plc_tag_update"), plcHandler.DeleteScanGroup)
+
 		// Rotas de tags
-		plc.GET("/:id/tags", plcHandler.GetPLCTags)
+		plc.GET("/:id/tags", middleware.ETagger(etagCache, middleware.ETagConfig{
+			Key:            domain.ETagKeyPLCTags,
+			VersionKeys:    []string{domain.ETagVersionAllTags, domain.ETagVersionPLCTags},
+			KeyPrefix:      etagKeyPrefix,
+			TTL:            etagTTL,
+			VolatileParams: []string{"include_derivative"},
+			LiveValues:     true, // current_value
+		}), plcHandler.GetPLCTags)
+		plc.GET("/:id/performance", plcHandler.GetPLCPerformance)
+		plc.GET("/tags/search", plcHandler.SearchTags)
+		plc.GET("/tags/name-convention", plcHandler.GetTagNameConvention)
+		plc.GET("/tags/idle", plcHandler.GetIdleTags)
+		plc.POST("/tags/idle/apply-suggestions", middleware.PermissionMiddleware(userRepo, "plc_tag_update"), plcHandler.ApplyIdleTagSuggestions)
+		plc.POST("/:id/tags/adapt-scan-rates", middleware.PermissionMiddleware(userRepo, "plc_tag_update"), plcHandler.AdaptScanRates)
+		plc.GET("/tags/auto-disabled", plcHandler.GetAutoDisabledTags)
 		plc.GET("/tags/:id", plcHandler.GetTagByID)
+		plc.POST("/tags/:id/enable", middleware.PermissionMiddleware(userRepo, "plc_tag_update"), plcHandler.EnableTag)
+		plc.GET("/tags/:id/annotations", plcHandler.GetTagAnnotations)
+		plc.POST("/tags/:id/annotations", plcHandler.CreateTagAnnotation)
+		plc.DELETE("/tags/:id/annotations/:annotID", plcHandler.DeleteTagAnnotation)
+		plc.GET("/tags/:id/dependencies", plcHandler.GetTagDependencies)
+		plc.GET("/tags/:id/history/csv", plcHandler.ExportTagHistoryCSV)
+		plc.POST("/tags/:id/dependencies", middleware.PermissionMiddleware(userRepo, "plc_tag_update"), plcHandler.CreateTagDependency)
+		plc.DELETE("/tags/:id/dependencies/:depID", middleware.PermissionMiddleware(userRepo, "plc_tag_update"), plcHandler.DeleteTagDependency)
+		plc.GET("/:id/tags/conflicts", plcHandler.GetTagAddressConflicts)
+		plc.GET("/:id/tags/values/initial", plcHandler.GetInitialTagValues)
+		plc.GET("/:id/tags/:tagID/derivative", plcHandler.GetTagDerivative)
+		plc.GET("/:id/tags/:tagID/history", plcHandler.GetTagHistory)
+		plc.GET("/:id/tags/:tagID/history/influx", plcHandler.ExportTagHistoryInflux)
+		plc.GET("/history/influx", plcHandler.ExportHistoryInflux)
+		plc.GET("/sse/tags", plcHandler.StreamTags)
+		plc.GET("/:id/events", plcHandler.StreamPLCEvents)
+		plc.GET("/events", ipWhitelist, middleware.PermissionMiddleware(userRepo, "plc_admin"), plcHandler.StreamAllPLCEvents)
 		plc.POST("/:id/tags", middleware.PermissionMiddleware(userRepo, "plc_tag_create"), plcHandler.CreatePLCTag)
+		plc.POST("/:id/import/wonderware", middleware.RequestSizeLimiter(importMaxSizeBytes), middleware.PermissionMiddleware(userRepo, "plc_tag_create"), plcHandler.ImportWonderwareTags)
 		plc.PUT("/tags/:id", middleware.PermissionMiddleware(userRepo, "plc_tag_update"), plcHandler.UpdatePLCTag)
+		plc.POST("/tags/:id/migrate-type", middleware.PermissionMiddleware(userRepo, "plc_tag_update"), plcHandler.MigrateTagType)
 		plc.DELETE("/tags/:id", middleware.PermissionMiddleware(userRepo, "plc_tag_delete"), plcHandler.DeletePLCTag)
+		plc.DELETE("/:id/tags/bulk", middleware.PermissionMiddleware(userRepo, "plc_tag_delete"), plcHandler.BulkDeletePLCTags)
+		plc.PATCH("/:id/tags/bulk", middleware.PermissionMiddleware(userRepo, "plc_tag_update"), plcHandler.BulkUpdatePLCTags)
 
 		// Operações de escrita
 		plc.POST("/tag/write", middleware.PermissionMiddleware(userRepo, "plc_write"), plcHandler.WriteTagValue)
+		plc.POST("/tag/sequence-write", middleware.PermissionMiddleware(userRepo, "plc_write"), plcHandler.WriteTagSequence)
+		plc.GET("/:id/write-queue", middleware.PermissionMiddleware(userRepo, "plc_write"), plcHandler.GetWriteQueue)
+		plc.DELETE("/:id/write-queue/:requestID", middleware.PermissionMiddleware(userRepo, "plc_write"), plcHandler.CancelQueuedWrite)
+
+		// Alarmes
+		plc.GET("/alarms/active", plcHandler.GetActiveAlarms)
+		plc.POST("/alarms/:id/acknowledge", middleware.PermissionMiddleware(userRepo, "plc_write"), plcHandler.AcknowledgeAlarm)
 
 		// Diagnóstico e estatísticas
 		plc.GET("/diagnostic/tags", plcHandler.DiagnosticTags)
-		plc.POST("/reset/:id", middleware.PermissionMiddleware(userRepo, "plc_admin"), plcHandler.ResetPLCConnection)
+		plc.POST("/reset/:id", ipWhitelist, middleware.PermissionMiddleware(userRepo, "plc_admin"), plcHandler.ResetPLCConnection)
+		plc.PUT("/:id/monitoring/enable", ipWhitelist, middleware.PermissionMiddleware(userRepo, "plc_admin"), plcHandler.EnablePLCMonitoring)
+		plc.PUT("/:id/monitoring/disable", ipWhitelist, middleware.PermissionMiddleware(userRepo, "plc_admin"), plcHandler.DisablePLCMonitoring)
 		plc.GET("/health", plcHandler.GetPLCHealth)
 		plc.GET("/stats", plcHandler.GetDetailedStats)
 		plc.GET("/status", plcHandler.GetPLCStatus)
+
+		// Bytes brutos dos DBs, para PLCs sem tabela de símbolos
+		plc.GET("/:id/db/:dbNumber/browse", ipWhitelist, middleware.PermissionMiddleware(userRepo, "plc_admin"), dbAccessLimit, plcHandler.BrowseDataBlock)
+		plc.PUT("/:id/db/:dbNumber/poke", ipWhitelist, middleware.PermissionMiddleware(userRepo, "plc_admin"), dbAccessLimit, plcHandler.PokeDataBlock)
 	}
 }
 
-// corsMiddleware cria o middleware CORS com configurações seguras
-func corsMiddleware() gin.HandlerFunc {
+// setupPLCAdminRoutes configura as rotas administrativas do sistema de PLCs
+func setupPLCAdminRoutes(api *gin.RouterGroup, plcHandler *handler.PLCHandler, userRepo domain.UserRepository, ipWhitelist gin.HandlerFunc) {
+	plcAdmin := api.Group("/admin/plc")
+	plcAdmin.Use(ipWhitelist, middleware.PermissionMiddleware(userRepo, "plc_admin"))
+	{
+		// Sincronização PostgreSQL -> Redis
+		plcAdmin.POST("/sync/force", plcHandler.ForceSync)
+		plcAdmin.PUT("/sync/interval", plcHandler.SetSyncInterval)
+		plcAdmin.GET("/sync/status", plcHandler.GetSyncStatus)
+		plcAdmin.POST("/sync/clear-tracker", plcHandler.ClearSyncTracker)
+		plcAdmin.GET("/sync/errors", plcHandler.GetSyncErrors)
+		plcAdmin.GET("/sync/lock-status", plcHandler.GetSyncLockStatus)
+
+		// Configuração alterável em execução
+		plcAdmin.GET("/config", plcHandler.GetPLCConfig)
+		plcAdmin.PUT("/config", plcHandler.UpdatePLCConfig)
+
+		// Limite de tags próprio de um PLC
+		plcAdmin.PUT("/:id/tag-limit", plcHandler.SetPLCTagLimit)
+
+		// Fila de gravação e retenção do histórico
+		plcAdmin.GET("/history-queue", plcHandler.GetHistoryQueue)
+		plcAdmin.GET("/history/stats", plcHandler.GetHistoryStats)
+
+		// Log de acesso às tags (auditoria)
+		plcAdmin.GET("/access-log", plcHandler.GetTagAccessLog)
+
+		// Cache em memória dos PLCs
+		plcAdmin.GET("/metadata-cache/stats", plcHandler.GetPLCMetadataCacheStats)
+
+		// Monitor de depuração
+		plcAdmin.POST("/debug-monitor/start", plcHandler.StartDebugMonitor)
+		plcAdmin.POST("/debug-monitor/stop", plcHandler.StopDebugMonitor)
+	}
+}
+
+// CORSMiddleware cria o middleware CORS. Com "*" qualquer origem é aceita;
+// caso contrário só origens da lista recebem os cabeçalhos CORS.
+func CORSMiddleware(config CORSConfig) gin.HandlerFunc {
+	allowAll := false
+	for _, origin := range config.AllowedOrigins {
+		if origin == "*" {
+			allowAll = true
+			break
+		}
+	}
+
+	allowedHeaders := strings.Join(config.AllowedHeaders, ", ")
+	maxAge := strconv.Itoa(config.MaxAge)
+
 	return func(c *gin.Context) {
-		// Configuração CORS mais abrangente
-		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, DELETE, PATCH")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Origin, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization")
-		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Max-Age", "86400")
+		origin := c.Request.Header.Get("Origin")
+
+		allowOrigin := ""
+		if allowAll {
+			allowOrigin = "*"
+		} else if origin != "" && originAllowed(origin, config.AllowedOrigins) {
+			allowOrigin = origin
+			c.Writer.Header().Add("Vary", "Origin")
+		}
+
+		// Origem não permitida: sem cabeçalhos CORS, o navegador rejeita
+		if allowOrigin != "" {
+			c.Writer.Header().Set("Access-Control-Allow-Origin", allowOrigin)
+			c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, DELETE, PATCH")
+			c.Writer.Header().Set("Access-Control-Allow-Headers", allowedHeaders)
+			c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
+			c.Writer.Header().Set("Access-Control-Max-Age", maxAge)
+		}
 
 		// Resposta imediata para requisições OPTIONS (preflight)
 		if c.Request.Method == "OPTIONS" {
@@ -249,8 +592,42 @@ func corsMiddleware() gin.HandlerFunc {
 	}
 }
 
-// requestLogger configura o middleware de logging
-func requestLogger() gin.HandlerFunc {
+// originAllowed compara a origem com a lista, sem diferenciar maiúsculas.
+// Entradas "*.dominio.com" aceitam qualquer subdomínio de dominio.com, em
+// qualquer porta; "*.dominio.com:8443" exige também a porta.
+func originAllowed(origin string, allowed []string) bool {
+	origin = strings.ToLower(origin)
+
+	var hostname, hostPort string
+	if u, err := url.Parse(origin); err == nil {
+		hostname = u.Hostname()
+		hostPort = u.Host
+	}
+
+	for _, entry := range allowed {
+		entry = strings.ToLower(entry)
+
+		if strings.HasPrefix(entry, "*.") {
+			host := hostname
+			if strings.Contains(entry, ":") {
+				host = hostPort
+			}
+			if host != "" && strings.HasSuffix(host, entry[1:]) {
+				return true
+			}
+			continue
+		}
+
+		if origin == entry {
+			return true
+		}
+	}
+
+	return false
+}
+
+// RequestLogger configura o middleware de logging
+func RequestLogger() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Iniciar cronômetro
 		startTime := time.Now()
@@ -291,11 +668,12 @@ func requestLogger() gin.HandlerFunc {
 			}
 		}
 
-		log.Printf("%s%s%s | %s%d%s | %v | %s | %s",
+		log.Printf("%s%s%s | %s%d%s | %v | %s | %s | %s",
 			methodColor, method, resetColor,
 			statusColor, statusCode, resetColor,
 			latency,
 			ip,
+			c.GetString("requestID"),
 			path)
 	}
 }
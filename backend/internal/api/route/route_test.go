@@ -0,0 +1,131 @@
+package route
+
+import (
+	"app_padrao/internal/api/handler"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestEmailVerifyAcceptsGetAndPost(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	setupAuthRoutes(router, handler.NewAuthHandler(nil))
+
+	for _, method := range []string{http.MethodGet, http.MethodPost} {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, "/auth/email/verify", nil)
+		router.ServeHTTP(w, req)
+
+		// Sem token o handler responde 400; 404 indicaria rota ausente
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s /auth/email/verify = %d, esperado %d", method, w.Code, http.StatusBadRequest)
+		}
+	}
+}
+
+func TestOriginAllowed(t *testing.T) {
+	allowed := []string{"http://localhost:3000", "*.example.com", "*.painel.local:8443"}
+
+	tests := []struct {
+		origin string
+		want   bool
+	}{
+		{"http://localhost:3000", true},
+		{"http://localhost:3001", false},
+		{"https://app.example.com", true},
+		{"https://app.example.com:8080", true},
+		{"https://APP.Example.com:8080", true},
+		{"https://example.com", false},
+		{"https://evilexample.com", false},
+		{"https://evil.com:443.example.com", false},
+		{"https://a.painel.local:8443", true},
+		{"https://a.painel.local:9000", false},
+		{"https://a.painel.local", false},
+		{"null", false},
+	}
+
+	for _, tt := range tests {
+		if got := originAllowed(tt.origin, allowed); got != tt.want {
+			t.Errorf("originAllowed(%q) = %v, esperado %v", tt.origin, got, tt.want)
+		}
+	}
+}
+
+func TestCORSMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name        string
+		allowed     []string
+		method      string
+		origin      string
+		wantCode    int
+		wantOrigin  string // vazio = sem cabeçalhos CORS
+		wantHandler bool
+	}{
+		{"preflight de origem permitida", []string{"http://localhost:3000"}, http.MethodOptions, "http://localhost:3000", http.StatusNoContent, "http://localhost:3000", false},
+		{"origem permitida ecoada", []string{"http://localhost:3000", "*.example.com"}, http.MethodGet, "https://app.example.com", http.StatusOK, "https://app.example.com", true},
+		{"origem não permitida", []string{"http://localhost:3000"}, http.MethodGet, "https://evil.com", http.StatusOK, "", true},
+		{"preflight de origem não permitida", []string{"http://localhost:3000"}, http.MethodOptions, "https://evil.com", http.StatusNoContent, "", false},
+		{"qualquer origem", []string{"*"}, http.MethodGet, "https://evil.com", http.StatusOK, "*", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handlerCalled := false
+			router := gin.New()
+			router.Use(CORSMiddleware(CORSConfig{
+				AllowedOrigins: tt.allowed,
+				AllowedHeaders: []string{"Origin", "Content-Type", "Authorization"},
+				MaxAge:         600,
+			}))
+			router.GET("/api/plc", func(c *gin.Context) {
+				handlerCalled = true
+				c.Status(http.StatusOK)
+			})
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(tt.method, "/api/plc", nil)
+			req.Header.Set("Origin", tt.origin)
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantCode {
+				t.Errorf("status = %d, esperado %d", w.Code, tt.wantCode)
+			}
+			if handlerCalled != tt.wantHandler {
+				t.Errorf("handler executado = %v, esperado %v", handlerCalled, tt.wantHandler)
+			}
+
+			header := w.Header()
+			if got := header.Get("Access-Control-Allow-Origin"); got != tt.wantOrigin {
+				t.Errorf("Access-Control-Allow-Origin = %q, esperado %q", got, tt.wantOrigin)
+			}
+			if tt.wantOrigin == "" {
+				for _, name := range []string{"Access-Control-Allow-Methods", "Access-Control-Allow-Headers", "Access-Control-Allow-Credentials"} {
+					if got := header.Get(name); got != "" {
+						t.Errorf("%s = %q, esperado ausente para origem não permitida", name, got)
+					}
+				}
+				return
+			}
+
+			want := map[string]string{
+				"Access-Control-Allow-Methods":     "POST, GET, OPTIONS, PUT, DELETE, PATCH",
+				"Access-Control-Allow-Headers":     "Origin, Content-Type, Authorization",
+				"Access-Control-Allow-Credentials": "true",
+				"Access-Control-Max-Age":           "600",
+			}
+			for name, value := range want {
+				if got := header.Get(name); got != value {
+					t.Errorf("%s = %q, esperado %q", name, got, value)
+				}
+			}
+			if tt.wantOrigin != "*" && header.Get("Vary") != "Origin" {
+				t.Errorf("Vary = %q, esperado Origin ao ecoar a origem", header.Get("Vary"))
+			}
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package route
+
+import (
+	"app_padrao/internal/api/handler"
+	"app_padrao/internal/domain"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// versioningPLCService implementa apenas a consulta de um PLC
+type versioningPLCService struct {
+	domain.PLCService
+}
+
+func (s *versioningPLCService) GetByID(id int) (domain.PLC, error) {
+	if id != 7 {
+		return domain.PLC{}, domain.ErrPLCNotFound
+	}
+	return domain.PLC{ID: 7, Name: "CLP_Linha1", IPAddress: "10.0.0.7", Slot: 1, Active: true,
+		CreatedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)}, nil
+}
+
+func (s *versioningPLCService) EffectiveMonitoringStatus(plc domain.PLC) string {
+	return "running"
+}
+
+func (s *versioningPLCService) GetTagLimitStatus(plc domain.PLC) (int, int, error) {
+	return 3, 100, nil
+}
+
+// versioningUserRepo nega todas as permissões
+type versioningUserRepo struct {
+	domain.UserRepository
+}
+
+func (r *versioningUserRepo) HasPermission(userID int, permissionCode string) (bool, error) {
+	return false, nil
+}
+
+var versioningSunset = time.Date(2027, 6, 30, 0, 0, 0, 0, time.UTC)
+
+// newVersioningRouter monta as rotas completas com handlers sobre os fakes.
+// O token "valido" autentica o usuário 1.
+func newVersioningRouter(t *testing.T, disableLegacy bool) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	t.Setenv("AVATAR_DIRECTORY", t.TempDir())
+
+	plcService := &versioningPLCService{}
+	router := gin.New()
+	SetupRoutes(router,
+		handler.NewAuthHandler(nil),
+		handler.NewUserHandler(nil),
+		handler.NewAdminHandler(nil, nil),
+		handler.NewPermissionHandler(nil),
+		handler.NewProfileHandler(nil, nil, nil),
+		handler.NewPLCHandler(plcService),
+		handler.NewSystemHandler(plcService, nil, nil, 0),
+		&versioningUserRepo{},
+		"segredo",
+		nil,
+		nil,
+		1024,
+		VersioningConfig{DisableLegacyRoutes: disableLegacy, LegacySunset: versioningSunset},
+		&Application{TokenValidator: func(token string) (int, error) {
+			if token != "valido" {
+				return 0, errors.New("token inválido")
+			}
+			return 1, nil
+		}},
+	)
+	return router
+}
+
+func doVersioningRequest(router *gin.Engine, method, path, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader("{}"))
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestLegacyRoutesMirrorV1(t *testing.T) {
+	router := newVersioningRouter(t, false)
+
+	v1 := make(map[string]string)
+	legacy := make(map[string]string)
+	for _, r := range router.Routes() {
+		if rest, ok := strings.CutPrefix(r.Path, "/api/v1/"); ok {
+			v1[r.Method+" "+rest] = r.Handler
+		} else if rest, ok := strings.CutPrefix(r.Path, "/api/"); ok {
+			legacy[r.Method+" "+rest] = r.Handler
+		}
+	}
+
+	if len(v1) == 0 {
+		t.Fatal("nenhuma rota registrada em /api/v1")
+	}
+	for route, h := range v1 {
+		if legacy[route] != h {
+			t.Errorf("%s: handler em /api = %q, esperado %q", route, legacy[route], h)
+		}
+	}
+	if len(legacy) != len(v1) {
+		t.Errorf("rotas em /api = %d, em /api/v1 = %d", len(legacy), len(v1))
+	}
+}
+
+func TestLegacyAndV1ReturnIdenticalResponses(t *testing.T) {
+	router := newVersioningRouter(t, false)
+
+	requests := []struct {
+		method, path, token string
+		wantStatus          int
+	}{
+		{http.MethodGet, "/plc/7", "valido", http.StatusOK},
+		{http.MethodGet, "/plc/99", "valido", http.StatusNotFound},
+		{http.MethodGet, "/plc/abc", "valido", http.StatusBadRequest},
+		{http.MethodPost, "/plc/", "valido", http.StatusForbidden},
+		{http.MethodGet, "/plc/7", "", http.StatusUnauthorized},
+		{http.MethodGet, "/plc/7", "expirado", http.StatusUnauthorized},
+	}
+
+	for _, tt := range requests {
+		v1 := doVersioningRequest(router, tt.method, "/api/v1"+tt.path, tt.token)
+		legacy := doVersioningRequest(router, tt.method, "/api"+tt.path, tt.token)
+		name := tt.method + " " + tt.path + " (token " + tt.token + ")"
+
+		if v1.Code != tt.wantStatus {
+			t.Errorf("%s: status em /api/v1 = %d, esperado %d", name, v1.Code, tt.wantStatus)
+		}
+		if legacy.Code != v1.Code || legacy.Body.String() != v1.Body.String() {
+			t.Errorf("%s: /api respondeu %d %s, /api/v1 respondeu %d %s",
+				name, legacy.Code, legacy.Body.String(), v1.Code, v1.Body.String())
+		}
+
+		if v1.Header().Get("Deprecation") != "" {
+			t.Errorf("%s: /api/v1 não deveria ter o cabeçalho Deprecation", name)
+		}
+		if legacy.Header().Get("Deprecation") != "true" || legacy.Header().Get("Sunset") != versioningSunset.Format(http.TimeFormat) {
+			t.Errorf("%s: cabeçalhos de /api = Deprecation %q, Sunset %q", name,
+				legacy.Header().Get("Deprecation"), legacy.Header().Get("Sunset"))
+		}
+		if link := legacy.Header().Get("Link"); link != `</api/v1`+tt.path+`>; rel="successor-version"` {
+			t.Errorf("%s: Link = %q", name, link)
+		}
+	}
+}
+
+func TestDisableLegacyRoutes(t *testing.T) {
+	router := newVersioningRouter(t, true)
+
+	if w := doVersioningRequest(router, http.MethodGet, "/api/v1/plc/7", "valido"); w.Code != http.StatusOK {
+		t.Errorf("/api/v1/plc/7 = %d, esperado 200", w.Code)
+	}
+	if w := doVersioningRequest(router, http.MethodGet, "/api/plc/7", "valido"); w.Code != http.StatusNotFound {
+		t.Errorf("/api/plc/7 com aliases desativados = %d, esperado 404", w.Code)
+	}
+}
@@ -3,13 +3,13 @@ package api
 
 import (
 	"app_padrao/internal/api/handler"
+	"app_padrao/internal/api/middleware"
 	"app_padrao/internal/api/route"
 	"app_padrao/internal/config"
 	"app_padrao/internal/domain"
 	"context"
 	"log"
 	"net/http"
-	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -24,6 +24,7 @@ type Server struct {
 	permissionHandler *handler.PermissionHandler
 	profileHandler    *handler.ProfileHandler
 	plcHandler        *handler.PLCHandler // NOVO: handler do PLC
+	systemHandler     *handler.SystemHandler
 	userRepo          domain.UserRepository
 	cfg               *config.Config
 	app               *route.Application // Campo para Application
@@ -37,10 +38,42 @@ func NewServer(
 	permissionHandler *handler.PermissionHandler,
 	profileHandler *handler.ProfileHandler,
 	plcHandler *handler.PLCHandler, // NOVO: handler do PLC
+	systemHandler *handler.SystemHandler,
 	userRepo domain.UserRepository,
 	app *route.Application, // Novo parâmetro para Application
 ) *Server {
-	router := gin.Default()
+	router := gin.New()
+
+	// Sem proxies confiáveis o IP do cliente é o endereço da conexão e o
+	// X-Forwarded-For é ignorado; do contrário qualquer cliente poderia forjar
+	// o IP usado pela whitelist de administração e pelo rate limit
+	if err := router.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		log.Fatalf("SERVER_TRUSTED_PROXIES inválido: %v", err)
+	}
+
+	// HTTP/2 sem TLS para implantações internas (o push das tags depende de HTTP/2)
+	router.UseH2C = cfg.Server.H2C
+
+	// Ordem dos middlewares globais:
+	//  1. RequestIDMiddleware: identifica a requisição antes de qualquer log
+	//  2. UserRateLimiter: recusa o excesso antes de gastar com o restante
+	//  3. RequestLogger: registra também as respostas de erro, 413 e 429
+	//  4. gin.Recovery: converte pânicos em 500, que ainda passam pelo log
+	//  5. CORS: cabeçalhos por origem, inclusive nos preflights
+	//  6. RequestSizeLimiter: limite padrão do corpo, substituível por rota
+	// Middlewares adicionados com Server.Use rodam depois destes.
+	router.Use(
+		middleware.RequestIDMiddleware(),
+		middleware.UserRateLimiter(cfg.Server.RateLimitPerMinute),
+		route.RequestLogger(),
+		gin.Recovery(),
+		route.CORSMiddleware(route.CORSConfig{
+			AllowedOrigins: cfg.Server.AllowedOrigins,
+			AllowedHeaders: cfg.Server.AllowedHeaders,
+			MaxAge:         cfg.Server.MaxAge,
+		}),
+		middleware.RequestSizeLimiter(cfg.Server.MaxRequestSizeBytes),
+	)
 
 	return &Server{
 		router:            router,
@@ -50,13 +83,36 @@ func NewServer(
 		permissionHandler: permissionHandler,
 		profileHandler:    profileHandler,
 		plcHandler:        plcHandler, // NOVO: handler do PLC
+		systemHandler:     systemHandler,
 		userRepo:          userRepo,
 		cfg:               cfg,
 		app:               app, // Inicializa o novo campo
 	}
 }
 
+// Use acrescenta um middleware global depois dos padrões do servidor. Deve
+// ser chamado antes de Run, que registra as rotas.
+func (s *Server) Use(m gin.HandlerFunc) {
+	s.router.Use(m)
+}
+
 func (s *Server) Run() error {
+	s.setupRoutes()
+
+	s.httpServer = &http.Server{
+		Addr:           ":" + s.cfg.Server.Port,
+		Handler:        s.router.Handler(), // Handler() aplica o h2c quando habilitado
+		ReadTimeout:    s.cfg.Server.ReadTimeout,
+		WriteTimeout:   s.cfg.Server.WriteTimeout,
+		MaxHeaderBytes: 1 << 20,
+	}
+
+	log.Printf("Servidor iniciado na porta %s", s.cfg.Server.Port)
+	return s.httpServer.ListenAndServe()
+}
+
+// setupRoutes registra as rotas da aplicação no router do servidor
+func (s *Server) setupRoutes() {
 	// Passar todos os parâmetros para SetupRoutes, incluindo o app
 	route.SetupRoutes(
 		s.router,
@@ -66,23 +122,29 @@ func (s *Server) Run() error {
 		s.permissionHandler,
 		s.profileHandler,
 		s.plcHandler, // NOVO: handler do PLC
+		s.systemHandler,
 		s.userRepo,
 		s.cfg.JWT.SecretKey,
+		s.cfg.Server.AdminAllowedCIDRs,
+		dashboardAccounts(s.cfg.Server.DashboardUser, s.cfg.Server.DashboardPassword),
+		s.cfg.Server.AvatarMaxSizeBytes,
+		route.VersioningConfig{
+			DisableLegacyRoutes: s.cfg.Server.DisableLegacyRoutes,
+			LegacySunset:        s.cfg.Server.LegacyRoutesSunset,
+		},
 		s.app, // Passar a instância de Application
 	)
-
-	s.httpServer = &http.Server{
-		Addr:           ":" + s.cfg.Server.Port,
-		Handler:        s.router,
-		ReadTimeout:    10 * time.Second,
-		WriteTimeout:   10 * time.Second,
-		MaxHeaderBytes: 1 << 20,
-	}
-
-	log.Printf("Servidor iniciado na porta %s", s.cfg.Server.Port)
-	return s.httpServer.ListenAndServe()
 }
 
 func (s *Server) Shutdown(ctx context.Context) error {
 	return s.httpServer.Shutdown(ctx)
 }
+
+// dashboardAccounts monta as credenciais do dashboard HTML; sem usuário ou
+// senha configurados o dashboard fica desabilitado
+func dashboardAccounts(user, password string) gin.Accounts {
+	if user == "" || password == "" {
+		return nil
+	}
+	return gin.Accounts{user: password}
+}
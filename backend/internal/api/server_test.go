@@ -0,0 +1,111 @@
+package api
+
+import (
+	"app_padrao/internal/api/handler"
+	"app_padrao/internal/api/route"
+	"app_padrao/internal/config"
+	"app_padrao/internal/domain"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// serverTestUserRepo nega todas as permissões, então uma requisição que passa
+// pela whitelist termina em PERMISSION_DENIED
+type serverTestUserRepo struct {
+	domain.UserRepository
+}
+
+func (r *serverTestUserRepo) HasPermission(userID int, permissionCode string) (bool, error) {
+	return false, nil
+}
+
+// newTestServer monta o servidor real, com os middlewares globais e as
+// rotas, restringindo a administração a 10.0.0.0/8
+func newTestServer(t *testing.T, trustedProxies []string) *Server {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	t.Setenv("AVATAR_DIRECTORY", t.TempDir())
+
+	cfg := &config.Config{Server: config.ServerConfig{
+		AdminAllowedCIDRs:   []string{"10.0.0.0/8"},
+		TrustedProxies:      trustedProxies,
+		DashboardUser:       "admin",
+		DashboardPassword:   "senha",
+		DisableLegacyRoutes: true,
+	}}
+
+	s := NewServer(cfg,
+		handler.NewAuthHandler(nil),
+		handler.NewUserHandler(nil),
+		handler.NewAdminHandler(nil, nil),
+		handler.NewPermissionHandler(nil),
+		handler.NewProfileHandler(nil, nil, nil),
+		handler.NewPLCHandler(nil),
+		handler.NewSystemHandler(nil, nil, nil, 0),
+		&serverTestUserRepo{},
+		&route.Application{TokenValidator: func(token string) (int, error) {
+			if token != "valido" {
+				return 0, errors.New("token inválido")
+			}
+			return 1, nil
+		}},
+	)
+	s.setupRoutes()
+	return s
+}
+
+func TestServerIgnoresSpoofedForwardedFor(t *testing.T) {
+	tests := []struct {
+		name           string
+		trustedProxies []string
+		remoteAddr     string
+		forwardedFor   string
+		wantCode       string // código do erro de /api/v1/admin/users
+		wantDashboard  int
+	}{
+		{"cabeçalho forjado sem proxy confiável", nil, "203.0.113.5:40000", "10.0.0.1", domain.ErrCodeIPNotAllowed, http.StatusForbidden},
+		{"conexão direta da rede interna", nil, "10.0.0.5:40000", "", domain.ErrCodePermissionDenied, http.StatusUnauthorized},
+		{"cabeçalho de proxy não configurado", []string{"192.168.0.1"}, "203.0.113.5:40000", "10.0.0.1", domain.ErrCodeIPNotAllowed, http.StatusForbidden},
+		{"cliente interno atrás do proxy confiável", []string{"192.168.0.1"}, "192.168.0.1:40000", "10.0.0.1", domain.ErrCodePermissionDenied, http.StatusUnauthorized},
+		{"cliente externo atrás do proxy confiável", []string{"192.168.0.1"}, "192.168.0.1:40000", "203.0.113.5", domain.ErrCodeIPNotAllowed, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t, tt.trustedProxies)
+
+			request := func(path, auth string) *httptest.ResponseRecorder {
+				req := httptest.NewRequest(http.MethodGet, path, nil)
+				req.RemoteAddr = tt.remoteAddr
+				if tt.forwardedFor != "" {
+					req.Header.Set("X-Forwarded-For", tt.forwardedFor)
+				}
+				if auth != "" {
+					req.Header.Set("Authorization", auth)
+				}
+				w := httptest.NewRecorder()
+				s.router.ServeHTTP(w, req)
+				return w
+			}
+
+			w := request("/api/v1/admin/users", "Bearer valido")
+			var resp domain.APIError
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("resposta inválida (%d): %s", w.Code, w.Body.String())
+			}
+			if w.Code != http.StatusForbidden || resp.Code != tt.wantCode {
+				t.Errorf("/api/v1/admin/users = %d %s, esperado 403 %s", w.Code, resp.Code, tt.wantCode)
+			}
+
+			// O dashboard usa a mesma whitelist antes do Basic Auth
+			if w := request("/admin/dashboard", ""); w.Code != tt.wantDashboard {
+				t.Errorf("/admin/dashboard = %d, esperado %d", w.Code, tt.wantDashboard)
+			}
+		})
+	}
+}
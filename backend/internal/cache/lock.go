@@ -0,0 +1,117 @@
+// internal/cache/lock.go
+package cache
+
+import (
+	"app_padrao/internal/domain"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// ErrLockNotHeld indica que o lock expirou ou pertence a outra instância
+var ErrLockNotHeld = errors.New("lock não pertence a esta instância")
+
+// releaseScript remove o lock apenas se o valor ainda for o desta instância
+var releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// extendScript renova o TTL apenas se o valor ainda for o desta instância
+var extendScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// DistributedLock é um lock exclusivo entre instâncias da aplicação baseado
+// em SET NX com expiração no Redis
+type DistributedLock struct {
+	client *redis.Client
+	key    string
+	value  string
+}
+
+// NewDistributedLock cria um lock para a chave informada. O valor gravado
+// identifica a instância (host, PID e um sufixo aleatório).
+func NewDistributedLock(client *redis.Client, key string) *DistributedLock {
+	host, _ := os.Hostname()
+	suffix := make([]byte, 4)
+	rand.Read(suffix)
+
+	return &DistributedLock{
+		client: client,
+		key:    key,
+		value:  fmt.Sprintf("%s:%d:%s", host, os.Getpid(), hex.EncodeToString(suffix)),
+	}
+}
+
+// Acquire tenta obter o lock por ttl. Retorna false se outra instância o detém.
+func (l *DistributedLock) Acquire(ttl time.Duration) (bool, error) {
+	if l.client == nil {
+		return false, ErrRedisNotConnected
+	}
+	return l.client.SetNX(context.Background(), l.key, l.value, ttl).Result()
+}
+
+// Extend renova o TTL de um lock já obtido, para operações que podem
+// ultrapassar o TTL original
+func (l *DistributedLock) Extend(ttl time.Duration) error {
+	if l.client == nil {
+		return ErrRedisNotConnected
+	}
+
+	res, err := extendScript.Run(context.Background(), l.client, []string{l.key}, l.value, ttl.Milliseconds()).Int()
+	if err != nil {
+		return fmt.Errorf("erro ao renovar lock %s: %w", l.key, err)
+	}
+	if res == 0 {
+		return ErrLockNotHeld
+	}
+	return nil
+}
+
+// Release libera o lock se ele ainda pertencer a esta instância
+func (l *DistributedLock) Release() error {
+	if l.client == nil {
+		return ErrRedisNotConnected
+	}
+
+	if err := releaseScript.Run(context.Background(), l.client, []string{l.key}, l.value).Err(); err != nil {
+		return fmt.Errorf("erro ao liberar lock %s: %w", l.key, err)
+	}
+	return nil
+}
+
+// GetLockStatus consulta quem detém um lock e quanto tempo falta para expirar
+func GetLockStatus(client *redis.Client, key string) (domain.LockStatus, error) {
+	status := domain.LockStatus{Key: key}
+	if client == nil {
+		return status, ErrRedisNotConnected
+	}
+
+	ctx := context.Background()
+	holder, err := client.Get(ctx, key).Result()
+	if err == redis.Nil {
+		return status, nil
+	}
+	if err != nil {
+		return status, fmt.Errorf("erro ao consultar lock %s: %w", key, err)
+	}
+
+	status.Locked = true
+	status.Holder = holder
+	if ttl, err := client.PTTL(ctx, key).Result(); err == nil && ttl > 0 {
+		status.TTLSeconds = int64(ttl.Seconds())
+	}
+	return status, nil
+}
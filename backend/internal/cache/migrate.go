@@ -0,0 +1,101 @@
+// internal/cache/migrate.go
+package cache
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// migrateScanCount é a quantidade de chaves pedida por iteração do SCAN
+const migrateScanCount = 100
+
+// LegacyKeyPatterns são os padrões das chaves gravadas sem namespace
+var LegacyKeyPatterns = []string{
+	"etag:*",
+	"plc:*",
+	"plcs:*",
+	"plcstatus:*",
+	"plctag:*",
+	"plctags:*",
+	"tagvalue:*",
+}
+
+// KeyMigrationResult resume uma migração de namespace
+type KeyMigrationResult struct {
+	Migrated int `json:"migrated"`
+	Total    int `json:"total"`
+}
+
+// MigrateKeyNamespace move as chaves que casam com os padrões para o novo
+// prefixo. Usa SCAN com cursor para não bloquear o Redis e DUMP/RESTORE para
+// preservar tipo e TTL de cada chave. Chaves que já possuem o prefixo são
+// ignoradas.
+func MigrateKeyNamespace(ctx context.Context, client *redis.Client, newPrefix string, patterns []string) (KeyMigrationResult, error) {
+	var result KeyMigrationResult
+
+	if client == nil {
+		return result, ErrRedisNotConnected
+	}
+
+	for _, pattern := range patterns {
+		var cursor uint64
+		for {
+			keys, next, err := client.Scan(ctx, cursor, pattern, migrateScanCount).Result()
+			if err != nil {
+				return result, fmt.Errorf("erro ao percorrer chaves %s: %w", pattern, err)
+			}
+
+			for _, key := range keys {
+				if strings.HasPrefix(key, newPrefix) {
+					continue
+				}
+				result.Total++
+
+				if err := moveKey(ctx, client, key, newPrefix+key); err != nil {
+					log.Printf("Aviso: erro ao migrar chave %s: %v", key, err)
+					continue
+				}
+				result.Migrated++
+			}
+
+			cursor = next
+			if cursor == 0 {
+				break
+			}
+		}
+
+		log.Printf("Migração de chaves %s: %d/%d migradas até agora", pattern, result.Migrated, result.Total)
+	}
+
+	return result, nil
+}
+
+// moveKey copia uma chave para o novo nome e remove a original
+func moveKey(ctx context.Context, client *redis.Client, oldKey, newKey string) error {
+	dump, err := client.Dump(ctx, oldKey).Result()
+	if err == redis.Nil {
+		// Expirou ou foi removida durante a varredura
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	ttl, err := client.PTTL(ctx, oldKey).Result()
+	if err != nil {
+		return err
+	}
+	if ttl < 0 {
+		ttl = 0 // sem expiração
+	}
+
+	if err := client.RestoreReplace(ctx, newKey, ttl, dump).Err(); err != nil {
+		return err
+	}
+
+	return client.Del(ctx, oldKey).Err()
+}
@@ -3,6 +3,7 @@ package cache
 
 import (
 	"app_padrao/internal/domain"
+	"app_padrao/internal/metrics"
 	"context"
 	"encoding/json"
 	"errors"
@@ -15,7 +16,7 @@ import (
 
 // Erros específicos para o cache
 var (
-	ErrRedisNotConnected = errors.New("conexão com Redis não estabelecida")
+	ErrRedisNotConnected = fmt.Errorf("%w: conexão com Redis não estabelecida", domain.ErrCacheUnavailable)
 	ErrKeyNotFound       = errors.New("chave não encontrada no Redis")
 	ErrInvalidFormat     = errors.New("formato de dados inválido")
 )
@@ -25,27 +26,38 @@ type RedisCache struct {
 	client         *redis.Client
 	ctx            context.Context
 	keyPrefix      string
+	namespace      string
 	defaultTTL     time.Duration
 	connRetryCount int
 	connRetryDelay time.Duration
+	// Máximo de comandos por pipeline; lotes maiores são divididos
+	maxPipelineSize int
+	metrics         *metrics.MetricsCollector
 }
 
 // RedisConfig contém configurações para o cache Redis
 type RedisConfig struct {
 	KeyPrefix      string
+	Namespace      string // REDIS_KEY_PREFIX da instância, usado nas chaves fora de KeyPrefix
 	DefaultTTL     time.Duration
 	ConnRetryCount int
 	ConnRetryDelay time.Duration
+	// Tamanho máximo de cada pipeline (padrão 100)
+	MaxPipelineSize int
 }
 
+// defaultMaxPipelineSize é usado quando MaxPipelineSize não é informado
+const defaultMaxPipelineSize = 100
+
 // NewRedisCache cria uma nova instância do cache Redis
 func NewRedisCache(addr, password string, db int) (*RedisCache, error) {
 	// Configuração padrão
 	config := RedisConfig{
-		KeyPrefix:      "plc:",
-		DefaultTTL:     24 * time.Hour,
-		ConnRetryCount: 3,
-		ConnRetryDelay: 2 * time.Second,
+		KeyPrefix:       "plc:",
+		DefaultTTL:      24 * time.Hour,
+		ConnRetryCount:  3,
+		ConnRetryDelay:  2 * time.Second,
+		MaxPipelineSize: defaultMaxPipelineSize,
 	}
 
 	return NewRedisCacheWithConfig(addr, password, db, config)
@@ -84,18 +96,24 @@ func NewRedisCacheWithConfig(addr, password string, db int, config RedisConfig)
 
 	if err != nil {
 		log.Printf("Falha ao conectar ao Redis após %d tentativas: %v", config.ConnRetryCount, err)
-		return nil, fmt.Errorf("%w: %v", ErrRedisNotConnected, err)
+		return nil, fmt.Errorf("%w: %w", ErrRedisNotConnected, err)
 	}
 
 	log.Printf("Conexão com Redis estabelecida com sucesso: %s", addr)
 
+	if config.MaxPipelineSize <= 0 {
+		config.MaxPipelineSize = defaultMaxPipelineSize
+	}
+
 	cache := &RedisCache{
-		client:         client,
-		ctx:            ctx,
-		keyPrefix:      config.KeyPrefix,
-		defaultTTL:     config.DefaultTTL,
-		connRetryCount: config.ConnRetryCount,
-		connRetryDelay: config.ConnRetryDelay,
+		client:          client,
+		ctx:             ctx,
+		keyPrefix:       config.KeyPrefix,
+		namespace:       config.Namespace,
+		defaultTTL:      config.DefaultTTL,
+		connRetryCount:  config.ConnRetryCount,
+		connRetryDelay:  config.ConnRetryDelay,
+		maxPipelineSize: config.MaxPipelineSize,
 	}
 
 	return cache, nil
@@ -106,6 +124,20 @@ func (r *RedisCache) GetRedisClient() *redis.Client {
 	return r.client
 }
 
+// SetMetricsCollector define o coletor que recebe as métricas de pipeline
+func (r *RedisCache) SetMetricsCollector(collector *metrics.MetricsCollector) {
+	r.metrics = collector
+}
+
+// recordPipelineMetrics registra quantos pipelines uma operação usou e quanto tempo levou
+func (r *RedisCache) recordPipelineMetrics(batchCount int, elapsed time.Duration) {
+	if r.metrics == nil {
+		return
+	}
+	r.metrics.RecordHistogram("redis.pipeline.batch_count", float64(batchCount))
+	r.metrics.RecordHistogram("redis.pipeline.latency_ms", float64(elapsed.Microseconds())/1000.0)
+}
+
 // formatKey formata uma chave com o prefixo padrão
 func (r *RedisCache) formatKey(plcID, tagID int) string {
 	return fmt.Sprintf("%splc:%d:tag:%d", r.keyPrefix, plcID, tagID)
@@ -122,6 +154,7 @@ func (r *RedisCache) SetTagValue(plcID, tagID int, value interface{}) error {
 
 	tagValue := map[string]interface{}{
 		"value":     value,
+		"quality":   domain.QualityGood,
 		"timestamp": time.Now().Format(time.RFC3339),
 	}
 
@@ -175,7 +208,7 @@ func (r *RedisCache) GetTagValue(plcID, tagID int) (*domain.TagValue, error) {
 
 	var valueMap map[string]interface{}
 	if err := json.Unmarshal([]byte(data), &valueMap); err != nil {
-		return nil, fmt.Errorf("%w: %v", ErrInvalidFormat, err)
+		return nil, fmt.Errorf("%w: %w", ErrInvalidFormat, err)
 	}
 
 	// Parse timestamp
@@ -196,41 +229,66 @@ func (r *RedisCache) GetTagValue(plcID, tagID int) (*domain.TagValue, error) {
 		PLCID:     plcID,
 		TagID:     tagID,
 		Value:     valueMap["value"],
+		RawValue:  valueMap["raw_value"],
+		Quality:   parseQuality(valueMap),
 		Timestamp: timestamp,
 	}, nil
 }
 
-// BatchSetTagValues define vários valores de tag de uma vez só
+// BatchSetTagValues define vários valores de tag de uma vez só. Lotes maiores
+// que maxPipelineSize são enviados em vários pipelines sequenciais.
 func (r *RedisCache) BatchSetTagValues(values []domain.TagValue) error {
 	if len(values) == 0 {
 		return nil // Nada para fazer
 	}
 
-	pipe := r.client.Pipeline()
+	start := time.Now()
+	batchCount := 0
 	errors := make([]error, 0)
 
-	for _, tagValue := range values {
-		key := r.formatKey(tagValue.PLCID, tagValue.TagID)
+	for offset := 0; offset < len(values); offset += r.maxPipelineSize {
+		end := offset + r.maxPipelineSize
+		if end > len(values) {
+			end = len(values)
+		}
+
+		pipe := r.client.Pipeline()
+
+		for _, tagValue := range values[offset:end] {
+			key := r.formatKey(tagValue.PLCID, tagValue.TagID)
+
+			quality := tagValue.Quality
+			if quality == "" {
+				quality = domain.QualityGood
+			}
 
-		data := map[string]interface{}{
-			"value":     tagValue.Value,
-			"timestamp": tagValue.Timestamp.Format(time.RFC3339),
+			data := map[string]interface{}{
+				"value":     tagValue.Value,
+				"quality":   quality,
+				"timestamp": tagValue.Timestamp.Format(time.RFC3339),
+			}
+			if tagValue.RawValue != nil {
+				data["raw_value"] = tagValue.RawValue
+			}
+
+			jsonData, err := json.Marshal(data)
+			if err != nil {
+				errors = append(errors, fmt.Errorf("erro ao serializar tag %d: %w", tagValue.TagID, err))
+				continue
+			}
+
+			pipe.Set(r.ctx, key, jsonData, r.defaultTTL)
 		}
 
-		jsonData, err := json.Marshal(data)
+		// Executar as operações em pipeline
+		batchCount++
+		_, err := pipe.Exec(r.ctx)
 		if err != nil {
-			errors = append(errors, fmt.Errorf("erro ao serializar tag %d: %w", tagValue.TagID, err))
-			continue
+			errors = append(errors, fmt.Errorf("erro ao executar pipeline: %w", err))
 		}
-
-		pipe.Set(r.ctx, key, jsonData, r.defaultTTL)
 	}
 
-	// Executar as operações em pipeline
-	_, err := pipe.Exec(r.ctx)
-	if err != nil {
-		errors = append(errors, fmt.Errorf("erro ao executar pipeline: %w", err))
-	}
+	r.recordPipelineMetrics(batchCount, time.Since(start))
 
 	// Se tivemos erros, retornar um erro combinado
 	if len(errors) > 0 {
@@ -245,12 +303,38 @@ func (r *RedisCache) BatchSetTagValues(values []domain.TagValue) error {
 	return nil
 }
 
-// GetMultipleTagValues busca múltiplos valores de tag de uma vez
+// GetMultipleTagValues busca múltiplos valores de tag de uma vez, dividindo
+// a leitura em pipelines de até maxPipelineSize chaves
 func (r *RedisCache) GetMultipleTagValues(queries []struct{ PLCID, TagID int }) ([]domain.TagValue, error) {
 	if len(queries) == 0 {
 		return []domain.TagValue{}, nil
 	}
 
+	start := time.Now()
+	batchCount := 0
+	var results []domain.TagValue
+
+	for offset := 0; offset < len(queries); offset += r.maxPipelineSize {
+		end := offset + r.maxPipelineSize
+		if end > len(queries) {
+			end = len(queries)
+		}
+
+		batchCount++
+		batchResults, err := r.getTagValuesBatch(queries[offset:end])
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, batchResults...)
+	}
+
+	r.recordPipelineMetrics(batchCount, time.Since(start))
+
+	return results, nil
+}
+
+// getTagValuesBatch lê um lote de valores de tag em um único pipeline
+func (r *RedisCache) getTagValuesBatch(queries []struct{ PLCID, TagID int }) ([]domain.TagValue, error) {
 	pipe := r.client.Pipeline()
 	cmds := make(map[string]*redis.StringCmd)
 	queryMap := make(map[string]struct{ PLCID, TagID int })
@@ -309,6 +393,8 @@ func (r *RedisCache) GetMultipleTagValues(queries []struct{ PLCID, TagID int })
 			PLCID:     query.PLCID,
 			TagID:     query.TagID,
 			Value:     valueMap["value"],
+			RawValue:  valueMap["raw_value"],
+			Quality:   parseQuality(valueMap),
 			Timestamp: timestamp,
 		})
 
@@ -327,6 +413,87 @@ func (r *RedisCache) GetMultipleTagValues(queries []struct{ PLCID, TagID int })
 	return results, nil
 }
 
+// parseQuality lê a qualidade de um valor serializado. Valores gravados antes
+// da existência do campo são considerados bons.
+func parseQuality(valueMap map[string]interface{}) string {
+	if quality, ok := valueMap["quality"].(string); ok && quality != "" {
+		return quality
+	}
+	return domain.QualityGood
+}
+
+// tagLastWriteTTL é a validade do registro de última escrita no Redis
+const tagLastWriteTTL = 7 * 24 * time.Hour
+
+// tagLastWriteKey retorna a chave da última escrita de uma tag
+func (r *RedisCache) tagLastWriteKey(tagID int) string {
+	return fmt.Sprintf("%splctag:%d:last_write", r.namespace, tagID)
+}
+
+// SetTagLastWrite registra a última escrita bem-sucedida em uma tag
+func (r *RedisCache) SetTagLastWrite(tagID int, userID int, t time.Time) error {
+	data, err := json.Marshal(domain.TagLastWrite{TagID: tagID, UserID: userID, At: t})
+	if err != nil {
+		return fmt.Errorf("erro ao serializar última escrita: %w", err)
+	}
+
+	return r.client.Set(r.ctx, r.tagLastWriteKey(tagID), data, tagLastWriteTTL).Err()
+}
+
+// GetTagLastWrite retorna a última escrita de uma tag, ou nil se não houver registro
+func (r *RedisCache) GetTagLastWrite(tagID int) (*domain.TagLastWrite, error) {
+	data, err := r.client.Get(r.ctx, r.tagLastWriteKey(tagID)).Result()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var lastWrite domain.TagLastWrite
+	if err := json.Unmarshal([]byte(data), &lastWrite); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidFormat, err)
+	}
+
+	return &lastWrite, nil
+}
+
+// DeletePLCValues remove os valores de todas as tags do PLC. Usa SCAN para
+// não bloquear o Redis e remove cada página de chaves em um pipeline.
+func (r *RedisCache) DeletePLCValues(plcID int) error {
+	pattern := fmt.Sprintf("%splc:%d:tag:*", r.keyPrefix, plcID)
+
+	var cursor uint64
+	deleted := 0
+	for {
+		keys, next, err := r.client.Scan(r.ctx, cursor, pattern, int64(r.maxPipelineSize)).Result()
+		if err != nil {
+			return fmt.Errorf("erro ao percorrer valores do PLC %d: %w", plcID, err)
+		}
+
+		if len(keys) > 0 {
+			pipe := r.client.Pipeline()
+			for _, key := range keys {
+				pipe.Del(r.ctx, key)
+			}
+			if _, err := pipe.Exec(r.ctx); err != nil {
+				return fmt.Errorf("erro ao remover valores do PLC %d: %w", plcID, err)
+			}
+			deleted += len(keys)
+		}
+
+		cursor = next
+		if cursor == 0 {
+			break
+		}
+	}
+
+	if deleted > 0 {
+		log.Printf("%d valores de tags do PLC %d removidos do cache", deleted, plcID)
+	}
+	return nil
+}
+
 // VerifyRedisHealth verifica a saúde do Redis
 func (r *RedisCache) VerifyRedisHealth() error {
 	// Tenta salvar um valor de teste
@@ -0,0 +1,126 @@
+package cache
+
+import (
+	"app_padrao/internal/domain"
+	"app_padrao/internal/metrics"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+func newTestRedisCache(tb testing.TB, pipelineSize int) (*RedisCache, *miniredis.Miniredis) {
+	tb.Helper()
+	mr := miniredis.RunT(tb)
+
+	redisCache, err := NewRedisCacheWithConfig(mr.Addr(), "", 0, RedisConfig{
+		KeyPrefix:       "plc:",
+		DefaultTTL:      time.Hour,
+		ConnRetryCount:  1,
+		MaxPipelineSize: pipelineSize,
+	})
+	if err != nil {
+		tb.Fatalf("erro ao conectar ao miniredis: %v", err)
+	}
+	tb.Cleanup(func() { redisCache.Close() })
+	return redisCache, mr
+}
+
+func testTagValues(n int) []domain.TagValue {
+	values := make([]domain.TagValue, n)
+	for i := range values {
+		values[i] = domain.TagValue{PLCID: 1, TagID: i + 1, Value: float64(i), Timestamp: time.Now()}
+	}
+	return values
+}
+
+func TestNewRedisCacheDefaultPipelineSize(t *testing.T) {
+	redisCache, _ := newTestRedisCache(t, 0)
+	if redisCache.maxPipelineSize != defaultMaxPipelineSize {
+		t.Errorf("maxPipelineSize = %d, esperado %d", redisCache.maxPipelineSize, defaultMaxPipelineSize)
+	}
+}
+
+func TestBatchSetTagValuesSplitsPipelines(t *testing.T) {
+	tests := []struct {
+		name        string
+		items       int
+		wantBatches float64
+	}{
+		{"menor que o limite", 40, 1},
+		{"igual ao limite", 100, 1},
+		{"um além do limite", 101, 2},
+		{"vários lotes", 250, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			redisCache, mr := newTestRedisCache(t, 100)
+			collector := metrics.NewMetricsCollector()
+			redisCache.SetMetricsCollector(collector)
+
+			if err := redisCache.BatchSetTagValues(testTagValues(tt.items)); err != nil {
+				t.Fatalf("erro inesperado: %v", err)
+			}
+
+			if keys := mr.Keys(); len(keys) != tt.items {
+				t.Errorf("chaves gravadas = %d, esperado %d", len(keys), tt.items)
+			}
+			samples := collector.GetHistogramSamples("redis.pipeline.batch_count", 0)
+			if len(samples) != 1 || samples[0] != tt.wantBatches {
+				t.Errorf("redis.pipeline.batch_count = %v, esperado [%v]", samples, tt.wantBatches)
+			}
+			if stats := collector.GetHistogramStats("redis.pipeline.latency_ms"); stats.Count != 1 {
+				t.Errorf("redis.pipeline.latency_ms com %d amostras, esperado 1", stats.Count)
+			}
+		})
+	}
+}
+
+func TestGetMultipleTagValuesSplitsPipelines(t *testing.T) {
+	redisCache, _ := newTestRedisCache(t, 100)
+
+	// Uma tag sem valor no cache é omitida do resultado
+	const items = 250
+	if err := redisCache.BatchSetTagValues(testTagValues(items - 1)); err != nil {
+		t.Fatalf("erro ao gravar valores: %v", err)
+	}
+
+	collector := metrics.NewMetricsCollector()
+	redisCache.SetMetricsCollector(collector)
+
+	queries := make([]struct{ PLCID, TagID int }, items)
+	for i := range queries {
+		queries[i] = struct{ PLCID, TagID int }{1, i + 1}
+	}
+
+	values, err := redisCache.GetMultipleTagValues(queries)
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if len(values) != items-1 {
+		t.Errorf("valores lidos = %d, esperado %d", len(values), items-1)
+	}
+	if samples := collector.GetHistogramSamples("redis.pipeline.batch_count", 0); len(samples) != 1 || samples[0] != 3 {
+		t.Errorf("redis.pipeline.batch_count = %v, esperado [3]", samples)
+	}
+}
+
+// BenchmarkBatchSetTagValues compara 1000 valores em um único pipeline com
+// a divisão em pipelines de 100
+func BenchmarkBatchSetTagValues(b *testing.B) {
+	values := testTagValues(1000)
+
+	for _, size := range []int{len(values), defaultMaxPipelineSize} {
+		b.Run(fmt.Sprintf("pipeline=%d", size), func(b *testing.B) {
+			redisCache, _ := newTestRedisCache(b, size)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if err := redisCache.BatchSetTagValues(values); err != nil {
+					b.Fatalf("erro inesperado: %v", err)
+				}
+			}
+		})
+	}
+}
@@ -3,25 +3,112 @@ package config
 
 import (
 	"app_padrao/pkg/database"
+	"errors"
+	"fmt"
+	"net"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
-	Server ServerConfig
-	DB     database.Config
-	JWT    JWTConfig
+	Server      ServerConfig
+	DB          database.Config
+	JWT         JWTConfig
+	Security    SecurityConfig
+	Redis       RedisConfig
+	Diagnostics DiagnosticsConfig
+	Profile     ProfileConfig
+	Alarm       AlarmConfig
+	Health      HealthConfig
 }
 
 type ServerConfig struct {
-	Port string
+	Port           string
+	PublicURL      string   // URL base usada nos links enviados por email
+	AllowedOrigins []string // Origens CORS permitidas ("*" libera todas, "*.dominio.com" libera subdomínios)
+	AllowedHeaders []string // Cabeçalhos aceitos em requisições CORS
+	MaxAge         int      // Tempo em segundos de cache do preflight
+	// Faixas CIDR com acesso às rotas de administração (vazio = todas)
+	AdminAllowedCIDRs []string
+	// IPs ou faixas CIDR dos proxies cujo X-Forwarded-For é aceito como IP do
+	// cliente (vazio = nenhum; vale o endereço da conexão)
+	TrustedProxies []string
+	// Credenciais Basic Auth do dashboard HTML (vazias = dashboard desabilitado)
+	DashboardUser     string
+	DashboardPassword string
+	ReadTimeout       time.Duration // Tempo máximo para ler a requisição
+	WriteTimeout      time.Duration // Tempo máximo para escrever a resposta
+	// Requisições por minuto aceitas de cada IP (0 = sem limite)
+	RateLimitPerMinute int
+	// Tamanho máximo do corpo das requisições, em bytes (0 = sem limite)
+	MaxRequestSizeBytes int64
+	// Tamanho máximo do upload de avatar, em bytes
+	AvatarMaxSizeBytes int64
+	// Remove os aliases /api sem versão; apenas /api/v1 é registrado
+	DisableLegacyRoutes bool
+	// Data informada no cabeçalho Sunset dos aliases /api sem versão
+	LegacyRoutesSunset time.Time
+	// Aceita HTTP/2 sem TLS (h2c), para implantações internas
+	H2C bool
 }
 
 type JWTConfig struct {
 	SecretKey       string
 	ExpirationHours int
+
+	// Segredo anterior, aceito apenas na verificação durante a rotação
+	OldSecretKey             string
+	RotationGracePeriodHours int // Horas em que o segredo anterior continua aceito
+}
+
+type SecurityConfig struct {
+	PasswordMinLength      int
+	PasswordRequireUpper   bool
+	PasswordRequireLower   bool
+	PasswordRequireDigit   bool
+	PasswordRequireSpecial bool
+	BcryptCost             int
+	// Domínios de email aceitos no cadastro; vazio aceita qualquer domínio
+	AllowedEmailDomains []string
+}
+
+type RedisConfig struct {
+	PipelineBatchSize int    // Máximo de comandos por pipeline
+	KeyPrefix         string // Namespace das chaves quando várias instâncias compartilham o Redis (ex.: "tenant1:")
+}
+
+type DiagnosticsConfig struct {
+	// Diferença máxima entre goroutines do runtime e as rastreadas antes de suspeitar de vazamento
+	MaxGoroutineLeakThreshold int
+	// Testar na inicialização se os PLCs ativos respondem na rede
+	StartupCheckPLCReachability bool
+
+	// Amostras brutas guardadas por histograma de métricas sem faixas
+	MetricsHistogramMaxSamples int
+}
+
+type HealthConfig struct {
+	// Latência de consulta acima da qual o PostgreSQL é considerado degradado
+	DBLatencyThreshold time.Duration
+	// Latência de SET/GET acima da qual o Redis é considerado degradado
+	RedisLatencyThreshold time.Duration
+	// Prefixo das sondas live/ready/startup (ex.: /k8s, para conviver com
+	// outro serviço no mesmo host)
+	ProbePathPrefix string
+}
+
+type ProfileConfig struct {
+	// Canais de notificação que os usuários podem configurar no perfil
+	NotificationChannels []string
+}
+
+type AlarmConfig struct {
+	// Destinatário das notificações de escalonamento pelo canal "email"
+	EscalationEmail string
 }
 
 func LoadConfig(path string) (*Config, error) {
@@ -34,7 +121,24 @@ func LoadConfig(path string) (*Config, error) {
 
 	return &Config{
 		Server: ServerConfig{
-			Port: getEnv("SERVER_PORT", "8080"),
+			Port:           getEnv("SERVER_PORT", "8080"),
+			PublicURL:      getEnv("APP_PUBLIC_URL", "http://localhost:"+getEnv("SERVER_PORT", "8080")),
+			AllowedOrigins: getEnvAsList("CORS_ALLOWED_ORIGINS", "*"),
+			AllowedHeaders: getEnvAsList("CORS_ALLOWED_HEADERS",
+				"Origin,Content-Type,Content-Length,Accept-Encoding,X-CSRF-Token,Authorization,X-Bypass-Cache"),
+			MaxAge:              getEnvAsInt("CORS_MAX_AGE", 86400),
+			AdminAllowedCIDRs:   getEnvAsList("SERVER_ADMIN_ALLOWED_CIDRS", ""),
+			TrustedProxies:      getEnvAsList("SERVER_TRUSTED_PROXIES", ""),
+			DashboardUser:       getEnv("SERVER_DASHBOARD_USER", ""),
+			DashboardPassword:   getEnv("SERVER_DASHBOARD_PASSWORD", ""),
+			ReadTimeout:         time.Duration(getEnvAsInt("SERVER_READ_TIMEOUT", 10)) * time.Second,
+			WriteTimeout:        time.Duration(getEnvAsInt("SERVER_WRITE_TIMEOUT", 10)) * time.Second,
+			RateLimitPerMinute:  getEnvAsInt("SERVER_RATE_LIMIT_PER_MINUTE", 600),
+			MaxRequestSizeBytes: int64(getEnvAsInt("SERVER_MAX_REQUEST_SIZE_BYTES", 100*1024)),
+			AvatarMaxSizeBytes:  int64(getEnvAsInt("AVATAR_MAX_SIZE_BYTES", 2*1024*1024)),
+			DisableLegacyRoutes: getEnvAsBool("SERVER_DISABLE_LEGACY_ROUTES", false),
+			LegacyRoutesSunset:  getEnvAsDate("SERVER_LEGACY_ROUTES_SUNSET", "2027-04-30"),
+			H2C:                 getEnvAsBool("SERVER_H2C", false),
 		},
 		DB: database.Config{
 			Host:     getEnv("DB_HOST", "localhost"),
@@ -43,14 +147,114 @@ func LoadConfig(path string) (*Config, error) {
 			Password: getEnv("DB_PASSWORD", "Danilo@34333528"),
 			DBName:   getEnv("DB_NAME", "app_padrao"),
 			SSLMode:  getEnv("DB_SSLMODE", "disable"),
+
+			MaxOpenConns:    getEnvAsInt("DB_MAX_OPEN_CONNS", 25),
+			MaxIdleConns:    getEnvAsInt("DB_MAX_IDLE_CONNS", 5),
+			ConnMaxLifetime: time.Duration(getEnvAsInt("DB_CONN_MAX_LIFETIME_SECS", 300)) * time.Second,
+			MaxConnsPerPLC:  getEnvAsInt("DB_MAX_CONNS_PER_PLC", 2),
+			QueryTimeout:    time.Duration(getEnvAsInt("DB_QUERY_TIMEOUT_MS", 5000)) * time.Millisecond,
 		},
 		JWT: JWTConfig{
 			SecretKey:       getEnv("JWT_SECRET", "chave_super_segura_app_padrao"),
 			ExpirationHours: expirationHours,
+
+			OldSecretKey:             getEnv("JWT_SECRET_OLD", ""),
+			RotationGracePeriodHours: getEnvAsInt("JWT_ROTATION_GRACE_PERIOD_HOURS", 24),
+		},
+		Security: SecurityConfig{
+			PasswordMinLength:      getEnvAsInt("SECURITY_PASSWORD_MIN_LENGTH", 8),
+			PasswordRequireUpper:   getEnvAsBool("SECURITY_PASSWORD_REQUIRE_UPPER", true),
+			PasswordRequireLower:   getEnvAsBool("SECURITY_PASSWORD_REQUIRE_LOWER", true),
+			PasswordRequireDigit:   getEnvAsBool("SECURITY_PASSWORD_REQUIRE_DIGIT", true),
+			PasswordRequireSpecial: getEnvAsBool("SECURITY_PASSWORD_REQUIRE_SPECIAL", false),
+			BcryptCost:             getEnvAsInt("SECURITY_BCRYPT_COST", 10),
+			AllowedEmailDomains:    getEnvAsList("AUTH_ALLOWED_EMAIL_DOMAINS", ""),
+		},
+		Redis: RedisConfig{
+			PipelineBatchSize: getEnvAsInt("REDIS_PIPELINE_BATCH_SIZE", 100),
+			KeyPrefix:         getEnv("REDIS_KEY_PREFIX", ""),
+		},
+		Diagnostics: DiagnosticsConfig{
+			MaxGoroutineLeakThreshold:   getEnvAsInt("GOROUTINE_LEAK_THRESHOLD", 50),
+			StartupCheckPLCReachability: getEnvAsBool("STARTUP_CHECK_PLC_REACHABILITY", true),
+
+			MetricsHistogramMaxSamples: getEnvAsInt("METRICS_HISTOGRAM_MAX_SAMPLES", 1000),
+		},
+		Profile: ProfileConfig{
+			NotificationChannels: getEnvAsList("NOTIFICATION_CHANNELS", "email,push,sms,teams"),
+		},
+		Alarm: AlarmConfig{
+			EscalationEmail: getEnv("ALARM_ESCALATION_EMAIL", ""),
+		},
+		Health: HealthConfig{
+			DBLatencyThreshold:    time.Duration(getEnvAsInt("HEALTH_DB_LATENCY_THRESHOLD_MS", 50)) * time.Millisecond,
+			RedisLatencyThreshold: time.Duration(getEnvAsInt("HEALTH_REDIS_LATENCY_THRESHOLD_MS", 10)) * time.Millisecond,
+			ProbePathPrefix:       strings.TrimRight(getEnv("KUBERNETES_PROBE_PATH_PREFIX", ""), "/"),
 		},
 	}, nil
 }
 
+// ValidateConfig verifica valores de configuração que impedem a aplicação de
+// funcionar. Retorna todos os problemas encontrados.
+func ValidateConfig(cfg *Config) error {
+	var errs []error
+
+	if port, err := strconv.Atoi(cfg.Server.Port); err != nil || port <= 0 || port > 65535 {
+		errs = append(errs, fmt.Errorf("SERVER_PORT inválida: %q", cfg.Server.Port))
+	}
+	if cfg.Server.ReadTimeout <= 0 || cfg.Server.WriteTimeout <= 0 {
+		errs = append(errs, errors.New("SERVER_READ_TIMEOUT e SERVER_WRITE_TIMEOUT devem ser positivos"))
+	}
+	if !cfg.Server.DisableLegacyRoutes && cfg.Server.LegacyRoutesSunset.IsZero() {
+		errs = append(errs, errors.New("SERVER_LEGACY_ROUTES_SUNSET inválida: use o formato AAAA-MM-DD"))
+	}
+	for _, cidr := range cfg.Server.AdminAllowedCIDRs {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			errs = append(errs, fmt.Errorf("SERVER_ADMIN_ALLOWED_CIDRS: faixa CIDR inválida %q", cidr))
+		}
+	}
+	for _, proxy := range cfg.Server.TrustedProxies {
+		if !validIPOrCIDR(proxy) {
+			errs = append(errs, fmt.Errorf("SERVER_TRUSTED_PROXIES: IP ou faixa CIDR inválida %q", proxy))
+		}
+	}
+	if cfg.DB.Host == "" || cfg.DB.DBName == "" {
+		errs = append(errs, errors.New("DB_HOST e DB_NAME são obrigatórios"))
+	}
+	if cfg.DB.MaxOpenConns > 0 && cfg.DB.MaxIdleConns > cfg.DB.MaxOpenConns {
+		errs = append(errs, fmt.Errorf("DB_MAX_IDLE_CONNS (%d) maior que DB_MAX_OPEN_CONNS (%d)", cfg.DB.MaxIdleConns, cfg.DB.MaxOpenConns))
+	}
+	if cfg.JWT.SecretKey == "" {
+		errs = append(errs, errors.New("JWT_SECRET é obrigatório"))
+	}
+	if cfg.JWT.ExpirationHours <= 0 {
+		errs = append(errs, errors.New("JWT_EXPIRATION_HOURS deve ser positivo"))
+	}
+	if cfg.JWT.RotationGracePeriodHours <= 0 {
+		errs = append(errs, errors.New("JWT_ROTATION_GRACE_PERIOD_HOURS deve ser positivo"))
+	}
+	if cfg.Security.PasswordMinLength <= 0 {
+		errs = append(errs, errors.New("SECURITY_PASSWORD_MIN_LENGTH deve ser positivo"))
+	}
+	if cfg.Security.BcryptCost < 4 || cfg.Security.BcryptCost > 31 {
+		errs = append(errs, fmt.Errorf("SECURITY_BCRYPT_COST deve estar entre 4 e 31: %d", cfg.Security.BcryptCost))
+	}
+	if cfg.Health.DBLatencyThreshold <= 0 || cfg.Health.RedisLatencyThreshold <= 0 {
+		errs = append(errs, errors.New("HEALTH_DB_LATENCY_THRESHOLD_MS e HEALTH_REDIS_LATENCY_THRESHOLD_MS devem ser positivos"))
+	}
+
+	return errors.Join(errs...)
+}
+
+// validIPOrCIDR indica se o valor é um IP ou uma faixa CIDR
+func validIPOrCIDR(value string) bool {
+	if net.ParseIP(value) != nil {
+		return true
+	}
+	_, _, err := net.ParseCIDR(value)
+	return err == nil
+}
+
 func getEnv(key, defaultValue string) string {
 	value := os.Getenv(key)
 	if value == "" {
@@ -58,3 +262,24 @@ func getEnv(key, defaultValue string) string {
 	}
 	return value
 }
+
+// getEnvAsList lê uma lista separada por vírgulas, descartando itens vazios
+func getEnvAsList(key, defaultValue string) []string {
+	var items []string
+	for _, item := range strings.Split(getEnv(key, defaultValue), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// getEnvAsDate lê uma data no formato AAAA-MM-DD; valor inválido resulta na
+// data zero, recusada por ValidateConfig
+func getEnvAsDate(key, defaultValue string) time.Time {
+	date, err := time.Parse(time.DateOnly, getEnv(key, defaultValue))
+	if err != nil {
+		return time.Time{}
+	}
+	return date
+}
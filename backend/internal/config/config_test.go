@@ -0,0 +1,58 @@
+package config
+
+import (
+	"app_padrao/pkg/database"
+	"strings"
+	"testing"
+	"time"
+)
+
+// validConfig retorna uma configuração aceita por ValidateConfig
+func validConfig() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Port:                "8080",
+			ReadTimeout:         10 * time.Second,
+			WriteTimeout:        10 * time.Second,
+			DisableLegacyRoutes: true,
+		},
+		DB:       database.Config{Host: "localhost", DBName: "app_padrao"},
+		JWT:      JWTConfig{SecretKey: "segredo", ExpirationHours: 24, RotationGracePeriodHours: 24},
+		Security: SecurityConfig{PasswordMinLength: 8, BcryptCost: 10},
+		Health:   HealthConfig{DBLatencyThreshold: time.Millisecond, RedisLatencyThreshold: time.Millisecond},
+	}
+}
+
+func TestValidateConfigAddressLists(t *testing.T) {
+	tests := []struct {
+		name    string
+		cidrs   []string
+		proxies []string
+		wantErr string
+	}{
+		{"listas vazias", nil, nil, ""},
+		{"faixas e proxies válidos", []string{"10.0.0.0/8", "2001:db8::/32"}, []string{"192.168.0.1", "172.16.0.0/12"}, ""},
+		{"faixa com erro de digitação", []string{"10.0.0.0/8", "10.0.0.300/8"}, nil, "SERVER_ADMIN_ALLOWED_CIDRS"},
+		{"IP sem máscara na whitelist", []string{"10.0.0.1"}, nil, "SERVER_ADMIN_ALLOWED_CIDRS"},
+		{"proxy inválido", nil, []string{"proxy.local"}, "SERVER_TRUSTED_PROXIES"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validConfig()
+			cfg.Server.AdminAllowedCIDRs = tt.cidrs
+			cfg.Server.TrustedProxies = tt.proxies
+
+			err := ValidateConfig(cfg)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("erro inesperado: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("erro = %v, esperado menção a %s", err, tt.wantErr)
+			}
+		})
+	}
+}
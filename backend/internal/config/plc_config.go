@@ -17,6 +17,27 @@ type PLCConfig struct {
 	SyncInterval          int  // Intervalo em minutos para sincronização
 	ConnectionTimeout     int  // Timeout em segundos para conexão com PLC
 	EnableDetailedLogging bool // Habilitar logs detalhados
+	MaxTagsPerPLC         int  // Tags permitidas por PLC (0 = sem limite)
+	MaxStreamClients      int  // Clientes simultâneos de streaming SSE (0 = sem limite)
+	// Falhas de leitura consecutivas que desativam a tag (0 = nunca desativa)
+	ConsecutiveErrorThreshold int
+	ValidatorsFile            string // Intertravamentos de escrita (JSON)
+	AutoAdaptScanRates        bool   // Adaptar semanalmente o scan rate das tags pelo histórico
+	RetentionDays             int    // Dias de histórico de tags mantidos (0 = sem limite)
+	StartupStaggerMs          int    // Atraso (ms) entre o início de cada PLC
+	PingIntervalSec           int    // Intervalo entre pings das conexões ativas (0 = desativado)
+	MaxConcurrentConnections  int    // PLCs conectando ao mesmo tempo (0 = sem limite)
+	EnableAccessLog           bool   // Registrar quais usuários leram quais tags (auditoria)
+	MetadataCacheSize         int    // PLCs mantidos em memória (0 = desativado)
+	MetadataCacheTTLSec       int    // Validade (s) de cada PLC em memória
+
+	// Convenção de nomes das tags (regex) e sua descrição para os usuários
+	TagNamePattern     string
+	TagNameDescription string
+
+	// Habilitar a gravação de bytes brutos em DBs (PUT .../db/:dbNumber/poke),
+	// que ignora os intertravamentos. Desligado por padrão.
+	EnableDBPoke bool
 }
 
 // LoadPLCConfig carrega configurações para o sistema de PLCs
@@ -33,6 +54,24 @@ func LoadPLCConfig() PLCConfig {
 		SyncInterval:          getEnvAsInt("PLC_SYNC_INTERVAL", 5),
 		ConnectionTimeout:     getEnvAsInt("PLC_CONNECTION_TIMEOUT", 10),
 		EnableDetailedLogging: getEnvAsBool("PLC_DETAILED_LOGGING", false),
+		MaxTagsPerPLC:         getEnvAsInt("PLC_MAX_TAGS_PER_PLC", 500),
+		MaxStreamClients:      getEnvAsInt("PLC_MAX_STREAM_CLIENTS", 100),
+
+		ConsecutiveErrorThreshold: getEnvAsInt("PLC_CONSECUTIVE_ERROR_THRESHOLD", 10),
+		ValidatorsFile:            getEnv("PLC_VALIDATORS_FILE", "validators.json"),
+		AutoAdaptScanRates:        getEnvAsBool("PLC_AUTO_ADAPT_SCAN_RATES", false),
+		RetentionDays:             getEnvAsInt("PLC_HISTORY_RETENTION_DAYS", 90),
+		StartupStaggerMs:          getEnvAsInt("PLC_STARTUP_STAGGER_MS", 100),
+		PingIntervalSec:           getEnvAsInt("PLC_PING_INTERVAL_SEC", 30),
+		MaxConcurrentConnections:  getEnvAsInt("PLC_MAX_CONCURRENT_CONNECTIONS", 10),
+		EnableAccessLog:           getEnvAsBool("PLC_ENABLE_ACCESS_LOG", false),
+		MetadataCacheSize:         getEnvAsInt("PLC_METADATA_CACHE_SIZE", 1000),
+		MetadataCacheTTLSec:       getEnvAsInt("PLC_METADATA_CACHE_TTL_SEC", 60),
+
+		TagNamePattern:     getEnv("PLC_TAG_NAME_PATTERN", ".*"),
+		TagNameDescription: getEnv("PLC_TAG_NAME_DESCRIPTION", ""),
+
+		EnableDBPoke: getEnvAsBool("PLC_ENABLE_DB_POKE", false),
 	}
 }
 
@@ -0,0 +1,326 @@
+// internal/diagnostics/startup.go
+package diagnostics
+
+import (
+	"app_padrao/internal/cache"
+	"app_padrao/internal/config"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// ErrCriticalCheckFailed indica que uma verificação crítica falhou e a
+// aplicação não deve iniciar
+var ErrCriticalCheckFailed = errors.New("verificação crítica de inicialização falhou")
+
+// Status das verificações
+const (
+	StatusOK      = "ok"
+	StatusWarning = "warning"
+	StatusFailed  = "failed"
+)
+
+// Severidade de uma verificação: falhas críticas impedem a inicialização,
+// avisos são apenas registrados
+const (
+	SeverityCritical = "critical"
+	SeverityWarning  = "warning"
+)
+
+const (
+	// Tamanho mínimo recomendado para JWT_SECRET
+	minJWTSecretLength = 32
+	// Uso de memória do Redis (em relação a maxmemory) a partir do qual há aviso
+	redisMemoryWarningRatio = 0.9
+	// Porta ISO-TSAP dos PLCs S7 e espera máxima pela conexão
+	plcPort        = "102"
+	plcDialTimeout = 2 * time.Second
+	checkTimeout   = 5 * time.Second
+)
+
+// requiredTables são as tabelas criadas por banco.txt e pelos repositórios
+var requiredTables = []string{
+	"users", "roles", "permissions", "role_permissions", "profiles", "themes",
+	"plcs", "plc_tags", "plc_sites", "plc_scan_groups", "tag_history", "tag_alarms", "tag_alarm_events", "tag_annotations",
+	"tag_dependencies", "tag_access_log",
+}
+
+// requiredColumns são colunas adicionadas por alterações posteriores do
+// esquema; sua ausência indica alteração não aplicada
+var requiredColumns = []struct{ table, column string }{
+	{"users", "deleted_at"},
+	{"plcs", "site_id"},
+	{"plc_tags", "auto_disabled_at"},
+	{"plc_tags", "last_read_error"},
+	{"plc_tags", "retention_days"},
+	{"plc_tags", "scan_group_id"},
+	{"plcs", "protocol_variant"},
+	{"plcs", "remote_tsap"},
+	{"profiles", "dashboard_config"},
+}
+
+// DiagnosticResult é o resultado de uma verificação de inicialização
+type DiagnosticResult struct {
+	Name     string
+	Status   string
+	Severity string
+	Details  string
+	Duration time.Duration
+}
+
+// Failed indica se a verificação falhou com severidade crítica
+func (r DiagnosticResult) Failed() bool {
+	return r.Status == StatusFailed && r.Severity == SeverityCritical
+}
+
+// startupCheck é uma verificação e a severidade de sua falha. run retorna o
+// status e os detalhes do resultado.
+type startupCheck struct {
+	name     string
+	severity string
+	run      func() (string, string)
+}
+
+// RunStartupCheck executa as verificações de inicialização e retorna os
+// resultados de todas elas. Retorna ErrCriticalCheckFailed se alguma
+// verificação crítica falhou.
+func RunStartupCheck(cfg *config.Config, db *sql.DB, redisCache *cache.RedisCache) ([]DiagnosticResult, error) {
+	checks := []startupCheck{
+		{"Banco de dados", SeverityCritical, func() (string, string) { return checkDatabase(db) }},
+		{"Redis", SeverityCritical, func() (string, string) { return checkRedis(redisCache) }},
+		{"Configuração", SeverityCritical, func() (string, string) { return checkConfig(cfg) }},
+		{"Segredo JWT", SeverityWarning, func() (string, string) { return checkJWTSecret(cfg.JWT.SecretKey) }},
+		{"Esquema do banco", SeverityCritical, func() (string, string) { return checkSchema(db) }},
+	}
+	if cfg.Diagnostics.StartupCheckPLCReachability {
+		checks = append(checks, startupCheck{"Rede dos PLCs", SeverityWarning, func() (string, string) { return checkPLCReachability(db) }})
+	}
+
+	results := make([]DiagnosticResult, 0, len(checks))
+	var failed []string
+	for _, check := range checks {
+		start := time.Now()
+		status, details := check.run()
+		result := DiagnosticResult{
+			Name:     check.name,
+			Status:   status,
+			Severity: check.severity,
+			Details:  details,
+			Duration: time.Since(start),
+		}
+		results = append(results, result)
+		if result.Failed() {
+			failed = append(failed, result.Name)
+		}
+	}
+
+	if len(failed) > 0 {
+		return results, fmt.Errorf("%w: %s", ErrCriticalCheckFailed, strings.Join(failed, ", "))
+	}
+	return results, nil
+}
+
+// PrintResults escreve os resultados em forma de tabela
+func PrintResults(w io.Writer, results []DiagnosticResult) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "VERIFICAÇÃO\tSTATUS\tSEVERIDADE\tDURAÇÃO\tDETALHES")
+	for _, r := range results {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%v\t%s\n",
+			r.Name, strings.ToUpper(r.Status), r.Severity, r.Duration.Round(time.Millisecond), r.Details)
+	}
+	tw.Flush()
+}
+
+// checkDatabase verifica a conexão e a versão do PostgreSQL
+func checkDatabase(db *sql.DB) (string, string) {
+	ctx, cancel := context.WithTimeout(context.Background(), checkTimeout)
+	defer cancel()
+
+	var version string
+	if err := db.QueryRowContext(ctx, "SHOW server_version").Scan(&version); err != nil {
+		return StatusFailed, err.Error()
+	}
+	return StatusOK, "PostgreSQL " + version
+}
+
+// checkRedis verifica a conexão e o uso de memória do Redis
+func checkRedis(redisCache *cache.RedisCache) (string, string) {
+	client := redisCache.GetRedisClient()
+	if client == nil {
+		return StatusFailed, cache.ErrRedisNotConnected.Error()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), checkTimeout)
+	defer cancel()
+
+	info, err := client.Info(ctx, "memory").Result()
+	if err != nil {
+		return StatusFailed, err.Error()
+	}
+
+	fields := parseRedisInfo(info)
+	used, _ := strconv.ParseInt(fields["used_memory"], 10, 64)
+	max, _ := strconv.ParseInt(fields["maxmemory"], 10, 64)
+	if max <= 0 {
+		return StatusOK, fmt.Sprintf("memória em uso: %s (sem maxmemory)", fields["used_memory_human"])
+	}
+
+	details := fmt.Sprintf("memória em uso: %s de %s", fields["used_memory_human"], fields["maxmemory_human"])
+	if float64(used) >= float64(max)*redisMemoryWarningRatio {
+		return StatusWarning, details
+	}
+	return StatusOK, details
+}
+
+// parseRedisInfo converte a saída do comando INFO em chave/valor
+func parseRedisInfo(info string) map[string]string {
+	fields := make(map[string]string)
+	for _, line := range strings.Split(info, "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), ":")
+		if ok {
+			fields[key] = value
+		}
+	}
+	return fields
+}
+
+// checkConfig valida a configuração carregada
+func checkConfig(cfg *config.Config) (string, string) {
+	if err := config.ValidateConfig(cfg); err != nil {
+		return StatusFailed, strings.ReplaceAll(err.Error(), "\n", "; ")
+	}
+	return StatusOK, "configuração válida"
+}
+
+// checkJWTSecret verifica o tamanho do segredo usado para assinar os tokens
+func checkJWTSecret(secret string) (string, string) {
+	if len(secret) < minJWTSecretLength {
+		return StatusFailed, fmt.Sprintf("JWT_SECRET com %d caracteres (mínimo recomendado: %d)", len(secret), minJWTSecretLength)
+	}
+	return StatusOK, fmt.Sprintf("%d caracteres", len(secret))
+}
+
+// checkSchema verifica se as tabelas e colunas esperadas existem, ou seja,
+// se todas as alterações do esquema foram aplicadas
+func checkSchema(db *sql.DB) (string, string) {
+	ctx, cancel := context.WithTimeout(context.Background(), checkTimeout)
+	defer cancel()
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT table_name, column_name
+		FROM information_schema.columns
+		WHERE table_schema = current_schema()
+	`)
+	if err != nil {
+		return StatusFailed, err.Error()
+	}
+	defer rows.Close()
+
+	tables := make(map[string]map[string]bool)
+	for rows.Next() {
+		var table, column string
+		if err := rows.Scan(&table, &column); err != nil {
+			return StatusFailed, err.Error()
+		}
+		if tables[table] == nil {
+			tables[table] = make(map[string]bool)
+		}
+		tables[table][column] = true
+	}
+	if err := rows.Err(); err != nil {
+		return StatusFailed, err.Error()
+	}
+
+	var missing []string
+	for _, table := range requiredTables {
+		if tables[table] == nil {
+			missing = append(missing, "tabela "+table)
+		}
+	}
+	for _, c := range requiredColumns {
+		if tables[c.table] != nil && !tables[c.table][c.column] {
+			missing = append(missing, "coluna "+c.table+"."+c.column)
+		}
+	}
+
+	if len(missing) > 0 {
+		return StatusFailed, "alterações não aplicadas: " + strings.Join(missing, ", ")
+	}
+	return StatusOK, fmt.Sprintf("%d tabelas verificadas, todas as alterações aplicadas", len(requiredTables))
+}
+
+// MigrationsPending indica se alguma tabela ou coluna esperada ainda não
+// existe no banco (ou se o esquema não pôde ser verificado)
+func MigrationsPending(db *sql.DB) bool {
+	status, _ := checkSchema(db)
+	return status != StatusOK
+}
+
+// checkPLCReachability testa a conexão TCP com todos os PLCs ativos
+func checkPLCReachability(db *sql.DB) (string, string) {
+	ctx, cancel := context.WithTimeout(context.Background(), checkTimeout)
+	defer cancel()
+
+	rows, err := db.QueryContext(ctx, "SELECT name, ip_address FROM plcs WHERE active = true ORDER BY id")
+	if err != nil {
+		return StatusFailed, err.Error()
+	}
+	defer rows.Close()
+
+	type plcAddress struct{ name, address string }
+	var plcs []plcAddress
+	for rows.Next() {
+		var p plcAddress
+		if err := rows.Scan(&p.name, &p.address); err != nil {
+			return StatusFailed, err.Error()
+		}
+		plcs = append(plcs, p)
+	}
+	if err := rows.Err(); err != nil {
+		return StatusFailed, err.Error()
+	}
+
+	if len(plcs) == 0 {
+		return StatusOK, "nenhum PLC ativo"
+	}
+
+	var (
+		mu          sync.Mutex
+		wg          sync.WaitGroup
+		unreachable []string
+	)
+	for _, p := range plcs {
+		wg.Add(1)
+		go func(p plcAddress) {
+			defer wg.Done()
+
+			address := p.address
+			if _, _, err := net.SplitHostPort(address); err != nil {
+				address = net.JoinHostPort(address, plcPort)
+			}
+
+			conn, err := net.DialTimeout("tcp", address, plcDialTimeout)
+			if err != nil {
+				mu.Lock()
+				unreachable = append(unreachable, fmt.Sprintf("%s (%s)", p.name, address))
+				mu.Unlock()
+				return
+			}
+			conn.Close()
+		}(p)
+	}
+	wg.Wait()
+
+	if len(unreachable) > 0 {
+		return StatusFailed, fmt.Sprintf("%d de %d PLCs sem resposta: %s", len(unreachable), len(plcs), strings.Join(unreachable, ", "))
+	}
+	return StatusOK, fmt.Sprintf("%d PLCs acessíveis", len(plcs))
+}
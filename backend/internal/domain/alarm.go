@@ -0,0 +1,102 @@
+// internal/domain/alarm.go
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Severidades de alarme, da menor para a maior
+const (
+	AlarmSeverityInfo     = "info"
+	AlarmSeverityWarning  = "warning"
+	AlarmSeverityCritical = "critical"
+)
+
+var alarmSeverityRank = map[string]int{
+	AlarmSeverityInfo:     1,
+	AlarmSeverityWarning:  2,
+	AlarmSeverityCritical: 3,
+}
+
+// AlarmSeverityRank retorna a ordem da severidade (0 quando desconhecida)
+func AlarmSeverityRank(severity string) int {
+	return alarmSeverityRank[strings.ToLower(strings.TrimSpace(severity))]
+}
+
+// EscalationRule eleva a severidade e/ou notifica outros canais quando o
+// alarme continua sem reconhecimento depois de AfterMinutes
+type EscalationRule struct {
+	AfterMinutes   int      `json:"after_minutes"`
+	NewSeverity    string   `json:"new_severity,omitempty"`
+	NotifyChannels []string `json:"notify_channels,omitempty"`
+}
+
+// TagAlarm é a definição de um alarme associado a uma tag
+type TagAlarm struct {
+	ID              int              `json:"id"`
+	PLCID           int              `json:"plc_id"`
+	TagID           int              `json:"tag_id"`
+	Name            string           `json:"name"`
+	Severity        string           `json:"severity"`
+	EscalationRules []EscalationRule `json:"escalation_rules"`
+	Active          bool             `json:"active"`
+	CreatedAt       time.Time        `json:"created_at"`
+}
+
+// TagAlarmEvent é uma ocorrência de alarme
+type TagAlarmEvent struct {
+	ID              int64      `json:"id"`
+	AlarmID         int        `json:"alarm_id"`
+	AlarmName       string     `json:"alarm_name"`
+	PLCID           int        `json:"plc_id"`
+	TagID           int        `json:"tag_id"`
+	Severity        string     `json:"severity"`
+	Message         string     `json:"message"`
+	Active          bool       `json:"active"`
+	RaisedAt        time.Time  `json:"raised_at"`
+	EscalationLevel int        `json:"escalation_level"` // Regras de escalonamento já aplicadas
+	EscalatedAt     *time.Time `json:"escalated_at,omitempty"`
+	AcknowledgedBy  *int       `json:"acknowledged_by,omitempty"`
+	AcknowledgedAt  *time.Time `json:"acknowledged_at,omitempty"`
+	AckComment      string     `json:"ack_comment,omitempty"`
+}
+
+// AlarmEscalationCandidate é um evento ativo sem reconhecimento com as regras
+// de escalonamento do seu alarme
+type AlarmEscalationCandidate struct {
+	Event TagAlarmEvent
+	Rules []EscalationRule
+}
+
+// TagAlarmRepository persiste alarmes e suas ocorrências
+type TagAlarmRepository interface {
+	GetActiveEvents() ([]TagAlarmEvent, error)
+	GetEscalationCandidates() ([]AlarmEscalationCandidate, error)
+	Escalate(eventID int64, level int, severity string) error
+	Acknowledge(eventID int64, userID int, comment string) (TagAlarmEvent, error)
+}
+
+// Erros de alarmes
+var (
+	ErrAlarmEventNotFound       = errors.New("ocorrência de alarme não encontrada")
+	ErrAlarmAlreadyAcknowledged = errors.New("alarme já reconhecido")
+	ErrInvalidAlarmSeverity     = errors.New("severidade de alarme inválida")
+	ErrTagsHaveActiveAlarms     = errors.New("tags com alarmes ativos")
+)
+
+// ActiveAlarmConflictError é retornado ao excluir tags que ainda têm
+// ocorrências de alarme ativas, sem force=true
+type ActiveAlarmConflictError struct {
+	Alarms []TagAlarmEvent
+}
+
+func (e *ActiveAlarmConflictError) Error() string {
+	return fmt.Sprintf("%v: %d ocorrência(s)", ErrTagsHaveActiveAlarms, len(e.Alarms))
+}
+
+func (e *ActiveAlarmConflictError) Unwrap() error {
+	return ErrTagsHaveActiveAlarms
+}
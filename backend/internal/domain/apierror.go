@@ -0,0 +1,77 @@
+// internal/domain/apierror.go
+package domain
+
+//go:generate go run ../../cmd/errcodes -in apierror.go -out ../../docs/error-codes.md
+
+// APIError é o corpo de todas as respostas de erro da API. Code é estável
+// entre versões e deve ser usado pelos clientes no tratamento de erros;
+// Message é apenas informativa e pode mudar.
+type APIError struct {
+	Code    string      `json:"code"`
+	Message string      `json:"message"`
+	Details interface{} `json:"details,omitempty"`
+}
+
+// Códigos de erro da API. Fazem parte da API pública: não renomeie nem
+// reutilize um código existente. A documentação em docs/error-codes.md é
+// gerada a partir destes comentários (go generate ./internal/domain).
+const (
+	// Corpo da requisição ausente, malformado ou com campos inválidos (400)
+	ErrCodeInvalidPayload = "INVALID_PAYLOAD"
+	// Parâmetro de rota ou de consulta inválido, como um ID não numérico (400)
+	ErrCodeInvalidParameter = "INVALID_PARAMETER"
+	// Configuração da tag inválida: endereço, tipo, escala, limites (400)
+	ErrCodeTagValidation = "TAG_VALIDATION_FAILED"
+	// Senha não atende à política de senhas; details lista as regras violadas (400)
+	ErrCodeWeakPassword = "WEAK_PASSWORD"
+	// Requisição sem autenticação ou com token ausente/inválido (401)
+	ErrCodeUnauthorized = "UNAUTHORIZED"
+	// Usuário ou senha incorretos (401)
+	ErrCodeInvalidCredentials = "INVALID_CREDENTIALS"
+	// Usuário autenticado sem a permissão exigida pela rota (403)
+	ErrCodePermissionDenied = "PERMISSION_DENIED"
+	// Endereço IP de origem fora da lista de IPs permitidos (403)
+	ErrCodeIPNotAllowed = "IP_NOT_ALLOWED"
+	// Tag sem permissão de escrita (403)
+	ErrCodeWriteNotPermitted = "WRITE_NOT_PERMITTED"
+	// Domínio do email fora da lista de domínios aceitos no cadastro (403)
+	ErrCodeEmailDomainNotAllowed = "EMAIL_DOMAIN_NOT_ALLOWED"
+	// Recurso não encontrado (404)
+	ErrCodeNotFound = "NOT_FOUND"
+	// PLC não encontrado (404)
+	ErrCodePLCNotFound = "PLC_NOT_FOUND"
+	// Tag não encontrada (404)
+	ErrCodeTagNotFound = "TAG_NOT_FOUND"
+	// Usuário não encontrado (404)
+	ErrCodeUserNotFound = "USER_NOT_FOUND"
+	// Perfil não encontrado (404)
+	ErrCodeProfileNotFound = "PROFILE_NOT_FOUND"
+	// Role não encontrada (404)
+	ErrCodeRoleNotFound = "ROLE_NOT_FOUND"
+	// Site de PLCs não encontrado (404)
+	ErrCodeSiteNotFound = "SITE_NOT_FOUND"
+	// Ocorrência de alarme não encontrada (404)
+	ErrCodeAlarmNotFound = "ALARM_NOT_FOUND"
+	// Estado atual do recurso impede a operação (409)
+	ErrCodeConflict = "CONFLICT"
+	// Email ou nome de usuário já cadastrado (409)
+	ErrCodeAlreadyExists = "ALREADY_EXISTS"
+	// Endereço da tag sobrepõe outra tag do PLC; details lista os conflitos (409)
+	ErrCodeAddressConflict = "ADDRESS_CONFLICT"
+	// Corpo da requisição excede o tamanho máximo aceito pela rota (413)
+	ErrCodeRequestTooLarge = "REQUEST_TOO_LARGE"
+	// Requisição válida, mas não processável no estado atual (422)
+	ErrCodeUnprocessable = "UNPROCESSABLE"
+	// PLC atingiu o limite de tags (422)
+	ErrCodeTagLimitExceeded = "TAG_LIMIT_EXCEEDED"
+	// Escrita bloqueada por um intertravamento de segurança (422)
+	ErrCodeWriteBlocked = "WRITE_BLOCKED"
+	// Limite de requisições ou de escritas excedido (429)
+	ErrCodeRateLimited = "RATE_LIMITED"
+	// Erro interno inesperado (500)
+	ErrCodeInternal = "INTERNAL_ERROR"
+	// Dependência indisponível: banco de dados, Redis, PLC desconectado (503)
+	ErrCodeServiceUnavailable = "SERVICE_UNAVAILABLE"
+	// Consulta ao banco de dados excedeu o prazo configurado (504)
+	ErrCodeTimeout = "TIMEOUT"
+)
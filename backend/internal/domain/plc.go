@@ -1,7 +1,11 @@
 package domain
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"time"
 
 	"github.com/go-redis/redis/v8"
@@ -9,34 +13,252 @@ import (
 
 // PLC representa um dispositivo PLC no sistema
 type PLC struct {
-	ID        int       `json:"id"`
-	Name      string    `json:"name"`
-	IPAddress string    `json:"ip_address"`
-	Rack      int       `json:"rack"`
-	Slot      int       `json:"slot"`
-	Active    bool      `json:"is_active"`
-	Status    string    `json:"status,omitempty"` // Campo transitório
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at,omitempty"`
+	ID                    int       `json:"id"`
+	Name                  string    `json:"name"`
+	IPAddress             string    `json:"ip_address"`
+	Rack                  int       `json:"rack"`
+	Slot                  int       `json:"slot"`
+	Active                bool      `json:"is_active"`
+	MonitoringEnabled     bool      `json:"monitoring_enabled"`           // Pausa o monitoramento (manutenção) sem desativar o PLC
+	Status                string    `json:"status,omitempty"`             // Campo transitório
+	EffectiveStatus       string    `json:"effective_status,omitempty"`   // Campo transitório: estado real do monitoramento
+	OverrideTagLimit      *int      `json:"override_tag_limit,omitempty"` // Limite de tags próprio (nil = MaxTagsPerPLC)
+	SiteID                *int      `json:"site_id,omitempty"`            // Site (planta) do PLC
+	SiteMonitoringEnabled bool      `json:"site_monitoring_enabled"`      // Campo transitório: false com o site pausado
+	CreatedAt             time.Time `json:"created_at"`
+	UpdatedAt             time.Time `json:"updated_at,omitempty"`
+
+	// Variante do protocolo S7 e endereçamento TSAP (nil = calculado)
+	ProtocolVariant string `json:"protocol_variant,omitempty"` // s7-300, s7-400, s7-1200 ou s7-1500 (vazio = s7-300)
+	LocalTSAP       *int   `json:"local_tsap,omitempty"`
+	RemoteTSAP      *int   `json:"remote_tsap,omitempty"`
+	ProtocolNotes   string `json:"protocol_notes,omitempty"` // Campo transitório
+}
+
+// Variantes do protocolo S7
+const (
+	PLCVariantS7300  = "s7-300"
+	PLCVariantS7400  = "s7-400"
+	PLCVariantS71200 = "s7-1200"
+	PLCVariantS71500 = "s7-1500"
+)
+
+// TSAPs da conexão S7. O TSAP remoto é o tipo de conexão no byte alto mais
+// rack*0x20+slot: conexão PG (0x01) nos S7-300/400 e básica (0x03) nos
+// S7-1200/1500, que só aceitam PUT/GET por ela.
+const (
+	DefaultLocalTSAP    = 0x0100
+	remoteTSAPPGBase    = 0x0100
+	remoteTSAPBasicBase = 0x0300
+)
+
+// PutGetProtocolNote é o aviso exibido para os S7-1200/1500
+const PutGetProtocolNote = "PUT/GET must be enabled in TIA Portal"
+
+// RequiresPutGet indica se a variante exige PUT/GET habilitado no projeto do TIA Portal
+func (p PLC) RequiresPutGet() bool {
+	return p.ProtocolVariant == PLCVariantS71200 || p.ProtocolVariant == PLCVariantS71500
+}
+
+// EffectiveRemoteTSAP retorna o TSAP remoto usado na conexão: o informado
+// em RemoteTSAP ou o calculado a partir da variante, do rack e do slot
+func (p PLC) EffectiveRemoteTSAP() int {
+	if p.RemoteTSAP != nil {
+		return *p.RemoteTSAP
+	}
+	base := remoteTSAPPGBase
+	if p.RequiresPutGet() {
+		base = remoteTSAPBasicBase
+	}
+	return base + p.Rack*0x20 + p.Slot
+}
+
+// ValidateProtocol verifica a variante do protocolo e os TSAPs informados
+func (p PLC) ValidateProtocol() error {
+	switch p.ProtocolVariant {
+	case "", PLCVariantS7300, PLCVariantS7400, PLCVariantS71200, PLCVariantS71500:
+	default:
+		return ErrInvalidProtocolVariant
+	}
+
+	if p.ProtocolVariant == PLCVariantS71200 && p.Slot != 0 {
+		return ErrInvalidS71200Slot
+	}
+
+	// A biblioteca S7 sempre se identifica com o TSAP local 0x0100
+	if p.LocalTSAP != nil && *p.LocalTSAP != DefaultLocalTSAP {
+		return ErrUnsupportedLocalTSAP
+	}
+	if p.RemoteTSAP != nil && (*p.RemoteTSAP <= 0 || *p.RemoteTSAP > 0xFFFF) {
+		return ErrInvalidRemoteTSAP
+	}
+
+	return nil
+}
+
+// Chaves Redis dos ETags das listagens de PLCs e tags. O hash de cada
+// listagem é guardado junto das versões lidas dos contadores; incrementar um
+// contador invalida os hashes que dependem dele sem varrer o Redis.
+const (
+	ETagKeyPLCList = "etag:plcs"
+	ETagKeyPLCTags = "etag:plc:{id}:tags"
+
+	// Contador da listagem de PLCs (cadastro e status)
+	ETagVersionPLCList = "etag:version:plcs"
+	// Contador das tags de um PLC ({id} na rota, %d no serviço)
+	ETagVersionPLCTags       = "etag:version:plc:{id}:tags"
+	ETagVersionPLCTagsFormat = "etag:version:plc:%d:tags"
+	// Contador de todas as listagens de tags, para mudanças de PLC desconhecido
+	ETagVersionAllTags = "etag:version:tags"
+)
+
+// Estados efetivos do monitoramento de um PLC
+const (
+	MonitoringRunning  = "running"  // Goroutine de monitoramento ativa
+	MonitoringPaused   = "paused"   // Monitoramento desabilitado pelo operador
+	MonitoringInactive = "inactive" // PLC desativado
+	MonitoringStopped  = "stopped"  // Habilitado, mas ainda não monitorado
+)
+
+// UnmarshalJSON mantém MonitoringEnabled e SiteMonitoringEnabled = true
+// quando os campos não são enviados (requisições antigas e PLCs já
+// armazenados no Redis)
+func (p *PLC) UnmarshalJSON(data []byte) error {
+	type plcAlias PLC
+	aux := plcAlias{MonitoringEnabled: true, SiteMonitoringEnabled: true}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	*p = PLC(aux)
+	return nil
+}
+
+// IsMonitored indica se o PLC deve ser monitorado
+func (p PLC) IsMonitored() bool {
+	return p.Active && p.MonitoringEnabled && p.SiteMonitoringEnabled
 }
 
 // PLCTag representa uma tag monitorada em um PLC
 type PLCTag struct {
-	ID             int         `json:"id"`
-	PLCID          int         `json:"plc_id"`
-	Name           string      `json:"name"`
-	Description    string      `json:"description"`
-	DBNumber       int         `json:"db_number"`
-	ByteOffset     int         `json:"byte_offset"`
-	BitOffset      int         `json:"bit_offset"` // Offset de bit (0-7)
-	DataType       string      `json:"data_type"`  // "real", "int", "word", "bool", "string"
-	ScanRate       int         `json:"scan_rate"`  // em milissegundos
-	MonitorChanges bool        `json:"monitor_changes"`
-	CanWrite       bool        `json:"can_write"`
-	Active         bool        `json:"active"`
-	CreatedAt      time.Time   `json:"created_at"`
-	UpdatedAt      time.Time   `json:"updated_at,omitempty"`
-	CurrentValue   interface{} `json:"current_value,omitempty"` // Não persistido
+	ID               int            `json:"id"`
+	PLCID            int            `json:"plc_id"`
+	Name             string         `json:"name"`
+	Description      string         `json:"description"`
+	DBNumber         int            `json:"db_number"`
+	ByteOffset       int            `json:"byte_offset"`
+	BitOffset        int            `json:"bit_offset"` // Offset de bit (0-7)
+	DataType         string         `json:"data_type"`  // "real", "int", "word", "bool", "string", "char", "date", "time_of_day", "date_and_time", "timer", "counter"
+	ScanRate         int            `json:"scan_rate"`  // em milissegundos
+	MonitorChanges   bool           `json:"monitor_changes"`
+	CanWrite         bool           `json:"can_write"`
+	Active           bool           `json:"active"`
+	WriteRateLimitHz float64        `json:"write_rate_limit_hz"`        // Escritas por segundo permitidas (0 = sem limite)
+	UnpackBits       bool           `json:"unpack_bits"`                // Word com 16 sinais booleanos empacotados
+	BitLabels        map[int]string `json:"bit_labels,omitempty"`       // Nome do sinal por posição de bit (0-15)
+	MinDelta         float64        `json:"min_delta"`                  // Variação mínima para atualizar o cache (0 = qualquer mudança)
+	ScaleFactor      float64        `json:"scale_factor"`               // Valor = bruto * ScaleFactor + ScaleOffset (padrão 1)
+	ScaleOffset      float64        `json:"scale_offset"`               // Deslocamento somado após o fator (padrão 0)
+	LastWrittenAt    *time.Time     `json:"last_written_at"`            // Última escrita bem-sucedida
+	LastWrittenBy    *int           `json:"last_written_by"`            // Usuário da última escrita (nil = sistema)
+	AutoDisabledAt   *time.Time     `json:"auto_disabled_at,omitempty"` // Desativada por falhas consecutivas de leitura
+	LastReadError    string         `json:"last_read_error,omitempty"`  // Erro que levou à desativação automática
+	RetentionDays    int            `json:"retention_days"`             // Dias de histórico mantidos (0 = padrão global)
+	ScanGroupID      *int           `json:"scan_group_id,omitempty"`    // Grupo lido em uma única requisição (nil = leitura individual)
+	CreatedAt        time.Time      `json:"created_at"`
+	UpdatedAt        time.Time      `json:"updated_at,omitempty"`
+	CurrentValue     *TagReading    `json:"current_value,omitempty"` // Não persistido
+	ChangeRate       *float64       `json:"change_rate,omitempty"`   // Taxa de variação por segundo, não persistida
+
+	// Anotações da tag, preenchidas apenas na consulta individual
+	AnnotationCount *int            `json:"annotation_count,omitempty"`
+	Annotations     []TagAnnotation `json:"annotations,omitempty"` // Apenas com include_annotations=true
+}
+
+// UnmarshalJSON mantém ScaleFactor = 1 quando o campo não é enviado
+// (requisições antigas e tags já armazenadas no Redis)
+func (t *PLCTag) UnmarshalJSON(data []byte) error {
+	type plcTagAlias PLCTag
+	aux := plcTagAlias{ScaleFactor: 1}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	*t = PLCTag(aux)
+	return nil
+}
+
+// HasScaling indica se a tag converte o valor bruto lido do PLC
+func (t PLCTag) HasScaling() bool {
+	return IsNumericDataType(t.DataType) && (t.ScaleFactor != 1 || t.ScaleOffset != 0)
+}
+
+// IsScanned indica se a tag entra na varredura: ativa pelo operador e não
+// desativada automaticamente por falhas de leitura
+func (t PLCTag) IsScanned() bool {
+	return t.Active && t.AutoDisabledAt == nil
+}
+
+// Validate verifica a consistência do endereço da tag: byte offset não
+// negativo, bit offset entre 0 e 7 para bool e zero para os demais tipos,
+// desempacotamento de bits apenas em words e variação mínima apenas em
+// tipos numéricos.
+func (t PLCTag) Validate() error {
+	if t.ByteOffset < 0 {
+		return ErrInvalidByteOffset
+	}
+
+	if t.DataType == "bool" {
+		if t.BitOffset < 0 || t.BitOffset > 7 {
+			return ErrInvalidBitOffset
+		}
+	} else if t.BitOffset != 0 {
+		return ErrBitOffsetNotAllowed
+	}
+
+	if t.UnpackBits && t.DataType != "word" && t.DataType != "uint16" {
+		return ErrUnpackBitsNotAllowed
+	}
+
+	for bit := range t.BitLabels {
+		if bit < 0 || bit > 15 {
+			return ErrInvalidBitLabel
+		}
+	}
+
+	if t.MinDelta < 0 {
+		return ErrInvalidMinDelta
+	}
+	if t.MinDelta > 0 && !IsNumericDataType(t.DataType) {
+		return ErrMinDeltaNotAllowed
+	}
+
+	if t.ScaleFactor == 0 {
+		return ErrInvalidScaleFactor
+	}
+	if (t.ScaleFactor != 1 || t.ScaleOffset != 0) && !IsNumericDataType(t.DataType) {
+		return ErrScalingNotAllowed
+	}
+
+	if t.RetentionDays < 0 {
+		return ErrInvalidRetentionDays
+	}
+
+	return nil
+}
+
+// IsNumericDataType indica se o tipo de dados da tag é numérico
+func IsNumericDataType(dataType string) bool {
+	switch dataType {
+	case "real", "dint", "int32", "dword", "uint32", "int", "int16", "word", "uint16",
+		"sint", "int8", "usint", "byte", "uint8", "counter":
+		return true
+	}
+	return false
+}
+
+// PackedBitID retorna o ID sintético usado no cache para um bit de uma tag
+// com UnpackBits, permitindo ler cada sinal individualmente
+func PackedBitID(tagID, bitPos int) int {
+	return tagID*100 + bitPos
 }
 
 // PLCStatus representa o status de um PLC
@@ -46,23 +268,233 @@ type PLCStatus struct {
 	LastUpdate time.Time `json:"last_update"`
 }
 
+// Qualidade de um valor de tag
+const (
+	QualityGood      = "good"      // Leitura bem-sucedida
+	QualityBad       = "bad"       // Falha de comunicação; valor é o último conhecido
+	QualityUncertain = "uncertain" // Falha de conversão; valor é o último conhecido
+)
+
 // TagValue representa um valor de tag armazenado
 type TagValue struct {
 	PLCID     int         `json:"plc_id"`
 	TagID     int         `json:"tag_id"`
 	Value     interface{} `json:"value"`
+	Quality   string      `json:"quality"`
+	Timestamp time.Time   `json:"timestamp"`
+	// RawValue é o valor lido do PLC antes da escala (apenas tags com escala)
+	RawValue interface{} `json:"raw_value,omitempty"`
+	// Interpolated indica um ponto sintético gerado no preenchimento de lacunas do histórico
+	Interpolated bool `json:"interpolated,omitempty"`
+}
+
+// Tipos de PLCEvent, usados como nome do evento no streaming SSE
+const (
+	PLCEventStatus   = "plc_status"
+	PLCEventTagValue = "tag_value"
+)
+
+// PLCStatusChange é uma mudança no status de conexão de um PLC
+type PLCStatusChange struct {
+	PLCID     int       `json:"plc_id"`
+	OldStatus string    `json:"old_status"`
+	NewStatus string    `json:"new_status"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// PLCEvent é um evento do streaming de eventos dos PLCs. Data é um
+// PLCStatusChange (PLCEventStatus) ou um TagValue (PLCEventTagValue).
+type PLCEvent struct {
+	Type string
+	Data interface{}
+}
+
+// Modos de interpolação das consultas de histórico
+const (
+	InterpolationNone     = "none"     // Apenas leituras reais
+	InterpolationLinear   = "linear"   // Reta entre a última e a próxima leitura conhecidas
+	InterpolationStepwise = "stepwise" // Repete a última leitura conhecida
+)
+
+// TagReading é o valor atual de uma tag como exposto na API
+type TagReading struct {
+	Value     interface{} `json:"value"`
+	RawValue  interface{} `json:"raw_value,omitempty"` // Valor antes da escala
+	Quality   string      `json:"quality"`
 	Timestamp time.Time   `json:"timestamp"`
 }
 
+// Reading converte o valor armazenado para o formato exposto na API.
+// Retorna nil quando não há valor.
+func (v *TagValue) Reading() *TagReading {
+	if v == nil {
+		return nil
+	}
+	return &TagReading{
+		Value:     v.Value,
+		RawValue:  v.RawValue,
+		Quality:   v.Quality,
+		Timestamp: v.Timestamp,
+	}
+}
+
+// QueuedWrite representa uma escrita aguardando na fila de um PLC
+type QueuedWrite struct {
+	RequestID string      `json:"request_id"`
+	PLCID     int         `json:"plc_id"`
+	TagName   string      `json:"tag_name"`
+	Value     interface{} `json:"value"`
+	UserID    int         `json:"user_id,omitempty"`
+	QueuedAt  time.Time   `json:"queued_at"`
+	Attempts  int         `json:"attempts"`
+}
+
+// TagNameConvention é o padrão exigido nos nomes das tags (regex) e sua
+// descrição para os usuários
+type TagNameConvention struct {
+	Pattern     string `json:"pattern"`
+	Description string `json:"description"`
+}
+
+// TagWritePreview é o resultado de uma escrita simulada (dry-run): todas as
+// validações são feitas, mas nada é enviado ao PLC
+type TagWritePreview struct {
+	DryRun       bool        `json:"dry_run"`
+	TagName      string      `json:"tag_name"`
+	DataType     string      `json:"data_type"`
+	CurrentValue interface{} `json:"current_value"`
+	WriteValue   interface{} `json:"write_value"`
+	WouldSucceed bool        `json:"would_succeed"`
+	Conversion   string      `json:"conversion,omitempty"` // Ex.: "float64→float32"
+	Error        string      `json:"error,omitempty"`
+}
+
+// LockStatus descreve o estado de um lock distribuído no Redis
+type LockStatus struct {
+	Key        string `json:"key"`
+	Locked     bool   `json:"locked"`
+	Holder     string `json:"holder,omitempty"` // Instância que detém o lock (host:pid:sufixo)
+	TTLSeconds int64  `json:"ttl_seconds,omitempty"`
+}
+
+// Tipos de conflito de endereço entre tags do mesmo PLC
+const (
+	ConflictOverlappingBytes = "overlapping_bytes"
+	ConflictSameAddress      = "same_address"
+)
+
+// AddressConflict descreve duas tags do mesmo PLC com endereços sobrepostos
+type AddressConflict struct {
+	Tag1         PLCTag `json:"tag1"`
+	Tag2         PLCTag `json:"tag2"`
+	ConflictType string `json:"conflict_type"`
+}
+
+// AddressConflictError é retornado ao criar ou alterar uma tag cujo endereço
+// sobrepõe o de outra tag do mesmo PLC
+type AddressConflictError struct {
+	Conflicts []AddressConflict
+}
+
+func (e *AddressConflictError) Error() string {
+	return fmt.Sprintf("%v: %d conflito(s)", ErrAddressConflict, len(e.Conflicts))
+}
+
+func (e *AddressConflictError) Unwrap() error {
+	return ErrAddressConflict
+}
+
+// TagTypeMigration é o resultado da troca do tipo de dados de uma tag
+type TagTypeMigration struct {
+	TagID    int         `json:"tag_id"`
+	OldType  string      `json:"old_type"`
+	NewType  string      `json:"new_type"`
+	OldValue interface{} `json:"old_value"`
+	NewValue interface{} `json:"new_value"`
+	Quality  string      `json:"quality"`
+}
+
+// TagImportIssue descreve um problema em uma entrada do arquivo importado
+type TagImportIssue struct {
+	Row     int    `json:"row"`
+	Name    string `json:"name"`
+	Message string `json:"message"`
+}
+
+// TagImportResult resume uma importação de tags
+type TagImportResult struct {
+	Total    int              `json:"total"`
+	Created  int              `json:"created"`
+	Skipped  int              `json:"skipped"`
+	Errors   []TagImportIssue `json:"errors"`
+	Warnings []TagImportIssue `json:"warnings"`
+}
+
+// BulkTagDeletePreview lista as tags que serão excluídas após a confirmação
+type BulkTagDeletePreview struct {
+	ConfirmToken string    `json:"confirm_token"`
+	TagsToDelete []PLCTag  `json:"tags_to_delete"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+// BulkTagDeleteResult resume uma exclusão em massa confirmada
+type BulkTagDeleteResult struct {
+	Deleted  int   `json:"deleted"`
+	NotFound []int `json:"not_found"`
+}
+
+// TagHistoryEntry representa um valor de tag registrado no histórico
+type TagHistoryEntry struct {
+	PLCID      int         `json:"plc_id"`
+	TagID      int         `json:"tag_id"`
+	TagName    string      `json:"tag_name,omitempty"` // Preenchido apenas em exportações
+	Value      interface{} `json:"value"`
+	RecordedAt time.Time   `json:"recorded_at"`
+}
+
+// HistoryQueueStats descreve a fila de gravação do histórico de tags
+type HistoryQueueStats struct {
+	Capacity       int     `json:"capacity"`
+	Length         int     `json:"length"`
+	DropRatePerSec float64 `json:"drop_rate_per_sec"`
+	DroppedTotal   int64   `json:"dropped_total"`
+}
+
+// PLCMetadataCacheStats descreve o cache em memória dos PLCs
+type PLCMetadataCacheStats struct {
+	Size     int     `json:"size"`
+	Capacity int     `json:"capacity"`
+	TTLSec   int     `json:"ttl_sec"`
+	Hits     int64   `json:"hits"`
+	Misses   int64   `json:"misses"`
+	HitRate  float64 `json:"hit_rate"` // Acertos / consultas (0 sem consultas)
+}
+
 // PLCConnectionStats contém estatísticas de uma conexão com PLC
 type PLCConnectionStats struct {
 	PLCID         int       `json:"plc_id"`
+	SiteID        *int      `json:"site_id,omitempty"`
 	Name          string    `json:"name"`
 	Status        string    `json:"status"`
 	TagCount      int       `json:"tag_count"`
 	LastConnected time.Time `json:"last_connected"`
 	ReadErrors    int64     `json:"read_errors"`
 	WriteErrors   int64     `json:"write_errors"`
+	RetryCount    int64     `json:"retry_count"`
+	NextRetryAt   time.Time `json:"next_retry_at,omitempty"`
+
+	ReconnectAttempts int64     `json:"reconnect_attempts"` // Reconexões disparadas por falha de ping
+	LastReconnectAt   time.Time `json:"last_reconnect_at,omitempty"`
+	OverrunCount      int64     `json:"overrun_count"` // Disparos do scan descartados por PLC lento
+	LastOverrunAt     time.Time `json:"last_overrun_at,omitempty"`
+
+	LastPingMs float64 `json:"last_ping_ms"` // Duração do último ping da verificação de saúde
+
+	ReadLatencyP50Ms float64 `json:"read_latency_p50_ms"`
+	ReadLatencyP95Ms float64 `json:"read_latency_p95_ms"`
+	ReadLatencyP99Ms float64 `json:"read_latency_p99_ms"`
+	ReadsPerSecond   float64 `json:"reads_per_second"`
+	BytesReadTotal   int64   `json:"bytes_read_total"`
 }
 
 // PLCManagerStats contém estatísticas do gerenciador de PLCs
@@ -75,6 +507,23 @@ type PLCManagerStats struct {
 	WriteErrors     int64                      `json:"write_errors"`
 	LastUpdated     time.Time                  `json:"last_updated"`
 	ConnectionStats map[int]PLCConnectionStats `json:"connections"`
+	Sites           map[int]PLCSiteStats       `json:"sites,omitempty"`
+}
+
+// SyncChangeSummary resume as mudanças ainda não sincronizadas com o Redis
+type SyncChangeSummary struct {
+	PendingPLCs  []int     `json:"pending_plcs"`
+	PendingTags  []int     `json:"pending_tags"`
+	LastSyncTime time.Time `json:"last_sync_time"`
+	IsRunning    bool      `json:"is_running"`
+}
+
+// SyncError registra uma falha ao sincronizar um PLC ou tag
+type SyncError struct {
+	OccurredAt time.Time `json:"occurred_at"`
+	EntityType string    `json:"entity_type"` // "plc" ou "tag"
+	EntityID   int       `json:"entity_id"`
+	Error      string    `json:"error"`
 }
 
 // PLCRepository define operações com PLCs no banco de dados
@@ -82,12 +531,178 @@ type PLCRepository interface {
 	GetByID(id int) (PLC, error)
 	GetAll() ([]PLC, error)
 	GetActivePLCs() ([]PLC, error)
+	// ListWithFilter retorna a página de PLCs que atendem ao filtro, ordenados
+	// por nome, e o total de PLCs encontrados
+	ListWithFilter(ctx context.Context, filter PLCFilter, page, pageSize int) ([]PLC, int, error)
 	Create(plc PLC) (int, error)
 	Update(plc PLC) error
 	Delete(id int) error
 	UpdatePLCStatus(status PLCStatus) error
 }
 
+// PLCFilter define os critérios da listagem de PLCs. Campos vazios ou nulos
+// não filtram.
+type PLCFilter struct {
+	Name   string // Parte do nome, sem diferenciar maiúsculas
+	Status string // "online", "offline" ou "unknown"
+	Active *bool
+	SiteID *int
+}
+
+// TagSearchFilter define os critérios de busca de tags. Campos vazios ou nulos
+// não filtram.
+type TagSearchFilter struct {
+	Query    string // Busca em nome, descrição e tipo de dados
+	PLCID    *int
+	DataType string
+	Active   *bool
+	Page     int
+	PageSize int
+}
+
+// TagFilter seleciona as tags de um PLC afetadas por uma atualização em
+// massa. Campos vazios ou nulos não filtram.
+type TagFilter struct {
+	DataType string `json:"data_type"`
+	Active   *bool  `json:"active"`
+}
+
+// TagPatch contém os campos alterados em uma atualização em massa de tags.
+// Campos nulos não são alterados.
+type TagPatch struct {
+	ScanRate       *int  `json:"scan_rate,omitempty"`
+	Active         *bool `json:"active,omitempty"`
+	MonitorChanges *bool `json:"monitor_changes,omitempty"`
+	CanWrite       *bool `json:"can_write,omitempty"`
+}
+
+// PLCRuntimeConfig é a parte da configuração do serviço de PLCs que pode ser
+// alterada sem reiniciar a aplicação
+type PLCRuntimeConfig struct {
+	DetailedLoggingEnabled    bool `json:"detailed_logging_enabled"`
+	CacheEnabled              bool `json:"cache_enabled"`
+	MaxRetryAttempts          int  `json:"max_retry_attempts"`
+	RetryIntervalMs           int  `json:"retry_interval_ms"`
+	DefaultTagScanRate        int  `json:"default_tag_scan_rate"`
+	MaxTagsPerPLC             int  `json:"max_tags_per_plc"`
+	ConsecutiveErrorThreshold int  `json:"consecutive_error_threshold"`
+	SyncIntervalSec           int  `json:"sync_interval_sec"`
+}
+
+// PLCRuntimeConfigPatch contém os campos da configuração a alterar; campos
+// ausentes mantêm o valor atual
+type PLCRuntimeConfigPatch struct {
+	DetailedLoggingEnabled    *bool `json:"detailed_logging_enabled,omitempty"`
+	CacheEnabled              *bool `json:"cache_enabled,omitempty"`
+	MaxRetryAttempts          *int  `json:"max_retry_attempts,omitempty"`
+	RetryIntervalMs           *int  `json:"retry_interval_ms,omitempty"`
+	DefaultTagScanRate        *int  `json:"default_tag_scan_rate,omitempty"`
+	MaxTagsPerPLC             *int  `json:"max_tags_per_plc,omitempty"`
+	ConsecutiveErrorThreshold *int  `json:"consecutive_error_threshold,omitempty"`
+	SyncIntervalSec           *int  `json:"sync_interval_sec,omitempty"`
+}
+
+// Apply aplica o patch a uma configuração
+func (p PLCRuntimeConfigPatch) Apply(config *PLCRuntimeConfig) {
+	if p.DetailedLoggingEnabled != nil {
+		config.DetailedLoggingEnabled = *p.DetailedLoggingEnabled
+	}
+	if p.CacheEnabled != nil {
+		config.CacheEnabled = *p.CacheEnabled
+	}
+	if p.MaxRetryAttempts != nil {
+		config.MaxRetryAttempts = *p.MaxRetryAttempts
+	}
+	if p.RetryIntervalMs != nil {
+		config.RetryIntervalMs = *p.RetryIntervalMs
+	}
+	if p.DefaultTagScanRate != nil {
+		config.DefaultTagScanRate = *p.DefaultTagScanRate
+	}
+	if p.MaxTagsPerPLC != nil {
+		config.MaxTagsPerPLC = *p.MaxTagsPerPLC
+	}
+	if p.ConsecutiveErrorThreshold != nil {
+		config.ConsecutiveErrorThreshold = *p.ConsecutiveErrorThreshold
+	}
+	if p.SyncIntervalSec != nil {
+		config.SyncIntervalSec = *p.SyncIntervalSec
+	}
+}
+
+// IsEmpty indica se o patch não altera nenhum campo
+func (p TagPatch) IsEmpty() bool {
+	return p.ScanRate == nil && p.Active == nil && p.MonitorChanges == nil && p.CanWrite == nil
+}
+
+// Apply aplica o patch a uma tag
+func (p TagPatch) Apply(tag *PLCTag) {
+	if p.ScanRate != nil {
+		tag.ScanRate = *p.ScanRate
+	}
+	if p.Active != nil {
+		tag.Active = *p.Active
+	}
+	if p.MonitorChanges != nil {
+		tag.MonitorChanges = *p.MonitorChanges
+	}
+	if p.CanWrite != nil {
+		tag.CanWrite = *p.CanWrite
+	}
+}
+
+// Matches indica se a tag atende ao filtro
+func (f TagFilter) Matches(tag PLCTag) bool {
+	if f.DataType != "" && tag.DataType != f.DataType {
+		return false
+	}
+	if f.Active != nil && tag.Active != *f.Active {
+		return false
+	}
+	return true
+}
+
+// immutableTagFields não podem ser alterados por atualização em massa, pois
+// mudam o endereço ou a identidade da tag
+var immutableTagFields = map[string]bool{
+	"id": true, "plc_id": true, "name": true, "db_number": true,
+	"byte_offset": true, "bit_offset": true, "data_type": true,
+}
+
+// ParseTagPatch converte o objeto "update" de uma atualização em massa,
+// aceitando apenas scan_rate, active, monitor_changes e can_write
+func ParseTagPatch(raw map[string]json.RawMessage) (TagPatch, error) {
+	for field := range raw {
+		if immutableTagFields[field] {
+			return TagPatch{}, fmt.Errorf("%w: %s", ErrImmutableTagField, field)
+		}
+		switch field {
+		case "scan_rate", "active", "monitor_changes", "can_write":
+		default:
+			return TagPatch{}, fmt.Errorf("%w: %s", ErrUnsupportedBulkField, field)
+		}
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return TagPatch{}, err
+	}
+
+	var patch TagPatch
+	if err := json.Unmarshal(data, &patch); err != nil {
+		return TagPatch{}, fmt.Errorf("%w: %w", ErrInvalidTagPatch, err)
+	}
+
+	if patch.IsEmpty() {
+		return TagPatch{}, ErrEmptyTagPatch
+	}
+	if patch.ScanRate != nil && *patch.ScanRate <= 0 {
+		return TagPatch{}, fmt.Errorf("%w: scan_rate deve ser maior que zero", ErrInvalidTagPatch)
+	}
+
+	return patch, nil
+}
+
 // PLCTagRepository define operações com tags de PLCs no banco de dados
 type PLCTagRepository interface {
 	GetByID(id int) (PLCTag, error)
@@ -96,6 +711,97 @@ type PLCTagRepository interface {
 	Create(tag PLCTag) (int, error)
 	Update(tag PLCTag) error
 	Delete(id int) error
+	DeleteMany(ids []int) ([]int, error)
+	BulkUpdate(plcID int, filter TagFilter, patch TagPatch) (int, error)
+	Search(ctx context.Context, filter TagSearchFilter) ([]PLCTag, int, error)
+	UpdateLastWrite(tagID, userID int, t time.Time) error
+	CountByPLC(plcID int) (int, error)
+	// BulkCreate insere as tags de uma vez, falhando com ErrTagLimitExceeded
+	// se o total ultrapassar maxTags (0 = sem limite)
+	BulkCreate(plcID int, tags []PLCTag, maxTags int) ([]int, error)
+	// GetIdleTags retorna as tags ativas sem registro no histórico há mais de since
+	GetIdleTags(since time.Duration) ([]PLCTag, error)
+	// UpdateScanRates altera o scan_rate das tags (id -> taxa) em uma única transação
+	UpdateScanRates(rates map[int]int) error
+	// DeleteByPLCID exclui todas as tags do PLC e retorna quantas foram excluídas
+	DeleteByPLCID(plcID int) (int, error)
+	// SetAutoDisabled tira a tag da varredura registrando o erro de leitura que causou a desativação
+	SetAutoDisabled(tagID int, lastError string, at time.Time) error
+	// ClearAutoDisabled devolve à varredura uma tag desativada automaticamente
+	ClearAutoDisabled(tagID int) error
+	// GetAutoDisabled lista as tags desativadas automaticamente
+	GetAutoDisabled() ([]PLCTag, error)
+}
+
+// MaxSuggestedScanRate é a maior taxa de leitura sugerida para tags ociosas (ms)
+const MaxSuggestedScanRate = 60000
+
+// IdleTag é uma tag sem mudança de valor no período consultado, com a taxa
+// de leitura sugerida para reduzir o custo de leitura
+type IdleTag struct {
+	Tag               PLCTag `json:"tag"`
+	SuggestedScanRate int    `json:"suggested_scan_rate"`
+}
+
+// SuggestScanRate sugere 10x a taxa atual da tag, limitada a MaxSuggestedScanRate
+func SuggestScanRate(scanRate int) int {
+	if scanRate <= 0 || scanRate*10 > MaxSuggestedScanRate {
+		return MaxSuggestedScanRate
+	}
+	return scanRate * 10
+}
+
+// TagLastWrite registra a última escrita bem-sucedida em uma tag
+type TagLastWrite struct {
+	TagID  int       `json:"tag_id"`
+	UserID int       `json:"user_id"` // 0 = escrita do sistema (ex.: fila assíncrona sem usuário)
+	At     time.Time `json:"at"`
+}
+
+// PLCTagHistoryRepository define operações com o histórico de valores das tags
+type PLCTagHistoryRepository interface {
+	Insert(entries []TagHistoryEntry) error
+	GetRange(plcID, tagID int, from, to time.Time) ([]TagHistoryEntry, error)
+	// GetLatestPairs retorna, por tag, os dois registros mais recentes no
+	// intervalo em ordem cronológica, com uma única consulta
+	GetLatestPairs(plcID int, tagIDs []int, from, to time.Time) (map[int][]TagHistoryEntry, error)
+	StreamRange(plcIDs []int, tagID int, from, to time.Time, batchSize int, fn func([]TagHistoryEntry) error) error
+	// CountRange conta os registros da tag no intervalo, parando em limit
+	CountRange(tagID int, from, to time.Time, limit int) (int, error)
+	GetInterpolated(ctx context.Context, plcID, tagID int, from, to time.Time, window time.Duration, mode string) ([]TagValue, error)
+	// GetChangeStats conta, por tag do PLC, as leituras e as mudanças de valor no intervalo
+	GetChangeStats(plcID int, from, to time.Time) ([]TagChangeStats, error)
+	// Prune remove o histórico anterior a olderThan (zero = sem limite global) e o
+	// das tags com retenção própria fora do seu prazo
+	Prune(olderThan time.Time) (int64, error)
+	// GetStats resume o tamanho do histórico
+	GetStats() (TagHistoryStats, error)
+}
+
+// TagHistoryStats resume o tamanho da tabela de histórico e a política de
+// retenção em vigor
+type TagHistoryStats struct {
+	TotalRows       int64      `json:"total_rows"`
+	OldestEntry     *time.Time `json:"oldest_entry"`
+	EstimatedSizeMB float64    `json:"estimated_size_mb"`
+	RetentionDays   int        `json:"retention_days"` // 0 = sem limite
+	NextPrune       *time.Time `json:"next_prune"`     // nil = limpeza não agendada
+}
+
+// TagChangeStats resume o histórico de uma tag em um intervalo
+type TagChangeStats struct {
+	TagID   int
+	Samples int // Leituras registradas
+	Changes int // Leituras com valor diferente da anterior
+}
+
+// ScanRateSuggestion é a taxa de leitura sugerida para uma tag a partir da
+// frequência de mudança do seu valor
+type ScanRateSuggestion struct {
+	TagID         int    `json:"tag_id"`
+	CurrentRate   int    `json:"current_rate"`
+	SuggestedRate int    `json:"suggested_rate"`
+	Reason        string `json:"reason"`
 }
 
 // PLCService define as operações disponíveis para PLCs
@@ -103,6 +809,7 @@ type PLCService interface {
 	GetByID(id int) (PLC, error)
 	GetAll() ([]PLC, error)
 	GetActivePLCs() ([]PLC, error)
+	ListPLCs(ctx context.Context, filter PLCFilter, page, pageSize int, bypassCache bool, userID int) ([]PLC, int, error)
 	Create(plc PLC) (int, error)
 	Update(plc PLC) error
 	Delete(id int) error
@@ -110,15 +817,82 @@ type PLCService interface {
 	GetPLCTags(plcID int) ([]PLCTag, error)
 	GetTagByID(id int) (PLCTag, error)
 	GetTagByName(name string) ([]PLCTag, error)
-	CreateTag(tag PLCTag) (int, error)
-	UpdateTag(tag PLCTag) error
+	SearchTags(ctx context.Context, filter TagSearchFilter) ([]PLCTag, int, error)
+	CreateTag(tag PLCTag, force bool) (int, error)
+	BulkCreateTags(plcID int, tags []PLCTag) ([]int, error)
+	GetTagLimitStatus(plc PLC) (int, int, error)
+	SetPLCTagLimit(plcID int, limit *int) (PLC, error)
+	UpdateTag(tag PLCTag, force bool) error
+	DetectAddressConflicts(plcID int) ([]AddressConflict, error)
+	MigrateTagType(tagID int, newDataType string, userID int) (TagTypeMigration, error)
+	ImportWonderwareTags(plcID int, r io.Reader) (TagImportResult, error)
 	DeleteTag(id int) error
+	PrepareBulkTagDelete(plcID int, tagIDs []int) (BulkTagDeletePreview, error)
+	ConfirmBulkTagDelete(plcID int, tagIDs []int, confirmToken string, force bool) (BulkTagDeleteResult, error)
+	BulkUpdateTags(plcID int, filter TagFilter, patch TagPatch) (int, error)
+	GetIdleTags(since time.Duration) ([]IdleTag, error)
+	ApplyIdleTagSuggestions(since time.Duration, userID int) ([]IdleTag, error)
+	SuggestScanRates(plcID int) ([]ScanRateSuggestion, error)
+	GetHistoryStats() (TagHistoryStats, error)
+	AdaptScanRates(plcID, userID int) ([]ScanRateSuggestion, error)
+
+	GetSites() ([]PLCSite, error)
+	GetSite(id int) (PLCSite, error)
+	CreateSite(site PLCSite) (int, error)
+	UpdateSite(site PLCSite) error
+	DeleteSite(id int) error
+	GetPLCsBySite(siteID int) ([]PLC, error)
+	GetSiteHealth(siteID int) (SiteHealth, error)
+
+	GetScanGroups(plcID int) ([]PLCScanGroup, error)
+	CreateScanGroup(group PLCScanGroup, userID int) (int, error)
+	AssignScanGroupTags(groupID int, tagIDs []int, userID int) (PLCScanGroup, error)
+	DeleteScanGroup(id, userID int) error
+
+	CreateTagAnnotation(annotation TagAnnotation) (int, error)
+	GetTagAnnotations(tagID int) ([]TagAnnotation, error)
+	CountTagAnnotations(tagID int) (int, error)
+	DeleteTagAnnotation(tagID, annotationID, userID int) error
+
+	CreateTagDependency(dependency TagDependency, userID int) (int, error)
+	GetTagDependencies(tagID int) ([]TagDependency, error)
+	DeleteTagDependency(tagID, dependencyID, userID int) error
+	WriteTagWithDependencies(ctx context.Context, tagName string, value interface{}, dependencyValues map[string]interface{}, userID int) (TagWriteReceipt, error)
+	WriteSequence(steps []WriteStep, rollback bool, userID int) (SequenceResult, error)
+	GetTagNameConvention() TagNameConvention
+
+	BrowseDataBlock(plcID, dbNumber, offset, length int) (DBBrowseResult, error)
+	PokeDataBlock(plcID, dbNumber, offset int, data []byte, userID int) error
 
 	StartMonitoring() error
 	StopMonitoring() error
-	WriteTagValue(tagName string, value interface{}) error
+	WriteTagValue(tagName string, value interface{}, userID int) error
+	PreviewTagWrite(tagName string, value interface{}) (*TagWritePreview, error)
+	QueueTagWrite(tagName string, value interface{}, userID int) (QueuedWrite, error)
+	GetWriteQueue(plcID int) ([]QueuedWrite, error)
+	CancelQueuedWrite(plcID int, requestID string) error
 	GetTagValue(plcID int, tagID int) (*TagValue, error)
 	GetPLCStats() PLCManagerStats
+	GetPLCPerformance(plcID int, reset bool) (PLCConnectionStats, error)
+	MigrateRedisKeys() (migrated int, total int, err error)
+	SetPLCMonitoring(plcID int, enabled bool) (PLC, error)
+	EffectiveMonitoringStatus(plc PLC) string
+	GetActiveAlarms(minSeverity string) ([]TagAlarmEvent, error)
+	AcknowledgeAlarm(eventID int64, userID int, comment string) (TagAlarmEvent, error)
+	GetTrackedGoroutines() int64
+	GetHistoryQueueStats() HistoryQueueStats
+	GetTagDerivative(plcID, tagID int, windowMs int) (float64, error)
+	GetPLCTagsWithDerivative(plcID int, windowMs int) ([]PLCTag, error)
+	ExportTagHistory(plcIDs []int, tagID int, from, to time.Time, fn func([]TagHistoryEntry) error) error
+	ExportTagHistoryCSV(tagID int, from, to time.Time, maxRows int, fn func([]TagHistoryEntry) error) error
+	SubscribeTagValues(ctx context.Context, plcIDs []int) (<-chan TagValue, error)
+	SubscribePLCEvents(ctx context.Context, plcID, userID int) (<-chan PLCEvent, error)
+	GetAutoDisabledTags() ([]PLCTag, error)
+	RecordTagAccess(entries []TagAccessLog)
+	GetTagAccessLog(filter TagAccessLogFilter) ([]TagAccessLog, error)
+	GetPLCMetadataCacheStats() PLCMetadataCacheStats
+	EnableTag(id, userID int) (PLCTag, error)
+	GetTagHistory(ctx context.Context, plcID, tagID int, from, to time.Time, window time.Duration, mode string) ([]TagValue, error)
 
 	// Métodos adicionados ou atualizados:
 	ResetPLCConnection(plcID int) error
@@ -126,7 +900,21 @@ type PLCService interface {
 	GetStatistics() map[string]interface{}
 	DiagnosticTags() (map[string]interface{}, error)
 	StartDebugMonitor()
+	StartDebugMonitorWithInterval(intervalSec int) error
+	StopDebugMonitor()
 	VerifyTagAddresses() error
+
+	// Sincronização PostgreSQL -> Redis
+	SyncNow() error
+	SetSyncInterval(interval time.Duration) error
+	GetSyncChangeSummary() (SyncChangeSummary, error)
+	ClearSyncChangeTracker() error
+	GetSyncErrorLog() ([]SyncError, error)
+	GetSyncLockStatus() ([]LockStatus, error)
+
+	// Configuração alterável em execução
+	GetRuntimeConfig() PLCRuntimeConfig
+	UpdateRuntimeConfig(patch PLCRuntimeConfigPatch, userID int) (PLCRuntimeConfig, error)
 }
 
 // PLCCache define operações para cache de valores de tags
@@ -136,11 +924,49 @@ type PLCCache interface {
 	BatchSetTagValues(values []TagValue) error
 	GetMultipleTagValues(queries []struct{ PLCID, TagID int }) ([]TagValue, error)
 	GetRedisClient() *redis.Client
+	SetTagLastWrite(tagID int, userID int, t time.Time) error
+	GetTagLastWrite(tagID int) (*TagLastWrite, error)
+	// DeletePLCValues remove os valores armazenados de todas as tags do PLC
+	DeletePLCValues(plcID int) error
 }
 
+// Erros de infraestrutura, verificáveis com errors.Is em qualquer camada
+var (
+	ErrMonitorNotStarted = errors.New("serviço de monitoramento não está ativo")
+	ErrCacheUnavailable  = errors.New("cache indisponível")
+)
+
+// Erros da configuração do protocolo S7
+var (
+	ErrInvalidProtocolVariant = errors.New("protocol_variant deve ser s7-300, s7-400, s7-1200 ou s7-1500")
+	ErrInvalidS71200Slot      = errors.New("o slot deve ser 0 para o S7-1200")
+	ErrUnsupportedLocalTSAP   = errors.New("apenas o TSAP local 0x0100 é suportado")
+	ErrInvalidRemoteTSAP      = errors.New("remote_tsap deve estar entre 1 e 0xFFFF")
+)
+
 // Erros comuns
 var (
-	ErrPLCNotFound     = errors.New("PLC não encontrado")
-	ErrPLCTagNotFound  = errors.New("tag de PLC não encontrada")
-	ErrInvalidDataType = errors.New("tipo de dados inválido")
+	ErrPLCNotFound          = errors.New("PLC não encontrado")
+	ErrAddressConflict      = errors.New("endereço da tag sobrepõe outra tag do PLC")
+	ErrTagLimitExceeded     = errors.New("limite de tags do PLC atingido")
+	ErrPLCTagNotFound       = errors.New("tag de PLC não encontrada")
+	ErrInvalidDataType      = errors.New("tipo de dados inválido")
+	ErrInvalidByteOffset    = errors.New("byte offset não pode ser negativo")
+	ErrInvalidBitOffset     = errors.New("bit offset deve estar entre 0 e 7 para tipo bool")
+	ErrBitOffsetNotAllowed  = errors.New("bit offset deve ser 0 para tipos não booleanos")
+	ErrUnpackBitsNotAllowed = errors.New("desempacotamento de bits só é permitido em tags word/uint16")
+	ErrInvalidBitLabel      = errors.New("rótulos de bit devem usar posições entre 0 e 15")
+	ErrInvalidMinDelta      = errors.New("variação mínima não pode ser negativa")
+	ErrMinDeltaNotAllowed   = errors.New("variação mínima só é permitida em tipos numéricos")
+	ErrInvalidScaleFactor   = errors.New("fator de escala não pode ser zero")
+	ErrScalingNotAllowed    = errors.New("escala só é permitida em tipos numéricos")
+	ErrInvalidRetentionDays = errors.New("dias de retenção do histórico não podem ser negativos")
+	ErrInvalidScaledValue   = errors.New("valor inválido para tag com escala")
+	ErrNotEnoughHistory     = errors.New("histórico insuficiente para o cálculo")
+	ErrNonNumericValue      = errors.New("valor da tag não é numérico")
+	ErrHistoryPruneLocked   = errors.New("limpeza do histórico em andamento em outra instância")
+	ErrImmutableTagField    = errors.New("campo não pode ser alterado em massa")
+	ErrUnsupportedBulkField = errors.New("campo não suportado na atualização em massa")
+	ErrInvalidTagPatch      = errors.New("atualização em massa inválida")
+	ErrEmptyTagPatch        = errors.New("nenhum campo informado para atualização")
 )
@@ -0,0 +1,27 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPLCTagIsScanned(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name string
+		tag  PLCTag
+		want bool
+	}{
+		{"ativa", PLCTag{Active: true}, true},
+		{"desativada pelo operador", PLCTag{Active: false}, false},
+		{"desativada automaticamente", PLCTag{Active: true, AutoDisabledAt: &now}, false},
+		{"desativada pelos dois", PLCTag{Active: false, AutoDisabledAt: &now}, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.tag.IsScanned(); got != tt.want {
+			t.Errorf("%s: IsScanned() = %v, esperado %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package domain
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// MaxDBBrowseLength limita os bytes lidos ou gravados por chamada na
+// navegação de DBs
+const MaxDBBrowseLength = 256
+
+// DefaultDBBrowseLength é o tamanho lido quando length não é informado
+const DefaultDBBrowseLength = 100
+
+// DBWordPreview interpreta uma palavra (2 bytes) lida de um DB. No último
+// byte de uma faixa ímpar, Int16 e Uint16 são omitidos.
+type DBWordPreview struct {
+	ByteOffset int     `json:"byte_offset"`
+	RawHex     string  `json:"raw_hex"`
+	Int16      *int16  `json:"int16,omitempty"`
+	Uint16     *uint16 `json:"uint16,omitempty"`
+	BoolBits   string  `json:"bool_bits"` // bits do bit mais significativo ao menos, byte a byte
+}
+
+// DBBrowseResult é uma faixa de bytes lida de um DB, em hexadecimal e
+// interpretada palavra a palavra
+type DBBrowseResult struct {
+	PLCID    int             `json:"plc_id"`
+	DBNumber int             `json:"db_number"`
+	Offset   int             `json:"offset"`
+	Length   int             `json:"length"`
+	HexDump  string          `json:"hex_dump"`
+	Preview  []DBWordPreview `json:"preview"`
+}
+
+// Erros da navegação de DBs
+var (
+	ErrInvalidDBRange = errors.New("faixa do DB inválida")
+	ErrInvalidDBBytes = errors.New("bytes devem estar em hexadecimal, ex.: 0x0A0B")
+)
+
+// ValidateDBRange verifica o número do DB e a faixa de bytes acessada
+func ValidateDBRange(dbNumber, offset, length int) error {
+	if dbNumber <= 0 {
+		return fmt.Errorf("%w: número do DB deve ser maior que zero", ErrInvalidDBRange)
+	}
+	if offset < 0 {
+		return fmt.Errorf("%w: offset não pode ser negativo", ErrInvalidDBRange)
+	}
+	if length <= 0 || length > MaxDBBrowseLength {
+		return fmt.Errorf("%w: length deve estar entre 1 e %d", ErrInvalidDBRange, MaxDBBrowseLength)
+	}
+	return nil
+}
+
+// ParseDBBytes converte "0x0A0B" (ou "0A0B") nos bytes a gravar
+func ParseDBBytes(s string) ([]byte, error) {
+	s = strings.TrimSpace(s)
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "0x"), "0X")
+	if s == "" {
+		return nil, ErrInvalidDBBytes
+	}
+
+	data, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidDBBytes, err)
+	}
+	return data, nil
+}
+
+// NewDBBrowseResult monta o resultado a partir dos bytes lidos em offset
+func NewDBBrowseResult(plcID, dbNumber, offset int, data []byte) DBBrowseResult {
+	dump := make([]string, len(data))
+	for i, b := range data {
+		dump[i] = fmt.Sprintf("%02X", b)
+	}
+
+	preview := make([]DBWordPreview, 0, (len(data)+1)/2)
+	for i := 0; i < len(data); i += 2 {
+		if i+1 >= len(data) {
+			preview = append(preview, DBWordPreview{
+				ByteOffset: offset + i,
+				RawHex:     fmt.Sprintf("0x%02X", data[i]),
+				BoolBits:   fmt.Sprintf("%08b", data[i]),
+			})
+			break
+		}
+
+		// S7 grava as palavras em big-endian
+		u := uint16(data[i])<<8 | uint16(data[i+1])
+		s := int16(u)
+		preview = append(preview, DBWordPreview{
+			ByteOffset: offset + i,
+			RawHex:     fmt.Sprintf("0x%04X", u),
+			Int16:      &s,
+			Uint16:     &u,
+			BoolBits:   fmt.Sprintf("%016b", u),
+		})
+	}
+
+	return DBBrowseResult{
+		PLCID:    plcID,
+		DBNumber: dbNumber,
+		Offset:   offset,
+		Length:   len(data),
+		HexDump:  strings.Join(dump, " "),
+		Preview:  preview,
+	}
+}
@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// PLCScanGroup reúne tags de um PLC que precisam ser lidas juntas, em uma
+// única requisição por DB, para que seus valores sejam consistentes entre si
+type PLCScanGroup struct {
+	ID          int       `json:"id"`
+	PLCID       int       `json:"plc_id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	TagIDs      []int     `json:"tag_ids"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at,omitempty"`
+}
+
+// Validate verifica o nome do grupo
+func (g PLCScanGroup) Validate() error {
+	if strings.TrimSpace(g.Name) == "" {
+		return ErrInvalidScanGroupName
+	}
+	return nil
+}
+
+// PLCScanGroupRepository define operações com grupos de varredura no banco de dados
+type PLCScanGroupRepository interface {
+	GetByPLC(plcID int) ([]PLCScanGroup, error)
+	GetByID(id int) (PLCScanGroup, error)
+	Create(group PLCScanGroup) (int, error)
+	// AssignTags substitui as tags do grupo; tags que saem ficam sem grupo
+	AssignTags(groupID int, tagIDs []int) error
+	// Delete remove o grupo; suas tags voltam à leitura individual
+	Delete(id int) error
+}
+
+// Erros de grupos de varredura
+var (
+	ErrScanGroupNotFound    = errors.New("grupo de varredura não encontrado")
+	ErrInvalidScanGroupName = errors.New("nome do grupo de varredura é obrigatório")
+	ErrScanGroupTagMismatch = errors.New("tags não pertencem ao PLC do grupo de varredura")
+	ErrScanGroupSpansDBs    = errors.New("tags de um grupo de varredura devem estar no mesmo DB")
+	ErrScanGroupExceedsPDU  = errors.New("tags de um grupo de varredura devem caber em uma única leitura (PDU)")
+
+	// ErrTagGroupNotFound é o nome genérico de ErrScanGroupNotFound: os
+	// grupos de tags do sistema são os grupos de varredura
+	ErrTagGroupNotFound = ErrScanGroupNotFound
+)
@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// PLCSite agrupa os PLCs de uma mesma planta ou localidade
+type PLCSite struct {
+	ID                int       `json:"id"`
+	Name              string    `json:"name"`
+	Location          string    `json:"location"`
+	Timezone          string    `json:"timezone"`           // Nome IANA (ex.: America/Sao_Paulo)
+	MonitoringEnabled bool      `json:"monitoring_enabled"` // false pausa todos os PLCs do site (manutenção)
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at,omitempty"`
+}
+
+// Validate verifica o nome e o fuso horário do site
+func (s PLCSite) Validate() error {
+	if strings.TrimSpace(s.Name) == "" {
+		return ErrInvalidSiteName
+	}
+	if s.Timezone != "" {
+		if _, err := time.LoadLocation(s.Timezone); err != nil {
+			return ErrInvalidSiteTimezone
+		}
+	}
+	return nil
+}
+
+// Estados agregados da saúde de um site
+const (
+	SiteHealthHealthy  = "healthy"  // Todos os PLCs monitorados estão online
+	SiteHealthDegraded = "degraded" // Parte dos PLCs está com falha
+	SiteHealthDown     = "down"     // Nenhum PLC monitorado está online
+	SiteHealthPaused   = "paused"   // Monitoramento do site pausado
+)
+
+// SiteHealth resume a saúde dos PLCs de um site
+type SiteHealth struct {
+	Site    PLCSite        `json:"site"`
+	Status  string         `json:"status"`
+	Total   int            `json:"total"`
+	Online  int            `json:"online"`
+	Offline int            `json:"offline"`
+	PLCs    map[int]string `json:"plcs"`
+}
+
+// PLCSiteStats resume os PLCs de um site nas estatísticas do gerenciador
+type PLCSiteStats struct {
+	SiteID    int    `json:"site_id"`
+	Name      string `json:"name"`
+	PLCs      int    `json:"plcs"`
+	Connected int    `json:"connected"`
+}
+
+// PLCSiteRepository define operações com sites de PLCs no banco de dados
+type PLCSiteRepository interface {
+	GetAll() ([]PLCSite, error)
+	GetByID(id int) (PLCSite, error)
+	Create(site PLCSite) (int, error)
+	Update(site PLCSite) error
+	// Delete remove o site; os PLCs do site ficam sem site (site_id = NULL)
+	Delete(id int) error
+}
+
+// Erros de sites de PLCs
+var (
+	ErrPLCSiteNotFound     = errors.New("site não encontrado")
+	ErrInvalidSiteName     = errors.New("nome do site é obrigatório")
+	ErrInvalidSiteTimezone = errors.New("fuso horário do site inválido")
+)
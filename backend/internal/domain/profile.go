@@ -2,22 +2,171 @@
 package domain
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"sort"
 	"time"
 )
 
 type Profile struct {
 	// Removido ID como campo separado
-	UserID                  int             `json:"user_id"`
-	AvatarURL               string          `json:"avatar_url"`
-	Bio                     string          `json:"bio"`
-	Department              string          `json:"department"`
-	NotificationPreferences map[string]bool `json:"notification_preferences"`
-	Theme                   string          `json:"theme"`
-	FontSize                string          `json:"font_size"`
-	Language                string          `json:"language"`
-	CreatedAt               time.Time       `json:"created_at"`
-	UpdatedAt               time.Time       `json:"updated_at"`
+	UserID                  int                  `json:"user_id"`
+	AvatarURL               string               `json:"avatar_url"`
+	Bio                     string               `json:"bio"`
+	Department              string               `json:"department"`
+	NotificationPreferences NotificationChannels `json:"notification_preferences"`
+	Theme                   string               `json:"theme"`
+	FontSize                string               `json:"font_size"`
+	Language                string               `json:"language"`
+	CreatedAt               time.Time            `json:"created_at"`
+	UpdatedAt               time.Time            `json:"updated_at"`
+}
+
+// NotificationChannel representa um canal de notificação do usuário.
+// Config guarda parâmetros específicos do canal (ex.: URL de webhook)
+type NotificationChannel struct {
+	Channel string            `json:"channel"`
+	Enabled bool              `json:"enabled"`
+	Config  map[string]string `json:"config,omitempty"`
+}
+
+// NotificationChannels aceita tanto o formato atual (lista de canais) quanto o
+// formato antigo {"email": true, "push": false} ao decodificar JSON
+type NotificationChannels []NotificationChannel
+
+func (n *NotificationChannels) UnmarshalJSON(data []byte) error {
+	var channels []NotificationChannel
+	if err := json.Unmarshal(data, &channels); err == nil {
+		*n = channels
+		return nil
+	}
+
+	var legacy map[string]bool
+	if err := json.Unmarshal(data, &legacy); err != nil {
+		return errors.New("notification_preferences deve ser uma lista de canais ou um objeto {canal: bool}")
+	}
+	if legacy == nil {
+		*n = nil
+		return nil
+	}
+
+	names := make([]string, 0, len(legacy))
+	for name := range legacy {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	channels = make([]NotificationChannel, 0, len(names))
+	for _, name := range names {
+		channels = append(channels, NotificationChannel{Channel: name, Enabled: legacy[name]})
+	}
+	*n = channels
+	return nil
+}
+
+// IsEnabled informa se o canal está presente e habilitado
+func (n NotificationChannels) IsEnabled(channel string) bool {
+	for _, c := range n {
+		if c.Channel == channel {
+			return c.Enabled
+		}
+	}
+	return false
+}
+
+// Merge aplica as atualizações canal a canal: canais presentes em updates
+// substituem os existentes e os demais são mantidos, na ordem original
+func (n NotificationChannels) Merge(updates NotificationChannels) NotificationChannels {
+	merged := make(NotificationChannels, 0, len(n)+len(updates))
+	pending := make(map[string]NotificationChannel, len(updates))
+	for _, u := range updates {
+		pending[u.Channel] = u
+	}
+
+	for _, c := range n {
+		if u, ok := pending[c.Channel]; ok {
+			merged = append(merged, u)
+			delete(pending, c.Channel)
+			continue
+		}
+		merged = append(merged, c)
+	}
+	for _, u := range updates {
+		if _, ok := pending[u.Channel]; ok {
+			merged = append(merged, u)
+		}
+	}
+	return merged
+}
+
+// DefaultNotificationChannels retorna as preferências usadas em perfis novos
+func DefaultNotificationChannels() NotificationChannels {
+	return NotificationChannels{
+		{Channel: "email", Enabled: true},
+		{Channel: "push", Enabled: true},
+		{Channel: "sms", Enabled: false},
+	}
+}
+
+// Tipos de widget aceitos no painel do usuário
+const (
+	DashboardWidgetTagValue  = "tag_value"
+	DashboardWidgetTagChart  = "tag_chart"
+	DashboardWidgetPLCStatus = "plc_status"
+	DashboardWidgetAlarmList = "alarm_list"
+)
+
+// MaxDashboardWidgets é a quantidade máxima de widgets por painel
+const MaxDashboardWidgets = 50
+
+// DashboardWidgetTypes lista os tipos de widget aceitos
+var DashboardWidgetTypes = []string{
+	DashboardWidgetTagValue, DashboardWidgetTagChart, DashboardWidgetPLCStatus, DashboardWidgetAlarmList,
+}
+
+// DashboardPosition é a posição e o tamanho do widget na grade do painel
+type DashboardPosition struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+	W int `json:"w"`
+	H int `json:"h"`
+}
+
+// DashboardWidget é um widget do painel configurável do usuário. TagID é
+// obrigatório nos widgets de tag (tag_value e tag_chart)
+type DashboardWidget struct {
+	Type     string            `json:"type"`
+	TagID    int               `json:"tag_id,omitempty"`
+	Position DashboardPosition `json:"position"`
+}
+
+// RequiresTag indica se o tipo de widget exibe uma tag
+func (w DashboardWidget) RequiresTag() bool {
+	return w.Type == DashboardWidgetTagValue || w.Type == DashboardWidgetTagChart
+}
+
+// Validate verifica o tipo, a tag e a posição do widget (a existência da tag
+// é verificada pelo serviço)
+func (w DashboardWidget) Validate() error {
+	known := false
+	for _, t := range DashboardWidgetTypes {
+		if w.Type == t {
+			known = true
+			break
+		}
+	}
+	if !known {
+		return fmt.Errorf("%w: tipo %q não permitido", ErrInvalidDashboardWidget, w.Type)
+	}
+	if w.RequiresTag() && w.TagID <= 0 {
+		return fmt.Errorf("%w: tag_id é obrigatório para %s", ErrInvalidDashboardWidget, w.Type)
+	}
+	if w.Position.X < 0 || w.Position.Y < 0 || w.Position.W <= 0 || w.Position.H <= 0 {
+		return fmt.Errorf("%w: posição inválida", ErrInvalidDashboardWidget)
+	}
+	return nil
 }
 
 type Theme struct {
@@ -36,6 +185,12 @@ type ProfileRepository interface {
 	GetByUserID(userID int) (Profile, error)
 	Update(profile Profile) error
 	Delete(id int) error
+	// GetDashboardConfig retorna os widgets do painel do usuário (lista vazia
+	// se o perfil não existir)
+	GetDashboardConfig(userID int) ([]DashboardWidget, error)
+	// UpdateDashboardConfig substitui os widgets do painel do usuário,
+	// criando o perfil se necessário
+	UpdateDashboardConfig(userID int, widgets []DashboardWidget) error
 }
 
 type ThemeRepository interface {
@@ -50,6 +205,16 @@ type ProfileService interface {
 	GetByUserID(userID int) (Profile, error)
 	Update(profile Profile) error
 	Delete(id int) error
+	CompletenessScore(profile Profile, user User) (score int, missing []string)
+	AllowedNotificationChannels() []string
+	ValidateNotificationChannels(channels NotificationChannels) error
+	GetNotificationChannels(userID int) (NotificationChannels, error)
+	UpdateNotificationChannels(userID int, channels NotificationChannels) (NotificationChannels, error)
+	UpdateTheme(userID int, theme string, changedBy int) error
+	SubscribeThemeChanges(ctx context.Context, userID int) (<-chan string, error)
+	GetDashboard(userID int) ([]DashboardWidget, error)
+	UpdateDashboard(userID int, widgets []DashboardWidget) ([]DashboardWidget, error)
+	ResetDashboard(userID int) error
 }
 
 type ThemeService interface {
@@ -63,4 +228,10 @@ type ThemeService interface {
 var (
 	ErrProfileNotFound = errors.New("perfil não encontrado")
 	ErrThemeNotFound   = errors.New("tema não encontrado")
+
+	ErrInvalidNotificationChannel   = errors.New("canal de notificação não permitido")
+	ErrDuplicateNotificationChannel = errors.New("canal de notificação duplicado")
+
+	ErrInvalidDashboardWidget = errors.New("widget do painel inválido")
+	ErrDashboardTooLarge      = errors.New("painel excede o limite de widgets")
 )
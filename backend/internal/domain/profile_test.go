@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNotificationChannelsMerge(t *testing.T) {
+	current := NotificationChannels{
+		{Channel: "email", Enabled: true},
+		{Channel: "teams", Enabled: true, Config: map[string]string{"webhook": "https://example.com/hook"}},
+		{Channel: "sms", Enabled: false},
+	}
+	updates := NotificationChannels{
+		{Channel: "sms", Enabled: true},
+		{Channel: "email", Enabled: false},
+		{Channel: "push", Enabled: true},
+	}
+
+	got := current.Merge(updates)
+	want := NotificationChannels{
+		{Channel: "email", Enabled: false},
+		{Channel: "teams", Enabled: true, Config: map[string]string{"webhook": "https://example.com/hook"}},
+		{Channel: "sms", Enabled: true},
+		{Channel: "push", Enabled: true},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Merge = %+v, esperado %+v", got, want)
+	}
+
+	if current[0].Enabled != true {
+		t.Error("Merge não deveria alterar a lista original")
+	}
+}
+
+func TestNotificationChannelsUnmarshalLegacy(t *testing.T) {
+	var got NotificationChannels
+	if err := got.UnmarshalJSON([]byte(`{"sms": false, "email": true}`)); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	want := NotificationChannels{
+		{Channel: "email", Enabled: true},
+		{Channel: "sms", Enabled: false},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("UnmarshalJSON = %+v, esperado %+v", got, want)
+	}
+}
@@ -0,0 +1,236 @@
+// internal/domain/response.go
+package domain
+
+import "time"
+
+// Respostas da API HTTP. Os nomes JSON daqui são o contrato com os clientes;
+// as tags JSON das entidades continuam servindo ao cache Redis e ao binding
+// das requisições. Novos campos das entidades só aparecem na API quando
+// adicionados aqui.
+
+// PLCResponse é o PLC exposto pela API. O campo Active (coluna active) é
+// exposto como is_active.
+type PLCResponse struct {
+	ID                    int       `json:"id"`
+	Name                  string    `json:"name"`
+	IPAddress             string    `json:"ip_address"`
+	Rack                  int       `json:"rack"`
+	Slot                  int       `json:"slot"`
+	IsActive              bool      `json:"is_active"`
+	MonitoringEnabled     bool      `json:"monitoring_enabled"`
+	Status                string    `json:"status,omitempty"`
+	EffectiveStatus       string    `json:"effective_status,omitempty"`
+	OverrideTagLimit      *int      `json:"override_tag_limit,omitempty"`
+	SiteID                *int      `json:"site_id,omitempty"`
+	SiteMonitoringEnabled bool      `json:"site_monitoring_enabled"`
+	ProtocolVariant       string    `json:"protocol_variant,omitempty"`
+	LocalTSAP             *int      `json:"local_tsap,omitempty"`
+	RemoteTSAP            *int      `json:"remote_tsap,omitempty"`
+	ProtocolNotes         string    `json:"protocol_notes,omitempty"`
+	CreatedAt             time.Time `json:"created_at"`
+	UpdatedAt             time.Time `json:"updated_at,omitempty"`
+}
+
+// NewPLCResponse converte o PLC para a resposta da API
+func NewPLCResponse(p PLC) PLCResponse {
+	return PLCResponse{
+		ID:                    p.ID,
+		Name:                  p.Name,
+		IPAddress:             p.IPAddress,
+		Rack:                  p.Rack,
+		Slot:                  p.Slot,
+		IsActive:              p.Active,
+		MonitoringEnabled:     p.MonitoringEnabled,
+		Status:                p.Status,
+		EffectiveStatus:       p.EffectiveStatus,
+		OverrideTagLimit:      p.OverrideTagLimit,
+		SiteID:                p.SiteID,
+		SiteMonitoringEnabled: p.SiteMonitoringEnabled,
+		ProtocolVariant:       p.ProtocolVariant,
+		LocalTSAP:             p.LocalTSAP,
+		RemoteTSAP:            p.RemoteTSAP,
+		ProtocolNotes:         p.ProtocolNotes,
+		CreatedAt:             p.CreatedAt,
+		UpdatedAt:             p.UpdatedAt,
+	}
+}
+
+// NewPLCResponses converte uma lista de PLCs (nunca retorna nil)
+func NewPLCResponses(plcs []PLC) []PLCResponse {
+	responses := make([]PLCResponse, 0, len(plcs))
+	for _, p := range plcs {
+		responses = append(responses, NewPLCResponse(p))
+	}
+	return responses
+}
+
+// PLCTagResponse é a tag exposta pela API
+type PLCTagResponse struct {
+	ID               int             `json:"id"`
+	PLCID            int             `json:"plc_id"`
+	Name             string          `json:"name"`
+	Description      string          `json:"description"`
+	DBNumber         int             `json:"db_number"`
+	ByteOffset       int             `json:"byte_offset"`
+	BitOffset        int             `json:"bit_offset"`
+	DataType         string          `json:"data_type"`
+	ScanRate         int             `json:"scan_rate"`
+	MonitorChanges   bool            `json:"monitor_changes"`
+	CanWrite         bool            `json:"can_write"`
+	Active           bool            `json:"active"`
+	WriteRateLimitHz float64         `json:"write_rate_limit_hz"`
+	UnpackBits       bool            `json:"unpack_bits"`
+	BitLabels        map[int]string  `json:"bit_labels,omitempty"`
+	MinDelta         float64         `json:"min_delta"`
+	ScaleFactor      float64         `json:"scale_factor"`
+	ScaleOffset      float64         `json:"scale_offset"`
+	LastWrittenAt    *time.Time      `json:"last_written_at"`
+	LastWrittenBy    *int            `json:"last_written_by"`
+	AutoDisabledAt   *time.Time      `json:"auto_disabled_at,omitempty"`
+	LastReadError    string          `json:"last_read_error,omitempty"`
+	RetentionDays    int             `json:"retention_days"`
+	ScanGroupID      *int            `json:"scan_group_id,omitempty"`
+	CreatedAt        time.Time       `json:"created_at"`
+	UpdatedAt        time.Time       `json:"updated_at,omitempty"`
+	CurrentValue     *TagReading     `json:"current_value,omitempty"`
+	ChangeRate       *float64        `json:"change_rate,omitempty"`
+	AnnotationCount  *int            `json:"annotation_count,omitempty"`
+	Annotations      []TagAnnotation `json:"annotations,omitempty"`
+}
+
+// NewPLCTagResponse converte a tag para a resposta da API
+func NewPLCTagResponse(t PLCTag) PLCTagResponse {
+	return PLCTagResponse{
+		ID:               t.ID,
+		PLCID:            t.PLCID,
+		Name:             t.Name,
+		Description:      t.Description,
+		DBNumber:         t.DBNumber,
+		ByteOffset:       t.ByteOffset,
+		BitOffset:        t.BitOffset,
+		DataType:         t.DataType,
+		ScanRate:         t.ScanRate,
+		MonitorChanges:   t.MonitorChanges,
+		CanWrite:         t.CanWrite,
+		Active:           t.Active,
+		WriteRateLimitHz: t.WriteRateLimitHz,
+		UnpackBits:       t.UnpackBits,
+		BitLabels:        t.BitLabels,
+		MinDelta:         t.MinDelta,
+		ScaleFactor:      t.ScaleFactor,
+		ScaleOffset:      t.ScaleOffset,
+		LastWrittenAt:    t.LastWrittenAt,
+		LastWrittenBy:    t.LastWrittenBy,
+		AutoDisabledAt:   t.AutoDisabledAt,
+		LastReadError:    t.LastReadError,
+		RetentionDays:    t.RetentionDays,
+		ScanGroupID:      t.ScanGroupID,
+		CreatedAt:        t.CreatedAt,
+		UpdatedAt:        t.UpdatedAt,
+		CurrentValue:     t.CurrentValue,
+		ChangeRate:       t.ChangeRate,
+		AnnotationCount:  t.AnnotationCount,
+		Annotations:      t.Annotations,
+	}
+}
+
+// NewPLCTagResponses converte uma lista de tags (nunca retorna nil)
+func NewPLCTagResponses(tags []PLCTag) []PLCTagResponse {
+	responses := make([]PLCTagResponse, 0, len(tags))
+	for _, t := range tags {
+		responses = append(responses, NewPLCTagResponse(t))
+	}
+	return responses
+}
+
+// PLCStatsResponse são as estatísticas do gerenciador expostas pela API
+type PLCStatsResponse struct {
+	ActivePLCs      int                        `json:"active_plcs"`
+	TotalTags       int                        `json:"total_tags"`
+	TagsRead        int64                      `json:"tags_read"`
+	TagsWritten     int64                      `json:"tags_written"`
+	ReadErrors      int64                      `json:"read_errors"`
+	WriteErrors     int64                      `json:"write_errors"`
+	LastUpdated     time.Time                  `json:"last_updated"`
+	ConnectionStats map[int]PLCConnectionStats `json:"connections"`
+	Sites           map[int]PLCSiteStats       `json:"sites,omitempty"`
+}
+
+// NewPLCStatsResponse converte as estatísticas para a resposta da API
+func NewPLCStatsResponse(s PLCManagerStats) PLCStatsResponse {
+	return PLCStatsResponse{
+		ActivePLCs:      s.ActivePLCs,
+		TotalTags:       s.TotalTags,
+		TagsRead:        s.TagsRead,
+		TagsWritten:     s.TagsWritten,
+		ReadErrors:      s.ReadErrors,
+		WriteErrors:     s.WriteErrors,
+		LastUpdated:     s.LastUpdated,
+		ConnectionStats: s.ConnectionStats,
+		Sites:           s.Sites,
+	}
+}
+
+// InitialTagValue é o valor atual de uma tag sem os metadados, enviado aos
+// clientes que acabaram de conectar
+type InitialTagValue struct {
+	TagID     int         `json:"tag_id"`
+	Value     interface{} `json:"value"`
+	Timestamp time.Time   `json:"timestamp"`
+}
+
+// NewInitialTagValues extrai os valores atuais das tags (tags sem valor no
+// cache ficam de fora; nunca retorna nil)
+func NewInitialTagValues(tags []PLCTag) []InitialTagValue {
+	values := make([]InitialTagValue, 0, len(tags))
+	for _, t := range tags {
+		if t.CurrentValue == nil {
+			continue
+		}
+		values = append(values, InitialTagValue{
+			TagID:     t.ID,
+			Value:     t.CurrentValue.Value,
+			Timestamp: t.CurrentValue.Timestamp,
+		})
+	}
+	return values
+}
+
+// UserResponse é o usuário exposto pela API, sem o hash da senha
+type UserResponse struct {
+	ID              int        `json:"id"`
+	Username        string     `json:"username"`
+	Email           string     `json:"email"`
+	Role            string     `json:"role"`
+	IsActive        bool       `json:"is_active"`
+	FullName        string     `json:"full_name"`
+	Phone           string     `json:"phone"`
+	LastLogin       string     `json:"last_login"`
+	AvatarURL       string     `json:"avatar_url"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty"`
+}
+
+// NewUserResponse converte o usuário para a resposta da API
+func NewUserResponse(u User) UserResponse {
+	return UserResponse{
+		ID:              u.ID,
+		Username:        u.Username,
+		Email:           u.Email,
+		Role:            u.Role,
+		IsActive:        u.IsActive,
+		FullName:        u.FullName,
+		Phone:           u.Phone,
+		LastLogin:       u.LastLogin,
+		AvatarURL:       u.AvatarURL,
+		EmailVerifiedAt: u.EmailVerifiedAt,
+	}
+}
+
+// NewUserResponses converte uma lista de usuários (nunca retorna nil)
+func NewUserResponses(users []User) []UserResponse {
+	responses := make([]UserResponse, 0, len(users))
+	for _, u := range users {
+		responses = append(responses, NewUserResponse(u))
+	}
+	return responses
+}
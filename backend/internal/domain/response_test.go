@@ -0,0 +1,72 @@
+package domain
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// jsonKeys retorna as chaves do objeto JSON gerado para v
+func jsonKeys(t *testing.T, v interface{}) map[string]json.RawMessage {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("erro ao serializar %T: %v", v, err)
+	}
+	var keys map[string]json.RawMessage
+	if err := json.Unmarshal(data, &keys); err != nil {
+		t.Fatalf("resposta %s não é um objeto: %v", data, err)
+	}
+	return keys
+}
+
+func TestUserResponseOmitsPassword(t *testing.T) {
+	user := User{ID: 1, Username: "maria", Email: "maria@empresa.com", Password: "$2a$10$hash", Role: "admin", IsActive: true}
+
+	keys := jsonKeys(t, NewUserResponse(user))
+	if _, ok := keys["password"]; ok {
+		t.Error("UserResponse não deveria expor o campo password")
+	}
+	for _, key := range []string{"id", "username", "email", "role", "is_active"} {
+		if _, ok := keys[key]; !ok {
+			t.Errorf("campo %q ausente em UserResponse", key)
+		}
+	}
+
+	data, _ := json.Marshal(NewUserResponses([]User{user}))
+	if strings.Contains(string(data), user.Password) {
+		t.Errorf("hash da senha vazou na lista de usuários: %s", data)
+	}
+}
+
+func TestPLCResponseExposesActiveAsIsActive(t *testing.T) {
+	keys := jsonKeys(t, NewPLCResponse(PLC{ID: 1, Name: "CLP1", Active: true}))
+
+	if string(keys["is_active"]) != "true" {
+		t.Errorf("is_active = %s, esperado true", keys["is_active"])
+	}
+	if _, ok := keys["active"]; ok {
+		t.Error("PLCResponse não deveria usar o nome da coluna (active)")
+	}
+}
+
+func TestResponseListsAreNeverNull(t *testing.T) {
+	tests := []struct {
+		name string
+		v    interface{}
+	}{
+		{"PLCs", NewPLCResponses(nil)},
+		{"tags", NewPLCTagResponses(nil)},
+		{"usuários", NewUserResponses(nil)},
+	}
+
+	for _, tt := range tests {
+		data, err := json.Marshal(tt.v)
+		if err != nil {
+			t.Fatalf("%s: erro ao serializar: %v", tt.name, err)
+		}
+		if string(data) != "[]" {
+			t.Errorf("%s: lista vazia = %s, esperado []", tt.name, data)
+		}
+	}
+}
@@ -1,10 +1,21 @@
 // internal/domain/role.go
 package domain
 
+import "errors"
+
+// MaxRoleDepth limita a cadeia de herança de roles percorrida na avaliação de permissões
+const MaxRoleDepth = 5
+
+var (
+	ErrRoleNotFound       = errors.New("role não encontrada")
+	ErrRoleHierarchyCycle = errors.New("a herança de roles forma um ciclo")
+)
+
 type Role struct {
-	ID          int    `json:"id"`
-	Name        string `json:"name"`
-	Description string `json:"description"`
+	ID           int    `json:"id"`
+	Name         string `json:"name"`
+	Description  string `json:"description"`
+	ParentRoleID *int   `json:"parent_role_id,omitempty"` // Role da qual as permissões são herdadas
 }
 
 type Permission struct {
@@ -18,6 +29,7 @@ type RoleRepository interface {
 	GetByID(id int) (Role, error)
 	GetByName(name string) (Role, error)
 	GetPermissions(roleID int) ([]Permission, error)
+	GetEffectivePermissions(roleID int) ([]Permission, error)
 }
 
 type RoleService interface {
@@ -25,4 +37,5 @@ type RoleService interface {
 	GetByID(id int) (Role, error)
 	GetByName(name string) (Role, error)
 	GetPermissions(roleID int) ([]Permission, error)
+	GetEffectivePermissions(roleID int) ([]Permission, error)
 }
@@ -0,0 +1,33 @@
+// internal/domain/tagaccesslog.go
+package domain
+
+import "time"
+
+// TagAccessLog registra a leitura do valor de uma tag por um usuário
+// (auditoria de acesso a dados em instalações reguladas)
+type TagAccessLog struct {
+	ID         int64     `json:"id"`
+	UserID     int       `json:"user_id"`
+	TagID      int       `json:"tag_id"`
+	PLCID      int       `json:"plc_id"`
+	AccessedAt time.Time `json:"accessed_at"`
+	Endpoint   string    `json:"endpoint"`
+	IPAddress  string    `json:"ip_address"`
+}
+
+// TagAccessLogFilter filtra a consulta do log de acesso (zero = sem filtro)
+type TagAccessLogFilter struct {
+	UserID int
+	TagID  int
+	From   time.Time
+	To     time.Time
+	Limit  int
+}
+
+// TagAccessLogRepository persiste o log de acesso às tags
+type TagAccessLogRepository interface {
+	// Insert grava um lote de acessos
+	Insert(entries []TagAccessLog) error
+	// List retorna os acessos mais recentes que atendem ao filtro
+	List(filter TagAccessLogFilter) ([]TagAccessLog, error)
+}
@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// MaxTagAnnotationLength é o tamanho máximo, em caracteres, de uma anotação
+const MaxTagAnnotationLength = 1000
+
+// TagAnnotation é uma nota de operador associada a uma tag, como o motivo de
+// uma alteração ("scan rate aumentado para análise de defeito")
+type TagAnnotation struct {
+	ID        int       `json:"id"`
+	TagID     int       `json:"tag_id"`
+	UserID    int       `json:"user_id"` // 0 = autor excluído
+	Text      string    `json:"text"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Validate verifica o texto da anotação
+func (a TagAnnotation) Validate() error {
+	if strings.TrimSpace(a.Text) == "" {
+		return ErrInvalidTagAnnotation
+	}
+	if utf8.RuneCountInString(a.Text) > MaxTagAnnotationLength {
+		return ErrTagAnnotationTooLong
+	}
+	return nil
+}
+
+// TagAnnotationRepository define operações com anotações de tags no banco de dados
+type TagAnnotationRepository interface {
+	Create(annotation TagAnnotation) (int, error)
+	GetByID(id int) (TagAnnotation, error)
+	// GetByTagID retorna as anotações da tag, da mais recente para a mais antiga
+	GetByTagID(tagID int) ([]TagAnnotation, error)
+	CountByTagID(tagID int) (int, error)
+	Delete(id int) error
+}
+
+// Erros de anotações de tags
+var (
+	ErrTagAnnotationNotFound  = errors.New("anotação não encontrada")
+	ErrInvalidTagAnnotation   = errors.New("texto da anotação é obrigatório")
+	ErrTagAnnotationTooLong   = errors.New("texto da anotação excede 1000 caracteres")
+	ErrTagAnnotationForbidden = errors.New("apenas o autor ou um administrador pode excluir a anotação")
+)
@@ -0,0 +1,91 @@
+package domain
+
+import "errors"
+
+// Tipos de dependência entre tags
+const (
+	// TagDependencyBefore: a tag dependente é escrita antes da tag
+	TagDependencyBefore = "before"
+	// TagDependencyAfter: a tag dependente é escrita depois da tag
+	TagDependencyAfter = "after"
+)
+
+// MaxTagDependencyDelayMs limita o atraso de uma dependência (1 minuto)
+const MaxTagDependencyDelayMs = 60000
+
+// TagDependency liga a escrita de uma tag à de outra. Por exemplo, a tag
+// "Executar" pode depender de "Setpoint" com tipo "before": o setpoint é
+// escrito primeiro e, após DelayMs, o bit de execução.
+type TagDependency struct {
+	ID             int    `json:"id"`
+	TagID          int    `json:"tag_id"`
+	DependsOnTagID int    `json:"depends_on_tag_id"`
+	DependencyType string `json:"dependency_type"` // before ou after
+	DelayMs        int    `json:"delay_ms"`        // atraso entre as duas escritas
+}
+
+// Validate verifica os campos da dependência
+func (d TagDependency) Validate() error {
+	if d.DependsOnTagID <= 0 {
+		return ErrInvalidTagDependency
+	}
+	if d.DependsOnTagID == d.TagID {
+		return ErrTagDependencyCycle
+	}
+	if d.DependencyType != TagDependencyBefore && d.DependencyType != TagDependencyAfter {
+		return ErrInvalidTagDependencyType
+	}
+	if d.DelayMs < 0 || d.DelayMs > MaxTagDependencyDelayMs {
+		return ErrInvalidTagDependencyDelay
+	}
+	return nil
+}
+
+// WriteOrder retorna as tags na ordem em que devem ser escritas
+func (d TagDependency) WriteOrder() (first, second int) {
+	if d.DependencyType == TagDependencyAfter {
+		return d.TagID, d.DependsOnTagID
+	}
+	return d.DependsOnTagID, d.TagID
+}
+
+// TagDependencyRepository define operações com dependências de tags no banco de dados
+type TagDependencyRepository interface {
+	Create(dependency TagDependency) (int, error)
+	GetByID(id int) (TagDependency, error)
+	// GetByTagID retorna as dependências declaradas pela tag
+	GetByTagID(tagID int) ([]TagDependency, error)
+	GetAll() ([]TagDependency, error)
+	Delete(id int) error
+}
+
+// TagWriteResult é o resultado da escrita de uma tag em uma sequência
+type TagWriteResult struct {
+	TagID   int         `json:"tag_id"`
+	PLCID   int         `json:"plc_id"`
+	TagName string      `json:"tag_name"`
+	Value   interface{} `json:"value"`
+	DelayMs int         `json:"delay_ms,omitempty"` // atraso aguardado antes da escrita
+	Success bool        `json:"success"`
+	Skipped bool        `json:"skipped,omitempty"` // não escrita por falha anterior
+	Error   string      `json:"error,omitempty"`
+}
+
+// TagWriteReceipt lista as escritas feitas ao seguir as dependências de uma tag
+type TagWriteReceipt struct {
+	TagID   int              `json:"tag_id"`
+	Success bool             `json:"success"`
+	Writes  []TagWriteResult `json:"writes"`
+}
+
+// Erros de dependências de tags
+var (
+	ErrTagDependencyNotFound     = errors.New("dependência de tag não encontrada")
+	ErrInvalidTagDependency      = errors.New("depends_on_tag_id é obrigatório")
+	ErrInvalidTagDependencyType  = errors.New("dependency_type deve ser before ou after")
+	ErrInvalidTagDependencyDelay = errors.New("delay_ms deve estar entre 0 e 60000")
+	ErrTagDependencyCycle        = errors.New("a dependência criaria um ciclo entre as tags")
+	ErrTagDependencyExists       = errors.New("dependência já cadastrada")
+	ErrTagDependencyValueMissing = errors.New("valor não informado para tag dependente")
+	ErrTagDependencyDelayTooLong = errors.New("atraso total das dependências excede o prazo da requisição")
+)
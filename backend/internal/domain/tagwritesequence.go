@@ -0,0 +1,40 @@
+package domain
+
+import "errors"
+
+// MaxWriteSequenceSteps limita os passos de uma sequência de escritas
+const MaxWriteSequenceSteps = 50
+
+// Situação do rollback de uma sequência de escritas
+const (
+	RollbackStatusNotNeeded    = "not_needed"    // nenhum passo falhou
+	RollbackStatusNotRequested = "not_requested" // falhou sem rollback_on_failure
+	RollbackStatusSuccess      = "success"       // todos os valores originais restaurados
+	RollbackStatusPartial      = "partial"       // parte dos valores originais restaurada
+	RollbackStatusFailed       = "failed"        // nenhum valor original restaurado
+)
+
+// WriteStep é um passo de uma sequência de escritas
+type WriteStep struct {
+	TagName string      `json:"tag_name"`
+	Value   interface{} `json:"value"`
+}
+
+// SequenceResult resume a execução de uma sequência de escritas. FailedStep
+// é o índice (a partir de 0) do passo que falhou.
+type SequenceResult struct {
+	Executed       int      `json:"executed"`
+	RolledBack     int      `json:"rolled_back"`
+	FailedStep     *int     `json:"failed_step,omitempty"`
+	Error          string   `json:"error,omitempty"`
+	RollbackStatus string   `json:"rollback_status"`
+	RollbackErrors []string `json:"rollback_errors,omitempty"`
+}
+
+// Erros de sequências de escritas
+var (
+	ErrWriteSequenceEmpty      = errors.New("a sequência deve ter ao menos um passo")
+	ErrWriteSequenceTooLong    = errors.New("a sequência excede o limite de passos")
+	ErrWriteSequenceInvalid    = errors.New("passo da sequência sem tag_name ou value")
+	ErrWriteSequenceNoOriginal = errors.New("valor original da tag indisponível para rollback")
+)
@@ -1,7 +1,10 @@
 // internal/domain/user.go
 package domain
 
-import "errors"
+import (
+	"errors"
+	"time"
+)
 
 type User struct {
 	ID        int    `json:"id"`
@@ -14,17 +17,25 @@ type User struct {
 	Phone     string `json:"phone"`
 	LastLogin string `json:"last_login"`
 	AvatarURL string `json:"avatar_url"` // Novo campo adicionado
+
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty"`
 }
 
 type UserRepository interface {
 	Create(user User) (int, error)
 	GetByID(id int) (User, error)
 	GetByEmail(email string) (User, error)
+	GetByUsername(username string) (User, error)
 	Update(user User) error
 	Delete(id int) error
 	List(page, pageSize int) ([]User, int, error)
 	HasPermission(userID int, permissionCode string) (bool, error)
 	UpdateLastLogin(userID int) error
+	SetEmailVerified(userID int, verifiedAt time.Time) error
+	UpdatePassword(userID int, passwordHash string) error
+	SoftDelete(userID int, deletedAt time.Time) error
+	RevokeSessions(userID int, at time.Time) error
+	GetSessionsRevokedAt(userID int) (time.Time, error)
 }
 
 type UserService interface {
@@ -35,12 +46,41 @@ type UserService interface {
 	Delete(id int) error
 	List(page, pageSize int) ([]User, int, error)
 	HasPermission(userID int, permissionCode string) (bool, error)
+	VerifyEmail(token string) error
+	ChangePassword(userID int, currentPassword, newPassword string) error
+	DeleteAccount(userID int, password string) error
+	UpdateAllowedEmailDomains(domains []string, userID int) ([]string, error)
+	RotateJWTSecret(userID int) (JWTSecretRotation, error)
+	ValidateToken(token string) (int, error)
+}
+
+// Mailer abstrai o envio de emails transacionais (verificação de email, avisos)
+type Mailer interface {
+	Send(to, subject, body string) error
 }
 
 // Erros comuns
 var (
-	ErrUserNotFound       = errors.New("usuário não encontrado")
-	ErrInvalidCredentials = errors.New("credenciais inválidas")
-	ErrEmailInUse         = errors.New("email já em uso")
-	ErrUsernameInUse      = errors.New("nome de usuário já em uso")
+	ErrUserNotFound          = errors.New("usuário não encontrado")
+	ErrInvalidCredentials    = errors.New("credenciais inválidas")
+	ErrEmailInUse            = errors.New("email já em uso")
+	ErrUsernameInUse         = errors.New("nome de usuário já em uso")
+	ErrInvalidVerifyToken    = errors.New("token de verificação inválido ou expirado")
+	ErrEmailDomainNotAllowed = errors.New("cadastro não permitido para este domínio de email")
 )
+
+// ErrSessionRevoked é retornado para tokens emitidos antes da revogação das
+// sessões do usuário
+var ErrSessionRevoked = errors.New("sessão revogada")
+
+// ErrJWTRotationNotConfigured é retornado quando a rotação do segredo JWT
+// não foi habilitada no serviço
+var ErrJWTRotationNotConfigured = errors.New("rotação do segredo JWT não configurada")
+
+// JWTSecretRotation resume uma rotação do segredo JWT. Os segredos nunca são
+// expostos pela API.
+type JWTSecretRotation struct {
+	RotatedAt          time.Time `json:"rotated_at"`
+	OldSecretExpiresAt time.Time `json:"old_secret_expires_at"`
+	GracePeriodHours   int       `json:"grace_period_hours"`
+}
@@ -2,8 +2,11 @@
 package health
 
 import (
+	"app_padrao/internal/service"
 	"context"
 	"database/sql"
+	"fmt"
+	"strings"
 	"sync"
 	"time"
 
@@ -30,8 +33,26 @@ type ComponentHealth struct {
 type HealthCheck struct {
 	mutex      sync.RWMutex
 	components map[string]ComponentHealth
+	latency    LatencyReport
+	keyPrefix  string // Namespace das chaves no Redis (REDIS_KEY_PREFIX)
 }
 
+// LatencyReport traz a última latência medida do PostgreSQL e do Redis e os
+// limites a partir dos quais cada um é considerado degradado
+type LatencyReport struct {
+	PostgresMs       float64 `json:"postgres_ms"`
+	RedisMs          float64 `json:"redis_ms"`
+	ThresholdMs      float64 `json:"threshold_ms"`
+	RedisThresholdMs float64 `json:"redis_threshold_ms"`
+}
+
+const (
+	// latencyCheckTimeout é a espera máxima das consultas de latência
+	latencyCheckTimeout = 5 * time.Second
+	// redisLatencyKey é a chave temporária gravada e lida na medição do Redis
+	redisLatencyKey = "plc:health:latency:check"
+)
+
 // NewHealthCheck cria um novo verificador de saúde
 func NewHealthCheck() *HealthCheck {
 	return &HealthCheck{
@@ -39,6 +60,13 @@ func NewHealthCheck() *HealthCheck {
 	}
 }
 
+// SetKeyPrefix define o namespace da chave usada na medição do Redis
+func (hc *HealthCheck) SetKeyPrefix(prefix string) {
+	hc.mutex.Lock()
+	defer hc.mutex.Unlock()
+	hc.keyPrefix = prefix
+}
+
 // CheckPostgres verifica a saúde da conexão PostgreSQL
 func (hc *HealthCheck) CheckPostgres(db *sql.DB) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -91,6 +119,167 @@ func (hc *HealthCheck) CheckRedis(client *redis.Client) {
 	}
 }
 
+// latencyStatus classifica a latência: acima do limite o componente está
+// degradado e acima de 5 vezes o limite, fora do ar
+func latencyStatus(latency, threshold time.Duration) Status {
+	switch {
+	case latency > 5*threshold:
+		return StatusUnhealthy
+	case latency > threshold:
+		return StatusDegraded
+	}
+	return StatusHealthy
+}
+
+// durationMs converte uma duração para milissegundos com fração
+func durationMs(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000.0
+}
+
+// CheckPostgresLatency mede o tempo de ida e volta de "SELECT 1" e registra o
+// resultado como "postgres_latency"
+func (hc *HealthCheck) CheckPostgresLatency(db *sql.DB, threshold time.Duration) ComponentHealth {
+	ctx, cancel := context.WithTimeout(context.Background(), latencyCheckTimeout)
+	defer cancel()
+
+	start := time.Now()
+	var one int
+	err := db.QueryRowContext(ctx, "SELECT 1").Scan(&one)
+	latency := time.Since(start)
+
+	health := ComponentHealth{
+		Status:      latencyStatus(latency, threshold),
+		Details:     fmt.Sprintf("%.1f ms (limite %.1f ms)", durationMs(latency), durationMs(threshold)),
+		LastChecked: time.Now(),
+	}
+	if err != nil {
+		health.Status = StatusUnhealthy
+		health.Details = err.Error()
+	}
+
+	hc.mutex.Lock()
+	defer hc.mutex.Unlock()
+
+	hc.components["postgres_latency"] = health
+	hc.latency.PostgresMs = durationMs(latency)
+	hc.latency.ThresholdMs = durationMs(threshold)
+
+	return health
+}
+
+// CheckRedisLatency mede o tempo de ida e volta de um SET seguido de GET e
+// registra o resultado como "redis_latency"
+func (hc *HealthCheck) CheckRedisLatency(client *redis.Client, threshold time.Duration) ComponentHealth {
+	ctx, cancel := context.WithTimeout(context.Background(), latencyCheckTimeout)
+	defer cancel()
+
+	hc.mutex.RLock()
+	key := hc.keyPrefix + redisLatencyKey
+	hc.mutex.RUnlock()
+
+	start := time.Now()
+	err := client.Set(ctx, key, 1, 10*time.Second).Err()
+	if err == nil {
+		err = client.Get(ctx, key).Err()
+	}
+	latency := time.Since(start)
+
+	health := ComponentHealth{
+		Status:      latencyStatus(latency, threshold),
+		Details:     fmt.Sprintf("%.1f ms (limite %.1f ms)", durationMs(latency), durationMs(threshold)),
+		LastChecked: time.Now(),
+	}
+	if err != nil {
+		health.Status = StatusUnhealthy
+		health.Details = err.Error()
+	}
+
+	hc.mutex.Lock()
+	defer hc.mutex.Unlock()
+
+	hc.components["redis_latency"] = health
+	hc.latency.RedisMs = durationMs(latency)
+	hc.latency.RedisThresholdMs = durationMs(threshold)
+
+	return health
+}
+
+// GetLatency retorna as últimas latências medidas
+func (hc *HealthCheck) GetLatency() LatencyReport {
+	hc.mutex.RLock()
+	defer hc.mutex.RUnlock()
+	return hc.latency
+}
+
+// plcComponentPrefix identifica os componentes que representam conexões com PLCs
+const plcComponentPrefix = "plc_"
+
+// CheckPLCConnections faz ping em cada conexão ativa do gerenciador e registra
+// o resultado como "plc_<id>". Conexões que deixaram de existir são removidas.
+func (hc *HealthCheck) CheckPLCConnections(manager *service.PLCManager) {
+	if manager == nil {
+		return
+	}
+
+	connections := manager.GetAllConnections()
+
+	results := make(map[string]ComponentHealth, len(connections))
+	var resultsMu sync.Mutex
+	var wg sync.WaitGroup
+
+	// Ping em paralelo: cada um pode levar alguns segundos se o PLC não responder
+	for plcID, conn := range connections {
+		wg.Add(1)
+		go func(plcID int, conn *service.PLCConnection) {
+			defer wg.Done()
+
+			health := ComponentHealth{
+				Status:      StatusHealthy,
+				Details:     "Connection successful",
+				LastChecked: time.Now(),
+			}
+			if err := conn.Ping(); err != nil {
+				health.Status = StatusUnhealthy
+				health.Details = err.Error()
+			}
+
+			resultsMu.Lock()
+			results[fmt.Sprintf("%s%d", plcComponentPrefix, plcID)] = health
+			resultsMu.Unlock()
+		}(plcID, conn)
+	}
+	wg.Wait()
+
+	hc.mutex.Lock()
+	defer hc.mutex.Unlock()
+
+	for name := range hc.components {
+		if strings.HasPrefix(name, plcComponentPrefix) {
+			if _, exists := results[name]; !exists {
+				delete(hc.components, name)
+			}
+		}
+	}
+	for name, health := range results {
+		hc.components[name] = health
+	}
+}
+
+// GetPLCHealth retorna apenas o status das conexões com PLCs
+func (hc *HealthCheck) GetPLCHealth() map[string]ComponentHealth {
+	hc.mutex.RLock()
+	defer hc.mutex.RUnlock()
+
+	result := make(map[string]ComponentHealth)
+	for k, v := range hc.components {
+		if strings.HasPrefix(k, plcComponentPrefix) {
+			result[k] = v
+		}
+	}
+
+	return result
+}
+
 // GetHealth retorna o status de saúde de todos os componentes
 func (hc *HealthCheck) GetHealth() map[string]ComponentHealth {
 	hc.mutex.RLock()
@@ -113,10 +302,11 @@ func (hc *HealthCheck) GetOverallStatus() Status {
 	hasUnhealthy := false
 	hasDegraded := false
 
-	for _, health := range hc.components {
-		if health.Status == StatusUnhealthy {
+	for name, health := range hc.components {
+		// Um PLC fora do ar degrada o sistema, mas não o derruba
+		if health.Status == StatusUnhealthy && !strings.HasPrefix(name, plcComponentPrefix) {
 			hasUnhealthy = true
-		} else if health.Status == StatusDegraded {
+		} else if health.Status != StatusHealthy {
 			hasDegraded = true
 		}
 	}
@@ -0,0 +1,27 @@
+package health
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+)
+
+func TestCheckRedisLatencyUsesKeyPrefix(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	hc := NewHealthCheck()
+	hc.SetKeyPrefix("app1:")
+
+	if health := hc.CheckRedisLatency(client, time.Second); health.Status == StatusUnhealthy {
+		t.Fatalf("status = %s (%s), esperado saudável", health.Status, health.Details)
+	}
+
+	keys := mr.Keys()
+	if len(keys) != 1 || keys[0] != "app1:plc:health:latency:check" {
+		t.Errorf("chaves = %v, esperado apenas app1:plc:health:latency:check", keys)
+	}
+}
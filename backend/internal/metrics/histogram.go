@@ -0,0 +1,184 @@
+// internal/metrics/histogram.go
+package metrics
+
+import (
+	"math"
+	"sort"
+	"strconv"
+)
+
+// DefaultHistogramMaxSamples é quantas amostras brutas um histograma sem
+// faixas guarda por padrão
+const DefaultHistogramMaxSamples = 1000
+
+// DefaultLatencyBucketsMs são limites de faixas (ms) adequados a latências
+// de rede e de banco
+var DefaultLatencyBucketsMs = []float64{0.5, 1, 2.5, 5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000}
+
+// HistogramStats resume um histograma. Em histogramas com faixas, os
+// quantis são estimados por interpolação linear dentro da faixa.
+type HistogramStats struct {
+	Count uint64  `json:"count"`
+	Sum   float64 `json:"sum"`
+	Min   float64 `json:"min"`
+	Max   float64 `json:"max"`
+	P50   float64 `json:"p50"`
+	P75   float64 `json:"p75"`
+	P95   float64 `json:"p95"`
+	P99   float64 `json:"p99"`
+	P999  float64 `json:"p99_9"`
+}
+
+// BucketCount é a contagem acumulada de uma faixa no formato Prometheus:
+// observações menores ou iguais a LE ("+Inf" na última)
+type BucketCount struct {
+	LE    string `json:"le"`
+	Count uint64 `json:"count"`
+}
+
+// bucketHistogram conta as observações em faixas fixas: memória constante,
+// independente do número de observações
+type bucketHistogram struct {
+	bounds []float64 // limites superiores, em ordem crescente
+	counts []uint64  // uma contagem por limite e a última para +Inf
+	count  uint64
+	sum    float64
+	min    float64
+	max    float64
+}
+
+// newBucketHistogram cria um histograma com os limites ordenados e sem
+// repetições
+func newBucketHistogram(buckets []float64) *bucketHistogram {
+	bounds := make([]float64, 0, len(buckets))
+	for _, b := range buckets {
+		if !math.IsNaN(b) && !math.IsInf(b, 0) {
+			bounds = append(bounds, b)
+		}
+	}
+	sort.Float64s(bounds)
+
+	unique := bounds[:0]
+	for i, b := range bounds {
+		if i == 0 || b != bounds[i-1] {
+			unique = append(unique, b)
+		}
+	}
+
+	return &bucketHistogram{
+		bounds: unique,
+		counts: make([]uint64, len(unique)+1),
+	}
+}
+
+// observe conta um valor na primeira faixa cujo limite o comporta
+func (h *bucketHistogram) observe(value float64) {
+	i := sort.SearchFloat64s(h.bounds, value)
+	h.counts[i]++
+
+	if h.count == 0 || value < h.min {
+		h.min = value
+	}
+	if h.count == 0 || value > h.max {
+		h.max = value
+	}
+	h.count++
+	h.sum += value
+}
+
+// quantile estima o quantil q (0 a 1) interpolando linearmente dentro da
+// faixa que contém a posição q*count. As pontas são limitadas ao mínimo e
+// ao máximo observados.
+func (h *bucketHistogram) quantile(q float64) float64 {
+	if h.count == 0 {
+		return 0
+	}
+
+	rank := q * float64(h.count)
+	var cumulative uint64
+	for i, n := range h.counts {
+		if n == 0 || float64(cumulative+n) < rank {
+			cumulative += n
+			continue
+		}
+
+		lower, upper := h.min, h.max
+		if i > 0 && h.bounds[i-1] > lower {
+			lower = h.bounds[i-1]
+		}
+		if i < len(h.bounds) && h.bounds[i] < upper {
+			upper = h.bounds[i]
+		}
+
+		fraction := (rank - float64(cumulative)) / float64(n)
+		return lower + (upper-lower)*fraction
+	}
+	return h.max
+}
+
+// stats resume o histograma
+func (h *bucketHistogram) stats() HistogramStats {
+	return HistogramStats{
+		Count: h.count,
+		Sum:   h.sum,
+		Min:   h.min,
+		Max:   h.max,
+		P50:   h.quantile(0.50),
+		P75:   h.quantile(0.75),
+		P95:   h.quantile(0.95),
+		P99:   h.quantile(0.99),
+		P999:  h.quantile(0.999),
+	}
+}
+
+// cumulativeCounts retorna as contagens acumuladas por faixa
+func (h *bucketHistogram) cumulativeCounts() []BucketCount {
+	result := make([]BucketCount, 0, len(h.counts))
+	var cumulative uint64
+	for i, n := range h.counts {
+		cumulative += n
+		le := "+Inf"
+		if i < len(h.bounds) {
+			le = strconv.FormatFloat(h.bounds[i], 'g', -1, 64)
+		}
+		result = append(result, BucketCount{LE: le, Count: cumulative})
+	}
+	return result
+}
+
+// sampleStats resume amostras brutas, com quantis pelo método do vizinho
+// mais próximo
+func sampleStats(values []float64) HistogramStats {
+	if len(values) == 0 {
+		return HistogramStats{}
+	}
+
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+
+	sum := 0.0
+	for _, v := range sorted {
+		sum += v
+	}
+
+	quantile := func(q float64) float64 {
+		i := int(math.Ceil(q*float64(len(sorted)))) - 1
+		if i < 0 {
+			i = 0
+		}
+		return sorted[i]
+	}
+
+	return HistogramStats{
+		Count: uint64(len(sorted)),
+		Sum:   sum,
+		Min:   sorted[0],
+		Max:   sorted[len(sorted)-1],
+		P50:   quantile(0.50),
+		P75:   quantile(0.75),
+		P95:   quantile(0.95),
+		P99:   quantile(0.99),
+		P999:  quantile(0.999),
+	}
+}
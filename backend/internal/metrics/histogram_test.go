@@ -0,0 +1,181 @@
+package metrics
+
+import (
+	"math"
+	"testing"
+)
+
+// almostEqual compara quantis interpolados com tolerância
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestBucketHistogramQuantile(t *testing.T) {
+	tests := []struct {
+		name    string
+		buckets []float64
+		values  map[float64]int // valor observado -> repetições
+		want    HistogramStats
+	}{
+		{
+			name: "histograma vazio",
+			// Sem observações, todos os quantis são zero
+			buckets: []float64{10, 20},
+			want:    HistogramStats{},
+		},
+		{
+			// 50 em ≤10, 40 em ≤20, 9 em ≤50 e 1 em ≤100
+			name:    "distribuição conhecida",
+			buckets: []float64{10, 20, 50, 100},
+			values:  map[float64]int{5: 50, 15: 40, 40: 9, 80: 1},
+			want: HistogramStats{
+				Count: 100, Sum: 5*50 + 15*40 + 40*9 + 80, Min: 5, Max: 80,
+				P50:  10,              // fim da primeira faixa
+				P75:  10 + 10*25.0/40, // 25 de 40 amostras na faixa 10–20
+				P95:  20 + 30*5.0/9,   // 5 de 9 amostras na faixa 20–50
+				P99:  50,              // fim da faixa 20–50
+				P999: 50 + 30*9.0/10,  // faixa 50–100 limitada ao máximo 80
+			},
+		},
+		{
+			name:    "todas as amostras na faixa +Inf",
+			buckets: []float64{1, 2},
+			values:  map[float64]int{10: 1, 20: 1, 30: 1},
+			want: HistogramStats{
+				Count: 3, Sum: 60, Min: 10, Max: 30,
+				// Sem limite superior, a faixa vai do mínimo ao máximo observados
+				P50:  20,
+				P75:  25,
+				P95:  10 + 20*0.95,
+				P99:  10 + 20*0.99,
+				P999: 10 + 20*0.999,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newBucketHistogram(tt.buckets)
+			for value, n := range tt.values {
+				for i := 0; i < n; i++ {
+					h.observe(value)
+				}
+			}
+
+			got := h.stats()
+			if got.Count != tt.want.Count || !almostEqual(got.Sum, tt.want.Sum) ||
+				got.Min != tt.want.Min || got.Max != tt.want.Max {
+				t.Errorf("count/sum/min/max = %d/%v/%v/%v, esperado %d/%v/%v/%v",
+					got.Count, got.Sum, got.Min, got.Max, tt.want.Count, tt.want.Sum, tt.want.Min, tt.want.Max)
+			}
+
+			quantiles := []struct {
+				name      string
+				got, want float64
+			}{
+				{"p50", got.P50, tt.want.P50},
+				{"p75", got.P75, tt.want.P75},
+				{"p95", got.P95, tt.want.P95},
+				{"p99", got.P99, tt.want.P99},
+				{"p99.9", got.P999, tt.want.P999},
+			}
+			for _, q := range quantiles {
+				if !almostEqual(q.got, q.want) {
+					t.Errorf("%s = %v, esperado %v", q.name, q.got, q.want)
+				}
+			}
+		})
+	}
+}
+
+func TestBucketHistogramCumulativeCounts(t *testing.T) {
+	h := newBucketHistogram([]float64{2, 1, 2, math.Inf(1)})
+	for _, v := range []float64{0.5, 1, 1.5, 10, 20} {
+		h.observe(v)
+	}
+
+	want := []BucketCount{{LE: "1", Count: 2}, {LE: "2", Count: 3}, {LE: "+Inf", Count: 5}}
+	got := h.cumulativeCounts()
+	if len(got) != len(want) {
+		t.Fatalf("faixas = %v, esperado %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("faixa %d = %+v, esperado %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestSampleStats(t *testing.T) {
+	oneToHundred := make([]float64, 100)
+	for i := range oneToHundred {
+		// Fora de ordem, para exercitar a ordenação
+		oneToHundred[i] = float64(100 - i)
+	}
+
+	tests := []struct {
+		name   string
+		values []float64
+		want   HistogramStats
+	}{
+		{"sem amostras", nil, HistogramStats{}},
+		{"uma amostra", []float64{7}, HistogramStats{Count: 1, Sum: 7, Min: 7, Max: 7, P50: 7, P75: 7, P95: 7, P99: 7, P999: 7}},
+		{"1 a 100", oneToHundred, HistogramStats{Count: 100, Sum: 5050, Min: 1, Max: 100, P50: 50, P75: 75, P95: 95, P99: 99, P999: 100}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sampleStats(tt.values); got != tt.want {
+				t.Errorf("sampleStats = %+v, esperado %+v", got, tt.want)
+			}
+		})
+	}
+
+	// O resumo não reordena as amostras do chamador
+	if oneToHundred[0] != 100 {
+		t.Error("sampleStats alterou o slice recebido")
+	}
+}
+
+func TestRecordHistogramSampleCap(t *testing.T) {
+	mc := NewMetricsCollector()
+	mc.HistogramMaxSamples = 10
+
+	capacity := 0
+	for i := 1; i <= 35; i++ {
+		mc.RecordHistogram("latencia", float64(i))
+
+		values := mc.histograms["latencia"]
+		if len(values) > mc.HistogramMaxSamples {
+			t.Fatalf("após %d amostras: %d guardadas, limite %d", i, len(values), mc.HistogramMaxSamples)
+		}
+
+		// Cheio o slice, o descarte é feito no lugar, sem realocar
+		if len(values) == mc.HistogramMaxSamples {
+			if capacity == 0 {
+				capacity = cap(values)
+			} else if cap(values) != capacity {
+				t.Fatalf("após %d amostras: capacidade %d, esperado %d", i, cap(values), capacity)
+			}
+		}
+	}
+
+	// Ficam as amostras mais recentes, em ordem
+	values := mc.histograms["latencia"]
+	for i, v := range values {
+		if want := float64(26 + i); v != want {
+			t.Errorf("amostra %d = %v, esperado %v", i, v, want)
+		}
+	}
+
+	// Reduzir o limite em execução descarta o excesso na próxima amostra
+	mc.HistogramMaxSamples = 4
+	mc.RecordHistogram("latencia", 36)
+	if got := mc.histograms["latencia"]; len(got) != 4 || got[0] != 33 || got[3] != 36 {
+		t.Errorf("amostras após reduzir o limite = %v, esperado [33 34 35 36]", got)
+	}
+
+	if stats := mc.GetHistogramStats("latencia"); stats.Count != 4 || stats.Min != 33 || stats.Max != 36 {
+		t.Errorf("resumo = %+v, esperado 4 amostras de 33 a 36", stats)
+	}
+}
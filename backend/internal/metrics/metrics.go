@@ -13,6 +13,14 @@ type MetricsCollector struct {
 	gauges     map[string]float64
 	histograms map[string][]float64
 	startTime  time.Time
+
+	// Histogramas registrados com faixas (RegisterHistogram): apenas
+	// contagens, sem guardar as amostras
+	bucketed map[string]*bucketHistogram
+
+	// HistogramMaxSamples é quantas amostras brutas cada histograma sem
+	// faixas guarda (padrão DefaultHistogramMaxSamples)
+	HistogramMaxSamples int
 }
 
 // NewMetricsCollector cria um novo coletor de métricas
@@ -22,6 +30,9 @@ func NewMetricsCollector() *MetricsCollector {
 		gauges:     make(map[string]float64),
 		histograms: make(map[string][]float64),
 		startTime:  time.Now(),
+
+		bucketed:            make(map[string]*bucketHistogram),
+		HistogramMaxSamples: DefaultHistogramMaxSamples,
 	}
 }
 
@@ -39,15 +50,58 @@ func (mc *MetricsCollector) SetGauge(name string, value float64) {
 	mc.gauges[name] = value
 }
 
+// RegisterHistogram passa a contar o histograma nas faixas informadas
+// (limites superiores; a faixa +Inf é implícita), com memória constante.
+// Amostras brutas já gravadas com o mesmo nome são contadas nas faixas e
+// descartadas.
+func (mc *MetricsCollector) RegisterHistogram(name string, buckets []float64) {
+	mc.mutex.Lock()
+	defer mc.mutex.Unlock()
+
+	h := newBucketHistogram(buckets)
+	for _, v := range mc.histograms[name] {
+		h.observe(v)
+	}
+	delete(mc.histograms, name)
+	mc.bucketed[name] = h
+}
+
 // RecordHistogram adiciona um valor a um histograma
 func (mc *MetricsCollector) RecordHistogram(name string, value float64) {
 	mc.mutex.Lock()
 	defer mc.mutex.Unlock()
-	mc.histograms[name] = append(mc.histograms[name], value)
-	// Limitar tamanho para evitar uso excessivo de memória
-	if len(mc.histograms[name]) > 1000 {
-		mc.histograms[name] = mc.histograms[name][1:]
+
+	if h, ok := mc.bucketed[name]; ok {
+		h.observe(value)
+		return
+	}
+
+	maxSamples := mc.HistogramMaxSamples
+	if maxSamples <= 0 {
+		maxSamples = DefaultHistogramMaxSamples
+	}
+
+	// Limitar tamanho para evitar uso excessivo de memória: descartar as
+	// amostras mais antigas antes de adicionar, sem realocar o slice
+	values := mc.histograms[name]
+	if len(values) >= maxSamples {
+		drop := len(values) - maxSamples + 1
+		n := copy(values, values[drop:])
+		values = values[:n]
 	}
+	mc.histograms[name] = append(values, value)
+}
+
+// GetHistogramStats retorna o resumo de um histograma (zerado se não houver
+// amostras)
+func (mc *MetricsCollector) GetHistogramStats(name string) HistogramStats {
+	mc.mutex.RLock()
+	defer mc.mutex.RUnlock()
+
+	if h, ok := mc.bucketed[name]; ok {
+		return h.stats()
+	}
+	return sampleStats(mc.histograms[name])
 }
 
 // GetAllMetrics retorna todas as métricas coletadas
@@ -78,20 +132,61 @@ func (mc *MetricsCollector) GetAllMetrics() map[string]interface{} {
 			continue
 		}
 
-		stats := make(map[string]float64)
-		// Calcular média
-		sum := 0.0
-		for _, v := range values {
-			sum += v
-		}
-		stats["avg"] = sum / float64(len(values))
+		histogramStats[name] = histogramStatsMap(sampleStats(values))
+	}
 
-		histogramStats[name] = stats
+	// Histogramas com faixas também exportam as contagens acumuladas por
+	// faixa (le), no formato dos histogramas do Prometheus
+	histogramBuckets := make(map[string][]BucketCount)
+	for name, h := range mc.bucketed {
+		if h.count == 0 {
+			continue
+		}
+		histogramStats[name] = histogramStatsMap(h.stats())
+		histogramBuckets[name] = h.cumulativeCounts()
 	}
 	result["histograms"] = histogramStats
+	result["histogram_buckets"] = histogramBuckets
 
 	// Adicionar tempo de atividade
 	result["uptime_seconds"] = time.Since(mc.startTime).Seconds()
 
 	return result
 }
+
+// histogramStatsMap converte o resumo de um histograma para o formato de
+// GetAllMetrics
+func histogramStatsMap(s HistogramStats) map[string]float64 {
+	stats := map[string]float64{
+		"count": float64(s.Count),
+		"sum":   s.Sum,
+		"min":   s.Min,
+		"max":   s.Max,
+		"p50":   s.P50,
+		"p75":   s.P75,
+		"p95":   s.P95,
+		"p99":   s.P99,
+		"p99_9": s.P999,
+	}
+	if s.Count > 0 {
+		stats["avg"] = s.Sum / float64(s.Count)
+	}
+	return stats
+}
+
+// GetHistogramSamples retorna cópia das últimas n amostras de um histograma,
+// da mais antiga para a mais recente. Histogramas com faixas não guardam
+// amostras e retornam vazio.
+func (mc *MetricsCollector) GetHistogramSamples(name string, n int) []float64 {
+	mc.mutex.RLock()
+	defer mc.mutex.RUnlock()
+
+	values := mc.histograms[name]
+	if n > 0 && len(values) > n {
+		values = values[len(values)-n:]
+	}
+
+	samples := make([]float64, len(values))
+	copy(samples, values)
+	return samples
+}
@@ -0,0 +1,196 @@
+//go:build integration
+
+package repository
+
+import (
+	"app_padrao/internal/domain"
+	"app_padrao/internal/testutil"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestPLCRepositoryCRUD(t *testing.T) {
+	db := testutil.NewTestDB(t)
+	repo := NewPLCRepository(db)
+
+	id, err := repo.Create(domain.PLC{
+		Name:              "CLP_Linha1",
+		IPAddress:         "10.0.0.10",
+		Rack:              0,
+		Slot:              1,
+		Active:            true,
+		MonitoringEnabled: true,
+		CreatedAt:         time.Now(),
+	})
+	if err != nil {
+		t.Fatalf("erro ao criar PLC: %v", err)
+	}
+
+	plc, err := repo.GetByID(id)
+	if err != nil {
+		t.Fatalf("erro ao buscar PLC: %v", err)
+	}
+	if plc.Name != "CLP_Linha1" || plc.IPAddress != "10.0.0.10" || plc.Status != "unknown" {
+		t.Errorf("PLC lido = %+v, diferente do criado", plc)
+	}
+
+	plc.Name = "CLP_Linha1_B"
+	plc.Active = false
+	if err := repo.Update(plc); err != nil {
+		t.Fatalf("erro ao atualizar PLC: %v", err)
+	}
+	if plc, err = repo.GetByID(id); err != nil || plc.Name != "CLP_Linha1_B" || plc.Active {
+		t.Errorf("PLC após atualização = %+v (%v)", plc, err)
+	}
+
+	all, err := repo.GetAll()
+	if err != nil || len(all) != 1 {
+		t.Errorf("GetAll = %d PLCs (%v), esperado 1", len(all), err)
+	}
+
+	if err := repo.Delete(id); err != nil {
+		t.Fatalf("erro ao excluir PLC: %v", err)
+	}
+	if _, err := repo.GetByID(id); !errors.Is(err, domain.ErrPLCNotFound) {
+		t.Errorf("erro após exclusão = %v, esperado ErrPLCNotFound", err)
+	}
+}
+
+func TestPLCTagRepositoryBulkCreate(t *testing.T) {
+	db := testutil.NewTestDB(t)
+	plcRepo := NewPLCRepository(db)
+	tagRepo := NewPLCTagRepository(db)
+
+	plcID, err := plcRepo.Create(domain.PLC{Name: "CLP_Bulk", IPAddress: "10.0.0.11", Slot: 1, Active: true, MonitoringEnabled: true, CreatedAt: time.Now()})
+	if err != nil {
+		t.Fatalf("erro ao criar PLC: %v", err)
+	}
+
+	// Acima do limiar do COPY FROM, para cobrir os dois caminhos de inserção
+	for _, size := range []int{3, copyBulkCreateThreshold + 5} {
+		tags := testTags(size, fmt.Sprintf("lote%d", size))
+		ids, err := tagRepo.BulkCreate(plcID, tags, 0)
+		if err != nil {
+			t.Fatalf("erro ao criar lote de %d tags: %v", size, err)
+		}
+		if len(ids) != size {
+			t.Errorf("IDs retornados = %d, esperado %d", len(ids), size)
+		}
+	}
+
+	stored, err := tagRepo.GetPLCTags(plcID)
+	if err != nil {
+		t.Fatalf("erro ao buscar tags: %v", err)
+	}
+	want := 3 + copyBulkCreateThreshold + 5
+	if len(stored) != want {
+		t.Fatalf("tags gravadas = %d, esperado %d", len(stored), want)
+	}
+
+	// O lote inteiro é recusado se ultrapassar o limite
+	if _, err := tagRepo.BulkCreate(plcID, testTags(2, "excesso"), want+1); !errors.Is(err, domain.ErrTagLimitExceeded) {
+		t.Errorf("erro = %v, esperado ErrTagLimitExceeded", err)
+	}
+	if count, _ := tagRepo.CountByPLC(plcID); count != want {
+		t.Errorf("tags após lote recusado = %d, esperado %d", count, want)
+	}
+
+	if _, err := tagRepo.BulkCreate(plcID+1000, testTags(1, "orfa"), 0); !errors.Is(err, domain.ErrPLCNotFound) {
+		t.Errorf("erro = %v, esperado ErrPLCNotFound", err)
+	}
+}
+
+// testTags monta n tags real com nomes e endereços distintos
+func testTags(n int, prefix string) []domain.PLCTag {
+	tags := make([]domain.PLCTag, n)
+	for i := range tags {
+		tags[i] = domain.PLCTag{
+			Name:        fmt.Sprintf("%s_tag_%d", prefix, i),
+			DBNumber:    1,
+			ByteOffset:  i * 4,
+			DataType:    "real",
+			ScanRate:    1000,
+			Active:      true,
+			ScaleFactor: 1,
+			CreatedAt:   time.Now(),
+		}
+	}
+	return tags
+}
+
+func TestPLCTagByteOffsetRoundTrip(t *testing.T) {
+	db := testutil.NewTestDB(t)
+	plcRepo := NewPLCRepository(db)
+
+	plcID, err := plcRepo.Create(domain.PLC{Name: "CLP_Offset", IPAddress: "10.0.0.12", Slot: 1, Active: true, MonitoringEnabled: true, CreatedAt: time.Now()})
+	if err != nil {
+		t.Fatalf("erro ao criar PLC: %v", err)
+	}
+
+	// Base antiga: byte_offset NUMERIC, com uma tag já cadastrada
+	if _, err := db.Exec(`ALTER TABLE plc_tags ALTER COLUMN byte_offset TYPE NUMERIC(10,2)`); err != nil {
+		t.Fatalf("erro ao preparar coluna legada: %v", err)
+	}
+	var legacyID int
+	err = db.QueryRow(`
+		INSERT INTO plc_tags (plc_id, name, db_number, byte_offset, data_type)
+		VALUES ($1, 'legada', 1, 12.00, 'int') RETURNING id
+	`, plcID).Scan(&legacyID)
+	if err != nil {
+		t.Fatalf("erro ao inserir tag legada: %v", err)
+	}
+
+	tagRepo := NewPLCTagRepository(db)
+	if columnType, err := tagRepo.byteOffsetColumnType(); err != nil || columnType != "integer" {
+		t.Fatalf("tipo de byte_offset = %q (%v), esperado integer", columnType, err)
+	}
+
+	// Maior endereço de byte de um DB S7
+	const bigOffset = 65534
+	id, err := tagRepo.Create(domain.PLCTag{
+		PLCID:       plcID,
+		Name:        "offset_alto",
+		DBNumber:    1,
+		ByteOffset:  bigOffset,
+		DataType:    "dint",
+		ScanRate:    1000,
+		Active:      true,
+		ScaleFactor: 1,
+		CreatedAt:   time.Now(),
+	})
+	if err != nil {
+		t.Fatalf("erro ao criar tag: %v", err)
+	}
+
+	want := map[int]int{legacyID: 12, id: bigOffset}
+
+	for tagID, offset := range want {
+		tag, err := tagRepo.GetByID(tagID)
+		if err != nil {
+			t.Fatalf("GetByID(%d): %v", tagID, err)
+		}
+		if tag.ByteOffset != offset {
+			t.Errorf("GetByID(%d).ByteOffset = %d, esperado %d", tagID, tag.ByteOffset, offset)
+		}
+	}
+
+	byName, err := tagRepo.GetByName("offset_alto")
+	if err != nil || len(byName) != 1 || byName[0].ByteOffset != bigOffset {
+		t.Errorf("GetByName = %+v (%v), esperado byte_offset %d", byName, err, bigOffset)
+	}
+
+	tags, err := tagRepo.GetPLCTags(plcID)
+	if err != nil {
+		t.Fatalf("GetPLCTags: %v", err)
+	}
+	for _, tag := range tags {
+		if tag.ByteOffset != want[tag.ID] {
+			t.Errorf("GetPLCTags: tag %d ByteOffset = %d, esperado %d", tag.ID, tag.ByteOffset, want[tag.ID])
+		}
+	}
+	if len(tags) != len(want) {
+		t.Errorf("GetPLCTags = %d tags, esperado %d", len(tags), len(want))
+	}
+}
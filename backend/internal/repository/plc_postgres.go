@@ -2,48 +2,107 @@ package repository
 
 import (
 	"app_padrao/internal/domain"
+	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+	"log"
+	"strings"
 	"time"
 )
 
 type PLCRepository struct {
 	db *sql.DB
+	queryTimeout
 }
 
 func NewPLCRepository(db *sql.DB) *PLCRepository {
-	return &PLCRepository{db: db}
+	r := &PLCRepository{db: db}
+	r.ensureSchema()
+	return r
 }
 
-func (r *PLCRepository) GetByID(id int) (domain.PLC, error) {
-	query := `
-		SELECT p.id, p.name, p.ip_address, p.rack, p.slot, p.active, p.created_at, p.updated_at,
-			COALESCE(s.status, 'unknown') as status
-		FROM plcs p 
-		LEFT JOIN plc_status s ON p.id = s.plc_id
-		WHERE p.id = $1
-	`
+// ensureSchema adiciona colunas novas à tabela plcs quando ainda não existem
+func (r *PLCRepository) ensureSchema() {
+	if r.db == nil {
+		return
+	}
+
+	_, err := r.db.Exec(`ALTER TABLE plcs ADD COLUMN IF NOT EXISTS monitoring_enabled BOOLEAN NOT NULL DEFAULT true`)
+	if err != nil {
+		log.Printf("Erro ao adicionar coluna monitoring_enabled: %v", err)
+	}
+
+	_, err = r.db.Exec(`ALTER TABLE plcs ADD COLUMN IF NOT EXISTS override_tag_limit INTEGER`)
+	if err != nil {
+		log.Printf("Erro ao adicionar coluna override_tag_limit: %v", err)
+	}
+
+	// Sites precisam existir antes da chave estrangeira site_id
+	ensurePLCSitesTable(r.db)
+
+	_, err = r.db.Exec(`ALTER TABLE plcs ADD COLUMN IF NOT EXISTS site_id INTEGER REFERENCES plc_sites(id) ON DELETE SET NULL`)
+	if err != nil {
+		log.Printf("Erro ao adicionar coluna site_id: %v", err)
+	}
 
+	_, err = r.db.Exec(`
+		ALTER TABLE plcs ADD COLUMN IF NOT EXISTS protocol_variant VARCHAR(10) NOT NULL DEFAULT '';
+		ALTER TABLE plcs ADD COLUMN IF NOT EXISTS local_tsap INTEGER;
+		ALTER TABLE plcs ADD COLUMN IF NOT EXISTS remote_tsap INTEGER
+	`)
+	if err != nil {
+		log.Printf("Erro ao adicionar colunas do protocolo S7: %v", err)
+	}
+}
+
+// nullableInt converte um inteiro opcional para NULL quando ausente
+func nullableInt(v *int) interface{} {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
+
+// plcSelect lista as colunas lidas em todas as consultas de PLCs, com o
+// status e a pausa do site
+const plcSelect = `
+	SELECT p.id, p.name, p.ip_address, p.rack, p.slot, p.active, p.monitoring_enabled, p.override_tag_limit,
+		p.site_id, COALESCE(ps.monitoring_enabled, true), p.created_at, p.updated_at,
+		COALESCE(s.status, 'unknown') as status, p.protocol_variant, p.local_tsap, p.remote_tsap
+	FROM plcs p
+	LEFT JOIN plc_status s ON p.id = s.plc_id
+	LEFT JOIN plc_sites ps ON ps.id = p.site_id
+`
+
+// scanPLC lê uma linha com as colunas de plcSelect
+func scanPLC(row interface{ Scan(...interface{}) error }) (domain.PLC, error) {
 	var plc domain.PLC
 	var updatedAt sql.NullTime
 	var status sql.NullString
+	var overrideTagLimit sql.NullInt64
+	var siteID sql.NullInt64
+	var localTSAP, remoteTSAP sql.NullInt64
 
-	err := r.db.QueryRow(query, id).Scan(
+	err := row.Scan(
 		&plc.ID,
 		&plc.Name,
 		&plc.IPAddress,
 		&plc.Rack,
 		&plc.Slot,
 		&plc.Active,
+		&plc.MonitoringEnabled,
+		&overrideTagLimit,
+		&siteID,
+		&plc.SiteMonitoringEnabled,
 		&plc.CreatedAt,
 		&updatedAt,
 		&status,
+		&plc.ProtocolVariant,
+		&localTSAP,
+		&remoteTSAP,
 	)
-
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return domain.PLC{}, domain.ErrPLCNotFound
-		}
 		return domain.PLC{}, err
 	}
 
@@ -51,6 +110,26 @@ func (r *PLCRepository) GetByID(id int) (domain.PLC, error) {
 		plc.UpdatedAt = updatedAt.Time
 	}
 
+	if overrideTagLimit.Valid {
+		limit := int(overrideTagLimit.Int64)
+		plc.OverrideTagLimit = &limit
+	}
+
+	if siteID.Valid {
+		id := int(siteID.Int64)
+		plc.SiteID = &id
+	}
+
+	if localTSAP.Valid {
+		tsap := int(localTSAP.Int64)
+		plc.LocalTSAP = &tsap
+	}
+
+	if remoteTSAP.Valid {
+		tsap := int(remoteTSAP.Int64)
+		plc.RemoteTSAP = &tsap
+	}
+
 	if status.Valid {
 		plc.Status = status.String
 	} else {
@@ -60,16 +139,9 @@ func (r *PLCRepository) GetByID(id int) (domain.PLC, error) {
 	return plc, nil
 }
 
-func (r *PLCRepository) GetAll() ([]domain.PLC, error) {
-	query := `
-		SELECT p.id, p.name, p.ip_address, p.rack, p.slot, p.active, p.created_at, p.updated_at,
-			COALESCE(s.status, 'unknown') as status
-		FROM plcs p 
-		LEFT JOIN plc_status s ON p.id = s.plc_id
-		ORDER BY p.name
-	`
-
-	rows, err := r.db.Query(query)
+// queryPLCs executa uma consulta de plcSelect e lê todos os PLCs
+func (r *PLCRepository) queryPLCs(ctx context.Context, query string, args ...interface{}) ([]domain.PLC, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -77,36 +149,10 @@ func (r *PLCRepository) GetAll() ([]domain.PLC, error) {
 
 	var plcs []domain.PLC
 	for rows.Next() {
-		var plc domain.PLC
-		var updatedAt sql.NullTime
-		var status sql.NullString
-
-		err := rows.Scan(
-			&plc.ID,
-			&plc.Name,
-			&plc.IPAddress,
-			&plc.Rack,
-			&plc.Slot,
-			&plc.Active,
-			&plc.CreatedAt,
-			&updatedAt,
-			&status,
-		)
-
+		plc, err := scanPLC(rows)
 		if err != nil {
 			return nil, err
 		}
-
-		if updatedAt.Valid {
-			plc.UpdatedAt = updatedAt.Time
-		}
-
-		if status.Valid {
-			plc.Status = status.String
-		} else {
-			plc.Status = "unknown"
-		}
-
 		plcs = append(plcs, plc)
 	}
 
@@ -117,80 +163,153 @@ func (r *PLCRepository) GetAll() ([]domain.PLC, error) {
 	return plcs, nil
 }
 
+func (r *PLCRepository) GetByID(id int) (domain.PLC, error) {
+	ctx, cancel := r.queryContext()
+	defer cancel()
+
+	plc, err := scanPLC(r.db.QueryRowContext(ctx, plcSelect+" WHERE p.id = $1", id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.PLC{}, domain.ErrPLCNotFound
+		}
+		return domain.PLC{}, err
+	}
+
+	return plc, nil
+}
+
+func (r *PLCRepository) GetAll() ([]domain.PLC, error) {
+	ctx, cancel := r.queryContext()
+	defer cancel()
+
+	return r.queryPLCs(ctx, plcSelect+" ORDER BY p.name")
+}
+
 func (r *PLCRepository) GetActivePLCs() ([]domain.PLC, error) {
-	query := `
-		SELECT p.id, p.name, p.ip_address, p.rack, p.slot, p.active, p.created_at, p.updated_at,
-			COALESCE(s.status, 'unknown') as status
-		FROM plcs p 
-		LEFT JOIN plc_status s ON p.id = s.plc_id
-		WHERE p.active = true
-		ORDER BY p.name
-	`
+	ctx, cancel := r.queryContext()
+	defer cancel()
+
+	return r.queryPLCs(ctx, plcSelect+" WHERE p.active = true ORDER BY p.name")
+}
+
+// likeEscaper escapa os curingas do LIKE/ILIKE (o escape padrão do
+// PostgreSQL é a barra invertida)
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// escapeLike faz o termo ser comparado literalmente em LIKE/ILIKE
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+// ListWithFilter monta a cláusula WHERE a partir do filtro e obtém o total
+// com COUNT(*) OVER(), na mesma consulta da página
+func (r *PLCRepository) ListWithFilter(ctx context.Context, filter domain.PLCFilter, page, pageSize int) ([]domain.PLC, int, error) {
+	ctx, cancel := r.requestContext(ctx)
+	defer cancel()
+
+	conditions := []string{}
+	params := []interface{}{}
+	paramIndex := 1
+
+	if filter.Name != "" {
+		conditions = append(conditions, fmt.Sprintf("p.name ILIKE $%d", paramIndex))
+		params = append(params, "%"+escapeLike(filter.Name)+"%")
+		paramIndex++
+	}
+
+	if filter.Status != "" {
+		conditions = append(conditions, fmt.Sprintf("COALESCE(s.status, 'unknown') = $%d", paramIndex))
+		params = append(params, filter.Status)
+		paramIndex++
+	}
+
+	if filter.Active != nil {
+		conditions = append(conditions, fmt.Sprintf("p.active = $%d", paramIndex))
+		params = append(params, *filter.Active)
+		paramIndex++
+	}
+
+	if filter.SiteID != nil {
+		conditions = append(conditions, fmt.Sprintf("p.site_id = $%d", paramIndex))
+		params = append(params, *filter.SiteID)
+		paramIndex++
+	}
 
-	rows, err := r.db.Query(query)
+	whereClause := ""
+	if len(conditions) > 0 {
+		whereClause = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	query := fmt.Sprintf(`SELECT f.*, COUNT(*) OVER() FROM (%s%s) f
+		ORDER BY f.name, f.id
+		LIMIT $%d OFFSET $%d`, plcSelect, whereClause, paramIndex, paramIndex+1)
+
+	rows, err := r.db.QueryContext(ctx, query, append(params, pageSize, (page-1)*pageSize)...)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 	defer rows.Close()
 
-	var plcs []domain.PLC
+	plcs := []domain.PLC{}
+	total := 0
 	for rows.Next() {
-		var plc domain.PLC
-		var updatedAt sql.NullTime
-		var status sql.NullString
-
-		err := rows.Scan(
-			&plc.ID,
-			&plc.Name,
-			&plc.IPAddress,
-			&plc.Rack,
-			&plc.Slot,
-			&plc.Active,
-			&plc.CreatedAt,
-			&updatedAt,
-			&status,
-		)
-
+		plc, err := scanPLC(countedRow{rows, &total})
 		if err != nil {
-			return nil, err
+			return nil, 0, err
 		}
-
-		if updatedAt.Valid {
-			plc.UpdatedAt = updatedAt.Time
-		}
-
-		if status.Valid {
-			plc.Status = status.String
-		} else {
-			plc.Status = "unknown"
-		}
-
 		plcs = append(plcs, plc)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
 
-	if err = rows.Err(); err != nil {
-		return nil, err
+	// Página além do fim: COUNT(*) OVER() não tem linha onde aparecer
+	if len(plcs) == 0 && page > 1 {
+		countQuery := "SELECT COUNT(*) FROM (" + plcSelect + whereClause + ") f"
+		if err := r.db.QueryRowContext(ctx, countQuery, params...).Scan(&total); err != nil {
+			return nil, 0, err
+		}
 	}
 
-	return plcs, nil
+	return plcs, total, nil
+}
+
+// countedRow lê as colunas de plcSelect seguidas do total de COUNT(*) OVER()
+type countedRow struct {
+	rows  *sql.Rows
+	total *int
+}
+
+func (r countedRow) Scan(dest ...interface{}) error {
+	return r.rows.Scan(append(dest, r.total)...)
 }
 
 func (r *PLCRepository) Create(plc domain.PLC) (int, error) {
+	ctx, cancel := r.queryContext()
+	defer cancel()
+
 	query := `
-		INSERT INTO plcs (name, ip_address, rack, slot, active, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO plcs (name, ip_address, rack, slot, active, monitoring_enabled, override_tag_limit, site_id, created_at,
+			protocol_variant, local_tsap, remote_tsap)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 		RETURNING id
 	`
 
 	var id int
-	err := r.db.QueryRow(
+	err := r.db.QueryRowContext(ctx,
 		query,
 		plc.Name,
 		plc.IPAddress,
 		plc.Rack,
 		plc.Slot,
 		plc.Active,
+		plc.MonitoringEnabled,
+		nullableInt(plc.OverrideTagLimit),
+		nullableInt(plc.SiteID),
 		plc.CreatedAt,
+		plc.ProtocolVariant,
+		nullableInt(plc.LocalTSAP),
+		nullableInt(plc.RemoteTSAP),
 	).Scan(&id)
 
 	if err != nil {
@@ -205,7 +324,7 @@ func (r *PLCRepository) Create(plc domain.PLC) (int, error) {
 		SET status = EXCLUDED.status, last_update = EXCLUDED.last_update
 	`
 
-	_, err = r.db.Exec(statusQuery, id, "unknown", time.Now())
+	_, err = r.db.ExecContext(ctx, statusQuery, id, "unknown", time.Now())
 	if err != nil {
 		return id, err // Retornamos o ID mesmo com erro no status
 	}
@@ -214,20 +333,31 @@ func (r *PLCRepository) Create(plc domain.PLC) (int, error) {
 }
 
 func (r *PLCRepository) Update(plc domain.PLC) error {
+	ctx, cancel := r.queryContext()
+	defer cancel()
+
 	query := `
 		UPDATE plcs
-		SET name = $1, ip_address = $2, rack = $3, slot = $4, active = $5, updated_at = $6
-		WHERE id = $7
+		SET name = $1, ip_address = $2, rack = $3, slot = $4, active = $5, monitoring_enabled = $6,
+			override_tag_limit = $7, site_id = $8, updated_at = $9,
+			protocol_variant = $10, local_tsap = $11, remote_tsap = $12
+		WHERE id = $13
 	`
 
-	result, err := r.db.Exec(
+	result, err := r.db.ExecContext(ctx,
 		query,
 		plc.Name,
 		plc.IPAddress,
 		plc.Rack,
 		plc.Slot,
 		plc.Active,
+		plc.MonitoringEnabled,
+		nullableInt(plc.OverrideTagLimit),
+		nullableInt(plc.SiteID),
 		time.Now(),
+		plc.ProtocolVariant,
+		nullableInt(plc.LocalTSAP),
+		nullableInt(plc.RemoteTSAP),
 		plc.ID,
 	)
 
@@ -248,14 +378,17 @@ func (r *PLCRepository) Update(plc domain.PLC) error {
 }
 
 func (r *PLCRepository) Delete(id int) error {
+	ctx, cancel := r.queryContext()
+	defer cancel()
+
 	// Remover o status primeiro (devido à chave estrangeira)
-	_, err := r.db.Exec("DELETE FROM plc_status WHERE plc_id = $1", id)
+	_, err := r.db.ExecContext(ctx, "DELETE FROM plc_status WHERE plc_id = $1", id)
 	if err != nil {
 		return err
 	}
 
 	// Agora remove o PLC
-	result, err := r.db.Exec("DELETE FROM plcs WHERE id = $1", id)
+	result, err := r.db.ExecContext(ctx, "DELETE FROM plcs WHERE id = $1", id)
 	if err != nil {
 		return err
 	}
@@ -273,6 +406,9 @@ func (r *PLCRepository) Delete(id int) error {
 }
 
 func (r *PLCRepository) UpdatePLCStatus(status domain.PLCStatus) error {
+	ctx, cancel := r.queryContext()
+	defer cancel()
+
 	query := `
 		INSERT INTO plc_status (plc_id, status, last_update)
 		VALUES ($1, $2, $3)
@@ -280,6 +416,6 @@ func (r *PLCRepository) UpdatePLCStatus(status domain.PLCStatus) error {
 		SET status = EXCLUDED.status, last_update = EXCLUDED.last_update
 	`
 
-	_, err := r.db.Exec(query, status.PLCID, status.Status, status.LastUpdate)
+	_, err := r.db.ExecContext(ctx, query, status.PLCID, status.Status, status.LastUpdate)
 	return err
 }
@@ -0,0 +1,22 @@
+package repository
+
+import "testing"
+
+func TestEscapeLike(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"PLC01", "PLC01"},
+		{"100%", `100\%`},
+		{"linha_1", `linha\_1`},
+		{`C:\PLC`, `C:\\PLC`},
+		{`%_\`, `\%\_\\`},
+	}
+
+	for _, tt := range tests {
+		if got := escapeLike(tt.in); got != tt.want {
+			t.Errorf("escapeLike(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -6,7 +6,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-redis/redis/v8"
@@ -14,18 +16,31 @@ import (
 
 // PLCRedisRepository implementa a interface PLCRepository usando Redis
 type PLCRedisRepository struct {
-	client *redis.Client
-	ctx    context.Context
+	client    *redis.Client
+	ctx       context.Context
+	keyPrefix string // Namespace da instância (REDIS_KEY_PREFIX)
 }
 
 // NewPLCRedisRepository cria um novo repositório Redis para PLCs
 func NewPLCRedisRepository(client *redis.Client) *PLCRedisRepository {
+	return NewPLCRedisRepositoryWithPrefix(client, "")
+}
+
+// NewPLCRedisRepositoryWithPrefix cria o repositório prefixando todas as chaves com
+// o namespace informado (ex.: "tenant1:")
+func NewPLCRedisRepositoryWithPrefix(client *redis.Client, keyPrefix string) *PLCRedisRepository {
 	return &PLCRedisRepository{
-		client: client,
-		ctx:    context.Background(),
+		client:    client,
+		ctx:       context.Background(),
+		keyPrefix: keyPrefix,
 	}
 }
 
+// key aplica o namespace da instância a uma chave ou prefixo padronizado
+func (r *PLCRedisRepository) key(base string) string {
+	return r.keyPrefix + base
+}
+
 // chavesPadronizadas para garantir consistência
 const (
 	plcKeyPrefix       = "plc:"
@@ -36,7 +51,7 @@ const (
 
 // GetByID busca um PLC pelo ID no Redis
 func (r *PLCRedisRepository) GetByID(id int) (domain.PLC, error) {
-	key := fmt.Sprintf("%s%d", plcKeyPrefix, id)
+	key := fmt.Sprintf("%s%d", r.key(plcKeyPrefix), id)
 
 	data, err := r.client.Get(r.ctx, key).Result()
 	if err != nil {
@@ -52,7 +67,7 @@ func (r *PLCRedisRepository) GetByID(id int) (domain.PLC, error) {
 	}
 
 	// Buscar status também
-	statusKey := fmt.Sprintf("%s%d", plcStatusKeyPrefix, id)
+	statusKey := fmt.Sprintf("%s%d", r.key(plcStatusKeyPrefix), id)
 	statusData, err := r.client.Get(r.ctx, statusKey).Result()
 	if err == nil {
 		var status struct {
@@ -71,7 +86,7 @@ func (r *PLCRedisRepository) GetByID(id int) (domain.PLC, error) {
 // GetAll retorna todos os PLCs armazenados no Redis
 func (r *PLCRedisRepository) GetAll() ([]domain.PLC, error) {
 	// Obter todos os IDs de PLCs armazenados
-	ids, err := r.client.SMembers(r.ctx, plcListKey).Result()
+	ids, err := r.client.SMembers(r.ctx, r.key(plcListKey)).Result()
 	if err != nil {
 		return nil, err
 	}
@@ -96,10 +111,57 @@ func (r *PLCRedisRepository) GetAll() ([]domain.PLC, error) {
 	return plcs, nil
 }
 
+// ListWithFilter filtra em memória os PLCs armazenados no Redis, com a mesma
+// ordenação e paginação da consulta no PostgreSQL. A leitura do Redis não
+// usa o contexto da requisição.
+func (r *PLCRedisRepository) ListWithFilter(_ context.Context, filter domain.PLCFilter, page, pageSize int) ([]domain.PLC, int, error) {
+	all, err := r.GetAll()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	name := strings.ToLower(filter.Name)
+	matches := []domain.PLC{}
+	for _, plc := range all {
+		if name != "" && !strings.Contains(strings.ToLower(plc.Name), name) {
+			continue
+		}
+		if filter.Status != "" && plc.Status != filter.Status {
+			continue
+		}
+		if filter.Active != nil && plc.Active != *filter.Active {
+			continue
+		}
+		if filter.SiteID != nil && (plc.SiteID == nil || *plc.SiteID != *filter.SiteID) {
+			continue
+		}
+		matches = append(matches, plc)
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Name != matches[j].Name {
+			return matches[i].Name < matches[j].Name
+		}
+		return matches[i].ID < matches[j].ID
+	})
+
+	total := len(matches)
+	start := (page - 1) * pageSize
+	if start >= total {
+		return []domain.PLC{}, total, nil
+	}
+	end := start + pageSize
+	if end > total {
+		end = total
+	}
+
+	return matches[start:end], total, nil
+}
+
 // GetActivePLCs retorna apenas PLCs ativos do Redis
 func (r *PLCRedisRepository) GetActivePLCs() ([]domain.PLC, error) {
 	// Obter IDs de PLCs ativos
-	ids, err := r.client.SMembers(r.ctx, plcActivesListKey).Result()
+	ids, err := r.client.SMembers(r.ctx, r.key(plcActivesListKey)).Result()
 	if err != nil {
 		return nil, err
 	}
@@ -131,7 +193,7 @@ func (r *PLCRedisRepository) Create(plc domain.PLC) (int, error) {
 		return 0, fmt.Errorf("ID de PLC inválido para criação no Redis")
 	}
 
-	key := fmt.Sprintf("%s%d", plcKeyPrefix, plc.ID)
+	key := fmt.Sprintf("%s%d", r.key(plcKeyPrefix), plc.ID)
 
 	// Serializar PLC para JSON
 	data, err := json.Marshal(plc)
@@ -142,15 +204,15 @@ func (r *PLCRedisRepository) Create(plc domain.PLC) (int, error) {
 	// Salvar no Redis
 	pipe := r.client.Pipeline()
 	pipe.Set(r.ctx, key, data, 0) // Sem expiração
-	pipe.SAdd(r.ctx, plcListKey, strconv.Itoa(plc.ID))
+	pipe.SAdd(r.ctx, r.key(plcListKey), strconv.Itoa(plc.ID))
 
 	// Se estiver ativo, adicionar à lista de ativos
 	if plc.Active {
-		pipe.SAdd(r.ctx, plcActivesListKey, strconv.Itoa(plc.ID))
+		pipe.SAdd(r.ctx, r.key(plcActivesListKey), strconv.Itoa(plc.ID))
 	}
 
 	// Adicionar status inicial
-	statusKey := fmt.Sprintf("%s%d", plcStatusKeyPrefix, plc.ID)
+	statusKey := fmt.Sprintf("%s%d", r.key(plcStatusKeyPrefix), plc.ID)
 	statusData, _ := json.Marshal(map[string]interface{}{
 		"status":      "unknown",
 		"last_update": time.Now(),
@@ -172,7 +234,7 @@ func (r *PLCRedisRepository) Update(plc domain.PLC) error {
 	}
 
 	// Verificar se o PLC existe
-	key := fmt.Sprintf("%s%d", plcKeyPrefix, plc.ID)
+	key := fmt.Sprintf("%s%d", r.key(plcKeyPrefix), plc.ID)
 	exists, err := r.client.Exists(r.ctx, key).Result()
 	if err != nil {
 		return err
@@ -194,9 +256,9 @@ func (r *PLCRedisRepository) Update(plc domain.PLC) error {
 
 	// Atualizar a lista de ativos
 	if plc.Active {
-		pipe.SAdd(r.ctx, plcActivesListKey, strconv.Itoa(plc.ID))
+		pipe.SAdd(r.ctx, r.key(plcActivesListKey), strconv.Itoa(plc.ID))
 	} else {
-		pipe.SRem(r.ctx, plcActivesListKey, strconv.Itoa(plc.ID))
+		pipe.SRem(r.ctx, r.key(plcActivesListKey), strconv.Itoa(plc.ID))
 	}
 
 	_, err = pipe.Exec(r.ctx)
@@ -205,7 +267,7 @@ func (r *PLCRedisRepository) Update(plc domain.PLC) error {
 
 // Delete remove um PLC do Redis
 func (r *PLCRedisRepository) Delete(id int) error {
-	key := fmt.Sprintf("%s%d", plcKeyPrefix, id)
+	key := fmt.Sprintf("%s%d", r.key(plcKeyPrefix), id)
 
 	// Verificar se o PLC existe
 	exists, err := r.client.Exists(r.ctx, key).Result()
@@ -219,9 +281,9 @@ func (r *PLCRedisRepository) Delete(id int) error {
 	// Remover PLC e suas referências
 	pipe := r.client.Pipeline()
 	pipe.Del(r.ctx, key)
-	pipe.SRem(r.ctx, plcListKey, strconv.Itoa(id))
-	pipe.SRem(r.ctx, plcActivesListKey, strconv.Itoa(id))
-	pipe.Del(r.ctx, fmt.Sprintf("%s%d", plcStatusKeyPrefix, id))
+	pipe.SRem(r.ctx, r.key(plcListKey), strconv.Itoa(id))
+	pipe.SRem(r.ctx, r.key(plcActivesListKey), strconv.Itoa(id))
+	pipe.Del(r.ctx, fmt.Sprintf("%s%d", r.key(plcStatusKeyPrefix), id))
 
 	_, err = pipe.Exec(r.ctx)
 	return err
@@ -229,7 +291,7 @@ func (r *PLCRedisRepository) Delete(id int) error {
 
 // UpdatePLCStatus atualiza o status de um PLC no Redis
 func (r *PLCRedisRepository) UpdatePLCStatus(status domain.PLCStatus) error {
-	statusKey := fmt.Sprintf("%s%d", plcStatusKeyPrefix, status.PLCID)
+	statusKey := fmt.Sprintf("%s%d", r.key(plcStatusKeyPrefix), status.PLCID)
 
 	data, err := json.Marshal(status)
 	if err != nil {
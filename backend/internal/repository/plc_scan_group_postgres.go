@@ -0,0 +1,209 @@
+package repository
+
+import (
+	"app_padrao/internal/domain"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// PLCScanGroupRepository implementa domain.PLCScanGroupRepository no PostgreSQL
+type PLCScanGroupRepository struct {
+	db *sql.DB
+	queryTimeout
+}
+
+func NewPLCScanGroupRepository(db *sql.DB) *PLCScanGroupRepository {
+	ensurePLCScanGroupsTable(db)
+	return &PLCScanGroupRepository{db: db}
+}
+
+// ensurePLCScanGroupsTable cria a tabela plc_scan_groups quando ainda não existe
+func ensurePLCScanGroupsTable(db *sql.DB) {
+	if db == nil {
+		return
+	}
+
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS plc_scan_groups (
+			id SERIAL PRIMARY KEY,
+			plc_id INTEGER NOT NULL REFERENCES plcs(id) ON DELETE CASCADE,
+			name VARCHAR(100) NOT NULL,
+			description TEXT NOT NULL DEFAULT '',
+			created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+			updated_at TIMESTAMP,
+			UNIQUE (plc_id, name)
+		)
+	`)
+	if err != nil {
+		log.Printf("Erro ao criar tabela plc_scan_groups: %v", err)
+	}
+}
+
+// plcScanGroupColumns inclui os IDs das tags do grupo, em ordem
+const plcScanGroupColumns = `g.id, g.plc_id, g.name, g.description, g.created_at, g.updated_at,
+	COALESCE((SELECT array_agg(t.id ORDER BY t.id) FROM plc_tags t WHERE t.scan_group_id = g.id), '{}')`
+
+// scanPLCScanGroup lê uma linha com as colunas de plcScanGroupColumns
+func scanPLCScanGroup(row rowScanner) (domain.PLCScanGroup, error) {
+	var group domain.PLCScanGroup
+	var updatedAt sql.NullTime
+	var tagIDs pq.Int64Array
+
+	err := row.Scan(&group.ID, &group.PLCID, &group.Name, &group.Description,
+		&group.CreatedAt, &updatedAt, &tagIDs)
+	if err != nil {
+		return domain.PLCScanGroup{}, err
+	}
+
+	if updatedAt.Valid {
+		group.UpdatedAt = updatedAt.Time
+	}
+
+	group.TagIDs = make([]int, len(tagIDs))
+	for i, id := range tagIDs {
+		group.TagIDs[i] = int(id)
+	}
+	return group, nil
+}
+
+func (r *PLCScanGroupRepository) GetByPLC(plcID int) ([]domain.PLCScanGroup, error) {
+	ctx, cancel := r.queryContext()
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+plcScanGroupColumns+` FROM plc_scan_groups g WHERE g.plc_id = $1 ORDER BY g.name`, plcID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	groups := []domain.PLCScanGroup{}
+	for rows.Next() {
+		group, err := scanPLCScanGroup(rows)
+		if err != nil {
+			return nil, err
+		}
+		groups = append(groups, group)
+	}
+
+	return groups, rows.Err()
+}
+
+func (r *PLCScanGroupRepository) GetByID(id int) (domain.PLCScanGroup, error) {
+	ctx, cancel := r.queryContext()
+	defer cancel()
+
+	group, err := scanPLCScanGroup(r.db.QueryRowContext(ctx,
+		`SELECT `+plcScanGroupColumns+` FROM plc_scan_groups g WHERE g.id = $1`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.PLCScanGroup{}, domain.ErrScanGroupNotFound
+		}
+		return domain.PLCScanGroup{}, err
+	}
+
+	return group, nil
+}
+
+func (r *PLCScanGroupRepository) Create(group domain.PLCScanGroup) (int, error) {
+	ctx, cancel := r.queryContext()
+	defer cancel()
+
+	var id int
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO plc_scan_groups (plc_id, name, description, created_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id
+	`, group.PLCID, group.Name, group.Description, time.Now()).Scan(&id)
+
+	return id, err
+}
+
+// AssignTags substitui as tags do grupo em uma única transação. Todas as tags
+// informadas precisam pertencer ao PLC do grupo.
+func (r *PLCScanGroupRepository) AssignTags(groupID int, tagIDs []int) error {
+	ctx, cancel := r.queryContext()
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var plcID int
+	err = tx.QueryRowContext(ctx, "SELECT plc_id FROM plc_scan_groups WHERE id = $1 FOR UPDATE", groupID).Scan(&plcID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.ErrScanGroupNotFound
+		}
+		return err
+	}
+
+	ids := make(pq.Int64Array, 0, len(tagIDs))
+	seen := make(map[int]bool, len(tagIDs))
+	for _, id := range tagIDs {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, int64(id))
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE plc_tags SET scan_group_id = NULL, updated_at = $2
+		WHERE scan_group_id = $1 AND NOT (id = ANY($3))
+	`, groupID, time.Now(), ids)
+	if err != nil {
+		return err
+	}
+
+	if len(ids) > 0 {
+		result, err := tx.ExecContext(ctx, `
+			UPDATE plc_tags SET scan_group_id = $1, updated_at = $2
+			WHERE plc_id = $3 AND id = ANY($4)
+		`, groupID, time.Now(), plcID, ids)
+		if err != nil {
+			return err
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rowsAffected != int64(len(ids)) {
+			return domain.ErrScanGroupTagMismatch
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, "UPDATE plc_scan_groups SET updated_at = $1 WHERE id = $2", time.Now(), groupID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Delete remove o grupo; a chave estrangeira ON DELETE SET NULL libera as tags
+func (r *PLCScanGroupRepository) Delete(id int) error {
+	ctx, cancel := r.queryContext()
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, "DELETE FROM plc_scan_groups WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return domain.ErrScanGroupNotFound
+	}
+
+	return nil
+}
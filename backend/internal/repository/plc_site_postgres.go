@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"app_padrao/internal/domain"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+)
+
+// PLCSiteRepository implementa domain.PLCSiteRepository no PostgreSQL
+type PLCSiteRepository struct {
+	db *sql.DB
+	queryTimeout
+}
+
+func NewPLCSiteRepository(db *sql.DB) *PLCSiteRepository {
+	ensurePLCSitesTable(db)
+	return &PLCSiteRepository{db: db}
+}
+
+// ensurePLCSitesTable cria a tabela plc_sites quando ainda não existe
+func ensurePLCSitesTable(db *sql.DB) {
+	if db == nil {
+		return
+	}
+
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS plc_sites (
+			id SERIAL PRIMARY KEY,
+			name VARCHAR(100) NOT NULL UNIQUE,
+			location VARCHAR(255) NOT NULL DEFAULT '',
+			timezone VARCHAR(64) NOT NULL DEFAULT '',
+			monitoring_enabled BOOLEAN NOT NULL DEFAULT true,
+			created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+			updated_at TIMESTAMP
+		)
+	`)
+	if err != nil {
+		log.Printf("Erro ao criar tabela plc_sites: %v", err)
+	}
+}
+
+const plcSiteColumns = `id, name, location, timezone, monitoring_enabled, created_at, updated_at`
+
+// scanPLCSite lê uma linha com as colunas de plcSiteColumns
+func scanPLCSite(row interface{ Scan(...interface{}) error }) (domain.PLCSite, error) {
+	var site domain.PLCSite
+	var updatedAt sql.NullTime
+
+	err := row.Scan(&site.ID, &site.Name, &site.Location, &site.Timezone,
+		&site.MonitoringEnabled, &site.CreatedAt, &updatedAt)
+	if err != nil {
+		return domain.PLCSite{}, err
+	}
+
+	if updatedAt.Valid {
+		site.UpdatedAt = updatedAt.Time
+	}
+	return site, nil
+}
+
+func (r *PLCSiteRepository) GetAll() ([]domain.PLCSite, error) {
+	ctx, cancel := r.queryContext()
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `SELECT `+plcSiteColumns+` FROM plc_sites ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sites := []domain.PLCSite{}
+	for rows.Next() {
+		site, err := scanPLCSite(rows)
+		if err != nil {
+			return nil, err
+		}
+		sites = append(sites, site)
+	}
+
+	return sites, rows.Err()
+}
+
+func (r *PLCSiteRepository) GetByID(id int) (domain.PLCSite, error) {
+	ctx, cancel := r.queryContext()
+	defer cancel()
+
+	site, err := scanPLCSite(r.db.QueryRowContext(ctx,
+		`SELECT `+plcSiteColumns+` FROM plc_sites WHERE id = $1`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.PLCSite{}, domain.ErrPLCSiteNotFound
+		}
+		return domain.PLCSite{}, err
+	}
+
+	return site, nil
+}
+
+func (r *PLCSiteRepository) Create(site domain.PLCSite) (int, error) {
+	ctx, cancel := r.queryContext()
+	defer cancel()
+
+	var id int
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO plc_sites (name, location, timezone, monitoring_enabled, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`, site.Name, site.Location, site.Timezone, site.MonitoringEnabled, time.Now()).Scan(&id)
+
+	return id, err
+}
+
+func (r *PLCSiteRepository) Update(site domain.PLCSite) error {
+	ctx, cancel := r.queryContext()
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE plc_sites
+		SET name = $1, location = $2, timezone = $3, monitoring_enabled = $4, updated_at = $5
+		WHERE id = $6
+	`, site.Name, site.Location, site.Timezone, site.MonitoringEnabled, time.Now(), site.ID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return domain.ErrPLCSiteNotFound
+	}
+
+	return nil
+}
+
+// Delete remove o site; a chave estrangeira ON DELETE SET NULL libera os PLCs
+func (r *PLCSiteRepository) Delete(id int) error {
+	ctx, cancel := r.queryContext()
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, "DELETE FROM plc_sites WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return domain.ErrPLCSiteNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"app_padrao/internal/domain"
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+// copyBulkCreateThreshold é a quantidade de tags a partir da qual a criação em
+// lote usa COPY FROM em vez de um INSERT por tag. Abaixo disso o custo fixo do
+// COPY (preparar o fluxo e buscar os IDs depois) não compensa.
+// BenchmarkCreatePLCTags (TEST_DATABASE_URL) compara as duas formas por
+// tamanho de lote, de 10 a 10.000 tags; o limite deve ficar no primeiro
+// tamanho em que o COPY é mais rápido.
+//
+// O valor 100 ainda é uma estimativa: o benchmark não foi executado contra
+// um PostgreSQL neste repositório e não há números medidos. Ao rodá-lo,
+// registrar aqui os tempos de insert e copy por tamanho (ao menos 1.000 e
+// 10.000) e ajustar o limite a partir deles.
+const copyBulkCreateThreshold = 100
+
+// plcTagCopyColumns são as colunas gravadas por copyPLCTags, na mesma ordem de
+// insertPLCTag
+var plcTagCopyColumns = []string{
+	"plc_id", "name", "description", "db_number", "byte_offset", "bit_offset", "data_type",
+	"scan_rate", "monitor_changes", "can_write", "active", "write_rate_limit_hz", "unpack_bits", "bit_labels", "min_delta",
+	"scale_factor", "scale_offset", "retention_days", "created_at",
+}
+
+// BulkCreateCOPY insere as tags com COPY FROM em uma única transação e
+// retorna os IDs na ordem recebida. Lotes menores que copyBulkCreateThreshold
+// usam um INSERT por tag. Os nomes devem ser únicos por PLC (restrição
+// UNIQUE (plc_id, name)). Não verifica o limite de tags: use BulkCreate para isso.
+func (r *PLCTagRepository) BulkCreateCOPY(tags []domain.PLCTag) ([]int, error) {
+	ctx, cancel := r.queryContext()
+	defer cancel()
+
+	if len(tags) == 0 {
+		return []int{}, nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	ids, err := createPLCTags(ctx, tx, tags)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// createPLCTags insere as tags na transação escolhendo entre COPY FROM e um
+// INSERT por tag conforme o tamanho do lote
+func createPLCTags(ctx context.Context, tx *sql.Tx, tags []domain.PLCTag) ([]int, error) {
+	if len(tags) >= copyBulkCreateThreshold {
+		return copyPLCTags(ctx, tx, tags)
+	}
+	return insertPLCTags(ctx, tx, tags)
+}
+
+// insertPLCTags insere as tags com um INSERT por tag
+func insertPLCTags(ctx context.Context, tx *sql.Tx, tags []domain.PLCTag) ([]int, error) {
+	ids := make([]int, 0, len(tags))
+	for _, tag := range tags {
+		id, err := insertPLCTag(ctx, tx, tag)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao inserir tag %s: %w", tag.Name, err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// copyPLCTags envia as tags por COPY FROM e busca os IDs atribuídos pelo par
+// (plc_id, name)
+func copyPLCTags(ctx context.Context, tx *sql.Tx, tags []domain.PLCTag) ([]int, error) {
+	stmt, err := tx.PrepareContext(ctx, pq.CopyIn("plc_tags", plcTagCopyColumns...))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, tag := range tags {
+		bitLabels, err := marshalBitLabels(tag.BitLabels)
+		if err != nil {
+			stmt.Close()
+			return nil, err
+		}
+		// JSONB é enviado como texto; []byte seria codificado como bytea
+		var labels interface{}
+		if data, ok := bitLabels.([]byte); ok {
+			labels = string(data)
+		}
+
+		_, err = stmt.ExecContext(ctx,
+			tag.PLCID, tag.Name, tag.Description, tag.DBNumber, tag.ByteOffset, tag.BitOffset, tag.DataType,
+			tag.ScanRate, tag.MonitorChanges, tag.CanWrite, tag.Active, tag.WriteRateLimitHz, tag.UnpackBits, labels, tag.MinDelta,
+			tag.ScaleFactor, tag.ScaleOffset, tag.RetentionDays, tag.CreatedAt,
+		)
+		if err != nil {
+			stmt.Close()
+			return nil, fmt.Errorf("erro ao enviar tag %s: %w", tag.Name, err)
+		}
+	}
+
+	if _, err := stmt.ExecContext(ctx); err != nil {
+		stmt.Close()
+		return nil, err
+	}
+	if err := stmt.Close(); err != nil {
+		return nil, err
+	}
+
+	// Buscar os IDs por PLC, já que COPY não tem RETURNING
+	namesByPLC := make(map[int][]string)
+	for _, tag := range tags {
+		namesByPLC[tag.PLCID] = append(namesByPLC[tag.PLCID], tag.Name)
+	}
+
+	type tagKey struct {
+		plcID int
+		name  string
+	}
+	idByKey := make(map[tagKey]int, len(tags))
+	for plcID, names := range namesByPLC {
+		rows, err := tx.QueryContext(ctx,
+			"SELECT id, name FROM plc_tags WHERE plc_id = $1 AND name = ANY($2)", plcID, pq.Array(names))
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			var id int
+			var name string
+			if err := rows.Scan(&id, &name); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			idByKey[tagKey{plcID, name}] = id
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	ids := make([]int, 0, len(tags))
+	for _, tag := range tags {
+		id, ok := idByKey[tagKey{tag.PLCID, tag.Name}]
+		if !ok {
+			return nil, fmt.Errorf("ID da tag %s não encontrado após COPY", tag.Name)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"app_padrao/internal/domain"
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+)
+
+// testDB abre o banco de TEST_DATABASE_URL, que já deve ter o schema da
+// aplicação; sem a variável o teste ou benchmark é ignorado
+func testDB(b testing.TB) *sql.DB {
+	b.Helper()
+
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		b.Skip("TEST_DATABASE_URL não definida")
+	}
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		b.Fatalf("erro ao abrir o banco: %v", err)
+	}
+	b.Cleanup(func() { db.Close() })
+
+	if err := db.Ping(); err != nil {
+		b.Fatalf("erro ao conectar ao banco: %v", err)
+	}
+	return db
+}
+
+// benchmarkTags monta n tags de um PLC com nomes únicos
+func benchmarkTags(plcID, n int) []domain.PLCTag {
+	now := time.Now()
+	tags := make([]domain.PLCTag, n)
+	for i := range tags {
+		tags[i] = domain.PLCTag{
+			PLCID:       plcID,
+			Name:        fmt.Sprintf("bench_tag_%d", i),
+			DBNumber:    1,
+			ByteOffset:  i * 4,
+			DataType:    "real",
+			ScanRate:    1000,
+			Active:      true,
+			ScaleFactor: 1,
+			CreatedAt:   now,
+		}
+	}
+	return tags
+}
+
+// BenchmarkCreatePLCTags compara COPY FROM e um INSERT por tag para cada
+// tamanho de lote. Cada iteração cria um PLC e as tags em uma transação
+// desfeita ao final, deixando o banco como estava.
+//
+//	TEST_DATABASE_URL=postgres://... go test -run '^$' -bench CreatePLCTags ./internal/repository
+func BenchmarkCreatePLCTags(b *testing.B) {
+	db := testDB(b)
+
+	methods := []struct {
+		name   string
+		create func(context.Context, *sql.Tx, []domain.PLCTag) ([]int, error)
+	}{
+		{"insert", insertPLCTags},
+		{"copy", copyPLCTags},
+	}
+
+	for _, size := range []int{10, 50, 100, 500, 1000, 10000} {
+		for _, method := range methods {
+			b.Run(fmt.Sprintf("%s/%d", method.name, size), func(b *testing.B) {
+				ctx := context.Background()
+				for i := 0; i < b.N; i++ {
+					b.StopTimer()
+					tx, err := db.BeginTx(ctx, nil)
+					if err != nil {
+						b.Fatal(err)
+					}
+					var plcID int
+					err = tx.QueryRowContext(ctx,
+						`INSERT INTO plcs (name, ip_address, rack, slot) VALUES ($1, '127.0.0.1', 0, 1) RETURNING id`,
+						fmt.Sprintf("bench_plc_%d", i),
+					).Scan(&plcID)
+					if err != nil {
+						tx.Rollback()
+						b.Fatal(err)
+					}
+					tags := benchmarkTags(plcID, size)
+					b.StartTimer()
+
+					ids, err := method.create(ctx, tx, tags)
+
+					b.StopTimer()
+					tx.Rollback()
+					if err != nil {
+						b.Fatal(err)
+					}
+					if len(ids) != size {
+						b.Fatalf("%d IDs retornados, esperado %d", len(ids), size)
+					}
+					b.StartTimer()
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,635 @@
+package repository
+
+import (
+	"app_padrao/internal/domain"
+	"app_padrao/pkg/plc"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type PLCTagHistoryRepository struct {
+	queryTimeout
+	db         *sql.DB
+	schemaOnce sync.Once
+	schemaErr  error
+}
+
+func NewPLCTagHistoryRepository(db *sql.DB) *PLCTagHistoryRepository {
+	return &PLCTagHistoryRepository{db: db}
+}
+
+// ensureTable cria a tabela tag_history se ainda não existir
+func (r *PLCTagHistoryRepository) ensureTable() error {
+	r.schemaOnce.Do(func() {
+		_, r.schemaErr = r.db.Exec(`
+			CREATE TABLE IF NOT EXISTS tag_history (
+				id BIGSERIAL PRIMARY KEY,
+				plc_id INTEGER NOT NULL,
+				tag_id INTEGER NOT NULL,
+				value JSONB,
+				recorded_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+			);
+			CREATE INDEX IF NOT EXISTS idx_tag_history_tag_time ON tag_history (tag_id, recorded_at);
+		`)
+		if r.schemaErr != nil {
+			log.Printf("Erro ao criar tabela tag_history: %v", r.schemaErr)
+		}
+	})
+	return r.schemaErr
+}
+
+func (r *PLCTagHistoryRepository) Insert(entries []domain.TagHistoryEntry) error {
+	ctx, cancel := r.queryContext()
+	defer cancel()
+
+	if len(entries) == 0 {
+		return nil
+	}
+
+	if err := r.ensureTable(); err != nil {
+		return err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	// COPY FROM é bem mais rápido que INSERTs individuais para lotes grandes
+	stmt, err := tx.PrepareContext(ctx, pq.CopyIn("tag_history", "plc_id", "tag_id", "value", "recorded_at"))
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	for _, entry := range entries {
+		value, err := json.Marshal(entry.Value)
+		if err != nil {
+			stmt.Close()
+			tx.Rollback()
+			return fmt.Errorf("erro ao serializar valor da tag %d: %w", entry.TagID, err)
+		}
+
+		// JSONB é enviado como texto; []byte seria codificado como bytea
+		if _, err := stmt.Exec(entry.PLCID, entry.TagID, string(value), entry.RecordedAt.UTC()); err != nil {
+			stmt.Close()
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if _, err := stmt.Exec(); err != nil {
+		stmt.Close()
+		tx.Rollback()
+		return err
+	}
+
+	if err := stmt.Close(); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *PLCTagHistoryRepository) GetRange(plcID, tagID int, from, to time.Time) ([]domain.TagHistoryEntry, error) {
+	ctx, cancel := r.queryContext()
+	defer cancel()
+
+	return r.getRange(ctx, plcID, tagID, from, to)
+}
+
+// getRange lê os registros da tag no intervalo com o contexto informado
+func (r *PLCTagHistoryRepository) getRange(ctx context.Context, plcID, tagID int, from, to time.Time) ([]domain.TagHistoryEntry, error) {
+	if err := r.ensureTable(); err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT plc_id, tag_id, value, recorded_at
+		FROM tag_history
+		WHERE plc_id = $1 AND tag_id = $2 AND recorded_at BETWEEN $3 AND $4
+		ORDER BY recorded_at
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, plcID, tagID, from.UTC(), to.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []domain.TagHistoryEntry{}
+	for rows.Next() {
+		entry, err := scanTagHistoryEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// GetLatestPairs busca em uma única consulta os dois registros mais recentes
+// de cada tag no intervalo, em ordem cronológica por tag. Tags sem registros
+// ficam fora do mapa.
+func (r *PLCTagHistoryRepository) GetLatestPairs(plcID int, tagIDs []int, from, to time.Time) (map[int][]domain.TagHistoryEntry, error) {
+	pairs := make(map[int][]domain.TagHistoryEntry, len(tagIDs))
+	if len(tagIDs) == 0 {
+		return pairs, nil
+	}
+
+	if err := r.ensureTable(); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := r.queryContext()
+	defer cancel()
+
+	query := `
+		SELECT plc_id, tag_id, value, recorded_at
+		FROM (
+			SELECT plc_id, tag_id, value, recorded_at,
+				ROW_NUMBER() OVER (PARTITION BY tag_id ORDER BY recorded_at DESC) AS rn
+			FROM tag_history
+			WHERE plc_id = $1 AND tag_id = ANY($2) AND recorded_at BETWEEN $3 AND $4
+		) latest
+		WHERE rn <= 2
+		ORDER BY tag_id, recorded_at
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, plcID, pq.Array(tagIDs), from.UTC(), to.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		entry, err := scanTagHistoryEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		pairs[entry.TagID] = append(pairs[entry.TagID], entry)
+	}
+
+	return pairs, rows.Err()
+}
+
+// scanTagHistoryEntry lê um registro (plc_id, tag_id, value, recorded_at)
+func scanTagHistoryEntry(rows *sql.Rows) (domain.TagHistoryEntry, error) {
+	var entry domain.TagHistoryEntry
+	var raw []byte
+
+	if err := rows.Scan(&entry.PLCID, &entry.TagID, &raw, &entry.RecordedAt); err != nil {
+		return entry, err
+	}
+
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &entry.Value); err != nil {
+			return entry, fmt.Errorf("erro ao decodificar valor do histórico: %w", err)
+		}
+	}
+
+	return entry, nil
+}
+
+// StreamRange percorre o histórico dos PLCs informados no intervalo, entregando
+// os registros a fn em lotes de até batchSize sem carregar o resultado inteiro
+// em memória. Com tagID igual a zero, todas as tags dos PLCs são incluídas.
+func (r *PLCTagHistoryRepository) StreamRange(plcIDs []int, tagID int, from, to time.Time, batchSize int, fn func([]domain.TagHistoryEntry) error) error {
+	if len(plcIDs) == 0 {
+		return nil
+	}
+
+	if err := r.ensureTable(); err != nil {
+		return err
+	}
+
+	if batchSize <= 0 {
+		batchSize = 500
+	}
+
+	placeholders := make([]string, len(plcIDs))
+	params := make([]interface{}, 0, len(plcIDs)+3)
+	for i, id := range plcIDs {
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+		params = append(params, id)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT plc_id, tag_id, value, recorded_at
+		FROM tag_history
+		WHERE plc_id IN (%s) AND recorded_at BETWEEN $%d AND $%d
+	`, strings.Join(placeholders, ", "), len(params)+1, len(params)+2)
+	params = append(params, from.UTC(), to.UTC())
+
+	if tagID > 0 {
+		query += fmt.Sprintf(" AND tag_id = $%d", len(params)+1)
+		params = append(params, tagID)
+	}
+	query += " ORDER BY recorded_at, tag_id"
+
+	rows, err := r.db.Query(query, params...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	batch := make([]domain.TagHistoryEntry, 0, batchSize)
+	for rows.Next() {
+		var entry domain.TagHistoryEntry
+		var raw []byte
+
+		if err := rows.Scan(&entry.PLCID, &entry.TagID, &raw, &entry.RecordedAt); err != nil {
+			return err
+		}
+
+		if len(raw) > 0 {
+			if err := json.Unmarshal(raw, &entry.Value); err != nil {
+				return fmt.Errorf("erro ao decodificar valor do histórico: %w", err)
+			}
+		}
+
+		batch = append(batch, entry)
+		if len(batch) == batchSize {
+			if err := fn(batch); err != nil {
+				return err
+			}
+			batch = make([]domain.TagHistoryEntry, 0, batchSize)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if len(batch) > 0 {
+		return fn(batch)
+	}
+
+	return nil
+}
+
+// CountRange conta os registros da tag no intervalo. A contagem para em
+// limit, evitando percorrer o intervalo inteiro só para recusar a consulta.
+func (r *PLCTagHistoryRepository) CountRange(tagID int, from, to time.Time, limit int) (int, error) {
+	ctx, cancel := r.queryContext()
+	defer cancel()
+
+	if err := r.ensureTable(); err != nil {
+		return 0, err
+	}
+
+	var count int
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM (
+			SELECT 1 FROM tag_history
+			WHERE tag_id = $1 AND recorded_at BETWEEN $2 AND $3
+			LIMIT $4
+		) AS limited
+	`, tagID, from.UTC(), to.UTC(), limit).Scan(&count)
+
+	return count, err
+}
+
+// historyPruneLockID identifica o advisory lock da limpeza do histórico
+const historyPruneLockID = 7310
+
+// historyPruneBatchSize limita quantas leituras cada DELETE remove, para que a
+// primeira limpeza não vire uma única transação com milhões de linhas
+const historyPruneBatchSize = 10000
+
+// Prune remove as leituras anteriores a olderThan, exceto as das tags com
+// retenção própria (retention_days > 0), que são removidas após o seu prazo.
+// Com olderThan zero apenas as retenções próprias são aplicadas. A remoção é
+// feita em lotes sob um advisory lock: se outra instância já estiver limpando,
+// retorna domain.ErrHistoryPruneLocked.
+func (r *PLCTagHistoryRepository) Prune(olderThan time.Time) (int64, error) {
+	if err := r.ensureTable(); err != nil {
+		return 0, err
+	}
+
+	// A limpeza pode levar muito tempo: sem o prazo das consultas comuns. O
+	// advisory lock é da sessão, então tudo roda na mesma conexão.
+	ctx := context.Background()
+	conn, err := r.db.Conn(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	var locked bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", historyPruneLockID).Scan(&locked); err != nil {
+		return 0, fmt.Errorf("erro ao obter lock da limpeza do histórico: %w", err)
+	}
+	if !locked {
+		return 0, domain.ErrHistoryPruneLocked
+	}
+	defer func() {
+		if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", historyPruneLockID); err != nil {
+			log.Printf("Aviso: erro ao liberar lock da limpeza do histórico: %v", err)
+		}
+	}()
+
+	var total int64
+
+	if !olderThan.IsZero() {
+		pruned, err := pruneInBatches(ctx, conn, `
+			DELETE FROM tag_history
+			WHERE id IN (
+				SELECT h.id FROM tag_history h
+				WHERE h.recorded_at < $1
+				  AND NOT EXISTS (SELECT 1 FROM plc_tags t WHERE t.id = h.tag_id AND t.retention_days > 0)
+				LIMIT $2
+			)
+		`, olderThan.UTC())
+		total += pruned
+		if err != nil {
+			return total, err
+		}
+	}
+
+	pruned, err := pruneInBatches(ctx, conn, `
+		DELETE FROM tag_history
+		WHERE id IN (
+			SELECT h.id FROM tag_history h
+			JOIN plc_tags t ON t.id = h.tag_id
+			WHERE t.retention_days > 0
+			  AND h.recorded_at < NOW() - make_interval(days => t.retention_days)
+			LIMIT $1
+		)
+	`)
+	total += pruned
+
+	return total, err
+}
+
+// pruneInBatches repete o DELETE até um lote vir incompleto. O tamanho do
+// lote é passado como último parâmetro da consulta.
+func pruneInBatches(ctx context.Context, conn *sql.Conn, query string, args ...interface{}) (int64, error) {
+	args = append(args, historyPruneBatchSize)
+
+	var total int64
+	for {
+		result, err := conn.ExecContext(ctx, query, args...)
+		if err != nil {
+			return total, err
+		}
+		affected, _ := result.RowsAffected()
+		total += affected
+
+		if affected < historyPruneBatchSize {
+			return total, nil
+		}
+	}
+}
+
+// GetStats retorna a quantidade de leituras, a mais antiga e o tamanho da
+// tabela (dados e índices)
+func (r *PLCTagHistoryRepository) GetStats() (domain.TagHistoryStats, error) {
+	ctx, cancel := r.queryContext()
+	defer cancel()
+
+	if err := r.ensureTable(); err != nil {
+		return domain.TagHistoryStats{}, err
+	}
+
+	var stats domain.TagHistoryStats
+	var oldest sql.NullTime
+	var sizeBytes int64
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COUNT(*), MIN(recorded_at), pg_total_relation_size('tag_history')
+		FROM tag_history
+	`).Scan(&stats.TotalRows, &oldest, &sizeBytes)
+	if err != nil {
+		return domain.TagHistoryStats{}, err
+	}
+
+	if oldest.Valid {
+		stats.OldestEntry = &oldest.Time
+	}
+	stats.EstimatedSizeMB = float64(sizeBytes) / (1024 * 1024)
+
+	return stats, nil
+}
+
+// GetChangeStats conta as leituras e as mudanças de valor de cada tag do PLC
+// no intervalo. A primeira leitura de cada tag não conta como mudança.
+func (r *PLCTagHistoryRepository) GetChangeStats(plcID int, from, to time.Time) ([]domain.TagChangeStats, error) {
+	ctx, cancel := r.queryContext()
+	defer cancel()
+
+	if err := r.ensureTable(); err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT tag_id, COUNT(*), COUNT(*) FILTER (WHERE previous IS NOT NULL AND value IS DISTINCT FROM previous)
+		FROM (
+			SELECT tag_id, value, LAG(value) OVER (PARTITION BY tag_id ORDER BY recorded_at) AS previous
+			FROM tag_history
+			WHERE plc_id = $1 AND recorded_at BETWEEN $2 AND $3
+		) h
+		GROUP BY tag_id
+		ORDER BY tag_id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, plcID, from.UTC(), to.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := []domain.TagChangeStats{}
+	for rows.Next() {
+		var s domain.TagChangeStats
+		if err := rows.Scan(&s.TagID, &s.Samples, &s.Changes); err != nil {
+			return nil, err
+		}
+		stats = append(stats, s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// GetInterpolated retorna o histórico da tag em uma série regular com um ponto
+// a cada window a partir de from. Intervalos com leitura real usam a última
+// leitura do intervalo; intervalos vazios recebem um ponto sintético calculado
+// conforme mode. Com InterpolationNone, retorna apenas as leituras reais.
+// Todos os cálculos são feitos em UTC, então mudanças de horário de verão não
+// criam nem removem pontos.
+func (r *PLCTagHistoryRepository) GetInterpolated(ctx context.Context, plcID, tagID int, from, to time.Time, window time.Duration, mode string) ([]domain.TagValue, error) {
+	from, to = from.UTC(), to.UTC()
+
+	ctx, cancel := r.requestContext(ctx)
+	defer cancel()
+
+	entries, err := r.getRange(ctx, plcID, tagID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	if mode == domain.InterpolationNone {
+		values := make([]domain.TagValue, 0, len(entries))
+		for _, entry := range entries {
+			values = append(values, domain.TagValue{
+				PLCID:     entry.PLCID,
+				TagID:     entry.TagID,
+				Value:     entry.Value,
+				Quality:   domain.QualityGood,
+				Timestamp: entry.RecordedAt.UTC(),
+			})
+		}
+		return values, nil
+	}
+
+	if window <= 0 {
+		return nil, fmt.Errorf("janela de interpolação inválida: %v", window)
+	}
+
+	// Leituras imediatamente antes e depois do intervalo servem de âncora
+	// para as bordas da série
+	before, err := r.getAdjacent(plcID, tagID, from, true)
+	if err != nil {
+		return nil, err
+	}
+	after, err := r.getAdjacent(plcID, tagID, to, false)
+	if err != nil {
+		return nil, err
+	}
+
+	points := make([]domain.TagHistoryEntry, 0, len(entries)+2)
+	if before != nil {
+		points = append(points, *before)
+	}
+	points = append(points, entries...)
+	if after != nil {
+		points = append(points, *after)
+	}
+
+	return interpolateSeries(plcID, tagID, points, from, to, window, mode), nil
+}
+
+// getAdjacent busca a última leitura antes de ref (before=true) ou a primeira
+// depois de ref. Retorna nil se não houver.
+func (r *PLCTagHistoryRepository) getAdjacent(plcID, tagID int, ref time.Time, before bool) (*domain.TagHistoryEntry, error) {
+	ctx, cancel := r.queryContext()
+	defer cancel()
+
+	query := `
+		SELECT plc_id, tag_id, value, recorded_at
+		FROM tag_history
+		WHERE plc_id = $1 AND tag_id = $2 AND recorded_at > $3
+		ORDER BY recorded_at
+		LIMIT 1
+	`
+	if before {
+		query = `
+			SELECT plc_id, tag_id, value, recorded_at
+			FROM tag_history
+			WHERE plc_id = $1 AND tag_id = $2 AND recorded_at < $3
+			ORDER BY recorded_at DESC
+			LIMIT 1
+		`
+	}
+
+	var entry domain.TagHistoryEntry
+	var raw []byte
+	err := r.db.QueryRowContext(ctx, query, plcID, tagID, ref.UTC()).Scan(&entry.PLCID, &entry.TagID, &raw, &entry.RecordedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &entry.Value); err != nil {
+			return nil, fmt.Errorf("erro ao decodificar valor do histórico: %w", err)
+		}
+	}
+
+	return &entry, nil
+}
+
+// interpolateSeries monta a série regular a partir das leituras ordenadas por
+// recorded_at. Pontos que não podem ser calculados (sem leitura anterior, ou
+// sem próxima leitura no modo linear) são omitidos.
+func interpolateSeries(plcID, tagID int, points []domain.TagHistoryEntry, from, to time.Time, window time.Duration, mode string) []domain.TagValue {
+	series := []domain.TagValue{}
+	next := 0
+	var prev *domain.TagHistoryEntry
+
+	for t := from; !t.After(to); t = t.Add(window) {
+		end := t.Add(window)
+
+		// Avançar sobre as leituras anteriores ao fim do intervalo [t, end)
+		var real *domain.TagHistoryEntry
+		for next < len(points) && points[next].RecordedAt.Before(end) {
+			if !points[next].RecordedAt.Before(t) {
+				real = &points[next]
+			}
+			prev = &points[next]
+			next++
+		}
+
+		if real != nil {
+			series = append(series, domain.TagValue{
+				PLCID:     plcID,
+				TagID:     tagID,
+				Value:     real.Value,
+				Quality:   domain.QualityGood,
+				Timestamp: t,
+			})
+			continue
+		}
+
+		if prev == nil {
+			continue
+		}
+
+		value := prev.Value
+		if mode == domain.InterpolationLinear {
+			if next >= len(points) {
+				continue
+			}
+			following := points[next]
+
+			v1, ok1 := plc.ToFloat64(prev.Value)
+			v2, ok2 := plc.ToFloat64(following.Value)
+			span := following.RecordedAt.Sub(prev.RecordedAt)
+			if !ok1 || !ok2 || span <= 0 {
+				continue
+			}
+			value = v1 + (v2-v1)*float64(t.Sub(prev.RecordedAt))/float64(span)
+		}
+
+		series = append(series, domain.TagValue{
+			PLCID:        plcID,
+			TagID:        tagID,
+			Value:        value,
+			Quality:      domain.QualityUncertain,
+			Timestamp:    t,
+			Interpolated: true,
+		})
+	}
+
+	return series
+}
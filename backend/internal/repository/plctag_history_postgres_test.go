@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"app_padrao/internal/domain"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPruneDeletesInBatchesUnderLock(t *testing.T) {
+	db := testDB(t)
+	repo := NewPLCTagHistoryRepository(db)
+	if err := repo.ensureTable(); err != nil {
+		t.Fatalf("erro ao criar tag_history: %v", err)
+	}
+
+	// Leituras antigas de um PLC inexistente, em mais de um lote
+	old := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	rows := historyPruneBatchSize + 50
+	if _, err := db.Exec(`
+		INSERT INTO tag_history (plc_id, tag_id, value, recorded_at)
+		SELECT -1, -g, '1', $1 FROM generate_series(1, $2) AS g
+	`, old, rows); err != nil {
+		t.Fatalf("erro ao inserir histórico: %v", err)
+	}
+	t.Cleanup(func() { db.Exec("DELETE FROM tag_history WHERE plc_id = -1") })
+
+	// Com o lock em outra sessão a limpeza é recusada
+	ctx := context.Background()
+	other, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatalf("erro ao abrir conexão: %v", err)
+	}
+	defer other.Close()
+	if _, err := other.ExecContext(ctx, "SELECT pg_advisory_lock($1)", historyPruneLockID); err != nil {
+		t.Fatalf("erro ao obter lock: %v", err)
+	}
+
+	if _, err := repo.Prune(old.Add(time.Hour)); !errors.Is(err, domain.ErrHistoryPruneLocked) {
+		t.Fatalf("erro = %v, esperado ErrHistoryPruneLocked", err)
+	}
+
+	if _, err := other.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", historyPruneLockID); err != nil {
+		t.Fatalf("erro ao liberar lock: %v", err)
+	}
+
+	pruned, err := repo.Prune(old.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if pruned < int64(rows) {
+		t.Errorf("removidas = %d, esperado pelo menos %d", pruned, rows)
+	}
+
+	var left int
+	db.QueryRow("SELECT COUNT(*) FROM tag_history WHERE plc_id = -1").Scan(&left)
+	if left != 0 {
+		t.Errorf("restaram %d leituras antigas, esperado 0", left)
+	}
+}
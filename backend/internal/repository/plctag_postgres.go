@@ -2,32 +2,143 @@ package repository
 
 import (
 	"app_padrao/internal/domain"
+	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"log"
+	"strings"
 	"time"
+
+	"github.com/lib/pq"
 )
 
+// plcTagColumns lista as colunas lidas em todas as consultas de tags, na ordem
+// esperada por scanPLCTag
+const plcTagColumns = `id, plc_id, name, description, db_number, byte_offset, bit_offset, data_type,
+			   scan_rate, monitor_changes, can_write, active, write_rate_limit_hz, unpack_bits, bit_labels,
+			   min_delta, scale_factor, scale_offset, last_written_at, last_written_by,
+			   auto_disabled_at, last_read_error, retention_days, scan_group_id, created_at, updated_at`
+
 type PLCTagRepository struct {
 	db *sql.DB
+	queryTimeout
 }
 
 func NewPLCTagRepository(db *sql.DB) *PLCTagRepository {
-	return &PLCTagRepository{db: db}
+	r := &PLCTagRepository{db: db}
+	r.ensureSchema()
+	return r
 }
 
-func (r *PLCTagRepository) GetByID(id int) (domain.PLCTag, error) {
-	query := `
-		SELECT id, plc_id, name, description, db_number, byte_offset, bit_offset, data_type,
-			   scan_rate, monitor_changes, can_write, active, created_at, updated_at
-		FROM plc_tags
-		WHERE id = $1
-	`
+// ensureSchema adiciona colunas novas à tabela plc_tags quando ainda não existem
+func (r *PLCTagRepository) ensureSchema() {
+	if r.db == nil {
+		return
+	}
+
+	_, err := r.db.Exec(`ALTER TABLE plc_tags ADD COLUMN IF NOT EXISTS write_rate_limit_hz DOUBLE PRECISION NOT NULL DEFAULT 0`)
+	if err != nil {
+		log.Printf("Erro ao adicionar coluna write_rate_limit_hz: %v", err)
+	}
+
+	_, err = r.db.Exec(`
+		ALTER TABLE plc_tags
+			ADD COLUMN IF NOT EXISTS unpack_bits BOOLEAN NOT NULL DEFAULT false,
+			ADD COLUMN IF NOT EXISTS bit_labels JSONB
+	`)
+	if err != nil {
+		log.Printf("Erro ao adicionar colunas de bits empacotados: %v", err)
+	}
+
+	_, err = r.db.Exec(`ALTER TABLE plc_tags ADD COLUMN IF NOT EXISTS min_delta DOUBLE PRECISION NOT NULL DEFAULT 0`)
+	if err != nil {
+		log.Printf("Erro ao adicionar coluna min_delta: %v", err)
+	}
+
+	_, err = r.db.Exec(`
+		ALTER TABLE plc_tags
+			ADD COLUMN IF NOT EXISTS scale_factor DOUBLE PRECISION NOT NULL DEFAULT 1,
+			ADD COLUMN IF NOT EXISTS scale_offset DOUBLE PRECISION NOT NULL DEFAULT 0
+	`)
+	if err != nil {
+		log.Printf("Erro ao adicionar colunas de escala: %v", err)
+	}
+
+	_, err = r.db.Exec(`
+		ALTER TABLE plc_tags
+			ADD COLUMN IF NOT EXISTS last_written_at TIMESTAMP,
+			ADD COLUMN IF NOT EXISTS last_written_by INTEGER
+	`)
+	if err != nil {
+		log.Printf("Erro ao adicionar colunas de última escrita: %v", err)
+	}
+
+	_, err = r.db.Exec(`
+		ALTER TABLE plc_tags
+			ADD COLUMN IF NOT EXISTS auto_disabled_at TIMESTAMP,
+			ADD COLUMN IF NOT EXISTS last_read_error TEXT
+	`)
+	if err != nil {
+		log.Printf("Erro ao adicionar colunas de desativação automática: %v", err)
+	}
+
+	_, err = r.db.Exec(`ALTER TABLE plc_tags ADD COLUMN IF NOT EXISTS retention_days INTEGER NOT NULL DEFAULT 0`)
+	if err != nil {
+		log.Printf("Erro ao adicionar coluna retention_days: %v", err)
+	}
+
+	// Grupos de varredura precisam existir antes da chave estrangeira scan_group_id
+	ensurePLCScanGroupsTable(r.db)
+
+	_, err = r.db.Exec(`ALTER TABLE plc_tags ADD COLUMN IF NOT EXISTS scan_group_id INTEGER REFERENCES plc_scan_groups(id) ON DELETE SET NULL`)
+	if err != nil {
+		log.Printf("Erro ao adicionar coluna scan_group_id: %v", err)
+	}
+
+	// byte_offset é sempre inteiro; bases antigas podem ter a coluna como NUMERIC
+	byteOffsetType, err := r.byteOffsetColumnType()
+	if err == nil && byteOffsetType != "integer" {
+		_, err = r.db.Exec(`ALTER TABLE plc_tags ALTER COLUMN byte_offset TYPE INTEGER USING byte_offset::integer`)
+		if err != nil {
+			log.Printf("Erro ao converter coluna byte_offset para INTEGER: %v", err)
+		}
+
+		// Ler um byte_offset fracionário como int truncaria o endereço em
+		// silêncio: não iniciar com a coluna em outro tipo
+		if byteOffsetType, err = r.byteOffsetColumnType(); err == nil && byteOffsetType != "integer" {
+			panic(fmt.Sprintf("plc_tags.byte_offset tem tipo %q e não pôde ser convertida para integer; "+
+				"execute manualmente: ALTER TABLE plc_tags ALTER COLUMN byte_offset TYPE INTEGER USING byte_offset::integer",
+				byteOffsetType))
+		}
+	}
+}
 
+// byteOffsetColumnType retorna o tipo da coluna plc_tags.byte_offset
+func (r *PLCTagRepository) byteOffsetColumnType() (string, error) {
+	var dataType string
+	err := r.db.QueryRow(`
+		SELECT data_type FROM information_schema.columns
+		WHERE table_name = 'plc_tags' AND column_name = 'byte_offset'
+	`).Scan(&dataType)
+	return dataType, err
+}
+
+// rowScanner é satisfeito por *sql.Row e *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanPLCTag lê uma linha com as colunas de plcTagColumns
+func scanPLCTag(row rowScanner) (domain.PLCTag, error) {
 	var tag domain.PLCTag
-	var updatedAt sql.NullTime
-	var description sql.NullString
+	var updatedAt, lastWrittenAt, autoDisabledAt sql.NullTime
+	var lastWrittenBy, scanGroupID sql.NullInt64
+	var description, lastReadError sql.NullString
+	var bitLabels []byte
 
-	err := r.db.QueryRow(query, id).Scan(
+	err := row.Scan(
 		&tag.ID,
 		&tag.PLCID,
 		&tag.Name,
@@ -40,17 +151,31 @@ func (r *PLCTagRepository) GetByID(id int) (domain.PLCTag, error) {
 		&tag.MonitorChanges,
 		&tag.CanWrite,
 		&tag.Active,
+		&tag.WriteRateLimitHz,
+		&tag.UnpackBits,
+		&bitLabels,
+		&tag.MinDelta,
+		&tag.ScaleFactor,
+		&tag.ScaleOffset,
+		&lastWrittenAt,
+		&lastWrittenBy,
+		&autoDisabledAt,
+		&lastReadError,
+		&tag.RetentionDays,
+		&scanGroupID,
 		&tag.CreatedAt,
 		&updatedAt,
 	)
-
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return domain.PLCTag{}, domain.ErrPLCTagNotFound
-		}
 		return domain.PLCTag{}, err
 	}
 
+	if len(bitLabels) > 0 {
+		if err := json.Unmarshal(bitLabels, &tag.BitLabels); err != nil {
+			return domain.PLCTag{}, fmt.Errorf("bit_labels inválido na tag %d: %w", tag.ID, err)
+		}
+	}
+
 	if description.Valid {
 		tag.Description = description.String
 	}
@@ -59,18 +184,46 @@ func (r *PLCTagRepository) GetByID(id int) (domain.PLCTag, error) {
 		tag.UpdatedAt = updatedAt.Time
 	}
 
+	if lastWrittenAt.Valid {
+		tag.LastWrittenAt = &lastWrittenAt.Time
+	}
+
+	if lastWrittenBy.Valid {
+		userID := int(lastWrittenBy.Int64)
+		tag.LastWrittenBy = &userID
+	}
+
+	if autoDisabledAt.Valid {
+		tag.AutoDisabledAt = &autoDisabledAt.Time
+	}
+
+	if lastReadError.Valid {
+		tag.LastReadError = lastReadError.String
+	}
+
+	if scanGroupID.Valid {
+		groupID := int(scanGroupID.Int64)
+		tag.ScanGroupID = &groupID
+	}
+
 	return tag, nil
 }
 
-func (r *PLCTagRepository) GetByName(name string) ([]domain.PLCTag, error) {
-	query := `
-		SELECT id, plc_id, name, description, db_number, byte_offset, bit_offset, data_type,
-			   scan_rate, monitor_changes, can_write, active, created_at, updated_at
-		FROM plc_tags
-		WHERE name = $1
-	`
+// marshalBitLabels serializa os rótulos de bits para a coluna JSONB (NULL se vazio)
+func marshalBitLabels(labels map[int]string) (interface{}, error) {
+	if len(labels) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal(labels)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao serializar bit_labels: %w", err)
+	}
+	return data, nil
+}
 
-	rows, err := r.db.Query(query, name)
+// queryTags executa uma consulta de tags e lê todas as linhas
+func (r *PLCTagRepository) queryTags(ctx context.Context, query string, args ...interface{}) ([]domain.PLCTag, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -78,39 +231,10 @@ func (r *PLCTagRepository) GetByName(name string) ([]domain.PLCTag, error) {
 
 	var tags []domain.PLCTag
 	for rows.Next() {
-		var tag domain.PLCTag
-		var updatedAt sql.NullTime
-		var description sql.NullString
-
-		err := rows.Scan(
-			&tag.ID,
-			&tag.PLCID,
-			&tag.Name,
-			&description,
-			&tag.DBNumber,
-			&tag.ByteOffset,
-			&tag.BitOffset,
-			&tag.DataType,
-			&tag.ScanRate,
-			&tag.MonitorChanges,
-			&tag.CanWrite,
-			&tag.Active,
-			&tag.CreatedAt,
-			&updatedAt,
-		)
-
+		tag, err := scanPLCTag(rows)
 		if err != nil {
 			return nil, err
 		}
-
-		if description.Valid {
-			tag.Description = description.String
-		}
-
-		if updatedAt.Valid {
-			tag.UpdatedAt = updatedAt.Time
-		}
-
 		tags = append(tags, tag)
 	}
 
@@ -121,78 +245,79 @@ func (r *PLCTagRepository) GetByName(name string) ([]domain.PLCTag, error) {
 	return tags, nil
 }
 
-func (r *PLCTagRepository) GetPLCTags(plcID int) ([]domain.PLCTag, error) {
-	query := `
-		SELECT id, plc_id, name, description, db_number, byte_offset, bit_offset, data_type,
-			   scan_rate, monitor_changes, can_write, active, created_at, updated_at
+func (r *PLCTagRepository) GetByID(id int) (domain.PLCTag, error) {
+	ctx, cancel := r.queryContext()
+	defer cancel()
+
+	query := `SELECT ` + plcTagColumns + `
 		FROM plc_tags
-		WHERE plc_id = $1
-		ORDER BY name
+		WHERE id = $1
 	`
 
-	rows, err := r.db.Query(query, plcID)
+	tag, err := scanPLCTag(r.db.QueryRowContext(ctx, query, id))
 	if err != nil {
-		return nil, err
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.PLCTag{}, domain.ErrPLCTagNotFound
+		}
+		return domain.PLCTag{}, err
 	}
-	defer rows.Close()
 
-	var tags []domain.PLCTag
-	for rows.Next() {
-		var tag domain.PLCTag
-		var updatedAt sql.NullTime
-		var description sql.NullString
-
-		err := rows.Scan(
-			&tag.ID,
-			&tag.PLCID,
-			&tag.Name,
-			&description,
-			&tag.DBNumber,
-			&tag.ByteOffset,
-			&tag.BitOffset,
-			&tag.DataType,
-			&tag.ScanRate,
-			&tag.MonitorChanges,
-			&tag.CanWrite,
-			&tag.Active,
-			&tag.CreatedAt,
-			&updatedAt,
-		)
+	return tag, nil
+}
 
-		if err != nil {
-			return nil, err
-		}
+func (r *PLCTagRepository) GetByName(name string) ([]domain.PLCTag, error) {
+	ctx, cancel := r.queryContext()
+	defer cancel()
 
-		if description.Valid {
-			tag.Description = description.String
-		}
+	query := `SELECT ` + plcTagColumns + `
+		FROM plc_tags
+		WHERE name = $1
+	`
 
-		if updatedAt.Valid {
-			tag.UpdatedAt = updatedAt.Time
-		}
+	return r.queryTags(ctx, query, name)
+}
 
-		tags = append(tags, tag)
-	}
+func (r *PLCTagRepository) GetPLCTags(plcID int) ([]domain.PLCTag, error) {
+	ctx, cancel := r.queryContext()
+	defer cancel()
 
-	if err = rows.Err(); err != nil {
-		return nil, err
-	}
+	query := `SELECT ` + plcTagColumns + `
+		FROM plc_tags
+		WHERE plc_id = $1
+		ORDER BY name
+	`
 
-	return tags, nil
+	return r.queryTags(ctx, query, plcID)
 }
 
 func (r *PLCTagRepository) Create(tag domain.PLCTag) (int, error) {
+	ctx, cancel := r.queryContext()
+	defer cancel()
+
+	return insertPLCTag(ctx, r.db, tag)
+}
+
+// insertPLCTag insere uma tag usando o banco ou uma transação em andamento
+func insertPLCTag(ctx context.Context, q interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}, tag domain.PLCTag) (int, error) {
 	query := `
 		INSERT INTO plc_tags (
 			plc_id, name, description, db_number, byte_offset, bit_offset, data_type,
-			scan_rate, monitor_changes, can_write, active, created_at
+			scan_rate, monitor_changes, can_write, active, write_rate_limit_hz, unpack_bits, bit_labels, min_delta,
+			scale_factor, scale_offset, retention_days, created_at
 		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19)
 		RETURNING id
 	`
 
+	bitLabels, err := marshalBitLabels(tag.BitLabels)
+	if err != nil {
+		return 0, err
+	}
+
 	var id int
-	err := r.db.QueryRow(
+	err = q.QueryRowContext(ctx,
 		query,
 		tag.PLCID,
 		tag.Name,
@@ -205,6 +330,13 @@ func (r *PLCTagRepository) Create(tag domain.PLCTag) (int, error) {
 		tag.MonitorChanges,
 		tag.CanWrite,
 		tag.Active,
+		tag.WriteRateLimitHz,
+		tag.UnpackBits,
+		bitLabels,
+		tag.MinDelta,
+		tag.ScaleFactor,
+		tag.ScaleOffset,
+		tag.RetentionDays,
 		tag.CreatedAt,
 	).Scan(&id)
 
@@ -215,16 +347,91 @@ func (r *PLCTagRepository) Create(tag domain.PLCTag) (int, error) {
 	return id, nil
 }
 
+// CountByPLC retorna quantas tags o PLC possui
+func (r *PLCTagRepository) CountByPLC(plcID int) (int, error) {
+	ctx, cancel := r.queryContext()
+	defer cancel()
+
+	var count int
+	err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM plc_tags WHERE plc_id = $1", plcID).Scan(&count)
+	return count, err
+}
+
+// BulkCreate insere as tags em uma única transação, com COPY FROM a partir de
+// copyBulkCreateThreshold tags. A linha do PLC fica bloqueada (FOR UPDATE)
+// durante a contagem e a inserção, para que duas importações simultâneas não
+// ultrapassem juntas o limite.
+func (r *PLCTagRepository) BulkCreate(plcID int, tags []domain.PLCTag, maxTags int) ([]int, error) {
+	ctx, cancel := r.queryContext()
+	defer cancel()
+
+	if len(tags) == 0 {
+		return []int{}, nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var lockedID int
+	if err := tx.QueryRowContext(ctx, "SELECT id FROM plcs WHERE id = $1 FOR UPDATE", plcID).Scan(&lockedID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrPLCNotFound
+		}
+		return nil, err
+	}
+
+	if maxTags > 0 {
+		var count int
+		if err := tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM plc_tags WHERE plc_id = $1", plcID).Scan(&count); err != nil {
+			return nil, err
+		}
+		if count+len(tags) > maxTags {
+			return nil, fmt.Errorf("%w: PLC %d possui %d tags, o lote de %d ultrapassa o limite de %d",
+				domain.ErrTagLimitExceeded, plcID, count, len(tags), maxTags)
+		}
+	}
+
+	batch := make([]domain.PLCTag, len(tags))
+	for i, tag := range tags {
+		tag.PLCID = plcID
+		batch[i] = tag
+	}
+
+	// Lotes grandes (importações) vão por COPY FROM
+	ids, err := createPLCTags(ctx, tx, batch)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
 func (r *PLCTagRepository) Update(tag domain.PLCTag) error {
+	ctx, cancel := r.queryContext()
+	defer cancel()
+
 	query := `
 		UPDATE plc_tags
 		SET plc_id = $1, name = $2, description = $3, db_number = $4, byte_offset = $5,
 			bit_offset = $6, data_type = $7, scan_rate = $8, monitor_changes = $9, can_write = $10,
-			active = $11, updated_at = $12
-		WHERE id = $13
+			active = $11, write_rate_limit_hz = $12, unpack_bits = $13, bit_labels = $14, min_delta = $15,
+			scale_factor = $16, scale_offset = $17, retention_days = $18, scan_group_id = $19, updated_at = $20
+		WHERE id = $21
 	`
 
-	result, err := r.db.Exec(
+	bitLabels, err := marshalBitLabels(tag.BitLabels)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.ExecContext(ctx,
 		query,
 		tag.PLCID,
 		tag.Name,
@@ -237,6 +444,14 @@ func (r *PLCTagRepository) Update(tag domain.PLCTag) error {
 		tag.MonitorChanges,
 		tag.CanWrite,
 		tag.Active,
+		tag.WriteRateLimitHz,
+		tag.UnpackBits,
+		bitLabels,
+		tag.MinDelta,
+		tag.ScaleFactor,
+		tag.ScaleOffset,
+		tag.RetentionDays,
+		nullableInt(tag.ScanGroupID),
 		time.Now(),
 		tag.ID,
 	)
@@ -257,10 +472,95 @@ func (r *PLCTagRepository) Update(tag domain.PLCTag) error {
 	return nil
 }
 
+// UpdateLastWrite registra a última escrita bem-sucedida (userID 0 = sistema)
+func (r *PLCTagRepository) UpdateLastWrite(tagID, userID int, t time.Time) error {
+	ctx, cancel := r.queryContext()
+	defer cancel()
+
+	var writtenBy interface{}
+	if userID > 0 {
+		writtenBy = userID
+	}
+
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE plc_tags SET last_written_at = $1, last_written_by = $2 WHERE id = $3`,
+		t, writtenBy, tagID,
+	)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return domain.ErrPLCTagNotFound
+	}
+
+	return nil
+}
+
 func (r *PLCTagRepository) Delete(id int) error {
+	ctx, cancel := r.queryContext()
+	defer cancel()
+
 	query := "DELETE FROM plc_tags WHERE id = $1"
 
-	result, err := r.db.Exec(query, id)
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return domain.ErrPLCTagNotFound
+	}
+
+	return nil
+}
+
+// SetAutoDisabled tira a tag da varredura registrando o erro de leitura que
+// causou a desativação, sem alterar o campo active do operador
+func (r *PLCTagRepository) SetAutoDisabled(tagID int, lastError string, at time.Time) error {
+	ctx, cancel := r.queryContext()
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE plc_tags SET auto_disabled_at = $1, last_read_error = $2, updated_at = $1 WHERE id = $3`,
+		at, lastError, tagID,
+	)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return domain.ErrPLCTagNotFound
+	}
+
+	return nil
+}
+
+// ClearAutoDisabled limpa o registro da desativação automática; o campo
+// active, controlado pelo operador, não é alterado
+func (r *PLCTagRepository) ClearAutoDisabled(tagID int) error {
+	ctx, cancel := r.queryContext()
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE plc_tags SET auto_disabled_at = NULL, last_read_error = NULL, updated_at = $1 WHERE id = $2`,
+		time.Now(), tagID,
+	)
 	if err != nil {
 		return err
 	}
@@ -276,3 +576,259 @@ func (r *PLCTagRepository) Delete(id int) error {
 
 	return nil
 }
+
+// GetAutoDisabled lista as tags desativadas automaticamente, das mais recentes
+// para as mais antigas
+func (r *PLCTagRepository) GetAutoDisabled() ([]domain.PLCTag, error) {
+	ctx, cancel := r.queryContext()
+	defer cancel()
+
+	query := `SELECT ` + plcTagColumns + `
+		FROM plc_tags
+		WHERE auto_disabled_at IS NOT NULL
+		ORDER BY auto_disabled_at DESC, id`
+
+	return r.queryTags(ctx, query)
+}
+
+// DeleteByPLCID exclui todas as tags do PLC em um único comando
+func (r *PLCTagRepository) DeleteByPLCID(plcID int) (int, error) {
+	ctx, cancel := r.queryContext()
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, "DELETE FROM plc_tags WHERE plc_id = $1", plcID)
+	if err != nil {
+		return 0, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(rowsAffected), nil
+}
+
+// DeleteMany exclui as tags informadas em uma única transação e retorna os IDs
+// efetivamente excluídos
+func (r *PLCTagRepository) DeleteMany(ids []int) ([]int, error) {
+	ctx, cancel := r.queryContext()
+	defer cancel()
+
+	if len(ids) == 0 {
+		return []int{}, nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.QueryContext(ctx, "DELETE FROM plc_tags WHERE id = ANY($1) RETURNING id", pq.Array(ids))
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	deleted := []int{}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			tx.Rollback()
+			return nil, err
+		}
+		deleted = append(deleted, id)
+	}
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return deleted, nil
+}
+
+// BulkUpdate aplica o patch a todas as tags do PLC que atendem ao filtro em
+// um único UPDATE e retorna quantas foram alteradas
+func (r *PLCTagRepository) BulkUpdate(plcID int, filter domain.TagFilter, patch domain.TagPatch) (int, error) {
+	ctx, cancel := r.queryContext()
+	defer cancel()
+
+	sets := []string{}
+	params := []interface{}{plcID}
+
+	addSet := func(column string, value interface{}) {
+		params = append(params, value)
+		sets = append(sets, fmt.Sprintf("%s = $%d", column, len(params)))
+	}
+
+	if patch.ScanRate != nil {
+		addSet("scan_rate", *patch.ScanRate)
+	}
+	if patch.Active != nil {
+		addSet("active", *patch.Active)
+	}
+	if patch.MonitorChanges != nil {
+		addSet("monitor_changes", *patch.MonitorChanges)
+	}
+	if patch.CanWrite != nil {
+		addSet("can_write", *patch.CanWrite)
+	}
+
+	if len(sets) == 0 {
+		return 0, domain.ErrEmptyTagPatch
+	}
+	addSet("updated_at", time.Now())
+
+	conditions := []string{"plc_id = $1"}
+	if filter.DataType != "" {
+		params = append(params, filter.DataType)
+		conditions = append(conditions, fmt.Sprintf("data_type = $%d", len(params)))
+	}
+	if filter.Active != nil {
+		params = append(params, *filter.Active)
+		conditions = append(conditions, fmt.Sprintf("active = $%d", len(params)))
+	}
+
+	query := fmt.Sprintf("UPDATE plc_tags SET %s WHERE %s",
+		strings.Join(sets, ", "), strings.Join(conditions, " AND "))
+
+	result, err := r.db.ExecContext(ctx, query, params...)
+	if err != nil {
+		return 0, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(rowsAffected), nil
+}
+
+// GetIdleTags retorna as tags ativas cujo último registro em tag_history é
+// anterior a since. Tags sem histórico contam como ociosas se foram criadas
+// antes desse instante.
+func (r *PLCTagRepository) GetIdleTags(since time.Duration) ([]domain.PLCTag, error) {
+	ctx, cancel := r.queryContext()
+	defer cancel()
+
+	cutoff := time.Now().Add(-since)
+	query := `SELECT ` + plcTagColumns + `
+		FROM plc_tags t
+		LEFT JOIN (
+			SELECT tag_id, MAX(recorded_at) AS last_recorded
+			FROM tag_history
+			GROUP BY tag_id
+		) h ON h.tag_id = t.id
+		WHERE t.active = true
+		  AND t.auto_disabled_at IS NULL
+		  AND COALESCE(h.last_recorded, t.created_at) < $1
+		ORDER BY t.scan_rate ASC, t.id`
+
+	return r.queryTags(ctx, query, cutoff)
+}
+
+// UpdateScanRates altera o scan_rate de várias tags em uma única transação
+func (r *PLCTagRepository) UpdateScanRates(rates map[int]int) error {
+	ctx, cancel := r.queryContext()
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	for id, rate := range rates {
+		result, err := tx.ExecContext(ctx,
+			"UPDATE plc_tags SET scan_rate = $1, updated_at = $2 WHERE id = $3", rate, now, id)
+		if err != nil {
+			return err
+		}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rowsAffected == 0 {
+			return fmt.Errorf("tag %d: %w", id, domain.ErrPLCTagNotFound)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Search busca tags por texto livre e filtros opcionais, com paginação.
+// Retorna as tags da página e o total de registros encontrados.
+func (r *PLCTagRepository) Search(ctx context.Context, filter domain.TagSearchFilter) ([]domain.PLCTag, int, error) {
+	ctx, cancel := r.requestContext(ctx)
+	defer cancel()
+
+	conditions := []string{}
+	params := []interface{}{}
+	paramIndex := 1
+
+	if filter.Query != "" {
+		conditions = append(conditions, fmt.Sprintf(
+			"(name ILIKE $%d OR description ILIKE $%d OR data_type ILIKE $%d OR "+
+				"to_tsvector('simple', name || ' ' || COALESCE(description, '')) @@ plainto_tsquery('simple', $%d))",
+			paramIndex, paramIndex, paramIndex, paramIndex+1))
+		params = append(params, "%"+escapeLike(filter.Query)+"%", filter.Query)
+		paramIndex += 2
+	}
+
+	if filter.PLCID != nil {
+		conditions = append(conditions, fmt.Sprintf("plc_id = $%d", paramIndex))
+		params = append(params, *filter.PLCID)
+		paramIndex++
+	}
+
+	if filter.DataType != "" {
+		conditions = append(conditions, fmt.Sprintf("data_type = $%d", paramIndex))
+		params = append(params, filter.DataType)
+		paramIndex++
+	}
+
+	if filter.Active != nil {
+		conditions = append(conditions, fmt.Sprintf("active = $%d", paramIndex))
+		params = append(params, *filter.Active)
+		paramIndex++
+	}
+
+	whereClause := ""
+	if len(conditions) > 0 {
+		whereClause = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int
+	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM plc_tags %s", whereClause)
+	if err := r.db.QueryRowContext(ctx, countQuery, params...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	if total == 0 {
+		return []domain.PLCTag{}, 0, nil
+	}
+
+	query := fmt.Sprintf(`SELECT %s
+		FROM plc_tags
+		%s
+		ORDER BY plc_id, name
+		LIMIT $%d OFFSET $%d
+	`, plcTagColumns, whereClause, paramIndex, paramIndex+1)
+	params = append(params, filter.PageSize, (filter.Page-1)*filter.PageSize)
+
+	tags, err := r.queryTags(ctx, query, params...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return tags, total, nil
+}
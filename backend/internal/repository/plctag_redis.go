@@ -6,7 +6,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-redis/redis/v8"
@@ -14,18 +16,31 @@ import (
 
 // PLCTagRedisRepository implementa a interface PLCTagRepository usando Redis
 type PLCTagRedisRepository struct {
-	client *redis.Client
-	ctx    context.Context
+	client    *redis.Client
+	ctx       context.Context
+	keyPrefix string // Namespace da instância (REDIS_KEY_PREFIX)
 }
 
 // NewPLCTagRedisRepository cria um novo repositório Redis para tags de PLCs
 func NewPLCTagRedisRepository(client *redis.Client) *PLCTagRedisRepository {
+	return NewPLCTagRedisRepositoryWithPrefix(client, "")
+}
+
+// NewPLCTagRedisRepositoryWithPrefix cria o repositório prefixando todas as chaves com
+// o namespace informado (ex.: "tenant1:")
+func NewPLCTagRedisRepositoryWithPrefix(client *redis.Client, keyPrefix string) *PLCTagRedisRepository {
 	return &PLCTagRedisRepository{
-		client: client,
-		ctx:    context.Background(),
+		client:    client,
+		ctx:       context.Background(),
+		keyPrefix: keyPrefix,
 	}
 }
 
+// key aplica o namespace da instância a uma chave ou prefixo padronizado
+func (r *PLCTagRedisRepository) key(base string) string {
+	return r.keyPrefix + base
+}
+
 // chavesPadronizadas para garantir consistência
 const (
 	tagKeyPrefix      = "plctag:"
@@ -37,7 +52,7 @@ const (
 
 // GetByID busca uma tag pelo ID no Redis
 func (r *PLCTagRedisRepository) GetByID(id int) (domain.PLCTag, error) {
-	key := fmt.Sprintf("%s%d", tagKeyPrefix, id)
+	key := fmt.Sprintf("%s%d", r.key(tagKeyPrefix), id)
 
 	data, err := r.client.Get(r.ctx, key).Result()
 	if err != nil {
@@ -57,7 +72,7 @@ func (r *PLCTagRedisRepository) GetByID(id int) (domain.PLCTag, error) {
 
 // GetByName busca tags pelo nome no Redis
 func (r *PLCTagRedisRepository) GetByName(name string) ([]domain.PLCTag, error) {
-	key := fmt.Sprintf("%s%s", tagsByNamePrefix, name)
+	key := fmt.Sprintf("%s%s", r.key(tagsByNamePrefix), name)
 
 	// Buscar IDs das tags com este nome
 	ids, err := r.client.SMembers(r.ctx, key).Result()
@@ -87,7 +102,7 @@ func (r *PLCTagRedisRepository) GetByName(name string) ([]domain.PLCTag, error)
 
 // GetPLCTags busca todas as tags de um PLC específico
 func (r *PLCTagRedisRepository) GetPLCTags(plcID int) ([]domain.PLCTag, error) {
-	key := fmt.Sprintf("%s%d", tagsByPLCPrefix, plcID)
+	key := fmt.Sprintf("%s%d", r.key(tagsByPLCPrefix), plcID)
 
 	// Buscar IDs das tags deste PLC
 	ids, err := r.client.SMembers(r.ctx, key).Result()
@@ -122,7 +137,7 @@ func (r *PLCTagRedisRepository) Create(tag domain.PLCTag) (int, error) {
 		return 0, fmt.Errorf("ID de tag inválido para criação no Redis")
 	}
 
-	key := fmt.Sprintf("%s%d", tagKeyPrefix, tag.ID)
+	key := fmt.Sprintf("%s%d", r.key(tagKeyPrefix), tag.ID)
 
 	// Serializar tag para JSON
 	data, err := json.Marshal(tag)
@@ -135,14 +150,14 @@ func (r *PLCTagRedisRepository) Create(tag domain.PLCTag) (int, error) {
 	pipe.Set(r.ctx, key, data, 0) // Sem expiração
 
 	// Adicionar à lista global de tags
-	pipe.SAdd(r.ctx, tagListKey, strconv.Itoa(tag.ID))
+	pipe.SAdd(r.ctx, r.key(tagListKey), strconv.Itoa(tag.ID))
 
 	// Adicionar ao índice por PLC
-	plcTagsKey := fmt.Sprintf("%s%d", tagsByPLCPrefix, tag.PLCID)
+	plcTagsKey := fmt.Sprintf("%s%d", r.key(tagsByPLCPrefix), tag.PLCID)
 	pipe.SAdd(r.ctx, plcTagsKey, strconv.Itoa(tag.ID))
 
 	// Adicionar ao índice por nome
-	nameTagsKey := fmt.Sprintf("%s%s", tagsByNamePrefix, tag.Name)
+	nameTagsKey := fmt.Sprintf("%s%s", r.key(tagsByNamePrefix), tag.Name)
 	pipe.SAdd(r.ctx, nameTagsKey, strconv.Itoa(tag.ID))
 
 	_, err = pipe.Exec(r.ctx)
@@ -160,7 +175,7 @@ func (r *PLCTagRedisRepository) Update(tag domain.PLCTag) error {
 	}
 
 	// Verificar se a tag existe e buscar dados antigos para comparação
-	oldTagKey := fmt.Sprintf("%s%d", tagKeyPrefix, tag.ID)
+	oldTagKey := fmt.Sprintf("%s%d", r.key(tagKeyPrefix), tag.ID)
 	oldTagData, err := r.client.Get(r.ctx, oldTagKey).Result()
 	if err != nil {
 		if err == redis.Nil {
@@ -188,8 +203,8 @@ func (r *PLCTagRedisRepository) Update(tag domain.PLCTag) error {
 
 	// Se o PLC mudou, atualizar os índices
 	if oldTag.PLCID != tag.PLCID {
-		oldPLCTagsKey := fmt.Sprintf("%s%d", tagsByPLCPrefix, oldTag.PLCID)
-		newPLCTagsKey := fmt.Sprintf("%s%d", tagsByPLCPrefix, tag.PLCID)
+		oldPLCTagsKey := fmt.Sprintf("%s%d", r.key(tagsByPLCPrefix), oldTag.PLCID)
+		newPLCTagsKey := fmt.Sprintf("%s%d", r.key(tagsByPLCPrefix), tag.PLCID)
 
 		pipe.SRem(r.ctx, oldPLCTagsKey, strconv.Itoa(tag.ID))
 		pipe.SAdd(r.ctx, newPLCTagsKey, strconv.Itoa(tag.ID))
@@ -197,8 +212,8 @@ func (r *PLCTagRedisRepository) Update(tag domain.PLCTag) error {
 
 	// Se o nome mudou, atualizar os índices
 	if oldTag.Name != tag.Name {
-		oldNameTagsKey := fmt.Sprintf("%s%s", tagsByNamePrefix, oldTag.Name)
-		newNameTagsKey := fmt.Sprintf("%s%s", tagsByNamePrefix, tag.Name)
+		oldNameTagsKey := fmt.Sprintf("%s%s", r.key(tagsByNamePrefix), oldTag.Name)
+		newNameTagsKey := fmt.Sprintf("%s%s", r.key(tagsByNamePrefix), tag.Name)
 
 		pipe.SRem(r.ctx, oldNameTagsKey, strconv.Itoa(tag.ID))
 		pipe.SAdd(r.ctx, newNameTagsKey, strconv.Itoa(tag.ID))
@@ -216,21 +231,304 @@ func (r *PLCTagRedisRepository) Delete(id int) error {
 		return err
 	}
 
-	key := fmt.Sprintf("%s%d", tagKeyPrefix, id)
-	plcTagsKey := fmt.Sprintf("%s%d", tagsByPLCPrefix, tag.PLCID)
-	nameTagsKey := fmt.Sprintf("%s%s", tagsByNamePrefix, tag.Name)
+	key := fmt.Sprintf("%s%d", r.key(tagKeyPrefix), id)
+	plcTagsKey := fmt.Sprintf("%s%d", r.key(tagsByPLCPrefix), tag.PLCID)
+	nameTagsKey := fmt.Sprintf("%s%s", r.key(tagsByNamePrefix), tag.Name)
 
 	// Remover usando pipeline
 	pipe := r.client.Pipeline()
 	pipe.Del(r.ctx, key)
-	pipe.SRem(r.ctx, tagListKey, strconv.Itoa(id))
+	pipe.SRem(r.ctx, r.key(tagListKey), strconv.Itoa(id))
 	pipe.SRem(r.ctx, plcTagsKey, strconv.Itoa(id))
 	pipe.SRem(r.ctx, nameTagsKey, strconv.Itoa(id))
 
 	// Também remover qualquer valor armazenado
-	valueKey := fmt.Sprintf("%s%d", tagValueKeyPrefix, id)
+	valueKey := fmt.Sprintf("%s%d", r.key(tagValueKeyPrefix), id)
 	pipe.Del(r.ctx, valueKey)
 
 	_, err = pipe.Exec(r.ctx)
 	return err
 }
+
+// DeleteByPLCID remove todas as tags do PLC, seus índices e valores em um
+// único pipeline
+func (r *PLCTagRedisRepository) DeleteByPLCID(plcID int) (int, error) {
+	plcTagsKey := fmt.Sprintf("%s%d", r.key(tagsByPLCPrefix), plcID)
+
+	ids, err := r.client.SMembers(r.ctx, plcTagsKey).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	pipe := r.client.Pipeline()
+	for _, idStr := range ids {
+		id, err := strconv.Atoi(idStr)
+		if err != nil {
+			log.Printf("ID de tag inválido no Redis: %s", idStr)
+			continue
+		}
+
+		// O nome é necessário para limpar o índice por nome
+		if tag, err := r.GetByID(id); err == nil {
+			pipe.SRem(r.ctx, fmt.Sprintf("%s%s", r.key(tagsByNamePrefix), tag.Name), idStr)
+		}
+
+		pipe.Del(r.ctx, fmt.Sprintf("%s%d", r.key(tagKeyPrefix), id))
+		pipe.SRem(r.ctx, r.key(tagListKey), idStr)
+		pipe.Del(r.ctx, fmt.Sprintf("%s%d", r.key(tagValueKeyPrefix), id))
+	}
+	pipe.Del(r.ctx, plcTagsKey)
+
+	if _, err := pipe.Exec(r.ctx); err != nil {
+		return 0, err
+	}
+
+	return len(ids), nil
+}
+
+// DeleteMany remove várias tags e seus valores em um único pipeline. Tags
+// ausentes no Redis são ignoradas; retorna os IDs removidos.
+func (r *PLCTagRedisRepository) DeleteMany(ids []int) ([]int, error) {
+	deleted := []int{}
+	if len(ids) == 0 {
+		return deleted, nil
+	}
+
+	pipe := r.client.Pipeline()
+	for _, id := range ids {
+		tag, err := r.GetByID(id)
+		if err != nil {
+			if err == domain.ErrPLCTagNotFound {
+				continue
+			}
+			return nil, err
+		}
+
+		idStr := strconv.Itoa(id)
+		pipe.Del(r.ctx, fmt.Sprintf("%s%d", r.key(tagKeyPrefix), id))
+		pipe.SRem(r.ctx, r.key(tagListKey), idStr)
+		pipe.SRem(r.ctx, fmt.Sprintf("%s%d", r.key(tagsByPLCPrefix), tag.PLCID), idStr)
+		pipe.SRem(r.ctx, fmt.Sprintf("%s%s", r.key(tagsByNamePrefix), tag.Name), idStr)
+		pipe.Del(r.ctx, fmt.Sprintf("%s%d", r.key(tagValueKeyPrefix), id))
+		deleted = append(deleted, id)
+	}
+
+	if len(deleted) == 0 {
+		return deleted, nil
+	}
+
+	if _, err := pipe.Exec(r.ctx); err != nil {
+		return nil, err
+	}
+
+	return deleted, nil
+}
+
+// BulkUpdate aplica o patch às tags do PLC que atendem ao filtro, uma a uma
+func (r *PLCTagRedisRepository) BulkUpdate(plcID int, filter domain.TagFilter, patch domain.TagPatch) (int, error) {
+	tags, err := r.GetPLCTags(plcID)
+	if err != nil {
+		return 0, err
+	}
+
+	updated := 0
+	for _, tag := range tags {
+		if !filter.Matches(tag) {
+			continue
+		}
+		patch.Apply(&tag)
+		if err := r.Update(tag); err != nil {
+			return updated, err
+		}
+		updated++
+	}
+
+	return updated, nil
+}
+
+// CountByPLC retorna quantas tags do PLC estão no Redis
+func (r *PLCTagRedisRepository) CountByPLC(plcID int) (int, error) {
+	tags, err := r.GetPLCTags(plcID)
+	if err != nil {
+		return 0, err
+	}
+	return len(tags), nil
+}
+
+// BulkCreate cria as tags uma a uma após verificar o limite. Sem transação:
+// o Redis é o cache, o limite é garantido no PostgreSQL.
+func (r *PLCTagRedisRepository) BulkCreate(plcID int, tags []domain.PLCTag, maxTags int) ([]int, error) {
+	if maxTags > 0 {
+		count, err := r.CountByPLC(plcID)
+		if err != nil {
+			return nil, err
+		}
+		if count+len(tags) > maxTags {
+			return nil, fmt.Errorf("%w: PLC %d possui %d tags, o lote de %d ultrapassa o limite de %d",
+				domain.ErrTagLimitExceeded, plcID, count, len(tags), maxTags)
+		}
+	}
+
+	ids := make([]int, 0, len(tags))
+	for _, tag := range tags {
+		tag.PLCID = plcID
+		id, err := r.Create(tag)
+		if err != nil {
+			return ids, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// GetIdleTags não é suportado no Redis, que não guarda o histórico de valores
+func (r *PLCTagRedisRepository) GetIdleTags(since time.Duration) ([]domain.PLCTag, error) {
+	return nil, fmt.Errorf("consulta de tags ociosas requer o histórico no PostgreSQL")
+}
+
+// UpdateScanRates altera o scan_rate das tags uma a uma
+func (r *PLCTagRedisRepository) UpdateScanRates(rates map[int]int) error {
+	for id, rate := range rates {
+		tag, err := r.GetByID(id)
+		if err != nil {
+			return err
+		}
+		tag.ScanRate = rate
+		if err := r.Update(tag); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UpdateLastWrite registra a última escrita na tag armazenada no Redis
+func (r *PLCTagRedisRepository) UpdateLastWrite(tagID, userID int, t time.Time) error {
+	tag, err := r.GetByID(tagID)
+	if err != nil {
+		return err
+	}
+
+	tag.LastWrittenAt = &t
+	tag.LastWrittenBy = nil
+	if userID > 0 {
+		tag.LastWrittenBy = &userID
+	}
+
+	return r.Update(tag)
+}
+
+// SetAutoDisabled tira da varredura a tag armazenada no Redis registrando o
+// erro de leitura, sem alterar o campo active
+func (r *PLCTagRedisRepository) SetAutoDisabled(tagID int, lastError string, at time.Time) error {
+	tag, err := r.GetByID(tagID)
+	if err != nil {
+		return err
+	}
+
+	tag.AutoDisabledAt = &at
+	tag.LastReadError = lastError
+
+	return r.Update(tag)
+}
+
+// ClearAutoDisabled limpa a desativação automática da tag armazenada no Redis
+func (r *PLCTagRedisRepository) ClearAutoDisabled(tagID int) error {
+	tag, err := r.GetByID(tagID)
+	if err != nil {
+		return err
+	}
+
+	tag.AutoDisabledAt = nil
+	tag.LastReadError = ""
+
+	return r.Update(tag)
+}
+
+// GetAutoDisabled percorre as tags do Redis e retorna as desativadas automaticamente
+func (r *PLCTagRedisRepository) GetAutoDisabled() ([]domain.PLCTag, error) {
+	ids, err := r.client.SMembers(r.ctx, r.key(tagListKey)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	tags := []domain.PLCTag{}
+	for _, idStr := range ids {
+		id, err := strconv.Atoi(idStr)
+		if err != nil {
+			continue
+		}
+
+		tag, err := r.GetByID(id)
+		if err != nil || tag.AutoDisabledAt == nil {
+			continue
+		}
+		tags = append(tags, tag)
+	}
+
+	sort.Slice(tags, func(i, j int) bool {
+		return tags[i].AutoDisabledAt.After(*tags[j].AutoDisabledAt)
+	})
+
+	return tags, nil
+}
+
+// Search filtra em memória as tags armazenadas no Redis. A busca principal é
+// feita no PostgreSQL; esta implementação existe para o modo sem banco.
+func (r *PLCTagRedisRepository) Search(ctx context.Context, filter domain.TagSearchFilter) ([]domain.PLCTag, int, error) {
+	ids, err := r.client.SMembers(ctx, r.key(tagListKey)).Result()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	query := strings.ToLower(filter.Query)
+	matches := []domain.PLCTag{}
+
+	for _, idStr := range ids {
+		id, err := strconv.Atoi(idStr)
+		if err != nil {
+			continue
+		}
+
+		tag, err := r.GetByID(id)
+		if err != nil {
+			continue
+		}
+
+		if query != "" &&
+			!strings.Contains(strings.ToLower(tag.Name), query) &&
+			!strings.Contains(strings.ToLower(tag.Description), query) &&
+			!strings.Contains(strings.ToLower(tag.DataType), query) {
+			continue
+		}
+		if filter.PLCID != nil && tag.PLCID != *filter.PLCID {
+			continue
+		}
+		if filter.DataType != "" && tag.DataType != filter.DataType {
+			continue
+		}
+		if filter.Active != nil && tag.Active != *filter.Active {
+			continue
+		}
+
+		matches = append(matches, tag)
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].PLCID != matches[j].PLCID {
+			return matches[i].PLCID < matches[j].PLCID
+		}
+		return matches[i].Name < matches[j].Name
+	})
+
+	total := len(matches)
+	start := (filter.Page - 1) * filter.PageSize
+	if start >= total {
+		return []domain.PLCTag{}, total, nil
+	}
+	end := start + filter.PageSize
+	if end > total {
+		end = total
+	}
+
+	return matches[start:end], total, nil
+}
@@ -6,39 +6,94 @@ import (
 	"database/sql"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"log"
 	"time"
 )
 
 type ProfileRepository struct {
 	db *sql.DB
+	queryTimeout
 }
 
 func NewProfileRepository(db *sql.DB) *ProfileRepository {
-	return &ProfileRepository{db: db}
+	r := &ProfileRepository{db: db}
+	r.ensureSchema()
+	return r
 }
 
-func (r *ProfileRepository) Create(profile domain.Profile) (int, error) {
-	// Tratamento seguro para NotificationPreferences
-	var notificationJSON []byte
-	var err error
+// defaultNotificationJSON é usado quando as preferências não podem ser serializadas
+const defaultNotificationJSON = `[{"channel":"email","enabled":true},{"channel":"push","enabled":true},{"channel":"sms","enabled":false}]`
 
-	if profile.NotificationPreferences == nil {
-		// Valor padrão se for nulo
-		profile.NotificationPreferences = map[string]bool{
-			"email": true,
-			"push":  true,
-			"sms":   false,
-		}
+// ensureSchema adiciona a coluna do painel configurável e converte
+// preferências de notificação no formato antigo {"email": true} para a
+// lista [{"channel": "email", "enabled": true}]
+func (r *ProfileRepository) ensureSchema() {
+	if r.db == nil {
+		return
+	}
+
+	var exists bool
+	err := r.db.QueryRow(`
+		SELECT EXISTS (
+			SELECT FROM information_schema.tables 
+			WHERE table_schema = 'public' 
+			AND table_name = 'profiles'
+		)
+	`).Scan(&exists)
+	if err != nil || !exists {
+		return
 	}
 
-	// Converter map para JSON
-	notificationJSON, err = json.Marshal(profile.NotificationPreferences)
+	// Painel configurável do usuário
+	_, err = r.db.Exec(`ALTER TABLE profiles ADD COLUMN IF NOT EXISTS dashboard_config JSONB NOT NULL DEFAULT '[]'::jsonb`)
+	if err != nil {
+		log.Printf("Erro ao adicionar coluna dashboard_config: %v", err)
+	}
+
+	result, err := r.db.Exec(`
+		UPDATE profiles
+		SET notification_preferences = COALESCE((
+			SELECT jsonb_agg(jsonb_build_object('channel', key, 'enabled', value = 'true'::jsonb) ORDER BY key)
+			FROM jsonb_each(notification_preferences)
+		), '[]'::jsonb)
+		WHERE jsonb_typeof(notification_preferences) = 'object'
+	`)
+	if err != nil {
+		log.Printf("Erro ao migrar preferências de notificação: %v", err)
+		return
+	}
+	if n, _ := result.RowsAffected(); n > 0 {
+		log.Printf("Preferências de notificação migradas para o formato de canais: %d perfis", n)
+	}
+
+	_, err = r.db.Exec(`ALTER TABLE profiles ALTER COLUMN notification_preferences SET DEFAULT '` + defaultNotificationJSON + `'::jsonb`)
+	if err != nil {
+		log.Printf("Erro ao alterar padrão de notification_preferences: %v", err)
+	}
+}
+
+// marshalNotificationChannels serializa as preferências para a coluna JSONB
+func marshalNotificationChannels(channels domain.NotificationChannels) []byte {
+	if channels == nil {
+		channels = domain.DefaultNotificationChannels()
+	}
+
+	data, err := json.Marshal(channels)
 	if err != nil {
 		log.Printf("Erro ao converter notificações para JSON: %v", err)
-		// Usar JSON padrão em caso de erro
-		notificationJSON = []byte(`{"email":true,"push":true,"sms":false}`)
+		return []byte(defaultNotificationJSON)
 	}
+	return data
+}
+
+func (r *ProfileRepository) Create(profile domain.Profile) (int, error) {
+	ctx, cancel := r.queryContext()
+	defer cancel()
+
+	// Tratamento seguro para NotificationPreferences
+	notificationJSON := marshalNotificationChannels(profile.NotificationPreferences)
+	var err error
 
 	// Garantir que os campos opcionais tenham valores padrão
 	if profile.Theme == "" {
@@ -59,7 +114,7 @@ func (r *ProfileRepository) Create(profile domain.Profile) (int, error) {
 
 	// Verificar se a tabela profiles existe
 	var exists bool
-	err = r.db.QueryRow(`
+	err = r.db.QueryRowContext(ctx, `
 		SELECT EXISTS (
 			SELECT FROM information_schema.tables 
 			WHERE table_schema = 'public' 
@@ -75,16 +130,17 @@ func (r *ProfileRepository) Create(profile domain.Profile) (int, error) {
 	// Se a tabela não existir, criá-la - CORRIGIDO: sem coluna ID
 	if !exists {
 		log.Printf("Tabela profiles não existe. Criando...")
-		_, err = r.db.Exec(`
+		_, err = r.db.ExecContext(ctx, `
 			CREATE TABLE IF NOT EXISTS profiles (
 				user_id INTEGER NOT NULL PRIMARY KEY,
 				avatar_url TEXT,
 				bio TEXT,
 				department VARCHAR(100),
-				notification_preferences JSONB DEFAULT '{"email": true, "push": true, "sms": false}'::jsonb,
+				notification_preferences JSONB DEFAULT '`+defaultNotificationJSON+`'::jsonb,
 				theme VARCHAR(50) DEFAULT 'default',
 				font_size VARCHAR(20) DEFAULT 'medium',
 				language VARCHAR(10) DEFAULT 'pt_BR',
+				dashboard_config JSONB NOT NULL DEFAULT '[]'::jsonb,
 				created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
 				updated_at TIMESTAMP
 			)
@@ -114,7 +170,7 @@ func (r *ProfileRepository) Create(profile domain.Profile) (int, error) {
 	`
 
 	var userID int
-	err = r.db.QueryRow(
+	err = r.db.QueryRowContext(ctx,
 		query,
 		profile.UserID,
 		profile.AvatarURL,
@@ -137,9 +193,12 @@ func (r *ProfileRepository) Create(profile domain.Profile) (int, error) {
 }
 
 func (r *ProfileRepository) GetByUserID(userID int) (domain.Profile, error) {
+	ctx, cancel := r.queryContext()
+	defer cancel()
+
 	// Verificar se a tabela profiles existe
 	var exists bool
-	err := r.db.QueryRow(`
+	err := r.db.QueryRowContext(ctx, `
 		SELECT EXISTS (
 			SELECT FROM information_schema.tables 
 			WHERE table_schema = 'public' 
@@ -167,7 +226,7 @@ func (r *ProfileRepository) GetByUserID(userID int) (domain.Profile, error) {
 		Theme:                   "default",
 		FontSize:                "medium",
 		Language:                "pt_BR",
-		NotificationPreferences: map[string]bool{"email": true, "push": true, "sms": false},
+		NotificationPreferences: domain.DefaultNotificationChannels(),
 		CreatedAt:               time.Now(),
 	}
 
@@ -184,7 +243,7 @@ func (r *ProfileRepository) GetByUserID(userID int) (domain.Profile, error) {
 		WHERE user_id = $1
 	`
 
-	err = r.db.QueryRow(query, userID).Scan(
+	err = r.db.QueryRowContext(ctx, query, userID).Scan(
 		&profile.UserID,
 		&avatarURL,
 		&bio,
@@ -237,26 +296,13 @@ func (r *ProfileRepository) GetByUserID(userID int) (domain.Profile, error) {
 		profile.Language = "pt_BR"
 	}
 
-	// Processar JSON de notificações
+	// Processar JSON de notificações (aceita também o formato antigo ainda não migrado)
+	profile.NotificationPreferences = domain.DefaultNotificationChannels()
 	if notificationJSON.Valid {
-		var notifications map[string]bool
+		var notifications domain.NotificationChannels
 		err = json.Unmarshal([]byte(notificationJSON.String), &notifications)
-		if err == nil {
+		if err == nil && notifications != nil {
 			profile.NotificationPreferences = notifications
-		} else {
-			// Valor padrão em caso de erro
-			profile.NotificationPreferences = map[string]bool{
-				"email": true,
-				"push":  true,
-				"sms":   false,
-			}
-		}
-	} else {
-		// Valor padrão para preferências nulas
-		profile.NotificationPreferences = map[string]bool{
-			"email": true,
-			"push":  true,
-			"sms":   false,
 		}
 	}
 
@@ -274,9 +320,12 @@ func (r *ProfileRepository) GetByUserID(userID int) (domain.Profile, error) {
 }
 
 func (r *ProfileRepository) Update(profile domain.Profile) error {
+	ctx, cancel := r.queryContext()
+	defer cancel()
+
 	// Verificar se a tabela profiles existe
 	var exists bool
-	err := r.db.QueryRow(`
+	err := r.db.QueryRowContext(ctx, `
 		SELECT EXISTS (
 			SELECT FROM information_schema.tables 
 			WHERE table_schema = 'public' 
@@ -292,16 +341,17 @@ func (r *ProfileRepository) Update(profile domain.Profile) error {
 	// Se a tabela não existir, criá-la - CORRIGIDO: sem coluna ID
 	if !exists {
 		log.Printf("Tabela profiles não existe. Criando...")
-		_, err = r.db.Exec(`
+		_, err = r.db.ExecContext(ctx, `
 			CREATE TABLE IF NOT EXISTS profiles (
 				user_id INTEGER NOT NULL PRIMARY KEY,
 				avatar_url TEXT,
 				bio TEXT,
 				department VARCHAR(100),
-				notification_preferences JSONB DEFAULT '{"email": true, "push": true, "sms": false}'::jsonb,
+				notification_preferences JSONB DEFAULT '`+defaultNotificationJSON+`'::jsonb,
 				theme VARCHAR(50) DEFAULT 'default',
 				font_size VARCHAR(20) DEFAULT 'medium',
 				language VARCHAR(10) DEFAULT 'pt_BR',
+				dashboard_config JSONB NOT NULL DEFAULT '[]'::jsonb,
 				created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
 				updated_at TIMESTAMP
 			)
@@ -313,24 +363,7 @@ func (r *ProfileRepository) Update(profile domain.Profile) error {
 	}
 
 	// Tratamento seguro para NotificationPreferences
-	var notificationJSON []byte
-
-	if profile.NotificationPreferences == nil {
-		// Valor padrão se for nulo
-		profile.NotificationPreferences = map[string]bool{
-			"email": true,
-			"push":  true,
-			"sms":   false,
-		}
-	}
-
-	// Converter map para JSON
-	notificationJSON, err = json.Marshal(profile.NotificationPreferences)
-	if err != nil {
-		log.Printf("Erro ao converter notificações para JSON: %v", err)
-		// Usar JSON padrão em caso de erro
-		notificationJSON = []byte(`{"email":true,"push":true,"sms":false}`)
-	}
+	notificationJSON := marshalNotificationChannels(profile.NotificationPreferences)
 
 	// Garantir valores padrão para campos opcionais
 	if profile.Theme == "" {
@@ -366,7 +399,7 @@ func (r *ProfileRepository) Update(profile domain.Profile) error {
 			updated_at = EXCLUDED.updated_at
 	`
 
-	_, err = r.db.Exec(
+	_, err = r.db.ExecContext(ctx,
 		query,
 		profile.UserID,
 		profile.AvatarURL,
@@ -389,11 +422,14 @@ func (r *ProfileRepository) Update(profile domain.Profile) error {
 }
 
 func (r *ProfileRepository) Delete(id int) error {
+	ctx, cancel := r.queryContext()
+	defer cancel()
+
 	query := "DELETE FROM profiles WHERE user_id = $1"
 
 	// Verificar se a tabela profiles existe
 	var exists bool
-	err := r.db.QueryRow(`
+	err := r.db.QueryRowContext(ctx, `
 		SELECT EXISTS (
 			SELECT FROM information_schema.tables 
 			WHERE table_schema = 'public' 
@@ -411,7 +447,7 @@ func (r *ProfileRepository) Delete(id int) error {
 		return nil
 	}
 
-	result, err := r.db.Exec(query, id)
+	result, err := r.db.ExecContext(ctx, query, id)
 	if err != nil {
 		log.Printf("Erro ao excluir perfil: %v", err)
 		return err
@@ -429,3 +465,56 @@ func (r *ProfileRepository) Delete(id int) error {
 
 	return nil
 }
+
+// GetDashboardConfig retorna os widgets do painel do usuário
+func (r *ProfileRepository) GetDashboardConfig(userID int) ([]domain.DashboardWidget, error) {
+	ctx, cancel := r.queryContext()
+	defer cancel()
+
+	var data []byte
+	err := r.db.QueryRowContext(ctx,
+		"SELECT dashboard_config FROM profiles WHERE user_id = $1", userID,
+	).Scan(&data)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return []domain.DashboardWidget{}, nil
+		}
+		return nil, fmt.Errorf("erro ao buscar painel do usuário %d: %w", userID, err)
+	}
+
+	widgets := []domain.DashboardWidget{}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &widgets); err != nil {
+			return nil, fmt.Errorf("erro ao decodificar painel do usuário %d: %w", userID, err)
+		}
+	}
+	return widgets, nil
+}
+
+// UpdateDashboardConfig substitui os widgets do painel do usuário
+func (r *ProfileRepository) UpdateDashboardConfig(userID int, widgets []domain.DashboardWidget) error {
+	ctx, cancel := r.queryContext()
+	defer cancel()
+
+	if widgets == nil {
+		widgets = []domain.DashboardWidget{}
+	}
+	data, err := json.Marshal(widgets)
+	if err != nil {
+		return fmt.Errorf("erro ao converter painel para JSON: %w", err)
+	}
+
+	now := time.Now()
+	_, err = r.db.ExecContext(ctx, `
+		INSERT INTO profiles (user_id, dashboard_config, created_at, updated_at)
+		VALUES ($1, $2, $3, $3)
+		ON CONFLICT (user_id)
+		DO UPDATE SET
+			dashboard_config = EXCLUDED.dashboard_config,
+			updated_at = EXCLUDED.updated_at
+	`, userID, data, now)
+	if err != nil {
+		return fmt.Errorf("erro ao atualizar painel do usuário %d: %w", userID, err)
+	}
+	return nil
+}
@@ -4,20 +4,111 @@ package repository
 import (
 	"app_padrao/internal/domain"
 	"database/sql"
+	"errors"
+	"log"
+
+	"github.com/lib/pq"
 )
 
 type RoleRepository struct {
 	db *sql.DB
+	queryTimeout
 }
 
 func NewRoleRepository(db *sql.DB) *RoleRepository {
-	return &RoleRepository{db: db}
+	r := &RoleRepository{db: db}
+	r.ensureSchema()
+	return r
+}
+
+// ensureSchema adiciona a coluna de herança à tabela roles quando ainda não existe
+func (r *RoleRepository) ensureSchema() {
+	if r.db == nil {
+		return
+	}
+
+	_, err := r.db.Exec(`ALTER TABLE roles ADD COLUMN IF NOT EXISTS parent_role_id INTEGER REFERENCES roles(id) ON DELETE SET NULL`)
+	if err != nil {
+		log.Printf("Erro ao adicionar coluna parent_role_id: %v", err)
+	}
+
+	if _, err := r.db.Exec(seedPermissionsSQL); err != nil {
+		log.Printf("Erro ao cadastrar permissões padrão: %v", err)
+	}
+}
+
+// seedPermissionsSQL cadastra as roles user e manager e as permissões
+// exigidas pelas rotas (mesmo script de banco.txt). As concessões padrão só
+// são feitas junto com a criação da permissão, para não desfazer permissões
+// revogadas por um administrador.
+const seedPermissionsSQL = `
+	INSERT INTO roles (name, description)
+	SELECT v.name, v.description
+	FROM (VALUES
+		('user', 'Operador: lê, escreve e configura tags'),
+		('manager', 'Gerente: administra PLCs além das permissões de operador')
+	) AS v(name, description)
+	WHERE NOT EXISTS (SELECT 1 FROM roles r WHERE r.name = v.name);
+
+	UPDATE roles SET parent_role_id = (SELECT id FROM roles WHERE name = 'user')
+	WHERE name = 'manager' AND parent_role_id IS NULL;
+
+	WITH new_permissions AS (
+		INSERT INTO permissions (code, description)
+		SELECT v.code, v.description
+		FROM (VALUES
+			('admin_panel', 'Acessar o painel administrativo'),
+			('plc_admin', 'Administrar conexões, monitoramento e DBs dos PLCs'),
+			('plc_create', 'Cadastrar PLCs e sites'),
+			('plc_update', 'Editar PLCs e sites'),
+			('plc_delete', 'Excluir PLCs e sites'),
+			('plc_tag_create', 'Cadastrar e importar tags'),
+			('plc_tag_update', 'Editar tags, grupos de varredura e dependências'),
+			('plc_tag_delete', 'Excluir tags'),
+			('plc_write', 'Escrever valores nas tags e reconhecer alarmes')
+		) AS v(code, description)
+		WHERE NOT EXISTS (SELECT 1 FROM permissions p WHERE p.code = v.code)
+		RETURNING id, code
+	)
+	INSERT INTO role_permissions (role_id, permission_id)
+	SELECT r.id, np.id
+	FROM new_permissions np
+	JOIN (VALUES
+		('user', 'plc_write'),
+		('user', 'plc_tag_create'),
+		('user', 'plc_tag_update'),
+		('manager', 'plc_create'),
+		('manager', 'plc_update'),
+		('manager', 'plc_delete'),
+		('manager', 'plc_tag_delete'),
+		('manager', 'plc_admin')
+	) AS g(role_name, code) ON g.code = np.code
+	JOIN roles r ON r.name = g.role_name;
+`
+
+// scanRole lê uma linha de role incluindo a role pai
+func scanRole(scanner interface {
+	Scan(dest ...interface{}) error
+}) (domain.Role, error) {
+	var role domain.Role
+	var parentID sql.NullInt64
+	if err := scanner.Scan(&role.ID, &role.Name, &role.Description, &parentID); err != nil {
+		return domain.Role{}, err
+	}
+	if parentID.Valid {
+		id := int(parentID.Int64)
+		role.ParentRoleID = &id
+	}
+	return role, nil
 }
 
 func (r *RoleRepository) GetAll() ([]domain.Role, error) {
-	query := "SELECT id, name, description FROM roles ORDER BY id"
+	ctx, cancel := r.queryContext()
+	defer cancel()
+
+	query := "SELECT id, name, description, parent_role_id FROM roles ORDER BY id"
 
-	rows, err := r.db.Query(query)
+	rows, err := r.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -25,8 +116,7 @@ func (r *RoleRepository) GetAll() ([]domain.Role, error) {
 
 	var roles []domain.Role
 	for rows.Next() {
-		var role domain.Role
-		err := rows.Scan(&role.ID, &role.Name, &role.Description)
+		role, err := scanRole(rows)
 		if err != nil {
 			return nil, err
 		}
@@ -37,11 +127,16 @@ func (r *RoleRepository) GetAll() ([]domain.Role, error) {
 }
 
 func (r *RoleRepository) GetByID(id int) (domain.Role, error) {
-	var role domain.Role
-	query := "SELECT id, name, description FROM roles WHERE id = $1"
+	ctx, cancel := r.queryContext()
+	defer cancel()
+
+	query := "SELECT id, name, description, parent_role_id FROM roles WHERE id = $1"
 
-	err := r.db.QueryRow(query, id).Scan(&role.ID, &role.Name, &role.Description)
+	role, err := scanRole(r.db.QueryRowContext(ctx, query, id))
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.Role{}, domain.ErrRoleNotFound
+		}
 		return domain.Role{}, err
 	}
 
@@ -49,11 +144,16 @@ func (r *RoleRepository) GetByID(id int) (domain.Role, error) {
 }
 
 func (r *RoleRepository) GetByName(name string) (domain.Role, error) {
-	var role domain.Role
-	query := "SELECT id, name, description FROM roles WHERE name = $1"
+	ctx, cancel := r.queryContext()
+	defer cancel()
 
-	err := r.db.QueryRow(query, name).Scan(&role.ID, &role.Name, &role.Description)
+	query := "SELECT id, name, description, parent_role_id FROM roles WHERE name = $1"
+
+	role, err := scanRole(r.db.QueryRowContext(ctx, query, name))
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.Role{}, domain.ErrRoleNotFound
+		}
 		return domain.Role{}, err
 	}
 
@@ -61,6 +161,9 @@ func (r *RoleRepository) GetByName(name string) (domain.Role, error) {
 }
 
 func (r *RoleRepository) GetPermissions(roleID int) ([]domain.Permission, error) {
+	ctx, cancel := r.queryContext()
+	defer cancel()
+
 	query := `
         SELECT p.id, p.code, p.description
         FROM permissions p
@@ -69,7 +172,7 @@ func (r *RoleRepository) GetPermissions(roleID int) ([]domain.Permission, error)
         ORDER BY p.id
     `
 
-	rows, err := r.db.Query(query, roleID)
+	rows, err := r.db.QueryContext(ctx, query, roleID)
 	if err != nil {
 		return nil, err
 	}
@@ -87,3 +190,89 @@ func (r *RoleRepository) GetPermissions(roleID int) ([]domain.Permission, error)
 
 	return permissions, nil
 }
+
+// roleChain percorre a herança a partir da role informada, até MaxRoleDepth
+// níveis, e retorna os IDs da cadeia. Retorna ErrRoleHierarchyCycle se uma
+// role aparecer duas vezes na cadeia.
+func (r *RoleRepository) roleChain(roleID int) ([]int, error) {
+	ctx, cancel := r.queryContext()
+	defer cancel()
+
+	query := `
+		WITH RECURSIVE role_chain AS (
+			SELECT id, parent_role_id, ARRAY[id] AS path, false AS is_cycle, 1 AS depth
+			FROM roles WHERE id = $1
+			UNION ALL
+			SELECT r.id, r.parent_role_id, rc.path || r.id, r.id = ANY(rc.path), rc.depth + 1
+			FROM roles r
+			JOIN role_chain rc ON r.id = rc.parent_role_id
+			WHERE NOT rc.is_cycle AND rc.depth < $2
+		)
+		SELECT id, is_cycle FROM role_chain ORDER BY depth
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, roleID, domain.MaxRoleDepth)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var chain []int
+	for rows.Next() {
+		var id int
+		var isCycle bool
+		if err := rows.Scan(&id, &isCycle); err != nil {
+			return nil, err
+		}
+		if isCycle {
+			return nil, domain.ErrRoleHierarchyCycle
+		}
+		chain = append(chain, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(chain) == 0 {
+		return nil, domain.ErrRoleNotFound
+	}
+
+	return chain, nil
+}
+
+// GetEffectivePermissions retorna as permissões da role somadas às herdadas
+// das roles ancestrais
+func (r *RoleRepository) GetEffectivePermissions(roleID int) ([]domain.Permission, error) {
+	chain, err := r.roleChain(roleID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := r.queryContext()
+	defer cancel()
+
+	query := `
+		SELECT DISTINCT p.id, p.code, p.description
+		FROM permissions p
+		JOIN role_permissions rp ON p.id = rp.permission_id
+		WHERE rp.role_id = ANY($1)
+		ORDER BY p.id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(chain))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	permissions := []domain.Permission{}
+	for rows.Next() {
+		var permission domain.Permission
+		if err := rows.Scan(&permission.ID, &permission.Code, &permission.Description); err != nil {
+			return nil, err
+		}
+		permissions = append(permissions, permission)
+	}
+
+	return permissions, rows.Err()
+}
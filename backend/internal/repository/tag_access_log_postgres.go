@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"app_padrao/internal/domain"
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// TagAccessLogRepository implementa domain.TagAccessLogRepository no PostgreSQL
+type TagAccessLogRepository struct {
+	db *sql.DB
+	queryTimeout
+}
+
+// NewTagAccessLogRepository cria o repositório e garante a tabela tag_access_log
+func NewTagAccessLogRepository(db *sql.DB) *TagAccessLogRepository {
+	ensureTagAccessLogTable(db)
+	return &TagAccessLogRepository{db: db}
+}
+
+// ensureTagAccessLogTable cria a tabela tag_access_log quando ainda não existe
+func ensureTagAccessLogTable(db *sql.DB) {
+	if db == nil {
+		return
+	}
+
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS tag_access_log (
+			id BIGSERIAL PRIMARY KEY,
+			user_id INTEGER NOT NULL,
+			tag_id INTEGER NOT NULL,
+			plc_id INTEGER NOT NULL,
+			accessed_at TIMESTAMP NOT NULL DEFAULT NOW(),
+			endpoint VARCHAR(255) NOT NULL,
+			ip_address VARCHAR(64) NOT NULL DEFAULT ''
+		);
+		CREATE INDEX IF NOT EXISTS idx_tag_access_log_accessed_at ON tag_access_log (accessed_at);
+		CREATE INDEX IF NOT EXISTS idx_tag_access_log_user_id ON tag_access_log (user_id, accessed_at);
+		CREATE INDEX IF NOT EXISTS idx_tag_access_log_tag_id ON tag_access_log (tag_id, accessed_at)
+	`)
+	if err != nil {
+		log.Printf("Erro ao criar tabela tag_access_log: %v", err)
+	}
+}
+
+// Insert grava o lote com COPY FROM
+func (r *TagAccessLogRepository) Insert(entries []domain.TagAccessLog) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	ctx, cancel := r.queryContext()
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	stmt, err := tx.PrepareContext(ctx, pq.CopyIn("tag_access_log",
+		"user_id", "tag_id", "plc_id", "accessed_at", "endpoint", "ip_address"))
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	for _, entry := range entries {
+		if _, err := stmt.Exec(entry.UserID, entry.TagID, entry.PLCID, entry.AccessedAt.UTC(), entry.Endpoint, entry.IPAddress); err != nil {
+			stmt.Close()
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if _, err := stmt.Exec(); err != nil {
+		stmt.Close()
+		tx.Rollback()
+		return err
+	}
+
+	if err := stmt.Close(); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// List retorna os acessos mais recentes primeiro
+func (r *TagAccessLogRepository) List(filter domain.TagAccessLogFilter) ([]domain.TagAccessLog, error) {
+	ctx, cancel := r.queryContext()
+	defer cancel()
+
+	var conditions []string
+	var args []interface{}
+	addCondition := func(condition string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.UserID > 0 {
+		addCondition("user_id = $%d", filter.UserID)
+	}
+	if filter.TagID > 0 {
+		addCondition("tag_id = $%d", filter.TagID)
+	}
+	if !filter.From.IsZero() {
+		addCondition("accessed_at >= $%d", filter.From.UTC())
+	}
+	if !filter.To.IsZero() {
+		addCondition("accessed_at <= $%d", filter.To.UTC())
+	}
+
+	query := "SELECT id, user_id, tag_id, plc_id, accessed_at, endpoint, ip_address FROM tag_access_log"
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY accessed_at DESC, id DESC"
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao consultar log de acesso às tags: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []domain.TagAccessLog{}
+	for rows.Next() {
+		var entry domain.TagAccessLog
+		if err := rows.Scan(&entry.ID, &entry.UserID, &entry.TagID, &entry.PLCID,
+			&entry.AccessedAt, &entry.Endpoint, &entry.IPAddress); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
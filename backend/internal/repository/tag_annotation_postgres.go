@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"app_padrao/internal/domain"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+)
+
+// TagAnnotationRepository implementa domain.TagAnnotationRepository no PostgreSQL
+type TagAnnotationRepository struct {
+	db *sql.DB
+	queryTimeout
+}
+
+func NewTagAnnotationRepository(db *sql.DB) *TagAnnotationRepository {
+	ensureTagAnnotationsTable(db)
+	return &TagAnnotationRepository{db: db}
+}
+
+// ensureTagAnnotationsTable cria a tabela tag_annotations quando ainda não existe
+func ensureTagAnnotationsTable(db *sql.DB) {
+	if db == nil {
+		return
+	}
+
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS tag_annotations (
+			id SERIAL PRIMARY KEY,
+			tag_id INTEGER NOT NULL REFERENCES plc_tags(id) ON DELETE CASCADE,
+			user_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+			text VARCHAR(1000) NOT NULL,
+			created_at TIMESTAMP NOT NULL DEFAULT NOW()
+		);
+		CREATE INDEX IF NOT EXISTS idx_tag_annotations_tag_id ON tag_annotations (tag_id);
+		CREATE INDEX IF NOT EXISTS idx_tag_annotations_created_at ON tag_annotations (created_at)
+	`)
+	if err != nil {
+		log.Printf("Erro ao criar tabela tag_annotations: %v", err)
+	}
+}
+
+// scanTagAnnotation lê uma linha com id, tag_id, user_id, text e created_at
+func scanTagAnnotation(row rowScanner) (domain.TagAnnotation, error) {
+	var annotation domain.TagAnnotation
+	var userID sql.NullInt64
+
+	err := row.Scan(&annotation.ID, &annotation.TagID, &userID, &annotation.Text, &annotation.CreatedAt)
+	if err != nil {
+		return domain.TagAnnotation{}, err
+	}
+
+	if userID.Valid {
+		annotation.UserID = int(userID.Int64)
+	}
+	return annotation, nil
+}
+
+func (r *TagAnnotationRepository) Create(annotation domain.TagAnnotation) (int, error) {
+	ctx, cancel := r.queryContext()
+	defer cancel()
+
+	var userID interface{}
+	if annotation.UserID > 0 {
+		userID = annotation.UserID
+	}
+
+	var id int
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO tag_annotations (tag_id, user_id, text, created_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id
+	`, annotation.TagID, userID, annotation.Text, time.Now()).Scan(&id)
+
+	return id, err
+}
+
+func (r *TagAnnotationRepository) GetByID(id int) (domain.TagAnnotation, error) {
+	ctx, cancel := r.queryContext()
+	defer cancel()
+
+	annotation, err := scanTagAnnotation(r.db.QueryRowContext(ctx,
+		"SELECT id, tag_id, user_id, text, created_at FROM tag_annotations WHERE id = $1", id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.TagAnnotation{}, domain.ErrTagAnnotationNotFound
+		}
+		return domain.TagAnnotation{}, err
+	}
+
+	return annotation, nil
+}
+
+func (r *TagAnnotationRepository) GetByTagID(tagID int) ([]domain.TagAnnotation, error) {
+	ctx, cancel := r.queryContext()
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, tag_id, user_id, text, created_at FROM tag_annotations
+		WHERE tag_id = $1 ORDER BY created_at DESC, id DESC
+	`, tagID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	annotations := []domain.TagAnnotation{}
+	for rows.Next() {
+		annotation, err := scanTagAnnotation(rows)
+		if err != nil {
+			return nil, err
+		}
+		annotations = append(annotations, annotation)
+	}
+
+	return annotations, rows.Err()
+}
+
+func (r *TagAnnotationRepository) CountByTagID(tagID int) (int, error) {
+	ctx, cancel := r.queryContext()
+	defer cancel()
+
+	var count int
+	err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM tag_annotations WHERE tag_id = $1", tagID).Scan(&count)
+	return count, err
+}
+
+func (r *TagAnnotationRepository) Delete(id int) error {
+	ctx, cancel := r.queryContext()
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, "DELETE FROM tag_annotations WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return domain.ErrTagAnnotationNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"app_padrao/internal/domain"
+	"database/sql"
+	"errors"
+	"log"
+
+	"github.com/lib/pq"
+)
+
+// TagDependencyRepository implementa domain.TagDependencyRepository no PostgreSQL
+type TagDependencyRepository struct {
+	db *sql.DB
+	queryTimeout
+}
+
+func NewTagDependencyRepository(db *sql.DB) *TagDependencyRepository {
+	ensureTagDependenciesTable(db)
+	return &TagDependencyRepository{db: db}
+}
+
+// ensureTagDependenciesTable cria a tabela tag_dependencies quando ainda não existe
+func ensureTagDependenciesTable(db *sql.DB) {
+	if db == nil {
+		return
+	}
+
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS tag_dependencies (
+			id SERIAL PRIMARY KEY,
+			tag_id INTEGER NOT NULL REFERENCES plc_tags(id) ON DELETE CASCADE,
+			depends_on_tag_id INTEGER NOT NULL REFERENCES plc_tags(id) ON DELETE CASCADE,
+			dependency_type VARCHAR(10) NOT NULL DEFAULT 'before',
+			delay_ms INTEGER NOT NULL DEFAULT 0,
+			UNIQUE (tag_id, depends_on_tag_id)
+		);
+		CREATE INDEX IF NOT EXISTS idx_tag_dependencies_depends_on ON tag_dependencies (depends_on_tag_id)
+	`)
+	if err != nil {
+		log.Printf("Erro ao criar tabela tag_dependencies: %v", err)
+	}
+}
+
+const tagDependencyColumns = "id, tag_id, depends_on_tag_id, dependency_type, delay_ms"
+
+// scanTagDependency lê uma linha com as colunas de tagDependencyColumns
+func scanTagDependency(row rowScanner) (domain.TagDependency, error) {
+	var dependency domain.TagDependency
+	err := row.Scan(&dependency.ID, &dependency.TagID, &dependency.DependsOnTagID,
+		&dependency.DependencyType, &dependency.DelayMs)
+	return dependency, err
+}
+
+func (r *TagDependencyRepository) Create(dependency domain.TagDependency) (int, error) {
+	ctx, cancel := r.queryContext()
+	defer cancel()
+
+	var id int
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO tag_dependencies (tag_id, depends_on_tag_id, dependency_type, delay_ms)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id
+	`, dependency.TagID, dependency.DependsOnTagID, dependency.DependencyType, dependency.DelayMs).Scan(&id)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return 0, domain.ErrTagDependencyExists
+		}
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func (r *TagDependencyRepository) GetByID(id int) (domain.TagDependency, error) {
+	ctx, cancel := r.queryContext()
+	defer cancel()
+
+	dependency, err := scanTagDependency(r.db.QueryRowContext(ctx,
+		"SELECT "+tagDependencyColumns+" FROM tag_dependencies WHERE id = $1", id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.TagDependency{}, domain.ErrTagDependencyNotFound
+		}
+		return domain.TagDependency{}, err
+	}
+
+	return dependency, nil
+}
+
+func (r *TagDependencyRepository) GetByTagID(tagID int) ([]domain.TagDependency, error) {
+	return r.query(
+		"SELECT "+tagDependencyColumns+" FROM tag_dependencies WHERE tag_id = $1 ORDER BY id",
+		tagID)
+}
+
+func (r *TagDependencyRepository) GetAll() ([]domain.TagDependency, error) {
+	return r.query("SELECT " + tagDependencyColumns + " FROM tag_dependencies ORDER BY id")
+}
+
+// query executa uma consulta que retorna dependências
+func (r *TagDependencyRepository) query(query string, args ...interface{}) ([]domain.TagDependency, error) {
+	ctx, cancel := r.queryContext()
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	dependencies := []domain.TagDependency{}
+	for rows.Next() {
+		dependency, err := scanTagDependency(rows)
+		if err != nil {
+			return nil, err
+		}
+		dependencies = append(dependencies, dependency)
+	}
+
+	return dependencies, rows.Err()
+}
+
+func (r *TagDependencyRepository) Delete(id int) error {
+	ctx, cancel := r.queryContext()
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, "DELETE FROM tag_dependencies WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return domain.ErrTagDependencyNotFound
+	}
+
+	return nil
+}
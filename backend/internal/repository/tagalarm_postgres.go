@@ -0,0 +1,243 @@
+package repository
+
+import (
+	"app_padrao/internal/domain"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"sync"
+)
+
+type TagAlarmRepository struct {
+	queryTimeout
+	db         *sql.DB
+	schemaOnce sync.Once
+	schemaErr  error
+}
+
+func NewTagAlarmRepository(db *sql.DB) *TagAlarmRepository {
+	return &TagAlarmRepository{db: db}
+}
+
+const tagAlarmEventColumns = `e.id, e.alarm_id, a.name, e.plc_id, e.tag_id, e.severity, COALESCE(e.message, ''),
+	e.active, e.raised_at, e.escalation_level, e.escalated_at, e.acknowledged_by, e.acknowledged_at,
+	COALESCE(e.ack_comment, '')`
+
+// ensureTable cria as tabelas tag_alarms e tag_alarm_events se ainda não existirem
+func (r *TagAlarmRepository) ensureTable() error {
+	r.schemaOnce.Do(func() {
+		_, r.schemaErr = r.db.Exec(`
+			CREATE TABLE IF NOT EXISTS tag_alarms (
+				id SERIAL PRIMARY KEY,
+				plc_id INTEGER NOT NULL,
+				tag_id INTEGER NOT NULL,
+				name VARCHAR(100) NOT NULL,
+				severity VARCHAR(20) NOT NULL DEFAULT 'warning',
+				escalation_rules JSONB NOT NULL DEFAULT '[]'::jsonb,
+				active BOOLEAN NOT NULL DEFAULT true,
+				created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+			);
+			CREATE TABLE IF NOT EXISTS tag_alarm_events (
+				id BIGSERIAL PRIMARY KEY,
+				alarm_id INTEGER NOT NULL REFERENCES tag_alarms(id) ON DELETE CASCADE,
+				plc_id INTEGER NOT NULL,
+				tag_id INTEGER NOT NULL,
+				severity VARCHAR(20) NOT NULL,
+				message TEXT,
+				active BOOLEAN NOT NULL DEFAULT true,
+				raised_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+				escalation_level INTEGER NOT NULL DEFAULT 0,
+				escalated_at TIMESTAMPTZ,
+				acknowledged_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+				acknowledged_at TIMESTAMPTZ,
+				ack_comment TEXT
+			);
+			CREATE INDEX IF NOT EXISTS idx_tag_alarm_events_pending
+				ON tag_alarm_events (raised_at) WHERE active AND acknowledged_at IS NULL;
+		`)
+		if r.schemaErr != nil {
+			log.Printf("Erro ao criar tabelas de alarmes: %v", r.schemaErr)
+		}
+	})
+	return r.schemaErr
+}
+
+// GetActiveEvents retorna as ocorrências ativas, reconhecidas ou não
+func (r *TagAlarmRepository) GetActiveEvents() ([]domain.TagAlarmEvent, error) {
+	if err := r.ensureTable(); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := r.queryContext()
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+tagAlarmEventColumns+`
+		FROM tag_alarm_events e
+		JOIN tag_alarms a ON a.id = e.alarm_id
+		WHERE e.active
+		ORDER BY e.raised_at DESC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := make([]domain.TagAlarmEvent, 0)
+	for rows.Next() {
+		event, err := scanTagAlarmEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
+
+// GetEscalationCandidates retorna as ocorrências ativas sem reconhecimento
+// cujo alarme ainda tem regras de escalonamento não aplicadas
+func (r *TagAlarmRepository) GetEscalationCandidates() ([]domain.AlarmEscalationCandidate, error) {
+	if err := r.ensureTable(); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := r.queryContext()
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+tagAlarmEventColumns+`, a.escalation_rules
+		FROM tag_alarm_events e
+		JOIN tag_alarms a ON a.id = e.alarm_id
+		WHERE e.active
+			AND e.acknowledged_at IS NULL
+			AND jsonb_array_length(a.escalation_rules) > e.escalation_level
+		ORDER BY e.raised_at
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	candidates := make([]domain.AlarmEscalationCandidate, 0)
+	for rows.Next() {
+		var candidate domain.AlarmEscalationCandidate
+		var rulesJSON []byte
+
+		candidate.Event, err = scanTagAlarmEvent(rows, &rulesJSON)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(rulesJSON, &candidate.Rules); err != nil {
+			log.Printf("Aviso: regras de escalonamento inválidas no alarme %d: %v", candidate.Event.AlarmID, err)
+			continue
+		}
+		candidates = append(candidates, candidate)
+	}
+
+	return candidates, rows.Err()
+}
+
+// Escalate registra o novo nível e a severidade de uma ocorrência ainda não reconhecida
+func (r *TagAlarmRepository) Escalate(eventID int64, level int, severity string) error {
+	if err := r.ensureTable(); err != nil {
+		return err
+	}
+
+	ctx, cancel := r.queryContext()
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE tag_alarm_events
+		SET escalation_level = $1, severity = $2, escalated_at = NOW()
+		WHERE id = $3 AND acknowledged_at IS NULL
+	`, level, severity, eventID)
+	return err
+}
+
+// Acknowledge grava quem reconheceu a ocorrência e o comentário
+func (r *TagAlarmRepository) Acknowledge(eventID int64, userID int, comment string) (domain.TagAlarmEvent, error) {
+	if err := r.ensureTable(); err != nil {
+		return domain.TagAlarmEvent{}, err
+	}
+
+	ctx, cancel := r.queryContext()
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE tag_alarm_events
+		SET acknowledged_by = $1, acknowledged_at = NOW(), ack_comment = $2
+		WHERE id = $3 AND acknowledged_at IS NULL
+	`, userID, comment, eventID)
+	if err != nil {
+		return domain.TagAlarmEvent{}, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return domain.TagAlarmEvent{}, err
+	}
+
+	row := r.db.QueryRowContext(ctx, `
+		SELECT `+tagAlarmEventColumns+`
+		FROM tag_alarm_events e
+		JOIN tag_alarms a ON a.id = e.alarm_id
+		WHERE e.id = $1
+	`, eventID)
+
+	event, err := scanTagAlarmEvent(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.TagAlarmEvent{}, domain.ErrAlarmEventNotFound
+		}
+		return domain.TagAlarmEvent{}, err
+	}
+
+	if rowsAffected == 0 {
+		return event, domain.ErrAlarmAlreadyAcknowledged
+	}
+
+	return event, nil
+}
+
+// scanTagAlarmEvent lê uma ocorrência; extra recebe colunas adicionais da consulta
+func scanTagAlarmEvent(row interface{ Scan(...interface{}) error }, extra ...interface{}) (domain.TagAlarmEvent, error) {
+	var event domain.TagAlarmEvent
+	var escalatedAt, acknowledgedAt sql.NullTime
+	var acknowledgedBy sql.NullInt64
+
+	dest := []interface{}{
+		&event.ID,
+		&event.AlarmID,
+		&event.AlarmName,
+		&event.PLCID,
+		&event.TagID,
+		&event.Severity,
+		&event.Message,
+		&event.Active,
+		&event.RaisedAt,
+		&event.EscalationLevel,
+		&escalatedAt,
+		&acknowledgedBy,
+		&acknowledgedAt,
+		&event.AckComment,
+	}
+
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return domain.TagAlarmEvent{}, err
+	}
+
+	if escalatedAt.Valid {
+		event.EscalatedAt = &escalatedAt.Time
+	}
+	if acknowledgedBy.Valid {
+		userID := int(acknowledgedBy.Int64)
+		event.AcknowledgedBy = &userID
+	}
+	if acknowledgedAt.Valid {
+		event.AcknowledgedAt = &acknowledgedAt.Time
+	}
+
+	return event, nil
+}
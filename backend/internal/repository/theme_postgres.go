@@ -10,6 +10,7 @@ import (
 
 type ThemeRepository struct {
 	db *sql.DB
+	queryTimeout
 }
 
 func NewThemeRepository(db *sql.DB) *ThemeRepository {
@@ -17,9 +18,12 @@ func NewThemeRepository(db *sql.DB) *ThemeRepository {
 }
 
 func (r *ThemeRepository) GetAll() ([]domain.Theme, error) {
+	ctx, cancel := r.queryContext()
+	defer cancel()
+
 	// Verificar se a tabela themes existe
 	var exists bool
-	err := r.db.QueryRow(`
+	err := r.db.QueryRowContext(ctx, `
 		SELECT EXISTS (
 			SELECT FROM information_schema.tables 
 			WHERE table_schema = 'public' 
@@ -35,7 +39,7 @@ func (r *ThemeRepository) GetAll() ([]domain.Theme, error) {
 	// Se a tabela não existir, criá-la com valores padrão
 	if !exists {
 		log.Printf("Tabela themes não existe. Criando...")
-		_, err = r.db.Exec(`
+		_, err = r.db.ExecContext(ctx, `
 			CREATE TABLE IF NOT EXISTS themes (
 				id SERIAL PRIMARY KEY,
 				name VARCHAR(50) NOT NULL UNIQUE,
@@ -53,7 +57,7 @@ func (r *ThemeRepository) GetAll() ([]domain.Theme, error) {
 		}
 
 		// Inserir temas padrão
-		_, err = r.db.Exec(`
+		_, err = r.db.ExecContext(ctx, `
 			INSERT INTO themes (name, primary_color, secondary_color, text_color, background_color, accent_color, is_default)
 			VALUES 
 				('default', '#4285F4', '#34A853', '#202124', '#FFFFFF', '#FBBC05', true),
@@ -73,7 +77,7 @@ func (r *ThemeRepository) GetAll() ([]domain.Theme, error) {
 		ORDER BY name
 	`
 
-	rows, err := r.db.Query(query)
+	rows, err := r.db.QueryContext(ctx, query)
 	if err != nil {
 		log.Printf("Erro ao buscar temas: %v", err)
 		return getDefaultThemes(), nil
@@ -113,9 +117,12 @@ func (r *ThemeRepository) GetAll() ([]domain.Theme, error) {
 }
 
 func (r *ThemeRepository) GetByID(id int) (domain.Theme, error) {
+	ctx, cancel := r.queryContext()
+	defer cancel()
+
 	// Verificar se a tabela themes existe
 	var exists bool
-	err := r.db.QueryRow(`
+	err := r.db.QueryRowContext(ctx, `
 		SELECT EXISTS (
 			SELECT FROM information_schema.tables 
 			WHERE table_schema = 'public' 
@@ -140,7 +147,7 @@ func (r *ThemeRepository) GetByID(id int) (domain.Theme, error) {
 		WHERE id = $1
 	`
 
-	err = r.db.QueryRow(query, id).Scan(
+	err = r.db.QueryRowContext(ctx, query, id).Scan(
 		&theme.ID,
 		&theme.Name,
 		&theme.PrimaryColor,
@@ -163,6 +170,9 @@ func (r *ThemeRepository) GetByID(id int) (domain.Theme, error) {
 }
 
 func (r *ThemeRepository) GetByName(name string) (domain.Theme, error) {
+	ctx, cancel := r.queryContext()
+	defer cancel()
+
 	// Se o nome for vazio ou "default", retornar o tema padrão diretamente
 	if name == "" || name == "default" {
 		return getDefaultTheme(), nil
@@ -170,7 +180,7 @@ func (r *ThemeRepository) GetByName(name string) (domain.Theme, error) {
 
 	// Verificar se a tabela themes existe
 	var exists bool
-	err := r.db.QueryRow(`
+	err := r.db.QueryRowContext(ctx, `
 		SELECT EXISTS (
 			SELECT FROM information_schema.tables 
 			WHERE table_schema = 'public' 
@@ -195,7 +205,7 @@ func (r *ThemeRepository) GetByName(name string) (domain.Theme, error) {
 		WHERE name = $1
 	`
 
-	err = r.db.QueryRow(query, name).Scan(
+	err = r.db.QueryRowContext(ctx, query, name).Scan(
 		&theme.ID,
 		&theme.Name,
 		&theme.PrimaryColor,
@@ -218,9 +228,12 @@ func (r *ThemeRepository) GetByName(name string) (domain.Theme, error) {
 }
 
 func (r *ThemeRepository) GetDefault() (domain.Theme, error) {
+	ctx, cancel := r.queryContext()
+	defer cancel()
+
 	// Verificar se a tabela themes existe
 	var exists bool
-	err := r.db.QueryRow(`
+	err := r.db.QueryRowContext(ctx, `
 		SELECT EXISTS (
 			SELECT FROM information_schema.tables 
 			WHERE table_schema = 'public' 
@@ -246,7 +259,7 @@ func (r *ThemeRepository) GetDefault() (domain.Theme, error) {
 		LIMIT 1
 	`
 
-	err = r.db.QueryRow(query).Scan(
+	err = r.db.QueryRowContext(ctx, query).Scan(
 		&theme.ID,
 		&theme.Name,
 		&theme.PrimaryColor,
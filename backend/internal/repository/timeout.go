@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"app_padrao/pkg/database"
+	"context"
+	"time"
+)
+
+// queryTimeout é embutido nos repositórios PostgreSQL e limita a duração de
+// cada consulta, evitando que uma consulta lenta prenda a requisição
+type queryTimeout struct {
+	timeout time.Duration
+}
+
+// SetQueryTimeout define o prazo máximo das consultas (0 = sem prazo)
+func (q *queryTimeout) SetQueryTimeout(d time.Duration) {
+	q.timeout = d
+}
+
+// queryContext cria o contexto com prazo usado por uma operação do
+// repositório cujo método ainda não recebe o contexto da requisição. A
+// consulta respeita DB_QUERY_TIMEOUT_MS, mas não é cancelada quando o cliente
+// desiste. Passar ctx nos demais métodos (e nas interfaces de domain) fica
+// para uma mudança própria; métodos novos devem usar requestContext.
+func (q *queryTimeout) queryContext() (context.Context, context.CancelFunc) {
+	return database.WithTimeout(context.Background(), q.timeout)
+}
+
+// requestContext deriva o prazo da consulta do contexto da requisição: a
+// consulta é cancelada quando o cliente desiste ou o prazo da requisição
+// termina antes do prazo do repositório
+func (q *queryTimeout) requestContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return database.WithTimeout(ctx, q.timeout)
+}
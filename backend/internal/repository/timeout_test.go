@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRequestContextFollowsRequest(t *testing.T) {
+	q := &queryTimeout{timeout: time.Minute}
+
+	parent, cancelRequest := context.WithCancel(context.Background())
+	ctx, cancel := q.requestContext(parent)
+	defer cancel()
+
+	// O cliente desistiu da requisição: a consulta é cancelada
+	cancelRequest()
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("contexto da consulta não foi cancelado com a requisição")
+	}
+	if !errors.Is(ctx.Err(), context.Canceled) {
+		t.Errorf("ctx.Err() = %v, esperado context.Canceled", ctx.Err())
+	}
+}
+
+func TestRequestContextDeadline(t *testing.T) {
+	tests := []struct {
+		name            string
+		queryTimeout    time.Duration
+		requestDeadline time.Duration
+		want            time.Duration
+	}{
+		{"prazo do repositório menor", time.Second, time.Minute, time.Second},
+		{"prazo da requisição menor", time.Minute, time.Second, time.Second},
+		{"sem prazo no repositório", 0, time.Second, time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &queryTimeout{timeout: tt.queryTimeout}
+
+			parent, cancelRequest := context.WithTimeout(context.Background(), tt.requestDeadline)
+			defer cancelRequest()
+
+			start := time.Now()
+			ctx, cancel := q.requestContext(parent)
+			defer cancel()
+
+			deadline, ok := ctx.Deadline()
+			if !ok {
+				t.Fatal("contexto da consulta sem prazo")
+			}
+			if got := deadline.Sub(start); got < tt.want-100*time.Millisecond || got > tt.want+100*time.Millisecond {
+				t.Errorf("prazo = %v, esperado ~%v", got, tt.want)
+			}
+		})
+	}
+}
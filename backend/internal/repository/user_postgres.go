@@ -9,17 +9,80 @@ import (
 	"log"
 	"strings"
 	"time"
+
+	"github.com/lib/pq"
 )
 
 type UserRepository struct {
 	db *sql.DB
+	queryTimeout
 }
 
 func NewUserRepository(db *sql.DB) *UserRepository {
-	return &UserRepository{db: db}
+	r := &UserRepository{db: db}
+	r.ensureSchema()
+	return r
+}
+
+// ensureSchema adiciona colunas novas à tabela users quando ainda não existem
+func (r *UserRepository) ensureSchema() {
+	if r.db == nil {
+		return
+	}
+
+	_, err := r.db.Exec(`ALTER TABLE users ADD COLUMN IF NOT EXISTS email_verified_at TIMESTAMP`)
+	if err != nil {
+		log.Printf("Erro ao adicionar coluna email_verified_at: %v", err)
+	}
+
+	_, err = r.db.Exec(`ALTER TABLE users ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ`)
+	if err != nil {
+		log.Printf("Erro ao adicionar coluna deleted_at: %v", err)
+	}
+
+	_, err = r.db.Exec(`ALTER TABLE users ADD COLUMN IF NOT EXISTS sessions_revoked_at TIMESTAMPTZ`)
+	if err != nil {
+		log.Printf("Erro ao adicionar coluna sessions_revoked_at: %v", err)
+	}
+
+	// Liberar email e username de contas excluídas antes dos valores de descarte
+	_, err = r.db.Exec(`
+		UPDATE users
+		SET email = 'deleted-' || id || '@deleted.invalid', username = 'deleted_' || id,
+		    sessions_revoked_at = COALESCE(sessions_revoked_at, deleted_at)
+		WHERE deleted_at IS NOT NULL AND email NOT LIKE '%@deleted.invalid'
+	`)
+	if err != nil {
+		log.Printf("Erro ao anonimizar contas excluídas: %v", err)
+	}
+
+	// Garantir unicidade do username em bases criadas sem a restrição
+	var hasUnique bool
+	err = r.db.QueryRow(`
+		SELECT EXISTS (
+			SELECT 1
+			FROM pg_index i
+			JOIN pg_attribute a ON a.attrelid = i.indrelid AND a.attnum = ANY(i.indkey)
+			WHERE i.indrelid = 'users'::regclass AND i.indisunique
+			  AND i.indnatts = 1 AND a.attname = 'username'
+		)
+	`).Scan(&hasUnique)
+	if err != nil {
+		log.Printf("Erro ao verificar unicidade de username: %v", err)
+		return
+	}
+	if !hasUnique {
+		_, err = r.db.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS idx_users_username_unique ON users(username)`)
+		if err != nil {
+			log.Printf("Erro ao criar índice único de username: %v", err)
+		}
+	}
 }
 
 func (r *UserRepository) Create(user domain.User) (int, error) {
+	ctx, cancel := r.queryContext()
+	defer cancel()
+
 	var id int
 	query := `
         INSERT INTO users (username, email, password, role, is_active, full_name, phone) 
@@ -27,7 +90,7 @@ func (r *UserRepository) Create(user domain.User) (int, error) {
         RETURNING id
     `
 
-	err := r.db.QueryRow(
+	err := r.db.QueryRowContext(ctx,
 		query,
 		user.Username,
 		user.Email,
@@ -39,6 +102,16 @@ func (r *UserRepository) Create(user domain.User) (int, error) {
 	).Scan(&id)
 
 	if err != nil {
+		// Cadastro concorrente com o mesmo username ou email
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			if strings.Contains(pqErr.Constraint, "username") {
+				return 0, domain.ErrUsernameInUse
+			}
+			if strings.Contains(pqErr.Constraint, "email") {
+				return 0, domain.ErrEmailInUse
+			}
+		}
 		log.Printf("Erro ao criar usuário: %v", err)
 		return 0, err
 	}
@@ -47,17 +120,22 @@ func (r *UserRepository) Create(user domain.User) (int, error) {
 }
 
 func (r *UserRepository) GetByID(id int) (domain.User, error) {
+	ctx, cancel := r.queryContext()
+	defer cancel()
+
 	var user domain.User
 	var fullName, phone, avatarURL sql.NullString
-	var lastLogin sql.NullTime
+	var lastLogin, emailVerifiedAt sql.NullTime
 
 	query := `
-        SELECT id, username, email, role, is_active, full_name, phone, last_login, avatar_url
-        FROM users_with_avatars
-        WHERE id = $1
+        SELECT v.id, v.username, v.email, v.role, v.is_active, v.full_name, v.phone, v.last_login, v.avatar_url,
+               u.email_verified_at
+        FROM users_with_avatars v
+        JOIN users u ON u.id = v.id
+        WHERE v.id = $1 AND u.deleted_at IS NULL
     `
 
-	err := r.db.QueryRow(query, id).Scan(
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
 		&user.ID,
 		&user.Username,
 		&user.Email,
@@ -67,6 +145,7 @@ func (r *UserRepository) GetByID(id int) (domain.User, error) {
 		&phone,
 		&lastLogin,
 		&avatarURL,
+		&emailVerifiedAt,
 	)
 
 	if err != nil {
@@ -102,21 +181,74 @@ func (r *UserRepository) GetByID(id int) (domain.User, error) {
 		user.AvatarURL = ""
 	}
 
+	if emailVerifiedAt.Valid {
+		verifiedAt := emailVerifiedAt.Time
+		user.EmailVerifiedAt = &verifiedAt
+	}
+
+	return user, nil
+}
+
+// GetByUsername busca um usuário pelo nome de usuário
+func (r *UserRepository) GetByUsername(username string) (domain.User, error) {
+	ctx, cancel := r.queryContext()
+	defer cancel()
+
+	var user domain.User
+	var fullName, phone sql.NullString
+
+	query := `
+        SELECT id, username, email, role, is_active, full_name, phone
+        FROM users
+        WHERE username = $1
+    `
+
+	err := r.db.QueryRowContext(ctx, query, username).Scan(
+		&user.ID,
+		&user.Username,
+		&user.Email,
+		&user.Role,
+		&user.IsActive,
+		&fullName,
+		&phone,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.User{}, domain.ErrUserNotFound
+		}
+		log.Printf("Erro ao buscar usuário por username: %v", err)
+		return domain.User{}, err
+	}
+
+	if fullName.Valid {
+		user.FullName = fullName.String
+	}
+
+	if phone.Valid {
+		user.Phone = phone.String
+	}
+
 	return user, nil
 }
 
 func (r *UserRepository) GetByEmail(email string) (domain.User, error) {
+	ctx, cancel := r.queryContext()
+	defer cancel()
+
 	var user domain.User
 	var fullName, phone, avatarURL sql.NullString
-	var lastLogin sql.NullTime
+	var lastLogin, emailVerifiedAt sql.NullTime
 
 	query := `
-        SELECT id, username, email, password, role, is_active, full_name, phone, last_login, avatar_url
-        FROM users_with_avatars
-        WHERE email = $1
+        SELECT v.id, v.username, v.email, v.password, v.role, v.is_active, v.full_name, v.phone, v.last_login, v.avatar_url,
+               u.email_verified_at
+        FROM users_with_avatars v
+        JOIN users u ON u.id = v.id
+        WHERE v.email = $1 AND u.deleted_at IS NULL
     `
 
-	err := r.db.QueryRow(query, email).Scan(
+	err := r.db.QueryRowContext(ctx, query, email).Scan(
 		&user.ID,
 		&user.Username,
 		&user.Email,
@@ -127,6 +259,7 @@ func (r *UserRepository) GetByEmail(email string) (domain.User, error) {
 		&phone,
 		&lastLogin,
 		&avatarURL,
+		&emailVerifiedAt,
 	)
 
 	if err != nil {
@@ -162,10 +295,18 @@ func (r *UserRepository) GetByEmail(email string) (domain.User, error) {
 		user.AvatarURL = ""
 	}
 
+	if emailVerifiedAt.Valid {
+		verifiedAt := emailVerifiedAt.Time
+		user.EmailVerifiedAt = &verifiedAt
+	}
+
 	return user, nil
 }
 
 func (r *UserRepository) Update(user domain.User) error {
+	ctx, cancel := r.queryContext()
+	defer cancel()
+
 	// Verificar se o usuário existe primeiro
 	_, err := r.GetByID(user.ID)
 	if err != nil {
@@ -230,7 +371,7 @@ func (r *UserRepository) Update(user domain.User) error {
 
 	query := fmt.Sprintf("UPDATE users SET %s WHERE id = $%d", strings.Join(setClause, ", "), paramIndex)
 
-	result, err := r.db.Exec(query, params...)
+	result, err := r.db.ExecContext(ctx, query, params...)
 	if err != nil {
 		log.Printf("Erro ao atualizar usuário: %v", err)
 		return err
@@ -250,13 +391,16 @@ func (r *UserRepository) Update(user domain.User) error {
 }
 
 func (r *UserRepository) UpdateLastLogin(userID int) error {
+	ctx, cancel := r.queryContext()
+	defer cancel()
+
 	query := `
         UPDATE users
         SET last_login = NOW()
         WHERE id = $1
     `
 
-	_, err := r.db.Exec(query, userID)
+	_, err := r.db.ExecContext(ctx, query, userID)
 	if err != nil {
 		log.Printf("Erro ao atualizar last_login: %v", err)
 		return err
@@ -265,10 +409,154 @@ func (r *UserRepository) UpdateLastLogin(userID int) error {
 	return nil
 }
 
+func (r *UserRepository) SetEmailVerified(userID int, verifiedAt time.Time) error {
+	ctx, cancel := r.queryContext()
+	defer cancel()
+
+	query := `
+        UPDATE users
+        SET email_verified_at = $1
+        WHERE id = $2
+    `
+
+	result, err := r.db.ExecContext(ctx, query, verifiedAt, userID)
+	if err != nil {
+		log.Printf("Erro ao marcar email como verificado: %v", err)
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		log.Printf("Erro ao obter linhas afetadas: %v", err)
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return domain.ErrUserNotFound
+	}
+
+	return nil
+}
+
+// UpdatePassword grava o novo hash de senha do usuário
+func (r *UserRepository) UpdatePassword(userID int, passwordHash string) error {
+	ctx, cancel := r.queryContext()
+	defer cancel()
+
+	query := `
+        UPDATE users
+        SET password = $1, updated_at = NOW()
+        WHERE id = $2 AND deleted_at IS NULL
+    `
+
+	result, err := r.db.ExecContext(ctx, query, passwordHash, userID)
+	if err != nil {
+		log.Printf("Erro ao atualizar senha: %v", err)
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		log.Printf("Erro ao obter linhas afetadas: %v", err)
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return domain.ErrUserNotFound
+	}
+
+	return nil
+}
+
+// SoftDelete marca o usuário como excluído, desativa a conta, revoga as
+// sessões e remove os dados pessoais. Email e username são trocados por
+// valores de descarte, liberando-os para um novo cadastro. A linha é mantida
+// para preservar as referências (auditoria, última escrita em tags).
+func (r *UserRepository) SoftDelete(userID int, deletedAt time.Time) error {
+	ctx, cancel := r.queryContext()
+	defer cancel()
+
+	query := `
+        UPDATE users
+        SET deleted_at = $1, is_active = false, full_name = NULL, phone = NULL, updated_at = $1,
+            email = 'deleted-' || id || '@deleted.invalid', username = 'deleted_' || id,
+            sessions_revoked_at = $1
+        WHERE id = $2 AND deleted_at IS NULL
+    `
+
+	result, err := r.db.ExecContext(ctx, query, deletedAt.Truncate(time.Second), userID)
+	if err != nil {
+		log.Printf("Erro ao excluir conta do usuário: %v", err)
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		log.Printf("Erro ao obter linhas afetadas: %v", err)
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return domain.ErrUserNotFound
+	}
+
+	return nil
+}
+
+// RevokeSessions invalida os tokens do usuário emitidos antes de at. O
+// instante é truncado em segundos, a precisão do iat dos tokens.
+func (r *UserRepository) RevokeSessions(userID int, at time.Time) error {
+	ctx, cancel := r.queryContext()
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE users SET sessions_revoked_at = $1 WHERE id = $2 AND deleted_at IS NULL`,
+		at.Truncate(time.Second), userID)
+	if err != nil {
+		log.Printf("Erro ao revogar sessões do usuário %d: %v", userID, err)
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
+
+// GetSessionsRevokedAt retorna o instante da última revogação das sessões do
+// usuário (zero se nunca revogadas). Usuários excluídos retornam
+// domain.ErrUserNotFound.
+func (r *UserRepository) GetSessionsRevokedAt(userID int) (time.Time, error) {
+	ctx, cancel := r.queryContext()
+	defer cancel()
+
+	var revokedAt sql.NullTime
+	err := r.db.QueryRowContext(ctx,
+		`SELECT sessions_revoked_at FROM users WHERE id = $1 AND deleted_at IS NULL`,
+		userID).Scan(&revokedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return time.Time{}, domain.ErrUserNotFound
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	if !revokedAt.Valid {
+		return time.Time{}, nil
+	}
+	return revokedAt.Time, nil
+}
+
 func (r *UserRepository) Delete(id int) error {
+	ctx, cancel := r.queryContext()
+	defer cancel()
+
 	query := "DELETE FROM users WHERE id = $1"
 
-	result, err := r.db.Exec(query, id)
+	result, err := r.db.ExecContext(ctx, query, id)
 	if err != nil {
 		log.Printf("Erro ao excluir usuário: %v", err)
 		return err
@@ -288,15 +576,18 @@ func (r *UserRepository) Delete(id int) error {
 }
 
 func (r *UserRepository) List(page, pageSize int) ([]domain.User, int, error) {
+	ctx, cancel := r.queryContext()
+	defer cancel()
+
 	offset := (page - 1) * pageSize
 
 	// Log para depuração
 	log.Printf("Executando contagem de usuários")
 
 	// Mantemos a contagem na tabela users para não alterar o comportamento
-	countQuery := "SELECT COUNT(*) FROM users"
+	countQuery := "SELECT COUNT(*) FROM users WHERE deleted_at IS NULL"
 	var total int
-	err := r.db.QueryRow(countQuery).Scan(&total)
+	err := r.db.QueryRowContext(ctx, countQuery).Scan(&total)
 	if err != nil {
 		log.Printf("Erro ao contar usuários: %v", err)
 		return []domain.User{}, 0, err
@@ -321,13 +612,14 @@ func (r *UserRepository) List(page, pageSize int) ([]domain.User, int, error) {
             COALESCE(phone, '') as phone,
             COALESCE(avatar_url, '') as avatar_url
         FROM users_with_avatars
+        WHERE id IN (SELECT id FROM users WHERE deleted_at IS NULL)
         ORDER BY id
         LIMIT $1 OFFSET $2
     `
 
 	log.Printf("Executando consulta: LIMIT %d OFFSET %d", pageSize, offset)
 
-	rows, err := r.db.Query(query, pageSize, offset)
+	rows, err := r.db.QueryContext(ctx, query, pageSize, offset)
 	if err != nil {
 		log.Printf("Erro na consulta SQL: %v", err)
 		return []domain.User{}, 0, err
@@ -367,11 +659,14 @@ func (r *UserRepository) List(page, pageSize int) ([]domain.User, int, error) {
 }
 
 func (r *UserRepository) HasPermission(userID int, permissionCode string) (bool, error) {
+	ctx, cancel := r.queryContext()
+	defer cancel()
+
 	// Verificar role do usuário
 	var role string
-	query := "SELECT role FROM users WHERE id = $1"
+	query := "SELECT role FROM users WHERE id = $1 AND deleted_at IS NULL"
 
-	err := r.db.QueryRow(query, userID).Scan(&role)
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(&role)
 	if err != nil {
 		log.Printf("Erro ao verificar role do usuário %d: %v", userID, err)
 		if errors.Is(err, sql.ErrNoRows) {
@@ -385,10 +680,30 @@ func (r *UserRepository) HasPermission(userID int, permissionCode string) (bool,
 		return true, nil
 	}
 
-	// Para outros usuários, verificar na tabela de permissões (implementação básica)
-	// Na prática, implementaria a consulta real às permissões
-	log.Printf("Verificando permissão %s para usuário %d com role %s", permissionCode, userID, role)
+	// Para os demais, procurar a permissão na role do usuário e nas roles
+	// das quais ela herda, até domain.MaxRoleDepth níveis
+	query = `
+		WITH RECURSIVE role_chain AS (
+			SELECT id, parent_role_id, 1 AS depth FROM roles WHERE name = $1
+			UNION ALL
+			SELECT r.id, r.parent_role_id, rc.depth + 1
+			FROM roles r
+			JOIN role_chain rc ON r.id = rc.parent_role_id
+			WHERE rc.depth < $3
+		)
+		SELECT EXISTS (
+			SELECT 1
+			FROM role_permissions rp
+			JOIN permissions p ON p.id = rp.permission_id
+			WHERE rp.role_id IN (SELECT id FROM role_chain) AND p.code = $2
+		)
+	`
+
+	var allowed bool
+	if err := r.db.QueryRowContext(ctx, query, role, permissionCode, domain.MaxRoleDepth).Scan(&allowed); err != nil {
+		log.Printf("Erro ao verificar permissão %s para usuário %d com role %s: %v", permissionCode, userID, role, err)
+		return false, err
+	}
 
-	// Solução temporária para teste
-	return true, nil
+	return allowed, nil
 }
@@ -0,0 +1,200 @@
+// internal/service/emaildomains.go
+package service
+
+import (
+	"app_padrao/internal/domain"
+	"context"
+	"encoding/json"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	// emailDomainsKey guarda a lista de domínios de email permitidos no cadastro
+	emailDomainsKey = "config:email_domains"
+	// emailDomainsSourceKey guarda o AUTH_ALLOWED_EMAIL_DOMAINS que semeou a
+	// lista, para detectar mudanças na configuração
+	emailDomainsSourceKey = "config:email_domains_source"
+	// emailDomainsCacheTTL é quanto a lista lida do Redis é reutilizada
+	emailDomainsCacheTTL = 5 * time.Minute
+)
+
+// emailDomainAllowlist é a lista de domínios de email aceitos no cadastro,
+// compartilhada entre instâncias pelo Redis e mantida em memória por
+// emailDomainsCacheTTL. Lista vazia aceita qualquer domínio.
+type emailDomainAllowlist struct {
+	client *redis.Client // nil = apenas a lista em memória
+	key    string
+
+	mu       sync.Mutex
+	domains  []string
+	loadedAt time.Time
+}
+
+// SetAllowedEmailDomains define os domínios aceitos no cadastro. A lista do
+// Redis é substituída pela configuração quando ainda não existe ou quando a
+// configuração mudou desde a última gravação; enquanto a configuração não
+// muda, as alterações feitas pela API sobrevivem a reinícios.
+func (s *UserService) SetAllowedEmailDomains(domains []string, client *redis.Client, keyPrefix string) {
+	allowlist := &emailDomainAllowlist{
+		client:   client,
+		key:      keyPrefix + emailDomainsKey,
+		domains:  normalizeEmailDomains(domains),
+		loadedAt: time.Now(),
+	}
+
+	if client != nil {
+		if err := allowlist.seed(keyPrefix + emailDomainsSourceKey); err != nil {
+			log.Printf("Aviso: erro ao gravar domínios de email permitidos no Redis: %v", err)
+		}
+		// Forçar a leitura da lista do Redis na primeira verificação
+		allowlist.loadedAt = time.Time{}
+	}
+
+	s.emailDomains = allowlist
+}
+
+// seed grava a lista da configuração no Redis se a configuração gravada em
+// sourceKey for diferente (ou ausente)
+func (a *emailDomainAllowlist) seed(sourceKey string) error {
+	ctx := context.Background()
+	source := strings.Join(a.domains, ",")
+
+	stored, err := a.client.Get(ctx, sourceKey).Result()
+	if err == nil && stored == source {
+		return nil
+	}
+	if err != nil && err != redis.Nil {
+		return err
+	}
+	if err == nil {
+		log.Printf("AUTH_ALLOWED_EMAIL_DOMAINS mudou: lista de domínios do Redis substituída por %q", source)
+	} else if len(a.domains) == 0 {
+		// Sem registro da configuração de origem (lista gravada por versões
+		// anteriores) e sem domínios no ambiente: uma lista definida pela API
+		// não é apagada
+		exists, err := a.client.Exists(ctx, a.key).Result()
+		if err != nil {
+			return err
+		}
+		if exists > 0 {
+			return a.client.Set(ctx, sourceKey, source, 0).Err()
+		}
+	}
+
+	data, err := json.Marshal(a.domains)
+	if err != nil {
+		return err
+	}
+
+	pipe := a.client.TxPipeline()
+	pipe.Set(ctx, a.key, data, 0)
+	pipe.Set(ctx, sourceKey, source, 0)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// UpdateAllowedEmailDomains substitui a lista de domínios aceitos no cadastro
+// em todas as instâncias. Lista vazia libera qualquer domínio.
+func (s *UserService) UpdateAllowedEmailDomains(domains []string, userID int) ([]string, error) {
+	if s.emailDomains == nil {
+		s.SetAllowedEmailDomains(nil, nil, "")
+	}
+	allowlist := s.emailDomains
+	domains = normalizeEmailDomains(domains)
+
+	if allowlist.client != nil {
+		data, err := json.Marshal(domains)
+		if err != nil {
+			return nil, err
+		}
+		if err := allowlist.client.Set(context.Background(), allowlist.key, data, 0).Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	allowlist.mu.Lock()
+	allowlist.domains = domains
+	allowlist.loadedAt = time.Now()
+	allowlist.mu.Unlock()
+
+	log.Printf("Auditoria: entity_type=config entity_id=email_domains action=update user_id=%d domains=%q",
+		userID, strings.Join(domains, ","))
+
+	return domains, nil
+}
+
+// checkEmailDomain retorna domain.ErrEmailDomainNotAllowed se o domínio do
+// email não estiver na lista de domínios aceitos
+func (s *UserService) checkEmailDomain(email string) error {
+	if s.emailDomains == nil {
+		return nil
+	}
+
+	allowed := s.emailDomains.current()
+	if len(allowed) == 0 {
+		return nil
+	}
+
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return domain.ErrEmailDomainNotAllowed
+	}
+	emailDomain := strings.ToLower(strings.TrimSpace(email[at+1:]))
+
+	for _, d := range allowed {
+		if emailDomain == d {
+			return nil
+		}
+	}
+	return domain.ErrEmailDomainNotAllowed
+}
+
+// current retorna a lista em memória, relida do Redis quando expirada. Se o
+// Redis falhar, a última lista conhecida continua valendo.
+func (a *emailDomainAllowlist) current() []string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.client == nil || time.Since(a.loadedAt) < emailDomainsCacheTTL {
+		return a.domains
+	}
+
+	data, err := a.client.Get(context.Background(), a.key).Bytes()
+	if err != nil && err != redis.Nil {
+		log.Printf("Aviso: erro ao ler domínios de email permitidos do Redis: %v", err)
+		return a.domains
+	}
+
+	var domains []string
+	if err == nil {
+		if err := json.Unmarshal(data, &domains); err != nil {
+			log.Printf("Aviso: lista de domínios de email inválida no Redis: %v", err)
+			return a.domains
+		}
+	}
+
+	a.domains = normalizeEmailDomains(domains)
+	a.loadedAt = time.Now()
+	return a.domains
+}
+
+// normalizeEmailDomains remove espaços, "@" inicial, duplicatas e converte
+// para minúsculas
+func normalizeEmailDomains(domains []string) []string {
+	seen := make(map[string]bool, len(domains))
+	result := make([]string, 0, len(domains))
+	for _, d := range domains {
+		d = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(d), "@"))
+		if d == "" || seen[d] {
+			continue
+		}
+		seen[d] = true
+		result = append(result, d)
+	}
+	return result
+}
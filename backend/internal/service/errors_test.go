@@ -0,0 +1,57 @@
+package service
+
+import (
+	"app_padrao/internal/domain"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// fakeMissingPLCRepo não tem nenhum PLC cadastrado
+type fakeMissingPLCRepo struct {
+	domain.PLCRepository
+}
+
+func (r *fakeMissingPLCRepo) GetByID(id int) (domain.PLC, error) {
+	return domain.PLC{}, domain.ErrPLCNotFound
+}
+
+func TestServiceErrorsWrapDomainSentinels(t *testing.T) {
+	s := NewPLCService(&fakeMissingPLCRepo{}, nil, &fakeManagerCache{})
+	limit := 10
+
+	tests := []struct {
+		name string
+		call func() error
+		want error
+	}{
+		{"GetByID", func() error { _, err := s.GetByID(42); return err }, domain.ErrPLCNotFound},
+		{"SetPLCMonitoring", func() error { _, err := s.SetPLCMonitoring(42, false); return err }, domain.ErrPLCNotFound},
+		{"SetPLCTagLimit", func() error { _, err := s.SetPLCTagLimit(42, &limit); return err }, domain.ErrPLCNotFound},
+		{"GetConnectionByPLCID", func() error { _, err := s.manager.GetConnectionByPLCID(42); return err }, domain.ErrPLCNotFound},
+		{"StartDebugMonitorWithInterval", func() error { return s.StartDebugMonitorWithInterval(1) }, domain.ErrMonitorNotStarted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.call()
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("errors.Is(%v, %v) = false", err, tt.want)
+			}
+
+			// O erro continua identificável depois de outro envoltório com %w
+			if wrapped := fmt.Errorf("handler: %w", err); !errors.Is(wrapped, tt.want) {
+				t.Errorf("sentinela perdida ao envolver %v", err)
+			}
+		})
+	}
+}
+
+func TestServiceSentinelAliases(t *testing.T) {
+	if !errors.Is(ErrMonitoringNotActive, domain.ErrMonitorNotStarted) {
+		t.Error("ErrMonitoringNotActive deveria ser domain.ErrMonitorNotStarted")
+	}
+	if !errors.Is(domain.ErrTagGroupNotFound, domain.ErrScanGroupNotFound) {
+		t.Error("ErrTagGroupNotFound deveria ser domain.ErrScanGroupNotFound")
+	}
+}
@@ -0,0 +1,283 @@
+// internal/service/jwtsecrets.go
+package service
+
+import (
+	"app_padrao/internal/domain"
+	"app_padrao/pkg/jwt"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	// jwtSecretRedisKey guarda o segredo usado para assinar os tokens
+	jwtSecretRedisKey = "config:jwt_secret"
+	// jwtSecretOldRedisKey guarda o segredo anterior, com TTL igual à carência
+	jwtSecretOldRedisKey = "config:jwt_secret_old"
+	// jwtSecretSourceRedisKey guarda o hash SHA-256 do JWT_SECRET que
+	// semeou os segredos do Redis, para detectar a troca do segredo no ambiente
+	jwtSecretSourceRedisKey = "config:jwt_secret_source"
+	// jwtSecretsCacheTTL é quanto os segredos lidos do Redis são reutilizados
+	jwtSecretsCacheTTL = 30 * time.Second
+	// jwtSecretBytes é o tamanho dos segredos gerados na rotação
+	jwtSecretBytes = 64
+)
+
+// jwtSecrets guarda o segredo de assinatura (JWT_SECRET) e o anterior
+// (JWT_SECRET_OLD), aceito apenas na verificação até oldExpiresAt. Os dois
+// são compartilhados entre instâncias pelo Redis e relidos a cada
+// jwtSecretsCacheTTL, para que uma rotação feita em uma instância chegue às
+// demais.
+//
+// Os segredos ficam em texto puro no Redis: quem lê ou escreve nessas chaves
+// pode forjar tokens de qualquer usuário. O acesso ao Redis deve ser tratado
+// como acesso ao JWT_SECRET.
+type jwtSecrets struct {
+	client    *redis.Client // nil = apenas os segredos em memória
+	key       string
+	oldKey    string
+	sourceKey string
+	grace     time.Duration
+
+	// Serializa rotações e releituras do Redis
+	reloadMu sync.Mutex
+
+	mu           sync.RWMutex
+	primary      string
+	old          string
+	oldExpiresAt time.Time
+	loadedAt     time.Time
+}
+
+// SetJWTSecretRotation habilita a rotação do segredo JWT. oldSecret
+// (JWT_SECRET_OLD) continua aceito por grace a partir de agora.
+//
+// O Redis guarda o hash do JWT_SECRET que semeou os segredos. Enquanto
+// JWT_SECRET não muda, as rotações feitas pela API prevalecem. Quando muda
+// (ex.: troca após um vazamento), os segredos do Redis são substituídos pelo
+// do ambiente e o segredo anterior do Redis deixa de ser aceito; apenas
+// JWT_SECRET_OLD, se definido, continua valendo durante a carência.
+func (s *UserService) SetJWTSecretRotation(oldSecret string, grace time.Duration, client *redis.Client, keyPrefix string) {
+	secrets := &jwtSecrets{
+		client:    client,
+		key:       keyPrefix + jwtSecretRedisKey,
+		oldKey:    keyPrefix + jwtSecretOldRedisKey,
+		sourceKey: keyPrefix + jwtSecretSourceRedisKey,
+		grace:     grace,
+		primary:   s.jwtSecretKey,
+		loadedAt:  time.Now(),
+	}
+	if oldSecret != "" {
+		secrets.old = oldSecret
+		secrets.oldExpiresAt = time.Now().Add(grace)
+	}
+
+	if client != nil {
+		if err := secrets.seed(s.jwtSecretKey, oldSecret); err != nil {
+			log.Printf("Aviso: erro ao gravar segredos JWT no Redis: %v", err)
+		}
+		// Forçar a leitura dos segredos do Redis na primeira verificação
+		secrets.loadedAt = time.Time{}
+		if primary, _ := secrets.current(); primary != s.jwtSecretKey {
+			log.Println("Segredo JWT do Redis (rotacionado pela API) difere de JWT_SECRET; usando o do Redis")
+		}
+	}
+
+	s.jwtSecrets = secrets
+}
+
+// seed grava os segredos do ambiente no Redis quando ainda não há segredos
+// lá ou quando JWT_SECRET mudou desde a última semeadura
+func (j *jwtSecrets) seed(envSecret, oldSecret string) error {
+	ctx := context.Background()
+
+	sum := sha256.Sum256([]byte(envSecret))
+	source := hex.EncodeToString(sum[:])
+
+	stored, err := j.client.Get(ctx, j.sourceKey).Result()
+	if err != nil && err != redis.Nil {
+		return err
+	}
+	if stored == source {
+		return nil
+	}
+	if stored != "" {
+		log.Println("JWT_SECRET mudou: segredos JWT do Redis substituídos pelo do ambiente")
+	}
+
+	pipe := j.client.TxPipeline()
+	pipe.Set(ctx, j.key, envSecret, 0)
+	pipe.Set(ctx, j.sourceKey, source, 0)
+	if oldSecret != "" {
+		pipe.Set(ctx, j.oldKey, oldSecret, j.grace)
+	} else {
+		pipe.Del(ctx, j.oldKey)
+	}
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// RotateJWTSecret gera um novo segredo de assinatura. O segredo atual passa a
+// ser aceito apenas na verificação durante a carência configurada, para que
+// os clientes migrem sem novo login.
+func (s *UserService) RotateJWTSecret(userID int) (domain.JWTSecretRotation, error) {
+	if s.jwtSecrets == nil {
+		return domain.JWTSecretRotation{}, domain.ErrJWTRotationNotConfigured
+	}
+	secrets := s.jwtSecrets
+
+	buf := make([]byte, jwtSecretBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return domain.JWTSecretRotation{}, fmt.Errorf("erro ao gerar segredo JWT: %w", err)
+	}
+	newSecret := hex.EncodeToString(buf)
+
+	secrets.reloadMu.Lock()
+	defer secrets.reloadMu.Unlock()
+
+	current, _ := secrets.secrets()
+	now := time.Now()
+
+	if secrets.client != nil {
+		pipe := secrets.client.TxPipeline()
+		pipe.Set(context.Background(), secrets.oldKey, current, secrets.grace)
+		pipe.Set(context.Background(), secrets.key, newSecret, 0)
+		if _, err := pipe.Exec(context.Background()); err != nil {
+			return domain.JWTSecretRotation{}, fmt.Errorf("erro ao gravar segredos JWT no Redis: %w", err)
+		}
+	}
+
+	secrets.mu.Lock()
+	secrets.primary = newSecret
+	secrets.old = current
+	secrets.oldExpiresAt = now.Add(secrets.grace)
+	secrets.loadedAt = now
+	secrets.mu.Unlock()
+
+	log.Printf("Auditoria: entity_type=config entity_id=jwt_secret action=rotate user_id=%d old_secret_expires_at=%s",
+		userID, secrets.oldExpiresAt.Format(time.RFC3339))
+
+	return domain.JWTSecretRotation{
+		RotatedAt:          now,
+		OldSecretExpiresAt: secrets.oldExpiresAt,
+		GracePeriodHours:   int(secrets.grace / time.Hour),
+	}, nil
+}
+
+// ValidateToken valida um token de sessão com o segredo atual e, se falhar,
+// com o anterior ainda na carência. Tokens de usuários excluídos ou emitidos
+// antes da revogação das sessões do usuário são recusados.
+func (s *UserService) ValidateToken(token string) (int, error) {
+	primary, old := s.currentJWTSecrets()
+
+	claims, err := jwt.ValidateTokenClaims(token, primary)
+	if err != nil && old != "" {
+		// O erro do segredo atual prevalece (ex.: token expirado)
+		if oldClaims, oldErr := jwt.ValidateTokenClaims(token, old); oldErr == nil {
+			claims, err = oldClaims, nil
+		}
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	if err := s.checkSessionRevoked(claims); err != nil {
+		return 0, err
+	}
+	return claims.UserID, nil
+}
+
+// currentJWTSecrets retorna o segredo de assinatura e o anterior ("" quando
+// expirado ou sem rotação configurada)
+func (s *UserService) currentJWTSecrets() (string, string) {
+	if s.jwtSecrets == nil {
+		return s.jwtSecretKey, ""
+	}
+	return s.jwtSecrets.current()
+}
+
+// current retorna os segredos em memória, relidos do Redis quando expirados.
+// Se o Redis falhar, os últimos segredos conhecidos continuam valendo.
+func (j *jwtSecrets) current() (string, string) {
+	j.mu.RLock()
+	fresh := j.client == nil || time.Since(j.loadedAt) < jwtSecretsCacheTTL
+	j.mu.RUnlock()
+
+	if !fresh && j.reloadMu.TryLock() {
+		j.reload()
+		j.reloadMu.Unlock()
+	}
+
+	return j.secrets()
+}
+
+// secrets retorna os segredos em memória, descartando o anterior após a
+// carência
+func (j *jwtSecrets) secrets() (string, string) {
+	j.mu.RLock()
+	primary, old, expiresAt := j.primary, j.old, j.oldExpiresAt
+	j.mu.RUnlock()
+
+	if old != "" && !time.Now().Before(expiresAt) {
+		j.mu.Lock()
+		if j.old == old {
+			j.old = ""
+			log.Println("Segredo JWT anterior expirado: tokens assinados com ele deixam de ser aceitos")
+		}
+		j.mu.Unlock()
+		old = ""
+	}
+	return primary, old
+}
+
+// reload relê os segredos do Redis. Deve ser chamado com j.reloadMu travado.
+func (j *jwtSecrets) reload() {
+	ctx := context.Background()
+
+	primary, err := j.client.Get(ctx, j.key).Result()
+	if err != nil && err != redis.Nil {
+		log.Printf("Aviso: erro ao ler segredo JWT do Redis: %v", err)
+		j.touch()
+		return
+	}
+
+	old, err := j.client.Get(ctx, j.oldKey).Result()
+	if err != nil && err != redis.Nil {
+		log.Printf("Aviso: erro ao ler segredo JWT anterior do Redis: %v", err)
+		j.touch()
+		return
+	}
+
+	var expiresAt time.Time
+	if old != "" {
+		ttl, err := j.client.PTTL(ctx, j.oldKey).Result()
+		if err != nil || ttl <= 0 {
+			// Sem TTL legível, a carência conta a partir de agora
+			ttl = j.grace
+		}
+		expiresAt = time.Now().Add(ttl)
+	}
+
+	j.mu.Lock()
+	if primary != "" {
+		j.primary = primary
+	}
+	j.old = old
+	j.oldExpiresAt = expiresAt
+	j.loadedAt = time.Now()
+	j.mu.Unlock()
+}
+
+// touch adia a próxima releitura do Redis
+func (j *jwtSecrets) touch() {
+	j.mu.Lock()
+	j.loadedAt = time.Now()
+	j.mu.Unlock()
+}
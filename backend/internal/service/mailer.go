@@ -0,0 +1,19 @@
+// internal/service/mailer.go
+package service
+
+import (
+	"log"
+)
+
+// LogMailer implementa domain.Mailer apenas registrando as mensagens no log.
+// Útil em desenvolvimento enquanto não há um servidor SMTP configurado.
+type LogMailer struct{}
+
+func NewLogMailer() *LogMailer {
+	return &LogMailer{}
+}
+
+func (m *LogMailer) Send(to, subject, body string) error {
+	log.Printf("Email para %s | Assunto: %s\n%s", to, subject, body)
+	return nil
+}
@@ -4,11 +4,18 @@ package service
 import (
 	"app_padrao/internal/domain"
 	"app_padrao/internal/repository"
+	"app_padrao/pkg/lrucache"
+	"app_padrao/pkg/plc"
+	"context"
 	"errors"
 	"fmt"
+	"io"
 	"log"
+	"os"
+	"regexp"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -16,32 +23,99 @@ import (
 var (
 	ErrInvalidPLCName      = errors.New("nome do PLC é obrigatório")
 	ErrInvalidIPAddress    = errors.New("endereço IP do PLC é obrigatório")
-	ErrInvalidTagName      = errors.New("nome da tag é obrigatório")
+	ErrInvalidTagName      = errors.New("nome da tag inválido")
 	ErrInvalidDataType     = errors.New("tipo de dados da tag é obrigatório ou inválido")
-	ErrInvalidBitOffset    = errors.New("bit offset deve estar entre 0 e 7 para tipo bool")
+	ErrInvalidBitOffset    = domain.ErrInvalidBitOffset
 	ErrPLCNotActive        = errors.New("PLC não está ativo")
-	ErrMonitoringNotActive = errors.New("serviço de monitoramento não está ativo")
+	ErrMonitoringNotActive = domain.ErrMonitorNotStarted
+	ErrSearchQueryTooLong  = errors.New("termo de busca deve ter no máximo 100 caracteres")
+	ErrInvalidWriteRate    = errors.New("limite de taxa de escrita não pode ser negativo")
+	ErrTagLimitExceeded    = domain.ErrTagLimitExceeded
+	ErrInvalidTagLimit     = errors.New("limite de tags não pode ser negativo")
+
+	ErrInvalidPLCStatusFilter = errors.New("status deve ser online, offline ou unknown")
+)
+
+// maxTagSearchQueryLength limita o tamanho do termo de busca de tags
+const maxTagSearchQueryLength = 100
+
+// Tamanho padrão e máximo da página na listagem de PLCs
+const (
+	DefaultPLCPageSize = 50
+	MaxPLCPageSize     = 100
+)
+
+// Tamanho padrão e máximo da página na busca de tags
+const (
+	DefaultTagSearchPageSize = 20
+	MaxTagSearchPageSize     = 100
 )
 
 // PLCConfig contém configurações para o serviço PLC
 type PLCConfig struct {
-	MonitoringEnabled      bool
-	DetailedLoggingEnabled bool
-	CacheEnabled           bool
-	MaxRetryAttempts       int
-	RetryInterval          time.Duration
-	DefaultTagScanRate     int
+	MonitoringEnabled       bool
+	DetailedLoggingEnabled  bool
+	CacheEnabled            bool
+	MaxRetryAttempts        int
+	RetryInterval           time.Duration
+	DefaultTagScanRate      int
+	HistoryQueueSize        int    // Capacidade da fila de gravação do histórico
+	HistoryWorkers          int    // Workers que gravam o histórico em lotes
+	DebugMonitorIntervalSec int    // Intervalo do monitor de depuração (0 = desativado)
+	ShutdownDrainTimeoutSec int    // Espera por leituras/escritas em andamento ao parar
+	RedisKeyPrefix          string // Namespace das chaves Redis da instância
+	SyncWaitTimeoutSec      int    // Espera máxima pela sincronização inicial antes de consultar os PLCs
+	MaxTagsPerPLC           int    // Tags permitidas por PLC (0 = sem limite)
+	MaxStreamClients        int    // Clientes simultâneos de streaming de tags (0 = sem limite)
+	// Falhas de leitura consecutivas que desativam a tag (0 = nunca desativa)
+	ConsecutiveErrorThreshold int
+	SyncInterval              time.Duration // Intervalo da sincronização periódica PostgreSQL -> Redis
+	AutoAdaptScanRates        bool          // Adaptar semanalmente o scan rate das tags pelo histórico
+	HistoryRetentionDays      int           // Dias de histórico mantidos (0 = sem limite)
+	StartupStaggerMs          int           // Atraso (ms) entre o início de cada PLC
+	PingIntervalSec           int           // Intervalo entre pings das conexões ativas (0 = desativado)
+	MaxConcurrentConnections  int           // PLCs conectando ao mesmo tempo (0 = sem limite)
+	EnableAccessLog           bool          // Registrar quais usuários leram quais tags (auditoria)
+	MetadataCacheSize         int           // PLCs mantidos em memória por GetByID (0 = desativado)
+	MetadataCacheTTLSec       int           // Validade (s) de cada PLC em memória
+
+	// Convenção de nomes das tags (padrão ".*" aceita qualquer nome)
+	TagNamePattern     string
+	TagNameDescription string
+	// Padrão compilado na primeira validação (ver tagNamePattern)
+	tagNameRegexp *regexp.Regexp
+
+	// Gravação de bytes brutos em DBs, sem intertravamentos (desligada por padrão)
+	EnableDBPoke bool
 }
 
 // DefaultPLCConfig retorna uma configuração padrão
 func DefaultPLCConfig() PLCConfig {
 	return PLCConfig{
-		MonitoringEnabled:      true,
-		DetailedLoggingEnabled: true,
-		CacheEnabled:           true,
-		MaxRetryAttempts:       3,
-		RetryInterval:          2 * time.Second,
-		DefaultTagScanRate:     1000, // 1 segundo
+		MonitoringEnabled:       true,
+		DetailedLoggingEnabled:  true,
+		CacheEnabled:            true,
+		MaxRetryAttempts:        3,
+		RetryInterval:           2 * time.Second,
+		DefaultTagScanRate:      1000, // 1 segundo
+		HistoryQueueSize:        defaultHistoryQueueSize,
+		HistoryWorkers:          defaultHistoryWorkers,
+		DebugMonitorIntervalSec: 5,
+		ShutdownDrainTimeoutSec: 5,
+		SyncWaitTimeoutSec:      60,
+		MaxTagsPerPLC:           500,
+		MaxStreamClients:        100,
+
+		ConsecutiveErrorThreshold: defaultConsecutiveErrorThreshold,
+		SyncInterval:              5 * time.Minute,
+		HistoryRetentionDays:      defaultHistoryRetentionDays,
+		StartupStaggerMs:          int(defaultStartupStagger / time.Millisecond),
+		PingIntervalSec:           int(defaultPingInterval / time.Second),
+		MaxConcurrentConnections:  defaultMaxConcurrentConnections,
+		MetadataCacheSize:         defaultPLCMetadataCacheSize,
+		MetadataCacheTTLSec:       int(defaultPLCMetadataCacheTTL / time.Second),
+
+		TagNamePattern: defaultTagNamePattern,
 	}
 }
 
@@ -58,26 +132,95 @@ type PLCService struct {
 	// Cache Redis para valores de tags
 	cache domain.PLCCache
 
+	// PLCs em memória, evitando Redis/PostgreSQL em GetByID
+	plcMetaCache *lrucache.Cache[int, domain.PLC]
+
 	// Gerenciador de PLCs
 	manager *PLCManager
 
 	// Serviço de sincronização
 	syncService *PLCSyncService
 
+	// Histórico de valores das tags (opcional)
+	historyRepo domain.PLCTagHistoryRepository
+
+	// Alarmes das tags (opcional)
+	alarmRepo domain.TagAlarmRepository
+
+	// Sites (plantas) dos PLCs (opcional)
+	siteRepo domain.PLCSiteRepository
+
+	// Grupos de varredura das tags (opcional)
+	scanGroupRepo domain.PLCScanGroupRepository
+
+	// Anotações das tags (opcional) e usuários, para identificar administradores
+	annotationRepo domain.TagAnnotationRepository
+	userRepo       domain.UserRepository
+
+	// Dependências de escrita entre tags (opcional)
+	dependencyRepo domain.TagDependencyRepository
+
+	// Log de acesso às tags (fila nil = desativado)
+	accessLogRepo   domain.TagAccessLogRepository
+	accessLogQueue  chan domain.TagAccessLog
+	accessLogCancel context.CancelFunc
+	accessLogDone   chan struct{}
+
 	// Estado
 	isRunning bool
 	mu        sync.RWMutex // protege o estado isRunning
 
-	// Configuração
-	config PLCConfig
+	// syncComplete indica que a inicialização do monitoramento (incluindo a
+	// sincronização completa inicial) terminou; usado pela sonda de startup
+	syncComplete atomic.Bool
 
-	// Mapeamento de endereços corretos para referência
-	addressMap map[string]map[string]struct {
-		DBNumber   int
-		ByteOffset int
-		BitOffset  int
-		DataType   string
-	}
+	// Configuração (alterável em execução por ReloadConfig)
+	config   PLCConfig
+	configMu sync.RWMutex
+
+	// Compila uma única vez o padrão de nomes das tags
+	tagNameOnce sync.Once
+
+	// Endereços das tags ativas por DB ("DB11") e nome, montado a partir do banco
+	addressMap       map[string]map[string]TagAddress
+	addressMu        sync.RWMutex
+	addressRefreshMu sync.Mutex // serializa reconstruções concorrentes
+
+	// Aplicação das configurações publicadas pelas réplicas (nil = sem Redis)
+	configWatchCancel context.CancelFunc
+	configWatchDone   chan struct{}
+
+	// Adaptação automática de scan rate (nil = desativada)
+	adaptCancel context.CancelFunc
+
+	// Limpeza diária do histórico
+	pruneCancel context.CancelFunc
+	nextPrune   time.Time
+	pruneMu     sync.Mutex
+
+	// Monitor de depuração
+	debugMonitorCancel context.CancelFunc
+	debugOutput        io.Writer
+	debugMu            sync.Mutex
+
+	// Vagas de clientes de streaming (nil = sem limite)
+	streamSlots chan struct{}
+
+	// Streamings de eventos abertos, um por usuário e PLC
+	eventStreams   map[eventStreamKey]bool
+	eventStreamsMu sync.Mutex
+
+	// Intertravamentos verificados antes de cada escrita
+	writeValidators []WriteValidator
+	validatorsMu    sync.RWMutex
+}
+
+// TagAddress é o endereço de uma tag no PLC
+type TagAddress struct {
+	DBNumber   int
+	ByteOffset int
+	BitOffset  int
+	DataType   string
 }
 
 // NewPLCService cria um novo serviço de PLC
@@ -109,8 +252,8 @@ func NewPLCServiceWithConfig(
 	var redisTagRepo domain.PLCTagRepository
 
 	if redisClient != nil {
-		redisPLCRepo = repository.NewPLCRedisRepository(redisClient)
-		redisTagRepo = repository.NewPLCTagRedisRepository(redisClient)
+		redisPLCRepo = repository.NewPLCRedisRepositoryWithPrefix(redisClient, config.RedisKeyPrefix)
+		redisTagRepo = repository.NewPLCTagRedisRepositoryWithPrefix(redisClient, config.RedisKeyPrefix)
 	} else {
 		// Se Redis não está disponível, usar repositórios mock que sempre delegam ao PostgreSQL
 		redisPLCRepo = pgPLCRepo
@@ -126,17 +269,11 @@ func NewPLCServiceWithConfig(
 		cache:        cache,
 		isRunning:    false,
 		config:       config,
-		addressMap: make(map[string]map[string]struct {
-			DBNumber   int
-			ByteOffset int
-			BitOffset  int
-			DataType   string
-		}),
+		addressMap:   make(map[string]map[string]TagAddress),
+		debugOutput:  os.Stdout,
+		plcMetaCache: newPLCMetadataCache(config.MetadataCacheSize, config.MetadataCacheTTLSec),
 	}
 
-	// Inicializar mapeamento de endereços conhecido
-	s.initAddressMap()
-
 	// Criar serviço de sincronização
 	s.syncService = NewPLCSyncService(
 		pgPLCRepo,
@@ -146,111 +283,172 @@ func NewPLCServiceWithConfig(
 		true, // Fazer importação inicial
 	)
 
+	if config.SyncInterval > 0 {
+		if err := s.syncService.SetSyncInterval(config.SyncInterval); err != nil {
+			log.Printf("Aviso: intervalo de sincronização ignorado: %v", err)
+		}
+	}
+
+	s.syncService.SetETagClient(redisClient, config.RedisKeyPrefix)
+	s.syncService.SetLockClient(redisClient, config.RedisKeyPrefix)
+
+	// O gerenciador só consulta os PLCs depois da importação inicial para o Redis
+	syncReady := make(chan struct{})
+	s.syncService.SetReadyChannel(syncReady)
+
+	if config.MaxStreamClients > 0 {
+		s.streamSlots = make(chan struct{}, config.MaxStreamClients)
+	}
+
+	// PLCs alterados saem do cache em memória
+	s.syncService.SetPLCChangeListener(s.invalidatePLCMetadata)
+
+	// Manter o mapa de endereços atualizado quando PLCs ou tags mudam
+	s.syncService.SetChangeHandler(func() {
+		if err := s.RefreshAddressMap(); err != nil {
+			log.Printf("Aviso: erro ao atualizar mapa de endereços: %v", err)
+		}
+	})
+
 	// Criar gerenciador de PLCs
 	s.manager = NewPLCManager(redisPLCRepo, redisTagRepo, cache)
+	s.manager.keyPrefix = config.RedisKeyPrefix
+	s.manager.lastWriteRepo = pgTagRepo
+	s.manager.onTagAutoDisabled = s.persistAutoDisabledTag
+	s.manager.onStatusChange = s.handlePLCStatusChange
+	s.manager.validateWrite = s.validateWrite
+	s.manager.config.ConsecutiveErrorThreshold = config.ConsecutiveErrorThreshold
+	s.manager.config.DefaultTagScanRate = config.DefaultTagScanRate
+	s.manager.syncReady = syncReady
+	if config.RetryInterval > 0 {
+		s.manager.config.RetryInterval = config.RetryInterval
+	}
+	if config.HistoryQueueSize > 0 {
+		s.manager.config.HistoryQueueSize = config.HistoryQueueSize
+	}
+	if config.HistoryWorkers > 0 {
+		s.manager.config.HistoryWorkers = config.HistoryWorkers
+	}
+	if config.ShutdownDrainTimeoutSec > 0 {
+		s.manager.config.ShutdownDrainTimeout = time.Duration(config.ShutdownDrainTimeoutSec) * time.Second
+	}
+	if config.SyncWaitTimeoutSec > 0 {
+		s.manager.config.SyncWaitTimeout = time.Duration(config.SyncWaitTimeoutSec) * time.Second
+	}
+	if config.PingIntervalSec >= 0 {
+		s.manager.config.PingInterval = time.Duration(config.PingIntervalSec) * time.Second
+	}
+	if config.StartupStaggerMs >= 0 {
+		s.manager.config.StartupStagger = time.Duration(config.StartupStaggerMs) * time.Millisecond
+	}
+	if config.MaxConcurrentConnections >= 0 {
+		s.manager.config.MaxConcurrentConnections = config.MaxConcurrentConnections
+	}
+
+	// Aplicar alterações de configuração feitas em qualquer réplica
+	if redisClient != nil {
+		ctx, cancel := context.WithCancel(context.Background())
+		s.configWatchCancel = cancel
+		s.configWatchDone = make(chan struct{})
+		go s.watchConfigChanges(ctx, redisClient)
+	}
 
 	return s
 }
 
-// initAddressMap inicializa o mapeamento de endereços para referência
-func (s *PLCService) initAddressMap() {
-	// DB11 - mapeamentos conhecidos
-	db11Map := make(map[string]struct {
-		DBNumber   int
-		ByteOffset int
-		BitOffset  int
-		DataType   string
-	})
+// cfg retorna uma cópia da configuração atual
+func (s *PLCService) cfg() PLCConfig {
+	s.configMu.RLock()
+	defer s.configMu.RUnlock()
+	return s.config
+}
 
-	// Mapeamento de bits
-	for i := 0; i <= 7; i++ {
-		bitName := fmt.Sprintf("bit_%d", i)
-		if i == 0 {
-			bitName = "bit" // caso especial para o primeiro
-		}
+// GetManager retorna o gerenciador de conexões com os PLCs
+func (s *PLCService) GetManager() *PLCManager {
+	return s.manager
+}
 
-		db11Map[bitName] = struct {
-			DBNumber   int
-			ByteOffset int
-			BitOffset  int
-			DataType   string
-		}{
-			DBNumber:   11,
-			ByteOffset: 0,
-			BitOffset:  i,
-			DataType:   "bool",
-		}
+// RefreshAddressMap reconstrói o mapa de endereços a partir das tags ativas
+// no PostgreSQL
+func (s *PLCService) RefreshAddressMap() error {
+	s.addressRefreshMu.Lock()
+	defer s.addressRefreshMu.Unlock()
+
+	plcs, err := s.pgPLCRepo.GetAll()
+	if err != nil {
+		return fmt.Errorf("erro ao buscar PLCs para o mapa de endereços: %w", err)
 	}
 
-	// Bits adicionais em outra posição
-	db11Map["bit_8"] = struct {
-		DBNumber   int
-		ByteOffset int
-		BitOffset  int
-		DataType   string
-	}{
-		DBNumber:   11,
-		ByteOffset: 1,
-		BitOffset:  0,
-		DataType:   "bool",
-	}
-
-	db11Map["bit_9"] = struct {
-		DBNumber   int
-		ByteOffset int
-		BitOffset  int
-		DataType   string
-	}{
-		DBNumber:   11,
-		ByteOffset: 1,
-		BitOffset:  1,
-		DataType:   "bool",
-	}
-
-	// Valores inteiros
-	intPositions := map[string]int{
-		"int":   2,
-		"int_1": 4,
-		"int_2": 6,
-		"int_3": 8,
-		"int_4": 10,
-		"int_5": 12,
-	}
-
-	for name, pos := range intPositions {
-		db11Map[name] = struct {
-			DBNumber   int
-			ByteOffset int
-			BitOffset  int
-			DataType   string
-		}{
-			DBNumber:   11,
-			ByteOffset: pos,
-			BitOffset:  0,
-			DataType:   "int",
+	addressMap := make(map[string]map[string]TagAddress)
+	for _, plc := range plcs {
+		tags, err := s.pgTagRepo.GetPLCTags(plc.ID)
+		if err != nil {
+			return fmt.Errorf("erro ao buscar tags do PLC %d para o mapa de endereços: %w", plc.ID, err)
+		}
+
+		for _, tag := range tags {
+			if !tag.Active {
+				continue
+			}
+
+			dbName := fmt.Sprintf("DB%d", tag.DBNumber)
+			if addressMap[dbName] == nil {
+				addressMap[dbName] = make(map[string]TagAddress)
+			}
+			addressMap[dbName][tag.Name] = TagAddress{
+				DBNumber:   tag.DBNumber,
+				ByteOffset: tag.ByteOffset,
+				BitOffset:  tag.BitOffset,
+				DataType:   tag.DataType,
+			}
 		}
 	}
 
-	// Adicionar o mapa de DB11 ao mapa principal
-	s.addressMap["DB11"] = db11Map
+	s.addressMu.Lock()
+	s.addressMap = addressMap
+	s.addressMu.Unlock()
+
+	return nil
 }
 
 // GetPLCAddressMap retorna o mapeamento de endereços para um DB específico
-func (s *PLCService) GetPLCAddressMap(dbName string) (map[string]struct {
-	DBNumber   int
-	ByteOffset int
-	BitOffset  int
-	DataType   string
-}, bool) {
+func (s *PLCService) GetPLCAddressMap(dbName string) (map[string]TagAddress, bool) {
+	s.addressMu.RLock()
+	defer s.addressMu.RUnlock()
+
 	dbMap, exists := s.addressMap[dbName]
-	return dbMap, exists
+	if !exists {
+		return nil, false
+	}
+
+	// Cópia para que o chamador não acesse o mapa sem o lock
+	result := make(map[string]TagAddress, len(dbMap))
+	for name, addr := range dbMap {
+		result[name] = addr
+	}
+	return result, true
+}
+
+// lookupAddress busca o endereço mapeado de uma tag pelo DB e nome
+func (s *PLCService) lookupAddress(dbNumber int, name string) (TagAddress, bool) {
+	s.addressMu.RLock()
+	defer s.addressMu.RUnlock()
+
+	addr, exists := s.addressMap[fmt.Sprintf("DB%d", dbNumber)][name]
+	return addr, exists
 }
 
 // GetByID busca um PLC pelo ID
 func (s *PLCService) GetByID(id int) (domain.PLC, error) {
-	// Primeiro tentar no Redis para resposta mais rápida
+	// PLC em memória dispensa qualquer chamada de rede
+	if plc, ok := s.plcMetaCache.Get(id); ok {
+		return plc, nil
+	}
+
+	// Depois tentar no Redis para resposta mais rápida
 	plc, err := s.redisPLCRepo.GetByID(id)
 	if err == nil {
+		s.plcMetaCache.Set(id, plc)
 		return plc, nil
 	}
 
@@ -264,20 +462,21 @@ func (s *PLCService) GetByID(id int) (domain.PLC, error) {
 	}
 
 	// Armazenar no Redis para acessos futuros se o cache estiver ativado
-	if s.config.CacheEnabled {
+	if s.cfg().CacheEnabled {
 		_, storeErr := s.redisPLCRepo.Create(plc)
 		if storeErr != nil {
 			log.Printf("Aviso: erro ao armazenar PLC %d no Redis: %v", id, storeErr)
 		}
 	}
 
+	s.plcMetaCache.Set(id, plc)
 	return plc, nil
 }
 
 // GetAll retorna todos os PLCs
 func (s *PLCService) GetAll() ([]domain.PLC, error) {
 	// Tentar Redis primeiro se o cache estiver ativado
-	if s.config.CacheEnabled {
+	if s.cfg().CacheEnabled {
 		plcs, err := s.redisPLCRepo.GetAll()
 		if err == nil && len(plcs) > 0 {
 			return plcs, nil
@@ -291,7 +490,7 @@ func (s *PLCService) GetAll() ([]domain.PLC, error) {
 	}
 
 	// Armazenar no Redis para futuras consultas se o cache estiver ativado
-	if s.config.CacheEnabled {
+	if s.cfg().CacheEnabled {
 		for _, plc := range plcs {
 			_, err := s.redisPLCRepo.Create(plc)
 			if err != nil {
@@ -303,10 +502,55 @@ func (s *PLCService) GetAll() ([]domain.PLC, error) {
 	return plcs, nil
 }
 
+// ListPLCs retorna uma página de PLCs filtrados, do Redis quando o cache está
+// ativo. bypassCache consulta o PostgreSQL diretamente e só é atendido para
+// administradores.
+func (s *PLCService) ListPLCs(ctx context.Context, filter domain.PLCFilter, page, pageSize int, bypassCache bool, userID int) ([]domain.PLC, int, error) {
+	filter.Name = strings.TrimSpace(filter.Name)
+	filter.Status = strings.ToLower(strings.TrimSpace(filter.Status))
+	switch filter.Status {
+	case "", "online", "offline", "unknown":
+	default:
+		return nil, 0, ErrInvalidPLCStatusFilter
+	}
+
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = DefaultPLCPageSize
+	} else if pageSize > MaxPLCPageSize {
+		pageSize = MaxPLCPageSize
+	}
+
+	if bypassCache {
+		isAdmin, err := s.isAdmin(userID)
+		if err != nil {
+			log.Printf("Aviso: erro ao verificar administrador para leitura sem cache: %v", err)
+		}
+		bypassCache = isAdmin
+	}
+
+	// Redis vazio (ainda não sincronizado) também cai no PostgreSQL
+	if s.cfg().CacheEnabled && !bypassCache {
+		plcs, total, err := s.redisPLCRepo.ListWithFilter(ctx, filter, page, pageSize)
+		if err == nil && total > 0 {
+			return plcs, total, nil
+		}
+	}
+
+	plcs, total, err := s.pgPLCRepo.ListWithFilter(ctx, filter, page, pageSize)
+	if err != nil {
+		return nil, 0, fmt.Errorf("erro ao buscar PLCs: %w", err)
+	}
+
+	return plcs, total, nil
+}
+
 // GetActivePLCs retorna PLCs ativos
 func (s *PLCService) GetActivePLCs() ([]domain.PLC, error) {
 	// Tentar Redis primeiro se o cache estiver ativado
-	if s.config.CacheEnabled {
+	if s.cfg().CacheEnabled {
 		plcs, err := s.redisPLCRepo.GetActivePLCs()
 		if err == nil && len(plcs) > 0 {
 			return plcs, nil
@@ -320,7 +564,7 @@ func (s *PLCService) GetActivePLCs() ([]domain.PLC, error) {
 	}
 
 	// Armazenar no Redis para futuras consultas se o cache estiver ativado
-	if s.config.CacheEnabled {
+	if s.cfg().CacheEnabled {
 		for _, plc := range plcs {
 			_, err := s.redisPLCRepo.Create(plc)
 			if err != nil {
@@ -343,6 +587,10 @@ func (s *PLCService) Create(plc domain.PLC) (int, error) {
 		return 0, ErrInvalidIPAddress
 	}
 
+	if err := s.applyPLCSite(&plc); err != nil {
+		return 0, err
+	}
+
 	// Definir data de criação
 	plc.CreatedAt = time.Now()
 
@@ -356,7 +604,7 @@ func (s *PLCService) Create(plc domain.PLC) (int, error) {
 	plc.ID = id
 
 	// Criar no Redis também se o cache estiver ativado
-	if s.config.CacheEnabled {
+	if s.cfg().CacheEnabled {
 		_, err = s.redisPLCRepo.Create(plc)
 		if err != nil {
 			log.Printf("Aviso: erro ao armazenar novo PLC no Redis: %v", err)
@@ -382,11 +630,16 @@ func (s *PLCService) Update(plc domain.PLC) error {
 		return ErrInvalidIPAddress
 	}
 
+	if err := s.applyPLCSite(&plc); err != nil {
+		return err
+	}
+
 	// Atualizar data
 	plc.UpdatedAt = time.Now()
 
 	// Atualizar no banco de dados principal
 	err := s.pgPLCRepo.Update(plc)
+	s.invalidatePLCMetadata(plc.ID)
 	if err != nil {
 		if errors.Is(err, domain.ErrPLCNotFound) {
 			return fmt.Errorf("PLC com ID %d não encontrado para atualização: %w", plc.ID, domain.ErrPLCNotFound)
@@ -395,7 +648,7 @@ func (s *PLCService) Update(plc domain.PLC) error {
 	}
 
 	// Atualizar no Redis também se o cache estiver ativado
-	if s.config.CacheEnabled {
+	if s.cfg().CacheEnabled {
 		err = s.redisPLCRepo.Update(plc)
 		if err != nil {
 			// Tentar criar caso não exista
@@ -435,17 +688,61 @@ func (s *PLCService) Update(plc domain.PLC) error {
 	return nil
 }
 
+// SetPLCMonitoring habilita ou pausa o monitoramento de um PLC sem
+// desativá-lo. Ao pausar, a goroutine de monitoramento é cancelada na hora;
+// ao habilitar, o próximo ciclo de runAllPLCs retoma o PLC.
+func (s *PLCService) SetPLCMonitoring(plcID int, enabled bool) (domain.PLC, error) {
+	plc, err := s.pgPLCRepo.GetByID(plcID)
+	if err != nil {
+		if errors.Is(err, domain.ErrPLCNotFound) {
+			return domain.PLC{}, fmt.Errorf("PLC com ID %d não encontrado: %w", plcID, domain.ErrPLCNotFound)
+		}
+		return domain.PLC{}, fmt.Errorf("erro ao buscar PLC com ID %d: %w", plcID, err)
+	}
+
+	previous := plc.MonitoringEnabled
+	plc.MonitoringEnabled = enabled
+
+	if previous != enabled {
+		if err := s.Update(plc); err != nil {
+			return domain.PLC{}, err
+		}
+
+		log.Printf("Auditoria: entity_type=plc plc_id=%d field=monitoring_enabled old_value=%t new_value=%t",
+			plcID, previous, enabled)
+	}
+
+	if !enabled && s.manager != nil {
+		s.manager.StopPLCMonitor(plcID)
+	}
+
+	plc.EffectiveStatus = s.EffectiveMonitoringStatus(plc)
+	return plc, nil
+}
+
+// EffectiveMonitoringStatus informa se o monitoramento do PLC está de fato
+// em execução, considerando Active, MonitoringEnabled e o gerenciador
+func (s *PLCService) EffectiveMonitoringStatus(plc domain.PLC) string {
+	switch {
+	case !plc.Active:
+		return domain.MonitoringInactive
+	case !plc.MonitoringEnabled, !plc.SiteMonitoringEnabled:
+		return domain.MonitoringPaused
+	case s.manager != nil && s.manager.IsMonitoring(plc.ID):
+		return domain.MonitoringRunning
+	default:
+		return domain.MonitoringStopped
+	}
+}
+
 // Delete remove um PLC
 func (s *PLCService) Delete(id int) error {
-	// Excluir tags associadas primeiro
-	tags, err := s.GetPLCTags(id)
-	if err == nil {
-		for _, tag := range tags {
-			err := s.DeleteTag(tag.ID)
-			if err != nil && !errors.Is(err, domain.ErrPLCTagNotFound) {
-				log.Printf("Aviso: erro ao excluir tag %d do PLC %d: %v", tag.ID, id, err)
-			}
-		}
+	// Excluir tags associadas primeiro, em um único comando
+	deletedTags, err := s.pgTagRepo.DeleteByPLCID(id)
+	if err != nil {
+		log.Printf("Aviso: erro ao excluir tags do PLC %d: %v", id, err)
+	} else if deletedTags > 0 {
+		log.Printf("%d tags do PLC %d excluídas", deletedTags, id)
 	}
 
 	// Se o monitoramento estiver ativo, parar a conexão primeiro
@@ -464,6 +761,7 @@ func (s *PLCService) Delete(id int) error {
 
 	// Excluir do banco de dados principal
 	err = s.pgPLCRepo.Delete(id)
+	s.invalidatePLCMetadata(id)
 	if err != nil {
 		if errors.Is(err, domain.ErrPLCNotFound) {
 			return fmt.Errorf("PLC com ID %d não encontrado para exclusão: %w", id, domain.ErrPLCNotFound)
@@ -471,12 +769,26 @@ func (s *PLCService) Delete(id int) error {
 		return fmt.Errorf("erro ao excluir PLC do banco de dados: %w", err)
 	}
 
-	// Excluir do Redis também se o cache estiver ativado
-	if s.config.CacheEnabled {
+	// Excluir do Redis também se o cache estiver ativado: o PLC, suas tags e
+	// os valores em cache, para que nada do PLC excluído continue acessível
+	if s.cfg().CacheEnabled {
 		err = s.redisPLCRepo.Delete(id)
 		if err != nil && !errors.Is(err, domain.ErrPLCNotFound) {
 			log.Printf("Aviso: erro ao excluir PLC do Redis: %v", err)
 		}
+
+		if _, err := s.redisTagRepo.DeleteByPLCID(id); err != nil {
+			log.Printf("Aviso: erro ao excluir tags do PLC %d do Redis: %v", id, err)
+		}
+
+		if err := s.cache.DeletePLCValues(id); err != nil {
+			log.Printf("Aviso: erro ao excluir valores do PLC %d do cache: %v", id, err)
+		}
+	}
+
+	// Notificar o serviço de sincronização
+	if s.syncService != nil && s.syncService.IsRunning() {
+		s.syncService.NotifyPLCChange(id)
 	}
 
 	return nil
@@ -484,6 +796,20 @@ func (s *PLCService) Delete(id int) error {
 
 // GetPLCTags busca as tags de um PLC
 func (s *PLCService) GetPLCTags(plcID int) ([]domain.PLCTag, error) {
+	return s.getPLCTags(plcID, 0)
+}
+
+// GetPLCTagsWithDerivative busca as tags de um PLC preenchendo a taxa de variação
+// calculada sobre a janela informada
+func (s *PLCService) GetPLCTagsWithDerivative(plcID int, windowMs int) ([]domain.PLCTag, error) {
+	if windowMs <= 0 {
+		return nil, ErrInvalidDerivativeWindow
+	}
+	return s.getPLCTags(plcID, windowMs)
+}
+
+// getPLCTags busca as tags de um PLC; derivativeWindowMs > 0 inclui a taxa de variação
+func (s *PLCService) getPLCTags(plcID int, derivativeWindowMs int) ([]domain.PLCTag, error) {
 	// Verificar se o PLC existe
 	_, err := s.GetByID(plcID)
 	if err != nil {
@@ -493,11 +819,11 @@ func (s *PLCService) GetPLCTags(plcID int) ([]domain.PLCTag, error) {
 	// Tentar buscar do Redis primeiro se o cache estiver ativado
 	var tags []domain.PLCTag
 
-	if s.config.CacheEnabled {
+	if s.cfg().CacheEnabled {
 		tags, err = s.redisTagRepo.GetPLCTags(plcID)
 		if err == nil && len(tags) > 0 {
 			// Carregar valores atuais das tags
-			err = s.loadTagValues(plcID, tags)
+			err = s.loadTagValues(plcID, tags, derivativeWindowMs)
 			if err != nil {
 				log.Printf("Aviso: erro ao carregar valores das tags: %v", err)
 			}
@@ -512,7 +838,7 @@ func (s *PLCService) GetPLCTags(plcID int) ([]domain.PLCTag, error) {
 	}
 
 	// Armazenar no Redis para futuras consultas se o cache estiver ativado
-	if s.config.CacheEnabled {
+	if s.cfg().CacheEnabled {
 		for _, tag := range tags {
 			_, err := s.redisTagRepo.Create(tag)
 			if err != nil {
@@ -522,7 +848,7 @@ func (s *PLCService) GetPLCTags(plcID int) ([]domain.PLCTag, error) {
 	}
 
 	// Carregar valores atuais
-	err = s.loadTagValues(plcID, tags)
+	err = s.loadTagValues(plcID, tags, derivativeWindowMs)
 	if err != nil {
 		log.Printf("Aviso: erro ao carregar valores das tags: %v", err)
 	}
@@ -530,8 +856,9 @@ func (s *PLCService) GetPLCTags(plcID int) ([]domain.PLCTag, error) {
 	return tags, nil
 }
 
-// loadTagValues carrega os valores atuais de um conjunto de tags
-func (s *PLCService) loadTagValues(plcID int, tags []domain.PLCTag) error {
+// loadTagValues carrega os valores atuais de um conjunto de tags.
+// Com derivativeWindowMs > 0 também calcula a taxa de variação de cada tag.
+func (s *PLCService) loadTagValues(plcID int, tags []domain.PLCTag, derivativeWindowMs int) error {
 	if len(tags) == 0 {
 		return nil
 	}
@@ -549,39 +876,65 @@ func (s *PLCService) loadTagValues(plcID int, tags []domain.PLCTag) error {
 	}
 
 	// Mapear valores por ID da tag
-	valueMap := make(map[int]interface{})
-	for _, value := range values {
-		valueMap[value.TagID] = value.Value
+	valueMap := make(map[int]*domain.TagValue)
+	for i := range values {
+		valueMap[values[i].TagID] = &values[i]
+	}
+
+	// Derivadas de todas as tags em uma única consulta ao histórico
+	var rates map[int]float64
+	if derivativeWindowMs > 0 {
+		tagIDs := make([]int, len(tags))
+		for i, tag := range tags {
+			tagIDs[i] = tag.ID
+		}
+		rates = s.tagDerivatives(plcID, tagIDs, derivativeWindowMs)
 	}
 
 	// Atribuir valores às tags
 	for i := range tags {
-		if value, exists := valueMap[tags[i].ID]; exists {
-			tags[i].CurrentValue = value
-		} else {
-			tags[i].CurrentValue = nil
+		tags[i].CurrentValue = currentReading(tags[i], valueMap[tags[i].ID])
+
+		if rate, ok := rates[tags[i].ID]; ok {
+			tags[i].ChangeRate = &rate
 		}
 	}
 
 	return nil
 }
 
+// currentReading monta o valor atual exposto na API. Em tags com UnpackBits
+// a word lida é convertida em um mapa de sinais nomeados.
+func currentReading(tag domain.PLCTag, value *domain.TagValue) *domain.TagReading {
+	reading := value.Reading()
+	if reading == nil || !tag.UnpackBits || reading.Value == nil {
+		return reading
+	}
+
+	if word, ok := plc.ToFloat64(reading.Value); ok {
+		reading.Value = plc.UnpackBitWord(uint16(word), tag.BitLabels)
+	}
+
+	return reading
+}
+
 // GetTagByID busca uma tag pelo ID
 func (s *PLCService) GetTagByID(id int) (domain.PLCTag, error) {
 	// Tentar buscar do Redis primeiro se o cache estiver ativado
 	var tag domain.PLCTag
 	var err error
 
-	if s.config.CacheEnabled {
+	if s.cfg().CacheEnabled {
 		tag, err = s.redisTagRepo.GetByID(id)
 		if err == nil {
 			// Carregar valor atual
 			tagValue, err := s.cache.GetTagValue(tag.PLCID, tag.ID)
 			if err == nil && tagValue != nil {
-				tag.CurrentValue = tagValue.Value
+				tag.CurrentValue = currentReading(tag, tagValue)
 			} else {
 				tag.CurrentValue = nil
 			}
+			s.applyLastWrite(&tag)
 			return tag, nil
 		}
 	}
@@ -596,7 +949,7 @@ func (s *PLCService) GetTagByID(id int) (domain.PLCTag, error) {
 	}
 
 	// Armazenar no Redis para futuras consultas se o cache estiver ativado
-	if s.config.CacheEnabled {
+	if s.cfg().CacheEnabled {
 		_, err = s.redisTagRepo.Create(tag)
 		if err != nil {
 			log.Printf("Aviso: erro ao armazenar tag %d no Redis: %v", id, err)
@@ -606,14 +959,32 @@ func (s *PLCService) GetTagByID(id int) (domain.PLCTag, error) {
 	// Carregar valor atual
 	tagValue, err := s.cache.GetTagValue(tag.PLCID, tag.ID)
 	if err == nil && tagValue != nil {
-		tag.CurrentValue = tagValue.Value
+		tag.CurrentValue = currentReading(tag, tagValue)
 	} else {
 		tag.CurrentValue = nil
 	}
+	s.applyLastWrite(&tag)
 
 	return tag, nil
 }
 
+// applyLastWrite usa o registro de última escrita do Redis, que é gravado
+// antes da atualização assíncrona do banco
+func (s *PLCService) applyLastWrite(tag *domain.PLCTag) {
+	lastWrite, err := s.cache.GetTagLastWrite(tag.ID)
+	if err != nil || lastWrite == nil {
+		return
+	}
+
+	at := lastWrite.At
+	tag.LastWrittenAt = &at
+	tag.LastWrittenBy = nil
+	if lastWrite.UserID > 0 {
+		userID := lastWrite.UserID
+		tag.LastWrittenBy = &userID
+	}
+}
+
 // GetTagByName busca tags pelo nome
 func (s *PLCService) GetTagByName(name string) ([]domain.PLCTag, error) {
 	if name == "" {
@@ -624,14 +995,14 @@ func (s *PLCService) GetTagByName(name string) ([]domain.PLCTag, error) {
 	var tags []domain.PLCTag
 	var err error
 
-	if s.config.CacheEnabled {
+	if s.cfg().CacheEnabled {
 		tags, err = s.redisTagRepo.GetByName(name)
 		if err == nil && len(tags) > 0 {
 			// Carregar valores atuais
 			for i := range tags {
 				tagValue, err := s.cache.GetTagValue(tags[i].PLCID, tags[i].ID)
 				if err == nil && tagValue != nil {
-					tags[i].CurrentValue = tagValue.Value
+					tags[i].CurrentValue = currentReading(tags[i], tagValue)
 				} else {
 					tags[i].CurrentValue = nil
 				}
@@ -651,7 +1022,7 @@ func (s *PLCService) GetTagByName(name string) ([]domain.PLCTag, error) {
 	}
 
 	// Armazenar no Redis para futuras consultas se o cache estiver ativado
-	if s.config.CacheEnabled {
+	if s.cfg().CacheEnabled {
 		for _, tag := range tags {
 			_, err := s.redisTagRepo.Create(tag)
 			if err != nil {
@@ -664,7 +1035,7 @@ func (s *PLCService) GetTagByName(name string) ([]domain.PLCTag, error) {
 	for i := range tags {
 		tagValue, err := s.cache.GetTagValue(tags[i].PLCID, tags[i].ID)
 		if err == nil && tagValue != nil {
-			tags[i].CurrentValue = tagValue.Value
+			tags[i].CurrentValue = currentReading(tags[i], tagValue)
 		} else {
 			tags[i].CurrentValue = nil
 		}
@@ -673,6 +1044,57 @@ func (s *PLCService) GetTagByName(name string) ([]domain.PLCTag, error) {
 	return tags, nil
 }
 
+// SearchTags busca tags por texto e filtros, preenchendo os valores atuais do cache
+func (s *PLCService) SearchTags(ctx context.Context, filter domain.TagSearchFilter) ([]domain.PLCTag, int, error) {
+	filter.Query = strings.TrimSpace(filter.Query)
+	if len([]rune(filter.Query)) > maxTagSearchQueryLength {
+		return nil, 0, ErrSearchQueryTooLong
+	}
+
+	filter.DataType = strings.ToLower(strings.TrimSpace(filter.DataType))
+
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.PageSize < 1 {
+		filter.PageSize = DefaultTagSearchPageSize
+	} else if filter.PageSize > MaxTagSearchPageSize {
+		filter.PageSize = MaxTagSearchPageSize
+	}
+
+	tags, total, err := s.pgTagRepo.Search(ctx, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("erro ao buscar tags: %w", err)
+	}
+
+	if len(tags) == 0 {
+		return tags, total, nil
+	}
+
+	// Carregar valores atuais em batch (tags podem ser de PLCs diferentes)
+	queries := make([]struct{ PLCID, TagID int }, len(tags))
+	for i, tag := range tags {
+		queries[i] = struct{ PLCID, TagID int }{PLCID: tag.PLCID, TagID: tag.ID}
+	}
+
+	values, err := s.cache.GetMultipleTagValues(queries)
+	if err != nil {
+		log.Printf("Aviso: erro ao carregar valores das tags: %v", err)
+		return tags, total, nil
+	}
+
+	valueMap := make(map[int]*domain.TagValue, len(values))
+	for i := range values {
+		valueMap[values[i].TagID] = &values[i]
+	}
+
+	for i := range tags {
+		tags[i].CurrentValue = currentReading(tags[i], valueMap[tags[i].ID])
+	}
+
+	return tags, total, nil
+}
+
 // isValidDataType verifica se um tipo de dados é válido
 func (s *PLCService) isValidDataType(dataType string) bool {
 	validTypes := map[string]bool{
@@ -692,82 +1114,49 @@ func (s *PLCService) isValidDataType(dataType string) bool {
 		"byte":   true,
 		"int8":   true,
 		"uint8":  true,
-	}
-
-	return validTypes[strings.ToLower(strings.TrimSpace(dataType))]
-}
-
-// CreateTag cria uma nova tag
-func (s *PLCService) CreateTag(tag domain.PLCTag) (int, error) {
-	// Validações
-	if tag.Name == "" {
-		return 0, ErrInvalidTagName
-	}
+		"char":   true,
 
-	if tag.DataType == "" {
-		return 0, ErrInvalidDataType
-	}
+		"date":          true,
+		"time_of_day":   true,
+		"date_and_time": true,
 
-	// Normalizar o tipo de dados para evitar problemas de case-sensitivity
-	tag.DataType = strings.ToLower(strings.TrimSpace(tag.DataType))
-
-	// Validar tipo de dados
-	if !s.isValidDataType(tag.DataType) {
-		return 0, fmt.Errorf("%w: '%s' não é suportado", ErrInvalidDataType, tag.DataType)
+		"timer":   true,
+		"counter": true,
 	}
 
-	// Validar bit offset para tipo bool
-	if tag.DataType == "bool" {
-		if tag.BitOffset < 0 || tag.BitOffset > 7 {
-			return 0, ErrInvalidBitOffset
-		}
-	} else {
-		// Para outros tipos de dados, o bit offset deve ser 0
-		tag.BitOffset = 0
-	}
+	return validTypes[strings.ToLower(strings.TrimSpace(dataType))]
+}
 
+// CreateTag cria uma nova tag. Com force, a tag é criada mesmo que seu
+// endereço sobreponha o de outra tag do PLC.
+func (s *PLCService) CreateTag(tag domain.PLCTag, force bool) (int, error) {
 	// Verificar se o PLC existe
 	plc, err := s.GetByID(tag.PLCID)
 	if err != nil {
 		return 0, fmt.Errorf("PLC não encontrado: %w", err)
 	}
 
-	// Verificar se o mapeamento de endereços conhecidos tem esta tag
-	dbName := fmt.Sprintf("DB%d", tag.DBNumber)
-	if dbMap, exists := s.addressMap[dbName]; exists {
-		if tagMapping, exists := dbMap[tag.Name]; exists {
-			// Corrigir automaticamente os endereços
-			if tag.DBNumber != tagMapping.DBNumber ||
-				tag.ByteOffset != tagMapping.ByteOffset ||
-				tag.BitOffset != tagMapping.BitOffset ||
-				tag.DataType != tagMapping.DataType {
-
-				log.Printf("Corrigindo automaticamente endereços da tag '%s' para corresponder ao mapeamento conhecido", tag.Name)
-				tag.DBNumber = tagMapping.DBNumber
-				tag.ByteOffset = tagMapping.ByteOffset
-				tag.BitOffset = tagMapping.BitOffset
-				tag.DataType = tagMapping.DataType
-			}
-		}
-	}
-
-	// Definir valores padrão
-	tag.CreatedAt = time.Now()
-	if tag.ScanRate <= 0 {
-		tag.ScanRate = s.config.DefaultTagScanRate
+	if err := s.prepareNewTag(&tag, force); err != nil {
+		return 0, err
 	}
 
-	// Criar no banco de dados principal
-	id, err := s.pgTagRepo.Create(tag)
+	// Criar no banco de dados principal respeitando o limite de tags do PLC.
+	// BulkCreate conta e insere com a linha do PLC bloqueada (FOR UPDATE),
+	// então criações simultâneas não ultrapassam juntas o limite.
+	ids, err := s.pgTagRepo.BulkCreate(plc.ID, []domain.PLCTag{tag}, s.tagLimit(plc))
 	if err != nil {
+		if errors.Is(err, ErrTagLimitExceeded) {
+			return 0, err
+		}
 		return 0, fmt.Errorf("erro ao criar tag no banco de dados: %w", err)
 	}
+	id := ids[0]
 
 	// Definir ID
 	tag.ID = id
 
 	// Criar no Redis também se o cache estiver ativado
-	if s.config.CacheEnabled {
+	if s.cfg().CacheEnabled {
 		_, err = s.redisTagRepo.Create(tag)
 		if err != nil {
 			log.Printf("Aviso: erro ao armazenar nova tag no Redis: %v", err)
@@ -787,17 +1176,103 @@ func (s *PLCService) CreateTag(tag domain.PLCTag) (int, error) {
 	return id, nil
 }
 
-// UpdateTag atualiza uma tag
-func (s *PLCService) UpdateTag(tag domain.PLCTag) error {
+// tagLimit retorna o limite de tags do PLC: o valor definido pelo
+// administrador no PLC ou, se ausente, MaxTagsPerPLC (0 = sem limite)
+func (s *PLCService) tagLimit(plc domain.PLC) int {
+	if plc.OverrideTagLimit != nil {
+		return *plc.OverrideTagLimit
+	}
+	return s.cfg().MaxTagsPerPLC
+}
+
+// GetTagLimitStatus retorna quantas tags o PLC possui e o limite aplicado
+func (s *PLCService) GetTagLimitStatus(plc domain.PLC) (int, int, error) {
+	count, err := s.pgTagRepo.CountByPLC(plc.ID)
+	if err != nil {
+		return 0, 0, err
+	}
+	return count, s.tagLimit(plc), nil
+}
+
+// SetPLCTagLimit define (ou remove, com nil) o limite de tags próprio do PLC
+func (s *PLCService) SetPLCTagLimit(plcID int, limit *int) (domain.PLC, error) {
+	if limit != nil && *limit < 0 {
+		return domain.PLC{}, ErrInvalidTagLimit
+	}
+
+	plc, err := s.pgPLCRepo.GetByID(plcID)
+	if err != nil {
+		if errors.Is(err, domain.ErrPLCNotFound) {
+			return domain.PLC{}, fmt.Errorf("PLC com ID %d não encontrado: %w", plcID, domain.ErrPLCNotFound)
+		}
+		return domain.PLC{}, fmt.Errorf("erro ao buscar PLC com ID %d: %w", plcID, err)
+	}
+
+	previous := s.tagLimit(plc)
+	plc.OverrideTagLimit = limit
+	if err := s.Update(plc); err != nil {
+		return domain.PLC{}, err
+	}
+
+	log.Printf("Auditoria: entity_type=plc plc_id=%d field=override_tag_limit old_value=%d new_value=%d",
+		plcID, previous, s.tagLimit(plc))
+
+	return plc, nil
+}
+
+// BulkCreateTags cria várias tags de um PLC de uma só vez. O limite de tags
+// é verificado para o lote inteiro na mesma transação da inserção; se o lote
+// não couber, nenhuma tag é criada.
+func (s *PLCService) BulkCreateTags(plcID int, tags []domain.PLCTag) ([]int, error) {
+	plc, err := s.GetByID(plcID)
+	if err != nil {
+		return nil, fmt.Errorf("PLC não encontrado: %w", err)
+	}
+
+	ids, err := s.pgTagRepo.BulkCreate(plcID, tags, s.tagLimit(plc))
+	if err != nil {
+		if errors.Is(err, domain.ErrTagLimitExceeded) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("erro ao criar tags no banco de dados: %w", err)
+	}
+
+	for i := range tags {
+		tags[i].ID = ids[i]
+		if s.cfg().CacheEnabled {
+			if _, err := s.redisTagRepo.Create(tags[i]); err != nil {
+				log.Printf("Aviso: erro ao armazenar nova tag %d no Redis: %v", ids[i], err)
+			}
+		}
+	}
+
+	if s.syncService != nil && s.syncService.IsRunning() {
+		for _, id := range ids {
+			s.syncService.NotifyTagChange(id)
+		}
+		s.syncService.NotifyPLCChange(plcID)
+	}
+
+	log.Printf("%d tags criadas em lote no PLC %s", len(ids), plc.Name)
+	return ids, nil
+}
+
+// prepareNewTag valida e normaliza uma tag antes da criação e preenche os
+// valores padrão
+func (s *PLCService) prepareNewTag(tag *domain.PLCTag, force bool) error {
 	// Validações
-	if tag.Name == "" {
-		return ErrInvalidTagName
+	if err := s.validateTagName(tag.Name); err != nil {
+		return err
 	}
 
 	if tag.DataType == "" {
 		return ErrInvalidDataType
 	}
 
+	if tag.WriteRateLimitHz < 0 {
+		return ErrInvalidWriteRate
+	}
+
 	// Normalizar o tipo de dados para evitar problemas de case-sensitivity
 	tag.DataType = strings.ToLower(strings.TrimSpace(tag.DataType))
 
@@ -806,13 +1281,61 @@ func (s *PLCService) UpdateTag(tag domain.PLCTag) error {
 		return fmt.Errorf("%w: '%s' não é suportado", ErrInvalidDataType, tag.DataType)
 	}
 
-	// Validar bit offset para tipo bool
-	if tag.DataType == "bool" {
-		if tag.BitOffset < 0 || tag.BitOffset > 7 {
-			return ErrInvalidBitOffset
+	// Para tipos não booleanos o bit offset é sempre 0
+	if tag.DataType != "bool" {
+		tag.BitOffset = 0
+	}
+
+	// Validar endereço após normalização
+	if err := tag.Validate(); err != nil {
+		return err
+	}
+
+	// Recusar endereços sobrepostos a outras tags do PLC, exceto se forçado
+	if !force {
+		if err := s.checkTagConflicts(*tag); err != nil {
+			return err
 		}
-	} else {
-		// Para outros tipos de dados, o bit offset deve ser 0
+	}
+
+	// Tags novas entram sem grupo de varredura
+	tag.ScanGroupID = nil
+
+	// Definir valores padrão
+	tag.CreatedAt = time.Now()
+	if tag.ScanRate <= 0 {
+		tag.ScanRate = s.cfg().DefaultTagScanRate
+	}
+
+	return nil
+}
+
+// UpdateTag atualiza uma tag. Com force, a alteração é aplicada mesmo que o
+// endereço sobreponha o de outra tag do PLC.
+func (s *PLCService) UpdateTag(tag domain.PLCTag, force bool) error {
+	// Validações
+	if err := s.validateTagName(tag.Name); err != nil {
+		return err
+	}
+
+	if tag.DataType == "" {
+		return ErrInvalidDataType
+	}
+
+	if tag.WriteRateLimitHz < 0 {
+		return ErrInvalidWriteRate
+	}
+
+	// Normalizar o tipo de dados para evitar problemas de case-sensitivity
+	tag.DataType = strings.ToLower(strings.TrimSpace(tag.DataType))
+
+	// Validar tipo de dados
+	if !s.isValidDataType(tag.DataType) {
+		return fmt.Errorf("%w: '%s' não é suportado", ErrInvalidDataType, tag.DataType)
+	}
+
+	// Para tipos não booleanos o bit offset é sempre 0
+	if tag.DataType != "bool" {
 		tag.BitOffset = 0
 	}
 
@@ -828,22 +1351,22 @@ func (s *PLCService) UpdateTag(tag domain.PLCTag) error {
 		return fmt.Errorf("tag não encontrada: %w", err)
 	}
 
-	// Verificar se o mapeamento de endereços conhecidos tem esta tag
-	dbName := fmt.Sprintf("DB%d", tag.DBNumber)
-	if dbMap, exists := s.addressMap[dbName]; exists {
-		if tagMapping, exists := dbMap[tag.Name]; exists {
-			// Corrigir automaticamente os endereços
-			if tag.DBNumber != tagMapping.DBNumber ||
-				tag.ByteOffset != tagMapping.ByteOffset ||
-				tag.BitOffset != tagMapping.BitOffset ||
-				tag.DataType != tagMapping.DataType {
-
-				log.Printf("Corrigindo automaticamente endereços da tag '%s' para corresponder ao mapeamento conhecido", tag.Name)
-				tag.DBNumber = tagMapping.DBNumber
-				tag.ByteOffset = tagMapping.ByteOffset
-				tag.BitOffset = tagMapping.BitOffset
-				tag.DataType = tagMapping.DataType
-			}
+	// O grupo de varredura é alterado apenas pelas rotas de grupos; a tag sai
+	// do grupo se mudar de PLC
+	tag.ScanGroupID = oldTag.ScanGroupID
+	if tag.PLCID != oldTag.PLCID {
+		tag.ScanGroupID = nil
+	}
+
+	// Validar endereço após normalização
+	if err := tag.Validate(); err != nil {
+		return err
+	}
+
+	// Recusar endereços sobrepostos a outras tags do PLC, exceto se forçado
+	if !force {
+		if err := s.checkTagConflicts(tag); err != nil {
+			return err
 		}
 	}
 
@@ -852,7 +1375,7 @@ func (s *PLCService) UpdateTag(tag domain.PLCTag) error {
 
 	// Definir valores padrão
 	if tag.ScanRate <= 0 {
-		tag.ScanRate = s.config.DefaultTagScanRate
+		tag.ScanRate = s.cfg().DefaultTagScanRate
 	}
 
 	// Atualizar no banco de dados principal
@@ -865,7 +1388,7 @@ func (s *PLCService) UpdateTag(tag domain.PLCTag) error {
 	}
 
 	// Atualizar no Redis também se o cache estiver ativado
-	if s.config.CacheEnabled {
+	if s.cfg().CacheEnabled {
 		err = s.redisTagRepo.Update(tag)
 		if err != nil {
 			// Tentar criar caso não exista
@@ -929,7 +1452,7 @@ func (s *PLCService) DeleteTag(id int) error {
 	}
 
 	// Excluir do Redis também se o cache estiver ativado
-	if s.config.CacheEnabled {
+	if s.cfg().CacheEnabled {
 		err = s.redisTagRepo.Delete(id)
 		if err != nil && !errors.Is(err, domain.ErrPLCTagNotFound) {
 			log.Printf("Aviso: erro ao excluir tag do Redis: %v", err)
@@ -955,7 +1478,9 @@ func (s *PLCService) StartMonitoring() error {
 	}
 
 	// Verificar se o monitoramento está habilitado na configuração
-	if !s.config.MonitoringEnabled {
+	if !s.cfg().MonitoringEnabled {
+		// Sem monitoramento não há sincronização inicial a aguardar
+		s.syncComplete.Store(true)
 		return fmt.Errorf("monitoramento está desabilitado na configuração")
 	}
 
@@ -967,10 +1492,15 @@ func (s *PLCService) StartMonitoring() error {
 		}
 	}
 
+	// Montar o mapa de endereços a partir das tags do banco
+	if err := s.RefreshAddressMap(); err != nil {
+		log.Printf("Aviso: erro ao montar mapa de endereços: %v", err)
+	}
+
 	// Iniciar gerenciador de PLCs
 	if s.manager != nil {
 		// Configurar logging detalhado
-		s.manager.SetDetailedLogging(s.config.DetailedLoggingEnabled)
+		s.manager.SetDetailedLogging(s.cfg().DetailedLoggingEnabled)
 
 		err := s.manager.Start()
 		if err != nil {
@@ -988,11 +1518,24 @@ func (s *PLCService) StartMonitoring() error {
 		return fmt.Errorf("gerenciador de PLCs não inicializado")
 	}
 
+	if s.cfg().AutoAdaptScanRates {
+		s.startScanRateAdaptation()
+	}
+
+	s.startHistoryPruning()
+
 	s.isRunning = true
+	s.syncComplete.Store(true)
 	log.Println("Serviço de monitoramento de PLCs iniciado")
 	return nil
 }
 
+// StartupComplete indica se a primeira inicialização do monitoramento,
+// incluindo a sincronização completa inicial, já terminou
+func (s *PLCService) StartupComplete() bool {
+	return s.syncComplete.Load()
+}
+
 // StopMonitoring para o monitoramento de PLCs
 func (s *PLCService) StopMonitoring() error {
 	s.mu.Lock()
@@ -1004,6 +1547,13 @@ func (s *PLCService) StopMonitoring() error {
 
 	var errs []error
 
+	// Parar monitor de depuração
+	s.StopDebugMonitor()
+
+	// Parar adaptação automática de scan rate e limpeza do histórico
+	s.stopScanRateAdaptation()
+	s.stopHistoryPruning()
+
 	// Parar gerenciador
 	if s.manager != nil {
 		s.manager.Stop()
@@ -1028,8 +1578,8 @@ func (s *PLCService) StopMonitoring() error {
 	return nil
 }
 
-// WriteTagValue escreve um valor em uma tag pelo nome
-func (s *PLCService) WriteTagValue(tagName string, value interface{}) error {
+// WriteTagValue escreve um valor em uma tag pelo nome em nome do usuário informado
+func (s *PLCService) WriteTagValue(tagName string, value interface{}, userID int) error {
 	s.mu.RLock()
 	isRunning := s.isRunning
 	s.mu.RUnlock()
@@ -1043,8 +1593,43 @@ func (s *PLCService) WriteTagValue(tagName string, value interface{}) error {
 		return fmt.Errorf("valor não pode ser nulo")
 	}
 
+	if err := s.validateWrite(tagName, value, userID); err != nil {
+		return err
+	}
+
 	// Usar o manager para escrever o valor
-	return s.manager.WriteTagByName(tagName, value)
+	return s.manager.WriteTagByName(tagName, value, userID)
+}
+
+// QueueTagWrite enfileira uma escrita para execução quando o PLC estiver online
+func (s *PLCService) QueueTagWrite(tagName string, value interface{}, userID int) (domain.QueuedWrite, error) {
+	if tagName == "" {
+		return domain.QueuedWrite{}, ErrInvalidTagName
+	}
+
+	if value == nil {
+		return domain.QueuedWrite{}, fmt.Errorf("valor não pode ser nulo")
+	}
+
+	// Intertravamentos são verificados no momento do enfileiramento
+	if err := s.validateWrite(tagName, value, userID); err != nil {
+		return domain.QueuedWrite{}, err
+	}
+
+	return s.manager.EnqueueWrite(tagName, value, userID)
+}
+
+// GetWriteQueue lista as escritas pendentes de um PLC
+func (s *PLCService) GetWriteQueue(plcID int) ([]domain.QueuedWrite, error) {
+	if _, err := s.GetByID(plcID); err != nil {
+		return nil, err
+	}
+	return s.manager.GetWriteQueue(plcID)
+}
+
+// CancelQueuedWrite cancela uma escrita pendente
+func (s *PLCService) CancelQueuedWrite(plcID int, requestID string) error {
+	return s.manager.CancelQueuedWrite(plcID, requestID)
 }
 
 // GetTagValue busca o valor atual de uma tag
@@ -1095,143 +1680,46 @@ func (s *PLCService) GetPLCStats() domain.PLCManagerStats {
 
 	// Converter cada ConnectionStat para domain.PLCConnectionStats
 	for id, connStat := range stats.ConnectionStats {
-		domainStats.ConnectionStats[id] = domain.PLCConnectionStats{
-			PLCID:         connStat.PLCID,
-			Name:          connStat.Name,
-			Status:        connStat.Status,
-			TagCount:      connStat.TagCount,
-			LastConnected: connStat.LastConnected,
-			ReadErrors:    connStat.ReadErrors,
-			WriteErrors:   connStat.WriteErrors,
-		}
+		domainStats.ConnectionStats[id] = toDomainConnectionStats(connStat)
 	}
 
+	domainStats.Sites = s.siteStats(domainStats.ConnectionStats)
+
 	return domainStats
 }
 
-// StartDebugMonitor inicia uma rotina que imprime periodicamente os valores de todas as tags
-func (s *PLCService) StartDebugMonitor() {
-	s.mu.RLock()
-	isRunning := s.isRunning
-	s.mu.RUnlock()
-
-	// Verificar se o monitoramento está rodando
-	if !isRunning {
-		log.Println("DEPURAÇÃO: Não é possível iniciar o monitor de depuração porque o serviço PLC não está em execução")
-		return
+// toDomainConnectionStats converte as estatísticas de conexão do gerenciador
+func toDomainConnectionStats(connStat PLCConnectionStats) domain.PLCConnectionStats {
+	return domain.PLCConnectionStats{
+		PLCID:            connStat.PLCID,
+		SiteID:           connStat.SiteID,
+		Name:             connStat.Name,
+		Status:           connStat.Status,
+		TagCount:         connStat.TagCount,
+		LastConnected:    connStat.LastConnected,
+		ReadErrors:       connStat.ReadErrors,
+		WriteErrors:      connStat.WriteErrors,
+		RetryCount:       connStat.RetryCount,
+		NextRetryAt:      connStat.NextRetryAt,
+		ReadLatencyP50Ms: connStat.ReadLatencyP50Ms,
+		ReadLatencyP95Ms: connStat.ReadLatencyP95Ms,
+		ReadLatencyP99Ms: connStat.ReadLatencyP99Ms,
+		ReadsPerSecond:   connStat.ReadsPerSecond,
+		BytesReadTotal:   connStat.BytesReadTotal,
+
+		ReconnectAttempts: connStat.ReconnectAttempts,
+		LastReconnectAt:   connStat.LastReconnectAt,
+		OverrunCount:      connStat.OverrunCount,
+		LastOverrunAt:     connStat.LastOverrunAt,
+
+		LastPingMs: float64(connStat.Timeout) / float64(time.Millisecond),
 	}
-
-	log.Println("DEPURAÇÃO: Iniciando monitor de depuração para valores de tags")
-
-	// Iniciar uma goroutine para imprimir valores periodicamente
-	go func() {
-		ticker := time.NewTicker(5 * time.Second) // Ajuste o intervalo conforme necessário
-		defer ticker.Stop()
-
-		for {
-			select {
-			case <-ticker.C:
-				// Verificar se o serviço ainda está em execução
-				s.mu.RLock()
-				stillRunning := s.isRunning
-				s.mu.RUnlock()
-
-				if !stillRunning {
-					log.Println("DEPURAÇÃO: Monitor de depuração interrompido devido à parada do serviço")
-					return
-				}
-
-				// Obter todos os PLCs ativos
-				plcs, err := s.GetActivePLCs()
-				if err != nil {
-					log.Printf("DEPURAÇÃO: Erro ao buscar PLCs ativos: %v", err)
-					continue
-				}
-
-				if len(plcs) == 0 {
-					log.Println("DEPURAÇÃO: Nenhum PLC ativo encontrado")
-					continue
-				}
-
-				// Para cada PLC, buscar suas tags
-				for _, plc := range plcs {
-					tags, err := s.GetPLCTags(plc.ID)
-					if err != nil {
-						log.Printf("DEPURAÇÃO: Erro ao buscar tags do PLC %s (ID=%d): %v",
-							plc.Name, plc.ID, err)
-						continue
-					}
-
-					if len(tags) == 0 {
-						log.Printf("DEPURAÇÃO: PLC %s (ID=%d) não tem tags", plc.Name, plc.ID)
-						continue
-					}
-
-					// Filtrar apenas tags ativas
-					activeTags := make([]domain.PLCTag, 0)
-					for _, tag := range tags {
-						if tag.Active {
-							activeTags = append(activeTags, tag)
-						}
-					}
-
-					if len(activeTags) == 0 {
-						log.Printf("DEPURAÇÃO: PLC %s (ID=%d) não tem tags ativas", plc.Name, plc.ID)
-						continue
-					}
-
-					// Imprimir cabeçalho
-					log.Printf("=== VALORES ATUAIS DO PLC %s (STATUS: %s) ===", plc.Name, plc.Status)
-
-					// Imprimir cada tag com seu valor
-					for _, tag := range activeTags {
-						// Buscar o valor mais recente do cache
-						tagValue, err := s.cache.GetTagValue(plc.ID, tag.ID)
-
-						var valorStr string
-						if err != nil || tagValue == nil {
-							valorStr = "<sem valor>"
-						} else {
-							// Formatação mais legível do valor
-							switch tag.DataType {
-							case "real":
-								if v, ok := tagValue.Value.(float32); ok {
-									valorStr = fmt.Sprintf("%.3f", v)
-								} else {
-									valorStr = fmt.Sprintf("%v", tagValue.Value)
-								}
-							case "bool":
-								if v, ok := tagValue.Value.(bool); ok {
-									if v {
-										valorStr = "TRUE"
-									} else {
-										valorStr = "FALSE"
-									}
-								} else {
-									valorStr = fmt.Sprintf("%v", tagValue.Value)
-								}
-							default:
-								valorStr = fmt.Sprintf("%v", tagValue.Value)
-							}
-						}
-
-						log.Printf("  Tag: %-20s | Tipo: %-6s | DB%d.DBX%d.%d | Valor: %s",
-							tag.Name,
-							tag.DataType,
-							tag.DBNumber,
-							int(tag.ByteOffset),
-							tag.BitOffset,
-							valorStr)
-					}
-
-					log.Println("=============================================")
-				}
-			}
-		}
-	}()
 }
 
-// VerifyTagAddresses verifica se os endereços das tags correspondem aos do PLC real
+// VerifyTagAddresses confere se o endereço gravado em cada tag coincide com
+// o mapa de endereços. Como o mapa é montado a partir do mesmo banco, uma
+// divergência indica mapa desatualizado ou tags com o mesmo nome no mesmo DB
+// em PLCs diferentes. Nenhuma tag é alterada; divergências são apenas logadas.
 func (s *PLCService) VerifyTagAddresses() error {
 	log.Println("Verificando endereços das tags...")
 
@@ -1243,7 +1731,7 @@ func (s *PLCService) VerifyTagAddresses() error {
 
 	var wg sync.WaitGroup
 	var mu sync.Mutex
-	totalCorrected := 0
+	totalMismatches := 0
 	errorCount := 0
 
 	for _, plc := range plcs {
@@ -1262,62 +1750,29 @@ func (s *PLCService) VerifyTagAddresses() error {
 				return
 			}
 
-			localCorrected := 0
+			localMismatches := 0
 
-			// Verificar cada tag
 			for _, tag := range tags {
-				// Verificar endereços conforme mapeamento conhecido
-				dbName := fmt.Sprintf("DB%d", tag.DBNumber)
-				if dbMap, exists := s.addressMap[dbName]; exists {
-					if tagMapping, exists := dbMap[tag.Name]; exists {
-						needsUpdate := false
-
-						if tag.DBNumber != tagMapping.DBNumber ||
-							tag.ByteOffset != tagMapping.ByteOffset ||
-							tag.BitOffset != tagMapping.BitOffset ||
-							tag.DataType != tagMapping.DataType {
-
-							// Backup dos valores originais para log
-							oldDB := tag.DBNumber
-							oldByte := tag.ByteOffset
-							oldBit := tag.BitOffset
-							oldType := tag.DataType
-
-							// Atualizar com valores corretos
-							tag.DBNumber = tagMapping.DBNumber
-							tag.ByteOffset = tagMapping.ByteOffset
-							tag.BitOffset = tagMapping.BitOffset
-							tag.DataType = tagMapping.DataType
-							needsUpdate = true
-
-							log.Printf("Corrigindo endereço da tag '%s': DB%d.DBX%d.%d (%s) -> DB%d.DBX%d.%d (%s)",
-								tag.Name,
-								oldDB, oldByte, oldBit, oldType,
-								tag.DBNumber, tag.ByteOffset, tag.BitOffset, tag.DataType)
-						}
-
-						// Se precisar atualizar, chama o método UpdateTag
-						if needsUpdate {
-							if err := s.UpdateTag(tag); err != nil {
-								mu.Lock()
-								errorCount++
-								mu.Unlock()
-								log.Printf("Erro ao atualizar tag %s (ID=%d): %v", tag.Name, tag.ID, err)
-							} else {
-								mu.Lock()
-								totalCorrected++
-								localCorrected++
-								mu.Unlock()
-							}
-						}
-					}
+				mapping, exists := s.lookupAddress(tag.DBNumber, tag.Name)
+				if !exists || tagAddressMatches(tag, mapping) {
+					continue
 				}
+
+				localMismatches++
+				log.Printf("Endereço da tag '%s' (ID=%d) diverge do mapa: DB%d.DBX%d.%d (%s) x DB%d.DBX%d.%d (%s)",
+					tag.Name, tag.ID,
+					tag.DBNumber, tag.ByteOffset, tag.BitOffset, tag.DataType,
+					mapping.DBNumber, mapping.ByteOffset, mapping.BitOffset, mapping.DataType)
 			}
 
-			if localCorrected > 0 {
-				log.Printf("PLC %s: %d tags corrigidas com sucesso", plc.Name, localCorrected)
+			mu.Lock()
+			totalMismatches += localMismatches
+			mu.Unlock()
+
+			if localMismatches > 0 {
+				log.Printf("PLC %s: %d tags com endereço divergente", plc.Name, localMismatches)
 			} else {
-				log.Printf("PLC %s: nenhuma correção de tag necessária", plc.Name)
+				log.Printf("PLC %s: endereços consistentes", plc.Name)
 			}
 		}(plc)
 	}
@@ -1325,8 +1780,8 @@ func (s *PLCService) VerifyTagAddresses() error {
 	// Aguardar todas as goroutines
 	wg.Wait()
 
-	log.Printf("Verificação e correção de endereços concluída: %d tags corrigidas, %d erros",
-		totalCorrected, errorCount)
+	log.Printf("Verificação de endereços concluída: %d divergências, %d erros",
+		totalMismatches, errorCount)
 
 	if errorCount > 0 {
 		return fmt.Errorf("verificação de endereços concluída com %d erros", errorCount)
@@ -1335,6 +1790,14 @@ func (s *PLCService) VerifyTagAddresses() error {
 	return nil
 }
 
+// tagAddressMatches indica se a tag está no endereço informado
+func tagAddressMatches(tag domain.PLCTag, addr TagAddress) bool {
+	return tag.DBNumber == addr.DBNumber &&
+		tag.ByteOffset == addr.ByteOffset &&
+		tag.BitOffset == addr.BitOffset &&
+		tag.DataType == addr.DataType
+}
+
 // CheckPLCHealth verifica a saúde das conexões com PLCs
 func (s *PLCService) CheckPLCHealth() (map[int]string, error) {
 	s.mu.RLock()
@@ -1353,6 +1816,10 @@ func (s *PLCService) CheckPLCHealth() (map[int]string, error) {
 		return nil, fmt.Errorf("erro ao buscar PLCs ativos: %w", err)
 	}
 
+	// Um PLC que não responde não pode segurar a verificação inteira
+	ctx, cancel := context.WithTimeout(context.Background(), healthCheckTimeout)
+	defer cancel()
+
 	var wg sync.WaitGroup
 	var mu sync.Mutex
 
@@ -1371,15 +1838,10 @@ func (s *PLCService) CheckPLCHealth() (map[int]string, error) {
 			}
 
 			// Verificar a conexão com ping
-			if err := conn.Ping(); err != nil {
-				mu.Lock()
-				health[plc.ID] = fmt.Sprintf("falha: %v", err)
-				mu.Unlock()
-			} else {
-				mu.Lock()
-				health[plc.ID] = "online"
-				mu.Unlock()
-			}
+			status := s.manager.pingWithTimeout(ctx, plc.ID, conn)
+			mu.Lock()
+			health[plc.ID] = status
+			mu.Unlock()
 		}(plc)
 	}
 
@@ -1439,6 +1901,63 @@ func (s *PLCService) ResetPLCConnection(plcID int) error {
 	return nil
 }
 
+// SyncNow solicita uma sincronização incremental imediata PostgreSQL -> Redis
+func (s *PLCService) SyncNow() error {
+	if s.syncService == nil {
+		return ErrSyncNotRunning
+	}
+	return s.syncService.SyncNow()
+}
+
+// SetSyncInterval altera o intervalo da sincronização periódica
+func (s *PLCService) SetSyncInterval(interval time.Duration) error {
+	if s.syncService == nil {
+		return ErrSyncNotRunning
+	}
+	return s.syncService.SetSyncInterval(interval)
+}
+
+// GetTrackedGoroutines retorna as goroutines em execução no gerenciador de PLCs
+func (s *PLCService) GetTrackedGoroutines() int64 {
+	if s.manager == nil {
+		return 0
+	}
+	return s.manager.TrackedGoroutines()
+}
+
+// GetSyncChangeSummary retorna as mudanças pendentes de sincronização
+func (s *PLCService) GetSyncChangeSummary() (domain.SyncChangeSummary, error) {
+	if s.syncService == nil {
+		return domain.SyncChangeSummary{}, ErrSyncNotRunning
+	}
+	return s.syncService.GetChangeSummary(), nil
+}
+
+// ClearSyncChangeTracker descarta as mudanças pendentes de sincronização
+func (s *PLCService) ClearSyncChangeTracker() error {
+	if s.syncService == nil {
+		return ErrSyncNotRunning
+	}
+	s.syncService.ClearChangeTracker()
+	return nil
+}
+
+// GetSyncErrorLog retorna os últimos erros de sincronização
+func (s *PLCService) GetSyncErrorLog() ([]domain.SyncError, error) {
+	if s.syncService == nil {
+		return nil, ErrSyncNotRunning
+	}
+	return s.syncService.GetSyncErrorLog(), nil
+}
+
+// GetSyncLockStatus retorna o estado dos locks distribuídos de sincronização
+func (s *PLCService) GetSyncLockStatus() ([]domain.LockStatus, error) {
+	if s.syncService == nil {
+		return nil, ErrSyncNotRunning
+	}
+	return s.syncService.GetLockStatus()
+}
+
 // GetStatistics retorna estatísticas mais detalhadas do sistema
 func (s *PLCService) GetStatistics() map[string]interface{} {
 	stats := make(map[string]interface{})
@@ -1458,7 +1977,8 @@ func (s *PLCService) GetStatistics() map[string]interface{} {
 	// Obter serviço de sincronização
 	if s.syncService != nil {
 		stats["sync_service"] = map[string]interface{}{
-			"running": s.syncService.IsRunning(),
+			"running":          s.syncService.IsRunning(),
+			"interval_seconds": s.syncService.GetSyncInterval().Seconds(),
 		}
 	}
 
@@ -1518,7 +2038,13 @@ func (s *PLCService) GetStatistics() map[string]interface{} {
 	return stats
 }
 
-// DiagnosticTags verifica a configuração de todas as tags e tenta corrigir inconsistências
+// staleWriteThreshold é o tempo sem escritas após o qual uma tag gravável é
+// apontada no diagnóstico como possível configuração desatualizada
+const staleWriteThreshold = 30 * 24 * time.Hour
+
+// DiagnosticTags verifica a configuração de todas as tags e corrige tipo e
+// offsets inválidos. Divergências em relação ao mapa de endereços, que é
+// montado a partir do mesmo banco, são apenas reportadas.
 func (s *PLCService) DiagnosticTags() (map[string]interface{}, error) {
 	results := make(map[string]interface{})
 	var fixedTags, errorTags int
@@ -1582,58 +2108,67 @@ func (s *PLCService) DiagnosticTags() (map[string]interface{}, error) {
 					needsFix = true
 				}
 
-				// Problema 2: Bit offset inválido para tipo bool
-				if tagCopy.DataType == "bool" && (tag.BitOffset < 0 || tag.BitOffset > 7) {
-					issue["issue"] = fmt.Sprintf("Bit offset inválido (%d) para tipo bool", tag.BitOffset)
-					issue["action"] = "Corrigido para valor entre 0 e 7"
+				// Problema 2: Endereço inconsistente (byte/bit offset)
+				if err := tagCopy.Validate(); err != nil {
+					issue["issue"] = fmt.Sprintf("Endereço inválido: %v", err)
 
-					// Corrigir bit offset
-					if tag.BitOffset < 0 {
+					switch {
+					case errors.Is(err, domain.ErrInvalidBitOffset):
+						issue["action"] = "Corrigido para valor entre 0 e 7"
+						if tagCopy.BitOffset < 0 {
+							tagCopy.BitOffset = 0
+						} else {
+							tagCopy.BitOffset = tagCopy.BitOffset % 8
+						}
+						needsFix = true
+					case errors.Is(err, domain.ErrBitOffsetNotAllowed):
+						issue["action"] = "Bit offset definido como 0"
 						tagCopy.BitOffset = 0
-					} else {
-						tagCopy.BitOffset = tag.BitOffset % 8
+						needsFix = true
+					default:
+						// Byte offset negativo não tem correção automática segura
+						issue["action"] = "Correção manual necessária"
+						tagIssues = append(tagIssues, issue)
+						continue
 					}
-
-					needsFix = true
 				}
 
-				// Problema 3: Bit offset não-zero para tipo não-booleano
-				if tagCopy.DataType != "bool" && tag.BitOffset != 0 {
-					issue["issue"] = fmt.Sprintf("Bit offset (%d) definido para tipo não booleano (%s)",
-						tag.BitOffset, tag.DataType)
-					issue["action"] = "Bit offset definido como 0"
-
-					// Corrigir bit offset
-					tagCopy.BitOffset = 0
-					needsFix = true
+				// Problema 3: Endereço divergente do mapa (apenas reportado)
+				if mapping, exists := s.lookupAddress(tag.DBNumber, tag.Name); exists && !tagAddressMatches(tag, mapping) {
+					tagIssues = append(tagIssues, map[string]interface{}{
+						"tag_id":   tag.ID,
+						"tag_name": tag.Name,
+						"issue": fmt.Sprintf("Endereço DB%d.DBX%d.%d (%s) diverge do mapa: DB%d.DBX%d.%d (%s)",
+							tag.DBNumber, tag.ByteOffset, tag.BitOffset, tag.DataType,
+							mapping.DBNumber, mapping.ByteOffset, mapping.BitOffset, mapping.DataType),
+						"action": "Nenhuma; revisar tags com o mesmo nome no mesmo DB",
+					})
 				}
 
-				// Problema 4: Verificar mapeamento conhecido
-				dbName := fmt.Sprintf("DB%d", tag.DBNumber)
-				if dbMap, exists := s.addressMap[dbName]; exists {
-					if tagMapping, exists := dbMap[tag.Name]; exists {
-						if tag.DBNumber != tagMapping.DBNumber ||
-							tag.ByteOffset != tagMapping.ByteOffset ||
-							tag.BitOffset != tagMapping.BitOffset ||
-							tag.DataType != tagMapping.DataType {
-
-							issue["issue"] = fmt.Sprintf("Endereço não corresponde ao mapeamento conhecido: DB%d.DBX%d.%d (%s)",
-								tag.DBNumber, tag.ByteOffset, tag.BitOffset, tag.DataType)
-							issue["action"] = fmt.Sprintf("Corrigido para DB%d.DBX%d.%d (%s)",
-								tagMapping.DBNumber, tagMapping.ByteOffset, tagMapping.BitOffset, tagMapping.DataType)
-
-							tagCopy.DBNumber = tagMapping.DBNumber
-							tagCopy.ByteOffset = tagMapping.ByteOffset
-							tagCopy.BitOffset = tagMapping.BitOffset
-							tagCopy.DataType = tagMapping.DataType
-							needsFix = true
-						}
+				// Problema 4: tag gravável sem escritas recentes (apenas reportado)
+				if tag.CanWrite {
+					staleSince := time.Now().Add(-staleWriteThreshold)
+					if tag.LastWrittenAt != nil && tag.LastWrittenAt.Before(staleSince) {
+						tagIssues = append(tagIssues, map[string]interface{}{
+							"tag_id":   tag.ID,
+							"tag_name": tag.Name,
+							"issue": fmt.Sprintf("Tag permite escrita, mas a última escrita foi em %s",
+								tag.LastWrittenAt.Format(time.RFC3339)),
+							"action": "Nenhuma; verificar se can_write ainda é necessário",
+						})
+					} else if tag.LastWrittenAt == nil && !tag.CreatedAt.IsZero() && tag.CreatedAt.Before(staleSince) {
+						tagIssues = append(tagIssues, map[string]interface{}{
+							"tag_id":   tag.ID,
+							"tag_name": tag.Name,
+							"issue":    "Tag permite escrita, mas nunca foi escrita",
+							"action":   "Nenhuma; verificar se can_write ainda é necessário",
+						})
 					}
 				}
 
 				// Se precisa de correção, aplicar
 				if needsFix {
-					if err := s.UpdateTag(tagCopy); err != nil {
+					if err := s.UpdateTag(tagCopy, true); err != nil {
 						issue["result"] = fmt.Sprintf("Erro ao corrigir: %v", err)
 						mu.Lock()
 						errorTags++
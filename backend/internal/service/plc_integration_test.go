@@ -0,0 +1,112 @@
+//go:build integration
+
+package service
+
+import (
+	"app_padrao/internal/domain"
+	"app_padrao/internal/repository"
+	"app_padrao/internal/testutil"
+	"encoding/binary"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestPerformFullSyncCopiesPostgresToRedis(t *testing.T) {
+	db := testutil.NewTestDB(t)
+	redisCache := testutil.NewTestRedis(t)
+
+	pgPLCRepo := repository.NewPLCRepository(db)
+	pgTagRepo := repository.NewPLCTagRepository(db)
+	redisPLCRepo := repository.NewPLCRedisRepositoryWithPrefix(redisCache.GetRedisClient(), "")
+	redisTagRepo := repository.NewPLCTagRedisRepositoryWithPrefix(redisCache.GetRedisClient(), "")
+
+	plcID, err := pgPLCRepo.Create(domain.PLC{Name: "CLP_Sync", IPAddress: "10.0.0.20", Slot: 1, Active: true, MonitoringEnabled: true, CreatedAt: time.Now()})
+	if err != nil {
+		t.Fatalf("erro ao criar PLC: %v", err)
+	}
+	tags := []domain.PLCTag{
+		{Name: "Temperatura", DBNumber: 1, ByteOffset: 0, DataType: "real", ScanRate: 1000, Active: true, ScaleFactor: 1},
+		{Name: "Contador", DBNumber: 1, ByteOffset: 4, DataType: "int", ScanRate: 1000, Active: true, ScaleFactor: 1},
+	}
+	if _, err := pgTagRepo.BulkCreate(plcID, tags, 0); err != nil {
+		t.Fatalf("erro ao criar tags: %v", err)
+	}
+
+	sync := NewPLCSyncService(pgPLCRepo, pgTagRepo, redisPLCRepo, redisTagRepo, false)
+	if err := sync.performFullSync(); err != nil {
+		t.Fatalf("erro na sincronização completa: %v", err)
+	}
+
+	plc, err := redisPLCRepo.GetByID(plcID)
+	if err != nil || plc.Name != "CLP_Sync" {
+		t.Errorf("PLC no Redis = %+v (%v), esperado CLP_Sync", plc, err)
+	}
+
+	cached, err := redisTagRepo.GetPLCTags(plcID)
+	if err != nil {
+		t.Fatalf("erro ao ler tags do Redis: %v", err)
+	}
+	if len(cached) != len(tags) {
+		t.Errorf("tags no Redis = %d, esperado %d", len(cached), len(tags))
+	}
+}
+
+func TestCreateTagMonitorAndWriteWithSimulator(t *testing.T) {
+	db := testutil.NewTestDB(t)
+	redisCache := testutil.NewTestRedis(t)
+	sim := testutil.NewS7Simulator(t)
+
+	pgPLCRepo := repository.NewPLCRepository(db)
+	pgTagRepo := repository.NewPLCTagRepository(db)
+
+	plcID, err := pgPLCRepo.Create(domain.PLC{Name: "CLP_Simulador", IPAddress: sim.Addr(), Slot: 1, Active: true, MonitoringEnabled: true, CreatedAt: time.Now()})
+	if err != nil {
+		t.Fatalf("erro ao criar PLC: %v", err)
+	}
+
+	config := DefaultPLCConfig()
+	config.StartupStaggerMs = 0
+	s := NewPLCServiceWithConfig(pgPLCRepo, pgTagRepo, redisCache, config)
+
+	tagID, err := s.CreateTag(domain.PLCTag{
+		PLCID:       plcID,
+		Name:        "Setpoint",
+		DBNumber:    5,
+		ByteOffset:  8,
+		DataType:    "real",
+		ScanRate:    1000,
+		Active:      true,
+		CanWrite:    true,
+		ScaleFactor: 1,
+	}, false)
+	if err != nil {
+		t.Fatalf("erro ao criar tag: %v", err)
+	}
+	if tagID <= 0 {
+		t.Fatalf("ID da tag = %d, esperado positivo", tagID)
+	}
+
+	if err := s.StartMonitoring(); err != nil {
+		t.Fatalf("erro ao iniciar monitoramento: %v", err)
+	}
+	t.Cleanup(func() { s.StopMonitoring() })
+
+	// O gerenciador conecta ao simulador no primeiro ciclo de runAllPLCs
+	deadline := time.Now().Add(30 * time.Second)
+	for {
+		err = s.WriteTagValue("Setpoint", 42.5, 1)
+		if err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("escrita não concluída a tempo: %v", err)
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+
+	raw := sim.ReadDB(5, 8, 4)
+	if got := math.Float32frombits(binary.BigEndian.Uint32(raw)); got != 42.5 {
+		t.Errorf("valor no simulador = %v, esperado 42.5", got)
+	}
+}
@@ -0,0 +1,209 @@
+// internal/service/plcalarm.go
+package service
+
+import (
+	"app_padrao/internal/domain"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrAlarmsNotConfigured indica que o repositório de alarmes não foi definido
+var ErrAlarmsNotConfigured = errors.New("alarmes não configurados")
+
+// defaultAlarmEscalationInterval é a frequência da verificação de escalonamento
+const defaultAlarmEscalationInterval = time.Minute
+
+// SetAlarmRepository define onde os alarmes e suas ocorrências são persistidos
+func (s *PLCService) SetAlarmRepository(repo domain.TagAlarmRepository) {
+	s.alarmRepo = repo
+}
+
+// GetActiveAlarms retorna as ocorrências ativas com severidade mínima opcional
+func (s *PLCService) GetActiveAlarms(minSeverity string) ([]domain.TagAlarmEvent, error) {
+	if s.alarmRepo == nil {
+		return nil, ErrAlarmsNotConfigured
+	}
+
+	minRank := 0
+	if minSeverity != "" {
+		minRank = domain.AlarmSeverityRank(minSeverity)
+		if minRank == 0 {
+			return nil, fmt.Errorf("%w: %s", domain.ErrInvalidAlarmSeverity, minSeverity)
+		}
+	}
+
+	events, err := s.alarmRepo.GetActiveEvents()
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar alarmes ativos: %w", err)
+	}
+
+	filtered := make([]domain.TagAlarmEvent, 0, len(events))
+	for _, event := range events {
+		if domain.AlarmSeverityRank(event.Severity) >= minRank {
+			filtered = append(filtered, event)
+		}
+	}
+
+	return filtered, nil
+}
+
+// AcknowledgeAlarm registra o reconhecimento de uma ocorrência, interrompendo
+// seu escalonamento
+func (s *PLCService) AcknowledgeAlarm(eventID int64, userID int, comment string) (domain.TagAlarmEvent, error) {
+	if s.alarmRepo == nil {
+		return domain.TagAlarmEvent{}, ErrAlarmsNotConfigured
+	}
+
+	event, err := s.alarmRepo.Acknowledge(eventID, userID, strings.TrimSpace(comment))
+	if err != nil {
+		return event, err
+	}
+
+	log.Printf("Auditoria: entity_type=tag_alarm_event id=%d acknowledged_by=%d", eventID, userID)
+	return event, nil
+}
+
+// AlarmEscalationWorker eleva a severidade e notifica outros canais quando
+// uma ocorrência de alarme fica sem reconhecimento além do limite das regras
+type AlarmEscalationWorker struct {
+	repo      domain.TagAlarmRepository
+	mailer    domain.Mailer
+	recipient string // Destinatário das notificações por email
+	interval  time.Duration
+
+	cancel context.CancelFunc
+	mu     sync.Mutex
+}
+
+// NewAlarmEscalationWorker cria o worker; sem mailer ou destinatário as
+// notificações por email apenas são registradas no log
+func NewAlarmEscalationWorker(repo domain.TagAlarmRepository, mailer domain.Mailer, recipient string) *AlarmEscalationWorker {
+	return &AlarmEscalationWorker{
+		repo:      repo,
+		mailer:    mailer,
+		recipient: recipient,
+		interval:  defaultAlarmEscalationInterval,
+	}
+}
+
+// Start inicia a verificação periódica. Um worker já em execução é substituído.
+func (w *AlarmEscalationWorker) Start() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.cancel != nil {
+		w.cancel()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	w.cancel = cancel
+
+	go func() {
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := w.RunOnce(time.Now()); err != nil {
+					log.Printf("Erro no escalonamento de alarmes: %v", err)
+				}
+			}
+		}
+	}()
+
+	log.Printf("Worker de escalonamento de alarmes iniciado (intervalo: %v)", w.interval)
+}
+
+// Stop interrompe a verificação periódica
+func (w *AlarmEscalationWorker) Stop() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.cancel != nil {
+		w.cancel()
+		w.cancel = nil
+	}
+}
+
+// RunOnce aplica as regras vencidas de cada ocorrência pendente
+func (w *AlarmEscalationWorker) RunOnce(now time.Time) error {
+	candidates, err := w.repo.GetEscalationCandidates()
+	if err != nil {
+		return err
+	}
+
+	for _, candidate := range candidates {
+		event := candidate.Event
+		rules := append([]domain.EscalationRule(nil), candidate.Rules...)
+		sort.SliceStable(rules, func(i, j int) bool {
+			return rules[i].AfterMinutes < rules[j].AfterMinutes
+		})
+
+		age := now.Sub(event.RaisedAt)
+		level := event.EscalationLevel
+		severity := event.Severity
+		var channels []string
+
+		// Várias regras podem vencer no mesmo ciclo (ex.: worker parado)
+		for level < len(rules) && age >= time.Duration(rules[level].AfterMinutes)*time.Minute {
+			if domain.AlarmSeverityRank(rules[level].NewSeverity) > 0 {
+				severity = strings.ToLower(strings.TrimSpace(rules[level].NewSeverity))
+			}
+			channels = append(channels, rules[level].NotifyChannels...)
+			level++
+		}
+
+		if level == event.EscalationLevel {
+			continue
+		}
+
+		if err := w.repo.Escalate(event.ID, level, severity); err != nil {
+			log.Printf("Erro ao escalonar alarme %d: %v", event.ID, err)
+			continue
+		}
+
+		log.Printf("Alarme %d (%s) escalonado para o nível %d, severidade %s, após %v sem reconhecimento",
+			event.ID, event.AlarmName, level, severity, age.Round(time.Second))
+
+		event.Severity = severity
+		event.EscalationLevel = level
+		w.notify(event, channels)
+	}
+
+	return nil
+}
+
+// notify envia o aviso de escalonamento para cada canal (sem repetir canais)
+func (w *AlarmEscalationWorker) notify(event domain.TagAlarmEvent, channels []string) {
+	subject := fmt.Sprintf("[%s] Alarme não reconhecido: %s", strings.ToUpper(event.Severity), event.AlarmName)
+	body := fmt.Sprintf("O alarme %q (PLC %d, tag %d) está ativo desde %s sem reconhecimento.\nNível de escalonamento: %d\n%s",
+		event.AlarmName, event.PLCID, event.TagID, event.RaisedAt.Format(time.RFC3339), event.EscalationLevel, event.Message)
+
+	seen := make(map[string]bool, len(channels))
+	for _, channel := range channels {
+		channel = strings.ToLower(strings.TrimSpace(channel))
+		if channel == "" || seen[channel] {
+			continue
+		}
+		seen[channel] = true
+
+		switch {
+		case channel == "email" && w.mailer != nil && w.recipient != "":
+			if err := w.mailer.Send(w.recipient, subject, body); err != nil {
+				log.Printf("Erro ao enviar escalonamento do alarme %d por email: %v", event.ID, err)
+			}
+		default:
+			// Canais sem integração de envio ficam apenas registrados
+			log.Printf("Escalonamento do alarme %d para o canal %s: %s", event.ID, channel, subject)
+		}
+	}
+}
@@ -0,0 +1,221 @@
+// internal/service/plcconfigreload.go
+package service
+
+import (
+	"app_padrao/internal/domain"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// ErrInvalidPLCConfig indica um valor fora do permitido na configuração
+var ErrInvalidPLCConfig = errors.New("configuração do PLC inválida")
+
+const (
+	// Chave com a última configuração aplicada e canal que avisa as réplicas
+	plcConfigKey     = "plc:config"
+	plcConfigChannel = "plc:config:changed"
+
+	minRetryInterval      = time.Second
+	minDefaultTagScanRate = 100
+)
+
+// validatePLCConfig verifica os campos alteráveis em execução
+func validatePLCConfig(config PLCConfig) error {
+	switch {
+	case config.RetryInterval < minRetryInterval:
+		return fmt.Errorf("%w: intervalo entre tentativas deve ser de no mínimo %v", ErrInvalidPLCConfig, minRetryInterval)
+	case config.DefaultTagScanRate < minDefaultTagScanRate:
+		return fmt.Errorf("%w: scan rate padrão deve ser de no mínimo %d ms", ErrInvalidPLCConfig, minDefaultTagScanRate)
+	case config.MaxRetryAttempts < 0:
+		return fmt.Errorf("%w: tentativas de reconexão não podem ser negativas", ErrInvalidPLCConfig)
+	case config.MaxTagsPerPLC < 0:
+		return fmt.Errorf("%w: %w", ErrInvalidPLCConfig, ErrInvalidTagLimit)
+	case config.ConsecutiveErrorThreshold < 0:
+		return fmt.Errorf("%w: limite de falhas consecutivas não pode ser negativo", ErrInvalidPLCConfig)
+	case config.SyncInterval < MinSyncInterval:
+		return fmt.Errorf("%w: intervalo de sincronização deve ser de no mínimo %v", ErrInvalidPLCConfig, MinSyncInterval)
+	}
+	return nil
+}
+
+// ReloadConfig aplica uma nova configuração sem reiniciar o serviço e a
+// publica no Redis para as demais réplicas. Apenas os campos alteráveis em
+// execução são usados; filas e prefixo Redis só mudam na inicialização.
+func (s *PLCService) ReloadConfig(newConfig PLCConfig) error {
+	if err := validatePLCConfig(newConfig); err != nil {
+		return err
+	}
+
+	runtime := s.applyConfig(newConfig)
+
+	if client := s.cache.GetRedisClient(); client != nil {
+		if err := s.publishConfig(client, runtime); err != nil {
+			log.Printf("Aviso: configuração aplicada apenas nesta instância: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// applyConfig atualiza a configuração local, o gerenciador e a sincronização.
+// Retorna a configuração efetiva resultante.
+func (s *PLCService) applyConfig(newConfig PLCConfig) domain.PLCRuntimeConfig {
+	s.configMu.Lock()
+	config := s.config
+	config.DetailedLoggingEnabled = newConfig.DetailedLoggingEnabled
+	config.CacheEnabled = newConfig.CacheEnabled
+	config.MaxRetryAttempts = newConfig.MaxRetryAttempts
+	config.RetryInterval = newConfig.RetryInterval
+	config.DefaultTagScanRate = newConfig.DefaultTagScanRate
+	config.MaxTagsPerPLC = newConfig.MaxTagsPerPLC
+	config.ConsecutiveErrorThreshold = newConfig.ConsecutiveErrorThreshold
+	config.SyncInterval = newConfig.SyncInterval
+	s.config = config
+	s.configMu.Unlock()
+
+	s.manager.SetDetailedLogging(config.DetailedLoggingEnabled)
+	// Tags com scan rate 0 são reagrupadas com o novo padrão na próxima
+	// atualização da lista de tags de cada monitor
+	s.manager.SetRuntimeConfig(config.RetryInterval, config.DefaultTagScanRate, config.ConsecutiveErrorThreshold)
+
+	if s.syncService != nil {
+		if err := s.syncService.SetSyncInterval(config.SyncInterval); err != nil {
+			log.Printf("Aviso: erro ao atualizar intervalo de sincronização: %v", err)
+		}
+	}
+
+	return runtimeConfigOf(config)
+}
+
+// publishConfig grava a configuração no Redis e avisa as réplicas
+func (s *PLCService) publishConfig(client *redis.Client, runtime domain.PLCRuntimeConfig) error {
+	data, err := json.Marshal(runtime)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	prefix := s.cfg().RedisKeyPrefix
+	if err := client.Set(ctx, prefix+plcConfigKey, data, 0).Err(); err != nil {
+		return fmt.Errorf("erro ao gravar configuração no Redis: %w", err)
+	}
+	if err := client.Publish(ctx, prefix+plcConfigChannel, data).Err(); err != nil {
+		return fmt.Errorf("erro ao publicar configuração: %w", err)
+	}
+	return nil
+}
+
+// watchConfigChanges aplica a última configuração gravada no Redis e as
+// alterações publicadas por qualquer réplica (inclusive esta, sem efeito)
+// até ctx ser cancelado por StopConfigWatch
+func (s *PLCService) watchConfigChanges(ctx context.Context, client *redis.Client) {
+	defer close(s.configWatchDone)
+	prefix := s.cfg().RedisKeyPrefix
+
+	pubsub := client.Subscribe(ctx, prefix+plcConfigChannel)
+	defer pubsub.Close()
+
+	if data, err := client.Get(ctx, prefix+plcConfigKey).Bytes(); err == nil {
+		s.applyRemoteConfig(data)
+	} else if err != redis.Nil {
+		log.Printf("Aviso: erro ao ler configuração do PLC no Redis: %v", err)
+	}
+
+	messages := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-messages:
+			if !ok {
+				return
+			}
+			s.applyRemoteConfig([]byte(msg.Payload))
+		}
+	}
+}
+
+// StopConfigWatch encerra a aplicação das configurações publicadas no Redis
+func (s *PLCService) StopConfigWatch() {
+	if s.configWatchCancel == nil {
+		return
+	}
+	s.configWatchCancel()
+	<-s.configWatchDone
+	s.configWatchCancel = nil
+}
+
+// applyRemoteConfig aplica uma configuração recebida do Redis
+func (s *PLCService) applyRemoteConfig(data []byte) {
+	var runtime domain.PLCRuntimeConfig
+	if err := json.Unmarshal(data, &runtime); err != nil {
+		log.Printf("Aviso: configuração do PLC inválida no Redis: %v", err)
+		return
+	}
+
+	config := s.cfg().withRuntime(runtime)
+	if err := validatePLCConfig(config); err != nil {
+		log.Printf("Aviso: configuração do PLC recebida do Redis ignorada: %v", err)
+		return
+	}
+
+	s.applyConfig(config)
+	log.Printf("Configuração do PLC atualizada a partir do Redis")
+}
+
+// GetRuntimeConfig retorna a configuração efetiva alterável em execução
+func (s *PLCService) GetRuntimeConfig() domain.PLCRuntimeConfig {
+	runtime := runtimeConfigOf(s.cfg())
+	// O intervalo também pode ser alterado por SetSyncInterval
+	if s.syncService != nil {
+		runtime.SyncIntervalSec = int(s.syncService.GetSyncInterval() / time.Second)
+	}
+	return runtime
+}
+
+// UpdateRuntimeConfig aplica os campos informados sobre a configuração atual
+func (s *PLCService) UpdateRuntimeConfig(patch domain.PLCRuntimeConfigPatch, userID int) (domain.PLCRuntimeConfig, error) {
+	runtime := s.GetRuntimeConfig()
+	patch.Apply(&runtime)
+
+	if err := s.ReloadConfig(s.cfg().withRuntime(runtime)); err != nil {
+		return domain.PLCRuntimeConfig{}, err
+	}
+
+	log.Printf("Auditoria: entity_type=plc_config action=update user_id=%d", userID)
+
+	return s.GetRuntimeConfig(), nil
+}
+
+// runtimeConfigOf extrai os campos alteráveis em execução
+func runtimeConfigOf(config PLCConfig) domain.PLCRuntimeConfig {
+	return domain.PLCRuntimeConfig{
+		DetailedLoggingEnabled:    config.DetailedLoggingEnabled,
+		CacheEnabled:              config.CacheEnabled,
+		MaxRetryAttempts:          config.MaxRetryAttempts,
+		RetryIntervalMs:           int(config.RetryInterval / time.Millisecond),
+		DefaultTagScanRate:        config.DefaultTagScanRate,
+		MaxTagsPerPLC:             config.MaxTagsPerPLC,
+		ConsecutiveErrorThreshold: config.ConsecutiveErrorThreshold,
+		SyncIntervalSec:           int(config.SyncInterval / time.Second),
+	}
+}
+
+// withRuntime retorna a configuração com os campos alteráveis substituídos
+func (c PLCConfig) withRuntime(runtime domain.PLCRuntimeConfig) PLCConfig {
+	c.DetailedLoggingEnabled = runtime.DetailedLoggingEnabled
+	c.CacheEnabled = runtime.CacheEnabled
+	c.MaxRetryAttempts = runtime.MaxRetryAttempts
+	c.RetryInterval = time.Duration(runtime.RetryIntervalMs) * time.Millisecond
+	c.DefaultTagScanRate = runtime.DefaultTagScanRate
+	c.MaxTagsPerPLC = runtime.MaxTagsPerPLC
+	c.ConsecutiveErrorThreshold = runtime.ConsecutiveErrorThreshold
+	c.SyncInterval = time.Duration(runtime.SyncIntervalSec) * time.Second
+	return c
+}
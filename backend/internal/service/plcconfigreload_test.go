@@ -0,0 +1,103 @@
+package service
+
+import (
+	"app_padrao/internal/domain"
+	"app_padrao/internal/testutil"
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+)
+
+// fakeConfigCache expõe apenas o cliente Redis usado pela configuração
+type fakeConfigCache struct {
+	domain.PLCCache
+	client *redis.Client
+}
+
+func (c fakeConfigCache) GetRedisClient() *redis.Client {
+	return c.client
+}
+
+// TestConfigWatchWithRunningMonitor alterna o logging detalhado pelo Redis
+// enquanto um monitor de tags lê a opção; rodar com -race
+func TestConfigWatchWithRunningMonitor(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	config := DefaultPLCConfig()
+	config.RedisKeyPrefix = "app1:"
+	s := NewPLCServiceWithConfig(nil, nil, fakeConfigCache{client: client}, config)
+	t.Cleanup(s.StopConfigWatch)
+
+	// Aguardar a assinatura do canal para não perder as publicações
+	channel := config.RedisKeyPrefix + plcConfigChannel
+	deadline := time.Now().Add(2 * time.Second)
+	for mr.PubSubNumSub(channel)[channel] == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("configuração do Redis não assinada")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	sim := testutil.NewS7Simulator(t)
+	conn := NewPLCConnection(1, sim.Addr(), 0, 1)
+	if err := conn.Connect(); err != nil {
+		t.Fatalf("erro ao conectar ao simulador: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	tag := domain.PLCTag{ID: 1, PLCID: 1, Name: "Nivel", DBNumber: 1, DataType: "int", ScanRate: 10, Active: true, ScaleFactor: 1}
+	go func() {
+		defer close(done)
+		s.manager.startTagMonitor(10, []domain.PLCTag{tag}, make(chan tagListUpdate), ctx, domain.PLC{ID: 1, Name: "CLP"}, conn, &sync.Map{})
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	// As alterações chegam como se publicadas por outra réplica
+	for i := 0; i < 10; i++ {
+		config.DetailedLoggingEnabled = i%2 == 0
+		if err := s.publishConfig(client, runtimeConfigOf(config)); err != nil {
+			t.Fatalf("erro ao publicar configuração: %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	deadline = time.Now().Add(2 * time.Second)
+	for s.manager.enableDetailedLogging.Load() {
+		if time.Now().After(deadline) {
+			t.Fatal("última configuração (logging desativado) não aplicada")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		s.StopConfigWatch()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(2 * time.Second):
+		t.Fatal("StopConfigWatch não encerrou a assinatura")
+	}
+
+	// Depois de parado, novas publicações não são aplicadas
+	config.DetailedLoggingEnabled = true
+	if err := s.publishConfig(client, runtimeConfigOf(config)); err != nil {
+		t.Fatalf("erro ao publicar configuração: %v", err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if s.manager.enableDetailedLogging.Load() {
+		t.Error("configuração aplicada após StopConfigWatch")
+	}
+}
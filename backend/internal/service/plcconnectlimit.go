@@ -0,0 +1,74 @@
+// internal/service/plcconnectlimit.go
+package service
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// defaultStartupStagger é o atraso padrão entre o início de cada PLC
+	defaultStartupStagger = 100 * time.Millisecond
+	// defaultMaxConcurrentConnections é o padrão de PLCs conectando ao mesmo tempo
+	defaultMaxConcurrentConnections = 10
+)
+
+// initConnectSlots cria o semáforo que limita as tentativas de conexão
+// simultâneas (MaxConcurrentConnections <= 0 = sem limite). O canal é criado
+// uma única vez: conexões de um Start anterior ainda em andamento devolvem a
+// vaga ao mesmo canal em que a ocuparam.
+func (m *PLCManager) initConnectSlots() {
+	m.connectSlotsOnce.Do(func() {
+		if n := m.config.MaxConcurrentConnections; n > 0 {
+			m.connectSlots = make(chan struct{}, n)
+		}
+	})
+}
+
+// waitStartupStagger aguarda plcIndex * StartupStagger antes da primeira
+// conexão do PLC, para que dezenas de PLCs não conectem todos no mesmo
+// instante. Retorna false se o contexto for cancelado antes.
+func (m *PLCManager) waitStartupStagger(ctx context.Context, plcIndex int) bool {
+	delay := time.Duration(plcIndex) * m.config.StartupStagger
+	if delay <= 0 {
+		return true
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// connectLimited conecta ao PLC ocupando uma vaga do semáforo de conexões.
+// Retorna o erro do contexto se ele for cancelado enquanto aguarda a vaga.
+func (m *PLCManager) connectLimited(ctx context.Context, conn *PLCConnection) error {
+	if slots := m.connectSlots; slots != nil {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		defer func() { <-slots }()
+	}
+
+	m.recordConnectAttempts(atomic.AddInt64(&m.connectAttempts, 1))
+	defer func() {
+		m.recordConnectAttempts(atomic.AddInt64(&m.connectAttempts, -1))
+	}()
+
+	return conn.Connect()
+}
+
+// recordConnectAttempts publica o número de conexões em andamento
+func (m *PLCManager) recordConnectAttempts(n int64) {
+	if m.metrics != nil {
+		m.metrics.SetGauge("plc.connection.concurrent_attempts", float64(n))
+	}
+}
@@ -0,0 +1,38 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestConnectSlotsSurviveRestart(t *testing.T) {
+	m := NewPLCManager(nil, nil, nil)
+	m.config.MaxConcurrentConnections = 1
+
+	m.initConnectSlots()
+	slots := m.connectSlots
+	if cap(slots) != 1 {
+		t.Fatalf("capacidade = %d, esperado 1", cap(slots))
+	}
+
+	// Uma conexão do Start anterior ainda ocupa a vaga
+	slots <- struct{}{}
+
+	m.initConnectSlots()
+	if m.connectSlots != slots {
+		t.Fatal("um novo Start não deveria trocar o canal de vagas")
+	}
+
+	// Sem vaga livre a nova tentativa espera até o contexto ser cancelado
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := m.connectLimited(ctx, nil); !errors.Is(err, context.Canceled) {
+		t.Fatalf("erro = %v, esperado context.Canceled", err)
+	}
+
+	<-slots
+	if len(m.connectSlots) != 0 {
+		t.Errorf("vagas ocupadas = %d, esperado 0", len(m.connectSlots))
+	}
+}
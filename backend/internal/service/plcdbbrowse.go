@@ -0,0 +1,118 @@
+package service
+
+import (
+	"app_padrao/internal/domain"
+	"errors"
+	"fmt"
+	"log"
+)
+
+// ErrDBPokeDisabled indica que a gravação de bytes brutos não foi habilitada
+// (PLC_ENABLE_DB_POKE)
+var ErrDBPokeDisabled = errors.New("gravação de bytes brutos em DB desabilitada")
+
+// readRawBytes lê length bytes do DB a partir de offset, sem interpretação
+func (p *PLCConnection) readRawBytes(dbNumber, offset, length int) ([]byte, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if !p.active || p.s7Client == nil {
+		return nil, ErrPLCNotConnected
+	}
+
+	return p.s7Client.BatchReadDB(dbNumber, offset, length)
+}
+
+// writeRawBytes grava os bytes no DB a partir de offset, sem conversão
+func (p *PLCConnection) writeRawBytes(dbNumber, offset int, data []byte) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if !p.active || p.s7Client == nil {
+		return ErrPLCNotConnected
+	}
+
+	return p.s7Client.WriteRawDB(dbNumber, offset, data)
+}
+
+// BrowseDB lê uma faixa de bytes de um DB do PLC
+func (m *PLCManager) BrowseDB(plcID, dbNumber, offset, length int) ([]byte, error) {
+	conn, err := m.GetConnectionByPLCID(plcID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !m.beginInFlight() {
+		return nil, fmt.Errorf("%w: gerenciador em encerramento", ErrPLCNotConnected)
+	}
+	defer m.endInFlight()
+
+	return conn.readRawBytes(dbNumber, offset, length)
+}
+
+// PokeDB grava bytes brutos em um DB do PLC
+func (m *PLCManager) PokeDB(plcID, dbNumber, offset int, data []byte) error {
+	conn, err := m.GetConnectionByPLCID(plcID)
+	if err != nil {
+		return err
+	}
+
+	if !m.beginInFlight() {
+		return fmt.Errorf("%w: gerenciador em encerramento", ErrPLCNotConnected)
+	}
+	defer m.endInFlight()
+
+	return conn.writeRawBytes(dbNumber, offset, data)
+}
+
+// BrowseDataBlock lê até domain.MaxDBBrowseLength bytes de um DB e os
+// interpreta palavra a palavra, para descobrir endereços de PLCs sem tabela
+// de símbolos
+func (s *PLCService) BrowseDataBlock(plcID, dbNumber, offset, length int) (domain.DBBrowseResult, error) {
+	if err := domain.ValidateDBRange(dbNumber, offset, length); err != nil {
+		return domain.DBBrowseResult{}, err
+	}
+
+	s.mu.RLock()
+	isRunning := s.isRunning
+	s.mu.RUnlock()
+
+	if !isRunning || s.manager == nil {
+		return domain.DBBrowseResult{}, ErrMonitoringNotActive
+	}
+
+	data, err := s.manager.BrowseDB(plcID, dbNumber, offset, length)
+	if err != nil {
+		return domain.DBBrowseResult{}, err
+	}
+
+	return domain.NewDBBrowseResult(plcID, dbNumber, offset, data), nil
+}
+
+// PokeDataBlock grava bytes brutos em um DB, sem passar pelas tags nem pelos
+// intertravamentos de escrita. Só para depuração: exige PLC_ENABLE_DB_POKE.
+func (s *PLCService) PokeDataBlock(plcID, dbNumber, offset int, data []byte, userID int) error {
+	if !s.cfg().EnableDBPoke {
+		return ErrDBPokeDisabled
+	}
+
+	if err := domain.ValidateDBRange(dbNumber, offset, len(data)); err != nil {
+		return err
+	}
+
+	s.mu.RLock()
+	isRunning := s.isRunning
+	s.mu.RUnlock()
+
+	if !isRunning || s.manager == nil {
+		return ErrMonitoringNotActive
+	}
+
+	if err := s.manager.PokeDB(plcID, dbNumber, offset, data); err != nil {
+		return err
+	}
+
+	log.Printf("Auditoria: entity_type=plc entity_id=%d action=db_poke user_id=%d db=%d offset=%d bytes=%X",
+		plcID, userID, dbNumber, offset, data)
+	return nil
+}
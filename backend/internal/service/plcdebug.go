@@ -0,0 +1,194 @@
+// internal/service/plcdebug.go
+package service
+
+import (
+	"app_padrao/internal/domain"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"time"
+)
+
+// ErrInvalidDebugInterval é retornado para intervalos do monitor de depuração
+// menores ou iguais a zero
+var ErrInvalidDebugInterval = errors.New("intervalo do monitor de depuração deve ser maior que zero")
+
+// SetDebugOutput define onde o monitor de depuração escreve (padrão: os.Stdout)
+func (s *PLCService) SetDebugOutput(w io.Writer) {
+	s.debugMu.Lock()
+	defer s.debugMu.Unlock()
+	s.debugOutput = w
+}
+
+// StartDebugMonitor inicia o monitor de depuração com o intervalo configurado
+// em PLCConfig.DebugMonitorIntervalSec. Com intervalo zero o monitor fica
+// desativado.
+func (s *PLCService) StartDebugMonitor() {
+	if s.cfg().DebugMonitorIntervalSec <= 0 {
+		log.Println("DEPURAÇÃO: Monitor de depuração desativado na configuração")
+		return
+	}
+
+	if err := s.StartDebugMonitorWithInterval(s.cfg().DebugMonitorIntervalSec); err != nil {
+		log.Printf("DEPURAÇÃO: Não foi possível iniciar o monitor de depuração: %v", err)
+	}
+}
+
+// StartDebugMonitorWithInterval inicia uma rotina que imprime os valores de
+// todas as tags ativas a cada intervalSec segundos. Um monitor já em execução
+// é substituído.
+func (s *PLCService) StartDebugMonitorWithInterval(intervalSec int) error {
+	if intervalSec <= 0 {
+		return ErrInvalidDebugInterval
+	}
+
+	s.mu.RLock()
+	isRunning := s.isRunning
+	s.mu.RUnlock()
+
+	if !isRunning {
+		return ErrMonitoringNotActive
+	}
+
+	s.debugMu.Lock()
+	defer s.debugMu.Unlock()
+
+	if s.debugMonitorCancel != nil {
+		s.debugMonitorCancel()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.debugMonitorCancel = cancel
+	logger := log.New(s.debugOutput, "", log.LstdFlags)
+
+	log.Printf("DEPURAÇÃO: Iniciando monitor de depuração para valores de tags (intervalo: %ds)", intervalSec)
+
+	go func() {
+		ticker := time.NewTicker(time.Duration(intervalSec) * time.Second)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				// Verificar se o serviço ainda está em execução
+				s.mu.RLock()
+				stillRunning := s.isRunning
+				s.mu.RUnlock()
+
+				if !stillRunning {
+					logger.Println("DEPURAÇÃO: Monitor de depuração interrompido devido à parada do serviço")
+					return
+				}
+
+				s.printDebugSnapshot(logger)
+			}
+		}
+	}()
+
+	return nil
+}
+
+// StopDebugMonitor interrompe o monitor de depuração, se estiver em execução
+func (s *PLCService) StopDebugMonitor() {
+	s.debugMu.Lock()
+	defer s.debugMu.Unlock()
+
+	if s.debugMonitorCancel != nil {
+		s.debugMonitorCancel()
+		s.debugMonitorCancel = nil
+		log.Println("DEPURAÇÃO: Monitor de depuração parado")
+	}
+}
+
+// printDebugSnapshot escreve os valores atuais das tags ativas de cada PLC
+func (s *PLCService) printDebugSnapshot(logger *log.Logger) {
+	// Obter todos os PLCs ativos
+	plcs, err := s.GetActivePLCs()
+	if err != nil {
+		logger.Printf("DEPURAÇÃO: Erro ao buscar PLCs ativos: %v", err)
+		return
+	}
+
+	if len(plcs) == 0 {
+		logger.Println("DEPURAÇÃO: Nenhum PLC ativo encontrado")
+		return
+	}
+
+	// Para cada PLC, buscar suas tags
+	for _, plc := range plcs {
+		tags, err := s.GetPLCTags(plc.ID)
+		if err != nil {
+			logger.Printf("DEPURAÇÃO: Erro ao buscar tags do PLC %s (ID=%d): %v",
+				plc.Name, plc.ID, err)
+			continue
+		}
+
+		if len(tags) == 0 {
+			logger.Printf("DEPURAÇÃO: PLC %s (ID=%d) não tem tags", plc.Name, plc.ID)
+			continue
+		}
+
+		// Filtrar apenas tags ativas
+		activeTags := make([]domain.PLCTag, 0)
+		for _, tag := range tags {
+			if tag.Active {
+				activeTags = append(activeTags, tag)
+			}
+		}
+
+		if len(activeTags) == 0 {
+			logger.Printf("DEPURAÇÃO: PLC %s (ID=%d) não tem tags ativas", plc.Name, plc.ID)
+			continue
+		}
+
+		// Imprimir cabeçalho
+		logger.Printf("=== VALORES ATUAIS DO PLC %s (STATUS: %s) ===", plc.Name, plc.Status)
+
+		// Imprimir cada tag com seu valor
+		for _, tag := range activeTags {
+			// Buscar o valor mais recente do cache
+			tagValue, err := s.cache.GetTagValue(plc.ID, tag.ID)
+
+			var valorStr string
+			if err != nil || tagValue == nil {
+				valorStr = "<sem valor>"
+			} else {
+				// Formatação mais legível do valor
+				switch tag.DataType {
+				case "real":
+					if v, ok := tagValue.Value.(float32); ok {
+						valorStr = fmt.Sprintf("%.3f", v)
+					} else {
+						valorStr = fmt.Sprintf("%v", tagValue.Value)
+					}
+				case "bool":
+					if v, ok := tagValue.Value.(bool); ok {
+						if v {
+							valorStr = "TRUE"
+						} else {
+							valorStr = "FALSE"
+						}
+					} else {
+						valorStr = fmt.Sprintf("%v", tagValue.Value)
+					}
+				default:
+					valorStr = fmt.Sprintf("%v", tagValue.Value)
+				}
+			}
+
+			logger.Printf("  Tag: %-20s | Tipo: %-6s | DB%d.DBX%d.%d | Valor: %s",
+				tag.Name,
+				tag.DataType,
+				tag.DBNumber,
+				tag.ByteOffset,
+				tag.BitOffset,
+				valorStr)
+		}
+
+		logger.Println("=============================================")
+	}
+}
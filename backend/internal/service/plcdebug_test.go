@@ -0,0 +1,169 @@
+package service
+
+import (
+	"app_padrao/internal/domain"
+	"bytes"
+	"errors"
+	"log"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeDebugPLCRepo implementa a listagem e a busca de PLCs usadas pelo snapshot
+type fakeDebugPLCRepo struct {
+	domain.PLCRepository
+	plcs []domain.PLC
+}
+
+func (r *fakeDebugPLCRepo) GetActivePLCs() ([]domain.PLC, error) {
+	return r.plcs, nil
+}
+
+func (r *fakeDebugPLCRepo) GetByID(id int) (domain.PLC, error) {
+	for _, plc := range r.plcs {
+		if plc.ID == id {
+			return plc, nil
+		}
+	}
+	return domain.PLC{}, domain.ErrPLCNotFound
+}
+
+// fakeDebugCache devolve valores fixos por tag
+type fakeDebugCache struct {
+	domain.PLCCache
+	values map[int]interface{}
+}
+
+func (c *fakeDebugCache) GetTagValue(plcID, tagID int) (*domain.TagValue, error) {
+	value, ok := c.values[tagID]
+	if !ok {
+		return nil, nil
+	}
+	return &domain.TagValue{PLCID: plcID, TagID: tagID, Value: value}, nil
+}
+
+func (c *fakeDebugCache) GetMultipleTagValues(queries []struct{ PLCID, TagID int }) ([]domain.TagValue, error) {
+	return nil, nil
+}
+
+// syncBuffer permite ler a saída enquanto o monitor escreve nela
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func newDebugTestService() *PLCService {
+	plcRepo := &fakeDebugPLCRepo{plcs: []domain.PLC{
+		{ID: 1, Name: "CLP1", Status: "online", Active: true},
+		{ID: 2, Name: "CLP2", Status: "offline", Active: true},
+	}}
+	tagRepo := &fakeManagerTagRepo{tags: []domain.PLCTag{
+		{ID: 10, PLCID: 1, Name: "Temperatura", DBNumber: 1, ByteOffset: 0, DataType: "real", Active: true},
+		{ID: 11, PLCID: 1, Name: "Ligado", DBNumber: 1, ByteOffset: 4, BitOffset: 2, DataType: "bool", Active: true},
+		{ID: 12, PLCID: 1, Name: "Contador", DBNumber: 1, ByteOffset: 6, DataType: "int", Active: true},
+		{ID: 13, PLCID: 1, Name: "Inativa", DBNumber: 1, ByteOffset: 8, DataType: "int", Active: false},
+	}}
+
+	return &PLCService{
+		pgPLCRepo:    plcRepo,
+		pgTagRepo:    tagRepo,
+		redisPLCRepo: plcRepo,
+		redisTagRepo: tagRepo,
+		cache: &fakeDebugCache{values: map[int]interface{}{
+			10: float32(21.5),
+			11: true,
+		}},
+		plcMetaCache: newPLCMetadataCache(10, 60),
+	}
+}
+
+func TestPrintDebugSnapshotFormat(t *testing.T) {
+	s := newDebugTestService()
+	var out bytes.Buffer
+
+	s.printDebugSnapshot(log.New(&out, "", 0))
+
+	block := strings.Join([]string{
+		"=== VALORES ATUAIS DO PLC CLP1 (STATUS: online) ===",
+		"  Tag: Temperatura          | Tipo: real   | DB1.DBX0.0 | Valor: 21.500",
+		"  Tag: Ligado               | Tipo: bool   | DB1.DBX4.2 | Valor: TRUE",
+		"  Tag: Contador             | Tipo: int    | DB1.DBX6.0 | Valor: <sem valor>",
+		"=============================================",
+	}, "\n") + "\n"
+
+	// O mesmo repositório de tags atende os dois PLCs
+	want := block + strings.Replace(block, "CLP1 (STATUS: online)", "CLP2 (STATUS: offline)", 1)
+	if got := out.String(); got != want {
+		t.Errorf("saída:\n%s\nesperado:\n%s", got, want)
+	}
+	if strings.Contains(out.String(), "Inativa") {
+		t.Error("tags inativas não deveriam aparecer")
+	}
+}
+
+func TestPrintDebugSnapshotWithoutPLCs(t *testing.T) {
+	s := newDebugTestService()
+	s.pgPLCRepo = &fakeDebugPLCRepo{}
+	s.redisPLCRepo = s.pgPLCRepo
+	var out bytes.Buffer
+
+	s.printDebugSnapshot(log.New(&out, "", 0))
+
+	if got, want := out.String(), "DEPURAÇÃO: Nenhum PLC ativo encontrado\n"; got != want {
+		t.Errorf("saída = %q, esperado %q", got, want)
+	}
+}
+
+func TestStartDebugMonitorWithIntervalErrors(t *testing.T) {
+	s := newDebugTestService()
+
+	if err := s.StartDebugMonitorWithInterval(0); !errors.Is(err, ErrInvalidDebugInterval) {
+		t.Errorf("intervalo zero: erro = %v, esperado ErrInvalidDebugInterval", err)
+	}
+	if err := s.StartDebugMonitorWithInterval(1); !errors.Is(err, ErrMonitoringNotActive) {
+		t.Errorf("serviço parado: erro = %v, esperado ErrMonitoringNotActive", err)
+	}
+}
+
+func TestDebugMonitorWritesToOutputAndStops(t *testing.T) {
+	s := newDebugTestService()
+	s.isRunning = true
+	out := &syncBuffer{}
+	s.SetDebugOutput(out)
+
+	if err := s.StartDebugMonitorWithInterval(1); err != nil {
+		t.Fatalf("erro ao iniciar monitor: %v", err)
+	}
+
+	deadline := time.Now().Add(3 * time.Second)
+	for !strings.Contains(out.String(), "=== VALORES ATUAIS DO PLC CLP1") {
+		if time.Now().After(deadline) {
+			s.StopDebugMonitor()
+			t.Fatalf("monitor não escreveu na saída configurada: %q", out.String())
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	s.StopDebugMonitor()
+	written := len(out.String())
+
+	// Nenhum disparo após o Stop
+	time.Sleep(1500 * time.Millisecond)
+	if after := len(out.String()); after != written {
+		t.Errorf("monitor escreveu %d bytes após StopDebugMonitor", after-written)
+	}
+}
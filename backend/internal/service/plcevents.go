@@ -0,0 +1,158 @@
+// internal/service/plcevents.go
+package service
+
+import (
+	"app_padrao/internal/domain"
+	"app_padrao/pkg/events"
+	"context"
+	"log"
+	"time"
+)
+
+// maxCacheEventBatch é o maior lote gravado de uma vez pelo atualizador do cache
+const maxCacheEventBatch = 500
+
+// Events retorna o barramento onde o gerenciador publica leituras de tags e
+// mudanças de status dos PLCs
+func (m *PLCManager) Events() *events.Bus {
+	return m.events
+}
+
+// publishTagValues publica uma leitura por valor do lote
+func (m *PLCManager) publishTagValues(values []domain.TagValue, synthetic bool) {
+	for _, v := range values {
+		m.events.Publish(events.TagValueChangedEvent{
+			PLCID:     v.PLCID,
+			TagID:     v.TagID,
+			Value:     v.Value,
+			RawValue:  v.RawValue,
+			Timestamp: v.Timestamp,
+			Quality:   v.Quality,
+			Synthetic: synthetic,
+		})
+	}
+}
+
+// setPLCStatus grava o status do PLC e publica PLCStatusChangedEvent quando
+// ele difere do último status conhecido
+func (m *PLCManager) setPLCStatus(plcID int, status string) error {
+	err := m.plcRepo.UpdatePLCStatus(domain.PLCStatus{
+		PLCID:      plcID,
+		Status:     status,
+		LastUpdate: time.Now(),
+	})
+
+	m.statusMutex.Lock()
+	oldStatus, known := m.plcStatus[plcID]
+	m.plcStatus[plcID] = status
+	m.statusMutex.Unlock()
+
+	if !known || oldStatus != status {
+		m.events.Publish(events.PLCStatusChangedEvent{
+			PLCID:     plcID,
+			OldStatus: oldStatus,
+			NewStatus: status,
+		})
+		if m.onStatusChange != nil {
+			m.onStatusChange(plcID)
+		}
+	}
+
+	return err
+}
+
+// startEventConsumers inicia os consumidores das leituras publicadas pelo
+// monitoramento: o atualizador do cache e, se houver histórico, o ingestor
+func (m *PLCManager) startEventConsumers(ctx context.Context) {
+	cacheEvents := m.events.Subscribe(events.TypeTagValueChanged)
+	m.goTracked(func() {
+		defer m.events.Unsubscribe(cacheEvents)
+		m.runCacheUpdater(ctx, cacheEvents)
+	})
+
+	if m.historyQueue != nil {
+		historyEvents := m.events.Subscribe(events.TypeTagValueChanged)
+		m.goTracked(func() {
+			defer m.events.Unsubscribe(historyEvents)
+			m.runHistoryIngester(ctx, historyEvents)
+		})
+	}
+}
+
+// tagValueFromEvent converte o evento no valor armazenado no cache
+func tagValueFromEvent(e events.TagValueChangedEvent) domain.TagValue {
+	return domain.TagValue{
+		PLCID:     e.PLCID,
+		TagID:     e.TagID,
+		Value:     e.Value,
+		RawValue:  e.RawValue,
+		Quality:   e.Quality,
+		Timestamp: e.Timestamp,
+	}
+}
+
+// runCacheUpdater grava as leituras no Redis, agrupando os eventos já
+// disponíveis no canal em um único lote
+func (m *PLCManager) runCacheUpdater(ctx context.Context, ch <-chan events.Event) {
+	for {
+		var first events.Event
+		select {
+		case <-ctx.Done():
+			return
+		case first = <-ch:
+		}
+
+		batch := []domain.TagValue{}
+		goodReads := 0
+		add := func(ev events.Event) {
+			e, ok := ev.(events.TagValueChangedEvent)
+			if !ok {
+				return
+			}
+			batch = append(batch, tagValueFromEvent(e))
+			if !e.Synthetic && e.Quality == domain.QualityGood {
+				goodReads++
+			}
+		}
+		add(first)
+
+	drain:
+		for len(batch) < maxCacheEventBatch {
+			select {
+			case ev := <-ch:
+				add(ev)
+			default:
+				break drain
+			}
+		}
+
+		if len(batch) == 0 {
+			continue
+		}
+
+		if err := m.cache.BatchSetTagValues(batch); err != nil {
+			log.Printf("Erro ao atualizar valores em lote: %v", err)
+			continue
+		}
+
+		m.statsMutex.Lock()
+		m.stats.TagsRead += int64(goodReads)
+		m.statsMutex.Unlock()
+	}
+}
+
+// runHistoryIngester envia as leituras bem-sucedidas para a fila do histórico
+func (m *PLCManager) runHistoryIngester(ctx context.Context, ch <-chan events.Event) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev := <-ch:
+			e, ok := ev.(events.TagValueChangedEvent)
+			if !ok || e.Synthetic {
+				continue
+			}
+			m.enqueueHistory([]domain.TagValue{tagValueFromEvent(e)})
+		}
+	}
+}
@@ -0,0 +1,153 @@
+// internal/service/plchealthcheck.go
+package service
+
+import (
+	"app_padrao/internal/domain"
+	"context"
+	"fmt"
+	"log"
+	"time"
+)
+
+const (
+	// defaultPingInterval é o intervalo padrão entre pings de uma conexão ativa
+	defaultPingInterval = 30 * time.Second
+	// pingTimeout é a espera máxima por um ping
+	pingTimeout = 3 * time.Second
+	// healthCheckTimeout é a espera máxima pela verificação de todos os PLCs
+	healthCheckTimeout = 10 * time.Second
+)
+
+// pingWithTimeout faz o ping com prazo de pingTimeout (limitado pelo prazo
+// de ctx) e retorna o status do PLC: "online", "falha: ..." ou "timeout".
+// O ping roda em outra goroutine porque pode estar esperando a conexão,
+// ocupada por uma leitura, antes de chegar ao socket; nesse caso a
+// verificação não espera por ele. A duração fica nas estatísticas da conexão.
+func (m *PLCManager) pingWithTimeout(ctx context.Context, plcID int, conn *PLCConnection) string {
+	pingCtx, cancel := context.WithTimeout(ctx, pingTimeout)
+	defer cancel()
+
+	start := time.Now()
+	result := make(chan error, 1)
+	go func() {
+		result <- conn.PingContext(pingCtx)
+	}()
+
+	var status string
+	select {
+	case err := <-result:
+		switch {
+		case err == nil:
+			status = "online"
+		case pingCtx.Err() != nil:
+			status = "timeout"
+		default:
+			status = fmt.Sprintf("falha: %v", err)
+		}
+	case <-pingCtx.Done():
+		status = "timeout"
+	}
+
+	m.recordPingDuration(plcID, time.Since(start))
+	return status
+}
+
+// recordPingDuration registra nas estatísticas a duração do último ping
+func (m *PLCManager) recordPingDuration(plcID int, d time.Duration) {
+	m.statsMutex.Lock()
+	defer m.statsMutex.Unlock()
+
+	if connStats, exists := m.stats.ConnectionStats[plcID]; exists {
+		connStats.Timeout = d
+		m.stats.ConnectionStats[plcID] = connStats
+	}
+}
+
+// runConnectionHealthCheck envia um ping ao PLC a cada PingInterval e
+// reconecta quando ele falha, sem esperar que o monitor do PLC seja
+// reiniciado. Termina com o contexto do monitor.
+func (m *PLCManager) runConnectionHealthCheck(ctx context.Context, plcConfig domain.PLC, conn *PLCConnection) {
+	interval := m.config.PingInterval
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// Timeout indica conexão ocupada por uma leitura longa, não caída:
+			// só uma falha do ping dispara a reconexão
+			switch status := m.pingWithTimeout(ctx, plcConfig.ID, conn); status {
+			case "online":
+			case "timeout":
+				log.Printf("evento=plc_ping_timeout plc_id=%d timeout=%v", plcConfig.ID, pingTimeout)
+			default:
+				log.Printf("evento=plc_ping_failed plc_id=%d erro=%q", plcConfig.ID, status)
+				m.reconnectAfterPingFailure(ctx, plcConfig, conn)
+			}
+		}
+	}
+}
+
+// reconnectAfterPingFailure reconecta ao PLC com o backoff exponencial de
+// reconnectWithBackoff. O bloqueio de escrita em conn.scanMu pausa os
+// monitores de tags do PLC até a conexão voltar.
+func (m *PLCManager) reconnectAfterPingFailure(ctx context.Context, plcConfig domain.PLC, conn *PLCConnection) {
+	conn.scanMu.Lock()
+	defer conn.scanMu.Unlock()
+
+	m.recordReconnectAttempt(plcConfig)
+
+	if err := m.setPLCStatus(plcConfig.ID, "offline"); err != nil {
+		log.Printf("Erro ao atualizar status do PLC %d: %v", plcConfig.ID, err)
+	}
+
+	if err := m.connectLimited(ctx, conn); err != nil {
+		if ctx.Err() != nil {
+			return
+		}
+		log.Printf("evento=plc_reconnect_failed plc_id=%d attempt_count=1 erro=%q", plcConfig.ID, err.Error())
+
+		if !m.reconnectWithBackoff(ctx, plcConfig, conn, 1) {
+			return
+		}
+	}
+
+	m.statsMutex.Lock()
+	if connStats, exists := m.stats.ConnectionStats[plcConfig.ID]; exists {
+		connStats.NextRetryAt = time.Time{}
+		connStats.LastConnected = time.Now()
+		m.stats.ConnectionStats[plcConfig.ID] = connStats
+	}
+	m.statsMutex.Unlock()
+
+	if err := m.setPLCStatus(plcConfig.ID, "online"); err != nil {
+		log.Printf("Erro ao atualizar status do PLC %d: %v", plcConfig.ID, err)
+	}
+	log.Printf("evento=plc_ping_reconnected plc_id=%d", plcConfig.ID)
+}
+
+// recordReconnectAttempt registra nas estatísticas uma reconexão disparada
+// por falha de ping
+func (m *PLCManager) recordReconnectAttempt(plcConfig domain.PLC) {
+	m.statsMutex.Lock()
+	defer m.statsMutex.Unlock()
+
+	connStats, exists := m.stats.ConnectionStats[plcConfig.ID]
+	if !exists {
+		connStats = PLCConnectionStats{
+			PLCID:  plcConfig.ID,
+			Name:   plcConfig.Name,
+			Status: "offline",
+		}
+	}
+
+	connStats.ReconnectAttempts++
+	connStats.LastReconnectAt = time.Now()
+	m.stats.ConnectionStats[plcConfig.ID] = connStats
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"app_padrao/internal/domain"
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPingWithTimeoutSlowPing(t *testing.T) {
+	m := NewPLCManager(nil, nil, nil)
+	m.stats.ConnectionStats[1] = PLCConnectionStats{PLCID: 1}
+	conn := &PLCConnection{plcID: 1, active: true}
+
+	// Simula um ping de 5 s: uma leitura ocupa a conexão por 5 s
+	conn.mutex.Lock()
+	release := time.AfterFunc(5*time.Second, conn.mutex.Unlock)
+	defer func() {
+		if release.Stop() {
+			conn.mutex.Unlock()
+		}
+	}()
+
+	start := time.Now()
+	status := m.pingWithTimeout(context.Background(), 1, conn)
+	elapsed := time.Since(start)
+
+	if status != "timeout" {
+		t.Errorf("status = %q, esperado \"timeout\"", status)
+	}
+	if elapsed >= 4*time.Second {
+		t.Errorf("pingWithTimeout levou %v, esperado no máximo ~%v", elapsed, pingTimeout)
+	}
+	if got := m.stats.ConnectionStats[1].Timeout; got < pingTimeout {
+		t.Errorf("duração registrada = %v, esperado pelo menos %v", got, pingTimeout)
+	}
+}
+
+func TestPingWithTimeoutParentDeadline(t *testing.T) {
+	m := NewPLCManager(nil, nil, nil)
+	conn := &PLCConnection{plcID: 1, active: true}
+
+	conn.mutex.Lock()
+	defer conn.mutex.Unlock()
+
+	// O prazo da verificação completa vale mesmo abaixo de pingTimeout
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if status := m.pingWithTimeout(ctx, 1, conn); status != "timeout" {
+		t.Errorf("status = %q, esperado \"timeout\"", status)
+	}
+	if elapsed := time.Since(start); elapsed >= time.Second {
+		t.Errorf("pingWithTimeout levou %v, esperado o prazo do contexto pai", elapsed)
+	}
+}
+
+// statusRecorder implementa apenas o UpdatePLCStatus usado pelo gerenciador
+type statusRecorder struct {
+	domain.PLCRepository
+	mu       sync.Mutex
+	statuses []string
+}
+
+func (r *statusRecorder) UpdatePLCStatus(status domain.PLCStatus) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.statuses = append(r.statuses, status.Status)
+	return nil
+}
+
+func (r *statusRecorder) recorded() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.statuses...)
+}
+
+// reconnectAttempts lê das estatísticas as reconexões disparadas por ping
+func reconnectAttempts(m *PLCManager, plcID int) int64 {
+	m.statsMutex.RLock()
+	defer m.statsMutex.RUnlock()
+	return m.stats.ConnectionStats[plcID].ReconnectAttempts
+}
+
+func TestHealthCheckReconnectsAfterPingFailure(t *testing.T) {
+	repo := &statusRecorder{}
+	m := NewPLCManager(repo, nil, nil)
+	m.config.PingInterval = 10 * time.Millisecond
+	m.config.RetryInterval = 10 * time.Millisecond
+
+	// Sem cliente S7 o ping falha na hora; a reconexão também falha
+	// (nada escuta na porta S7 local), o que mantém o backoff em execução
+	plcConfig := domain.PLC{ID: 1, Name: "teste", IPAddress: "127.0.0.1"}
+	conn := NewPLCConnection(1, "127.0.0.1", 0, 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		m.runConnectionHealthCheck(ctx, plcConfig, conn)
+	}()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for reconnectAttempts(m, 1) == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	cancel()
+	<-done
+
+	if got := reconnectAttempts(m, 1); got == 0 {
+		t.Fatal("falha de ping deveria disparar uma tentativa de reconexão")
+	}
+	if statuses := repo.recorded(); len(statuses) == 0 || statuses[0] != "offline" {
+		t.Errorf("status gravados = %v, esperado \"offline\" primeiro", statuses)
+	}
+}
+
+func TestHealthCheckBusyConnectionDoesNotReconnect(t *testing.T) {
+	m := NewPLCManager(&statusRecorder{}, nil, nil)
+	m.config.PingInterval = 10 * time.Millisecond
+
+	plcConfig := domain.PLC{ID: 1, Name: "teste", IPAddress: "127.0.0.1"}
+	conn := NewPLCConnection(1, "127.0.0.1", 0, 1)
+
+	// Uma leitura longa ocupa a conexão: o ping expira sem falhar
+	conn.mutex.Lock()
+	defer conn.mutex.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), pingTimeout+500*time.Millisecond)
+	defer cancel()
+	m.runConnectionHealthCheck(ctx, plcConfig, conn)
+
+	if got := reconnectAttempts(m, 1); got != 0 {
+		t.Errorf("tentativas de reconexão = %d, esperado 0 com a conexão ocupada", got)
+	}
+}
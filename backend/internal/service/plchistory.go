@@ -0,0 +1,328 @@
+// internal/service/plchistory.go
+package service
+
+import (
+	"app_padrao/internal/domain"
+	"app_padrao/internal/metrics"
+	"app_padrao/pkg/plc"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+)
+
+// Erros relacionados ao histórico de tags
+var (
+	ErrHistoryNotConfigured    = errors.New("histórico de tags não configurado")
+	ErrInvalidDerivativeWindow = errors.New("janela de cálculo deve ser maior que zero")
+	ErrInvalidHistoryRange     = errors.New("início do intervalo deve ser anterior ao fim")
+	ErrInvalidInterpolation    = errors.New("modo de interpolação inválido (use linear, stepwise ou none)")
+	ErrInterpolationNotAllowed = errors.New("interpolação só é permitida em tags numéricas")
+	ErrInvalidHistoryWindow    = errors.New("janela do histórico deve ser maior que zero")
+	ErrTooManyHistoryPoints    = errors.New("intervalo e janela geram pontos demais")
+
+	ErrHistoryExportTooLarge = errors.New("intervalo excede o número máximo de registros da exportação")
+)
+
+// historyExportBatchSize é o número de registros lidos por vez na exportação
+const historyExportBatchSize = 500
+
+// historyCSVBatchSize é o número de registros por lote (e por flush) na
+// exportação em CSV
+const historyCSVBatchSize = 1000
+
+// maxInterpolatedPoints limita o tamanho da série gerada por GetTagHistory
+const maxInterpolatedPoints = 10000
+
+// SetHistoryRepository habilita o registro e a consulta do histórico de valores
+func (s *PLCService) SetHistoryRepository(repo domain.PLCTagHistoryRepository) {
+	s.historyRepo = repo
+	if s.manager != nil {
+		s.manager.SetHistoryRepository(repo)
+	}
+}
+
+// SetMetricsCollector define o coletor de métricas usado pelo gerenciador de PLCs
+func (s *PLCService) SetMetricsCollector(collector *metrics.MetricsCollector) {
+	if s.manager != nil {
+		s.manager.SetMetricsCollector(collector)
+	}
+}
+
+// GetHistoryQueueStats retorna o estado da fila de gravação do histórico
+func (s *PLCService) GetHistoryQueueStats() domain.HistoryQueueStats {
+	if s.manager == nil {
+		return domain.HistoryQueueStats{}
+	}
+	return s.manager.GetHistoryQueueStats()
+}
+
+// GetTagDerivative calcula a taxa de variação (unidades por segundo) de uma tag
+// usando as duas leituras mais recentes do histórico dentro da janela informada
+func (s *PLCService) GetTagDerivative(plcID, tagID int, windowMs int) (float64, error) {
+	if windowMs <= 0 {
+		return 0, ErrInvalidDerivativeWindow
+	}
+
+	// Verificar se a tag existe e pertence ao PLC
+	tag, err := s.GetTagByID(tagID)
+	if err != nil {
+		return 0, fmt.Errorf("erro ao verificar existência da tag: %w", err)
+	}
+
+	if tag.PLCID != plcID {
+		return 0, fmt.Errorf("tag %d não pertence ao PLC %d: %w", tagID, plcID, domain.ErrPLCTagNotFound)
+	}
+
+	return s.tagDerivative(plcID, tagID, windowMs)
+}
+
+// tagDerivative faz o cálculo da derivada sem validar a tag, usando cache Redis
+// com TTL de metade da janela
+func (s *PLCService) tagDerivative(plcID, tagID int, windowMs int) (float64, error) {
+	if s.historyRepo == nil {
+		return 0, ErrHistoryNotConfigured
+	}
+
+	window := time.Duration(windowMs) * time.Millisecond
+	cacheKey := s.derivativeCacheKey(plcID, tagID, windowMs)
+	redisClient := s.cache.GetRedisClient()
+
+	if redisClient != nil {
+		if cached, err := redisClient.Get(context.Background(), cacheKey).Result(); err == nil {
+			if rate, err := strconv.ParseFloat(cached, 64); err == nil {
+				return rate, nil
+			}
+		}
+	}
+
+	now := time.Now()
+	pairs, err := s.historyRepo.GetLatestPairs(plcID, []int{tagID}, now.Add(-window), now)
+	if err != nil {
+		return 0, fmt.Errorf("erro ao buscar histórico da tag %d: %w", tagID, err)
+	}
+
+	rate, err := derivativeFromEntries(pairs[tagID])
+	if err != nil {
+		return 0, err
+	}
+
+	if redisClient != nil && window/2 > 0 {
+		redisClient.Set(context.Background(), cacheKey, strconv.FormatFloat(rate, 'f', -1, 64), window/2)
+	}
+
+	return rate, nil
+}
+
+// tagDerivatives calcula a derivada de várias tags do mesmo PLC. Os valores em
+// cache são lidos com um único MGET e as tags restantes são resolvidas com uma
+// única consulta ao histórico. Tags sem derivada calculável ficam fora do mapa.
+func (s *PLCService) tagDerivatives(plcID int, tagIDs []int, windowMs int) map[int]float64 {
+	rates := make(map[int]float64, len(tagIDs))
+	if s.historyRepo == nil || len(tagIDs) == 0 {
+		return rates
+	}
+
+	window := time.Duration(windowMs) * time.Millisecond
+	ctx := context.Background()
+	redisClient := s.cache.GetRedisClient()
+
+	missing := tagIDs
+	if redisClient != nil {
+		keys := make([]string, len(tagIDs))
+		for i, tagID := range tagIDs {
+			keys[i] = s.derivativeCacheKey(plcID, tagID, windowMs)
+		}
+
+		if cached, err := redisClient.MGet(ctx, keys...).Result(); err == nil {
+			missing = make([]int, 0, len(tagIDs))
+			for i, raw := range cached {
+				str, ok := raw.(string)
+				if ok {
+					if rate, err := strconv.ParseFloat(str, 64); err == nil {
+						rates[tagIDs[i]] = rate
+						continue
+					}
+				}
+				missing = append(missing, tagIDs[i])
+			}
+		}
+	}
+
+	if len(missing) == 0 {
+		return rates
+	}
+
+	now := time.Now()
+	pairs, err := s.historyRepo.GetLatestPairs(plcID, missing, now.Add(-window), now)
+	if err != nil {
+		log.Printf("Aviso: erro ao buscar histórico das tags do PLC %d: %v", plcID, err)
+		return rates
+	}
+
+	computed := make(map[int]float64, len(missing))
+	for _, tagID := range missing {
+		if rate, err := derivativeFromEntries(pairs[tagID]); err == nil {
+			rates[tagID] = rate
+			computed[tagID] = rate
+		}
+	}
+
+	if redisClient != nil && window/2 > 0 && len(computed) > 0 {
+		pipe := redisClient.Pipeline()
+		for tagID, rate := range computed {
+			pipe.Set(ctx, s.derivativeCacheKey(plcID, tagID, windowMs), strconv.FormatFloat(rate, 'f', -1, 64), window/2)
+		}
+		if _, err := pipe.Exec(ctx); err != nil {
+			log.Printf("Aviso: erro ao gravar derivadas do PLC %d no cache: %v", plcID, err)
+		}
+	}
+
+	return rates
+}
+
+// derivativeCacheKey monta a chave Redis da derivada de uma tag
+func (s *PLCService) derivativeCacheKey(plcID, tagID, windowMs int) string {
+	return s.cfg().RedisKeyPrefix + fmt.Sprintf("plc:derivative:%d:%d:%d", plcID, tagID, windowMs)
+}
+
+// derivativeFromEntries calcula a taxa de variação entre os dois últimos
+// registros de uma lista em ordem cronológica
+func derivativeFromEntries(entries []domain.TagHistoryEntry) (float64, error) {
+	if len(entries) < 2 {
+		return 0, domain.ErrNotEnoughHistory
+	}
+
+	first := entries[len(entries)-2]
+	last := entries[len(entries)-1]
+
+	v1, ok1 := plc.ToFloat64(first.Value)
+	v2, ok2 := plc.ToFloat64(last.Value)
+	if !ok1 || !ok2 {
+		return 0, domain.ErrNonNumericValue
+	}
+
+	elapsed := last.RecordedAt.Sub(first.RecordedAt).Seconds()
+	if elapsed <= 0 {
+		return 0, domain.ErrNotEnoughHistory
+	}
+
+	return (v2 - v1) / elapsed, nil
+}
+
+// ExportTagHistory percorre o histórico dos PLCs no intervalo e entrega os
+// registros a fn em lotes, já com o nome da tag preenchido. Com tagID maior
+// que zero exporta apenas essa tag, que deve pertencer ao único PLC informado.
+func (s *PLCService) ExportTagHistory(plcIDs []int, tagID int, from, to time.Time, fn func([]domain.TagHistoryEntry) error) error {
+	if s.historyRepo == nil {
+		return ErrHistoryNotConfigured
+	}
+
+	if !from.Before(to) {
+		return ErrInvalidHistoryRange
+	}
+
+	tagNames := make(map[int]string)
+	for _, plcID := range plcIDs {
+		if _, err := s.GetByID(plcID); err != nil {
+			return err
+		}
+
+		tags, err := s.GetPLCTags(plcID)
+		if err != nil {
+			return fmt.Errorf("erro ao buscar tags do PLC %d: %w", plcID, err)
+		}
+		for _, tag := range tags {
+			tagNames[tag.ID] = tag.Name
+		}
+	}
+
+	if tagID > 0 {
+		if _, ok := tagNames[tagID]; !ok || len(plcIDs) != 1 {
+			return fmt.Errorf("tag %d não pertence ao PLC: %w", tagID, domain.ErrPLCTagNotFound)
+		}
+	}
+
+	return s.historyRepo.StreamRange(plcIDs, tagID, from, to, historyExportBatchSize, func(entries []domain.TagHistoryEntry) error {
+		for i := range entries {
+			entries[i].TagName = tagNames[entries[i].TagID]
+		}
+		return fn(entries)
+	})
+}
+
+// ExportTagHistoryCSV entrega a fn, em lotes de historyCSVBatchSize, o
+// histórico bruto da tag no intervalo. Retorna ErrHistoryExportTooLarge, sem
+// chamar fn, se o intervalo tiver mais de maxRows registros.
+func (s *PLCService) ExportTagHistoryCSV(tagID int, from, to time.Time, maxRows int, fn func([]domain.TagHistoryEntry) error) error {
+	if s.historyRepo == nil {
+		return ErrHistoryNotConfigured
+	}
+
+	if !from.Before(to) {
+		return ErrInvalidHistoryRange
+	}
+
+	tag, err := s.GetTagByID(tagID)
+	if err != nil {
+		return err
+	}
+
+	count, err := s.historyRepo.CountRange(tagID, from, to, maxRows+1)
+	if err != nil {
+		return fmt.Errorf("erro ao contar histórico da tag %d: %w", tagID, err)
+	}
+	if count > maxRows {
+		return fmt.Errorf("%w (%d)", ErrHistoryExportTooLarge, maxRows)
+	}
+
+	return s.historyRepo.StreamRange([]int{tag.PLCID}, tagID, from, to, historyCSVBatchSize, fn)
+}
+
+// GetTagHistory retorna o histórico de uma tag no intervalo. Com mode linear ou
+// stepwise, a série tem um ponto a cada window e as lacunas são preenchidas
+// com valores sintéticos marcados como interpolados.
+func (s *PLCService) GetTagHistory(ctx context.Context, plcID, tagID int, from, to time.Time, window time.Duration, mode string) ([]domain.TagValue, error) {
+	if s.historyRepo == nil {
+		return nil, ErrHistoryNotConfigured
+	}
+
+	if !from.Before(to) {
+		return nil, ErrInvalidHistoryRange
+	}
+
+	switch mode {
+	case domain.InterpolationNone:
+	case domain.InterpolationLinear, domain.InterpolationStepwise:
+		if window <= 0 {
+			return nil, ErrInvalidHistoryWindow
+		}
+		if to.Sub(from)/window > maxInterpolatedPoints {
+			return nil, fmt.Errorf("%w: máximo de %d", ErrTooManyHistoryPoints, maxInterpolatedPoints)
+		}
+	default:
+		return nil, ErrInvalidInterpolation
+	}
+
+	tag, err := s.GetTagByID(tagID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao verificar existência da tag: %w", err)
+	}
+
+	if tag.PLCID != plcID {
+		return nil, fmt.Errorf("tag %d não pertence ao PLC %d: %w", tagID, plcID, domain.ErrPLCTagNotFound)
+	}
+
+	if mode != domain.InterpolationNone && !domain.IsNumericDataType(tag.DataType) {
+		return nil, fmt.Errorf("%w: tag %s é do tipo %s", ErrInterpolationNotAllowed, tag.Name, tag.DataType)
+	}
+
+	values, err := s.historyRepo.GetInterpolated(ctx, plcID, tagID, from, to, window, mode)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar histórico da tag %d: %w", tagID, err)
+	}
+
+	return values, nil
+}
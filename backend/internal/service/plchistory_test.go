@@ -0,0 +1,119 @@
+package service
+
+import (
+	"app_padrao/internal/domain"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+)
+
+// fakeHistoryRepo implementa apenas o GetLatestPairs e conta as consultas
+type fakeHistoryRepo struct {
+	domain.PLCTagHistoryRepository
+	pairs   map[int][]domain.TagHistoryEntry
+	calls   int
+	queried [][]int
+}
+
+func (r *fakeHistoryRepo) GetLatestPairs(plcID int, tagIDs []int, from, to time.Time) (map[int][]domain.TagHistoryEntry, error) {
+	r.calls++
+	r.queried = append(r.queried, append([]int(nil), tagIDs...))
+
+	result := make(map[int][]domain.TagHistoryEntry)
+	for _, id := range tagIDs {
+		if entries, ok := r.pairs[id]; ok {
+			result[id] = entries
+		}
+	}
+	return result, nil
+}
+
+// fakeRedisCache expõe apenas o cliente Redis
+type fakeRedisCache struct {
+	domain.PLCCache
+	client *redis.Client
+}
+
+func (c *fakeRedisCache) GetRedisClient() *redis.Client {
+	return c.client
+}
+
+func TestTagDerivativesUsesSingleQueryAndCache(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	now := time.Now()
+	repo := &fakeHistoryRepo{pairs: map[int][]domain.TagHistoryEntry{
+		1: {
+			{TagID: 1, Value: 10.0, RecordedAt: now.Add(-2 * time.Second)},
+			{TagID: 1, Value: 14.0, RecordedAt: now},
+		},
+		2: {
+			{TagID: 2, Value: 5.0, RecordedAt: now},
+		},
+		3: {
+			{TagID: 3, Value: "abc", RecordedAt: now.Add(-time.Second)},
+			{TagID: 3, Value: "def", RecordedAt: now},
+		},
+	}}
+	s := &PLCService{
+		historyRepo: repo,
+		cache:       &fakeRedisCache{client: client},
+		config:      PLCConfig{RedisKeyPrefix: "app1:"},
+	}
+
+	rates := s.tagDerivatives(9, []int{1, 2, 3}, 60000)
+	if repo.calls != 1 {
+		t.Fatalf("consultas = %d, esperado 1", repo.calls)
+	}
+	if len(rates) != 1 || rates[1] != 2 {
+		t.Fatalf("derivadas = %v, esperado apenas a tag 1 com 2", rates)
+	}
+	if got, err := mr.Get("app1:plc:derivative:9:1:60000"); err != nil || got != "2" {
+		t.Errorf("cache da tag 1 = %q (%v), esperado 2", got, err)
+	}
+
+	// Na segunda chamada a tag 1 vem do cache e só as demais são consultadas
+	rates = s.tagDerivatives(9, []int{1, 2, 3}, 60000)
+	if repo.calls != 2 {
+		t.Fatalf("consultas = %d, esperado 2", repo.calls)
+	}
+	if got := repo.queried[1]; len(got) != 2 || got[0] != 2 || got[1] != 3 {
+		t.Errorf("tags consultadas = %v, esperado [2 3]", got)
+	}
+	if rates[1] != 2 {
+		t.Errorf("derivada da tag 1 = %v, esperado 2", rates[1])
+	}
+}
+
+func TestDerivativeFromEntries(t *testing.T) {
+	now := time.Now()
+
+	if _, err := derivativeFromEntries(nil); err != domain.ErrNotEnoughHistory {
+		t.Errorf("erro = %v, esperado ErrNotEnoughHistory", err)
+	}
+
+	same := []domain.TagHistoryEntry{
+		{Value: 1.0, RecordedAt: now},
+		{Value: 2.0, RecordedAt: now},
+	}
+	if _, err := derivativeFromEntries(same); err != domain.ErrNotEnoughHistory {
+		t.Errorf("erro = %v, esperado ErrNotEnoughHistory para leituras no mesmo instante", err)
+	}
+
+	entries := []domain.TagHistoryEntry{
+		{Value: 100.0, RecordedAt: now.Add(-10 * time.Second)},
+		{Value: 20.0, RecordedAt: now.Add(-4 * time.Second)},
+		{Value: 8.0, RecordedAt: now},
+	}
+	rate, err := derivativeFromEntries(entries)
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if rate != -3 {
+		t.Errorf("derivada = %v, esperado -3", rate)
+	}
+}
@@ -0,0 +1,200 @@
+// internal/service/plchistoryqueue.go
+package service
+
+import (
+	"app_padrao/internal/domain"
+	"app_padrao/internal/metrics"
+	"context"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultHistoryQueueSize = 50000
+	defaultHistoryWorkers   = 4
+	historyInsertBatchSize  = 500
+	historyFlushInterval    = time.Second
+	historyDropRateInterval = 5 * time.Second
+)
+
+// historyQueue é a fila em memória entre os monitores de tags e os workers
+// que gravam o histórico. Quando está cheia, novos registros são descartados
+// para não bloquear o ciclo de leitura.
+type historyQueue struct {
+	entries chan domain.TagHistoryEntry
+	dropped int64 // acesso atômico
+
+	rateMu       sync.Mutex
+	dropRate     float64
+	lastDropped  int64
+	lastSampleAt time.Time
+}
+
+// newHistoryQueue cria uma fila com a capacidade informada
+func newHistoryQueue(capacity int) *historyQueue {
+	if capacity <= 0 {
+		capacity = defaultHistoryQueueSize
+	}
+	return &historyQueue{
+		entries:      make(chan domain.TagHistoryEntry, capacity),
+		lastSampleAt: time.Now(),
+	}
+}
+
+// offer tenta enfileirar o registro sem bloquear. Retorna false se a fila
+// estiver cheia e o registro foi descartado.
+func (q *historyQueue) offer(entry domain.TagHistoryEntry) bool {
+	select {
+	case q.entries <- entry:
+		return true
+	default:
+		atomic.AddInt64(&q.dropped, 1)
+		return false
+	}
+}
+
+// sampleDropRate atualiza a taxa de descarte com base no intervalo desde a
+// última amostra
+func (q *historyQueue) sampleDropRate() {
+	q.rateMu.Lock()
+	defer q.rateMu.Unlock()
+
+	now := time.Now()
+	dropped := atomic.LoadInt64(&q.dropped)
+	elapsed := now.Sub(q.lastSampleAt).Seconds()
+	if elapsed > 0 {
+		q.dropRate = float64(dropped-q.lastDropped) / elapsed
+	}
+	q.lastDropped = dropped
+	q.lastSampleAt = now
+}
+
+// stats retorna o estado atual da fila
+func (q *historyQueue) stats() domain.HistoryQueueStats {
+	q.rateMu.Lock()
+	dropRate := q.dropRate
+	q.rateMu.Unlock()
+
+	return domain.HistoryQueueStats{
+		Capacity:       cap(q.entries),
+		Length:         len(q.entries),
+		DropRatePerSec: dropRate,
+		DroppedTotal:   atomic.LoadInt64(&q.dropped),
+	}
+}
+
+// SetMetricsCollector define o coletor que recebe as métricas do gerenciador
+func (m *PLCManager) SetMetricsCollector(collector *metrics.MetricsCollector) {
+	m.metrics = collector
+}
+
+// enqueueHistory coloca as leituras bem-sucedidas na fila de histórico
+func (m *PLCManager) enqueueHistory(values []domain.TagValue) {
+	if m.historyRepo == nil || m.historyQueue == nil {
+		return
+	}
+
+	for _, v := range values {
+		if v.Quality != domain.QualityGood {
+			continue
+		}
+
+		ok := m.historyQueue.offer(domain.TagHistoryEntry{
+			PLCID:      v.PLCID,
+			TagID:      v.TagID,
+			Value:      v.Value,
+			RecordedAt: v.Timestamp,
+		})
+		if !ok && m.metrics != nil {
+			m.metrics.IncrementCounter("tag_history.drop_total", 1)
+		}
+	}
+}
+
+// startHistoryWorkers inicia os workers que gravam o histórico e a rotina
+// que calcula a taxa de descarte
+func (m *PLCManager) startHistoryWorkers(ctx context.Context) {
+	if m.historyQueue == nil {
+		return
+	}
+
+	workers := m.config.HistoryWorkers
+	if workers <= 0 {
+		workers = defaultHistoryWorkers
+	}
+
+	for i := 0; i < workers; i++ {
+		m.goTracked(func() {
+			m.runHistoryWorker(ctx)
+		})
+	}
+
+	m.goTracked(func() {
+		ticker := time.NewTicker(historyDropRateInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				m.historyQueue.sampleDropRate()
+			}
+		}
+	})
+}
+
+// runHistoryWorker consome a fila gravando lotes de até historyInsertBatchSize
+// registros. Lotes incompletos são gravados a cada historyFlushInterval.
+func (m *PLCManager) runHistoryWorker(ctx context.Context) {
+	ticker := time.NewTicker(historyFlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]domain.TagHistoryEntry, 0, historyInsertBatchSize)
+
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := m.historyRepo.Insert(batch); err != nil {
+			log.Printf("Erro ao gravar lote de %d registros de histórico: %v", len(batch), err)
+		}
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			// Gravar o que já foi retirado da fila e o que ainda estiver nela
+			for {
+				select {
+				case entry := <-m.historyQueue.entries:
+					batch = append(batch, entry)
+					if len(batch) == historyInsertBatchSize {
+						flush()
+					}
+				default:
+					flush()
+					return
+				}
+			}
+		case entry := <-m.historyQueue.entries:
+			batch = append(batch, entry)
+			if len(batch) == historyInsertBatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+// GetHistoryQueueStats retorna o estado da fila de histórico
+func (m *PLCManager) GetHistoryQueueStats() domain.HistoryQueueStats {
+	if m.historyQueue == nil {
+		return domain.HistoryQueueStats{}
+	}
+	return m.historyQueue.stats()
+}
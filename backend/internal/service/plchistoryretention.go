@@ -0,0 +1,105 @@
+// internal/service/plchistoryretention.go
+package service
+
+import (
+	"app_padrao/internal/domain"
+	"context"
+	"errors"
+	"log"
+	"time"
+)
+
+// historyPruneInterval é o intervalo entre limpezas do histórico
+const historyPruneInterval = 24 * time.Hour
+
+// defaultHistoryRetentionDays é a retenção padrão do histórico
+const defaultHistoryRetentionDays = 90
+
+// startHistoryPruning limpa o histórico ao iniciar e depois diariamente, até
+// StopMonitoring. Tags com RetentionDays próprio são limpas mesmo com a
+// retenção global ilimitada.
+func (s *PLCService) startHistoryPruning() {
+	if s.historyRepo == nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.pruneCancel = cancel
+
+	go func() {
+		ticker := time.NewTicker(historyPruneInterval)
+		defer ticker.Stop()
+
+		for {
+			s.pruneHistory()
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// stopHistoryPruning interrompe a limpeza diária, se ativa
+func (s *PLCService) stopHistoryPruning() {
+	if s.pruneCancel != nil {
+		s.pruneCancel()
+		s.pruneCancel = nil
+	}
+
+	s.pruneMu.Lock()
+	s.nextPrune = time.Time{}
+	s.pruneMu.Unlock()
+}
+
+// pruneHistory remove as leituras fora do prazo de retenção
+func (s *PLCService) pruneHistory() {
+	var olderThan time.Time
+	if days := s.cfg().HistoryRetentionDays; days > 0 {
+		olderThan = time.Now().AddDate(0, 0, -days)
+	}
+
+	start := time.Now()
+	pruned, err := s.historyRepo.Prune(olderThan)
+
+	s.pruneMu.Lock()
+	s.nextPrune = start.Add(historyPruneInterval)
+	s.pruneMu.Unlock()
+
+	if errors.Is(err, domain.ErrHistoryPruneLocked) {
+		log.Printf("Limpeza do histórico de tags pulada: %v", err)
+		return
+	}
+	if err != nil {
+		log.Printf("Erro ao limpar histórico de tags: %v", err)
+		return
+	}
+
+	log.Printf(`Limpeza do histórico de tags: {"pruned_rows":%d,"duration_ms":%d}`,
+		pruned, time.Since(start).Milliseconds())
+}
+
+// GetHistoryStats retorna o tamanho do histórico e a política de retenção
+func (s *PLCService) GetHistoryStats() (domain.TagHistoryStats, error) {
+	if s.historyRepo == nil {
+		return domain.TagHistoryStats{}, ErrHistoryNotConfigured
+	}
+
+	stats, err := s.historyRepo.GetStats()
+	if err != nil {
+		return domain.TagHistoryStats{}, err
+	}
+
+	stats.RetentionDays = s.cfg().HistoryRetentionDays
+
+	s.pruneMu.Lock()
+	if !s.nextPrune.IsZero() {
+		next := s.nextPrune
+		stats.NextPrune = &next
+	}
+	s.pruneMu.Unlock()
+
+	return stats, nil
+}
@@ -3,24 +3,33 @@ package service
 
 import (
 	"app_padrao/internal/domain"
+	"app_padrao/internal/metrics"
+	"app_padrao/pkg/events"
 	"app_padrao/pkg/plc"
 	"context"
 	"errors"
 	"fmt"
 	"log"
+	"math"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"golang.org/x/time/rate"
 )
 
 // Erros específicos
 var (
 	ErrPLCNotConnected   = errors.New("PLC não está conectado")
-	ErrPLCNotFound       = errors.New("PLC não encontrado")
 	ErrTagNotFound       = errors.New("tag não encontrada")
 	ErrWriteNotPermitted = errors.New("escrita não permitida nesta tag")
+	ErrWriteRateLimited  = errors.New("limite de taxa de escrita da tag excedido")
 )
 
+// writeRateLimitWait é o tempo máximo de espera por uma vaga de escrita
+const writeRateLimitWait = time.Second
+
 // PLCManager encapsula a lógica de gerenciamento dos PLCs
 type PLCManager struct {
 	// Repositórios Redis para acesso rápido
@@ -28,6 +37,21 @@ type PLCManager struct {
 	tagRepo domain.PLCTagRepository
 	cache   domain.PLCCache
 
+	// Histórico de valores (opcional) e fila de gravação
+	historyRepo  domain.PLCTagHistoryRepository
+	historyQueue *historyQueue
+
+	// Métricas (opcional)
+	metrics *metrics.MetricsCollector
+
+	// Barramento de eventos: o monitoramento publica as leituras e o status
+	// dos PLCs; cache e histórico são consumidores independentes
+	events *events.Bus
+
+	// Último status publicado de cada PLC
+	plcStatus   map[int]string
+	statusMutex sync.Mutex
+
 	// Controle de execução
 	ctx    context.Context
 	cancel context.CancelFunc
@@ -43,14 +67,71 @@ type PLCManager struct {
 	statsMutex    sync.RWMutex
 
 	// Controle de monitoramento de tags
-	tagMonitors     map[int]context.CancelFunc
+	tagMonitors     map[tagMonitorKey]*tagMonitor
 	tagMonitorMutex sync.RWMutex
 
-	// Configuração de logging
-	enableDetailedLogging bool
-
-	// Valores de configuração
-	config ManagerConfig
+	// Latência e volume de leitura por PLC
+	readPerf   map[int]*readPerformance
+	readPerfMu sync.Mutex
+
+	// Namespace das chaves Redis da instância (REDIS_KEY_PREFIX)
+	keyPrefix string
+
+	// Repositório persistente onde a última escrita de cada tag é registrada
+	lastWriteRepo domain.PLCTagRepository
+
+	// Chamado quando uma tag é desativada por falhas consecutivas de leitura,
+	// para persistir a desativação (nil = apenas remove a tag da varredura)
+	onTagAutoDisabled func(tag domain.PLCTag, readErr error)
+
+	// Chamado quando o status de um PLC muda (nil = apenas publica o evento)
+	onStatusChange func(plcID int)
+
+	// Intertravamentos do serviço, verificados pelo worker da fila antes de
+	// cada tentativa de escrita (nil = sem verificação)
+	validateWrite func(tagName string, value interface{}, userID int) error
+
+	// Fechado pelo serviço de sincronização após a importação inicial para o
+	// Redis; nil quando não há sincronização
+	syncReady <-chan struct{}
+
+	// PLCs com goroutine de monitoramento em execução (valor = geração da
+	// execução) e pedidos de parada imediata
+	monitoredPLCs   map[int]uint64
+	monitorGen      uint64
+	monitoredMutex  sync.RWMutex
+	stopMonitorChan chan int
+
+	// Limitadores de escrita por tag (chave: tagID)
+	writeLimiters     map[int]*rate.Limiter
+	writeLimiterMutex sync.Mutex
+
+	// Goroutines do gerenciador em execução (acesso atômico)
+	goroutineTracker int64
+
+	// Vagas para conexões simultâneas com PLCs (nil = sem limite), criadas no
+	// primeiro Start, e tentativas de conexão em andamento (acesso atômico)
+	connectSlots     chan struct{}
+	connectSlotsOnce sync.Once
+	connectAttempts  int64
+
+	// Leituras e escritas em andamento, aguardadas por Stop antes de cancelar
+	// o contexto. drainMu protege o contador e impede novas operações durante
+	// a drenagem; drained é fechado quando o contador chega a zero. Um
+	// contador, e não um WaitGroup, porque um Start após uma drenagem
+	// expirada pode registrar operações enquanto as antigas ainda terminam.
+	drainMu     sync.Mutex
+	inFlightOps int64
+	draining    bool
+	drained     chan struct{}
+
+	// Configuração de logging, alterável em execução enquanto os monitores leem
+	enableDetailedLogging atomic.Bool
+
+	// Valores de configuração. configMu protege os campos alteráveis em
+	// execução por SetRuntimeConfig.
+	config   ManagerConfig
+	configMu sync.RWMutex
 }
 
 // ManagerConfig contém configurações para o PLCManager
@@ -60,6 +141,22 @@ type ManagerConfig struct {
 	RetryInterval      time.Duration
 	ConnectionTimeout  time.Duration
 	DetailedLogging    bool
+	HistoryQueueSize   int // Capacidade da fila de histórico
+	HistoryWorkers     int // Workers que gravam o histórico
+	// Tempo máximo que Stop aguarda leituras/escritas em andamento
+	ShutdownDrainTimeout time.Duration
+	// Espera máxima pela sincronização inicial antes de consultar os PLCs
+	SyncWaitTimeout time.Duration
+	// Falhas de leitura consecutivas que desativam a tag (0 = nunca desativa)
+	ConsecutiveErrorThreshold int
+	// Scan rate (ms) das tags sem scan rate próprio
+	DefaultTagScanRate int
+	// Intervalo entre pings da conexão ativa (0 = sem verificação)
+	PingInterval time.Duration
+	// Atraso entre o início de cada PLC na primeira conexão
+	StartupStagger time.Duration
+	// PLCs tentando conectar ao mesmo tempo (0 = sem limite)
+	MaxConcurrentConnections int
 }
 
 // PLCManagerStats contém estatísticas do gerenciador de PLCs
@@ -77,14 +174,61 @@ type PLCManagerStats struct {
 // PLCConnectionStats contém estatísticas de uma conexão com PLC
 type PLCConnectionStats struct {
 	PLCID         int
+	SiteID        *int
 	Name          string
 	Status        string
 	TagCount      int
 	LastConnected time.Time
 	ReadErrors    int64
 	WriteErrors   int64
+	RetryCount    int64
+	NextRetryAt   time.Time
+
+	// Reconexões disparadas por falha de ping
+	ReconnectAttempts int64
+	LastReconnectAt   time.Time
+
+	// Disparos do scan descartados porque o PLC não acompanhou o scan rate
+	OverrunCount  int64
+	LastOverrunAt time.Time
+
+	// Duração do último ping da verificação de saúde (o prazo, se expirou)
+	Timeout time.Duration
+
+	// Desempenho de leitura (buffer das últimas 1000 leituras)
+	ReadLatencyP50Ms float64
+	ReadLatencyP95Ms float64
+	ReadLatencyP99Ms float64
+	ReadsPerSecond   float64
+	BytesReadTotal   int64
 }
 
+// tagMonitorKey identifica um monitor de tags (um por PLC e taxa de scan)
+type tagMonitorKey struct {
+	plcID int
+	rate  int
+}
+
+// tagListUpdate carrega a nova lista de tags de um monitor
+type tagListUpdate struct {
+	tags []domain.PLCTag
+}
+
+// tagMonitor controla uma goroutine de monitoramento de tags em execução
+type tagMonitor struct {
+	cancel  context.CancelFunc
+	updates chan tagListUpdate
+}
+
+// defaultShutdownDrainTimeout é a espera padrão por operações em andamento no Stop
+const defaultShutdownDrainTimeout = 5 * time.Second
+
+// defaultSyncWaitTimeout é a espera padrão pela sincronização inicial
+const defaultSyncWaitTimeout = 60 * time.Second
+
+// maxReconnectBackoff limita o intervalo entre tentativas de reconexão
+const maxReconnectBackoff = 10 * time.Minute
+
 // NewPLCManager cria um novo gerenciador de PLCs
 func NewPLCManager(
 	plcRepo domain.PLCRepository,
@@ -98,22 +242,38 @@ func NewPLCManager(
 		RetryInterval:      10 * time.Second,
 		ConnectionTimeout:  5 * time.Second,
 		DetailedLogging:    true,
+		HistoryQueueSize:   defaultHistoryQueueSize,
+		HistoryWorkers:     defaultHistoryWorkers,
+
+		ShutdownDrainTimeout:      defaultShutdownDrainTimeout,
+		SyncWaitTimeout:           defaultSyncWaitTimeout,
+		ConsecutiveErrorThreshold: defaultConsecutiveErrorThreshold,
+		PingInterval:              defaultPingInterval,
+		StartupStagger:            defaultStartupStagger,
+		MaxConcurrentConnections:  defaultMaxConcurrentConnections,
 	}
 
-	return &PLCManager{
+	m := &PLCManager{
 		plcRepo:           plcRepo,
 		tagRepo:           tagRepo,
 		cache:             cache,
 		activeConnections: make(map[int]*PLCConnection),
-		tagMonitors:       make(map[int]context.CancelFunc),
+		tagMonitors:       make(map[tagMonitorKey]*tagMonitor),
+		writeLimiters:     make(map[int]*rate.Limiter),
+		readPerf:          make(map[int]*readPerformance),
+		monitoredPLCs:     make(map[int]uint64),
+		events:            events.NewBus(0),
+		plcStatus:         make(map[int]string),
+		stopMonitorChan:   make(chan int, 16),
 		statsInterval:     config.StatsInterval,
 		stats: PLCManagerStats{
 			ConnectionStats: make(map[int]PLCConnectionStats),
 			LastUpdated:     time.Now(),
 		},
-		enableDetailedLogging: config.DetailedLogging,
-		config:                config,
+		config: config,
 	}
+	m.enableDetailedLogging.Store(config.DetailedLogging)
+	return m
 }
 
 // Start inicia o monitoramento dos PLCs
@@ -122,38 +282,143 @@ func (m *PLCManager) Start() error {
 	m.ctx = ctx
 	m.cancel = cancel
 
+	m.resumeInFlight()
+
+	m.initConnectSlots()
+
 	// Iniciar rotina de estatísticas
-	m.wg.Add(1)
-	go func() {
-		defer m.wg.Done()
+	m.goTracked(func() {
 		m.runStatsCollector(ctx)
-	}()
+	})
+
+	// Iniciar gravação assíncrona do histórico
+	if m.historyRepo != nil {
+		m.historyQueue = newHistoryQueue(m.config.HistoryQueueSize)
+		m.startHistoryWorkers(ctx)
+	}
+
+	// Consumidores das leituras publicadas no barramento
+	m.startEventConsumers(ctx)
 
 	// Iniciar monitoramento de PLCs
+	m.goTracked(func() {
+		m.runAllPLCs(ctx)
+	})
+
+	log.Println("Gerenciador de PLCs iniciado")
+	return nil
+}
+
+// goTracked executa fn em uma goroutine registrada no WaitGroup e no
+// contador de goroutines, para que Stop aguarde seu término
+func (m *PLCManager) goTracked(fn func()) {
 	m.wg.Add(1)
+	atomic.AddInt64(&m.goroutineTracker, 1)
+
 	go func() {
 		defer m.wg.Done()
-		m.runAllPLCs(ctx)
+		defer atomic.AddInt64(&m.goroutineTracker, -1)
+		fn()
 	}()
+}
 
-	log.Println("Gerenciador de PLCs iniciado")
-	return nil
+// TrackedGoroutines retorna quantas goroutines do gerenciador estão em execução
+func (m *PLCManager) TrackedGoroutines() int64 {
+	return atomic.LoadInt64(&m.goroutineTracker)
+}
+
+// beginInFlight registra uma leitura/escrita em andamento. Retorna false se o
+// gerenciador está sendo encerrado e a operação não deve começar.
+func (m *PLCManager) beginInFlight() bool {
+	m.drainMu.Lock()
+	defer m.drainMu.Unlock()
+
+	if m.draining {
+		return false
+	}
+	m.inFlightOps++
+	return true
+}
+
+// endInFlight encerra uma operação registrada por beginInFlight
+func (m *PLCManager) endInFlight() {
+	m.drainMu.Lock()
+	defer m.drainMu.Unlock()
+
+	m.inFlightOps--
+	if m.inFlightOps == 0 && m.drained != nil {
+		close(m.drained)
+		m.drained = nil
+	}
+}
+
+// resumeInFlight volta a aceitar operações após uma drenagem. Operações de
+// uma drenagem que expirou continuam contadas até terminarem.
+func (m *PLCManager) resumeInFlight() {
+	m.drainMu.Lock()
+	m.draining = false
+	m.drainMu.Unlock()
+}
+
+// pendingInFlight retorna quantas operações ainda estão em andamento
+func (m *PLCManager) pendingInFlight() int64 {
+	m.drainMu.Lock()
+	defer m.drainMu.Unlock()
+	return m.inFlightOps
+}
+
+// drainInFlight bloqueia novas operações e aguarda as em andamento por até
+// timeout. Retorna false se o tempo esgotou antes de todas terminarem.
+func (m *PLCManager) drainInFlight(timeout time.Duration) bool {
+	m.drainMu.Lock()
+	m.draining = true
+	if m.inFlightOps == 0 {
+		m.drainMu.Unlock()
+		return true
+	}
+	// Reaproveitar o canal de uma drenagem anterior que expirou
+	if m.drained == nil {
+		m.drained = make(chan struct{})
+	}
+	drained := m.drained
+	m.drainMu.Unlock()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-drained:
+		return true
+	case <-timer.C:
+		return false
+	}
 }
 
 // Stop para o monitoramento dos PLCs
 func (m *PLCManager) Stop() {
+	// Aguardar leituras/escritas em andamento para não interromper uma
+	// transferência no meio e gravar dados parciais no cache
+	drainTimeout := m.config.ShutdownDrainTimeout
+	if drainTimeout <= 0 {
+		drainTimeout = defaultShutdownDrainTimeout
+	}
+	if !m.drainInFlight(drainTimeout) {
+		log.Printf("Aviso: %d operações de PLC ainda em andamento após %v; encerrando mesmo assim",
+			m.pendingInFlight(), drainTimeout)
+	}
+
 	if m.cancel != nil {
 		m.cancel()
 	}
 
 	// Parar todos os monitores de tags
 	m.tagMonitorMutex.Lock()
-	for _, cancel := range m.tagMonitors {
-		if cancel != nil {
-			cancel()
+	for _, monitor := range m.tagMonitors {
+		if monitor.cancel != nil {
+			monitor.cancel()
 		}
 	}
-	m.tagMonitors = make(map[int]context.CancelFunc)
+	m.tagMonitors = make(map[tagMonitorKey]*tagMonitor)
 	m.tagMonitorMutex.Unlock()
 
 	// Aguardar goroutines encerrarem
@@ -173,10 +438,33 @@ func (m *PLCManager) Stop() {
 
 // SetDetailedLogging ativa ou desativa o logging detalhado
 func (m *PLCManager) SetDetailedLogging(enabled bool) {
-	m.enableDetailedLogging = enabled
+	m.enableDetailedLogging.Store(enabled)
 	log.Printf("Logging detalhado %s", map[bool]string{true: "ativado", false: "desativado"}[enabled])
 }
 
+// SetRuntimeConfig atualiza os valores de configuração alteráveis com o
+// gerenciador em execução. Os monitores passam a usá-los na próxima
+// tentativa de reconexão ou atualização da lista de tags.
+func (m *PLCManager) SetRuntimeConfig(retryInterval time.Duration, defaultTagScanRate, consecutiveErrorThreshold int) {
+	m.configMu.Lock()
+	m.config.RetryInterval = retryInterval
+	m.config.DefaultTagScanRate = defaultTagScanRate
+	m.config.ConsecutiveErrorThreshold = consecutiveErrorThreshold
+	m.configMu.Unlock()
+}
+
+// runtimeConfig retorna uma cópia da configuração atual
+func (m *PLCManager) runtimeConfig() ManagerConfig {
+	m.configMu.RLock()
+	defer m.configMu.RUnlock()
+	return m.config
+}
+
+// SetHistoryRepository define o repositório onde os valores lidos são registrados
+func (m *PLCManager) SetHistoryRepository(repo domain.PLCTagHistoryRepository) {
+	m.historyRepo = repo
+}
+
 // GetStats retorna as estatísticas atuais
 func (m *PLCManager) GetStats() PLCManagerStats {
 	m.statsMutex.RLock()
@@ -250,20 +538,24 @@ func (m *PLCManager) updateStats() {
 		}
 
 		// Atualizar ou criar estatísticas para este PLC
-		if stats, exists := m.stats.ConnectionStats[plc.ID]; exists {
+		stats, exists := m.stats.ConnectionStats[plc.ID]
+		if exists {
 			stats.Name = plc.Name
+			stats.SiteID = plc.SiteID
 			stats.Status = status
 			stats.TagCount = tagCount
-			m.stats.ConnectionStats[plc.ID] = stats
 		} else {
-			m.stats.ConnectionStats[plc.ID] = PLCConnectionStats{
+			stats = PLCConnectionStats{
 				PLCID:         plc.ID,
+				SiteID:        plc.SiteID,
 				Name:          plc.Name,
 				Status:        status,
 				TagCount:      tagCount,
 				LastConnected: time.Now(),
 			}
 		}
+		m.applyReadPerformance(&stats)
+		m.stats.ConnectionStats[plc.ID] = stats
 	}
 
 	// Remover PLCs que não estão mais ativos
@@ -279,6 +571,18 @@ func (m *PLCManager) updateStats() {
 	}
 }
 
+// GetAllConnections retorna uma cópia do mapa de conexões ativas (chave: ID do PLC)
+func (m *PLCManager) GetAllConnections() map[int]*PLCConnection {
+	m.connectionsMutex.RLock()
+	defer m.connectionsMutex.RUnlock()
+
+	connections := make(map[int]*PLCConnection, len(m.activeConnections))
+	for id, conn := range m.activeConnections {
+		connections[id] = conn
+	}
+	return connections
+}
+
 // PLCConnection é a implementação da conexão com o PLC
 type PLCConnection struct {
 	plcID    int
@@ -289,6 +593,13 @@ type PLCConnection struct {
 	active   bool
 	mutex    sync.Mutex
 	lastErr  error
+
+	// TSAP remoto da conexão (0 = padrão PG calculado de rack/slot)
+	remoteTSAP uint16
+
+	// Ciclos de leitura e escritas (leitura) e reconexão após falha de ping
+	// (escrita): varredura e escritas pausam enquanto o PLC reconecta
+	scanMu sync.RWMutex
 }
 
 // NewPLCConnection cria uma nova conexão com um PLC
@@ -316,11 +627,16 @@ func (p *PLCConnection) Connect() error {
 	log.Printf("Conectando ao PLC %d: %s (Rack: %d, Slot: %d)", p.plcID, p.ip, p.rack, p.slot)
 
 	// Criar uma conexão real com o PLC usando o cliente S7
-	client, err := plc.NewClient(p.ip, p.rack, p.slot)
+	client, err := plc.NewClientWithConfig(plc.ClientConfig{
+		IPAddress:  p.ip,
+		Rack:       p.rack,
+		Slot:       p.slot,
+		RemoteTSAP: p.remoteTSAP,
+	})
 	if err != nil {
 		p.lastErr = err
 		p.active = false
-		return fmt.Errorf("falha ao conectar ao PLC: %v", err)
+		return fmt.Errorf("falha ao conectar ao PLC: %w", err)
 	}
 
 	p.s7Client = client
@@ -331,15 +647,27 @@ func (p *PLCConnection) Connect() error {
 
 // Ping verifica se o PLC está online
 func (p *PLCConnection) Ping() error {
+	ctx, cancel := context.WithTimeout(context.Background(), pingTimeout)
+	defer cancel()
+	return p.PingContext(ctx)
+}
+
+// PingContext verifica a conexão com o PLC respeitando o prazo de ctx
+func (p *PLCConnection) PingContext(ctx context.Context) error {
 	p.mutex.Lock()
 	defer p.mutex.Unlock()
 
+	// O prazo pode ter acabado enquanto uma leitura ocupava a conexão
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
 	if p.s7Client == nil {
 		return fmt.Errorf("conexão com PLC não inicializada")
 	}
 
 	// Usar o método Ping real do cliente S7
-	return p.s7Client.Ping()
+	return p.s7Client.PingContext(ctx)
 }
 
 // Close fecha a conexão com o PLC
@@ -375,6 +703,18 @@ func (p *PLCConnection) ReadTag(dbNumber int, byteOffset int, dataType string, b
 	return p.s7Client.ReadTag(dbNumber, byteOffset, dataType, bitOffset)
 }
 
+// BatchReadDB lê uma faixa contínua de um DB do PLC
+func (p *PLCConnection) BatchReadDB(dbNumber, startOffset, size int) ([]byte, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if !p.active || p.s7Client == nil {
+		return nil, ErrPLCNotConnected
+	}
+
+	return p.s7Client.BatchReadDB(dbNumber, startOffset, size)
+}
+
 // WriteTag escreve uma tag no PLC
 func (p *PLCConnection) WriteTag(dbNumber int, byteOffset int, dataType string, bitOffset int, value interface{}) error {
 	p.mutex.Lock()
@@ -395,6 +735,11 @@ func (m *PLCManager) runAllPLCs(ctx context.Context) {
 		return
 	}
 
+	// Só consultar os PLCs depois que as tags estiverem no Redis
+	if !m.waitSyncReady(ctx) {
+		return
+	}
+
 	// Mapa para controlar PLCs atualmente monitorados
 	plcCancels := make(map[int]context.CancelFunc)
 
@@ -404,26 +749,65 @@ func (m *PLCManager) runAllPLCs(ctx context.Context) {
 
 	log.Println("Iniciando monitoramento de PLCs...")
 
+	// stopPLC cancela o monitoramento de um PLC e fecha sua conexão
+	stopPLC := func(plcID int) {
+		cancel, exists := plcCancels[plcID]
+		if !exists {
+			return
+		}
+		cancel()
+		delete(plcCancels, plcID)
+		m.clearMonitored(plcID)
+
+		// Remover da lista de conexões ativas
+		m.connectionsMutex.Lock()
+		if conn, exists := m.activeConnections[plcID]; exists {
+			conn.Close()
+			delete(m.activeConnections, plcID)
+		}
+		m.connectionsMutex.Unlock()
+
+		log.Printf("PLC ID %d removido do monitoramento", plcID)
+	}
+
 	for {
 		select {
 		case <-ctx.Done():
 			// Encerrar todos os monitoramentos
-			for _, cancel := range plcCancels {
+			for plcID, cancel := range plcCancels {
 				cancel()
+				m.clearMonitored(plcID)
 			}
 			log.Println("Monitoramento de PLCs encerrado")
 			return
 
+		case plcID := <-m.stopMonitorChan:
+			stopPLC(plcID)
+
 		case <-ticker.C:
 			// Buscar PLCs ativos do Redis
-			plcs, err := m.plcRepo.GetActivePLCs()
+			activePLCs, err := m.plcRepo.GetActivePLCs()
 			if err != nil {
 				log.Printf("Erro ao carregar PLCs: %v", err)
 				continue
 			}
 
-			// Remover PLCs inativos
-			for plcID, cancel := range plcCancels {
+			// PLCs com monitoramento pausado ficam fora, mesmo ativos
+			plcs := make([]domain.PLC, 0, len(activePLCs))
+			for _, plc := range activePLCs {
+				if plc.MonitoringEnabled {
+					plcs = append(plcs, plc)
+				}
+			}
+
+			// Remover PLCs inativos ou pausados e os cujo monitoramento
+			// terminou sozinho, que são reiniciados logo abaixo
+			for plcID := range plcCancels {
+				if !m.IsMonitoring(plcID) {
+					stopPLC(plcID)
+					continue
+				}
+
 				found := false
 				for _, plc := range plcs {
 					if plc.ID == plcID {
@@ -433,23 +817,12 @@ func (m *PLCManager) runAllPLCs(ctx context.Context) {
 				}
 
 				if !found {
-					cancel()
-					delete(plcCancels, plcID)
-
-					// Remover da lista de conexões ativas
-					m.connectionsMutex.Lock()
-					if conn, exists := m.activeConnections[plcID]; exists {
-						conn.Close()
-						delete(m.activeConnections, plcID)
-					}
-					m.connectionsMutex.Unlock()
-
-					log.Printf("PLC ID %d removido do monitoramento", plcID)
+					stopPLC(plcID)
 				}
 			}
 
 			// Adicionar ou atualizar PLCs
-			for _, plcConfig := range plcs {
+			for plcIndex, plcConfig := range plcs {
 				if plcConfig.IPAddress == "" {
 					log.Printf("PLC ID %d tem endereço IP vazio", plcConfig.ID)
 					continue
@@ -460,13 +833,16 @@ func (m *PLCManager) runAllPLCs(ctx context.Context) {
 					// Iniciar novo monitoramento
 					plcCtx, cancel := context.WithCancel(ctx)
 					plcCancels[plcConfig.ID] = cancel
-
-					// Iniciar goroutine para este PLC
-					m.wg.Add(1)
-					go func(ctx context.Context, config domain.PLC) {
-						defer m.wg.Done()
-						m.monitorPLC(ctx, config)
-					}(plcCtx, plcConfig)
+					gen := m.markMonitored(plcConfig.ID)
+
+					// Iniciar goroutine para este PLC. A marca sai em qualquer
+					// saída do monitor, inclusive falhas e pânicos.
+					config := plcConfig
+					startIndex := plcIndex
+					m.goTracked(func() {
+						defer m.unmarkMonitored(config.ID, gen)
+						m.monitorPLC(plcCtx, config, startIndex)
+					})
 
 					log.Printf("Iniciado monitoramento do PLC %d: %s", plcConfig.ID, plcConfig.Name)
 				}
@@ -475,29 +851,114 @@ func (m *PLCManager) runAllPLCs(ctx context.Context) {
 	}
 }
 
-// monitorPLC implementa o monitoramento de um PLC específico
-func (m *PLCManager) monitorPLC(ctx context.Context, plcConfig domain.PLC) {
+// waitSyncReady aguarda a sincronização inicial PostgreSQL -> Redis, até
+// SyncWaitTimeout. Retorna false se o contexto for cancelado antes.
+func (m *PLCManager) waitSyncReady(ctx context.Context) bool {
+	if m.syncReady == nil {
+		return true
+	}
+
+	timeout := m.config.SyncWaitTimeout
+	if timeout <= 0 {
+		timeout = defaultSyncWaitTimeout
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-m.syncReady:
+		return true
+	case <-timer.C:
+		log.Printf("Aviso: sincronização inicial não concluída em %v, iniciando monitoramento mesmo assim", timeout)
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// clearMonitored remove a marca de monitoramento do PLC, qualquer que seja a
+// execução
+func (m *PLCManager) clearMonitored(plcID int) {
+	m.monitoredMutex.Lock()
+	defer m.monitoredMutex.Unlock()
+	delete(m.monitoredPLCs, plcID)
+}
+
+// markMonitored registra uma nova execução do monitor do PLC e retorna a sua
+// geração
+func (m *PLCManager) markMonitored(plcID int) uint64 {
+	m.monitoredMutex.Lock()
+	defer m.monitoredMutex.Unlock()
+
+	m.monitorGen++
+	m.monitoredPLCs[plcID] = m.monitorGen
+	return m.monitorGen
+}
+
+// unmarkMonitored remove a marca do PLC se ela ainda for da execução gen; uma
+// execução antiga terminando não apaga a marca de uma mais nova
+func (m *PLCManager) unmarkMonitored(plcID int, gen uint64) {
+	m.monitoredMutex.Lock()
+	defer m.monitoredMutex.Unlock()
+
+	if m.monitoredPLCs[plcID] == gen {
+		delete(m.monitoredPLCs, plcID)
+	}
+}
+
+// IsMonitoring indica se o PLC está sendo monitorado neste momento
+func (m *PLCManager) IsMonitoring(plcID int) bool {
+	m.monitoredMutex.RLock()
+	defer m.monitoredMutex.RUnlock()
+
+	_, running := m.monitoredPLCs[plcID]
+	return running
+}
+
+// StopPLCMonitor pede o cancelamento imediato do monitoramento de um PLC,
+// sem esperar o próximo ciclo de runAllPLCs
+func (m *PLCManager) StopPLCMonitor(plcID int) {
+	select {
+	case m.stopMonitorChan <- plcID:
+	default:
+		// Fila cheia: o próximo ciclo de runAllPLCs remove o PLC
+	}
+}
+
+// monitorPLC implementa o monitoramento de um PLC específico. plcIndex é a
+// posição do PLC na lista de PLCs ativos e escalona a primeira conexão.
+func (m *PLCManager) monitorPLC(ctx context.Context, plcConfig domain.PLC, plcIndex int) {
 	log.Printf("Iniciando monitor para PLC %d: %s (%s)", plcConfig.ID, plcConfig.Name, plcConfig.IPAddress)
 
+	// Worker da fila de escritas assíncronas deste PLC
+	m.goTracked(func() {
+		m.runWriteQueueWorker(ctx, plcConfig.ID)
+	})
+
 	// Criar conexão com o PLC
 	conn := NewPLCConnection(plcConfig.ID, plcConfig.IPAddress, plcConfig.Rack, plcConfig.Slot)
+	conn.remoteTSAP = uint16(plcConfig.EffectiveRemoteTSAP())
+
+	// Escalonar a primeira conexão para não conectar todos os PLCs de uma vez
+	if !m.waitStartupStagger(ctx, plcIndex) {
+		return
+	}
 
 	// Conectar ao PLC com retry
 	maxRetries := 3
 	connected := false
 
 	for attempt := 1; attempt <= maxRetries; attempt++ {
-		if err := conn.Connect(); err != nil {
+		if err := m.connectLimited(ctx, conn); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+
 			log.Printf("Tentativa %d/%d - Erro ao conectar ao PLC %d: %v",
 				attempt, maxRetries, plcConfig.ID, err)
 
 			// Atualizar status do PLC para "offline"
-			updateErr := m.plcRepo.UpdatePLCStatus(domain.PLCStatus{
-				PLCID:      plcConfig.ID,
-				Status:     "offline",
-				LastUpdate: time.Now(),
-			})
-			if updateErr != nil {
+			if updateErr := m.setPLCStatus(plcConfig.ID, "offline"); updateErr != nil {
 				log.Printf("Erro ao atualizar status do PLC %d: %v", plcConfig.ID, updateErr)
 			}
 
@@ -505,7 +966,7 @@ func (m *PLCManager) monitorPLC(ctx context.Context, plcConfig domain.PLC) {
 			select {
 			case <-ctx.Done():
 				return
-			case <-time.After(m.config.RetryInterval):
+			case <-time.After(m.runtimeConfig().RetryInterval):
 				// Continuar para a próxima tentativa
 			}
 		} else {
@@ -515,28 +976,42 @@ func (m *PLCManager) monitorPLC(ctx context.Context, plcConfig domain.PLC) {
 	}
 
 	if !connected {
-		log.Printf("Falha ao conectar ao PLC %d após %d tentativas. Desistindo.",
+		log.Printf("Falha ao conectar ao PLC %d após %d tentativas. Reagendando com backoff exponencial.",
 			plcConfig.ID, maxRetries)
-		return
+
+		if !m.reconnectWithBackoff(ctx, plcConfig, conn, maxRetries) {
+			return
+		}
 	}
 
+	// Conexão estabelecida, limpar agendamento de nova tentativa
+	m.statsMutex.Lock()
+	if connStats, exists := m.stats.ConnectionStats[plcConfig.ID]; exists {
+		connStats.NextRetryAt = time.Time{}
+		connStats.LastConnected = time.Now()
+		m.stats.ConnectionStats[plcConfig.ID] = connStats
+	}
+	m.statsMutex.Unlock()
+
 	// Registrar a conexão ativa
 	m.connectionsMutex.Lock()
 	m.activeConnections[plcConfig.ID] = conn
 	m.connectionsMutex.Unlock()
 
 	// Atualizar status do PLC para "online"
-	err := m.plcRepo.UpdatePLCStatus(domain.PLCStatus{
-		PLCID:      plcConfig.ID,
-		Status:     "online",
-		LastUpdate: time.Now(),
-	})
-	if err != nil {
+	if err := m.setPLCStatus(plcConfig.ID, "online"); err != nil {
 		log.Printf("Erro ao atualizar status do PLC %d: %v", plcConfig.ID, err)
 	}
 
+	// Verificar a conexão periodicamente enquanto as tags são monitoradas
+	healthCtx, cancelHealth := context.WithCancel(ctx)
+	m.goTracked(func() {
+		m.runConnectionHealthCheck(healthCtx, plcConfig, conn)
+	})
+
 	// Monitorar as tags
 	m.monitorPLCTags(ctx, plcConfig, conn)
+	cancelHealth()
 
 	// Ao finalizar, fechar a conexão e remover do registro
 	conn.Close()
@@ -548,6 +1023,66 @@ func (m *PLCManager) monitorPLC(ctx context.Context, plcConfig domain.PLC) {
 	log.Printf("Monitoramento encerrado para PLC %d: %s", plcConfig.ID, plcConfig.Name)
 }
 
+// reconnectWithBackoff tenta reconectar indefinidamente, dobrando a espera a cada
+// falha a partir de RetryInterval até maxReconnectBackoff. Retorna false se o
+// contexto for cancelado antes de conseguir conectar.
+func (m *PLCManager) reconnectWithBackoff(ctx context.Context, plcConfig domain.PLC, conn *PLCConnection, previousAttempts int) bool {
+	backoff := m.runtimeConfig().RetryInterval
+	if backoff <= 0 {
+		backoff = time.Second
+	}
+
+	for attempt := previousAttempts + 1; ; attempt++ {
+		nextRetry := time.Now().Add(backoff)
+		m.recordRetry(plcConfig, nextRetry)
+
+		log.Printf("evento=plc_reconnect_scheduled plc_id=%d attempt_count=%d backoff=%v next_retry_at=%s",
+			plcConfig.ID, attempt, backoff, nextRetry.Format(time.RFC3339))
+
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(backoff):
+		}
+
+		err := m.connectLimited(ctx, conn)
+		if ctx.Err() != nil {
+			return false
+		}
+		if err == nil {
+			log.Printf("evento=plc_reconnect_succeeded plc_id=%d attempt_count=%d", plcConfig.ID, attempt)
+			return true
+		}
+
+		log.Printf("evento=plc_reconnect_failed plc_id=%d attempt_count=%d backoff=%v erro=%q",
+			plcConfig.ID, attempt, backoff, err.Error())
+
+		backoff *= 2
+		if backoff > maxReconnectBackoff {
+			backoff = maxReconnectBackoff
+		}
+	}
+}
+
+// recordRetry registra nas estatísticas uma nova tentativa de reconexão agendada
+func (m *PLCManager) recordRetry(plcConfig domain.PLC, nextRetry time.Time) {
+	m.statsMutex.Lock()
+	defer m.statsMutex.Unlock()
+
+	connStats, exists := m.stats.ConnectionStats[plcConfig.ID]
+	if !exists {
+		connStats = PLCConnectionStats{
+			PLCID:  plcConfig.ID,
+			Name:   plcConfig.Name,
+			Status: "offline",
+		}
+	}
+
+	connStats.RetryCount++
+	connStats.NextRetryAt = nextRetry
+	m.stats.ConnectionStats[plcConfig.ID] = connStats
+}
+
 // monitorPLCTags implementa o monitoramento das tags de um PLC
 func (m *PLCManager) monitorPLCTags(ctx context.Context, plcConfig domain.PLC, conn *PLCConnection) {
 	log.Printf("Iniciando monitoramento de tags para PLC %d: %s", plcConfig.ID, plcConfig.Name)
@@ -567,7 +1102,7 @@ func (m *PLCManager) monitorPLCTags(ctx context.Context, plcConfig domain.PLC, c
 	} else {
 		// Log de verificação para tags
 		for _, tag := range tags {
-			if tag.Active {
+			if tag.IsScanned() {
 				log.Printf("PLC %d - Tag configurada: %s (ID: %d, Tipo: %s, DB%d.DBX%d.%d, ScanRate: %d ms)",
 					plcConfig.ID, tag.Name, tag.ID, tag.DataType, tag.DBNumber, tag.ByteOffset, tag.BitOffset, tag.ScanRate)
 			}
@@ -593,7 +1128,7 @@ func (m *PLCManager) monitorPLCTags(ctx context.Context, plcConfig domain.PLC, c
 
 			// Verificar novas tags e inicializar valores
 			for _, tag := range updatedTags {
-				if !tag.Active {
+				if !tag.IsScanned() {
 					continue
 				}
 
@@ -606,7 +1141,7 @@ func (m *PLCManager) monitorPLCTags(ctx context.Context, plcConfig domain.PLC, c
 						// Leitura imediata
 						value, err := conn.ReadTag(
 							tag.DBNumber,
-							int(tag.ByteOffset),
+							tag.ByteOffset,
 							tag.DataType,
 							tag.BitOffset,
 						)
@@ -619,17 +1154,20 @@ func (m *PLCManager) monitorPLCTags(ctx context.Context, plcConfig domain.PLC, c
 							tagValue := domain.TagValue{
 								PLCID:     plcConfig.ID,
 								TagID:     tag.ID,
-								Value:     value,
+								Value:     scaleValue(tag, value),
+								Quality:   domain.QualityGood,
 								Timestamp: time.Now(),
 							}
-
-							if err := m.cache.BatchSetTagValues([]domain.TagValue{tagValue}); err != nil {
-								log.Printf("Erro ao armazenar valor inicial da tag %s: %v", tag.Name, err)
-							} else {
-								// Armazenar no mapa local também
-								lastValues.Store(tag.ID, value)
-								log.Printf("Tag %s inicializada com valor: %v", tag.Name, value)
+							if tag.HasScaling() {
+								tagValue.RawValue = value
 							}
+							value = tagValue.Value
+
+							m.publishTagValues([]domain.TagValue{tagValue}, false)
+
+							// Armazenar no mapa local também
+							lastValues.Store(tag.ID, value)
+							log.Printf("Tag %s inicializada com valor: %v", tag.Name, value)
 						}
 					}
 				}
@@ -641,59 +1179,109 @@ func (m *PLCManager) monitorPLCTags(ctx context.Context, plcConfig domain.PLC, c
 	}
 }
 
-// processTagsUpdate processa atualizações nas tags de um PLC
+// processTagsUpdate processa atualizações nas tags de um PLC. Monitores já em
+// execução recebem a nova lista pelo canal de atualizações, sem reinício.
 func (m *PLCManager) processTagsUpdate(ctx context.Context, tags []domain.PLCTag, plcConfig domain.PLC, conn *PLCConnection, lastValues *sync.Map) {
-	// Agrupar tags por taxa de scan
-	tagsByRate := make(map[int]bool)
-	activeRates := make(map[int]bool)
+	defaultScanRate := m.runtimeConfig().DefaultTagScanRate
+	activeTags := make([]domain.PLCTag, 0, len(tags))
 
 	for _, tag := range tags {
-		if !tag.Active {
+		if !tag.IsScanned() {
 			continue
 		}
 
+		// Tags sem scan rate próprio seguem o padrão configurado
+		if tag.ScanRate <= 0 {
+			tag.ScanRate = defaultScanRate
+		}
+
 		// Definir scan rate mínimo
 		if tag.ScanRate < 100 {
 			tag.ScanRate = 100 // Mínimo de 100ms
 		}
 
-		tagsByRate[tag.ScanRate] = true
-		activeRates[tag.ScanRate] = true
+		activeTags = append(activeTags, tag)
+	}
+
+	// Tags de um grupo de varredura são lidas no mesmo ciclo
+	alignScanGroupRates(activeTags)
+
+	// Agrupar tags por taxa de scan
+	tagsByRate := make(map[int][]domain.PLCTag)
+	for _, tag := range activeTags {
+		tagsByRate[tag.ScanRate] = append(tagsByRate[tag.ScanRate], tag)
 	}
 
-	// Parar monitores que não têm mais tags
 	m.tagMonitorMutex.Lock()
-	for rate, cancel := range m.tagMonitors {
-		if !activeRates[rate] {
-			cancel()
-			delete(m.tagMonitors, rate)
-			log.Printf("Monitor de tags com taxa %dms encerrado para PLC %d", rate, plcConfig.ID)
+	defer m.tagMonitorMutex.Unlock()
+
+	// Parar monitores deste PLC que não têm mais tags
+	for key, monitor := range m.tagMonitors {
+		if key.plcID != plcConfig.ID {
+			continue
+		}
+		if _, active := tagsByRate[key.rate]; !active {
+			monitor.cancel()
+			delete(m.tagMonitors, key)
+			log.Printf("Monitor de tags com taxa %dms encerrado para PLC %d", key.rate, plcConfig.ID)
 		}
 	}
-	m.tagMonitorMutex.Unlock()
 
-	// Iniciar novos monitores para rates que não existem
-	for rate := range tagsByRate {
-		m.tagMonitorMutex.Lock()
-		if _, exists := m.tagMonitors[rate]; !exists {
-			monitorCtx, cancel := context.WithCancel(ctx)
-			m.tagMonitors[rate] = cancel
+	for rate, rateTags := range tagsByRate {
+		key := tagMonitorKey{plcID: plcConfig.ID, rate: rate}
 
-			log.Printf("Iniciando monitor de tags para PLC %d com taxa %dms",
-				plcConfig.ID, rate)
+		// Monitor existente: enviar a nova lista de tags
+		if monitor, exists := m.tagMonitors[key]; exists {
+			monitor.sendUpdate(tagListUpdate{tags: rateTags})
+			continue
+		}
 
-			go m.startTagMonitor(rate, plcConfig.ID, monitorCtx, plcConfig, conn, lastValues)
+		// Iniciar novo monitor para esta taxa
+		monitorCtx, cancel := context.WithCancel(ctx)
+		monitor := &tagMonitor{
+			cancel:  cancel,
+			updates: make(chan tagListUpdate, 1),
 		}
-		m.tagMonitorMutex.Unlock()
+		m.tagMonitors[key] = monitor
+
+		log.Printf("Iniciando monitor de tags para PLC %d com taxa %dms",
+			plcConfig.ID, rate)
+
+		rate, rateTags := rate, rateTags
+		m.goTracked(func() {
+			m.startTagMonitor(rate, rateTags, monitor.updates, monitorCtx, plcConfig, conn, lastValues)
+		})
+	}
+}
+
+// sendUpdate entrega uma atualização ao monitor sem bloquear. Se houver uma
+// atualização pendente ainda não consumida, ela é substituída pela mais recente.
+// Deve ser chamado com tagMonitorMutex travado (único remetente).
+func (t *tagMonitor) sendUpdate(update tagListUpdate) {
+	select {
+	case t.updates <- update:
+	default:
+		select {
+		case <-t.updates:
+		default:
+		}
+		t.updates <- update
 	}
 }
 
 // startTagMonitor inicia o monitoramento de um grupo de tags com a mesma taxa de scan
-func (m *PLCManager) startTagMonitor(rate int, plcID int, ctx context.Context, plcConfig domain.PLC, conn *PLCConnection, lastValues *sync.Map) {
+func (m *PLCManager) startTagMonitor(rate int, tags []domain.PLCTag, updates <-chan tagListUpdate, ctx context.Context, plcConfig domain.PLC, conn *PLCConnection, lastValues *sync.Map) {
 	ticker := time.NewTicker(time.Duration(rate) * time.Millisecond)
 	defer ticker.Stop()
 
-	log.Printf("PLC %d: Monitorando tags com taxa de %d ms", plcConfig.ID, rate)
+	log.Printf("PLC %d: Monitorando %d tags com taxa de %d ms", plcConfig.ID, len(tags), rate)
+
+	// Falhas de leitura consecutivas por tag
+	consecutiveErrors := make(map[int]int)
+
+	// Disparos seguidos descartados por backpressure
+	skippedTicks := 0
+	rateDuration := time.Duration(rate) * time.Millisecond
 
 	for {
 		select {
@@ -701,33 +1289,58 @@ func (m *PLCManager) startTagMonitor(rate int, plcID int, ctx context.Context, p
 			log.Printf("PLC %d: Encerrando monitoramento de tags com taxa %d ms", plcConfig.ID, rate)
 			return
 
-		case <-ticker.C:
-			// Buscar tags atuais para este PLC e para esta taxa de scan
-			allTags, err := m.tagRepo.GetPLCTags(plcConfig.ID)
-			if err != nil {
-				log.Printf("Erro ao buscar tags para PLC %d: %v", plcConfig.ID, err)
-				continue
+		case update := <-updates:
+			// Descartar últimos valores de tags que saíram deste grupo
+			current := make(map[int]bool, len(update.tags))
+			for _, tag := range update.tags {
+				current[tag.ID] = true
 			}
-
-			// Filtrar por tags ativos com esta taxa de scan
-			currentTags := make([]domain.PLCTag, 0)
-			for _, tag := range allTags {
-				if tag.Active && tag.ScanRate == rate {
-					currentTags = append(currentTags, tag)
+			for _, tag := range tags {
+				if !current[tag.ID] {
+					lastValues.Delete(tag.ID)
+					delete(consecutiveErrors, tag.ID)
 				}
 			}
 
+			tags = update.tags
+
+		case tickAt := <-ticker.C:
+			currentTags := tags
+
 			// Se não houver tags, pular esta execução
 			if len(currentTags) == 0 {
 				continue
 			}
 
+			// Descartar o disparo se o PLC não está acompanhando o scan rate
+			if m.skipScanTick(tickAt, rateDuration) {
+				skippedTicks++
+				m.recordScanSkip(plcConfig, rate, skippedTicks)
+				continue
+			}
+			skippedTicks = 0
+
+			// Não iniciar um novo ciclo de leitura durante o encerramento
+			if !m.beginInFlight() {
+				continue
+			}
+
 			// Ler valor de cada tag no grupo atual
+			readStart := time.Now()
 			updatedValues := make([]domain.TagValue, 0, len(currentTags))
+			// Bits individuais de tags com UnpackBits (IDs sintéticos, só no cache)
+			var bitValues []domain.TagValue
+			// Tags que atingiram o limite de falhas consecutivas neste ciclo
+			var disabled []disabledTag
+
+			// Aguardar uma reconexão em andamento antes de ler
+			conn.scanMu.RLock()
+
+			// Grupos de varredura são lidos antes, uma requisição por grupo e DB
+			groupReads := m.readScanGroups(plcConfig, conn, currentTags)
 
 			for _, tag := range currentTags {
-				// Converter ByteOffset de float64 para int
-				byteOffset := int(tag.ByteOffset)
+				byteOffset := tag.ByteOffset
 
 				// Verificação adicional para garantir que o tipo é válido
 				if tag.DataType == "" {
@@ -737,17 +1350,25 @@ func (m *PLCManager) startTagMonitor(rate int, plcID int, ctx context.Context, p
 				}
 
 				// Adicionar log para rastrear tipo de dados
-				if m.enableDetailedLogging {
+				if m.enableDetailedLogging.Load() {
 					log.Printf("Lendo tag %s (ID=%d) - Tipo: %s, DB%d.DBX%d.%d",
 						tag.Name, tag.ID, tag.DataType, tag.DBNumber, byteOffset, tag.BitOffset)
 				}
 
-				value, err := conn.ReadTag(
-					tag.DBNumber,
-					byteOffset,
-					tag.DataType,
-					tag.BitOffset,
-				)
+				var value interface{}
+				var err error
+				if read, grouped := groupReads[tag.ID]; grouped {
+					value, err = read.value, read.err
+				} else {
+					tagReadStart := time.Now()
+					value, err = conn.ReadTag(
+						tag.DBNumber,
+						byteOffset,
+						tag.DataType,
+						tag.BitOffset,
+					)
+					m.recordRead(plcConfig.ID, tag.DataType, time.Since(tagReadStart), err)
+				}
 
 				if err != nil {
 					log.Printf("Erro ao ler tag %s (ID=%d): %v",
@@ -761,43 +1382,59 @@ func (m *PLCManager) startTagMonitor(rate int, plcID int, ctx context.Context, p
 						m.stats.ConnectionStats[plcConfig.ID] = connStats
 					}
 					m.statsMutex.Unlock()
+
+					// Manter o último valor conhecido, sinalizando a qualidade
+					lastValue, _ := lastValues.Load(tag.ID)
+					updatedValues = append(updatedValues, domain.TagValue{
+						PLCID:     plcConfig.ID,
+						TagID:     tag.ID,
+						Value:     lastValue,
+						Quality:   readErrorQuality(err),
+						Timestamp: time.Now(),
+					})
+
+					consecutiveErrors[tag.ID]++
+					if threshold := m.runtimeConfig().ConsecutiveErrorThreshold; threshold > 0 && consecutiveErrors[tag.ID] >= threshold {
+						disabled = append(disabled, disabledTag{tag: tag, err: err})
+					}
 					continue
 				}
+				delete(consecutiveErrors, tag.ID)
 
 				// Verificar o tipo do valor retornado
-				if m.enableDetailedLogging {
+				if m.enableDetailedLogging.Load() {
 					log.Printf("Tag %s (ID=%d): Tipo definido '%s', valor lido do tipo %T: %v",
 						tag.Name, tag.ID, tag.DataType, value, value)
 				}
 
-				// Verificar se precisamos atualizar o cache
-				shouldUpdate := true
-
-				// Se a tag monitora apenas mudanças
-				if tag.MonitorChanges {
-					// Buscar valor anterior
-					if lastValue, exists := lastValues.Load(tag.ID); exists {
-						if plc.CompareValues(lastValue, value) {
-							// Valores iguais, não atualizar
-							shouldUpdate = false
-						}
-					}
-				}
+				// Aplicar a escala da tag; comparações, MinDelta e o cache
+				// trabalham com o valor em unidade de engenharia
+				rawValue := value
+				value = scaleValue(tag, rawValue)
 
-				if shouldUpdate {
+				if shouldCacheValue(tag, lastValues, value) {
 					// Atualizar valor no mapa local
 					lastValues.Store(tag.ID, value)
 
 					// Adicionar ao lote para atualização
-					updatedValues = append(updatedValues, domain.TagValue{
+					tagValue := domain.TagValue{
 						PLCID:     plcConfig.ID,
 						TagID:     tag.ID,
 						Value:     value,
+						Quality:   domain.QualityGood,
 						Timestamp: time.Now(),
-					})
+					}
+					if tag.HasScaling() {
+						tagValue.RawValue = rawValue
+					}
+					updatedValues = append(updatedValues, tagValue)
+
+					if tag.UnpackBits {
+						bitValues = append(bitValues, unpackBitValues(plcConfig.ID, tag, rawValue)...)
+					}
 
 					// Logging detalhado de valores
-					if m.enableDetailedLogging {
+					if m.enableDetailedLogging.Load() {
 						// Formatação mais legível do valor baseado no tipo de dados
 						var valorFormatado string
 						switch tag.DataType {
@@ -833,22 +1470,121 @@ func (m *PLCManager) startTagMonitor(rate int, plcID int, ctx context.Context, p
 					}
 				}
 			}
+			conn.scanMu.RUnlock()
+
+			// Duração do ciclo de leitura do grupo
+			if m.metrics != nil {
+				m.metrics.RecordHistogram("plc.read.latency_ms", float64(time.Since(readStart).Microseconds())/1000.0)
+			}
 
-			// Atualizar valores em lote para melhor performance
+			// Publicar as leituras; cache e histórico são atualizados pelos
+			// consumidores do barramento
 			if len(updatedValues) > 0 {
-				if err := m.cache.BatchSetTagValues(updatedValues); err != nil {
-					log.Printf("Erro ao atualizar valores em lote: %v", err)
-				} else {
-					// Atualizar estatísticas
-					m.statsMutex.Lock()
-					m.stats.TagsRead += int64(len(updatedValues))
-					m.statsMutex.Unlock()
-				}
+				m.publishTagValues(updatedValues, false)
+				m.publishTagValues(bitValues, true)
+			}
+
+			// Retirar da varredura as tags que falharam seguidamente
+			if len(disabled) > 0 {
+				tags = m.autoDisableTags(plcConfig, tags, disabled, consecutiveErrors)
 			}
+
+			m.endInFlight()
 		}
 	}
 }
 
+// shouldCacheValue decide se o valor lido vai para o cache, comparando-o com
+// o último valor gravado da tag (não o último lido): tags com MonitorChanges
+// ignoram valores iguais e tags com MinDelta ignoram variações menores que ele
+func shouldCacheValue(tag domain.PLCTag, lastValues *sync.Map, value interface{}) bool {
+	lastValue, exists := lastValues.Load(tag.ID)
+	if !exists {
+		return true
+	}
+
+	if tag.MonitorChanges && plc.CompareValues(lastValue, value) {
+		return false
+	}
+	if tag.MinDelta > 0 && withinMinDelta(lastValue, value, tag.MinDelta) {
+		return false
+	}
+	return true
+}
+
+// withinMinDelta indica se a diferença entre dois valores numéricos é menor
+// que minDelta. Valores não numéricos nunca são considerados dentro da faixa.
+func withinMinDelta(last, current interface{}, minDelta float64) bool {
+	lastFloat, ok1 := plc.ToFloat64(last)
+	currentFloat, ok2 := plc.ToFloat64(current)
+	if !ok1 || !ok2 {
+		return false
+	}
+	return math.Abs(currentFloat-lastFloat) < minDelta
+}
+
+// waitWriteSlot aguarda até writeRateLimitWait por uma vaga no limitador de
+// escrita da tag. Tags com WriteRateLimitHz igual a zero não são limitadas.
+func (m *PLCManager) waitWriteSlot(tag domain.PLCTag) error {
+	if tag.WriteRateLimitHz <= 0 {
+		return nil
+	}
+
+	limit := rate.Limit(tag.WriteRateLimitHz)
+
+	m.writeLimiterMutex.Lock()
+	limiter, exists := m.writeLimiters[tag.ID]
+	if !exists {
+		limiter = rate.NewLimiter(limit, 1)
+		m.writeLimiters[tag.ID] = limiter
+	} else if limiter.Limit() != limit {
+		// Configuração da tag alterada desde a última escrita
+		limiter.SetLimit(limit)
+	}
+	m.writeLimiterMutex.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), writeRateLimitWait)
+	defer cancel()
+
+	if err := limiter.Wait(ctx); err != nil {
+		log.Printf("Escrita na tag %s (ID=%d) rejeitada: limite de %.2f escritas/s", tag.Name, tag.ID, tag.WriteRateLimitHz)
+		return fmt.Errorf("%w: '%s'", ErrWriteRateLimited, tag.Name)
+	}
+
+	return nil
+}
+
+// unpackBitValues gera um valor por bit de uma word empacotada, usando os
+// IDs sintéticos de domain.PackedBitID
+func unpackBitValues(plcID int, tag domain.PLCTag, value interface{}) []domain.TagValue {
+	word, ok := plc.ToFloat64(value)
+	if !ok {
+		return nil
+	}
+
+	now := time.Now()
+	values := make([]domain.TagValue, 16)
+	for pos := 0; pos < 16; pos++ {
+		values[pos] = domain.TagValue{
+			PLCID:     plcID,
+			TagID:     domain.PackedBitID(tag.ID, pos),
+			Value:     uint16(word)&(1<<uint(pos)) != 0,
+			Quality:   domain.QualityGood,
+			Timestamp: now,
+		}
+	}
+	return values
+}
+
+// readErrorQuality classifica um erro de leitura: falhas de conversão geram
+// qualidade incerta, as demais (comunicação) qualidade ruim
+func readErrorQuality(err error) string {
+	if errors.Is(err, plc.ErrValueConversion) || errors.Is(err, plc.ErrInvalidDataType) {
+		return domain.QualityUncertain
+	}
+	return domain.QualityBad
+}
+
 // GetConnectionByPLCID retorna uma conexão ativa com um PLC
 func (m *PLCManager) GetConnectionByPLCID(plcID int) (*PLCConnection, error) {
 	m.connectionsMutex.RLock()
@@ -856,7 +1592,7 @@ func (m *PLCManager) GetConnectionByPLCID(plcID int) (*PLCConnection, error) {
 
 	conn, exists := m.activeConnections[plcID]
 	if !exists {
-		return nil, fmt.Errorf("%w: ID %d", ErrPLCNotFound, plcID)
+		return nil, fmt.Errorf("%w: ID %d", domain.ErrPLCNotFound, plcID)
 	}
 
 	if !conn.IsActive() {
@@ -866,14 +1602,15 @@ func (m *PLCManager) GetConnectionByPLCID(plcID int) (*PLCConnection, error) {
 	return conn, nil
 }
 
-// WriteTagByName encontra uma tag pelo nome e escreve um valor nela
-func (m *PLCManager) WriteTagByName(tagName string, value interface{}) error {
+// WriteTagByName encontra uma tag pelo nome e escreve um valor nela. userID
+// identifica quem pediu a escrita (0 = sistema).
+func (m *PLCManager) WriteTagByName(tagName string, value interface{}, userID int) error {
 	log.Printf("Solicitação para escrever na tag '%s': %v", tagName, value)
 
 	// Buscar tags pelo nome
 	tags, err := m.tagRepo.GetByName(tagName)
 	if err != nil {
-		return fmt.Errorf("erro ao buscar tag '%s': %v", tagName, err)
+		return fmt.Errorf("erro ao buscar tag '%s': %w", tagName, err)
 	}
 
 	if len(tags) == 0 {
@@ -881,11 +1618,28 @@ func (m *PLCManager) WriteTagByName(tagName string, value interface{}) error {
 	}
 
 	// Usar a primeira tag encontrada
-	tag := tags[0]
+	return m.writeTag(tags[0], value, userID)
+}
 
+// WriteTagByID escreve um valor na tag do PLC informado. Diferente de
+// WriteTagByName, não depende do nome, que pode se repetir entre PLCs.
+func (m *PLCManager) WriteTagByID(plcID, tagID int, value interface{}, userID int) error {
+	tag, err := m.tagRepo.GetByID(tagID)
+	if err != nil {
+		return fmt.Errorf("erro ao buscar tag %d: %w", tagID, err)
+	}
+	if tag.PLCID != plcID {
+		return fmt.Errorf("%w: tag %d não pertence ao PLC %d", ErrTagNotFound, tagID, plcID)
+	}
+
+	return m.writeTag(tag, value, userID)
+}
+
+// writeTag escreve um valor em uma tag já resolvida
+func (m *PLCManager) writeTag(tag domain.PLCTag, value interface{}, userID int) error {
 	// Verificar se a tag permite escrita
 	if !tag.CanWrite {
-		return fmt.Errorf("%w: '%s'", ErrWriteNotPermitted, tagName)
+		return fmt.Errorf("%w: '%s'", ErrWriteNotPermitted, tag.Name)
 	}
 
 	// Buscar conexão com o PLC
@@ -894,8 +1648,17 @@ func (m *PLCManager) WriteTagByName(tagName string, value interface{}) error {
 		return fmt.Errorf("erro de conexão: %w", err)
 	}
 
-	// Converter ByteOffset para inteiro
-	byteOffset := int(tag.ByteOffset)
+	if !m.beginInFlight() {
+		return fmt.Errorf("%w: gerenciador em encerramento", ErrPLCNotConnected)
+	}
+	defer m.endInFlight()
+
+	// Respeitar o limite de escritas por segundo da tag
+	if err := m.waitWriteSlot(tag); err != nil {
+		return err
+	}
+
+	byteOffset := tag.ByteOffset
 
 	// Verificação adicional para garantir que o tipo da tag é válido
 	if tag.DataType == "" {
@@ -906,23 +1669,32 @@ func (m *PLCManager) WriteTagByName(tagName string, value interface{}) error {
 	// Normalizar o tipo de dados
 	tag.DataType = strings.ToLower(strings.TrimSpace(tag.DataType))
 
+	// Converter o valor em unidade de engenharia para o valor bruto do PLC
+	rawValue, err := unscaleValue(tag, value)
+	if err != nil {
+		return err
+	}
+
 	// Log detalhado da operação de escrita
 	log.Printf("WriteTagByName - Escrevendo na tag %s: Tipo=%s, DB%d.DBX%d.%d, Valor=%v (%T)",
-		tag.Name, tag.DataType, tag.DBNumber, byteOffset, tag.BitOffset, value, value)
+		tag.Name, tag.DataType, tag.DBNumber, byteOffset, tag.BitOffset, rawValue, rawValue)
 
 	// Tentar escrever com retry em caso de erro
 	maxRetries := 2
 	var writeErr error
 
 	for attempt := 0; attempt <= maxRetries; attempt++ {
-		// Escrever o valor na tag
+		// Escrever o valor na tag; como a varredura, espera a reconexão
+		// disparada por falha de ping terminar
+		conn.scanMu.RLock()
 		writeErr = conn.WriteTag(
 			tag.DBNumber,
 			byteOffset,
 			tag.DataType,
 			tag.BitOffset,
-			value,
+			rawValue,
 		)
+		conn.scanMu.RUnlock()
 
 		if writeErr == nil {
 			break
@@ -955,11 +1727,31 @@ func (m *PLCManager) WriteTagByName(tagName string, value interface{}) error {
 		log.Printf("Erro ao atualizar cache: %v", err)
 	}
 
+	m.recordLastWrite(tag.ID, userID, time.Now())
+
 	// Incrementar contador de tags escritas
 	m.statsMutex.Lock()
 	m.stats.TagsWritten++
 	m.statsMutex.Unlock()
 
-	log.Printf("Valor escrito com sucesso na tag %s", tagName)
+	log.Printf("Valor escrito com sucesso na tag %s", tag.Name)
 	return nil
 }
+
+// recordLastWrite registra a última escrita no Redis e, em segundo plano, no
+// repositório persistente
+func (m *PLCManager) recordLastWrite(tagID, userID int, at time.Time) {
+	if err := m.cache.SetTagLastWrite(tagID, userID, at); err != nil {
+		log.Printf("Erro ao registrar última escrita da tag %d no cache: %v", tagID, err)
+	}
+
+	if m.lastWriteRepo == nil {
+		return
+	}
+
+	m.goTracked(func() {
+		if err := m.lastWriteRepo.UpdateLastWrite(tagID, userID, at); err != nil {
+			log.Printf("Erro ao registrar última escrita da tag %d no banco: %v", tagID, err)
+		}
+	})
+}
@@ -0,0 +1,240 @@
+package service
+
+import (
+	"app_padrao/internal/domain"
+	"app_padrao/internal/testutil"
+	"app_padrao/pkg/events"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDrainInFlightWithoutOperations(t *testing.T) {
+	m := NewPLCManager(nil, nil, nil)
+
+	if !m.drainInFlight(time.Second) {
+		t.Fatal("drenagem sem operações deveria concluir")
+	}
+	if m.beginInFlight() {
+		t.Error("nenhuma operação deveria começar durante a drenagem")
+	}
+}
+
+func TestDrainInFlightWaitsForOperation(t *testing.T) {
+	m := NewPLCManager(nil, nil, nil)
+	if !m.beginInFlight() {
+		t.Fatal("beginInFlight recusou a operação")
+	}
+
+	time.AfterFunc(50*time.Millisecond, m.endInFlight)
+
+	if !m.drainInFlight(2 * time.Second) {
+		t.Fatal("drenagem deveria concluir quando a operação termina")
+	}
+	if got := m.pendingInFlight(); got != 0 {
+		t.Errorf("operações pendentes = %d, esperado 0", got)
+	}
+}
+
+func TestDrainInFlightTimeoutDoesNotLeak(t *testing.T) {
+	m := NewPLCManager(nil, nil, nil)
+	m.beginInFlight()
+
+	before := runtime.NumGoroutine()
+	for i := 0; i < 20; i++ {
+		if m.drainInFlight(time.Millisecond) {
+			t.Fatal("drenagem deveria expirar com a operação em andamento")
+		}
+	}
+	runtime.Gosched()
+
+	if after := runtime.NumGoroutine(); after > before {
+		t.Errorf("goroutines: %d antes, %d depois das drenagens expiradas", before, after)
+	}
+	m.endInFlight()
+}
+
+func TestRestartAfterExpiredDrain(t *testing.T) {
+	m := NewPLCManager(nil, nil, nil)
+
+	// Operação da execução anterior, ainda em andamento após o Stop
+	m.beginInFlight()
+	if m.drainInFlight(time.Millisecond) {
+		t.Fatal("drenagem deveria expirar")
+	}
+
+	// Novo Start: novas operações começam enquanto a antiga termina
+	m.resumeInFlight()
+	if !m.beginInFlight() {
+		t.Fatal("operação recusada após o reinício")
+	}
+	m.endInFlight() // antiga
+	m.endInFlight() // nova
+
+	if !m.drainInFlight(time.Second) {
+		t.Fatal("segunda drenagem deveria concluir sem operações pendentes")
+	}
+
+	// O canal da drenagem expirada é fechado quando a última operação termina
+	m.resumeInFlight()
+	m.beginInFlight()
+	m.drainInFlight(time.Millisecond)
+	done := make(chan bool, 1)
+	go func() { done <- m.drainInFlight(2 * time.Second) }()
+	time.Sleep(10 * time.Millisecond)
+	m.endInFlight()
+
+	if !<-done {
+		t.Error("drenagem pendente deveria concluir quando a operação termina")
+	}
+}
+
+// TestStopDuringBatchReadKeepsCycleWhole chama Stop enquanto o PLC responde
+// devagar à leitura em lote de um grupo de varredura: o ciclo termina inteiro,
+// com as leituras do lote e da tag avulsa, e o cache não fica com parte dele
+func TestStopDuringBatchReadKeepsCycleWhole(t *testing.T) {
+	sim := testutil.NewS7Simulator(t)
+	sim.SetDB(1, 0, []byte{0x00, 0x0B, 0x00, 0x16, 0x00, 0x21})
+
+	group := 1
+	tagCache := &fakeManagerCache{values: make(map[int]domain.TagValue)}
+	plcRepo := &fakeManagerPLCRepo{plcs: []domain.PLC{
+		{ID: 1, Name: "CLP", IPAddress: sim.Addr(), Slot: 1, Active: true, MonitoringEnabled: true},
+	}}
+	tagRepo := &fakeManagerTagRepo{tags: []domain.PLCTag{
+		{ID: 1, PLCID: 1, Name: "A", DBNumber: 1, ByteOffset: 0, DataType: "int", ScanRate: 100, Active: true, ScaleFactor: 1, ScanGroupID: &group},
+		{ID: 2, PLCID: 1, Name: "B", DBNumber: 1, ByteOffset: 2, DataType: "int", ScanRate: 100, Active: true, ScaleFactor: 1, ScanGroupID: &group},
+		{ID: 3, PLCID: 1, Name: "C", DBNumber: 1, ByteOffset: 4, DataType: "int", ScanRate: 100, Active: true, ScaleFactor: 1},
+	}}
+	want := map[int]int16{1: 11, 2: 22, 3: 33}
+
+	m := NewPLCManager(plcRepo, tagRepo, tagCache)
+	m.SetDetailedLogging(false)
+	m.config.UpdateTagsInterval = 20 * time.Millisecond
+	m.config.StartupStagger = 0
+	m.config.ShutdownDrainTimeout = 5 * time.Second
+
+	readings := m.events.Subscribe(events.TypeTagValueChanged)
+
+	// Cada leitura do PLC demora; o ciclo leva duas (lote e tag avulsa)
+	const delay = 200 * time.Millisecond
+	sim.SetResponseDelay(delay)
+	if err := m.Start(); err != nil {
+		t.Fatalf("erro ao iniciar: %v", err)
+	}
+
+	// Aguardar o início do primeiro ciclo de leitura
+	deadline := time.Now().Add(5 * time.Second)
+	for m.pendingInFlight() == 0 {
+		if time.Now().After(deadline) {
+			m.Stop()
+			t.Fatal("nenhum ciclo de leitura começou")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	start := time.Now()
+	m.Stop()
+	if elapsed := time.Since(start); elapsed < delay {
+		t.Errorf("Stop retornou em %v, antes do fim da leitura em andamento", elapsed)
+	}
+	m.events.Unsubscribe(readings)
+
+	// Todas as leituras publicadas estão completas e com o valor do PLC
+	published := make(map[int]bool)
+	for len(readings) > 0 {
+		e := (<-readings).(events.TagValueChangedEvent)
+		if e.Quality != domain.QualityGood || e.Value != want[e.TagID] {
+			t.Errorf("tag %d publicada com %v (%s), esperado %d (%s)", e.TagID, e.Value, e.Quality, want[e.TagID], domain.QualityGood)
+		}
+		published[e.TagID] = true
+	}
+	if len(published) != len(want) {
+		t.Errorf("tags publicadas = %v, esperado o ciclo inteiro (1, 2 e 3)", published)
+	}
+
+	// O cache recebe o ciclo inteiro ou nada dele
+	tagCache.mu.Lock()
+	defer tagCache.mu.Unlock()
+	if n := len(tagCache.values); n != 0 && n != len(want) {
+		t.Errorf("cache com %d de %d tags do ciclo: %v", n, len(want), tagCache.values)
+	}
+	for id, v := range tagCache.values {
+		if v.Value != want[id] {
+			t.Errorf("tag %d no cache = %v, esperado %d", id, v.Value, want[id])
+		}
+	}
+}
+
+// cacheWrites conta quantos valores da sequência iriam para o cache,
+// repetindo o que o monitor faz a cada leitura
+func cacheWrites(tag domain.PLCTag, values []interface{}) int {
+	var lastValues sync.Map
+	writes := 0
+	for _, value := range values {
+		if shouldCacheValue(tag, &lastValues, value) {
+			lastValues.Store(tag.ID, value)
+			writes++
+		}
+	}
+	return writes
+}
+
+func TestShouldCacheValueMinDeltaOscillation(t *testing.T) {
+	tag := domain.PLCTag{ID: 1, DataType: "real", MinDelta: 0.5}
+
+	// Oscilação em torno de 10,0 sempre abaixo de MinDelta
+	values := []interface{}{float32(10.0), float32(10.2), float32(9.8), float32(10.4), float32(9.6), float32(10.1)}
+	if got := cacheWrites(tag, values); got != 1 {
+		t.Errorf("escritas no cache = %d, esperado 1", got)
+	}
+}
+
+func TestShouldCacheValueComparesWithLastWritten(t *testing.T) {
+	tag := domain.PLCTag{ID: 1, DataType: "real", MinDelta: 0.5}
+
+	// Cada passo é menor que MinDelta, mas a deriva acumulada em relação ao
+	// último valor gravado chega a 0,6 na quarta leitura
+	values := []interface{}{float32(10.0), float32(10.2), float32(10.4), float32(10.6)}
+	if got := cacheWrites(tag, values); got != 2 {
+		t.Errorf("escritas no cache = %d, esperado 2", got)
+	}
+}
+
+func TestShouldCacheValueMonitorChanges(t *testing.T) {
+	tag := domain.PLCTag{ID: 1, DataType: "int", MonitorChanges: true}
+
+	values := []interface{}{int16(5), int16(5), int16(6), int16(6), int16(5)}
+	if got := cacheWrites(tag, values); got != 3 {
+		t.Errorf("escritas no cache = %d, esperado 3", got)
+	}
+}
+
+func TestUnmarkMonitoredKeepsNewerRun(t *testing.T) {
+	m := NewPLCManager(nil, nil, nil)
+
+	first := m.markMonitored(1)
+	if !m.IsMonitoring(1) {
+		t.Fatal("PLC 1 deveria constar como monitorado")
+	}
+
+	// Monitor reiniciado antes de a execução anterior terminar
+	second := m.markMonitored(1)
+	m.unmarkMonitored(1, first)
+	if !m.IsMonitoring(1) {
+		t.Error("o fim da execução antiga não deveria apagar a marca da nova")
+	}
+
+	// Execução atual terminando por falha libera o status
+	m.unmarkMonitored(1, second)
+	if m.IsMonitoring(1) {
+		t.Error("PLC 1 não deveria constar como monitorado após o fim da execução")
+	}
+
+	m.markMonitored(2)
+	m.clearMonitored(2)
+	if m.IsMonitoring(2) {
+		t.Error("clearMonitored deveria remover a marca do PLC 2")
+	}
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"app_padrao/internal/domain"
+	"app_padrao/internal/testutil"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// fakeManagerPLCRepo implementa apenas o que o gerenciador usa dos PLCs
+type fakeManagerPLCRepo struct {
+	domain.PLCRepository
+	plcs []domain.PLC
+}
+
+func (r *fakeManagerPLCRepo) GetActivePLCs() ([]domain.PLC, error) {
+	return r.plcs, nil
+}
+
+func (r *fakeManagerPLCRepo) UpdatePLCStatus(status domain.PLCStatus) error {
+	return nil
+}
+
+// fakeManagerTagRepo implementa apenas a listagem de tags de um PLC
+type fakeManagerTagRepo struct {
+	domain.PLCTagRepository
+	tags []domain.PLCTag
+}
+
+func (r *fakeManagerTagRepo) GetPLCTags(plcID int) ([]domain.PLCTag, error) {
+	return r.tags, nil
+}
+
+// fakeManagerCache guarda em memória os lotes gravados pelo gerenciador. Sem
+// cliente Redis, não há conexões de pool contando como goroutines.
+type fakeManagerCache struct {
+	domain.PLCCache
+	mu     sync.Mutex
+	values map[int]domain.TagValue
+}
+
+func (c *fakeManagerCache) BatchSetTagValues(values []domain.TagValue) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, v := range values {
+		c.values[v.TagID] = v
+	}
+	return nil
+}
+
+func (c *fakeManagerCache) GetRedisClient() *redis.Client {
+	return nil
+}
+
+// has indica se a tag tem valor gravado e limpa os valores se reset
+func (c *fakeManagerCache) has(tagID int, reset bool) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, ok := c.values[tagID]
+	if reset {
+		c.values = make(map[int]domain.TagValue)
+	}
+	return ok
+}
+
+// waitGoroutines aguarda o número de goroutines voltar a no máximo want
+func waitGoroutines(want int, timeout time.Duration) int {
+	deadline := time.Now().Add(timeout)
+	for {
+		n := runtime.NumGoroutine()
+		if n <= want || time.Now().After(deadline) {
+			return n
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestPLCManagerStartStopDoesNotLeakGoroutines(t *testing.T) {
+	sim := testutil.NewS7Simulator(t)
+	sim.SetDB(1, 0, []byte{0x00, 0x05, 0x00, 0x06})
+
+	tagCache := &fakeManagerCache{values: make(map[int]domain.TagValue)}
+	plcRepo := &fakeManagerPLCRepo{plcs: []domain.PLC{
+		{ID: 1, Name: "CLP", IPAddress: sim.Addr(), Slot: 1, Active: true, MonitoringEnabled: true},
+	}}
+	tagRepo := &fakeManagerTagRepo{tags: []domain.PLCTag{
+		{ID: 1, PLCID: 1, Name: "A", DBNumber: 1, ByteOffset: 0, DataType: "int", ScanRate: 100, Active: true, ScaleFactor: 1},
+		{ID: 2, PLCID: 1, Name: "B", DBNumber: 1, ByteOffset: 2, DataType: "int", ScanRate: 200, Active: true, ScaleFactor: 1},
+	}}
+
+	m := NewPLCManager(plcRepo, tagRepo, tagCache)
+	m.SetDetailedLogging(false)
+	m.config.UpdateTagsInterval = 20 * time.Millisecond
+	m.config.StartupStagger = 0
+	m.config.ShutdownDrainTimeout = time.Second
+
+	baseline := runtime.NumGoroutine()
+
+	for i := 1; i <= 5; i++ {
+		if err := m.Start(); err != nil {
+			t.Fatalf("execução %d: erro ao iniciar: %v", i, err)
+		}
+
+		// Aguardar um ciclo completo: conexão, monitores e gravação no cache
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			if tagCache.has(2, false) {
+				break
+			}
+			if time.Now().After(deadline) {
+				m.Stop()
+				t.Fatalf("execução %d: nenhuma leitura chegou ao cache", i)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+
+		m.Stop()
+		tagCache.has(2, true)
+
+		if tracked := m.TrackedGoroutines(); tracked != 0 {
+			t.Errorf("execução %d: %d goroutines rastreadas após o Stop, esperado 0", i, tracked)
+		}
+	}
+
+	// As conexões do simulador terminam de forma assíncrona após o Close
+	if after := waitGoroutines(baseline, 2*time.Second); after > baseline {
+		buf := make([]byte, 1<<16)
+		t.Errorf("goroutines: %d antes, %d após 5 ciclos de Start/Stop\n%s", baseline, after, buf[:runtime.Stack(buf, true)])
+	}
+}
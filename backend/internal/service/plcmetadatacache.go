@@ -0,0 +1,50 @@
+// internal/service/plcmetadatacache.go
+package service
+
+import (
+	"app_padrao/internal/domain"
+	"app_padrao/pkg/lrucache"
+	"time"
+)
+
+const (
+	defaultPLCMetadataCacheSize = 1000
+	defaultPLCMetadataCacheTTL  = 60 * time.Second
+)
+
+// newPLCMetadataCache cria o cache em memória dos PLCs consultados por GetByID
+func newPLCMetadataCache(size, ttlSec int) *lrucache.Cache[int, domain.PLC] {
+	return lrucache.New[int, domain.PLC](size, time.Duration(ttlSec)*time.Second)
+}
+
+// invalidatePLCMetadata remove o PLC do cache em memória
+func (s *PLCService) invalidatePLCMetadata(plcID int) {
+	if s.plcMetaCache != nil {
+		s.plcMetaCache.Delete(plcID)
+	}
+}
+
+// handlePLCStatusChange descarta o PLC do cache em memória quando o status
+// muda, para que GetByID não devolva o status anterior até o TTL expirar
+func (s *PLCService) handlePLCStatusChange(plcID int) {
+	s.invalidatePLCMetadata(plcID)
+	s.syncService.NotifyPLCStatusChange(plcID)
+}
+
+// GetPLCMetadataCacheStats retorna o tamanho e a taxa de acerto do cache em
+// memória dos PLCs
+func (s *PLCService) GetPLCMetadataCacheStats() domain.PLCMetadataCacheStats {
+	if s.plcMetaCache == nil {
+		return domain.PLCMetadataCacheStats{}
+	}
+
+	stats := s.plcMetaCache.Stats()
+	return domain.PLCMetadataCacheStats{
+		Size:     stats.Size,
+		Capacity: stats.Capacity,
+		TTLSec:   int(stats.TTL / time.Second),
+		Hits:     stats.Hits,
+		Misses:   stats.Misses,
+		HitRate:  stats.HitRate,
+	}
+}
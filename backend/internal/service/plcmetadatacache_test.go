@@ -0,0 +1,24 @@
+package service
+
+import (
+	"app_padrao/internal/domain"
+	"testing"
+)
+
+func TestStatusChangeInvalidatesPLCMetadata(t *testing.T) {
+	s := &PLCService{
+		plcMetaCache: newPLCMetadataCache(10, 60),
+		syncService:  NewPLCSyncService(&fakeSyncPLCRepo{}, nil, nil, nil, false),
+	}
+	s.plcMetaCache.Set(1, domain.PLC{ID: 1, Status: "online"})
+	s.plcMetaCache.Set(2, domain.PLC{ID: 2, Status: "online"})
+
+	s.handlePLCStatusChange(1)
+
+	if _, ok := s.plcMetaCache.Get(1); ok {
+		t.Error("PLC 1 deveria ter saído do cache após a mudança de status")
+	}
+	if _, ok := s.plcMetaCache.Get(2); !ok {
+		t.Error("PLC 2 não deveria ter saído do cache")
+	}
+}
@@ -0,0 +1,165 @@
+// internal/service/plcperformance.go
+package service
+
+import (
+	"app_padrao/internal/domain"
+	"app_padrao/pkg/plc"
+	"sort"
+	"sync"
+	"time"
+)
+
+// readLatencySamples é o tamanho do buffer circular de latências por PLC
+const readLatencySamples = 1000
+
+// readPerformance acumula latência e volume das leituras de um PLC
+type readPerformance struct {
+	mu         sync.Mutex
+	samples    [readLatencySamples]float64 // latências em ms (buffer circular)
+	next       int
+	filled     int
+	reads      int64
+	bytesTotal int64
+	since      time.Time
+}
+
+// readPerformanceSnapshot é um resumo calculado a partir do buffer
+type readPerformanceSnapshot struct {
+	P50Ms          float64
+	P95Ms          float64
+	P99Ms          float64
+	ReadsPerSecond float64
+	BytesReadTotal int64
+}
+
+func newReadPerformance() *readPerformance {
+	return &readPerformance{since: time.Now()}
+}
+
+// record registra a duração de uma leitura; bytes só conta leituras bem-sucedidas
+func (p *readPerformance) record(d time.Duration, bytes int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.samples[p.next] = float64(d.Microseconds()) / 1000.0
+	p.next = (p.next + 1) % readLatencySamples
+	if p.filled < readLatencySamples {
+		p.filled++
+	}
+	p.reads++
+	p.bytesTotal += int64(bytes)
+}
+
+// reset zera o buffer e os contadores
+func (p *readPerformance) reset() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.next = 0
+	p.filled = 0
+	p.reads = 0
+	p.bytesTotal = 0
+	p.since = time.Now()
+}
+
+// snapshot calcula os percentis ordenando uma cópia das amostras
+func (p *readPerformance) snapshot() readPerformanceSnapshot {
+	p.mu.Lock()
+	sorted := make([]float64, p.filled)
+	copy(sorted, p.samples[:p.filled])
+	reads := p.reads
+	bytesTotal := p.bytesTotal
+	elapsed := time.Since(p.since).Seconds()
+	p.mu.Unlock()
+
+	sort.Float64s(sorted)
+
+	snap := readPerformanceSnapshot{
+		P50Ms:          percentile(sorted, 50),
+		P95Ms:          percentile(sorted, 95),
+		P99Ms:          percentile(sorted, 99),
+		BytesReadTotal: bytesTotal,
+	}
+	if elapsed > 0 {
+		snap.ReadsPerSecond = float64(reads) / elapsed
+	}
+	return snap
+}
+
+// percentile usa o método do posto mais próximo sobre valores já ordenados
+func percentile(sorted []float64, p int) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	idx := (p*len(sorted)+99)/100 - 1
+	if idx < 0 {
+		idx = 0
+	}
+	if idx >= len(sorted) {
+		idx = len(sorted) - 1
+	}
+	return sorted[idx]
+}
+
+// readPerformanceFor retorna (criando se preciso) o acumulador do PLC
+func (m *PLCManager) readPerformanceFor(plcID int) *readPerformance {
+	m.readPerfMu.Lock()
+	defer m.readPerfMu.Unlock()
+
+	perf, ok := m.readPerf[plcID]
+	if !ok {
+		perf = newReadPerformance()
+		m.readPerf[plcID] = perf
+	}
+	return perf
+}
+
+// recordRead registra uma chamada a ReadTag do PLC
+func (m *PLCManager) recordRead(plcID int, dataType string, d time.Duration, err error) {
+	bytes := 0
+	if err == nil {
+		bytes, _ = plc.DataTypeSize(dataType)
+	}
+	m.readPerformanceFor(plcID).record(d, bytes)
+}
+
+// applyReadPerformance preenche os campos de desempenho das estatísticas do PLC
+func (m *PLCManager) applyReadPerformance(stats *PLCConnectionStats) {
+	snap := m.readPerformanceFor(stats.PLCID).snapshot()
+	stats.ReadLatencyP50Ms = snap.P50Ms
+	stats.ReadLatencyP95Ms = snap.P95Ms
+	stats.ReadLatencyP99Ms = snap.P99Ms
+	stats.ReadsPerSecond = snap.ReadsPerSecond
+	stats.BytesReadTotal = snap.BytesReadTotal
+}
+
+// GetPLCPerformance retorna as estatísticas de leitura de um PLC, com os
+// percentis calculados no momento. Com reset, zera o buffer e os contadores
+// depois de montar a resposta.
+func (s *PLCService) GetPLCPerformance(plcID int, reset bool) (domain.PLCConnectionStats, error) {
+	plcConfig, err := s.GetByID(plcID)
+	if err != nil {
+		return domain.PLCConnectionStats{}, err
+	}
+
+	result := domain.PLCConnectionStats{
+		PLCID:  plcID,
+		Name:   plcConfig.Name,
+		Status: "offline",
+	}
+	if s.manager == nil {
+		return result, nil
+	}
+
+	stats, ok := s.manager.GetStats().ConnectionStats[plcID]
+	if !ok {
+		stats = PLCConnectionStats{PLCID: plcID, Name: plcConfig.Name, Status: "offline"}
+	}
+	s.manager.applyReadPerformance(&stats)
+
+	if reset {
+		s.manager.readPerformanceFor(plcID).reset()
+	}
+
+	return toDomainConnectionStats(stats), nil
+}
@@ -0,0 +1,31 @@
+// internal/service/plcrediskeys.go
+package service
+
+import (
+	"app_padrao/internal/cache"
+	"context"
+	"errors"
+	"log"
+)
+
+// ErrRedisKeyPrefixNotSet indica que não há namespace para onde migrar
+var ErrRedisKeyPrefixNotSet = errors.New("REDIS_KEY_PREFIX não configurado")
+
+// MigrateRedisKeys move as chaves gravadas sem namespace (plc:*, plctag:*,
+// tagvalue:* etc.) para o prefixo configurado em REDIS_KEY_PREFIX
+func (s *PLCService) MigrateRedisKeys() (int, int, error) {
+	if s.cfg().RedisKeyPrefix == "" {
+		return 0, 0, ErrRedisKeyPrefixNotSet
+	}
+
+	client := s.cache.GetRedisClient()
+	if client == nil {
+		return 0, 0, cache.ErrRedisNotConnected
+	}
+
+	result, err := cache.MigrateKeyNamespace(context.Background(), client, s.cfg().RedisKeyPrefix, cache.LegacyKeyPatterns)
+	log.Printf("Migração de chaves Redis para o prefixo %q: %d de %d chaves migradas",
+		s.cfg().RedisKeyPrefix, result.Migrated, result.Total)
+
+	return result.Migrated, result.Total, err
+}
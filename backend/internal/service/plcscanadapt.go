@@ -0,0 +1,186 @@
+// internal/service/plcscanadapt.go
+package service
+
+import (
+	"app_padrao/internal/domain"
+	"context"
+	"fmt"
+	"log"
+	"time"
+)
+
+const (
+	// scanRateAdaptWindow é o período do histórico analisado
+	scanRateAdaptWindow = 24 * time.Hour
+	// scanRateAdaptInterval é o intervalo da adaptação automática
+	scanRateAdaptInterval = 7 * 24 * time.Hour
+)
+
+// SuggestScanRates analisa o histórico das últimas 24 horas das tags do PLC e
+// sugere scan rate = min(intervalo médio entre mudanças / 2, taxa atual * 10),
+// arredondada para 100 ms e limitada a domain.MaxSuggestedScanRate. Tags sem
+// histórico no período ou já na taxa sugerida não são incluídas.
+func (s *PLCService) SuggestScanRates(plcID int) ([]domain.ScanRateSuggestion, error) {
+	if s.historyRepo == nil {
+		return nil, ErrHistoryNotConfigured
+	}
+
+	tags, err := s.GetPLCTags(plcID)
+	if err != nil {
+		return nil, err
+	}
+
+	to := time.Now()
+	stats, err := s.historyRepo.GetChangeStats(plcID, to.Add(-scanRateAdaptWindow), to)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao analisar histórico do PLC %d: %w", plcID, err)
+	}
+
+	statsByTag := make(map[int]domain.TagChangeStats, len(stats))
+	for _, st := range stats {
+		statsByTag[st.TagID] = st
+	}
+
+	suggestions := []domain.ScanRateSuggestion{}
+	for _, tag := range tags {
+		st, ok := statsByTag[tag.ID]
+		if !ok || st.Samples == 0 {
+			continue
+		}
+
+		current := tag.ScanRate
+		if current <= 0 {
+			current = s.cfg().DefaultTagScanRate
+		}
+
+		interval := scanRateAdaptWindow
+		reason := fmt.Sprintf("no_change_in_%ds", int(scanRateAdaptWindow.Seconds()))
+		if st.Changes > 0 {
+			interval = scanRateAdaptWindow / time.Duration(st.Changes)
+			reason = fmt.Sprintf("avg_change_interval_%ds", int(interval.Seconds()))
+		}
+
+		suggested := adaptedScanRate(current, interval)
+		if suggested == tag.ScanRate {
+			continue
+		}
+
+		suggestions = append(suggestions, domain.ScanRateSuggestion{
+			TagID:         tag.ID,
+			CurrentRate:   tag.ScanRate,
+			SuggestedRate: suggested,
+			Reason:        reason,
+		})
+	}
+
+	return suggestions, nil
+}
+
+// adaptedScanRate calcula a taxa sugerida a partir da taxa atual e do
+// intervalo médio entre mudanças
+func adaptedScanRate(current int, changeInterval time.Duration) int {
+	rate := int(changeInterval.Milliseconds() / 2)
+	if limit := current * 10; rate > limit {
+		rate = limit
+	}
+
+	// Arredondar para o múltiplo de 100 ms mais próximo
+	rate = (rate + 50) / 100 * 100
+	if rate < 100 {
+		rate = 100
+	}
+	if rate > domain.MaxSuggestedScanRate {
+		rate = domain.MaxSuggestedScanRate
+	}
+	return rate
+}
+
+// AdaptScanRates aplica as taxas sugeridas por SuggestScanRates em uma única
+// transação e retorna as sugestões aplicadas
+func (s *PLCService) AdaptScanRates(plcID, userID int) ([]domain.ScanRateSuggestion, error) {
+	suggestions, err := s.SuggestScanRates(plcID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(suggestions) == 0 {
+		return suggestions, nil
+	}
+
+	rates := make(map[int]int, len(suggestions))
+	for _, suggestion := range suggestions {
+		rates[suggestion.TagID] = suggestion.SuggestedRate
+	}
+
+	if err := s.pgTagRepo.UpdateScanRates(rates); err != nil {
+		return nil, fmt.Errorf("erro ao atualizar taxas de leitura no banco de dados: %w", err)
+	}
+
+	for _, suggestion := range suggestions {
+		log.Printf("Auditoria: entity_type=plc_tag entity_id=%d action=adapt_scan_rate user_id=%d plc_id=%d old_scan_rate=%d new_scan_rate=%d reason=%s",
+			suggestion.TagID, userID, plcID, suggestion.CurrentRate, suggestion.SuggestedRate, suggestion.Reason)
+	}
+
+	if s.cfg().CacheEnabled && s.syncService != nil {
+		if err := s.syncService.SyncSpecificPLC(plcID); err != nil {
+			log.Printf("Aviso: erro ao sincronizar tags do PLC %d no Redis: %v", plcID, err)
+		}
+	}
+
+	if s.syncService != nil && s.syncService.IsRunning() {
+		s.syncService.NotifyPLCChange(plcID)
+	}
+
+	return suggestions, nil
+}
+
+// startScanRateAdaptation adapta semanalmente as taxas de leitura de todos os
+// PLCs ativos até StopMonitoring
+func (s *PLCService) startScanRateAdaptation() {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.adaptCancel = cancel
+
+	go func() {
+		ticker := time.NewTicker(scanRateAdaptInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.adaptAllScanRates()
+			}
+		}
+	}()
+
+	log.Printf("Adaptação automática de scan rate ativada (a cada %v)", scanRateAdaptInterval)
+}
+
+// stopScanRateAdaptation interrompe a adaptação automática, se ativa
+func (s *PLCService) stopScanRateAdaptation() {
+	if s.adaptCancel != nil {
+		s.adaptCancel()
+		s.adaptCancel = nil
+	}
+}
+
+// adaptAllScanRates aplica as sugestões a todos os PLCs ativos
+func (s *PLCService) adaptAllScanRates() {
+	plcs, err := s.pgPLCRepo.GetActivePLCs()
+	if err != nil {
+		log.Printf("Erro ao buscar PLCs para adaptação de scan rate: %v", err)
+		return
+	}
+
+	for _, plc := range plcs {
+		applied, err := s.AdaptScanRates(plc.ID, 0)
+		if err != nil {
+			log.Printf("Erro ao adaptar scan rate das tags do PLC %d: %v", plc.ID, err)
+			continue
+		}
+		if len(applied) > 0 {
+			log.Printf("Scan rate adaptado em %d tags do PLC %d", len(applied), plc.ID)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+// internal/service/plcscanbackpressure.go
+package service
+
+import (
+	"app_padrao/internal/domain"
+	"log"
+	"time"
+)
+
+// skipScanTick indica se o disparo do scan deve ser descartado. Cada grupo
+// de scan rate lê suas tags de forma síncrona no próprio monitor, então não
+// há ciclos pendentes acumulados: o atraso aparece como um disparo retido no
+// ticker enquanto o ciclo anterior excedia o scan rate. Esse disparo é
+// descartado para que o próximo ciclo comece no ritmo do ticker, e um grupo
+// lento não afeta os disparos dos demais grupos do mesmo PLC.
+func (m *PLCManager) skipScanTick(tickAt time.Time, rate time.Duration) bool {
+	return time.Since(tickAt) >= rate
+}
+
+// recordScanSkip registra um disparo descartado. consecutive é a quantidade
+// de disparos seguidos descartados pelo grupo; no segundo, o PLC está
+// consistentemente mais lento que o scan rate e um aviso é registrado.
+func (m *PLCManager) recordScanSkip(plcConfig domain.PLC, rate int, consecutive int) {
+	if m.metrics != nil {
+		m.metrics.IncrementCounter("plc.scan.skipped", 1)
+	}
+
+	m.statsMutex.Lock()
+	if connStats, exists := m.stats.ConnectionStats[plcConfig.ID]; exists {
+		connStats.OverrunCount++
+		connStats.LastOverrunAt = time.Now()
+		m.stats.ConnectionStats[plcConfig.ID] = connStats
+	}
+	m.statsMutex.Unlock()
+
+	if consecutive == 2 {
+		log.Printf("Aviso: PLC %d mais lento que o scan rate de %d ms; ciclos de leitura sendo descartados",
+			plcConfig.ID, rate)
+	}
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"app_padrao/internal/domain"
+	"app_padrao/internal/testutil"
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSkipScanTick(t *testing.T) {
+	const rate = 100 * time.Millisecond
+
+	tests := []struct {
+		name    string
+		tickAge time.Duration
+		want    bool
+	}{
+		{"disparo em dia", 0, false},
+		{"disparo retido por menos que o scan rate", rate / 2, false},
+		{"disparo retido por um scan rate inteiro", rate, true},
+		{"disparo retido por vários scan rates", 3 * rate, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewPLCManager(nil, nil, nil)
+			if got := m.skipScanTick(time.Now().Add(-tt.tickAge), rate); got != tt.want {
+				t.Errorf("skipScanTick = %v, esperado %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestTagMonitorSkipsTicksOfSlowPLC roda o monitor contra um PLC que demora
+// mais que o scan rate para responder: os disparos retidos são descartados e
+// contados, sem acumular ciclos de leitura
+func TestTagMonitorSkipsTicksOfSlowPLC(t *testing.T) {
+	sim := testutil.NewS7Simulator(t)
+	sim.SetDB(1, 0, []byte{0x00, 0x07})
+
+	conn := NewPLCConnection(1, sim.Addr(), 0, 1)
+	if err := conn.Connect(); err != nil {
+		t.Fatalf("erro ao conectar ao simulador: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	const rate = 50
+	const delay = 3 * rate * time.Millisecond
+	sim.SetResponseDelay(delay)
+
+	m := NewPLCManager(nil, nil, nil)
+	m.stats.ConnectionStats[1] = PLCConnectionStats{PLCID: 1}
+	tag := domain.PLCTag{ID: 1, PLCID: 1, Name: "Lenta", DBNumber: 1, ByteOffset: 0, DataType: "int", ScanRate: rate, Active: true, ScaleFactor: 1}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	lastValues := &sync.Map{}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		m.startTagMonitor(rate, []domain.PLCTag{tag}, make(chan tagListUpdate), ctx, domain.PLC{ID: 1, Name: "CLP"}, conn, lastValues)
+	}()
+
+	time.Sleep(5 * delay)
+	cancel()
+
+	// Sem ciclos acumulados, o monitor encerra ao fim da leitura em andamento
+	select {
+	case <-done:
+	case <-time.After(2 * delay):
+		t.Fatal("monitor não encerrou após o cancelamento")
+	}
+
+	if v, ok := lastValues.Load(tag.ID); !ok || v != int16(7) {
+		t.Errorf("valor lido = %v, esperado 7", v)
+	}
+
+	m.statsMutex.RLock()
+	stats := m.stats.ConnectionStats[1]
+	m.statsMutex.RUnlock()
+	if stats.OverrunCount == 0 {
+		t.Error("OverrunCount = 0, esperado disparos descartados com o PLC mais lento que o scan rate")
+	}
+	if stats.LastOverrunAt.IsZero() {
+		t.Error("LastOverrunAt não registrado")
+	}
+}
+
+func TestRecordScanSkip(t *testing.T) {
+	m := NewPLCManager(nil, nil, nil)
+	m.stats.ConnectionStats[1] = PLCConnectionStats{PLCID: 1}
+	plcConfig := domain.PLC{ID: 1}
+
+	before := time.Now()
+	for i := 1; i <= 3; i++ {
+		m.recordScanSkip(plcConfig, 100, i)
+	}
+
+	stats := m.stats.ConnectionStats[1]
+	if stats.OverrunCount != 3 {
+		t.Errorf("OverrunCount = %d, esperado 3", stats.OverrunCount)
+	}
+	if stats.LastOverrunAt.Before(before) {
+		t.Errorf("LastOverrunAt = %v, esperado a partir de %v", stats.LastOverrunAt, before)
+	}
+}
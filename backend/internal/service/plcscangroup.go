@@ -0,0 +1,275 @@
+// internal/service/plcscangroup.go
+package service
+
+import (
+	"app_padrao/internal/domain"
+	"app_padrao/pkg/plc"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// ErrScanGroupsNotConfigured indica que o repositório de grupos de varredura não foi definido
+var ErrScanGroupsNotConfigured = errors.New("grupos de varredura não configurados")
+
+// scanGroupRead é o resultado da leitura de uma tag de grupo de varredura
+type scanGroupRead struct {
+	value interface{}
+	err   error
+}
+
+// scanGroupBlock é a faixa de um DB lida de uma vez para as tags de um grupo
+type scanGroupBlock struct {
+	dbNumber int
+	start    int
+	end      int
+	tags     []domain.PLCTag
+}
+
+// alignScanGroupRates faz todas as tags de um grupo de varredura usarem a
+// menor taxa do grupo, para que caiam no mesmo monitor e sejam lidas juntas
+func alignScanGroupRates(tags []domain.PLCTag) {
+	groupRates := make(map[int]int)
+	for _, tag := range tags {
+		if tag.ScanGroupID == nil {
+			continue
+		}
+		if rate, ok := groupRates[*tag.ScanGroupID]; !ok || tag.ScanRate < rate {
+			groupRates[*tag.ScanGroupID] = tag.ScanRate
+		}
+	}
+
+	for i := range tags {
+		if tags[i].ScanGroupID != nil {
+			tags[i].ScanRate = groupRates[*tags[i].ScanGroupID]
+		}
+	}
+}
+
+// readScanGroups lê as tags de grupos de varredura com uma requisição por
+// grupo e DB, cobrindo a faixa de bytes de todas as tags do grupo. Tags sem
+// grupo não são incluídas no resultado e continuam lidas individualmente.
+func (m *PLCManager) readScanGroups(plcConfig domain.PLC, conn *PLCConnection, tags []domain.PLCTag) map[int]scanGroupRead {
+	type blockKey struct{ groupID, dbNumber int }
+	blocks := make(map[blockKey]*scanGroupBlock)
+
+	for _, tag := range tags {
+		if tag.ScanGroupID == nil {
+			continue
+		}
+
+		size, ok := plc.DataTypeSize(tag.DataType)
+		if !ok {
+			continue
+		}
+
+		key := blockKey{*tag.ScanGroupID, tag.DBNumber}
+		block, exists := blocks[key]
+		if !exists {
+			block = &scanGroupBlock{dbNumber: tag.DBNumber, start: tag.ByteOffset, end: tag.ByteOffset + size}
+			blocks[key] = block
+		}
+		if tag.ByteOffset < block.start {
+			block.start = tag.ByteOffset
+		}
+		if tag.ByteOffset+size > block.end {
+			block.end = tag.ByteOffset + size
+		}
+		block.tags = append(block.tags, tag)
+	}
+
+	if len(blocks) == 0 {
+		return nil
+	}
+
+	reads := make(map[int]scanGroupRead)
+	for key, block := range blocks {
+		readStart := time.Now()
+		buf, err := conn.BatchReadDB(block.dbNumber, block.start, block.end-block.start)
+
+		bytesRead := 0
+		if err == nil {
+			bytesRead = len(buf)
+		}
+		m.readPerformanceFor(plcConfig.ID).record(time.Since(readStart), bytesRead)
+
+		if m.enableDetailedLogging.Load() {
+			log.Printf("PLC %d: grupo de varredura %d lido em DB%d.DBB%d (%d bytes, %d tags)",
+				plcConfig.ID, key.groupID, block.dbNumber, block.start, block.end-block.start, len(block.tags))
+		}
+
+		for _, tag := range block.tags {
+			if err != nil {
+				reads[tag.ID] = scanGroupRead{err: err}
+				continue
+			}
+
+			offset := tag.ByteOffset - block.start
+			value, decodeErr := plc.DecodeValue(buf[offset:], tag.DataType, tag.BitOffset)
+			reads[tag.ID] = scanGroupRead{value: value, err: decodeErr}
+		}
+	}
+
+	return reads
+}
+
+// SetScanGroupRepository define onde os grupos de varredura são persistidos
+func (s *PLCService) SetScanGroupRepository(repo domain.PLCScanGroupRepository) {
+	s.scanGroupRepo = repo
+}
+
+// GetScanGroups retorna os grupos de varredura de um PLC
+func (s *PLCService) GetScanGroups(plcID int) ([]domain.PLCScanGroup, error) {
+	if s.scanGroupRepo == nil {
+		return nil, ErrScanGroupsNotConfigured
+	}
+
+	if _, err := s.GetByID(plcID); err != nil {
+		return nil, err
+	}
+
+	return s.scanGroupRepo.GetByPLC(plcID)
+}
+
+// CreateScanGroup cadastra um grupo de varredura vazio no PLC
+func (s *PLCService) CreateScanGroup(group domain.PLCScanGroup, userID int) (int, error) {
+	if s.scanGroupRepo == nil {
+		return 0, ErrScanGroupsNotConfigured
+	}
+
+	group.Name = strings.TrimSpace(group.Name)
+	if err := group.Validate(); err != nil {
+		return 0, err
+	}
+
+	if _, err := s.GetByID(group.PLCID); err != nil {
+		return 0, err
+	}
+
+	id, err := s.scanGroupRepo.Create(group)
+	if err != nil {
+		return 0, fmt.Errorf("erro ao criar grupo de varredura no banco de dados: %w", err)
+	}
+
+	log.Printf("Auditoria: entity_type=plc_scan_group entity_id=%d action=create user_id=%d plc_id=%d name=%q",
+		id, userID, group.PLCID, group.Name)
+	return id, nil
+}
+
+// AssignScanGroupTags substitui as tags do grupo. As tags que saem do grupo
+// voltam a ser lidas individualmente no próximo ciclo de atualização.
+func (s *PLCService) AssignScanGroupTags(groupID int, tagIDs []int, userID int) (domain.PLCScanGroup, error) {
+	if s.scanGroupRepo == nil {
+		return domain.PLCScanGroup{}, ErrScanGroupsNotConfigured
+	}
+
+	tags := make([]domain.PLCTag, 0, len(tagIDs))
+	for _, tagID := range tagIDs {
+		tag, err := s.pgTagRepo.GetByID(tagID)
+		if err != nil {
+			if errors.Is(err, domain.ErrPLCTagNotFound) {
+				return domain.PLCScanGroup{}, fmt.Errorf("%w: tag %d não encontrada", domain.ErrScanGroupTagMismatch, tagID)
+			}
+			return domain.PLCScanGroup{}, fmt.Errorf("erro ao buscar tag %d: %w", tagID, err)
+		}
+		tags = append(tags, tag)
+	}
+	if err := validateScanGroupLayout(tags); err != nil {
+		return domain.PLCScanGroup{}, err
+	}
+
+	if err := s.scanGroupRepo.AssignTags(groupID, tagIDs); err != nil {
+		if errors.Is(err, domain.ErrScanGroupNotFound) || errors.Is(err, domain.ErrScanGroupTagMismatch) {
+			return domain.PLCScanGroup{}, err
+		}
+		return domain.PLCScanGroup{}, fmt.Errorf("erro ao atribuir tags ao grupo de varredura: %w", err)
+	}
+
+	group, err := s.scanGroupRepo.GetByID(groupID)
+	if err != nil {
+		return domain.PLCScanGroup{}, err
+	}
+
+	log.Printf("Auditoria: entity_type=plc_scan_group entity_id=%d action=assign_tags user_id=%d plc_id=%d tags=%v",
+		groupID, userID, group.PLCID, group.TagIDs)
+
+	s.refreshScanGroupPLC(group.PLCID)
+	return group, nil
+}
+
+// validateScanGroupLayout garante que o grupo é lido em uma única requisição:
+// todas as tags no mesmo DB e a faixa de bytes dentro de um PDU. Só assim os
+// valores do grupo são consistentes entre si; uma faixa maior seria dividida
+// em várias leituras. Tags de tipo sem tamanho fixo conhecido são lidas
+// individualmente e não entram na faixa.
+func validateScanGroupLayout(tags []domain.PLCTag) error {
+	dbNumber, start, end := -1, 0, 0
+	for _, tag := range tags {
+		size, ok := plc.DataTypeSize(tag.DataType)
+		if !ok {
+			continue
+		}
+
+		if dbNumber == -1 {
+			dbNumber, start, end = tag.DBNumber, tag.ByteOffset, tag.ByteOffset+size
+			continue
+		}
+		if tag.DBNumber != dbNumber {
+			return fmt.Errorf("%w: DB%d e DB%d", domain.ErrScanGroupSpansDBs, dbNumber, tag.DBNumber)
+		}
+		if tag.ByteOffset < start {
+			start = tag.ByteOffset
+		}
+		if tag.ByteOffset+size > end {
+			end = tag.ByteOffset + size
+		}
+	}
+
+	if span := end - start; span > plc.MinReadPayload {
+		return fmt.Errorf("%w: DB%d.DBB%d a DBB%d ocupa %d bytes (máximo %d)",
+			domain.ErrScanGroupExceedsPDU, dbNumber, start, end-1, span, plc.MinReadPayload)
+	}
+	return nil
+}
+
+// DeleteScanGroup remove um grupo de varredura; suas tags voltam à leitura individual
+func (s *PLCService) DeleteScanGroup(id, userID int) error {
+	if s.scanGroupRepo == nil {
+		return ErrScanGroupsNotConfigured
+	}
+
+	group, err := s.scanGroupRepo.GetByID(id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.scanGroupRepo.Delete(id); err != nil {
+		if errors.Is(err, domain.ErrScanGroupNotFound) {
+			return err
+		}
+		return fmt.Errorf("erro ao excluir grupo de varredura do banco de dados: %w", err)
+	}
+
+	log.Printf("Auditoria: entity_type=plc_scan_group entity_id=%d action=delete user_id=%d plc_id=%d name=%q",
+		id, userID, group.PLCID, group.Name)
+
+	if len(group.TagIDs) > 0 {
+		s.refreshScanGroupPLC(group.PLCID)
+	}
+	return nil
+}
+
+// refreshScanGroupPLC atualiza no Redis as tags do PLC e avisa a sincronização
+func (s *PLCService) refreshScanGroupPLC(plcID int) {
+	if s.cfg().CacheEnabled && s.syncService != nil {
+		if err := s.syncService.SyncSpecificPLC(plcID); err != nil {
+			log.Printf("Aviso: erro ao sincronizar tags do PLC %d no Redis: %v", plcID, err)
+		}
+	}
+
+	if s.syncService != nil && s.syncService.IsRunning() {
+		s.syncService.NotifyPLCChange(plcID)
+	}
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"app_padrao/internal/domain"
+	"app_padrao/pkg/plc"
+	"errors"
+	"testing"
+)
+
+func TestValidateScanGroupLayout(t *testing.T) {
+	tests := []struct {
+		name    string
+		tags    []domain.PLCTag
+		wantErr error
+	}{
+		{"grupo vazio", nil, nil},
+		{
+			"mesmo DB dentro do PDU",
+			[]domain.PLCTag{
+				{DBNumber: 10, ByteOffset: 0, DataType: "real"},
+				{DBNumber: 10, ByteOffset: 4, DataType: "int"},
+				{DBNumber: 10, ByteOffset: 6, DataType: "bool"},
+			},
+			nil,
+		},
+		{
+			"faixa exatamente do tamanho do PDU",
+			[]domain.PLCTag{
+				{DBNumber: 10, ByteOffset: 0, DataType: "byte"},
+				{DBNumber: 10, ByteOffset: plc.MinReadPayload - 4, DataType: "dint"},
+			},
+			nil,
+		},
+		{
+			"dois DBs",
+			[]domain.PLCTag{
+				{DBNumber: 10, ByteOffset: 0, DataType: "real"},
+				{DBNumber: 11, ByteOffset: 0, DataType: "real"},
+			},
+			domain.ErrScanGroupSpansDBs,
+		},
+		{
+			"faixa maior que o PDU",
+			[]domain.PLCTag{
+				{DBNumber: 10, ByteOffset: 0, DataType: "real"},
+				{DBNumber: 10, ByteOffset: plc.MinReadPayload, DataType: "byte"},
+			},
+			domain.ErrScanGroupExceedsPDU,
+		},
+		{
+			"tipo sem tamanho conhecido é ignorado",
+			[]domain.PLCTag{
+				{DBNumber: 10, ByteOffset: 0, DataType: "real"},
+				{DBNumber: 99, ByteOffset: 500, DataType: "desconhecido"},
+			},
+			nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateScanGroupLayout(tt.tags)
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Fatalf("erro inesperado: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("esperado %v, obtido %v", tt.wantErr, err)
+			}
+		})
+	}
+}
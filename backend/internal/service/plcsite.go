@@ -0,0 +1,278 @@
+// internal/service/plcsite.go
+package service
+
+import (
+	"app_padrao/internal/domain"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// ErrSitesNotConfigured indica que o repositório de sites não foi definido
+var ErrSitesNotConfigured = errors.New("sites de PLCs não configurados")
+
+// SetSiteRepository define onde os sites dos PLCs são persistidos
+func (s *PLCService) SetSiteRepository(repo domain.PLCSiteRepository) {
+	s.siteRepo = repo
+}
+
+// applyPLCSite confere se o site do PLC existe e copia para o PLC a pausa de
+// monitoramento do site. PLCs sem site nunca ficam pausados pelo site.
+func (s *PLCService) applyPLCSite(plc *domain.PLC) error {
+	plc.SiteMonitoringEnabled = true
+	if plc.SiteID == nil {
+		return nil
+	}
+	if s.siteRepo == nil {
+		return ErrSitesNotConfigured
+	}
+
+	site, err := s.siteRepo.GetByID(*plc.SiteID)
+	if err != nil {
+		if errors.Is(err, domain.ErrPLCSiteNotFound) {
+			return fmt.Errorf("site com ID %d não encontrado: %w", *plc.SiteID, domain.ErrPLCSiteNotFound)
+		}
+		return fmt.Errorf("erro ao buscar site com ID %d: %w", *plc.SiteID, err)
+	}
+
+	plc.SiteMonitoringEnabled = site.MonitoringEnabled
+	return nil
+}
+
+// GetSites retorna todos os sites
+func (s *PLCService) GetSites() ([]domain.PLCSite, error) {
+	if s.siteRepo == nil {
+		return nil, ErrSitesNotConfigured
+	}
+	return s.siteRepo.GetAll()
+}
+
+// GetSite retorna um site pelo ID
+func (s *PLCService) GetSite(id int) (domain.PLCSite, error) {
+	if s.siteRepo == nil {
+		return domain.PLCSite{}, ErrSitesNotConfigured
+	}
+	return s.siteRepo.GetByID(id)
+}
+
+// CreateSite cadastra um novo site
+func (s *PLCService) CreateSite(site domain.PLCSite) (int, error) {
+	if s.siteRepo == nil {
+		return 0, ErrSitesNotConfigured
+	}
+
+	site.Name = strings.TrimSpace(site.Name)
+	if err := site.Validate(); err != nil {
+		return 0, err
+	}
+
+	id, err := s.siteRepo.Create(site)
+	if err != nil {
+		return 0, fmt.Errorf("erro ao criar site no banco de dados: %w", err)
+	}
+
+	log.Printf("Auditoria: entity_type=plc_site entity_id=%d action=create name=%q", id, site.Name)
+	return id, nil
+}
+
+// UpdateSite altera um site. Pausar o site interrompe na hora o
+// monitoramento de todos os seus PLCs; reabilitar retoma no próximo ciclo.
+func (s *PLCService) UpdateSite(site domain.PLCSite) error {
+	if s.siteRepo == nil {
+		return ErrSitesNotConfigured
+	}
+
+	site.Name = strings.TrimSpace(site.Name)
+	if err := site.Validate(); err != nil {
+		return err
+	}
+
+	existing, err := s.siteRepo.GetByID(site.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.siteRepo.Update(site); err != nil {
+		return fmt.Errorf("erro ao atualizar site no banco de dados: %w", err)
+	}
+
+	if existing.MonitoringEnabled != site.MonitoringEnabled {
+		log.Printf("Auditoria: entity_type=plc_site entity_id=%d field=monitoring_enabled old_value=%t new_value=%t",
+			site.ID, existing.MonitoringEnabled, site.MonitoringEnabled)
+
+		plcs, err := s.GetPLCsBySite(site.ID)
+		if err != nil {
+			return err
+		}
+		s.refreshSitePLCs(plcs, !site.MonitoringEnabled)
+	}
+
+	return nil
+}
+
+// DeleteSite remove um site; seus PLCs ficam sem site
+func (s *PLCService) DeleteSite(id int) error {
+	if s.siteRepo == nil {
+		return ErrSitesNotConfigured
+	}
+
+	plcs, err := s.GetPLCsBySite(id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.siteRepo.Delete(id); err != nil {
+		return err
+	}
+
+	log.Printf("Auditoria: entity_type=plc_site entity_id=%d action=delete plcs=%d", id, len(plcs))
+
+	s.refreshSitePLCs(plcs, false)
+	return nil
+}
+
+// refreshSitePLCs recarrega do PostgreSQL os PLCs afetados por uma mudança
+// no site, atualiza o Redis e avisa a sincronização. Com stop, o
+// monitoramento dos PLCs é interrompido imediatamente.
+func (s *PLCService) refreshSitePLCs(plcs []domain.PLC, stop bool) {
+	for _, plc := range plcs {
+		current, err := s.pgPLCRepo.GetByID(plc.ID)
+		if err != nil {
+			log.Printf("Aviso: erro ao recarregar PLC %d após mudança no site: %v", plc.ID, err)
+			continue
+		}
+
+		s.invalidatePLCMetadata(plc.ID)
+		if s.cfg().CacheEnabled {
+			if err := s.redisPLCRepo.Update(current); err != nil {
+				log.Printf("Aviso: erro ao atualizar PLC %d no Redis: %v", plc.ID, err)
+			}
+		}
+
+		if s.syncService != nil && s.syncService.IsRunning() {
+			s.syncService.NotifyPLCChange(plc.ID)
+		}
+
+		if stop && s.manager != nil {
+			s.manager.StopPLCMonitor(plc.ID)
+		}
+	}
+}
+
+// GetPLCsBySite retorna os PLCs de um site
+func (s *PLCService) GetPLCsBySite(siteID int) ([]domain.PLC, error) {
+	plcs, err := s.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := []domain.PLC{}
+	for _, plc := range plcs {
+		if plc.SiteID != nil && *plc.SiteID == siteID {
+			filtered = append(filtered, plc)
+		}
+	}
+	return filtered, nil
+}
+
+// GetSiteHealth agrega a saúde dos PLCs ativos de um site
+func (s *PLCService) GetSiteHealth(siteID int) (domain.SiteHealth, error) {
+	site, err := s.GetSite(siteID)
+	if err != nil {
+		return domain.SiteHealth{}, err
+	}
+
+	plcs, err := s.GetPLCsBySite(siteID)
+	if err != nil {
+		return domain.SiteHealth{}, err
+	}
+
+	result := domain.SiteHealth{
+		Site: site,
+		PLCs: make(map[int]string),
+	}
+
+	if !site.MonitoringEnabled {
+		for _, plc := range plcs {
+			if plc.Active {
+				result.PLCs[plc.ID] = domain.MonitoringPaused
+			}
+		}
+		result.Status = domain.SiteHealthPaused
+		return result, nil
+	}
+
+	health, err := s.CheckPLCHealth()
+	if err != nil {
+		return domain.SiteHealth{}, err
+	}
+
+	for _, plc := range plcs {
+		if !plc.Active {
+			continue
+		}
+		if !plc.MonitoringEnabled {
+			result.PLCs[plc.ID] = domain.MonitoringPaused
+			continue
+		}
+
+		status, ok := health[plc.ID]
+		if !ok {
+			status = "offline"
+		}
+		result.PLCs[plc.ID] = status
+		result.Total++
+		if status == "online" {
+			result.Online++
+		} else {
+			result.Offline++
+		}
+	}
+
+	switch {
+	case result.Online == result.Total:
+		result.Status = domain.SiteHealthHealthy
+	case result.Online == 0:
+		result.Status = domain.SiteHealthDown
+	default:
+		result.Status = domain.SiteHealthDegraded
+	}
+
+	return result, nil
+}
+
+// siteStats agrupa as conexões por site para as estatísticas do gerenciador
+func (s *PLCService) siteStats(connections map[int]domain.PLCConnectionStats) map[int]domain.PLCSiteStats {
+	if s.siteRepo == nil {
+		return nil
+	}
+
+	sites, err := s.siteRepo.GetAll()
+	if err != nil {
+		log.Printf("Aviso: erro ao buscar sites para as estatísticas: %v", err)
+		return nil
+	}
+
+	stats := make(map[int]domain.PLCSiteStats, len(sites))
+	for _, site := range sites {
+		stats[site.ID] = domain.PLCSiteStats{SiteID: site.ID, Name: site.Name}
+	}
+
+	for _, conn := range connections {
+		if conn.SiteID == nil {
+			continue
+		}
+		siteStats, ok := stats[*conn.SiteID]
+		if !ok {
+			continue
+		}
+		siteStats.PLCs++
+		if conn.Status == "online" {
+			siteStats.Connected++
+		}
+		stats[*conn.SiteID] = siteStats
+	}
+
+	return stats
+}
@@ -0,0 +1,175 @@
+// internal/service/plcstream.go
+package service
+
+import (
+	"app_padrao/internal/domain"
+	"app_padrao/pkg/events"
+	"context"
+	"errors"
+	"log"
+	"time"
+)
+
+// tagStreamBufferSize é quantas leituras aguardam o envio a um cliente de
+// streaming; além disso as leituras são descartadas para aquele cliente
+const tagStreamBufferSize = 256
+
+// Erros do streaming
+var (
+	ErrTooManyStreams    = errors.New("limite de conexões de streaming atingido")
+	ErrStreamAlreadyOpen = errors.New("usuário já possui um streaming de eventos aberto para este PLC")
+)
+
+// eventStreamKey identifica o streaming de eventos de um usuário para um PLC
+// (plcID 0 = todos os PLCs)
+type eventStreamKey struct {
+	userID int
+	plcID  int
+}
+
+// SubscribeTagValues assina as leituras das tags dos PLCs informados, para
+// envio em streaming. O canal é fechado quando ctx termina. Um cliente lento
+// perde leituras em vez de atrasar o monitoramento.
+func (s *PLCService) SubscribeTagValues(ctx context.Context, plcIDs []int) (<-chan domain.TagValue, error) {
+	wanted := make(map[int]bool, len(plcIDs))
+	for _, plcID := range plcIDs {
+		if _, err := s.GetByID(plcID); err != nil {
+			return nil, err
+		}
+		wanted[plcID] = true
+	}
+
+	if s.streamSlots != nil {
+		select {
+		case s.streamSlots <- struct{}{}:
+		default:
+			return nil, ErrTooManyStreams
+		}
+	}
+
+	bus := s.manager.Events()
+	sub := bus.Subscribe(events.TypeTagValueChanged)
+	out := make(chan domain.TagValue, tagStreamBufferSize)
+
+	go func() {
+		defer func() {
+			bus.Unsubscribe(sub)
+			close(out)
+			if s.streamSlots != nil {
+				<-s.streamSlots
+			}
+		}()
+
+		dropped := 0
+		for {
+			select {
+			case <-ctx.Done():
+				if dropped > 0 {
+					log.Printf("Streaming de tags encerrado com %d leituras descartadas (cliente lento)", dropped)
+				}
+				return
+			case event := <-sub:
+				tagEvent, ok := event.(events.TagValueChangedEvent)
+				if !ok || !wanted[tagEvent.PLCID] {
+					continue
+				}
+
+				select {
+				case out <- tagValueFromEvent(tagEvent):
+				default:
+					dropped++
+				}
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+// SubscribePLCEvents assina as mudanças de status e as leituras das tags de um
+// PLC (plcID 0 = todos os PLCs). Cada usuário pode ter apenas um streaming
+// aberto por PLC. O canal é fechado quando ctx termina; um cliente lento
+// perde eventos em vez de atrasar o monitoramento.
+func (s *PLCService) SubscribePLCEvents(ctx context.Context, plcID, userID int) (<-chan domain.PLCEvent, error) {
+	if plcID != 0 {
+		if _, err := s.GetByID(plcID); err != nil {
+			return nil, err
+		}
+	}
+
+	key := eventStreamKey{userID: userID, plcID: plcID}
+	s.eventStreamsMu.Lock()
+	if s.eventStreams[key] {
+		s.eventStreamsMu.Unlock()
+		return nil, ErrStreamAlreadyOpen
+	}
+	if s.streamSlots != nil {
+		select {
+		case s.streamSlots <- struct{}{}:
+		default:
+			s.eventStreamsMu.Unlock()
+			return nil, ErrTooManyStreams
+		}
+	}
+	if s.eventStreams == nil {
+		s.eventStreams = make(map[eventStreamKey]bool)
+	}
+	s.eventStreams[key] = true
+	s.eventStreamsMu.Unlock()
+
+	bus := s.manager.Events()
+	statusSub := bus.Subscribe(events.TypePLCStatusChanged)
+	valueSub := bus.Subscribe(events.TypeTagValueChanged)
+	out := make(chan domain.PLCEvent, tagStreamBufferSize)
+
+	go func() {
+		defer func() {
+			bus.Unsubscribe(statusSub)
+			bus.Unsubscribe(valueSub)
+			close(out)
+			if s.streamSlots != nil {
+				<-s.streamSlots
+			}
+			s.eventStreamsMu.Lock()
+			delete(s.eventStreams, key)
+			s.eventStreamsMu.Unlock()
+		}()
+
+		dropped := 0
+		for {
+			var event domain.PLCEvent
+			select {
+			case <-ctx.Done():
+				if dropped > 0 {
+					log.Printf("Streaming de eventos encerrado com %d eventos descartados (cliente lento)", dropped)
+				}
+				return
+			case ev := <-statusSub:
+				statusEvent, ok := ev.(events.PLCStatusChangedEvent)
+				if !ok || (plcID != 0 && statusEvent.PLCID != plcID) {
+					continue
+				}
+				event = domain.PLCEvent{Type: domain.PLCEventStatus, Data: domain.PLCStatusChange{
+					PLCID:     statusEvent.PLCID,
+					OldStatus: statusEvent.OldStatus,
+					NewStatus: statusEvent.NewStatus,
+					Timestamp: time.Now(),
+				}}
+			case ev := <-valueSub:
+				tagEvent, ok := ev.(events.TagValueChangedEvent)
+				if !ok || (plcID != 0 && tagEvent.PLCID != plcID) {
+					continue
+				}
+				event = domain.PLCEvent{Type: domain.PLCEventTagValue, Data: tagValueFromEvent(tagEvent)}
+			}
+
+			select {
+			case out <- event:
+			default:
+				dropped++
+			}
+		}
+	}()
+
+	return out, nil
+}
@@ -2,20 +2,29 @@
 package service
 
 import (
+	"app_padrao/internal/cache"
 	"app_padrao/internal/domain"
 	"context"
 	"errors"
 	"fmt"
 	"log"
+	"sort"
 	"sync"
 	"time"
+
+	"github.com/go-redis/redis/v8"
 )
 
 var (
-	ErrSyncAlreadyRunning = errors.New("serviço de sincronização já está em execução")
-	ErrSyncNotRunning     = errors.New("serviço de sincronização não está em execução")
+	ErrSyncAlreadyRunning   = errors.New("serviço de sincronização já está em execução")
+	ErrSyncNotRunning       = errors.New("serviço de sincronização não está em execução")
+	ErrSyncIntervalTooShort = errors.New("intervalo de sincronização abaixo do mínimo permitido")
 )
 
+// MinSyncInterval é o menor intervalo aceito entre sincronizações periódicas.
+// Intervalos menores geram carga excessiva no Redis com muitos PLCs e tags.
+const MinSyncInterval = 30 * time.Second
+
 // PLCSyncService gerencia a sincronização entre PostgreSQL e Redis
 type PLCSyncService struct {
 	// Repositórios PostgreSQL (persistência)
@@ -35,11 +44,51 @@ type PLCSyncService struct {
 	isRunning     bool
 	mu            sync.Mutex // Para sincronizar acesso às flags de estado
 
+	// Sinais para a rotina periódica: novo intervalo e sincronização imediata
+	intervalCh chan time.Duration
+	syncNowCh  chan struct{}
+
+	// Cliente Redis dos contadores de versão dos ETags das listagens
+	// (opcional) e namespace das chaves
+	etagClient    *redis.Client
+	etagKeyPrefix string
+
+	// Cliente Redis dos locks de sincronização (opcional) e namespace das chaves
+	lockClient    *redis.Client
+	lockKeyPrefix string
+
 	// Rastreamento de modificações
 	lastSyncTime  time.Time
 	changeTracker *changeTracker
+
+	// Últimos erros de sincronização (buffer circular)
+	errorLog      [syncErrorLogSize]domain.SyncError
+	errorLogCount int
+	errorLogNext  int
+	errorLogMu    sync.Mutex
+
+	// Chamado em segundo plano a cada mudança de PLC ou tag notificada
+	onChange func()
+
+	// Chamado na hora com o ID de cada PLC notificado
+	onPLCChange func(plcID int)
+
+	// Fechado após a primeira sincronização completa bem-sucedida
+	syncReady chan struct{}
+	readyOnce sync.Once
 }
 
+// Locks distribuídos que impedem duas instâncias de sincronizar ao mesmo tempo
+const (
+	SyncFullLockKey        = "plc:sync:full:lock"
+	SyncIncrementalLockKey = "plc:sync:incremental:lock"
+	syncFullLockTTL        = 10 * time.Minute
+	syncIncrementalLockTTL = 2 * time.Minute
+)
+
+// syncErrorLogSize é a quantidade de erros de sincronização mantidos em memória
+const syncErrorLogSize = 100
+
 // changeTracker rastreia mudanças para sincronização incremental
 type changeTracker struct {
 	plcModifications map[int]time.Time
@@ -97,6 +146,14 @@ func (ct *changeTracker) getModifiedTags(since time.Time) []int {
 	return modified
 }
 
+// clear descarta todas as modificações rastreadas
+func (ct *changeTracker) clear() {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+	ct.plcModifications = make(map[int]time.Time)
+	ct.tagModifications = make(map[int]time.Time)
+}
+
 // NewPLCSyncService cria um novo serviço de sincronização
 func NewPLCSyncService(
 	pgPLCRepo domain.PLCRepository,
@@ -115,9 +172,28 @@ func NewPLCSyncService(
 		isRunning:     false,
 		lastSyncTime:  time.Now(),
 		changeTracker: newChangeTracker(),
+		intervalCh:    make(chan time.Duration, 1),
+		syncNowCh:     make(chan struct{}, 1),
 	}
 }
 
+// SetReadyChannel define o canal fechado quando a sincronização inicial termina
+func (s *PLCSyncService) SetReadyChannel(ch chan struct{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.syncReady = ch
+}
+
+// markReady fecha o canal de sincronização inicial (apenas uma vez)
+func (s *PLCSyncService) markReady() {
+	if s.syncReady == nil {
+		return
+	}
+	s.readyOnce.Do(func() {
+		close(s.syncReady)
+	})
+}
+
 // Start inicia o serviço de sincronização
 func (s *PLCSyncService) Start() error {
 	s.mu.Lock()
@@ -131,51 +207,130 @@ func (s *PLCSyncService) Start() error {
 	s.ctx = ctx
 	s.cancel = cancel
 	s.isRunning = true
+	interval := s.syncInterval
+	initialImport := s.initialImport
+
+	// A importação inicial roda sem s.mu: withSyncLock e os setters do
+	// serviço também o travam
+	s.mu.Unlock()
 
 	log.Println("Iniciando serviço de sincronização PostgreSQL -> Redis")
 
 	// Fazer importação inicial se necessário
-	if s.initialImport {
-		if err := s.performFullSync(); err != nil {
-			log.Printf("Erro na sincronização inicial: %v", err)
-			s.cancel()
-			s.isRunning = false
-			return fmt.Errorf("erro na sincronização inicial: %w", err)
-		}
+	var importErr error
+	if initialImport {
+		importErr = s.performFullSync()
+	}
+
+	s.mu.Lock()
+	if importErr != nil {
+		log.Printf("Erro na sincronização inicial: %v", importErr)
+		cancel()
+		s.isRunning = false
+		return fmt.Errorf("erro na sincronização inicial: %w", importErr)
 	}
+	s.markReady()
 
 	// Iniciar rotina de sincronização periódica
 	s.wg.Add(1)
-	go func() {
-		defer s.wg.Done()
-		ticker := time.NewTicker(s.syncInterval)
-		defer ticker.Stop()
+	go s.runSyncLoop(ctx, interval)
 
-		for {
+	return nil
+}
+
+// runSyncLoop executa a sincronização incremental periodicamente. Usa um timer
+// que é rearmado a cada execução para que mudanças de intervalo e pedidos de
+// sincronização imediata tenham efeito sem reiniciar o serviço.
+func (s *PLCSyncService) runSyncLoop(ctx context.Context, interval time.Duration) {
+	defer s.wg.Done()
+
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
+
+	// resetTimer rearma o timer descartando um disparo pendente
+	resetTimer := func(d time.Duration) {
+		if !timer.Stop() {
 			select {
-			case <-s.ctx.Done():
-				log.Println("Serviço de sincronização encerrado")
-				return
-			case <-ticker.C:
-				if err := s.performIncrementalSync(); err != nil {
-					log.Printf("Erro na sincronização periódica: %v", err)
-				}
+			case <-timer.C:
+			default:
 			}
 		}
-	}()
+		timer.Reset(d)
+	}
 
-	return nil
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("Serviço de sincronização encerrado")
+			return
+
+		case newInterval := <-s.intervalCh:
+			interval = newInterval
+			resetTimer(interval)
+			log.Printf("Rotina de sincronização reprogramada para cada %v", interval)
+
+		case <-s.syncNowCh:
+			if err := s.performIncrementalSync(); err != nil {
+				log.Printf("Erro na sincronização solicitada: %v", err)
+			}
+			resetTimer(interval)
+
+		case <-timer.C:
+			if err := s.performIncrementalSync(); err != nil {
+				log.Printf("Erro na sincronização periódica: %v", err)
+			}
+			timer.Reset(interval)
+		}
+	}
 }
 
-// SetSyncInterval configura o intervalo de sincronização
-func (s *PLCSyncService) SetSyncInterval(interval time.Duration) {
-	if interval < time.Second {
-		interval = time.Second
+// SetSyncInterval configura o intervalo de sincronização. O mínimo é
+// MinSyncInterval (30s); a rotina em execução passa a usar o novo valor imediatamente.
+func (s *PLCSyncService) SetSyncInterval(interval time.Duration) error {
+	if interval < MinSyncInterval {
+		return fmt.Errorf("%w: %v (mínimo %v)", ErrSyncIntervalTooShort, interval, MinSyncInterval)
 	}
+
 	s.mu.Lock()
-	defer s.mu.Unlock()
 	s.syncInterval = interval
+	running := s.isRunning
+	s.mu.Unlock()
+
+	if running {
+		// Manter apenas o valor mais recente no canal
+		select {
+		case <-s.intervalCh:
+		default:
+		}
+		select {
+		case s.intervalCh <- interval:
+		default:
+		}
+	}
+
 	log.Printf("Intervalo de sincronização atualizado para %v", interval)
+	return nil
+}
+
+// GetSyncInterval retorna o intervalo de sincronização configurado
+func (s *PLCSyncService) GetSyncInterval() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.syncInterval
+}
+
+// SyncNow dispara uma sincronização incremental imediata sem esperar o próximo ciclo
+func (s *PLCSyncService) SyncNow() error {
+	if !s.IsRunning() {
+		return ErrSyncNotRunning
+	}
+
+	select {
+	case s.syncNowCh <- struct{}{}:
+	default:
+		// Já existe uma sincronização imediata pendente
+	}
+	return nil
 }
 
 // Stop para o serviço de sincronização
@@ -202,6 +357,20 @@ func (s *PLCSyncService) IsRunning() bool {
 	return s.isRunning
 }
 
+// getLastSyncTime retorna o horário da última sincronização concluída
+func (s *PLCSyncService) getLastSyncTime() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lastSyncTime
+}
+
+// setLastSyncTime registra o fim de uma sincronização
+func (s *PLCSyncService) setLastSyncTime(t time.Time) {
+	s.mu.Lock()
+	s.lastSyncTime = t
+	s.mu.Unlock()
+}
+
 // ForceSync força uma sincronização completa
 func (s *PLCSyncService) ForceSync() error {
 	if !s.IsRunning() {
@@ -213,15 +382,234 @@ func (s *PLCSyncService) ForceSync() error {
 // NotifyPLCChange notifica o serviço sobre uma mudança de PLC
 func (s *PLCSyncService) NotifyPLCChange(plcID int) {
 	s.changeTracker.trackPLCChange(plcID)
+
+	s.mu.Lock()
+	listener := s.onPLCChange
+	s.mu.Unlock()
+	if listener != nil {
+		listener(plcID)
+	}
+
+	s.bumpETagVersions(domain.ETagVersionPLCList, fmt.Sprintf(domain.ETagVersionPLCTagsFormat, plcID))
+	s.runChangeHandler()
 }
 
 // NotifyTagChange notifica o serviço sobre uma mudança de tag
 func (s *PLCSyncService) NotifyTagChange(tagID int) {
 	s.changeTracker.trackTagChange(tagID)
+
+	// Sem a tag no cache (ex.: excluída) não dá para saber o PLC: invalida todos
+	if tag, err := s.redisTagRepo.GetByID(tagID); err == nil {
+		s.bumpETagVersions(fmt.Sprintf(domain.ETagVersionPLCTagsFormat, tag.PLCID))
+	} else {
+		s.bumpETagVersions(domain.ETagVersionAllTags)
+	}
+	s.runChangeHandler()
 }
 
-// performFullSync realiza uma sincronização completa do PostgreSQL para o Redis
+// NotifyPLCStatusChange invalida os ETags da listagem de PLCs, que inclui o
+// status de cada um
+func (s *PLCSyncService) NotifyPLCStatusChange(plcID int) {
+	s.bumpETagVersions(domain.ETagVersionPLCList)
+}
+
+// SetETagClient define o Redis onde os contadores de versão dos ETags das
+// listagens são armazenados, com o namespace da instância
+func (s *PLCSyncService) SetETagClient(client *redis.Client, keyPrefix string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.etagClient = client
+	s.etagKeyPrefix = keyPrefix
+}
+
+// SetLockClient define o Redis usado para os locks distribuídos de
+// sincronização. Sem cliente, a sincronização roda sem lock.
+func (s *PLCSyncService) SetLockClient(client *redis.Client, keyPrefix string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lockClient = client
+	s.lockKeyPrefix = keyPrefix
+}
+
+// withSyncLock executa fn apenas se obtiver o lock informado. Enquanto fn
+// roda, o lock é renovado na metade do TTL para não expirar em
+// sincronizações longas. Se outra instância detiver o lock, o ciclo é pulado.
+func (s *PLCSyncService) withSyncLock(key string, ttl time.Duration, fn func() error) error {
+	s.mu.Lock()
+	client := s.lockClient
+	key = s.lockKeyPrefix + key
+	s.mu.Unlock()
+
+	if client == nil {
+		return fn()
+	}
+
+	lock := cache.NewDistributedLock(client, key)
+	acquired, err := lock.Acquire(ttl)
+	if err != nil {
+		log.Printf("Aviso: erro ao obter lock %s, sincronizando sem lock: %v", key, err)
+		return fn()
+	}
+	if !acquired {
+		log.Printf("Lock %s pertence a outra instância, pulando este ciclo de sincronização", key)
+		return nil
+	}
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(ttl / 2)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := lock.Extend(ttl); err != nil {
+					log.Printf("Aviso: erro ao renovar lock %s: %v", key, err)
+				}
+			}
+		}
+	}()
+
+	defer func() {
+		close(done)
+		if err := lock.Release(); err != nil {
+			log.Printf("Aviso: %v", err)
+		}
+	}()
+
+	return fn()
+}
+
+// GetLockStatus retorna o estado dos locks de sincronização completa e incremental
+func (s *PLCSyncService) GetLockStatus() ([]domain.LockStatus, error) {
+	s.mu.Lock()
+	client := s.lockClient
+	prefix := s.lockKeyPrefix
+	s.mu.Unlock()
+
+	statuses := make([]domain.LockStatus, 0, 2)
+	for _, key := range []string{SyncFullLockKey, SyncIncrementalLockKey} {
+		status, err := cache.GetLockStatus(client, prefix+key)
+		if err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// bumpETagVersions incrementa os contadores de versão informados, o que
+// invalida os ETags guardados com a versão anterior (ver middleware.ETagger)
+func (s *PLCSyncService) bumpETagVersions(keys ...string) {
+	s.mu.Lock()
+	client := s.etagClient
+	prefix := s.etagKeyPrefix
+	s.mu.Unlock()
+
+	if client == nil {
+		return
+	}
+
+	ctx := context.Background()
+	pipe := client.Pipeline()
+	for _, key := range keys {
+		pipe.Incr(ctx, prefix+key)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		log.Printf("Aviso: erro ao invalidar ETags %v: %v", keys, err)
+	}
+}
+
+// SetChangeHandler define a função chamada após cada NotifyPLCChange ou
+// NotifyTagChange
+func (s *PLCSyncService) SetChangeHandler(handler func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onChange = handler
+}
+
+// SetPLCChangeListener define a função chamada, de forma síncrona, com o ID
+// de cada PLC passado a NotifyPLCChange
+func (s *PLCSyncService) SetPLCChangeListener(listener func(plcID int)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onPLCChange = listener
+}
+
+// runChangeHandler executa o handler de mudanças em segundo plano
+func (s *PLCSyncService) runChangeHandler() {
+	s.mu.Lock()
+	handler := s.onChange
+	s.mu.Unlock()
+
+	if handler != nil {
+		go handler()
+	}
+}
+
+// GetChangeSummary retorna os PLCs e tags modificados desde a última sincronização
+func (s *PLCSyncService) GetChangeSummary() domain.SyncChangeSummary {
+	lastSync := s.getLastSyncTime()
+
+	pendingPLCs := s.changeTracker.getModifiedPLCs(lastSync)
+	pendingTags := s.changeTracker.getModifiedTags(lastSync)
+	sort.Ints(pendingPLCs)
+	sort.Ints(pendingTags)
+
+	return domain.SyncChangeSummary{
+		PendingPLCs:  pendingPLCs,
+		PendingTags:  pendingTags,
+		LastSyncTime: lastSync,
+		IsRunning:    s.IsRunning(),
+	}
+}
+
+// ClearChangeTracker descarta as modificações pendentes. Uso emergencial:
+// mudanças descartadas só chegam ao Redis na próxima sincronização completa.
+func (s *PLCSyncService) ClearChangeTracker() {
+	s.changeTracker.clear()
+	log.Println("Rastreador de mudanças da sincronização foi limpo")
+}
+
+// recordSyncError guarda um erro no buffer circular, descartando o mais antigo
+func (s *PLCSyncService) recordSyncError(entityType string, entityID int, err error) {
+	s.errorLogMu.Lock()
+	defer s.errorLogMu.Unlock()
+
+	s.errorLog[s.errorLogNext] = domain.SyncError{
+		OccurredAt: time.Now(),
+		EntityType: entityType,
+		EntityID:   entityID,
+		Error:      err.Error(),
+	}
+	s.errorLogNext = (s.errorLogNext + 1) % syncErrorLogSize
+	if s.errorLogCount < syncErrorLogSize {
+		s.errorLogCount++
+	}
+}
+
+// GetSyncErrorLog retorna os últimos erros de sincronização, do mais antigo ao mais recente
+func (s *PLCSyncService) GetSyncErrorLog() []domain.SyncError {
+	s.errorLogMu.Lock()
+	defer s.errorLogMu.Unlock()
+
+	result := make([]domain.SyncError, 0, s.errorLogCount)
+	start := (s.errorLogNext - s.errorLogCount + syncErrorLogSize) % syncErrorLogSize
+	for i := 0; i < s.errorLogCount; i++ {
+		result = append(result, s.errorLog[(start+i)%syncErrorLogSize])
+	}
+	return result
+}
+
+// performFullSync realiza uma sincronização completa do PostgreSQL para o
+// Redis, protegida pelo lock distribuído de sincronização completa
 func (s *PLCSyncService) performFullSync() error {
+	return s.withSyncLock(SyncFullLockKey, syncFullLockTTL, s.runFullSync)
+}
+
+// runFullSync executa a sincronização completa
+func (s *PLCSyncService) runFullSync() error {
 	log.Println("Iniciando sincronização completa PostgreSQL -> Redis")
 	startTime := time.Now()
 
@@ -249,6 +637,7 @@ func (s *PLCSyncService) performFullSync() error {
 			}
 
 			if err != nil {
+				s.recordSyncError("plc", plc.ID, err)
 				syncMutex.Lock()
 				errors = append(errors, fmt.Errorf("erro ao sincronizar PLC %d (%s): %w",
 					plc.ID, plc.Name, err))
@@ -259,6 +648,7 @@ func (s *PLCSyncService) performFullSync() error {
 			// 2. Sincronizar as tags para este PLC
 			tags, err := s.pgTagRepo.GetPLCTags(plc.ID)
 			if err != nil {
+				s.recordSyncError("plc", plc.ID, err)
 				syncMutex.Lock()
 				errors = append(errors, fmt.Errorf("erro ao buscar tags do PLC %d: %w",
 					plc.ID, err))
@@ -274,6 +664,7 @@ func (s *PLCSyncService) performFullSync() error {
 				}
 
 				if err != nil {
+					s.recordSyncError("tag", tag.ID, err)
 					syncMutex.Lock()
 					errors = append(errors, fmt.Errorf("erro ao sincronizar tag %d (%s): %w",
 						tag.ID, tag.Name, err))
@@ -292,7 +683,7 @@ func (s *PLCSyncService) performFullSync() error {
 	wg.Wait()
 
 	// Atualizar timestamp da última sincronização
-	s.lastSyncTime = time.Now()
+	s.setLastSyncTime(time.Now())
 
 	// Reportar resultados
 	duration := time.Since(startTime)
@@ -321,14 +712,21 @@ func (s *PLCSyncService) performFullSync() error {
 	return nil
 }
 
-// performIncrementalSync realiza uma sincronização incremental
+// performIncrementalSync realiza uma sincronização incremental, protegida
+// pelo lock distribuído de sincronização incremental
 func (s *PLCSyncService) performIncrementalSync() error {
+	return s.withSyncLock(SyncIncrementalLockKey, syncIncrementalLockTTL, s.runIncrementalSync)
+}
+
+// runIncrementalSync executa a sincronização incremental
+func (s *PLCSyncService) runIncrementalSync() error {
 	log.Println("Iniciando sincronização incremental PostgreSQL -> Redis")
 	startTime := time.Now()
 
 	// Buscar PLCs e tags modificados desde a última sincronização
-	modifiedPLCs := s.changeTracker.getModifiedPLCs(s.lastSyncTime)
-	modifiedTags := s.changeTracker.getModifiedTags(s.lastSyncTime)
+	lastSync := s.getLastSyncTime()
+	modifiedPLCs := s.changeTracker.getModifiedPLCs(lastSync)
+	modifiedTags := s.changeTracker.getModifiedTags(lastSync)
 
 	// Se temos muitas modificações, pode ser mais eficiente fazer uma sincronização completa
 	if len(modifiedPLCs) > 50 || len(modifiedTags) > 200 {
@@ -349,6 +747,7 @@ func (s *PLCSyncService) performIncrementalSync() error {
 			defer wg.Done()
 
 			if err := s.SyncSpecificPLC(id); err != nil {
+				s.recordSyncError("plc", id, err)
 				syncMutex.Lock()
 				errors = append(errors, fmt.Errorf("erro ao sincronizar PLC %d: %w", id, err))
 				syncMutex.Unlock()
@@ -363,6 +762,7 @@ func (s *PLCSyncService) performIncrementalSync() error {
 			defer wg.Done()
 
 			if err := s.SyncSpecificTag(id); err != nil {
+				s.recordSyncError("tag", id, err)
 				syncMutex.Lock()
 				errors = append(errors, fmt.Errorf("erro ao sincronizar tag %d: %w", id, err))
 				syncMutex.Unlock()
@@ -374,7 +774,7 @@ func (s *PLCSyncService) performIncrementalSync() error {
 	wg.Wait()
 
 	// Atualizar timestamp da última sincronização
-	s.lastSyncTime = time.Now()
+	s.setLastSyncTime(time.Now())
 
 	// Reportar resultados
 	duration := time.Since(startTime)
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"app_padrao/internal/domain"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+)
+
+// fakeSyncPLCRepo implementa apenas o GetAll usado pela sincronização completa
+type fakeSyncPLCRepo struct {
+	domain.PLCRepository
+	plcs []domain.PLC
+	err  error
+}
+
+func (r *fakeSyncPLCRepo) GetAll() ([]domain.PLC, error) {
+	return r.plcs, r.err
+}
+
+// startWithin executa Start e falha o teste se ele não retornar a tempo
+func startWithin(t *testing.T, s *PLCSyncService, timeout time.Duration) error {
+	t.Helper()
+
+	done := make(chan error, 1)
+	go func() { done <- s.Start() }()
+
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(timeout):
+		t.Fatal("Start não retornou: importação inicial travada")
+		return nil
+	}
+}
+
+func TestStartWithInitialImport(t *testing.T) {
+	s := NewPLCSyncService(&fakeSyncPLCRepo{}, nil, nil, nil, true)
+	ready := make(chan struct{})
+	s.SetReadyChannel(ready)
+
+	if err := startWithin(t, s, 2*time.Second); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer s.Stop()
+
+	select {
+	case <-ready:
+	default:
+		t.Error("canal de sincronização inicial não foi fechado")
+	}
+	if !s.IsRunning() {
+		t.Error("serviço deveria estar em execução")
+	}
+}
+
+func TestStartInitialImportFailure(t *testing.T) {
+	repoErr := errors.New("banco indisponível")
+	s := NewPLCSyncService(&fakeSyncPLCRepo{err: repoErr}, nil, nil, nil, true)
+
+	err := startWithin(t, s, 2*time.Second)
+	if !errors.Is(err, repoErr) {
+		t.Fatalf("Start = %v, esperado erro envolvendo %v", err, repoErr)
+	}
+	if s.IsRunning() {
+		t.Error("serviço não deveria ficar em execução após falha na importação inicial")
+	}
+}
+
+func TestGetChangeSummaryConcurrentWithSync(t *testing.T) {
+	s := NewPLCSyncService(&fakeSyncPLCRepo{}, nil, nil, nil, false)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			if err := s.performFullSync(); err != nil {
+				t.Errorf("performFullSync: %v", err)
+				return
+			}
+		}
+	}()
+
+	// Com -race, leituras de lastSyncTime sem lock são detectadas aqui
+	for i := 0; i < 100; i++ {
+		s.GetChangeSummary()
+	}
+	<-done
+
+	if summary := s.GetChangeSummary(); summary.LastSyncTime.IsZero() {
+		t.Error("LastSyncTime não deveria ser zero")
+	}
+}
+
+func TestNotifyBumpsPrefixedETagVersions(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	s := NewPLCSyncService(&fakeSyncPLCRepo{}, nil, nil, nil, false)
+	s.SetETagClient(client, "app1:")
+
+	s.NotifyPLCChange(7)
+	s.NotifyPLCStatusChange(7)
+
+	want := map[string]string{
+		"app1:etag:version:plcs":       "2",
+		"app1:etag:version:plc:7:tags": "1",
+	}
+	for key, value := range want {
+		if got, err := mr.Get(key); err != nil || got != value {
+			t.Errorf("%s = %q (%v), esperado %q", key, got, err, value)
+		}
+	}
+	if keys := mr.Keys(); len(keys) != len(want) {
+		t.Errorf("chaves = %v, esperado apenas os contadores de versão", keys)
+	}
+}
@@ -0,0 +1,126 @@
+// internal/service/plctagaccesslog.go
+package service
+
+import (
+	"app_padrao/internal/domain"
+	"context"
+	"errors"
+	"log"
+	"time"
+)
+
+const (
+	tagAccessLogQueueSize     = 10000
+	tagAccessLogFlushInterval = 5 * time.Second
+	defaultTagAccessLogLimit  = 1000
+	maxTagAccessLogLimit      = 10000
+)
+
+// ErrAccessLogNotConfigured indica que o log de acesso às tags está desativado
+var ErrAccessLogNotConfigured = errors.New("log de acesso às tags não configurado")
+
+// SetTagAccessLogRepository habilita o log de acesso às tags. Com
+// EnableAccessLog os acessos são enfileirados e gravados em lote a cada
+// tagAccessLogFlushInterval até StopAccessLog.
+func (s *PLCService) SetTagAccessLogRepository(repo domain.TagAccessLogRepository) {
+	s.accessLogRepo = repo
+	if repo == nil || !s.cfg().EnableAccessLog || s.accessLogQueue != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.accessLogQueue = make(chan domain.TagAccessLog, tagAccessLogQueueSize)
+	s.accessLogCancel = cancel
+	s.accessLogDone = make(chan struct{})
+
+	go s.runAccessLogWriter(ctx)
+}
+
+// StopAccessLog grava os acessos ainda na fila e encerra a gravação
+func (s *PLCService) StopAccessLog() {
+	if s.accessLogCancel == nil {
+		return
+	}
+	s.accessLogCancel()
+	<-s.accessLogDone
+	s.accessLogCancel = nil
+}
+
+// RecordTagAccess enfileira os acessos sem bloquear a requisição. Com a fila
+// cheia os acessos são descartados e contados em plc.access_log.dropped.
+func (s *PLCService) RecordTagAccess(entries []domain.TagAccessLog) {
+	if s.accessLogQueue == nil {
+		return
+	}
+
+	for _, entry := range entries {
+		if entry.AccessedAt.IsZero() {
+			entry.AccessedAt = time.Now()
+		}
+		select {
+		case s.accessLogQueue <- entry:
+		default:
+			if s.manager != nil && s.manager.metrics != nil {
+				s.manager.metrics.IncrementCounter("plc.access_log.dropped", 1)
+			}
+		}
+	}
+}
+
+// GetTagAccessLog consulta o log de acesso às tags
+func (s *PLCService) GetTagAccessLog(filter domain.TagAccessLogFilter) ([]domain.TagAccessLog, error) {
+	if s.accessLogRepo == nil {
+		return nil, ErrAccessLogNotConfigured
+	}
+
+	if filter.Limit <= 0 {
+		filter.Limit = defaultTagAccessLogLimit
+	}
+	if filter.Limit > maxTagAccessLogLimit {
+		filter.Limit = maxTagAccessLogLimit
+	}
+	return s.accessLogRepo.List(filter)
+}
+
+// runAccessLogWriter grava a fila em lotes a cada tagAccessLogFlushInterval
+func (s *PLCService) runAccessLogWriter(ctx context.Context) {
+	defer close(s.accessLogDone)
+
+	ticker := time.NewTicker(tagAccessLogFlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]domain.TagAccessLog, 0, tagAccessLogQueueSize)
+
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := s.accessLogRepo.Insert(batch); err != nil {
+			log.Printf("Erro ao gravar lote de %d acessos às tags: %v", len(batch), err)
+		}
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			// Gravar o que ainda estiver na fila
+			for {
+				select {
+				case entry := <-s.accessLogQueue:
+					batch = append(batch, entry)
+				default:
+					flush()
+					return
+				}
+			}
+		case entry := <-s.accessLogQueue:
+			batch = append(batch, entry)
+			if len(batch) == tagAccessLogQueueSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
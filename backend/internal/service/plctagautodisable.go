@@ -0,0 +1,107 @@
+// internal/service/plctagautodisable.go
+package service
+
+import (
+	"app_padrao/internal/domain"
+	"log"
+	"time"
+)
+
+// defaultConsecutiveErrorThreshold é o padrão de falhas de leitura
+// consecutivas que desativam uma tag
+const defaultConsecutiveErrorThreshold = 10
+
+// disabledTag é uma tag que atingiu o limite de falhas e o último erro lido
+type disabledTag struct {
+	tag domain.PLCTag
+	err error
+}
+
+// autoDisableTags remove as tags desativadas da lista do monitor, descarta
+// seus contadores e persiste a desativação em segundo plano. Retorna a nova
+// lista de tags do monitor.
+func (m *PLCManager) autoDisableTags(plcConfig domain.PLC, tags []domain.PLCTag, disabled []disabledTag, consecutiveErrors map[int]int) []domain.PLCTag {
+	removed := make(map[int]bool, len(disabled))
+	for _, d := range disabled {
+		removed[d.tag.ID] = true
+		delete(consecutiveErrors, d.tag.ID)
+
+		log.Printf("Auditoria: entity_type=plc_tag entity_id=%d action=auto_disabled plc_id=%d tag=%q consecutive_errors=%d last_error=%q",
+			d.tag.ID, plcConfig.ID, d.tag.Name, m.runtimeConfig().ConsecutiveErrorThreshold, d.err.Error())
+
+		if m.metrics != nil {
+			m.metrics.IncrementCounter("plc.tag.auto_disabled", 1)
+		}
+
+		if m.onTagAutoDisabled != nil {
+			d := d
+			m.goTracked(func() {
+				m.onTagAutoDisabled(d.tag, d.err)
+			})
+		}
+	}
+
+	remaining := make([]domain.PLCTag, 0, len(tags)-len(disabled))
+	for _, tag := range tags {
+		if !removed[tag.ID] {
+			remaining = append(remaining, tag)
+		}
+	}
+	return remaining
+}
+
+// persistAutoDisabledTag grava a desativação automática no banco e no Redis e
+// avisa a sincronização, para que os monitores deixem de ler a tag
+func (s *PLCService) persistAutoDisabledTag(tag domain.PLCTag, readErr error) {
+	now := time.Now()
+
+	if err := s.pgTagRepo.SetAutoDisabled(tag.ID, readErr.Error(), now); err != nil {
+		log.Printf("Erro ao desativar automaticamente a tag %d no banco: %v", tag.ID, err)
+		return
+	}
+
+	if s.cfg().CacheEnabled {
+		if err := s.redisTagRepo.SetAutoDisabled(tag.ID, readErr.Error(), now); err != nil {
+			log.Printf("Aviso: erro ao desativar automaticamente a tag %d no Redis: %v", tag.ID, err)
+		}
+	}
+
+	if s.syncService != nil && s.syncService.IsRunning() {
+		s.syncService.NotifyPLCChange(tag.PLCID)
+	}
+}
+
+// GetAutoDisabledTags lista as tags desativadas por falhas consecutivas de
+// leitura, com o último erro
+func (s *PLCService) GetAutoDisabledTags() ([]domain.PLCTag, error) {
+	return s.pgTagRepo.GetAutoDisabled()
+}
+
+// EnableTag devolve à varredura uma tag desativada automaticamente, zerando o
+// registro da desativação. O campo active não é alterado: uma tag desativada
+// pelo operador continua fora da varredura. O contador de falhas recomeça
+// quando a tag volta a ser lida.
+func (s *PLCService) EnableTag(id, userID int) (domain.PLCTag, error) {
+	if err := s.pgTagRepo.ClearAutoDisabled(id); err != nil {
+		return domain.PLCTag{}, err
+	}
+
+	if s.cfg().CacheEnabled {
+		if err := s.redisTagRepo.ClearAutoDisabled(id); err != nil {
+			log.Printf("Aviso: erro ao reativar a tag %d no Redis: %v", id, err)
+		}
+	}
+
+	tag, err := s.pgTagRepo.GetByID(id)
+	if err != nil {
+		return domain.PLCTag{}, err
+	}
+
+	log.Printf("Auditoria: entity_type=plc_tag entity_id=%d action=enable user_id=%d", id, userID)
+
+	if s.syncService != nil && s.syncService.IsRunning() {
+		s.syncService.NotifyPLCChange(tag.PLCID)
+	}
+
+	return tag, nil
+}
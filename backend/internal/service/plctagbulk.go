@@ -0,0 +1,303 @@
+// internal/service/plctagbulk.go
+package service
+
+import (
+	"app_padrao/internal/domain"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// Erros da exclusão em massa de tags
+var (
+	ErrBulkDeleteEmpty       = errors.New("nenhuma tag informada para exclusão")
+	ErrBulkDeleteTooMany     = fmt.Errorf("exclusão em massa limitada a %d tags por chamada", maxBulkDeleteTags)
+	ErrBulkDeleteUnavailable = errors.New("exclusão em massa indisponível sem Redis")
+	ErrInvalidConfirmToken   = errors.New("token de confirmação inválido ou expirado")
+)
+
+const (
+	maxBulkDeleteTags   = 200
+	bulkDeleteTokenTTL  = 60 * time.Second
+	bulkDeleteKeyFormat = "plc:%d:bulk_delete:%s"
+)
+
+// pendingBulkDelete é o que fica salvo no Redis até a confirmação
+type pendingBulkDelete struct {
+	TagIDs []int `json:"tag_ids"`
+}
+
+// normalizeBulkTagIDs valida a lista de IDs e a devolve ordenada e sem repetições
+func normalizeBulkTagIDs(tagIDs []int) ([]int, error) {
+	seen := make(map[int]bool, len(tagIDs))
+	ids := make([]int, 0, len(tagIDs))
+	for _, id := range tagIDs {
+		if id <= 0 || seen[id] {
+			continue
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+
+	if len(ids) == 0 {
+		return nil, ErrBulkDeleteEmpty
+	}
+	if len(ids) > maxBulkDeleteTags {
+		return nil, ErrBulkDeleteTooMany
+	}
+
+	sort.Ints(ids)
+	return ids, nil
+}
+
+// bulkDeleteClient retorna o cliente Redis usado para guardar as confirmações
+func (s *PLCService) bulkDeleteClient() (*redis.Client, error) {
+	if s.cache == nil {
+		return nil, ErrBulkDeleteUnavailable
+	}
+	client := s.cache.GetRedisClient()
+	if client == nil {
+		return nil, ErrBulkDeleteUnavailable
+	}
+	return client, nil
+}
+
+// PrepareBulkTagDelete registra uma exclusão em massa pendente e retorna o
+// token que deve ser reenviado em até 60 segundos para efetivá-la
+func (s *PLCService) PrepareBulkTagDelete(plcID int, tagIDs []int) (domain.BulkTagDeletePreview, error) {
+	ids, err := normalizeBulkTagIDs(tagIDs)
+	if err != nil {
+		return domain.BulkTagDeletePreview{}, err
+	}
+
+	client, err := s.bulkDeleteClient()
+	if err != nil {
+		return domain.BulkTagDeletePreview{}, err
+	}
+
+	if _, err := s.GetByID(plcID); err != nil {
+		return domain.BulkTagDeletePreview{}, err
+	}
+
+	tags, err := s.pgTagRepo.GetPLCTags(plcID)
+	if err != nil {
+		return domain.BulkTagDeletePreview{}, fmt.Errorf("erro ao buscar tags do PLC %d: %w", plcID, err)
+	}
+
+	requested := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		requested[id] = true
+	}
+
+	toDelete := []domain.PLCTag{}
+	for _, tag := range tags {
+		if requested[tag.ID] {
+			toDelete = append(toDelete, tag)
+		}
+	}
+
+	token, err := newRequestID()
+	if err != nil {
+		return domain.BulkTagDeletePreview{}, fmt.Errorf("erro ao gerar token de confirmação: %w", err)
+	}
+
+	data, err := json.Marshal(pendingBulkDelete{TagIDs: ids})
+	if err != nil {
+		return domain.BulkTagDeletePreview{}, err
+	}
+
+	key := s.cfg().RedisKeyPrefix + fmt.Sprintf(bulkDeleteKeyFormat, plcID, token)
+	if err := client.Set(context.Background(), key, data, bulkDeleteTokenTTL).Err(); err != nil {
+		return domain.BulkTagDeletePreview{}, fmt.Errorf("erro ao registrar exclusão pendente: %w", err)
+	}
+
+	return domain.BulkTagDeletePreview{
+		ConfirmToken: token,
+		TagsToDelete: toDelete,
+		ExpiresAt:    time.Now().Add(bulkDeleteTokenTTL),
+	}, nil
+}
+
+// ConfirmBulkTagDelete executa uma exclusão em massa previamente preparada.
+// A lista de tags deve ser a mesma usada para gerar o token. Tags com
+// alarmes ativos só são excluídas com force; sem ele a exclusão é recusada
+// com *domain.ActiveAlarmConflictError e o token continua válido.
+func (s *PLCService) ConfirmBulkTagDelete(plcID int, tagIDs []int, confirmToken string, force bool) (domain.BulkTagDeleteResult, error) {
+	ids, err := normalizeBulkTagIDs(tagIDs)
+	if err != nil {
+		return domain.BulkTagDeleteResult{}, err
+	}
+
+	if !force {
+		alarms, err := s.activeAlarmsForTags(plcID, ids)
+		if err != nil {
+			return domain.BulkTagDeleteResult{}, err
+		}
+		if len(alarms) > 0 {
+			return domain.BulkTagDeleteResult{}, &domain.ActiveAlarmConflictError{Alarms: alarms}
+		}
+	}
+
+	client, err := s.bulkDeleteClient()
+	if err != nil {
+		return domain.BulkTagDeleteResult{}, err
+	}
+
+	// O token só pode ser usado uma vez
+	key := s.cfg().RedisKeyPrefix + fmt.Sprintf(bulkDeleteKeyFormat, plcID, confirmToken)
+	ctx := context.Background()
+	pipe := client.TxPipeline()
+	getCmd := pipe.Get(ctx, key)
+	pipe.Del(ctx, key)
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return domain.BulkTagDeleteResult{}, fmt.Errorf("erro ao ler exclusão pendente: %w", err)
+	}
+
+	data, err := getCmd.Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return domain.BulkTagDeleteResult{}, ErrInvalidConfirmToken
+		}
+		return domain.BulkTagDeleteResult{}, fmt.Errorf("erro ao ler exclusão pendente: %w", err)
+	}
+
+	var pending pendingBulkDelete
+	if err := json.Unmarshal(data, &pending); err != nil || !sameIDs(pending.TagIDs, ids) {
+		return domain.BulkTagDeleteResult{}, ErrInvalidConfirmToken
+	}
+
+	tags, err := s.pgTagRepo.GetPLCTags(plcID)
+	if err != nil {
+		return domain.BulkTagDeleteResult{}, fmt.Errorf("erro ao buscar tags do PLC %d: %w", plcID, err)
+	}
+
+	belongs := make(map[int]bool, len(tags))
+	for _, tag := range tags {
+		belongs[tag.ID] = true
+	}
+
+	// Só exclui tags que pertencem ao PLC da rota
+	candidates := make([]int, 0, len(ids))
+	for _, id := range ids {
+		if belongs[id] {
+			candidates = append(candidates, id)
+		}
+	}
+
+	deleted, err := s.pgTagRepo.DeleteMany(candidates)
+	if err != nil {
+		return domain.BulkTagDeleteResult{}, fmt.Errorf("erro ao excluir tags do banco de dados: %w", err)
+	}
+
+	if s.cfg().CacheEnabled && len(deleted) > 0 {
+		if _, err := s.redisTagRepo.DeleteMany(deleted); err != nil {
+			log.Printf("Aviso: erro ao excluir tags do Redis: %v", err)
+		}
+	}
+
+	if s.syncService != nil && s.syncService.IsRunning() && len(deleted) > 0 {
+		s.syncService.NotifyPLCChange(plcID)
+	}
+
+	wasDeleted := make(map[int]bool, len(deleted))
+	for _, id := range deleted {
+		wasDeleted[id] = true
+	}
+
+	notFound := []int{}
+	for _, id := range ids {
+		if !wasDeleted[id] {
+			notFound = append(notFound, id)
+		}
+	}
+
+	log.Printf("Exclusão em massa no PLC %d: %d tags excluídas, %d não encontradas", plcID, len(deleted), len(notFound))
+
+	return domain.BulkTagDeleteResult{
+		Deleted:  len(deleted),
+		NotFound: notFound,
+	}, nil
+}
+
+// activeAlarmsForTags retorna as ocorrências de alarme ativas das tags do PLC.
+// Sem repositório de alarmes não há o que verificar.
+func (s *PLCService) activeAlarmsForTags(plcID int, tagIDs []int) ([]domain.TagAlarmEvent, error) {
+	if s.alarmRepo == nil {
+		return nil, nil
+	}
+
+	events, err := s.alarmRepo.GetActiveEvents()
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar alarmes ativos: %w", err)
+	}
+
+	requested := make(map[int]bool, len(tagIDs))
+	for _, id := range tagIDs {
+		requested[id] = true
+	}
+
+	affected := []domain.TagAlarmEvent{}
+	for _, event := range events {
+		if event.PLCID == plcID && requested[event.TagID] {
+			affected = append(affected, event)
+		}
+	}
+	return affected, nil
+}
+
+// sameIDs compara duas listas de IDs já ordenadas
+func sameIDs(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// BulkUpdateTags altera scan_rate, active, monitor_changes e/ou can_write de
+// todas as tags do PLC que atendem ao filtro e sincroniza o Redis
+func (s *PLCService) BulkUpdateTags(plcID int, filter domain.TagFilter, patch domain.TagPatch) (int, error) {
+	if patch.IsEmpty() {
+		return 0, domain.ErrEmptyTagPatch
+	}
+
+	if _, err := s.GetByID(plcID); err != nil {
+		return 0, err
+	}
+
+	updated, err := s.pgTagRepo.BulkUpdate(plcID, filter, patch)
+	if err != nil {
+		return 0, fmt.Errorf("erro ao atualizar tags no banco de dados: %w", err)
+	}
+
+	patchJSON, _ := json.Marshal(patch)
+	filterJSON, _ := json.Marshal(filter)
+	log.Printf("Auditoria: entity_type=plc_tag_bulk plc_id=%d filter=%s new_value=%s updated=%d",
+		plcID, filterJSON, patchJSON, updated)
+
+	if updated == 0 {
+		return 0, nil
+	}
+
+	if s.cfg().CacheEnabled && s.syncService != nil {
+		if err := s.syncService.SyncSpecificPLC(plcID); err != nil {
+			log.Printf("Aviso: erro ao sincronizar tags do PLC %d no Redis: %v", plcID, err)
+		}
+	}
+
+	if s.syncService != nil && s.syncService.IsRunning() {
+		s.syncService.NotifyPLCChange(plcID)
+	}
+
+	return updated, nil
+}
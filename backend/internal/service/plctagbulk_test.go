@@ -0,0 +1,59 @@
+package service
+
+import (
+	"app_padrao/internal/domain"
+	"errors"
+	"testing"
+)
+
+// fakeAlarmRepo implementa apenas o GetActiveEvents usado na exclusão em massa
+type fakeAlarmRepo struct {
+	domain.TagAlarmRepository
+	events []domain.TagAlarmEvent
+}
+
+func (r *fakeAlarmRepo) GetActiveEvents() ([]domain.TagAlarmEvent, error) {
+	return r.events, nil
+}
+
+func TestConfirmBulkTagDeleteActiveAlarms(t *testing.T) {
+	s := &PLCService{alarmRepo: &fakeAlarmRepo{events: []domain.TagAlarmEvent{
+		{ID: 1, PLCID: 1, TagID: 10, AlarmName: "nível alto"},
+		{ID: 2, PLCID: 2, TagID: 11, AlarmName: "outro PLC"},
+		{ID: 3, PLCID: 1, TagID: 99, AlarmName: "tag fora da exclusão"},
+	}}}
+
+	_, err := s.ConfirmBulkTagDelete(1, []int{10, 11}, "token", false)
+
+	var conflictErr *domain.ActiveAlarmConflictError
+	if !errors.As(err, &conflictErr) {
+		t.Fatalf("erro = %v, esperado *domain.ActiveAlarmConflictError", err)
+	}
+	if !errors.Is(err, domain.ErrTagsHaveActiveAlarms) {
+		t.Error("o erro deveria envolver domain.ErrTagsHaveActiveAlarms")
+	}
+	if len(conflictErr.Alarms) != 1 || conflictErr.Alarms[0].ID != 1 {
+		t.Errorf("alarmes = %+v, esperado apenas a ocorrência 1", conflictErr.Alarms)
+	}
+}
+
+func TestConfirmBulkTagDeleteForceSkipsAlarmCheck(t *testing.T) {
+	s := &PLCService{alarmRepo: &fakeAlarmRepo{events: []domain.TagAlarmEvent{
+		{ID: 1, PLCID: 1, TagID: 10},
+	}}}
+
+	// Com force a verificação é pulada; sem Redis a exclusão para em seguida
+	_, err := s.ConfirmBulkTagDelete(1, []int{10}, "token", true)
+	if !errors.Is(err, ErrBulkDeleteUnavailable) {
+		t.Fatalf("erro = %v, esperado %v", err, ErrBulkDeleteUnavailable)
+	}
+}
+
+func TestConfirmBulkTagDeleteWithoutAlarmRepository(t *testing.T) {
+	s := &PLCService{}
+
+	_, err := s.ConfirmBulkTagDelete(1, []int{10}, "token", false)
+	if !errors.Is(err, ErrBulkDeleteUnavailable) {
+		t.Fatalf("erro = %v, esperado %v", err, ErrBulkDeleteUnavailable)
+	}
+}
@@ -0,0 +1,133 @@
+// internal/service/plctagconflicts.go
+package service
+
+import (
+	"app_padrao/internal/domain"
+	"app_padrao/pkg/plc"
+	"sort"
+)
+
+// tagByteRange retorna o intervalo [início, fim) de bytes lido para a tag.
+// Tipos desconhecidos são tratados como word, como na leitura do PLC.
+func tagByteRange(tag domain.PLCTag) (int, int) {
+	size, ok := plc.DataTypeSize(tag.DataType)
+	if !ok {
+		size = 2
+	}
+	return tag.ByteOffset, tag.ByteOffset + size
+}
+
+// findAddressConflicts procura endereços sobrepostos entre as tags de um PLC.
+// Tags bool só conflitam entre si no mesmo bit; as demais são comparadas pelo
+// intervalo de bytes com uma varredura ordenada por DB e byte offset.
+func findAddressConflicts(tags []domain.PLCTag) []domain.AddressConflict {
+	sorted := make([]domain.PLCTag, len(tags))
+	copy(sorted, tags)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].DBNumber != sorted[j].DBNumber {
+			return sorted[i].DBNumber < sorted[j].DBNumber
+		}
+		if sorted[i].ByteOffset != sorted[j].ByteOffset {
+			return sorted[i].ByteOffset < sorted[j].ByteOffset
+		}
+		return sorted[i].BitOffset < sorted[j].BitOffset
+	})
+
+	var conflicts []domain.AddressConflict
+
+	// Bools com o mesmo DB/byte/bit
+	type bitAddress struct{ db, byteOffset, bit int }
+	bools := make(map[bitAddress]domain.PLCTag)
+
+	// Intervalos abertos da varredura (apenas do DB atual)
+	var active []domain.PLCTag
+	currentDB := -1
+
+	for _, tag := range sorted {
+		if tag.DataType == "bool" {
+			addr := bitAddress{tag.DBNumber, tag.ByteOffset, tag.BitOffset}
+			if other, exists := bools[addr]; exists {
+				conflicts = append(conflicts, domain.AddressConflict{
+					Tag1: other, Tag2: tag, ConflictType: domain.ConflictSameAddress,
+				})
+				continue
+			}
+			bools[addr] = tag
+			continue
+		}
+
+		if tag.DBNumber != currentDB {
+			currentDB = tag.DBNumber
+			active = active[:0]
+		}
+
+		start, end := tagByteRange(tag)
+
+		// Descartar intervalos que terminam antes do início desta tag
+		open := active[:0]
+		for _, other := range active {
+			if _, otherEnd := tagByteRange(other); otherEnd > start {
+				open = append(open, other)
+			}
+		}
+		active = open
+
+		for _, other := range active {
+			otherStart, otherEnd := tagByteRange(other)
+			conflictType := domain.ConflictOverlappingBytes
+			if otherStart == start && otherEnd == end {
+				conflictType = domain.ConflictSameAddress
+			}
+			conflicts = append(conflicts, domain.AddressConflict{
+				Tag1: other, Tag2: tag, ConflictType: conflictType,
+			})
+		}
+
+		active = append(active, tag)
+	}
+
+	return conflicts
+}
+
+// DetectAddressConflicts lista as tags de um PLC cujos endereços se sobrepõem
+func (s *PLCService) DetectAddressConflicts(plcID int) ([]domain.AddressConflict, error) {
+	tags, err := s.GetPLCTags(plcID)
+	if err != nil {
+		return nil, err
+	}
+
+	conflicts := findAddressConflicts(tags)
+	if conflicts == nil {
+		conflicts = []domain.AddressConflict{}
+	}
+	return conflicts, nil
+}
+
+// checkTagConflicts verifica se a tag, criada ou alterada, sobrepõe o
+// endereço de outra tag do mesmo PLC
+func (s *PLCService) checkTagConflicts(tag domain.PLCTag) error {
+	tags, err := s.GetPLCTags(tag.PLCID)
+	if err != nil {
+		return err
+	}
+
+	candidates := make([]domain.PLCTag, 0, len(tags)+1)
+	for _, existing := range tags {
+		if existing.ID != tag.ID {
+			candidates = append(candidates, existing)
+		}
+	}
+	candidates = append(candidates, tag)
+
+	var own []domain.AddressConflict
+	for _, conflict := range findAddressConflicts(candidates) {
+		if conflict.Tag1.ID == tag.ID || conflict.Tag2.ID == tag.ID {
+			own = append(own, conflict)
+		}
+	}
+
+	if len(own) > 0 {
+		return &domain.AddressConflictError{Conflicts: own}
+	}
+	return nil
+}
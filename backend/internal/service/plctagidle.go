@@ -0,0 +1,77 @@
+// internal/service/plctagidle.go
+package service
+
+import (
+	"app_padrao/internal/domain"
+	"fmt"
+	"log"
+	"time"
+)
+
+// GetIdleTags lista as tags sem mudança de valor há mais de since, das mais
+// caras (menor scan_rate) para as mais baratas, com a taxa sugerida
+func (s *PLCService) GetIdleTags(since time.Duration) ([]domain.IdleTag, error) {
+	tags, err := s.pgTagRepo.GetIdleTags(since)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar tags ociosas: %w", err)
+	}
+
+	idle := make([]domain.IdleTag, 0, len(tags))
+	for _, tag := range tags {
+		idle = append(idle, domain.IdleTag{
+			Tag:               tag,
+			SuggestedScanRate: domain.SuggestScanRate(tag.ScanRate),
+		})
+	}
+
+	return idle, nil
+}
+
+// ApplyIdleTagSuggestions aplica a taxa sugerida a todas as tags ociosas em
+// uma única transação e retorna as tags alteradas. Tags que já estão na taxa
+// sugerida são ignoradas.
+func (s *PLCService) ApplyIdleTagSuggestions(since time.Duration, userID int) ([]domain.IdleTag, error) {
+	idle, err := s.GetIdleTags(since)
+	if err != nil {
+		return nil, err
+	}
+
+	applied := make([]domain.IdleTag, 0, len(idle))
+	rates := make(map[int]int, len(idle))
+	plcIDs := make(map[int]bool)
+	for _, item := range idle {
+		if item.SuggestedScanRate == item.Tag.ScanRate {
+			continue
+		}
+		rates[item.Tag.ID] = item.SuggestedScanRate
+		plcIDs[item.Tag.PLCID] = true
+		applied = append(applied, item)
+	}
+
+	if len(rates) == 0 {
+		return applied, nil
+	}
+
+	if err := s.pgTagRepo.UpdateScanRates(rates); err != nil {
+		return nil, fmt.Errorf("erro ao atualizar taxas de leitura no banco de dados: %w", err)
+	}
+
+	for _, item := range applied {
+		log.Printf("Auditoria: entity_type=plc_tag entity_id=%d action=apply_idle_suggestion user_id=%d old_scan_rate=%d new_scan_rate=%d",
+			item.Tag.ID, userID, item.Tag.ScanRate, item.SuggestedScanRate)
+	}
+
+	for plcID := range plcIDs {
+		if s.cfg().CacheEnabled && s.syncService != nil {
+			if err := s.syncService.SyncSpecificPLC(plcID); err != nil {
+				log.Printf("Aviso: erro ao sincronizar tags do PLC %d no Redis: %v", plcID, err)
+			}
+		}
+
+		if s.syncService != nil && s.syncService.IsRunning() {
+			s.syncService.NotifyPLCChange(plcID)
+		}
+	}
+
+	return applied, nil
+}
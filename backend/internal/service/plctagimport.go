@@ -0,0 +1,259 @@
+// internal/service/plctagimport.go
+package service
+
+import (
+	"app_padrao/internal/domain"
+	"bufio"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidImportFile indica um arquivo de importação que não pôde ser lido
+var ErrInvalidImportFile = errors.New("arquivo de importação inválido")
+
+// wonderwareAddress extrai DB, byte e bit do nome da tag exportada (ex.: DB11.DBX0.0)
+var wonderwareAddress = regexp.MustCompile(`DB(\d+)\.DBX(\d+)\.(\d+)`)
+
+// wonderwareTypes mapeia os tipos do InTouch para os tipos de tag do sistema
+var wonderwareTypes = map[string]string{
+	"discrete": "bool",
+	"integer":  "int",
+	"real":     "real",
+}
+
+// wonderwareTag é um elemento <Tag> do XML exportado pelo Wonderware/InTouch
+type wonderwareTag struct {
+	Name        string `xml:"Name,attr"`
+	Type        string `xml:"Type,attr"`
+	Description string `xml:"Description,attr"`
+}
+
+// xmlCharsetReader aceita arquivos em latin1 além de utf-8
+func xmlCharsetReader(charset string, input io.Reader) (io.Reader, error) {
+	switch strings.ToLower(charset) {
+	case "utf-8", "utf8", "":
+		return input, nil
+	case "iso-8859-1", "iso8859-1", "latin1", "latin-1", "windows-1252", "cp1252":
+		return &latin1Reader{r: bufio.NewReader(input)}, nil
+	}
+	return nil, fmt.Errorf("%w: codificação '%s' não suportada", ErrInvalidImportFile, charset)
+}
+
+// latin1Reader converte bytes latin1 em utf-8
+type latin1Reader struct {
+	r   *bufio.Reader
+	buf []byte
+}
+
+func (l *latin1Reader) Read(p []byte) (int, error) {
+	for len(l.buf) < len(p) {
+		b, err := l.r.ReadByte()
+		if err != nil {
+			if len(l.buf) == 0 {
+				return 0, err
+			}
+			break
+		}
+		l.buf = append(l.buf, string(rune(b))...)
+	}
+	n := copy(p, l.buf)
+	l.buf = l.buf[n:]
+	return n, nil
+}
+
+// parseWonderwareTags lê todos os elementos <Tag> do arquivo, em qualquer nível
+func parseWonderwareTags(r io.Reader) ([]wonderwareTag, error) {
+	decoder := xml.NewDecoder(r)
+	decoder.CharsetReader = xmlCharsetReader
+
+	var tags []wonderwareTag
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			if errors.Is(err, ErrInvalidImportFile) {
+				return nil, err
+			}
+			return nil, fmt.Errorf("%w: %w", ErrInvalidImportFile, err)
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok || !strings.EqualFold(start.Name.Local, "Tag") {
+			continue
+		}
+
+		var tag wonderwareTag
+		if err := decoder.DecodeElement(&tag, &start); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidImportFile, err)
+		}
+		tags = append(tags, tag)
+	}
+
+	return tags, nil
+}
+
+// ImportWonderwareTags cria no PLC as tags de um XML exportado pelo
+// Wonderware/InTouch. Tags já existentes (mesmo nome) são ignoradas. As tags
+// válidas são criadas em lote; se ultrapassarem o limite de tags do PLC,
+// nenhuma é criada.
+func (s *PLCService) ImportWonderwareTags(plcID int, r io.Reader) (domain.TagImportResult, error) {
+	if _, err := s.GetByID(plcID); err != nil {
+		return domain.TagImportResult{}, err
+	}
+
+	entries, err := parseWonderwareTags(r)
+	if err != nil {
+		return domain.TagImportResult{}, err
+	}
+
+	existing, err := s.GetPLCTags(plcID)
+	if err != nil {
+		return domain.TagImportResult{}, err
+	}
+	existingNames := make(map[string]bool, len(existing))
+	for _, tag := range existing {
+		existingNames[tag.Name] = true
+	}
+
+	result := domain.TagImportResult{
+		Total:    len(entries),
+		Errors:   []domain.TagImportIssue{},
+		Warnings: []domain.TagImportIssue{},
+	}
+
+	var valid []domain.PLCTag
+	var validRows []int
+	for i, entry := range entries {
+		line := i + 1
+		name := strings.TrimSpace(entry.Name)
+
+		match := wonderwareAddress.FindStringSubmatch(name)
+		if match == nil {
+			result.Errors = append(result.Errors, domain.TagImportIssue{
+				Row: line, Name: name, Message: "endereço não reconhecido (esperado DBn.DBXb.x)",
+			})
+			continue
+		}
+
+		if existingNames[name] {
+			result.Skipped++
+			continue
+		}
+
+		dataType, known := wonderwareTypes[strings.ToLower(strings.TrimSpace(entry.Type))]
+		if !known {
+			dataType = "word"
+			result.Warnings = append(result.Warnings, domain.TagImportIssue{
+				Row: line, Name: name, Message: fmt.Sprintf("tipo '%s' desconhecido, importada como word", entry.Type),
+			})
+		}
+
+		dbNumber, _ := strconv.Atoi(match[1])
+		byteOffset, _ := strconv.Atoi(match[2])
+		bitOffset, _ := strconv.Atoi(match[3])
+
+		tag := domain.PLCTag{
+			PLCID:          plcID,
+			Name:           name,
+			Description:    entry.Description,
+			DBNumber:       dbNumber,
+			ByteOffset:     byteOffset,
+			BitOffset:      bitOffset,
+			DataType:       dataType,
+			ScaleFactor:    1,
+			MonitorChanges: true,
+			Active:         true,
+		}
+
+		if err := s.prepareNewTag(&tag, false); err != nil {
+			result.Errors = append(result.Errors, domain.TagImportIssue{
+				Row: line, Name: name, Message: err.Error(),
+			})
+			continue
+		}
+
+		existingNames[name] = true
+		valid = append(valid, tag)
+		validRows = append(validRows, line)
+	}
+
+	// prepareNewTag compara só com as tags já gravadas; linhas do mesmo
+	// arquivo também não podem se sobrepor
+	valid = rejectBatchConflicts(valid, validRows, &result)
+
+	// Criar as tags válidas de uma vez, respeitando o limite de tags do PLC
+	if len(valid) > 0 {
+		ids, err := s.BulkCreateTags(plcID, valid)
+		if err != nil {
+			return domain.TagImportResult{}, err
+		}
+		result.Created = len(ids)
+	}
+
+	sort.SliceStable(result.Errors, func(i, j int) bool { return result.Errors[i].Row < result.Errors[j].Row })
+
+	log.Printf("Importação Wonderware no PLC %d: %d tags lidas, %d criadas, %d ignoradas, %d erros",
+		plcID, result.Total, result.Created, result.Skipped, len(result.Errors))
+
+	return result, nil
+}
+
+// rejectBatchConflicts remove do lote as tags cujo endereço sobrepõe o de uma
+// linha anterior do arquivo que foi mantida, registrando o erro na linha
+// rejeitada. rows traz a linha de cada tag do lote; os nomes são únicos.
+func rejectBatchConflicts(batch []domain.PLCTag, rows []int, result *domain.TagImportResult) []domain.PLCTag {
+	rowByName := make(map[string]int, len(batch))
+	for i, tag := range batch {
+		rowByName[tag.Name] = rows[i]
+	}
+
+	// Cada conflito como (linha anterior, linha posterior), resolvido na
+	// ordem do arquivo: a posterior só cai se a anterior foi mantida
+	type rowPair struct {
+		earlier, later domain.PLCTag
+	}
+	var pairs []rowPair
+	for _, conflict := range findAddressConflicts(batch) {
+		earlier, later := conflict.Tag1, conflict.Tag2
+		if rowByName[later.Name] < rowByName[earlier.Name] {
+			earlier, later = later, earlier
+		}
+		pairs = append(pairs, rowPair{earlier, later})
+	}
+	if len(pairs) == 0 {
+		return batch
+	}
+	sort.SliceStable(pairs, func(i, j int) bool {
+		return rowByName[pairs[i].later.Name] < rowByName[pairs[j].later.Name]
+	})
+
+	rejected := make(map[string]bool)
+	for _, pair := range pairs {
+		if rejected[pair.earlier.Name] || rejected[pair.later.Name] {
+			continue
+		}
+		rejected[pair.later.Name] = true
+		result.Errors = append(result.Errors, domain.TagImportIssue{
+			Row:     rowByName[pair.later.Name],
+			Name:    pair.later.Name,
+			Message: fmt.Sprintf("%v: endereço sobreposto ao da tag '%s' (linha %d)", domain.ErrAddressConflict, pair.earlier.Name, rowByName[pair.earlier.Name]),
+		})
+	}
+
+	kept := make([]domain.PLCTag, 0, len(batch)-len(rejected))
+	for _, tag := range batch {
+		if !rejected[tag.Name] {
+			kept = append(kept, tag)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"app_padrao/internal/domain"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseWonderwareTags(t *testing.T) {
+	xml := `<?xml version="1.0" encoding="utf-8"?>
+<Export><Tags>
+	<Tag Name="DB11.DBX0.0" Type="Discrete" Description="Motor ligado"/>
+	<Tag Name="DB11.DBX2.0" Type="Real"/>
+</Tags></Export>`
+
+	tags, err := parseWonderwareTags(strings.NewReader(xml))
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if len(tags) != 2 || tags[0].Name != "DB11.DBX0.0" || tags[0].Description != "Motor ligado" || tags[1].Type != "Real" {
+		t.Errorf("tags = %+v", tags)
+	}
+
+	if _, err := parseWonderwareTags(strings.NewReader("<Tag")); !errors.Is(err, ErrInvalidImportFile) {
+		t.Errorf("erro = %v, esperado %v", err, ErrInvalidImportFile)
+	}
+}
+
+func TestRejectBatchConflicts(t *testing.T) {
+	batch := []domain.PLCTag{
+		{Name: "nivel", DBNumber: 11, ByteOffset: 0, DataType: "real"},
+		{Name: "vazao", DBNumber: 11, ByteOffset: 2, DataType: "int"}, // sobrepõe nivel
+		{Name: "pressao", DBNumber: 11, ByteOffset: 4, DataType: "real"},
+		{Name: "motor", DBNumber: 11, ByteOffset: 8, DataType: "bool"},
+		{Name: "bomba", DBNumber: 11, ByteOffset: 8, DataType: "bool"}, // mesmo bit de motor
+		{Name: "valvula", DBNumber: 11, ByteOffset: 8, BitOffset: 1, DataType: "bool"},
+		{Name: "outro_db", DBNumber: 12, ByteOffset: 0, DataType: "real"},
+	}
+	rows := []int{1, 2, 3, 4, 5, 6, 7}
+	result := domain.TagImportResult{Errors: []domain.TagImportIssue{}}
+
+	kept := rejectBatchConflicts(batch, rows, &result)
+
+	var names []string
+	for _, tag := range kept {
+		names = append(names, tag.Name)
+	}
+	if got, want := strings.Join(names, ","), "nivel,pressao,motor,valvula,outro_db"; got != want {
+		t.Errorf("tags mantidas = %s, esperado %s", got, want)
+	}
+
+	if len(result.Errors) != 2 {
+		t.Fatalf("erros = %+v, esperado 2", result.Errors)
+	}
+	if result.Errors[0].Row != 2 || result.Errors[1].Row != 5 {
+		t.Errorf("linhas rejeitadas = %d e %d, esperado 2 e 5", result.Errors[0].Row, result.Errors[1].Row)
+	}
+}
+
+func TestRejectBatchConflictsKeepsLaterRowWhenEarlierWasRejected(t *testing.T) {
+	// b sobrepõe a e c; c só sobrepõe b, que já foi rejeitada
+	batch := []domain.PLCTag{
+		{Name: "a", DBNumber: 1, ByteOffset: 0, DataType: "int"},
+		{Name: "b", DBNumber: 1, ByteOffset: 1, DataType: "int"},
+		{Name: "c", DBNumber: 1, ByteOffset: 2, DataType: "int"},
+	}
+	result := domain.TagImportResult{}
+
+	kept := rejectBatchConflicts(batch, []int{1, 2, 3}, &result)
+
+	if len(kept) != 2 || kept[0].Name != "a" || kept[1].Name != "c" {
+		t.Errorf("tags mantidas = %+v, esperado a e c", kept)
+	}
+	if len(result.Errors) != 1 || result.Errors[0].Name != "b" {
+		t.Errorf("erros = %+v, esperado apenas b", result.Errors)
+	}
+}
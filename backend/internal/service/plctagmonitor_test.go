@@ -0,0 +1,70 @@
+package service
+
+import (
+	"app_padrao/internal/domain"
+	"app_padrao/internal/testutil"
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestTagMonitorPollsTagAddedMidRun garante que uma tag enviada a um monitor
+// em execução é lida em até dois disparos, sem reiniciar a conexão
+func TestTagMonitorPollsTagAddedMidRun(t *testing.T) {
+	sim := testutil.NewS7Simulator(t)
+	sim.SetDB(1, 0, []byte{0x00, 0x07, 0x00, 0x2A})
+
+	conn := NewPLCConnection(1, sim.Addr(), 0, 1)
+	if err := conn.Connect(); err != nil {
+		t.Fatalf("erro ao conectar ao simulador: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	m := NewPLCManager(nil, nil, nil)
+	const rate = 50
+	first := domain.PLCTag{ID: 1, PLCID: 1, Name: "Primeira", DBNumber: 1, ByteOffset: 0, DataType: "int", ScanRate: rate, Active: true, ScaleFactor: 1}
+	added := domain.PLCTag{ID: 2, PLCID: 1, Name: "Nova", DBNumber: 1, ByteOffset: 2, DataType: "int", ScanRate: rate, Active: true, ScaleFactor: 1}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	updates := make(chan tagListUpdate, 1)
+	lastValues := &sync.Map{}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		m.startTagMonitor(rate, []domain.PLCTag{first}, updates, ctx, domain.PLC{ID: 1, Name: "CLP"}, conn, lastValues)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	waitFor := func(tagID int, timeout time.Duration) (interface{}, bool) {
+		deadline := time.Now().Add(timeout)
+		for time.Now().Before(deadline) {
+			if v, ok := lastValues.Load(tagID); ok {
+				return v, true
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+		return nil, false
+	}
+
+	if _, ok := waitFor(first.ID, 2*time.Second); !ok {
+		t.Fatal("tag inicial não foi lida")
+	}
+	if _, ok := lastValues.Load(added.ID); ok {
+		t.Fatal("tag nova lida antes da atualização")
+	}
+
+	updates <- tagListUpdate{tags: []domain.PLCTag{first, added}}
+
+	// Margem para o agendador além dos dois disparos
+	value, ok := waitFor(added.ID, 2*rate*time.Millisecond+25*time.Millisecond)
+	if !ok {
+		t.Fatalf("tag nova não foi lida em até dois disparos de %dms", rate)
+	}
+	if v, ok := value.(int16); !ok || v != 42 {
+		t.Errorf("valor da tag nova = %v (%T), esperado 42", value, value)
+	}
+}
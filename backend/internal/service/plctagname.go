@@ -0,0 +1,63 @@
+// internal/service/plctagname.go
+package service
+
+import (
+	"app_padrao/internal/domain"
+	"fmt"
+	"regexp"
+)
+
+// defaultTagNamePattern aceita qualquer nome de tag
+const defaultTagNamePattern = ".*"
+
+// tagNamePattern retorna o padrão de nomes das tags, compilado na primeira
+// chamada e guardado na configuração. O padrão é verificado na inicialização
+// (ver ValidateTagNamePattern), por isso MustCompile não entra em pânico.
+func (s *PLCService) tagNamePattern() *regexp.Regexp {
+	s.tagNameOnce.Do(func() {
+		s.configMu.Lock()
+		defer s.configMu.Unlock()
+
+		pattern := s.config.TagNamePattern
+		if pattern == "" {
+			pattern = defaultTagNamePattern
+		}
+		s.config.tagNameRegexp = regexp.MustCompile(pattern)
+	})
+	return s.cfg().tagNameRegexp
+}
+
+// validateTagName recusa nomes vazios ou fora da convenção configurada em
+// PLC_TAG_NAME_PATTERN
+func (s *PLCService) validateTagName(name string) error {
+	if name == "" {
+		return fmt.Errorf("%w: o nome é obrigatório", ErrInvalidTagName)
+	}
+
+	pattern := s.tagNamePattern()
+	if pattern.MatchString(name) {
+		return nil
+	}
+
+	if description := s.cfg().TagNameDescription; description != "" {
+		return fmt.Errorf("%w: '%s' não segue a convenção de nomes (%s; padrão %s)",
+			ErrInvalidTagName, name, description, pattern.String())
+	}
+	return fmt.Errorf("%w: '%s' não segue o padrão de nomes %s", ErrInvalidTagName, name, pattern.String())
+}
+
+// GetTagNameConvention retorna a convenção de nomes das tags
+func (s *PLCService) GetTagNameConvention() domain.TagNameConvention {
+	return domain.TagNameConvention{
+		Pattern:     s.tagNamePattern().String(),
+		Description: s.cfg().TagNameDescription,
+	}
+}
+
+// ValidateTagNamePattern verifica se o padrão de nomes das tags compila
+func ValidateTagNamePattern(pattern string) error {
+	if _, err := regexp.Compile(pattern); err != nil {
+		return fmt.Errorf("padrão de nomes de tags inválido: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+// internal/service/plctagscale.go
+package service
+
+import (
+	"app_padrao/internal/domain"
+	"app_padrao/pkg/plc"
+	"fmt"
+	"math"
+)
+
+// scaleValue aplica a escala da tag ao valor bruto lido do PLC
+// (bruto * ScaleFactor + ScaleOffset). Tags sem escala e valores não
+// numéricos são devolvidos sem alteração.
+func scaleValue(tag domain.PLCTag, raw interface{}) interface{} {
+	if !tag.HasScaling() {
+		return raw
+	}
+	rawFloat, ok := plc.ToFloat64(raw)
+	if !ok {
+		return raw
+	}
+	return rawFloat*tag.ScaleFactor + tag.ScaleOffset
+}
+
+// unscaleValue desfaz a escala antes de escrever no PLC
+// ((valor - ScaleOffset) / ScaleFactor). Tipos inteiros são arredondados.
+func unscaleValue(tag domain.PLCTag, value interface{}) (interface{}, error) {
+	if !tag.HasScaling() {
+		return value, nil
+	}
+	scaled, ok := plc.ToFloat64(value)
+	if !ok {
+		return nil, fmt.Errorf("%w: valor %v não é numérico", domain.ErrInvalidScaledValue, value)
+	}
+	raw := (scaled - tag.ScaleOffset) / tag.ScaleFactor
+	if tag.DataType != "real" {
+		raw = math.Round(raw)
+	}
+	return raw, nil
+}
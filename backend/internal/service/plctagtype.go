@@ -0,0 +1,117 @@
+// internal/service/plctagtype.go
+package service
+
+import (
+	"app_padrao/internal/domain"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// ErrSameDataType indica uma migração para o tipo que a tag já possui
+var ErrSameDataType = errors.New("a tag já possui o tipo de dados informado")
+
+// readTagFromPLC lê o valor da tag diretamente do PLC, fora do ciclo de monitoramento
+func (s *PLCService) readTagFromPLC(tag domain.PLCTag) (interface{}, error) {
+	if s.manager == nil {
+		return nil, ErrMonitoringNotActive
+	}
+
+	conn, err := s.manager.GetConnectionByPLCID(tag.PLCID)
+	if err != nil {
+		return nil, err
+	}
+
+	return conn.ReadTag(tag.DBNumber, tag.ByteOffset, tag.DataType, tag.BitOffset)
+}
+
+// MigrateTagType troca o tipo de dados de uma tag em produção. O valor é lido
+// do PLC com o tipo antigo e com o novo; se o PLC não responder, a troca é
+// feita mesmo assim e o valor em cache fica com qualidade "uncertain".
+func (s *PLCService) MigrateTagType(tagID int, newDataType string, userID int) (domain.TagTypeMigration, error) {
+	newDataType = strings.ToLower(strings.TrimSpace(newDataType))
+	if !s.isValidDataType(newDataType) {
+		return domain.TagTypeMigration{}, fmt.Errorf("%w: '%s' não é suportado", ErrInvalidDataType, newDataType)
+	}
+
+	oldTag, err := s.pgTagRepo.GetByID(tagID)
+	if err != nil {
+		return domain.TagTypeMigration{}, err
+	}
+	if oldTag.DataType == newDataType {
+		return domain.TagTypeMigration{}, ErrSameDataType
+	}
+
+	newTag := oldTag
+	newTag.DataType = newDataType
+	if newDataType != "bool" {
+		newTag.BitOffset = 0
+	}
+	if err := newTag.Validate(); err != nil {
+		return domain.TagTypeMigration{}, err
+	}
+
+	result := domain.TagTypeMigration{
+		TagID:   tagID,
+		OldType: oldTag.DataType,
+		NewType: newDataType,
+	}
+
+	// Última leitura com o tipo antigo
+	oldValue, oldReadErr := s.readTagFromPLC(oldTag)
+	if oldReadErr != nil {
+		log.Printf("Aviso: não foi possível ler a tag %d com o tipo antigo: %v", tagID, oldReadErr)
+		if cached, err := s.cache.GetTagValue(oldTag.PLCID, tagID); err == nil && cached != nil {
+			oldValue = cached.Value
+		}
+	}
+	result.OldValue = oldValue
+
+	// Atualizar o banco e o Redis; se o Redis falhar, desfazer no banco para
+	// os dois não ficarem com tipos diferentes
+	newTag.UpdatedAt = time.Now()
+	if err := s.pgTagRepo.Update(newTag); err != nil {
+		return domain.TagTypeMigration{}, fmt.Errorf("erro ao atualizar tipo no banco de dados: %w", err)
+	}
+	if s.cfg().CacheEnabled {
+		if err := s.redisTagRepo.Update(newTag); err != nil && !errors.Is(err, domain.ErrPLCTagNotFound) {
+			if rollbackErr := s.pgTagRepo.Update(oldTag); rollbackErr != nil {
+				log.Printf("Erro ao desfazer a troca de tipo da tag %d no banco: %v", tagID, rollbackErr)
+			}
+			return domain.TagTypeMigration{}, fmt.Errorf("erro ao atualizar tipo no Redis: %w", err)
+		}
+	}
+
+	// Primeira leitura com o tipo novo
+	newValue, newReadErr := s.readTagFromPLC(newTag)
+	quality := domain.QualityGood
+	if newReadErr != nil {
+		log.Printf("Aviso: não foi possível ler a tag %d com o tipo novo: %v", tagID, newReadErr)
+		newValue = oldValue
+		quality = domain.QualityUncertain
+	}
+	result.NewValue = newValue
+	result.Quality = quality
+
+	if err := s.cache.BatchSetTagValues([]domain.TagValue{{
+		PLCID:     newTag.PLCID,
+		TagID:     tagID,
+		Value:     newValue,
+		Quality:   quality,
+		Timestamp: time.Now(),
+	}}); err != nil {
+		log.Printf("Aviso: erro ao armazenar valor da tag %d após troca de tipo: %v", tagID, err)
+	}
+
+	if s.syncService != nil && s.syncService.IsRunning() {
+		s.syncService.NotifyTagChange(tagID)
+		s.syncService.NotifyPLCChange(newTag.PLCID)
+	}
+
+	log.Printf("Auditoria: entity_type=plc_tag entity_id=%d action=migrate_type user_id=%d old_type=%s new_type=%s old_value=%v new_value=%v quality=%s",
+		tagID, userID, result.OldType, result.NewType, result.OldValue, result.NewValue, quality)
+
+	return result, nil
+}
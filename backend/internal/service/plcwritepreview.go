@@ -0,0 +1,113 @@
+// internal/service/plcwritepreview.go
+package service
+
+import (
+	"app_padrao/internal/domain"
+	"app_padrao/pkg/plc"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// writeGoTypes indica o tipo Go para o qual o valor é convertido antes da
+// escrita, por tipo de dados da tag
+var writeGoTypes = map[string]string{
+	"real":          "float32",
+	"dint":          "int32",
+	"int32":         "int32",
+	"dword":         "uint32",
+	"uint32":        "uint32",
+	"int":           "int16",
+	"int16":         "int16",
+	"word":          "uint16",
+	"uint16":        "uint16",
+	"sint":          "int8",
+	"int8":          "int8",
+	"usint":         "uint8",
+	"byte":          "uint8",
+	"uint8":         "uint8",
+	"char":          "string",
+	"bool":          "bool",
+	"string":        "string",
+	"date":          "time.Time",
+	"time_of_day":   "time.Duration",
+	"date_and_time": "time.Time",
+	"timer":         "time.Duration",
+	"counter":       "int",
+}
+
+// PreviewWriteByName simula a escrita de um valor em uma tag: verifica se a
+// tag existe, se permite escrita e se o valor é convertível para o seu tipo,
+// sem acessar o PLC. Falhas de conversão são retornadas no resultado
+// (WouldSucceed=false), não como erro. Simulações não contam nas
+// estatísticas de escrita nem registram a última escrita.
+func (m *PLCManager) PreviewWriteByName(tagName string, value interface{}) (*domain.TagWritePreview, error) {
+	log.Printf("Simulação de escrita na tag '%s': %v", tagName, value)
+
+	tags, err := m.tagRepo.GetByName(tagName)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar tag '%s': %w", tagName, err)
+	}
+
+	if len(tags) == 0 {
+		return nil, fmt.Errorf("%w: '%s'", ErrTagNotFound, tagName)
+	}
+
+	// Mesma escolha de WriteTagByName: a primeira tag encontrada
+	tag := tags[0]
+
+	if !tag.CanWrite {
+		return nil, fmt.Errorf("%w: '%s'", ErrWriteNotPermitted, tagName)
+	}
+
+	dataType := strings.ToLower(strings.TrimSpace(tag.DataType))
+	if dataType == "" {
+		dataType = "word"
+	}
+
+	preview := &domain.TagWritePreview{
+		DryRun:     true,
+		TagName:    tag.Name,
+		DataType:   dataType,
+		WriteValue: value,
+	}
+
+	if current, err := m.cache.GetTagValue(tag.PLCID, tag.ID); err == nil && current != nil {
+		preview.CurrentValue = current.Value
+	}
+
+	rawValue, err := unscaleValue(tag, value)
+	if err != nil {
+		preview.Error = err.Error()
+		return preview, nil
+	}
+
+	if err := plc.CheckWriteValue(dataType, rawValue); err != nil {
+		preview.Error = err.Error()
+		return preview, nil
+	}
+
+	preview.WouldSucceed = true
+	if target, ok := writeGoTypes[dataType]; ok {
+		preview.Conversion = fmt.Sprintf("%T→%s", rawValue, target)
+	}
+
+	return preview, nil
+}
+
+// PreviewTagWrite simula a escrita de um valor em uma tag pelo nome
+func (s *PLCService) PreviewTagWrite(tagName string, value interface{}) (*domain.TagWritePreview, error) {
+	s.mu.RLock()
+	isRunning := s.isRunning
+	s.mu.RUnlock()
+
+	if !isRunning || s.manager == nil {
+		return nil, ErrMonitoringNotActive
+	}
+
+	if value == nil {
+		return nil, fmt.Errorf("valor não pode ser nulo")
+	}
+
+	return s.manager.PreviewWriteByName(tagName, value)
+}
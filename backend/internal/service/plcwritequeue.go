@@ -0,0 +1,261 @@
+// internal/service/plcwritequeue.go
+package service
+
+import (
+	"app_padrao/internal/domain"
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// Erros da fila de escrita
+var (
+	ErrWriteQueueUnavailable = errors.New("fila de escrita indisponível sem Redis")
+	ErrQueuedWriteNotFound   = errors.New("escrita pendente não encontrada")
+)
+
+const (
+	writeQueueKeyFormat    = "plc:%d:write_queue"
+	writeQueueMaxAttempts  = 3
+	writeQueueTTL          = 5 * time.Minute
+	writeQueuePollInterval = time.Second
+)
+
+// writeQueueKey retorna a lista Redis com as escritas pendentes de um PLC
+func (m *PLCManager) writeQueueKey(plcID int) string {
+	return m.keyPrefix + fmt.Sprintf(writeQueueKeyFormat, plcID)
+}
+
+// newRequestID gera um identificador no formato UUID v4
+func newRequestID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
+
+// queueClient retorna o cliente Redis usado pela fila de escrita
+func (m *PLCManager) queueClient() (*redis.Client, error) {
+	if m.cache == nil {
+		return nil, ErrWriteQueueUnavailable
+	}
+	client := m.cache.GetRedisClient()
+	if client == nil {
+		return nil, ErrWriteQueueUnavailable
+	}
+	return client, nil
+}
+
+// EnqueueWrite valida a tag e coloca a escrita na fila do PLC correspondente.
+// A escrita é executada pelo worker da fila quando o PLC estiver online.
+func (m *PLCManager) EnqueueWrite(tagName string, value interface{}, userID int) (domain.QueuedWrite, error) {
+	client, err := m.queueClient()
+	if err != nil {
+		return domain.QueuedWrite{}, err
+	}
+
+	tags, err := m.tagRepo.GetByName(tagName)
+	if err != nil {
+		return domain.QueuedWrite{}, fmt.Errorf("erro ao buscar tag '%s': %w", tagName, err)
+	}
+	if len(tags) == 0 {
+		return domain.QueuedWrite{}, fmt.Errorf("%w: '%s'", ErrTagNotFound, tagName)
+	}
+
+	tag := tags[0]
+	if !tag.CanWrite {
+		return domain.QueuedWrite{}, fmt.Errorf("%w: '%s'", ErrWriteNotPermitted, tagName)
+	}
+
+	requestID, err := newRequestID()
+	if err != nil {
+		return domain.QueuedWrite{}, fmt.Errorf("erro ao gerar identificador da escrita: %w", err)
+	}
+
+	write := domain.QueuedWrite{
+		RequestID: requestID,
+		PLCID:     tag.PLCID,
+		TagName:   tagName,
+		Value:     value,
+		UserID:    userID,
+		QueuedAt:  time.Now(),
+	}
+
+	data, err := json.Marshal(write)
+	if err != nil {
+		return domain.QueuedWrite{}, fmt.Errorf("erro ao serializar escrita: %w", err)
+	}
+
+	if err := client.RPush(context.Background(), m.writeQueueKey(tag.PLCID), data).Err(); err != nil {
+		return domain.QueuedWrite{}, fmt.Errorf("erro ao enfileirar escrita: %w", err)
+	}
+
+	log.Printf("Escrita %s enfileirada para tag '%s' no PLC %d", requestID, tagName, tag.PLCID)
+	return write, nil
+}
+
+// GetWriteQueue lista as escritas pendentes de um PLC, na ordem de execução
+func (m *PLCManager) GetWriteQueue(plcID int) ([]domain.QueuedWrite, error) {
+	client, err := m.queueClient()
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := client.LRange(context.Background(), m.writeQueueKey(plcID), 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler fila de escrita: %w", err)
+	}
+
+	writes := make([]domain.QueuedWrite, 0, len(items))
+	for _, item := range items {
+		var write domain.QueuedWrite
+		if err := json.Unmarshal([]byte(item), &write); err != nil {
+			log.Printf("Item inválido na fila de escrita do PLC %d: %v", plcID, err)
+			continue
+		}
+		writes = append(writes, write)
+	}
+
+	return writes, nil
+}
+
+// CancelQueuedWrite remove uma escrita pendente da fila
+func (m *PLCManager) CancelQueuedWrite(plcID int, requestID string) error {
+	client, err := m.queueClient()
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	key := m.writeQueueKey(plcID)
+
+	items, err := client.LRange(ctx, key, 0, -1).Result()
+	if err != nil {
+		return fmt.Errorf("erro ao ler fila de escrita: %w", err)
+	}
+
+	for _, item := range items {
+		var write domain.QueuedWrite
+		if err := json.Unmarshal([]byte(item), &write); err != nil || write.RequestID != requestID {
+			continue
+		}
+
+		removed, err := client.LRem(ctx, key, 1, item).Result()
+		if err != nil {
+			return fmt.Errorf("erro ao remover escrita da fila: %w", err)
+		}
+		if removed == 0 {
+			// O worker consumiu o item entre a leitura e a remoção
+			break
+		}
+
+		log.Printf("Escrita %s cancelada no PLC %d", requestID, plcID)
+		return nil
+	}
+
+	return ErrQueuedWriteNotFound
+}
+
+// runWriteQueueWorker consome a fila de escrita do PLC enquanto o monitor
+// estiver ativo. Só retira itens quando há conexão com o PLC.
+func (m *PLCManager) runWriteQueueWorker(ctx context.Context, plcID int) {
+	client, err := m.queueClient()
+	if err != nil {
+		return
+	}
+
+	ticker := time.NewTicker(writeQueuePollInterval)
+	defer ticker.Stop()
+
+	key := m.writeQueueKey(plcID)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+			if _, err := m.GetConnectionByPLCID(plcID); err != nil {
+				continue
+			}
+
+			// Processar todos os itens disponíveis neste ciclo
+			for ctx.Err() == nil {
+				item, err := client.LPop(ctx, key).Result()
+				if err == redis.Nil {
+					break
+				}
+				if err != nil {
+					log.Printf("Erro ao ler fila de escrita do PLC %d: %v", plcID, err)
+					break
+				}
+
+				if !m.processQueuedWrite(ctx, client, key, item) {
+					break
+				}
+			}
+		}
+	}
+}
+
+// processQueuedWrite executa uma escrita da fila. Falhas são recolocadas no
+// início da fila, para não passarem à frente delas as escritas enfileiradas
+// depois, até writeQueueMaxAttempts. Retorna false se o worker deve aguardar
+// o próximo ciclo.
+func (m *PLCManager) processQueuedWrite(ctx context.Context, client *redis.Client, key, item string) bool {
+	var write domain.QueuedWrite
+	if err := json.Unmarshal([]byte(item), &write); err != nil {
+		log.Printf("Descartando item inválido da fila %s: %v", key, err)
+		return true
+	}
+
+	if time.Since(write.QueuedAt) > writeQueueTTL {
+		log.Printf("Escrita %s na tag '%s' descartada: expirou após %v na fila",
+			write.RequestID, write.TagName, writeQueueTTL)
+		return true
+	}
+
+	// Os intertravamentos valem no momento da escrita, não só ao enfileirar.
+	// Uma escrita bloqueada é descartada: executá-la mais tarde, quando o
+	// intertravamento liberar, seria agir sobre um pedido já obsoleto.
+	if m.validateWrite != nil {
+		if err := m.validateWrite(write.TagName, write.Value, write.UserID); err != nil {
+			log.Printf("Escrita %s na tag '%s' descartada: %v", write.RequestID, write.TagName, err)
+			return true
+		}
+	}
+
+	err := m.WriteTagByName(write.TagName, write.Value, write.UserID)
+	if err == nil {
+		log.Printf("Escrita %s da fila concluída na tag '%s'", write.RequestID, write.TagName)
+		return true
+	}
+
+	write.Attempts++
+	if write.Attempts >= writeQueueMaxAttempts {
+		log.Printf("Escrita %s na tag '%s' descartada após %d tentativas: %v",
+			write.RequestID, write.TagName, write.Attempts, err)
+		return true
+	}
+
+	data, marshalErr := json.Marshal(write)
+	if marshalErr == nil {
+		// Sem ctx: o item já saiu da fila e não pode se perder no encerramento
+		marshalErr = client.LPush(context.Background(), key, data).Err()
+	}
+	if marshalErr != nil {
+		log.Printf("Erro ao recolocar escrita %s na fila: %v", write.RequestID, marshalErr)
+	}
+
+	// Falha possivelmente de conexão: aguardar o próximo ciclo
+	return false
+}
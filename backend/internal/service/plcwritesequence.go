@@ -0,0 +1,131 @@
+package service
+
+import (
+	"app_padrao/internal/domain"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// WriteSequence escreve os passos em ordem, parando no primeiro que falhar.
+// Com rollback, os valores originais de todas as tags são lidos do cache
+// antes do primeiro passo e, em caso de falha, regravados em ordem inversa
+// nos passos já executados. O rollback também pode falhar: o resultado
+// informa quantos passos foram restaurados e os erros de cada restauração.
+func (s *PLCService) WriteSequence(steps []domain.WriteStep, rollback bool, userID int) (domain.SequenceResult, error) {
+	result := domain.SequenceResult{RollbackStatus: domain.RollbackStatusNotNeeded}
+
+	s.mu.RLock()
+	isRunning := s.isRunning
+	s.mu.RUnlock()
+
+	if !isRunning || s.manager == nil {
+		return result, ErrMonitoringNotActive
+	}
+
+	if len(steps) == 0 {
+		return result, domain.ErrWriteSequenceEmpty
+	}
+	if len(steps) > domain.MaxWriteSequenceSteps {
+		return result, fmt.Errorf("%w (%d)", domain.ErrWriteSequenceTooLong, domain.MaxWriteSequenceSteps)
+	}
+
+	// Mesma resolução por nome de WriteTagByName: a primeira tag encontrada
+	tags := make([]domain.PLCTag, len(steps))
+	for i, step := range steps {
+		if strings.TrimSpace(step.TagName) == "" || step.Value == nil {
+			return result, fmt.Errorf("%w: passo %d", domain.ErrWriteSequenceInvalid, i)
+		}
+
+		found, err := s.pgTagRepo.GetByName(step.TagName)
+		if err != nil {
+			return result, fmt.Errorf("erro ao buscar tag '%s': %w", step.TagName, err)
+		}
+		if len(found) == 0 {
+			return result, fmt.Errorf("%w: '%s'", ErrTagNotFound, step.TagName)
+		}
+		tags[i] = found[0]
+	}
+
+	// Intertravamentos de todos os passos são verificados antes do primeiro
+	for _, step := range steps {
+		if err := s.validateWrite(step.TagName, step.Value, userID); err != nil {
+			return result, err
+		}
+	}
+
+	// Valores originais lidos antes do primeiro passo: sem eles não há como
+	// desfazer, então a sequência nem começa
+	var originals []interface{}
+	if rollback {
+		originals = make([]interface{}, len(steps))
+		for i, tag := range tags {
+			current, err := s.cache.GetTagValue(tag.PLCID, tag.ID)
+			if err != nil || current == nil || current.Value == nil {
+				return result, fmt.Errorf("%w: '%s'", domain.ErrWriteSequenceNoOriginal, tag.Name)
+			}
+			originals[i] = current.Value
+		}
+	}
+
+	var writeErr error
+	for i, step := range steps {
+		if err := s.manager.WriteTagByName(step.TagName, step.Value, userID); err != nil {
+			failed := i
+			result.FailedStep = &failed
+			result.Error = err.Error()
+			writeErr = fmt.Errorf("erro ao escrever tag '%s' (passo %d): %w", step.TagName, i, err)
+			break
+		}
+		result.Executed++
+	}
+
+	if writeErr != nil {
+		if rollback {
+			s.rollbackSequence(steps, originals, &result, userID)
+		} else {
+			result.RollbackStatus = domain.RollbackStatusNotRequested
+		}
+	}
+
+	names := make([]string, len(steps))
+	for i, step := range steps {
+		names[i] = step.TagName
+	}
+	failedStep := -1
+	if result.FailedStep != nil {
+		failedStep = *result.FailedStep
+	}
+	log.Printf("Auditoria: entity_type=plc_tag_sequence action=sequence_write user_id=%d steps=%d executed=%d failed_step=%d rolled_back=%d rollback_status=%s tags=%q",
+		userID, len(steps), result.Executed, failedStep, result.RolledBack, result.RollbackStatus, strings.Join(names, ","))
+
+	return result, writeErr
+}
+
+// rollbackSequence regrava os valores originais dos passos executados, do
+// último para o primeiro, e registra no resultado o que foi restaurado. Cada
+// restauração passa pelos intertravamentos como qualquer escrita.
+func (s *PLCService) rollbackSequence(steps []domain.WriteStep, originals []interface{}, result *domain.SequenceResult, userID int) {
+	for i := result.Executed - 1; i >= 0; i-- {
+		err := s.validateWrite(steps[i].TagName, originals[i], userID)
+		if err == nil {
+			err = s.manager.WriteTagByName(steps[i].TagName, originals[i], userID)
+		}
+		if err != nil {
+			log.Printf("Erro no rollback da tag '%s' (passo %d): %v", steps[i].TagName, i, err)
+			result.RollbackErrors = append(result.RollbackErrors,
+				fmt.Sprintf("passo %d (%s): %v", i, steps[i].TagName, err))
+			continue
+		}
+		result.RolledBack++
+	}
+
+	switch {
+	case result.RolledBack == result.Executed:
+		result.RollbackStatus = domain.RollbackStatusSuccess
+	case result.RolledBack > 0:
+		result.RollbackStatus = domain.RollbackStatusPartial
+	default:
+		result.RollbackStatus = domain.RollbackStatusFailed
+	}
+}
@@ -0,0 +1,246 @@
+package service
+
+import (
+	"app_padrao/internal/domain"
+	"app_padrao/internal/testutil"
+	"bytes"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeSequenceTagRepo resolve as tags da sequência pelo nome
+type fakeSequenceTagRepo struct {
+	domain.PLCTagRepository
+	tags []domain.PLCTag
+}
+
+func (r *fakeSequenceTagRepo) GetByName(name string) ([]domain.PLCTag, error) {
+	for _, tag := range r.tags {
+		if tag.Name == name {
+			return []domain.PLCTag{tag}, nil
+		}
+	}
+	return nil, nil
+}
+
+// fakeSequenceCache guarda em memória os valores das tags
+type fakeSequenceCache struct {
+	domain.PLCCache
+	mu     sync.Mutex
+	values map[int]interface{}
+}
+
+func (c *fakeSequenceCache) GetTagValue(plcID, tagID int) (*domain.TagValue, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	value, ok := c.values[tagID]
+	if !ok {
+		return nil, nil
+	}
+	return &domain.TagValue{PLCID: plcID, TagID: tagID, Value: value}, nil
+}
+
+func (c *fakeSequenceCache) SetTagValue(plcID, tagID int, value interface{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.values[tagID] = value
+	return nil
+}
+
+func (c *fakeSequenceCache) SetTagLastWrite(tagID, userID int, t time.Time) error {
+	return nil
+}
+
+// blockValueValidator bloqueia a escrita de um valor específico em uma tag
+type blockValueValidator struct {
+	tagName string
+	value   interface{}
+}
+
+func (v blockValueValidator) Validate(tagName string, value interface{}, cache domain.PLCCache) error {
+	if tagName == v.tagName && fmt.Sprint(value) == fmt.Sprint(v.value) {
+		return errors.New("valor bloqueado")
+	}
+	return nil
+}
+
+// DB1 do simulador: setpoint (int) no byte 0, execução (bool) no byte 2 e
+// uma tag sem permissão de escrita no byte 4
+var sequenceTags = []domain.PLCTag{
+	{ID: 1, PLCID: 1, Name: "valvula_setpoint", DBNumber: 1, ByteOffset: 0, DataType: "int", CanWrite: true, ScaleFactor: 1},
+	{ID: 2, PLCID: 1, Name: "valvula_executar", DBNumber: 1, ByteOffset: 2, BitOffset: 0, DataType: "bool", CanWrite: true, ScaleFactor: 1},
+	{ID: 3, PLCID: 1, Name: "valvula_bloqueada", DBNumber: 1, ByteOffset: 4, DataType: "int", CanWrite: false, ScaleFactor: 1},
+}
+
+// newSequenceTestService liga um serviço em execução ao simulador, com
+// setpoint 10 e execução desligada no PLC e no cache
+func newSequenceTestService(t *testing.T) (*PLCService, *testutil.S7Simulator, *fakeSequenceCache) {
+	t.Helper()
+
+	sim := testutil.NewS7Simulator(t)
+	sim.SetDB(1, 0, []byte{0x00, 0x0A, 0x00, 0x00, 0x00, 0x00})
+
+	conn := NewPLCConnection(1, sim.Addr(), 0, 1)
+	if err := conn.Connect(); err != nil {
+		t.Fatalf("erro ao conectar ao simulador: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	repo := &fakeSequenceTagRepo{tags: sequenceTags}
+	cache := &fakeSequenceCache{values: map[int]interface{}{1: int16(10), 2: false, 3: int16(0)}}
+
+	m := NewPLCManager(nil, repo, cache)
+	m.activeConnections[1] = conn
+
+	s := &PLCService{
+		pgTagRepo: repo,
+		cache:     cache,
+		manager:   m,
+		isRunning: true,
+	}
+	return s, sim, cache
+}
+
+// assertDB confere os primeiros bytes do DB1 no simulador
+func assertDB(t *testing.T, sim *testutil.S7Simulator, want []byte) {
+	t.Helper()
+	if got := sim.ReadDB(1, 0, len(want)); !bytes.Equal(got, want) {
+		t.Errorf("DB1 = % X, esperado % X", got, want)
+	}
+}
+
+func TestWriteSequenceSuccess(t *testing.T) {
+	s, sim, _ := newSequenceTestService(t)
+
+	result, err := s.WriteSequence([]domain.WriteStep{
+		{TagName: "valvula_setpoint", Value: 50.0},
+		{TagName: "valvula_executar", Value: true},
+	}, true, 7)
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	if result.Executed != 2 || result.RolledBack != 0 || result.FailedStep != nil {
+		t.Errorf("resultado = %+v, esperado 2 passos executados sem falha", result)
+	}
+	if result.RollbackStatus != domain.RollbackStatusNotNeeded {
+		t.Errorf("rollback_status = %s, esperado %s", result.RollbackStatus, domain.RollbackStatusNotNeeded)
+	}
+	assertDB(t, sim, []byte{0x00, 0x32, 0x01, 0x00})
+}
+
+func TestWriteSequenceRollsBackExecutedSteps(t *testing.T) {
+	s, sim, _ := newSequenceTestService(t)
+
+	result, err := s.WriteSequence([]domain.WriteStep{
+		{TagName: "valvula_setpoint", Value: 50.0},
+		{TagName: "valvula_executar", Value: true},
+		{TagName: "valvula_bloqueada", Value: 1.0},
+	}, true, 7)
+	if !errors.Is(err, ErrWriteNotPermitted) {
+		t.Fatalf("erro = %v, esperado ErrWriteNotPermitted", err)
+	}
+
+	if result.Executed != 2 || result.FailedStep == nil || *result.FailedStep != 2 {
+		t.Errorf("resultado = %+v, esperado falha no passo 2 após 2 executados", result)
+	}
+	if result.RolledBack != 2 || result.RollbackStatus != domain.RollbackStatusSuccess {
+		t.Errorf("rollback = %d (%s), esperado 2 (%s)", result.RolledBack, result.RollbackStatus, domain.RollbackStatusSuccess)
+	}
+	if result.Error == "" {
+		t.Error("resultado deveria informar o erro do passo")
+	}
+
+	// Valores originais de volta no PLC
+	assertDB(t, sim, []byte{0x00, 0x0A, 0x00, 0x00})
+}
+
+func TestWriteSequenceWithoutRollback(t *testing.T) {
+	s, sim, _ := newSequenceTestService(t)
+
+	result, err := s.WriteSequence([]domain.WriteStep{
+		{TagName: "valvula_setpoint", Value: 50.0},
+		{TagName: "valvula_bloqueada", Value: 1.0},
+	}, false, 7)
+	if err == nil {
+		t.Fatal("esperado erro no passo 1")
+	}
+
+	if result.Executed != 1 || result.RolledBack != 0 || result.RollbackStatus != domain.RollbackStatusNotRequested {
+		t.Errorf("resultado = %+v, esperado 1 executado e rollback não solicitado", result)
+	}
+	assertDB(t, sim, []byte{0x00, 0x32})
+}
+
+func TestWriteSequenceReportsRollbackFailure(t *testing.T) {
+	s, sim, _ := newSequenceTestService(t)
+	// O intertravamento passa a bloquear o valor original do setpoint
+	s.AddWriteValidator(blockValueValidator{tagName: "valvula_setpoint", value: int16(10)})
+
+	result, err := s.WriteSequence([]domain.WriteStep{
+		{TagName: "valvula_setpoint", Value: 50.0},
+		{TagName: "valvula_executar", Value: true},
+		{TagName: "valvula_bloqueada", Value: 1.0},
+	}, true, 7)
+	if err == nil {
+		t.Fatal("esperado erro no passo 2")
+	}
+
+	if result.RolledBack != 1 || result.RollbackStatus != domain.RollbackStatusPartial {
+		t.Errorf("rollback = %d (%s), esperado 1 (%s)", result.RolledBack, result.RollbackStatus, domain.RollbackStatusPartial)
+	}
+	if len(result.RollbackErrors) != 1 {
+		t.Errorf("rollback_errors = %v, esperado um erro do passo 0", result.RollbackErrors)
+	}
+
+	// Só a execução foi restaurada; o setpoint ficou com o valor novo
+	assertDB(t, sim, []byte{0x00, 0x32, 0x00, 0x00})
+}
+
+func TestWriteSequenceRefusesWithoutOriginalValue(t *testing.T) {
+	s, sim, cache := newSequenceTestService(t)
+	delete(cache.values, 2)
+
+	result, err := s.WriteSequence([]domain.WriteStep{
+		{TagName: "valvula_setpoint", Value: 50.0},
+		{TagName: "valvula_executar", Value: true},
+	}, true, 7)
+	if !errors.Is(err, domain.ErrWriteSequenceNoOriginal) {
+		t.Fatalf("erro = %v, esperado ErrWriteSequenceNoOriginal", err)
+	}
+	if result.Executed != 0 {
+		t.Errorf("executados = %d, esperado 0", result.Executed)
+	}
+
+	// Nada foi escrito
+	assertDB(t, sim, []byte{0x00, 0x0A, 0x00, 0x00})
+}
+
+func TestWriteSequenceValidation(t *testing.T) {
+	s, _, _ := newSequenceTestService(t)
+
+	tests := []struct {
+		name  string
+		steps []domain.WriteStep
+		want  error
+	}{
+		{"vazia", nil, domain.ErrWriteSequenceEmpty},
+		{"longa demais", make([]domain.WriteStep, domain.MaxWriteSequenceSteps+1), domain.ErrWriteSequenceTooLong},
+		{"sem valor", []domain.WriteStep{{TagName: "valvula_setpoint"}}, domain.ErrWriteSequenceInvalid},
+		{"tag inexistente", []domain.WriteStep{{TagName: "nao_existe", Value: 1}}, ErrTagNotFound},
+	}
+
+	for _, tt := range tests {
+		if _, err := s.WriteSequence(tt.steps, true, 7); !errors.Is(err, tt.want) {
+			t.Errorf("%s: erro = %v, esperado %v", tt.name, err, tt.want)
+		}
+	}
+
+	s.isRunning = false
+	if _, err := s.WriteSequence([]domain.WriteStep{{TagName: "valvula_setpoint", Value: 1}}, true, 7); !errors.Is(err, ErrMonitoringNotActive) {
+		t.Errorf("serviço parado: erro = %v, esperado ErrMonitoringNotActive", err)
+	}
+}
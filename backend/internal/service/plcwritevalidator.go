@@ -0,0 +1,139 @@
+// internal/service/plcwritevalidator.go
+package service
+
+import (
+	"app_padrao/internal/domain"
+	"app_padrao/pkg/plc"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+)
+
+// ErrWriteBlocked indica uma escrita recusada por um validador (intertravamento)
+var ErrWriteBlocked = errors.New("escrita bloqueada por intertravamento")
+
+// WriteValidator decide se uma escrita pode ser enviada ao PLC. Retornar
+// erro bloqueia a escrita; a mensagem é devolvida ao cliente.
+type WriteValidator interface {
+	Validate(tagName string, value interface{}, cache domain.PLCCache) error
+}
+
+// ConditionValidator só permite escrever em TagName quando a tag booleana de
+// condição está verdadeira no cache. Sem valor no cache ou com qualidade
+// ruim a escrita também é bloqueada.
+type ConditionValidator struct {
+	TagName        string
+	ConditionPLCID int
+	ConditionTagID int
+	Message        string // Motivo exibido quando a escrita é bloqueada
+}
+
+// Validate implementa WriteValidator
+func (v ConditionValidator) Validate(tagName string, value interface{}, cache domain.PLCCache) error {
+	if tagName != v.TagName {
+		return nil
+	}
+
+	message := v.Message
+	if message == "" {
+		message = fmt.Sprintf("condição da tag %d do PLC %d não atendida", v.ConditionTagID, v.ConditionPLCID)
+	}
+
+	condition, err := cache.GetTagValue(v.ConditionPLCID, v.ConditionTagID)
+	if err != nil || condition == nil {
+		return fmt.Errorf("%s (valor da condição indisponível)", message)
+	}
+	if condition.Quality == domain.QualityBad || condition.Quality == domain.QualityUncertain {
+		return fmt.Errorf("%s (qualidade da condição: %s)", message, condition.Quality)
+	}
+
+	if ok, valid := plc.ExtractBool(condition.Value); !valid || !ok {
+		return errors.New(message)
+	}
+	return nil
+}
+
+// AddWriteValidator registra um validador executado antes de cada escrita
+func (s *PLCService) AddWriteValidator(v WriteValidator) {
+	s.validatorsMu.Lock()
+	s.writeValidators = append(s.writeValidators, v)
+	s.validatorsMu.Unlock()
+}
+
+// validateWrite executa os validadores em ordem e interrompe no primeiro que
+// bloquear a escrita
+func (s *PLCService) validateWrite(tagName string, value interface{}, userID int) error {
+	s.validatorsMu.RLock()
+	validators := s.writeValidators
+	s.validatorsMu.RUnlock()
+
+	for _, v := range validators {
+		if err := v.Validate(tagName, value, s.cache); err != nil {
+			log.Printf("Auditoria: entity_type=plc_tag tag=%q action=write_blocked_by_validator user_id=%d value=%v reason=%q",
+				tagName, userID, value, err.Error())
+			return fmt.Errorf("%w: %w", ErrWriteBlocked, err)
+		}
+	}
+	return nil
+}
+
+// validatorFileEntry é um validador no arquivo de configuração
+type validatorFileEntry struct {
+	Type           string `json:"type"`
+	Tag            string `json:"tag"`
+	ConditionPLCID int    `json:"condition_plc_id"`
+	ConditionTagID int    `json:"condition_tag_id"`
+	Message        string `json:"message"`
+}
+
+// ValidatorLoader lê os validadores de escrita de um arquivo JSON:
+//
+//	[{"type": "condition", "tag": "Esteira_Partir", "condition_plc_id": 1,
+//	  "condition_tag_id": 42, "message": "Proteção da esteira aberta"}]
+type ValidatorLoader struct {
+	path string
+}
+
+// NewValidatorLoader cria um carregador para o arquivo informado
+func NewValidatorLoader(path string) *ValidatorLoader {
+	return &ValidatorLoader{path: path}
+}
+
+// Load retorna os validadores do arquivo. Arquivo inexistente não é erro:
+// nenhum validador é configurado.
+func (l *ValidatorLoader) Load() ([]WriteValidator, error) {
+	data, err := os.ReadFile(l.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler %s: %w", l.path, err)
+	}
+
+	var entries []validatorFileEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("erro ao interpretar %s: %w", l.path, err)
+	}
+
+	validators := make([]WriteValidator, 0, len(entries))
+	for i, entry := range entries {
+		switch entry.Type {
+		case "condition":
+			if entry.Tag == "" || entry.ConditionPLCID <= 0 || entry.ConditionTagID <= 0 {
+				return nil, fmt.Errorf("%s: validador %d requer tag, condition_plc_id e condition_tag_id", l.path, i)
+			}
+			validators = append(validators, ConditionValidator{
+				TagName:        entry.Tag,
+				ConditionPLCID: entry.ConditionPLCID,
+				ConditionTagID: entry.ConditionTagID,
+				Message:        entry.Message,
+			})
+		default:
+			return nil, fmt.Errorf("%s: validador %d com tipo desconhecido %q", l.path, i, entry.Type)
+		}
+	}
+
+	return validators, nil
+}
@@ -3,15 +3,44 @@ package service
 
 import (
 	"app_padrao/internal/domain"
+	"errors"
+	"fmt"
+	"strings"
 	"time"
+
+	"github.com/go-redis/redis/v8"
 )
 
+// defaultNotificationChannels são os canais aceitos quando nenhuma lista é configurada
+var defaultNotificationChannels = []string{"email", "push", "sms", "teams"}
+
 type ProfileService struct {
-	repo domain.ProfileRepository
+	repo                 domain.ProfileRepository
+	notificationChannels []string
+
+	// Publicação das trocas de tema (nil = sem eventos)
+	themeClient *redis.Client
+	keyPrefix   string
+
+	// Verificação das tags referenciadas no painel (nil = sem verificação)
+	dashboardTags domain.PLCTagRepository
+	dashboardPLCs domain.PLCRepository
 }
 
 func NewProfileService(repo domain.ProfileRepository) *ProfileService {
-	return &ProfileService{repo: repo}
+	return &ProfileService{
+		repo:                 repo,
+		notificationChannels: defaultNotificationChannels,
+	}
+}
+
+// SetNotificationChannels define os canais de notificação aceitos nos perfis
+func (s *ProfileService) SetNotificationChannels(channels []string) {
+	if len(channels) == 0 {
+		s.notificationChannels = defaultNotificationChannels
+		return
+	}
+	s.notificationChannels = channels
 }
 
 func (s *ProfileService) Create(profile domain.Profile) (int, error) {
@@ -33,3 +62,108 @@ func (s *ProfileService) Update(profile domain.Profile) error {
 func (s *ProfileService) Delete(id int) error {
 	return s.repo.Delete(id)
 }
+
+// Pontuação de cada item do perfil usada no indicador de preenchimento
+var profileCompletenessRules = []struct {
+	field  string
+	points int
+	filled func(profile domain.Profile, user domain.User) bool
+}{
+	{"full_name", 20, func(p domain.Profile, u domain.User) bool { return strings.TrimSpace(u.FullName) != "" }},
+	{"email_verified", 10, func(p domain.Profile, u domain.User) bool { return u.EmailVerifiedAt != nil }},
+	{"bio", 15, func(p domain.Profile, u domain.User) bool { return strings.TrimSpace(p.Bio) != "" }},
+	{"department", 15, func(p domain.Profile, u domain.User) bool { return strings.TrimSpace(p.Department) != "" }},
+	{"avatar", 20, func(p domain.Profile, u domain.User) bool { return p.AvatarURL != "" || u.AvatarURL != "" }},
+	{"phone", 20, func(p domain.Profile, u domain.User) bool { return strings.TrimSpace(u.Phone) != "" }},
+}
+
+// CompletenessScore calcula de 0 a 100 o quanto o perfil está preenchido
+// e lista os campos que ainda faltam
+func (s *ProfileService) CompletenessScore(profile domain.Profile, user domain.User) (score int, missing []string) {
+	missing = []string{}
+
+	for _, rule := range profileCompletenessRules {
+		if rule.filled(profile, user) {
+			score += rule.points
+		} else {
+			missing = append(missing, rule.field)
+		}
+	}
+
+	return score, missing
+}
+
+// AllowedNotificationChannels retorna os canais de notificação aceitos
+func (s *ProfileService) AllowedNotificationChannels() []string {
+	allowed := make([]string, len(s.notificationChannels))
+	copy(allowed, s.notificationChannels)
+	return allowed
+}
+
+// ValidateNotificationChannels verifica se todos os canais estão na lista
+// permitida e se nenhum aparece mais de uma vez
+func (s *ProfileService) ValidateNotificationChannels(channels domain.NotificationChannels) error {
+	seen := make(map[string]bool, len(channels))
+	for _, c := range channels {
+		if !s.isAllowedChannel(c.Channel) {
+			return fmt.Errorf("%w: %q", domain.ErrInvalidNotificationChannel, c.Channel)
+		}
+		if seen[c.Channel] {
+			return fmt.Errorf("%w: %q", domain.ErrDuplicateNotificationChannel, c.Channel)
+		}
+		seen[c.Channel] = true
+	}
+	return nil
+}
+
+func (s *ProfileService) isAllowedChannel(channel string) bool {
+	for _, allowed := range s.notificationChannels {
+		if allowed == channel {
+			return true
+		}
+	}
+	return false
+}
+
+// GetNotificationChannels retorna as preferências de notificação do usuário,
+// usando as padrão se o perfil ainda não existir
+func (s *ProfileService) GetNotificationChannels(userID int) (domain.NotificationChannels, error) {
+	profile, err := s.repo.GetByUserID(userID)
+	if err != nil {
+		if errors.Is(err, domain.ErrProfileNotFound) {
+			return domain.DefaultNotificationChannels(), nil
+		}
+		return nil, err
+	}
+	return profile.NotificationPreferences, nil
+}
+
+// UpdateNotificationChannels substitui as preferências de notificação do usuário
+func (s *ProfileService) UpdateNotificationChannels(userID int, channels domain.NotificationChannels) (domain.NotificationChannels, error) {
+	if channels == nil {
+		channels = domain.NotificationChannels{}
+	}
+	if err := s.ValidateNotificationChannels(channels); err != nil {
+		return nil, err
+	}
+
+	profile, err := s.repo.GetByUserID(userID)
+	if err != nil {
+		if !errors.Is(err, domain.ErrProfileNotFound) {
+			return nil, err
+		}
+		profile = domain.Profile{
+			UserID:    userID,
+			Theme:     "default",
+			FontSize:  "medium",
+			Language:  "pt_BR",
+			CreatedAt: time.Now(),
+		}
+	}
+
+	profile.NotificationPreferences = channels
+	if err := s.Update(profile); err != nil {
+		return nil, err
+	}
+	return channels, nil
+}
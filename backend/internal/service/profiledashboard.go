@@ -0,0 +1,79 @@
+// internal/service/profiledashboard.go
+package service
+
+import (
+	"app_padrao/internal/domain"
+	"errors"
+	"fmt"
+)
+
+// SetDashboardTagRepositories define os repositórios usados para verificar se
+// as tags dos widgets existem e pertencem a um PLC cadastrado
+func (s *ProfileService) SetDashboardTagRepositories(tags domain.PLCTagRepository, plcs domain.PLCRepository) {
+	s.dashboardTags = tags
+	s.dashboardPLCs = plcs
+}
+
+// GetDashboard retorna os widgets do painel do usuário
+func (s *ProfileService) GetDashboard(userID int) ([]domain.DashboardWidget, error) {
+	return s.repo.GetDashboardConfig(userID)
+}
+
+// UpdateDashboard valida e substitui os widgets do painel do usuário
+func (s *ProfileService) UpdateDashboard(userID int, widgets []domain.DashboardWidget) ([]domain.DashboardWidget, error) {
+	if widgets == nil {
+		widgets = []domain.DashboardWidget{}
+	}
+	if len(widgets) > domain.MaxDashboardWidgets {
+		return nil, fmt.Errorf("%w: %d widgets (máximo %d)", domain.ErrDashboardTooLarge, len(widgets), domain.MaxDashboardWidgets)
+	}
+
+	for i, widget := range widgets {
+		if err := widget.Validate(); err != nil {
+			return nil, fmt.Errorf("widget %d: %w", i, err)
+		}
+		if widget.RequiresTag() {
+			if err := s.checkDashboardTag(widget.TagID); err != nil {
+				return nil, fmt.Errorf("widget %d: %w", i, err)
+			}
+		}
+	}
+
+	if err := s.repo.UpdateDashboardConfig(userID, widgets); err != nil {
+		return nil, err
+	}
+	return widgets, nil
+}
+
+// ResetDashboard esvazia o painel do usuário
+func (s *ProfileService) ResetDashboard(userID int) error {
+	return s.repo.UpdateDashboardConfig(userID, []domain.DashboardWidget{})
+}
+
+// checkDashboardTag verifica se a tag existe e pertence a um PLC cadastrado.
+// A leitura de PLCs é liberada a qualquer usuário autenticado, então não há
+// permissão por PLC a verificar.
+func (s *ProfileService) checkDashboardTag(tagID int) error {
+	if s.dashboardTags == nil {
+		return nil
+	}
+
+	tag, err := s.dashboardTags.GetByID(tagID)
+	if err != nil {
+		if errors.Is(err, domain.ErrPLCTagNotFound) {
+			return fmt.Errorf("%w: tag %d: %w", domain.ErrInvalidDashboardWidget, tagID, err)
+		}
+		return err
+	}
+
+	if s.dashboardPLCs == nil {
+		return nil
+	}
+	if _, err := s.dashboardPLCs.GetByID(tag.PLCID); err != nil {
+		if errors.Is(err, domain.ErrPLCNotFound) {
+			return fmt.Errorf("%w: tag %d: %w", domain.ErrInvalidDashboardWidget, tagID, err)
+		}
+		return err
+	}
+	return nil
+}
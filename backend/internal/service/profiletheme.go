@@ -0,0 +1,123 @@
+// internal/service/profiletheme.go
+package service
+
+import (
+	"app_padrao/internal/domain"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// themeChangedChannel é o canal Redis em que as trocas de tema de um usuário
+// são publicadas, para que as sessões abertas apliquem o tema sem recarregar
+const themeChangedChannel = "user:%d:theme_changed"
+
+// ErrThemeEventsNotConfigured indica que não há Redis para os eventos de tema
+var ErrThemeEventsNotConfigured = errors.New("eventos de tema não configurados")
+
+// themeChangedMessage é o conteúdo publicado em themeChangedChannel
+type themeChangedMessage struct {
+	Theme string `json:"theme"`
+}
+
+// SetThemeNotifier define o Redis usado para publicar e assinar as trocas de tema
+func (s *ProfileService) SetThemeNotifier(client *redis.Client, keyPrefix string) {
+	s.themeClient = client
+	s.keyPrefix = keyPrefix
+}
+
+// UpdateTheme troca o tema do perfil do usuário e avisa suas sessões abertas.
+// changedBy é quem fez a troca (o próprio usuário ou um administrador).
+func (s *ProfileService) UpdateTheme(userID int, theme string, changedBy int) error {
+	profile, err := s.repo.GetByUserID(userID)
+	if err != nil {
+		if !errors.Is(err, domain.ErrProfileNotFound) {
+			return err
+		}
+		profile = domain.Profile{
+			UserID:                  userID,
+			NotificationPreferences: domain.DefaultNotificationChannels(),
+			FontSize:                "medium",
+			Language:                "pt_BR",
+			CreatedAt:               time.Now(),
+		}
+	}
+
+	profile.Theme = theme
+	if err := s.Update(profile); err != nil {
+		return err
+	}
+
+	log.Printf("Auditoria: entity_type=profile entity_id=%d action=theme_change user_id=%d theme=%q",
+		userID, changedBy, theme)
+
+	s.publishThemeChanged(userID, theme)
+	return nil
+}
+
+// publishThemeChanged publica a troca de tema; falhas apenas são registradas,
+// pois o tema já foi gravado e será aplicado na próxima carga da página
+func (s *ProfileService) publishThemeChanged(userID int, theme string) {
+	if s.themeClient == nil {
+		return
+	}
+
+	data, err := json.Marshal(themeChangedMessage{Theme: theme})
+	if err != nil {
+		return
+	}
+
+	channel := s.keyPrefix + fmt.Sprintf(themeChangedChannel, userID)
+	if err := s.themeClient.Publish(context.Background(), channel, data).Err(); err != nil {
+		log.Printf("Aviso: erro ao publicar troca de tema do usuário %d: %v", userID, err)
+	}
+}
+
+// SubscribeThemeChanges entrega os novos temas do usuário até ctx ser
+// cancelado, quando o canal retornado é fechado
+func (s *ProfileService) SubscribeThemeChanges(ctx context.Context, userID int) (<-chan string, error) {
+	if s.themeClient == nil {
+		return nil, ErrThemeEventsNotConfigured
+	}
+
+	pubsub := s.themeClient.Subscribe(ctx, s.keyPrefix+fmt.Sprintf(themeChangedChannel, userID))
+	// Confirmar a assinatura antes de retornar, para não perder publicações
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return nil, err
+	}
+
+	themes := make(chan string, 1)
+	go func() {
+		defer close(themes)
+		defer pubsub.Close()
+
+		messages := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-messages:
+				if !ok {
+					return
+				}
+				var payload themeChangedMessage
+				if err := json.Unmarshal([]byte(msg.Payload), &payload); err != nil || payload.Theme == "" {
+					continue
+				}
+				select {
+				case themes <- payload.Theme:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return themes, nil
+}
@@ -28,3 +28,7 @@ func (s *RoleService) GetByName(name string) (domain.Role, error) {
 func (s *RoleService) GetPermissions(roleID int) ([]domain.Permission, error) {
 	return s.repo.GetPermissions(roleID)
 }
+
+func (s *RoleService) GetEffectivePermissions(roleID int) ([]domain.Permission, error) {
+	return s.repo.GetEffectivePermissions(roleID)
+}
@@ -0,0 +1,120 @@
+// internal/service/tagannotation.go
+package service
+
+import (
+	"app_padrao/internal/domain"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// ErrTagAnnotationsNotConfigured indica que o repositório de anotações não foi definido
+var ErrTagAnnotationsNotConfigured = errors.New("anotações de tags não configuradas")
+
+// SetTagAnnotationRepository define onde as anotações das tags são
+// persistidas. userRepo identifica os administradores, que podem excluir
+// anotações de outros usuários.
+func (s *PLCService) SetTagAnnotationRepository(repo domain.TagAnnotationRepository, userRepo domain.UserRepository) {
+	s.annotationRepo = repo
+	s.userRepo = userRepo
+}
+
+// CreateTagAnnotation registra uma anotação na tag em nome do usuário
+func (s *PLCService) CreateTagAnnotation(annotation domain.TagAnnotation) (int, error) {
+	if s.annotationRepo == nil {
+		return 0, ErrTagAnnotationsNotConfigured
+	}
+
+	annotation.Text = strings.TrimSpace(annotation.Text)
+	if err := annotation.Validate(); err != nil {
+		return 0, err
+	}
+
+	if _, err := s.pgTagRepo.GetByID(annotation.TagID); err != nil {
+		return 0, err
+	}
+
+	id, err := s.annotationRepo.Create(annotation)
+	if err != nil {
+		return 0, fmt.Errorf("erro ao criar anotação no banco de dados: %w", err)
+	}
+
+	log.Printf("Auditoria: entity_type=tag_annotation entity_id=%d action=create user_id=%d tag_id=%d",
+		id, annotation.UserID, annotation.TagID)
+	return id, nil
+}
+
+// GetTagAnnotations retorna as anotações da tag, da mais recente para a mais antiga
+func (s *PLCService) GetTagAnnotations(tagID int) ([]domain.TagAnnotation, error) {
+	if s.annotationRepo == nil {
+		return nil, ErrTagAnnotationsNotConfigured
+	}
+
+	if _, err := s.pgTagRepo.GetByID(tagID); err != nil {
+		return nil, err
+	}
+
+	return s.annotationRepo.GetByTagID(tagID)
+}
+
+// CountTagAnnotations retorna quantas anotações a tag possui
+func (s *PLCService) CountTagAnnotations(tagID int) (int, error) {
+	if s.annotationRepo == nil {
+		return 0, ErrTagAnnotationsNotConfigured
+	}
+	return s.annotationRepo.CountByTagID(tagID)
+}
+
+// DeleteTagAnnotation remove uma anotação da tag. Apenas o autor ou um
+// administrador pode excluí-la.
+func (s *PLCService) DeleteTagAnnotation(tagID, annotationID, userID int) error {
+	if s.annotationRepo == nil {
+		return ErrTagAnnotationsNotConfigured
+	}
+
+	annotation, err := s.annotationRepo.GetByID(annotationID)
+	if err != nil {
+		return err
+	}
+	if annotation.TagID != tagID {
+		return domain.ErrTagAnnotationNotFound
+	}
+
+	if annotation.UserID == 0 || annotation.UserID != userID {
+		isAdmin, err := s.isAdmin(userID)
+		if err != nil {
+			return err
+		}
+		if !isAdmin {
+			return domain.ErrTagAnnotationForbidden
+		}
+	}
+
+	if err := s.annotationRepo.Delete(annotationID); err != nil {
+		if errors.Is(err, domain.ErrTagAnnotationNotFound) {
+			return err
+		}
+		return fmt.Errorf("erro ao excluir anotação do banco de dados: %w", err)
+	}
+
+	log.Printf("Auditoria: entity_type=tag_annotation entity_id=%d action=delete user_id=%d tag_id=%d author_id=%d",
+		annotationID, userID, tagID, annotation.UserID)
+	return nil
+}
+
+// isAdmin indica se o usuário tem a role de administrador
+func (s *PLCService) isAdmin(userID int) (bool, error) {
+	if s.userRepo == nil {
+		return false, nil
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return false, nil
+		}
+		return false, fmt.Errorf("erro ao buscar usuário %d: %w", userID, err)
+	}
+	return user.Role == "admin", nil
+}
@@ -0,0 +1,301 @@
+// internal/service/tagdependency.go
+package service
+
+import (
+	"app_padrao/internal/domain"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+)
+
+// ErrTagDependenciesNotConfigured indica que o repositório de dependências não foi definido
+var ErrTagDependenciesNotConfigured = errors.New("dependências de tags não configuradas")
+
+// SetTagDependencyRepository define onde as dependências das tags são persistidas
+func (s *PLCService) SetTagDependencyRepository(repo domain.TagDependencyRepository) {
+	s.dependencyRepo = repo
+}
+
+// CreateTagDependency registra uma dependência da tag, rejeitando as que
+// formariam um ciclo na ordem de escrita
+func (s *PLCService) CreateTagDependency(dependency domain.TagDependency, userID int) (int, error) {
+	if s.dependencyRepo == nil {
+		return 0, ErrTagDependenciesNotConfigured
+	}
+
+	if dependency.DependencyType == "" {
+		dependency.DependencyType = domain.TagDependencyBefore
+	}
+	if err := dependency.Validate(); err != nil {
+		return 0, err
+	}
+
+	for _, tagID := range []int{dependency.TagID, dependency.DependsOnTagID} {
+		if _, err := s.pgTagRepo.GetByID(tagID); err != nil {
+			return 0, err
+		}
+	}
+
+	existing, err := s.dependencyRepo.GetAll()
+	if err != nil {
+		return 0, fmt.Errorf("erro ao buscar dependências: %w", err)
+	}
+	first, second := dependency.WriteOrder()
+	if writeOrderReaches(existing, second, first) {
+		return 0, domain.ErrTagDependencyCycle
+	}
+
+	id, err := s.dependencyRepo.Create(dependency)
+	if err != nil {
+		if errors.Is(err, domain.ErrTagDependencyExists) {
+			return 0, err
+		}
+		return 0, fmt.Errorf("erro ao criar dependência no banco de dados: %w", err)
+	}
+
+	log.Printf("Auditoria: entity_type=tag_dependency entity_id=%d action=create user_id=%d tag_id=%d depends_on_tag_id=%d type=%s delay_ms=%d",
+		id, userID, dependency.TagID, dependency.DependsOnTagID, dependency.DependencyType, dependency.DelayMs)
+	return id, nil
+}
+
+// GetTagDependencies retorna as dependências declaradas pela tag
+func (s *PLCService) GetTagDependencies(tagID int) ([]domain.TagDependency, error) {
+	if s.dependencyRepo == nil {
+		return nil, ErrTagDependenciesNotConfigured
+	}
+
+	if _, err := s.pgTagRepo.GetByID(tagID); err != nil {
+		return nil, err
+	}
+
+	return s.dependencyRepo.GetByTagID(tagID)
+}
+
+// DeleteTagDependency remove uma dependência da tag
+func (s *PLCService) DeleteTagDependency(tagID, dependencyID, userID int) error {
+	if s.dependencyRepo == nil {
+		return ErrTagDependenciesNotConfigured
+	}
+
+	dependency, err := s.dependencyRepo.GetByID(dependencyID)
+	if err != nil {
+		return err
+	}
+	if dependency.TagID != tagID {
+		return domain.ErrTagDependencyNotFound
+	}
+
+	if err := s.dependencyRepo.Delete(dependencyID); err != nil {
+		if errors.Is(err, domain.ErrTagDependencyNotFound) {
+			return err
+		}
+		return fmt.Errorf("erro ao excluir dependência do banco de dados: %w", err)
+	}
+
+	log.Printf("Auditoria: entity_type=tag_dependency entity_id=%d action=delete user_id=%d tag_id=%d depends_on_tag_id=%d",
+		dependencyID, userID, tagID, dependency.DependsOnTagID)
+	return nil
+}
+
+// writeOrderReaches indica, por busca em profundidade, se from alcança to no
+// grafo de ordem de escrita formado pelas dependências
+func writeOrderReaches(dependencies []domain.TagDependency, from, to int) bool {
+	next := make(map[int][]int)
+	for _, d := range dependencies {
+		first, second := d.WriteOrder()
+		next[first] = append(next[first], second)
+	}
+
+	visited := make(map[int]bool)
+	var visit func(tagID int) bool
+	visit = func(tagID int) bool {
+		if tagID == to {
+			return true
+		}
+		if visited[tagID] {
+			return false
+		}
+		visited[tagID] = true
+		for _, n := range next[tagID] {
+			if visit(n) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return visit(from)
+}
+
+// WriteTagWithDependencies escreve a tag junto com toda a sua cadeia de
+// dependências, em ordem topológica e aguardando o atraso de cada
+// dependência. dependencyValues informa, pelo nome da tag, o valor de cada
+// tag dependente. Cada escrita usa o PLC e o ID da tag do plano, não o nome,
+// que pode se repetir entre PLCs. A sequência para na primeira falha ou
+// quando ctx termina; o recibo lista o que foi escrito mesmo quando um erro é
+// retornado. Se a soma dos atrasos não couber no prazo de ctx, nada é escrito.
+func (s *PLCService) WriteTagWithDependencies(ctx context.Context, tagName string, value interface{}, dependencyValues map[string]interface{}, userID int) (domain.TagWriteReceipt, error) {
+	if s.dependencyRepo == nil {
+		return domain.TagWriteReceipt{}, ErrTagDependenciesNotConfigured
+	}
+
+	s.mu.RLock()
+	isRunning := s.isRunning
+	s.mu.RUnlock()
+
+	if !isRunning || s.manager == nil {
+		return domain.TagWriteReceipt{}, ErrMonitoringNotActive
+	}
+
+	// Mesma resolução por nome de WriteTagByName: a primeira tag encontrada
+	tags, err := s.pgTagRepo.GetByName(tagName)
+	if err != nil {
+		return domain.TagWriteReceipt{}, fmt.Errorf("erro ao buscar tag '%s': %w", tagName, err)
+	}
+	if len(tags) == 0 {
+		return domain.TagWriteReceipt{}, fmt.Errorf("%w: '%s'", ErrTagNotFound, tagName)
+	}
+	target := tags[0]
+
+	plan, err := s.planDependencyWrites(target, value, dependencyValues)
+	if err != nil {
+		return domain.TagWriteReceipt{}, err
+	}
+
+	// Intertravamentos de todas as escritas são verificados antes da primeira
+	totalDelay := time.Duration(0)
+	for _, w := range plan {
+		if err := s.validateWrite(w.TagName, w.Value, userID); err != nil {
+			return domain.TagWriteReceipt{}, err
+		}
+		totalDelay += time.Duration(w.DelayMs) * time.Millisecond
+	}
+
+	// Uma sequência que não termina no prazo ficaria pela metade
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) <= totalDelay {
+		return domain.TagWriteReceipt{}, fmt.Errorf("%w: %v", domain.ErrTagDependencyDelayTooLong, totalDelay)
+	}
+
+	receipt := domain.TagWriteReceipt{TagID: target.ID, Success: true, Writes: plan}
+	var writeErr error
+	for i := range receipt.Writes {
+		w := &receipt.Writes[i]
+		if writeErr != nil {
+			w.Skipped = true
+			continue
+		}
+
+		if w.DelayMs > 0 {
+			timer := time.NewTimer(time.Duration(w.DelayMs) * time.Millisecond)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				w.Skipped = true
+				receipt.Success = false
+				writeErr = fmt.Errorf("sequência interrompida antes de '%s': %w", w.TagName, ctx.Err())
+				continue
+			}
+		}
+
+		if err := s.manager.WriteTagByID(w.PLCID, w.TagID, w.Value, userID); err != nil {
+			w.Error = err.Error()
+			receipt.Success = false
+			writeErr = fmt.Errorf("erro ao escrever tag '%s': %w", w.TagName, err)
+			continue
+		}
+		w.Success = true
+	}
+
+	log.Printf("Auditoria: entity_type=plc_tag entity_id=%d action=write_with_dependencies user_id=%d writes=%d success=%t",
+		target.ID, userID, len(receipt.Writes), receipt.Success)
+	return receipt, writeErr
+}
+
+// planDependencyWrites reúne a cadeia de dependências da tag e a ordena
+// topologicamente (algoritmo de Kahn, com empate pelo menor ID). O atraso de
+// cada escrita é o maior DelayMs entre as dependências que a precedem.
+func (s *PLCService) planDependencyWrites(target domain.PLCTag, value interface{}, dependencyValues map[string]interface{}) ([]domain.TagWriteResult, error) {
+	tags := map[int]domain.PLCTag{target.ID: target}
+	var dependencies []domain.TagDependency
+
+	pending := []int{target.ID}
+	for len(pending) > 0 {
+		tagID := pending[0]
+		pending = pending[1:]
+
+		deps, err := s.dependencyRepo.GetByTagID(tagID)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao buscar dependências da tag %d: %w", tagID, err)
+		}
+		for _, d := range deps {
+			dependencies = append(dependencies, d)
+			if _, seen := tags[d.DependsOnTagID]; seen {
+				continue
+			}
+			tag, err := s.pgTagRepo.GetByID(d.DependsOnTagID)
+			if err != nil {
+				return nil, fmt.Errorf("erro ao buscar tag dependente %d: %w", d.DependsOnTagID, err)
+			}
+			tags[tag.ID] = tag
+			pending = append(pending, tag.ID)
+		}
+	}
+
+	inDegree := make(map[int]int, len(tags))
+	delays := make(map[int]int, len(tags))
+	next := make(map[int][]int)
+	for _, d := range dependencies {
+		first, second := d.WriteOrder()
+		next[first] = append(next[first], second)
+		inDegree[second]++
+		if d.DelayMs > delays[second] {
+			delays[second] = d.DelayMs
+		}
+	}
+
+	var ready []int
+	for id := range tags {
+		if inDegree[id] == 0 {
+			ready = append(ready, id)
+		}
+	}
+
+	plan := make([]domain.TagWriteResult, 0, len(tags))
+	for len(ready) > 0 {
+		sort.Ints(ready)
+		id := ready[0]
+		ready = ready[1:]
+
+		tag := tags[id]
+		v := value
+		if id != target.ID {
+			var ok bool
+			if v, ok = dependencyValues[tag.Name]; !ok || v == nil {
+				return nil, fmt.Errorf("%w: '%s'", domain.ErrTagDependencyValueMissing, tag.Name)
+			}
+		}
+		plan = append(plan, domain.TagWriteResult{
+			TagID:   id,
+			PLCID:   tag.PLCID,
+			TagName: tag.Name,
+			Value:   v,
+			DelayMs: delays[id],
+		})
+
+		for _, n := range next[id] {
+			inDegree[n]--
+			if inDegree[n] == 0 {
+				ready = append(ready, n)
+			}
+		}
+	}
+
+	if len(plan) != len(tags) {
+		return nil, domain.ErrTagDependencyCycle
+	}
+	return plan, nil
+}
@@ -4,27 +4,69 @@ package service
 import (
 	"app_padrao/internal/domain"
 	"app_padrao/pkg/jwt"
+	"app_padrao/pkg/password"
+	"fmt"
 	"log"
+	"net/url"
+	"strings"
 	"time"
 
 	"golang.org/x/crypto/bcrypt"
 )
 
+// Validade do link de verificação de email
+const emailVerificationTTL = 48 * time.Hour
+
 type UserService struct {
 	repo          domain.UserRepository
 	jwtSecretKey  string
 	expirationHrs int
+
+	// Envio do link de verificação de email (opcional)
+	mailer    domain.Mailer
+	publicURL string
+
+	// Política de senha e custo do hash
+	passwordPolicy password.PasswordPolicy
+	bcryptCost     int
+	// Domínios de email aceitos no cadastro (opcional)
+	emailDomains *emailDomainAllowlist
+	// Segredo JWT atual e anterior, para rotação sem reinício (opcional)
+	jwtSecrets *jwtSecrets
 }
 
 func NewUserService(repo domain.UserRepository, jwtKey string, expHours int) *UserService {
 	return &UserService{
-		repo:          repo,
-		jwtSecretKey:  jwtKey,
-		expirationHrs: expHours,
+		repo:           repo,
+		jwtSecretKey:   jwtKey,
+		expirationHrs:  expHours,
+		passwordPolicy: password.DefaultPolicy(),
+		bcryptCost:     bcrypt.DefaultCost,
 	}
 }
 
+// SetPasswordPolicy configura a política de senha e o custo do bcrypt
+func (s *UserService) SetPasswordPolicy(policy password.PasswordPolicy, bcryptCost int) {
+	s.passwordPolicy = policy
+	if bcryptCost >= bcrypt.MinCost && bcryptCost <= bcrypt.MaxCost {
+		s.bcryptCost = bcryptCost
+	} else {
+		log.Printf("Custo bcrypt %d inválido, mantendo %d", bcryptCost, s.bcryptCost)
+	}
+}
+
+// SetMailer configura o envio de emails e a URL pública usada nos links
+func (s *UserService) SetMailer(mailer domain.Mailer, publicURL string) {
+	s.mailer = mailer
+	s.publicURL = strings.TrimRight(publicURL, "/")
+}
+
 func (s *UserService) Register(user domain.User) (int, error) {
+	// Verificar se o domínio do email é aceito
+	if err := s.checkEmailDomain(user.Email); err != nil {
+		return 0, err
+	}
+
 	// Verificar se email já existe
 	_, err := s.repo.GetByEmail(user.Email)
 	if err == nil {
@@ -33,8 +75,21 @@ func (s *UserService) Register(user domain.User) (int, error) {
 		return 0, err
 	}
 
+	// Verificar se o nome de usuário já existe
+	_, err = s.repo.GetByUsername(user.Username)
+	if err == nil {
+		return 0, domain.ErrUsernameInUse
+	} else if err != domain.ErrUserNotFound {
+		return 0, err
+	}
+
+	// Validar força da senha
+	if err := password.Check(user.Password, s.passwordPolicy); err != nil {
+		return 0, err
+	}
+
 	// Hash da senha
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password), s.bcryptCost)
 	if err != nil {
 		return 0, err
 	}
@@ -55,7 +110,50 @@ func (s *UserService) Register(user domain.User) (int, error) {
 		user.IsActive = true
 	}
 
-	return s.repo.Create(user)
+	id, err := s.repo.Create(user)
+	if err != nil {
+		return 0, err
+	}
+
+	// Enviar link de verificação sem bloquear o cadastro em caso de falha
+	if err := s.sendVerificationEmail(id, user.Email); err != nil {
+		log.Printf("Erro ao enviar email de verificação para %s: %v", user.Email, err)
+	}
+
+	return id, nil
+}
+
+// sendVerificationEmail gera o token de verificação e envia o link por email
+func (s *UserService) sendVerificationEmail(userID int, email string) error {
+	if s.mailer == nil {
+		return nil
+	}
+
+	primary, _ := s.currentJWTSecrets()
+	token, err := jwt.GeneratePurposeToken(userID, jwt.PurposeEmailVerification, primary, emailVerificationTTL)
+	if err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/auth/email/verify?token=%s", s.publicURL, url.QueryEscape(token))
+	body := fmt.Sprintf("Confirme seu email acessando o link abaixo (válido por %d horas):\n%s",
+		int(emailVerificationTTL.Hours()), link)
+
+	return s.mailer.Send(email, "Confirme seu email", body)
+}
+
+// VerifyEmail valida o token recebido por email e marca o email como verificado
+func (s *UserService) VerifyEmail(token string) error {
+	primary, old := s.currentJWTSecrets()
+	userID, err := jwt.ValidatePurposeToken(token, jwt.PurposeEmailVerification, primary)
+	if err != nil && old != "" {
+		userID, err = jwt.ValidatePurposeToken(token, jwt.PurposeEmailVerification, old)
+	}
+	if err != nil {
+		return domain.ErrInvalidVerifyToken
+	}
+
+	return s.repo.SetEmailVerified(userID, time.Now())
 }
 
 func (s *UserService) GetByID(id int) (domain.User, error) {
@@ -87,7 +185,8 @@ func (s *UserService) Login(email, password string) (string, domain.User, error)
 	}
 
 	// Gerar token JWT
-	token, err := jwt.GenerateToken(user.ID, s.jwtSecretKey, s.expirationHrs)
+	primary, _ := s.currentJWTSecrets()
+	token, err := jwt.GenerateToken(user.ID, primary, s.expirationHrs)
 	if err != nil {
 		return "", domain.User{}, err
 	}
@@ -102,6 +201,74 @@ func (s *UserService) Login(email, password string) (string, domain.User, error)
 	return token, user, nil
 }
 
+// checkPassword confere a senha informada com o hash armazenado do usuário
+func (s *UserService) checkPassword(userID int, plain string) error {
+	user, err := s.repo.GetByID(userID)
+	if err != nil {
+		return err
+	}
+
+	// GetByID não retorna o hash da senha
+	withHash, err := s.repo.GetByEmail(user.Email)
+	if err != nil {
+		return err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(withHash.Password), []byte(plain)); err != nil {
+		return domain.ErrInvalidCredentials
+	}
+	return nil
+}
+
+// ChangePassword troca a senha do usuário após conferir a senha atual
+func (s *UserService) ChangePassword(userID int, currentPassword, newPassword string) error {
+	if err := s.checkPassword(userID, currentPassword); err != nil {
+		return err
+	}
+
+	if err := password.Check(newPassword, s.passwordPolicy); err != nil {
+		return err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), s.bcryptCost)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.UpdatePassword(userID, string(hashedPassword)); err != nil {
+		return err
+	}
+
+	// Tokens emitidos antes da troca deixam de valer
+	return s.repo.RevokeSessions(userID, time.Now())
+}
+
+// DeleteAccount exclui (soft delete) a conta do usuário após conferir a
+// senha. A exclusão também revoga as sessões do usuário.
+func (s *UserService) DeleteAccount(userID int, password string) error {
+	if err := s.checkPassword(userID, password); err != nil {
+		return err
+	}
+
+	return s.repo.SoftDelete(userID, time.Now())
+}
+
+// checkSessionRevoked recusa tokens de usuários excluídos e tokens emitidos
+// antes da última revogação das sessões do usuário (troca de senha)
+func (s *UserService) checkSessionRevoked(claims *jwt.Claims) error {
+	revokedAt, err := s.repo.GetSessionsRevokedAt(claims.UserID)
+	if err != nil {
+		return err
+	}
+
+	// revokedAt é truncado em segundos, como o iat do token: tokens emitidos
+	// no mesmo segundo da revogação continuam válidos
+	if !revokedAt.IsZero() && (claims.IssuedAt == nil || claims.IssuedAt.Time.Before(revokedAt)) {
+		return domain.ErrSessionRevoked
+	}
+	return nil
+}
+
 func (s *UserService) Update(user domain.User) error {
 	return s.repo.Update(user)
 }
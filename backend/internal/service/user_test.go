@@ -0,0 +1,77 @@
+package service
+
+import (
+	"app_padrao/internal/domain"
+	"errors"
+	"testing"
+)
+
+// fakeUserRepo implementa a consulta por email e por nome de usuário usada no
+// cadastro e registra as criações
+type fakeUserRepo struct {
+	domain.UserRepository
+	byEmail     map[string]domain.User
+	byUsername  map[string]domain.User
+	usernameErr error
+	created     []domain.User
+}
+
+func (r *fakeUserRepo) GetByEmail(email string) (domain.User, error) {
+	if user, ok := r.byEmail[email]; ok {
+		return user, nil
+	}
+	return domain.User{}, domain.ErrUserNotFound
+}
+
+func (r *fakeUserRepo) GetByUsername(username string) (domain.User, error) {
+	if r.usernameErr != nil {
+		return domain.User{}, r.usernameErr
+	}
+	if user, ok := r.byUsername[username]; ok {
+		return user, nil
+	}
+	return domain.User{}, domain.ErrUserNotFound
+}
+
+func (r *fakeUserRepo) Create(user domain.User) (int, error) {
+	r.created = append(r.created, user)
+	return len(r.created), nil
+}
+
+func TestRegisterUsernameUniqueness(t *testing.T) {
+	dbErr := errors.New("conexão perdida")
+
+	tests := []struct {
+		name        string
+		user        domain.User
+		usernameErr error
+		wantErr     error
+		wantCreated int
+	}{
+		{"nome de usuário em uso", domain.User{Username: "maria", Email: "outra@empresa.com", Password: "Senha123"}, nil, domain.ErrUsernameInUse, 0},
+		{"email em uso tem prioridade", domain.User{Username: "maria", Email: "maria@empresa.com", Password: "Senha123"}, nil, domain.ErrEmailInUse, 0},
+		{"erro do banco na consulta", domain.User{Username: "joao", Email: "joao@empresa.com", Password: "Senha123"}, dbErr, dbErr, 0},
+		{"nome livre", domain.User{Username: "joao", Email: "joao@empresa.com", Password: "Senha123"}, nil, nil, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			existing := domain.User{ID: 1, Username: "maria", Email: "maria@empresa.com"}
+			repo := &fakeUserRepo{
+				byEmail:     map[string]domain.User{existing.Email: existing},
+				byUsername:  map[string]domain.User{existing.Username: existing},
+				usernameErr: tt.usernameErr,
+			}
+			s := NewUserService(repo, "segredo", 1)
+			s.SetPasswordPolicy(s.passwordPolicy, 4)
+
+			_, err := s.Register(tt.user)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("erro = %v, esperado %v", err, tt.wantErr)
+			}
+			if len(repo.created) != tt.wantCreated {
+				t.Errorf("usuários criados = %d, esperado %d", len(repo.created), tt.wantCreated)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+//go:build integration
+
+// internal/testutil/containers.go
+package testutil
+
+import (
+	"app_padrao/internal/cache"
+	"context"
+	"database/sql"
+	"testing"
+
+	_ "github.com/lib/pq"
+	"github.com/testcontainers/testcontainers-go"
+	tcpostgres "github.com/testcontainers/testcontainers-go/modules/postgres"
+	tcredis "github.com/testcontainers/testcontainers-go/modules/redis"
+)
+
+// Imagens usadas nos testes de integração
+const (
+	postgresImage = "postgres:16-alpine"
+	redisImage    = "redis:7-alpine"
+)
+
+// baseSchema cria as tabelas que os repositórios esperam já existir. As
+// colunas acrescentadas depois são criadas pelo ensureSchema de cada
+// repositório, como em produção.
+const baseSchema = `
+CREATE TABLE plcs (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    ip_address VARCHAR(64) NOT NULL,
+    rack INTEGER NOT NULL DEFAULT 0,
+    slot INTEGER NOT NULL DEFAULT 1,
+    active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP
+);
+
+CREATE TABLE plc_status (
+    plc_id INTEGER PRIMARY KEY REFERENCES plcs(id),
+    status VARCHAR(20) NOT NULL,
+    last_update TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE plc_tags (
+    id SERIAL PRIMARY KEY,
+    plc_id INTEGER NOT NULL REFERENCES plcs(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    db_number INTEGER NOT NULL,
+    byte_offset INTEGER NOT NULL,
+    bit_offset INTEGER NOT NULL DEFAULT 0,
+    data_type VARCHAR(20) NOT NULL,
+    scan_rate INTEGER NOT NULL DEFAULT 1000,
+    monitor_changes BOOLEAN NOT NULL DEFAULT false,
+    can_write BOOLEAN NOT NULL DEFAULT false,
+    active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP
+);
+
+CREATE INDEX idx_plc_tags_plc_id ON plc_tags(plc_id);
+`
+
+// NewTestDB inicia um PostgreSQL em container, aplica o schema base e
+// retorna a conexão. Container e conexão são encerrados em t.Cleanup. Sem
+// Docker disponível o teste é ignorado.
+func NewTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	testcontainers.SkipIfProviderIsNotHealthy(t)
+	ctx := context.Background()
+
+	ctr, err := tcpostgres.Run(ctx, postgresImage,
+		tcpostgres.WithDatabase("app_padrao"),
+		tcpostgres.WithUsername("app"),
+		tcpostgres.WithPassword("app"),
+		tcpostgres.BasicWaitStrategies(),
+	)
+	testcontainers.CleanupContainer(t, ctr)
+	if err != nil {
+		t.Fatalf("erro ao iniciar PostgreSQL: %v", err)
+	}
+
+	dsn, err := ctr.ConnectionString(ctx, "sslmode=disable")
+	if err != nil {
+		t.Fatalf("erro ao obter DSN do PostgreSQL: %v", err)
+	}
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("erro ao abrir o banco: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	if _, err := db.ExecContext(ctx, baseSchema); err != nil {
+		t.Fatalf("erro ao aplicar o schema base: %v", err)
+	}
+
+	return db
+}
+
+// NewTestRedis inicia um Redis em container e retorna o cache conectado a
+// ele. Container e cliente são encerrados em t.Cleanup. Sem Docker
+// disponível o teste é ignorado.
+func NewTestRedis(t *testing.T) *cache.RedisCache {
+	t.Helper()
+	testcontainers.SkipIfProviderIsNotHealthy(t)
+	ctx := context.Background()
+
+	ctr, err := tcredis.Run(ctx, redisImage)
+	testcontainers.CleanupContainer(t, ctr)
+	if err != nil {
+		t.Fatalf("erro ao iniciar Redis: %v", err)
+	}
+
+	addr, err := ctr.Endpoint(ctx, "")
+	if err != nil {
+		t.Fatalf("erro ao obter endereço do Redis: %v", err)
+	}
+
+	redisCache, err := cache.NewRedisCache(addr, "", 0)
+	if err != nil {
+		t.Fatalf("erro ao conectar ao Redis: %v", err)
+	}
+	t.Cleanup(func() { redisCache.Close() })
+
+	return redisCache
+}
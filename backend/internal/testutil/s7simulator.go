@@ -0,0 +1,247 @@
+// internal/testutil/s7simulator.go
+package testutil
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Códigos do protocolo S7 atendidos pelo simulador
+const (
+	s7PDUSize        = 480
+	s7FuncSetupComm  = 0xF0
+	s7FuncReadVar    = 0x04
+	s7FuncWriteVar   = 0x05
+	s7AreaDB         = 0x84
+	s7ItemOK         = 0xFF
+	s7ItemNotFound   = 0x0A
+	cotpConnRequest  = 0xE0
+	cotpConnConfirm  = 0xD0
+	tpktHeaderLength = 4
+)
+
+// S7Simulator é um CLP S7 mínimo em memória, escutando ISO-on-TCP em uma
+// porta local. Atende a conexão COTP, a negociação de PDU e a leitura e
+// escrita de áreas de DB, o suficiente para o cliente de pkg/plc.
+type S7Simulator struct {
+	listener net.Listener
+
+	mu    sync.Mutex
+	dbs   map[int][]byte
+	delay time.Duration
+
+	wg sync.WaitGroup
+}
+
+// NewS7Simulator inicia o simulador em 127.0.0.1 e registra o encerramento
+// em t.Cleanup
+func NewS7Simulator(t testing.TB) *S7Simulator {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("erro ao iniciar simulador S7: %v", err)
+	}
+
+	sim := &S7Simulator{listener: listener, dbs: make(map[int][]byte)}
+	sim.wg.Add(1)
+	go sim.serve()
+
+	t.Cleanup(sim.Close)
+	return sim
+}
+
+// Addr retorna o endereço "host:porta" a usar como IP do PLC
+func (s *S7Simulator) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// Close encerra o simulador e aguarda as conexões abertas
+func (s *S7Simulator) Close() {
+	s.listener.Close()
+	s.wg.Wait()
+}
+
+// SetDB grava bytes em um DB a partir de offset
+func (s *S7Simulator) SetDB(dbNumber, offset int, data []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	copy(s.area(dbNumber, offset+len(data))[offset:], data)
+}
+
+// ReadDB retorna uma cópia de size bytes de um DB a partir de offset
+func (s *S7Simulator) ReadDB(dbNumber, offset, size int) []byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]byte, size)
+	copy(out, s.area(dbNumber, offset+size)[offset:])
+	return out
+}
+
+// SetResponseDelay atrasa as respostas de leitura e escrita, simulando um
+// PLC lento; a conexão e a negociação de PDU não são afetadas
+func (s *S7Simulator) SetResponseDelay(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.delay = d
+}
+
+// waitDelay aguarda o atraso configurado por SetResponseDelay
+func (s *S7Simulator) waitDelay() {
+	s.mu.Lock()
+	delay := s.delay
+	s.mu.Unlock()
+	time.Sleep(delay)
+}
+
+// area retorna a memória do DB com pelo menos size bytes; chamar com mu
+func (s *S7Simulator) area(dbNumber, size int) []byte {
+	db := s.dbs[dbNumber]
+	if len(db) < size {
+		grown := make([]byte, size)
+		copy(grown, db)
+		db = grown
+		s.dbs[dbNumber] = db
+	}
+	return db
+}
+
+func (s *S7Simulator) serve() {
+	defer s.wg.Done()
+
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			defer conn.Close()
+			s.handle(conn)
+		}()
+	}
+}
+
+// handle atende os telegramas de uma conexão até ela ser fechada. Conexões
+// abertas só para testar o socket (ping) terminam sem enviar nada.
+func (s *S7Simulator) handle(conn net.Conn) {
+	header := make([]byte, tpktHeaderLength)
+	for {
+		if _, err := io.ReadFull(conn, header); err != nil {
+			return
+		}
+
+		length := int(binary.BigEndian.Uint16(header[2:]))
+		if length < 7 {
+			return
+		}
+
+		request := make([]byte, length)
+		copy(request, header)
+		if _, err := io.ReadFull(conn, request[tpktHeaderLength:]); err != nil {
+			return
+		}
+
+		response, err := s.respond(request)
+		if err != nil {
+			return
+		}
+
+		if _, err := conn.Write(response); err != nil {
+			return
+		}
+	}
+}
+
+// respond monta a resposta de um telegrama TPKT completo
+func (s *S7Simulator) respond(request []byte) ([]byte, error) {
+	if request[5] == cotpConnRequest {
+		response := make([]byte, 22)
+		response[0] = 0x03
+		binary.BigEndian.PutUint16(response[2:], 22)
+		response[4] = 17 // tamanho do cabeçalho COTP
+		response[5] = cotpConnConfirm
+		return response, nil
+	}
+
+	if len(request) < 19 || request[7] != 0x32 {
+		return nil, errors.New("telegrama S7 inválido")
+	}
+
+	switch request[17] {
+	case s7FuncSetupComm:
+		params := make([]byte, 8)
+		params[0] = s7FuncSetupComm
+		binary.BigEndian.PutUint16(params[2:], 1)
+		binary.BigEndian.PutUint16(params[4:], 1)
+		binary.BigEndian.PutUint16(params[6:], s7PDUSize)
+		return ackData(request, params, nil), nil
+
+	case s7FuncReadVar:
+		if len(request) < 31 {
+			return nil, errors.New("leitura S7 incompleta")
+		}
+		s.waitDelay()
+		amount, dbNumber, area, start := varItem(request)
+		if area != s7AreaDB {
+			return ackData(request, []byte{s7FuncReadVar, 1}, []byte{s7ItemNotFound, 0, 0, 0}), nil
+		}
+
+		data := s.ReadDB(dbNumber, start, amount)
+		item := make([]byte, 4, 4+len(data))
+		item[0] = s7ItemOK
+		item[1] = 0x04 // transporte em bytes, tamanho em bits
+		binary.BigEndian.PutUint16(item[2:], uint16(len(data)*8))
+		item = append(item, data...)
+		return ackData(request, []byte{s7FuncReadVar, 1}, item), nil
+
+	case s7FuncWriteVar:
+		if len(request) < 35 {
+			return nil, errors.New("escrita S7 incompleta")
+		}
+		s.waitDelay()
+		amount, dbNumber, area, start := varItem(request)
+		if area != s7AreaDB || len(request) < 35+amount {
+			return ackData(request, []byte{s7FuncWriteVar, 1}, []byte{s7ItemNotFound}), nil
+		}
+
+		s.SetDB(dbNumber, start, request[35:35+amount])
+		return ackData(request, []byte{s7FuncWriteVar, 1}, []byte{s7ItemOK}), nil
+	}
+
+	return nil, errors.New("função S7 não suportada")
+}
+
+// varItem lê quantidade, DB, área e byte inicial do item de uma requisição
+func varItem(request []byte) (amount, dbNumber int, area byte, start int) {
+	amount = int(binary.BigEndian.Uint16(request[23:]))
+	dbNumber = int(binary.BigEndian.Uint16(request[25:]))
+	area = request[27]
+	address := int(request[28])<<16 | int(request[29])<<8 | int(request[30])
+	return amount, dbNumber, area, address >> 3
+}
+
+// ackData monta uma resposta Ack_Data com o mesmo PDU reference da requisição
+func ackData(request, params, data []byte) []byte {
+	length := 19 + len(params) + len(data)
+	response := make([]byte, 19, length)
+	response[0] = 0x03
+	binary.BigEndian.PutUint16(response[2:], uint16(length))
+	response[4] = 0x02
+	response[5] = 0xF0
+	response[6] = 0x80
+	response[7] = 0x32
+	response[8] = 0x03 // Ack_Data
+	copy(response[11:13], request[11:13])
+	binary.BigEndian.PutUint16(response[13:], uint16(len(params)))
+	binary.BigEndian.PutUint16(response[15:], uint16(len(data)))
+	response = append(response, params...)
+	return append(response, data...)
+}
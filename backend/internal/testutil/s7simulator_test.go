@@ -0,0 +1,40 @@
+package testutil
+
+import (
+	"app_padrao/pkg/plc"
+	"testing"
+)
+
+func TestS7SimulatorReadWrite(t *testing.T) {
+	sim := NewS7Simulator(t)
+
+	client, err := plc.NewClientWithConfig(plc.ClientConfig{IPAddress: sim.Addr(), Rack: 0, Slot: 1})
+	if err != nil {
+		t.Fatalf("erro ao conectar ao simulador: %v", err)
+	}
+	defer client.Close()
+
+	if err := client.WriteTag(10, 4, "real", 0, 12.5); err != nil {
+		t.Fatalf("erro ao escrever: %v", err)
+	}
+	got, err := client.ReadTag(10, 4, "real", 0)
+	if err != nil {
+		t.Fatalf("erro ao ler: %v", err)
+	}
+	if v, ok := got.(float32); !ok || v != 12.5 {
+		t.Errorf("valor lido = %v (%T), esperado 12.5", got, got)
+	}
+
+	// Escrita de bit preserva os demais bits do byte
+	sim.SetDB(10, 0, []byte{0x01})
+	if err := client.WriteTag(10, 0, "bool", 3, true); err != nil {
+		t.Fatalf("erro ao escrever bit: %v", err)
+	}
+	if b := sim.ReadDB(10, 0, 1); b[0] != 0x09 {
+		t.Errorf("byte = %#x, esperado 0x09", b[0])
+	}
+
+	if err := client.Ping(); err != nil {
+		t.Errorf("ping no simulador falhou: %v", err)
+	}
+}
@@ -2,10 +2,13 @@
 package database
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"time"
 
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 )
 
 type Config struct {
@@ -15,6 +18,17 @@ type Config struct {
 	Password string
 	DBName   string
 	SSLMode  string
+
+	// Pool de conexões (valores <= 0 mantêm o padrão do database/sql)
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+
+	// Conexões estimadas por PLC ativo, usada para avisar sobre pool subdimensionado
+	MaxConnsPerPLC int
+
+	// Prazo máximo de cada consulta dos repositórios (0 = sem prazo)
+	QueryTimeout time.Duration
 }
 
 func NewPostgresDB(cfg Config) (*sql.DB, error) {
@@ -26,6 +40,16 @@ func NewPostgresDB(cfg Config) (*sql.DB, error) {
 		return nil, err
 	}
 
+	if cfg.MaxOpenConns > 0 {
+		db.SetMaxOpenConns(cfg.MaxOpenConns)
+	}
+	if cfg.MaxIdleConns > 0 {
+		db.SetMaxIdleConns(cfg.MaxIdleConns)
+	}
+	if cfg.ConnMaxLifetime > 0 {
+		db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	}
+
 	err = db.Ping()
 	if err != nil {
 		return nil, err
@@ -33,3 +57,28 @@ func NewPostgresDB(cfg Config) (*sql.DB, error) {
 
 	return db, nil
 }
+
+// queryCanceledCode é o código SQLSTATE de uma consulta cancelada pelo cliente
+const queryCanceledCode = "57014"
+
+// WithTimeout deriva de ctx um contexto com prazo d para consultas ao banco.
+// Com d <= 0 o contexto não tem prazo, apenas cancelamento.
+func WithTimeout(ctx context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	if d <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, d)
+}
+
+// IsTimeout indica se err foi causado pelo prazo de uma consulta ter expirado
+func IsTimeout(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == queryCanceledCode
+}
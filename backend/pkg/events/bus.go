@@ -0,0 +1,143 @@
+// pkg/events/bus.go
+package events
+
+import (
+	"sync"
+	"time"
+)
+
+// Tipos de evento publicados pelo gerenciador de PLCs
+const (
+	TypeTagValueChanged  = "tag.value_changed"
+	TypePLCStatusChanged = "plc.status_changed"
+)
+
+// defaultBufferSize é a capacidade do canal de cada assinante
+const defaultBufferSize = 1024
+
+// Event é uma mensagem publicada no barramento
+type Event interface {
+	EventType() string
+}
+
+// TagValueChangedEvent é publicado a cada nova leitura de uma tag (inclusive
+// leituras com falha, sinalizadas pela qualidade)
+type TagValueChangedEvent struct {
+	PLCID     int
+	TagID     int
+	Value     interface{}
+	RawValue  interface{} // Valor antes da escala (nil para tags sem escala)
+	Timestamp time.Time
+	Quality   string
+	// Synthetic indica um valor derivado de outra tag (ex.: bit de uma word
+	// desempacotada), que vai para o cache mas não para o histórico
+	Synthetic bool
+}
+
+// EventType implementa Event
+func (TagValueChangedEvent) EventType() string { return TypeTagValueChanged }
+
+// PLCStatusChangedEvent é publicado quando o status de conexão de um PLC muda
+type PLCStatusChangedEvent struct {
+	PLCID     int
+	OldStatus string
+	NewStatus string
+}
+
+// EventType implementa Event
+func (PLCStatusChangedEvent) EventType() string { return TypePLCStatusChanged }
+
+// subscription é o canal de um assinante e o sinal de cancelamento que
+// libera publicações bloqueadas após Unsubscribe
+type subscription struct {
+	ch   chan Event
+	done chan struct{}
+	once sync.Once
+}
+
+// Bus distribui cada evento publicado para todos os assinantes do seu tipo
+// (fan-out). Cada assinante tem seu próprio canal com buffer; Publish
+// bloqueia enquanto o canal de um assinante estiver cheio, para que nenhum
+// consumidor perca eventos.
+type Bus struct {
+	mu         sync.RWMutex
+	subs       map[string][]*subscription
+	bufferSize int
+}
+
+// NewBus cria um barramento. bufferSize <= 0 usa o padrão de 1024 eventos.
+func NewBus(bufferSize int) *Bus {
+	if bufferSize <= 0 {
+		bufferSize = defaultBufferSize
+	}
+	return &Bus{
+		subs:       make(map[string][]*subscription),
+		bufferSize: bufferSize,
+	}
+}
+
+// Subscribe retorna um canal que recebe os eventos do tipo informado
+func (b *Bus) Subscribe(eventType string) <-chan Event {
+	sub := &subscription{
+		ch:   make(chan Event, b.bufferSize),
+		done: make(chan struct{}),
+	}
+
+	b.mu.Lock()
+	b.subs[eventType] = append(b.subs[eventType], sub)
+	b.mu.Unlock()
+
+	return sub.ch
+}
+
+// Unsubscribe remove o assinante. Publicações bloqueadas no seu canal são
+// liberadas e o canal não recebe mais eventos.
+func (b *Bus) Unsubscribe(ch <-chan Event) {
+	b.mu.RLock()
+	for _, subs := range b.subs {
+		for _, sub := range subs {
+			if sub.ch == ch {
+				sub.once.Do(func() { close(sub.done) })
+			}
+		}
+	}
+	b.mu.RUnlock()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for eventType, subs := range b.subs {
+		// Novo slice: Publish pode estar percorrendo o anterior
+		kept := make([]*subscription, 0, len(subs))
+		for _, sub := range subs {
+			if sub.ch != ch {
+				kept = append(kept, sub)
+			}
+		}
+		if len(kept) == 0 {
+			delete(b.subs, eventType)
+		} else {
+			b.subs[eventType] = kept
+		}
+	}
+}
+
+// Publish entrega o evento a todos os assinantes do seu tipo
+func (b *Bus) Publish(event Event) {
+	b.mu.RLock()
+	subs := b.subs[event.EventType()]
+	b.mu.RUnlock()
+
+	for _, sub := range subs {
+		select {
+		case sub.ch <- event:
+		case <-sub.done:
+		}
+	}
+}
+
+// HasSubscribers indica se há assinantes para o tipo de evento
+func (b *Bus) HasSubscribers(eventType string) bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return len(b.subs[eventType]) > 0
+}
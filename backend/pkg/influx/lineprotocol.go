@@ -0,0 +1,144 @@
+// pkg/influx/lineprotocol.go
+package influx
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Erros de codificação
+var (
+	ErrEmptyMeasurement = errors.New("measurement não pode ser vazio")
+	ErrNoFields         = errors.New("ponto precisa de pelo menos um campo válido")
+)
+
+var (
+	measurementEscaper = strings.NewReplacer(",", `\,`, " ", `\ `)
+	keyEscaper         = strings.NewReplacer(",", `\,`, "=", `\=`, " ", `\ `)
+	stringFieldEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+)
+
+// LineProtocolEncoder escreve pontos no formato line protocol do InfluxDB:
+//
+//	measurement,tag1=a,tag2=b campo=1.5 1700000000000000000
+//
+// Tags e campos são ordenados pela chave para gerar saída determinística.
+type LineProtocolEncoder struct{}
+
+// NewLineProtocolEncoder cria um novo encoder
+func NewLineProtocolEncoder() *LineProtocolEncoder {
+	return &LineProtocolEncoder{}
+}
+
+// Encode escreve uma linha para o ponto informado. Tags com valor vazio e
+// campos nulos ou não finitos são omitidos; se nenhum campo restar, retorna
+// ErrNoFields sem escrever nada.
+func (e *LineProtocolEncoder) Encode(w io.Writer, measurement string, tags map[string]string, fields map[string]interface{}, ts time.Time) error {
+	if measurement == "" {
+		return ErrEmptyMeasurement
+	}
+
+	var b strings.Builder
+	b.WriteString(measurementEscaper.Replace(measurement))
+
+	tagKeys := make([]string, 0, len(tags))
+	for k, v := range tags {
+		if k != "" && v != "" {
+			tagKeys = append(tagKeys, k)
+		}
+	}
+	sort.Strings(tagKeys)
+
+	for _, k := range tagKeys {
+		b.WriteByte(',')
+		b.WriteString(keyEscaper.Replace(k))
+		b.WriteByte('=')
+		b.WriteString(keyEscaper.Replace(tags[k]))
+	}
+
+	fieldKeys := make([]string, 0, len(fields))
+	for k := range fields {
+		if k != "" {
+			fieldKeys = append(fieldKeys, k)
+		}
+	}
+	sort.Strings(fieldKeys)
+
+	written := 0
+	for _, k := range fieldKeys {
+		value, ok := formatField(fields[k])
+		if !ok {
+			continue
+		}
+
+		if written == 0 {
+			b.WriteByte(' ')
+		} else {
+			b.WriteByte(',')
+		}
+		b.WriteString(keyEscaper.Replace(k))
+		b.WriteByte('=')
+		b.WriteString(value)
+		written++
+	}
+
+	if written == 0 {
+		return ErrNoFields
+	}
+
+	b.WriteByte(' ')
+	b.WriteString(strconv.FormatInt(ts.UnixNano(), 10))
+	b.WriteByte('\n')
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// formatField converte um valor Go para a representação de campo do line
+// protocol. Inteiros recebem o sufixo "i" e strings ficam entre aspas.
+func formatField(v interface{}) (string, bool) {
+	switch val := v.(type) {
+	case float64:
+		if math.IsNaN(val) || math.IsInf(val, 0) {
+			return "", false
+		}
+		return strconv.FormatFloat(val, 'f', -1, 64), true
+	case float32:
+		return formatField(float64(val))
+	case int:
+		return strconv.FormatInt(int64(val), 10) + "i", true
+	case int8:
+		return strconv.FormatInt(int64(val), 10) + "i", true
+	case int16:
+		return strconv.FormatInt(int64(val), 10) + "i", true
+	case int32:
+		return strconv.FormatInt(int64(val), 10) + "i", true
+	case int64:
+		return strconv.FormatInt(val, 10) + "i", true
+	case uint8:
+		return strconv.FormatUint(uint64(val), 10) + "i", true
+	case uint16:
+		return strconv.FormatUint(uint64(val), 10) + "i", true
+	case uint32:
+		return strconv.FormatUint(uint64(val), 10) + "i", true
+	case uint64:
+		if val > math.MaxInt64 {
+			return strconv.FormatFloat(float64(val), 'f', -1, 64), true
+		}
+		return strconv.FormatUint(val, 10) + "i", true
+	case bool:
+		return strconv.FormatBool(val), true
+	case string:
+		return `"` + stringFieldEscaper.Replace(val) + `"`, true
+	case nil:
+		return "", false
+	default:
+		return fmt.Sprintf(`"%s"`, stringFieldEscaper.Replace(fmt.Sprint(val))), true
+	}
+}
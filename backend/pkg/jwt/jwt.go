@@ -9,10 +9,16 @@ import (
 )
 
 type Claims struct {
-	UserID int `json:"user_id"`
+	UserID  int    `json:"user_id"`
+	Purpose string `json:"purpose,omitempty"`
 	jwt.RegisteredClaims
 }
 
+// Finalidades de tokens de uso único (não aceitos como token de sessão)
+const (
+	PurposeEmailVerification = "email_verification"
+)
+
 func GenerateToken(userID int, secretKey string, expirationHours int) (string, error) {
 	claims := Claims{
 		UserID: userID,
@@ -27,6 +33,16 @@ func GenerateToken(userID int, secretKey string, expirationHours int) (string, e
 }
 
 func ValidateToken(tokenString string, secretKey string) (int, error) {
+	claims, err := ValidateTokenClaims(tokenString, secretKey)
+	if err != nil {
+		return 0, err
+	}
+	return claims.UserID, nil
+}
+
+// ValidateTokenClaims valida um token de sessão e retorna suas claims (ex.:
+// IssuedAt, para conferir revogações)
+func ValidateTokenClaims(tokenString string, secretKey string) (*Claims, error) {
 	token, err := jwt.ParseWithClaims(
 		tokenString,
 		&Claims{},
@@ -36,11 +52,53 @@ func ValidateToken(tokenString string, secretKey string) (int, error) {
 	)
 
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 
 	claims, ok := token.Claims.(*Claims)
 	if !ok || !token.Valid {
+		return nil, errors.New("token inválido")
+	}
+
+	// Tokens com finalidade específica não valem como token de sessão
+	if claims.Purpose != "" {
+		return nil, errors.New("token inválido")
+	}
+
+	return claims, nil
+}
+
+// GeneratePurposeToken gera um token de curta duração para uma finalidade específica
+func GeneratePurposeToken(userID int, purpose string, secretKey string, ttl time.Duration) (string, error) {
+	claims := Claims{
+		UserID:  userID,
+		Purpose: purpose,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(secretKey))
+}
+
+// ValidatePurposeToken valida um token gerado por GeneratePurposeToken
+func ValidatePurposeToken(tokenString string, purpose string, secretKey string) (int, error) {
+	token, err := jwt.ParseWithClaims(
+		tokenString,
+		&Claims{},
+		func(token *jwt.Token) (interface{}, error) {
+			return []byte(secretKey), nil
+		},
+	)
+
+	if err != nil {
+		return 0, err
+	}
+
+	claims, ok := token.Claims.(*Claims)
+	if !ok || !token.Valid || claims.Purpose != purpose {
 		return 0, errors.New("token inválido")
 	}
 
@@ -0,0 +1,146 @@
+// pkg/lrucache/lrucache.go
+package lrucache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// Stats resume o uso do cache
+type Stats struct {
+	Size     int
+	Capacity int
+	TTL      time.Duration
+	Hits     int64
+	Misses   int64
+	HitRate  float64 // Acertos / consultas (0 sem consultas)
+}
+
+// lruEntry é um valor do cache com o prazo de validade
+type lruEntry[K comparable, V any] struct {
+	key       K
+	value     V
+	expiresAt time.Time
+}
+
+// Cache é um cache em memória com no máximo capacity itens, que descarta o
+// menos usado recentemente ao encher e trata itens mais velhos que ttl como
+// ausentes. Com capacity <= 0 o cache fica desativado (toda consulta é falha).
+type Cache[K comparable, V any] struct {
+	mu       sync.Mutex
+	capacity int
+	ttl      time.Duration
+	entries  map[K]*list.Element // valores: *lruEntry[K, V]
+	order    *list.List          // frente = usado mais recentemente
+
+	hits   int64
+	misses int64
+}
+
+// New cria um cache com a capacidade e o tempo de vida informados
+// (ttl <= 0 = sem expiração)
+func New[K comparable, V any](capacity int, ttl time.Duration) *Cache[K, V] {
+	return &Cache[K, V]{
+		capacity: capacity,
+		ttl:      ttl,
+		entries:  make(map[K]*list.Element),
+		order:    list.New(),
+	}
+}
+
+// Get retorna o valor da chave se presente e dentro da validade
+func (c *Cache[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var zero V
+	elem, ok := c.entries[key]
+	if !ok {
+		c.misses++
+		return zero, false
+	}
+
+	entry := elem.Value.(*lruEntry[K, V])
+	if !entry.expiresAt.IsZero() && time.Now().After(entry.expiresAt) {
+		c.removeElement(elem)
+		c.misses++
+		return zero, false
+	}
+
+	c.order.MoveToFront(elem)
+	c.hits++
+	return entry.value, true
+}
+
+// Set grava o valor, descartando o item menos usado se o cache estiver cheio
+func (c *Cache[K, V]) Set(key K, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.capacity <= 0 {
+		return
+	}
+
+	var expiresAt time.Time
+	if c.ttl > 0 {
+		expiresAt = time.Now().Add(c.ttl)
+	}
+
+	if elem, ok := c.entries[key]; ok {
+		entry := elem.Value.(*lruEntry[K, V])
+		entry.value = value
+		entry.expiresAt = expiresAt
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	for c.order.Len() >= c.capacity {
+		c.removeElement(c.order.Back())
+	}
+
+	c.entries[key] = c.order.PushFront(&lruEntry[K, V]{key: key, value: value, expiresAt: expiresAt})
+}
+
+// Delete remove a chave do cache
+func (c *Cache[K, V]) Delete(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		c.removeElement(elem)
+	}
+}
+
+// Purge esvazia o cache
+func (c *Cache[K, V]) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries = make(map[K]*list.Element)
+	c.order.Init()
+}
+
+// Stats retorna o tamanho e a taxa de acerto do cache
+func (c *Cache[K, V]) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := Stats{
+		Size:     c.order.Len(),
+		Capacity: c.capacity,
+		Hits:     c.hits,
+		Misses:   c.misses,
+		TTL:      c.ttl,
+	}
+	if total := c.hits + c.misses; total > 0 {
+		stats.HitRate = float64(c.hits) / float64(total)
+	}
+	return stats
+}
+
+// removeElement remove o item da lista e do mapa (com mu travado)
+func (c *Cache[K, V]) removeElement(elem *list.Element) {
+	entry := c.order.Remove(elem).(*lruEntry[K, V])
+	delete(c.entries, entry.key)
+}
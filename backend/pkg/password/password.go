@@ -0,0 +1,91 @@
+// pkg/password/password.go
+package password
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Regras de senha retornadas por Validate quando violadas
+const (
+	RuleMinLength       = "min_length"
+	RuleRequiresUpper   = "requires_uppercase"
+	RuleRequiresLower   = "requires_lowercase"
+	RuleRequiresDigit   = "requires_digit"
+	RuleRequiresSpecial = "requires_special"
+)
+
+// PasswordPolicy define os requisitos mínimos de uma senha
+type PasswordPolicy struct {
+	MinLength      int
+	RequireUpper   bool
+	RequireLower   bool
+	RequireDigit   bool
+	RequireSpecial bool
+}
+
+// DefaultPolicy retorna a política usada quando nenhuma é configurada
+func DefaultPolicy() PasswordPolicy {
+	return PasswordPolicy{
+		MinLength:    8,
+		RequireUpper: true,
+		RequireLower: true,
+		RequireDigit: true,
+	}
+}
+
+// WeakPasswordError indica que a senha não atende à política
+type WeakPasswordError struct {
+	Violations []string
+}
+
+func (e *WeakPasswordError) Error() string {
+	return "weak password: " + strings.Join(e.Violations, ", ")
+}
+
+// Validate verifica a senha contra a política e retorna as regras violadas.
+// Uma lista vazia significa que a senha é aceita.
+func Validate(password string, policy PasswordPolicy) []string {
+	violations := []string{}
+
+	if len([]rune(password)) < policy.MinLength {
+		violations = append(violations, RuleMinLength)
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSpecial bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			hasSpecial = true
+		}
+	}
+
+	if policy.RequireUpper && !hasUpper {
+		violations = append(violations, RuleRequiresUpper)
+	}
+	if policy.RequireLower && !hasLower {
+		violations = append(violations, RuleRequiresLower)
+	}
+	if policy.RequireDigit && !hasDigit {
+		violations = append(violations, RuleRequiresDigit)
+	}
+	if policy.RequireSpecial && !hasSpecial {
+		violations = append(violations, RuleRequiresSpecial)
+	}
+
+	return violations
+}
+
+// Check retorna um *WeakPasswordError quando a senha viola a política
+func Check(password string, policy PasswordPolicy) error {
+	if violations := Validate(password, policy); len(violations) > 0 {
+		return &WeakPasswordError{Violations: violations}
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package password
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	strict := PasswordPolicy{MinLength: 10, RequireUpper: true, RequireLower: true, RequireDigit: true, RequireSpecial: true}
+
+	tests := []struct {
+		name     string
+		password string
+		policy   PasswordPolicy
+		want     []string
+	}{
+		{"política vazia aceita tudo", "", PasswordPolicy{}, []string{}},
+		{"curta", "Ab1!", PasswordPolicy{MinLength: 8}, []string{RuleMinLength}},
+		{"comprimento em runas", "çãõéíóúâ", PasswordPolicy{MinLength: 8}, []string{}},
+		{"sem maiúscula", "abc123!x", PasswordPolicy{RequireUpper: true}, []string{RuleRequiresUpper}},
+		{"sem minúscula", "ABC123!X", PasswordPolicy{RequireLower: true}, []string{RuleRequiresLower}},
+		{"sem dígito", "Abcdef!x", PasswordPolicy{RequireDigit: true}, []string{RuleRequiresDigit}},
+		{"sem especial", "Abcdef1x", PasswordPolicy{RequireSpecial: true}, []string{RuleRequiresSpecial}},
+		{"espaço conta como especial", "Abc def1", PasswordPolicy{RequireSpecial: true}, []string{}},
+		{"todas as regras violadas", "", strict, []string{RuleMinLength, RuleRequiresUpper, RuleRequiresLower, RuleRequiresDigit, RuleRequiresSpecial}},
+		{"atende à política estrita", "Senha#Forte2026", strict, []string{}},
+		{"padrão sem dígito e curta", "Abcdef", DefaultPolicy(), []string{RuleMinLength, RuleRequiresDigit}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Validate(tt.password, tt.policy); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Validate(%q) = %v, esperado %v", tt.password, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestValidateAllPolicyCombinations confere cada combinação de regras contra
+// senhas que violam exatamente uma delas
+func TestValidateAllPolicyCombinations(t *testing.T) {
+	passwords := map[string]string{
+		RuleMinLength:       "Ab1!",
+		RuleRequiresUpper:   "abcdef1!",
+		RuleRequiresLower:   "ABCDEF1!",
+		RuleRequiresDigit:   "Abcdefg!",
+		RuleRequiresSpecial: "Abcdefg1",
+	}
+
+	for mask := 0; mask < 32; mask++ {
+		policy := PasswordPolicy{
+			RequireUpper:   mask&2 != 0,
+			RequireLower:   mask&4 != 0,
+			RequireDigit:   mask&8 != 0,
+			RequireSpecial: mask&16 != 0,
+		}
+		if mask&1 != 0 {
+			policy.MinLength = 8
+		}
+		enabled := map[string]bool{
+			RuleMinLength:       mask&1 != 0,
+			RuleRequiresUpper:   policy.RequireUpper,
+			RuleRequiresLower:   policy.RequireLower,
+			RuleRequiresDigit:   policy.RequireDigit,
+			RuleRequiresSpecial: policy.RequireSpecial,
+		}
+
+		for rule, password := range passwords {
+			want := []string{}
+			if enabled[rule] {
+				want = []string{rule}
+			}
+			if got := Validate(password, policy); !reflect.DeepEqual(got, want) {
+				t.Errorf("política %+v, senha %q: violações = %v, esperado %v", policy, password, got, want)
+			}
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	if err := Check("Senha123", DefaultPolicy()); err != nil {
+		t.Errorf("erro inesperado: %v", err)
+	}
+
+	err := Check("abc", DefaultPolicy())
+	var weak *WeakPasswordError
+	if !errors.As(err, &weak) {
+		t.Fatalf("erro = %v, esperado *WeakPasswordError", err)
+	}
+	want := []string{RuleMinLength, RuleRequiresUpper, RuleRequiresDigit}
+	if !reflect.DeepEqual(weak.Violations, want) {
+		t.Errorf("violações = %v, esperado %v", weak.Violations, want)
+	}
+}
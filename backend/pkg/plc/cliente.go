@@ -2,12 +2,14 @@
 package plc
 
 import (
+	"context"
 	"encoding/binary"
 	"errors"
 	"fmt"
 	"log"
 	"math"
 	"net"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -46,6 +48,11 @@ type ClientConfig struct {
 	Gateway    string
 	SubnetMask string
 	VLANID     int
+	PDUSize    PDUSizeConfig // Sobrescreve o PDU negociado (0 = usar o negociado)
+
+	// TSAP remoto explícito (0 = conexão PG calculada de Rack/Slot). Os
+	// S7-1200/1500 usam a conexão básica: 0x0300 + rack*0x20 + slot.
+	RemoteTSAP uint16
 }
 
 // NewClient cria uma nova instância do cliente PLC com suporte a reconexão
@@ -71,6 +78,12 @@ func NewClientWithConfig(config ClientConfig) (*Client, error) {
 		client.config.Timeout = 10 * time.Second
 	}
 
+	if config.PDUSize > 0 && config.PDUSize <= pduReadOverhead {
+		log.Printf("Aviso: PDU configurado (%d bytes) não comporta o cabeçalho de leitura (%d bytes), usando o PDU negociado",
+			config.PDUSize, pduReadOverhead)
+		client.config.PDUSize = 0
+	}
+
 	// Tenta conectar inicialmente
 	err := client.connect()
 	if err != nil {
@@ -80,6 +93,19 @@ func NewClientWithConfig(config ClientConfig) (*Client, error) {
 	return client, nil
 }
 
+// newTCPHandler cria o handler ISO-TCP. A gos7 monta o TSAP remoto como
+// tipo_de_conexão<<8 + rack*0x20 + slot; um TSAP remoto explícito é
+// decomposto nesses três valores para ser reproduzido exatamente.
+func newTCPHandler(config ClientConfig) *gos7.TCPClientHandler {
+	if config.RemoteTSAP == 0 {
+		return gos7.NewTCPClientHandler(config.IPAddress, config.Rack, config.Slot)
+	}
+
+	connectionType := int(config.RemoteTSAP >> 8)
+	low := int(config.RemoteTSAP & 0xFF)
+	return gos7.NewTCPClientHandlerWithConnectType(config.IPAddress, low/0x20, low%0x20, connectionType)
+}
+
 // connect estabelece a conexão com o PLC
 func (c *Client) connect() error {
 	c.mu.Lock()
@@ -93,7 +119,7 @@ func (c *Client) connect() error {
 	}
 
 	// Criar novo handler
-	handler := gos7.NewTCPClientHandler(c.config.IPAddress, c.config.Rack, c.config.Slot)
+	handler := newTCPHandler(c.config)
 	handler.Timeout = c.config.Timeout
 
 	// Tentar estabelecer conexão com retry
@@ -175,9 +201,24 @@ func (c *Client) Close() {
 
 // Ping testa a conectividade com o PLC
 func (c *Client) Ping() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	return c.PingContext(ctx)
+}
+
+// PingContext verifica a conexão como Ping, abrindo o socket de teste com
+// net.DialContext: o prazo e o cancelamento vêm de ctx. Um ping que expira
+// (inclusive esperando a conexão, ocupada por uma leitura) não marca a
+// conexão como perdida: só falhas de rede fazem isso.
+func (c *Client) PingContext(ctx context.Context) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
+	// O prazo pode ter acabado enquanto esperávamos a conexão
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
 	// Se não temos um handler, a conexão já está inativa
 	if c.handler == nil {
 		return ErrConnectionClosed
@@ -189,10 +230,14 @@ func (c *Client) Ping() error {
 		address = fmt.Sprintf("%s:102", address)
 	}
 
-	conn, err := net.DialTimeout("tcp", address, 3*time.Second)
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", address)
 	if err != nil {
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return err
+		}
 		c.isConnected = false
-		return fmt.Errorf("%w: %v", ErrNetworkFailure, err)
+		return fmt.Errorf("%w: %w", ErrNetworkFailure, err)
 	}
 	conn.Close()
 
@@ -247,6 +292,40 @@ func isNetworkError(err error) bool {
 	return false
 }
 
+// dataTypeSizes é o número de bytes lidos do PLC para cada tipo de dados
+var dataTypeSizes = map[string]int{
+	"real":   4,
+	"dint":   4,
+	"int32":  4,
+	"dword":  4,
+	"uint32": 4,
+	"int":    2,
+	"int16":  2,
+	"word":   2,
+	"uint16": 2,
+	"sint":   1,
+	"int8":   1,
+	"usint":  1,
+	"byte":   1,
+	"uint8":  1,
+	"char":   1,
+	"bool":   1,
+	"string": 256,
+
+	"date":          2,
+	"time_of_day":   4,
+	"date_and_time": 8,
+
+	"timer":   2,
+	"counter": 2,
+}
+
+// DataTypeSize retorna quantos bytes são lidos do PLC para o tipo de dados
+func DataTypeSize(dataType string) (int, bool) {
+	size, ok := dataTypeSizes[strings.ToLower(strings.TrimSpace(dataType))]
+	return size, ok
+}
+
 // ReadTag lê um valor do PLC usando DBNumber, ByteOffset, dataType e BitOffset opcional (para bool)
 func (c *Client) ReadTag(dbNumber int, byteOffset int, dataType string, bitOffset int) (interface{}, error) {
 	// Garante que a conexão está ativa antes de qualquer operação
@@ -262,27 +341,7 @@ func (c *Client) ReadTag(dbNumber int, byteOffset int, dataType string, bitOffse
 	// Validação explícita do tipo de dados para evitar interpretação incorreta
 	dataType = strings.ToLower(strings.TrimSpace(dataType))
 
-	// Mapear tipos válidos e seus tamanhos
-	validTypes := map[string]int{
-		"real":   4,
-		"dint":   4,
-		"int32":  4,
-		"dword":  4,
-		"uint32": 4,
-		"int":    2,
-		"int16":  2,
-		"word":   2,
-		"uint16": 2,
-		"sint":   1,
-		"int8":   1,
-		"usint":  1,
-		"byte":   1,
-		"uint8":  1,
-		"bool":   1,
-		"string": 256,
-	}
-
-	size, validType := validTypes[dataType]
+	size, validType := DataTypeSize(dataType)
 	if !validType {
 		// Se o tipo não for reconhecido, tente inferir um tipo adequado
 		log.Printf("AVISO: Tipo de dado não reconhecido: '%s'. Tentando inferir tipo adequado.", dataType)
@@ -304,12 +363,32 @@ func (c *Client) ReadTag(dbNumber int, byteOffset int, dataType string, bitOffse
 	if err != nil {
 		if isNetworkError(err) {
 			c.isConnected = false
-			return nil, fmt.Errorf("%w: DB%d.%d: %v", ErrNetworkFailure, dbNumber, byteOffset, err)
+			return nil, fmt.Errorf("%w: DB%d.%d: %w", ErrNetworkFailure, dbNumber, byteOffset, err)
 		}
 		return nil, fmt.Errorf("erro ao ler dados do PLC (DB%d.%d): %w", dbNumber, byteOffset, err)
 	}
 
-	// Interpretar os bytes conforme o tipo de dado
+	return decodeValue(buf, dataType, bitOffset)
+}
+
+// DecodeValue interpreta os bytes de uma tag lidos do PLC, por exemplo um
+// trecho do buffer retornado por BatchReadDB
+func DecodeValue(buf []byte, dataType string, bitOffset int) (interface{}, error) {
+	dataType = strings.ToLower(strings.TrimSpace(dataType))
+
+	size, ok := DataTypeSize(dataType)
+	if !ok {
+		return nil, fmt.Errorf("tipo de dado não suportado: %s", dataType)
+	}
+	if len(buf) < size {
+		return nil, fmt.Errorf("%w: %d bytes para o tipo %s (esperado %d)", ErrInvalidReadRange, len(buf), dataType, size)
+	}
+
+	return decodeValue(buf, dataType, bitOffset)
+}
+
+// decodeValue interpreta os bytes conforme o tipo de dado já normalizado
+func decodeValue(buf []byte, dataType string, bitOffset int) (interface{}, error) {
 	var resultado interface{}
 
 	switch dataType {
@@ -334,6 +413,9 @@ func (c *Client) ReadTag(dbNumber int, byteOffset int, dataType string, bitOffse
 	case "usint", "byte", "uint8":
 		resultado = buf[0]
 
+	case "char":
+		resultado = string([]byte{GetCharAt(buf, 0)})
+
 	case "bool":
 		// Usa o bitOffset explicitamente para selecionar o bit correto
 		if bitOffset >= 0 && bitOffset <= 7 {
@@ -363,6 +445,21 @@ func (c *Client) ReadTag(dbNumber int, byteOffset int, dataType string, bitOffse
 		}
 
 		resultado = string(buf[2 : 2+strLen])
+
+	case "date":
+		resultado = GetDateAt(buf, 0)
+
+	case "time_of_day":
+		resultado = GetTimeOfDayAt(buf, 0)
+
+	case "date_and_time":
+		resultado = GetDateAndTimeAt(buf, 0)
+
+	case "timer":
+		resultado = GetTimerAt(buf, 0)
+
+	case "counter":
+		resultado = GetCounterAt(buf, 0)
 	}
 
 	return resultado, nil
@@ -383,6 +480,73 @@ func (c *Client) WriteTag(dbNumber int, byteOffset int, dataType string, bitOffs
 
 	var buf []byte
 
+	if dataType == "bool" {
+		val, err := toBool(value)
+		if err != nil {
+			return err
+		}
+
+		buf = make([]byte, 1)
+
+		// Primeiro ler o byte atual para preservar os outros bits
+		err = c.client.AGReadDB(dbNumber, byteOffset, 1, buf)
+		if err != nil {
+			return fmt.Errorf("erro ao ler byte atual para escrita de bit: %w", err)
+		}
+
+		// Se temos uma posição de bit específica
+		if bitOffset >= 0 && bitOffset <= 7 {
+			if val {
+				buf[0] |= (1 << uint(bitOffset)) // set bit
+			} else {
+				buf[0] &= ^(1 << uint(bitOffset)) // clear bit
+			}
+		} else {
+			// Caso contrário, assume o primeiro bit
+			if val {
+				buf[0] |= 0x01 // set bit 0
+			} else {
+				buf[0] &= 0xFE // clear bit 0
+			}
+		}
+	} else {
+		var err error
+		if buf, err = encodeValue(dataType, value); err != nil {
+			return err
+		}
+	}
+
+	// Escrever os bytes no PLC
+	err := c.client.AGWriteDB(dbNumber, byteOffset, len(buf), buf)
+	if err != nil {
+		if isNetworkError(err) {
+			c.isConnected = false
+			return fmt.Errorf("%w: DB%d.%d: %w", ErrNetworkFailure, dbNumber, byteOffset, err)
+		}
+		return fmt.Errorf("erro ao escrever dados no PLC (DB%d.%d): %w", dbNumber, byteOffset, err)
+	}
+
+	return nil
+}
+
+// CheckWriteValue verifica se o valor pode ser convertido para o tipo de
+// dados da tag, sem acessar o PLC. Retorna o mesmo erro que WriteTag
+// retornaria na conversão.
+func CheckWriteValue(dataType string, value interface{}) error {
+	dataType = strings.ToLower(strings.TrimSpace(dataType))
+	if dataType == "bool" {
+		_, err := toBool(value)
+		return err
+	}
+	_, err := encodeValue(dataType, value)
+	return err
+}
+
+// encodeValue converte o valor para os bytes gravados no PLC (todos os tipos
+// exceto bool, que depende do byte atual)
+func encodeValue(dataType string, value interface{}) ([]byte, error) {
+	var buf []byte
+
 	switch dataType {
 	case "real":
 		buf = make([]byte, 4)
@@ -398,7 +562,7 @@ func (c *Client) WriteTag(dbNumber int, byteOffset int, dataType string, bitOffs
 		case int64:
 			val = float32(v)
 		default:
-			return fmt.Errorf("%w: esperado float32, recebido %T", ErrValueConversion, value)
+			return nil, fmt.Errorf("%w: esperado float32, recebido %T", ErrValueConversion, value)
 		}
 
 		binary.BigEndian.PutUint32(buf, math.Float32bits(val))
@@ -419,7 +583,7 @@ func (c *Client) WriteTag(dbNumber int, byteOffset int, dataType string, bitOffs
 		case float64:
 			val = int32(v)
 		default:
-			return fmt.Errorf("%w: esperado int32, recebido %T", ErrValueConversion, value)
+			return nil, fmt.Errorf("%w: esperado int32, recebido %T", ErrValueConversion, value)
 		}
 
 		binary.BigEndian.PutUint32(buf, uint32(val))
@@ -435,16 +599,16 @@ func (c *Client) WriteTag(dbNumber int, byteOffset int, dataType string, bitOffs
 			val = uint32(v)
 		case int:
 			if v < 0 {
-				return fmt.Errorf("%w: valor negativo não pode ser convertido para uint32", ErrValueConversion)
+				return nil, fmt.Errorf("%w: valor negativo não pode ser convertido para uint32", ErrValueConversion)
 			}
 			val = uint32(v)
 		case float64:
 			if v < 0 {
-				return fmt.Errorf("%w: valor negativo não pode ser convertido para uint32", ErrValueConversion)
+				return nil, fmt.Errorf("%w: valor negativo não pode ser convertido para uint32", ErrValueConversion)
 			}
 			val = uint32(v)
 		default:
-			return fmt.Errorf("%w: esperado uint32, recebido %T", ErrValueConversion, value)
+			return nil, fmt.Errorf("%w: esperado uint32, recebido %T", ErrValueConversion, value)
 		}
 
 		binary.BigEndian.PutUint32(buf, val)
@@ -459,21 +623,21 @@ func (c *Client) WriteTag(dbNumber int, byteOffset int, dataType string, bitOffs
 		case int:
 			// Verificar se está dentro dos limites de int16
 			if v > 32767 || v < -32768 {
-				return fmt.Errorf("%w: valor %d está fora dos limites de int16 (-32768 a 32767)", ErrValueConversion, v)
+				return nil, fmt.Errorf("%w: valor %d está fora dos limites de int16 (-32768 a 32767)", ErrValueConversion, v)
 			}
 			val = int16(v)
 		case float32:
 			if v > 32767 || v < -32768 {
-				return fmt.Errorf("%w: valor %f está fora dos limites de int16", ErrValueConversion, v)
+				return nil, fmt.Errorf("%w: valor %f está fora dos limites de int16", ErrValueConversion, v)
 			}
 			val = int16(v)
 		case float64:
 			if v > 32767 || v < -32768 {
-				return fmt.Errorf("%w: valor %f está fora dos limites de int16", ErrValueConversion, v)
+				return nil, fmt.Errorf("%w: valor %f está fora dos limites de int16", ErrValueConversion, v)
 			}
 			val = int16(v)
 		default:
-			return fmt.Errorf("%w: esperado int16, recebido %T", ErrValueConversion, value)
+			return nil, fmt.Errorf("%w: esperado int16, recebido %T", ErrValueConversion, value)
 		}
 
 		binary.BigEndian.PutUint16(buf, uint16(val))
@@ -487,16 +651,16 @@ func (c *Client) WriteTag(dbNumber int, byteOffset int, dataType string, bitOffs
 			val = v
 		case int:
 			if v < 0 || v > 65535 {
-				return fmt.Errorf("%w: valor %d está fora dos limites de uint16 (0 a 65535)", ErrValueConversion, v)
+				return nil, fmt.Errorf("%w: valor %d está fora dos limites de uint16 (0 a 65535)", ErrValueConversion, v)
 			}
 			val = uint16(v)
 		case float64:
 			if v < 0 || v > 65535 {
-				return fmt.Errorf("%w: valor %f está fora dos limites de uint16", ErrValueConversion, v)
+				return nil, fmt.Errorf("%w: valor %f está fora dos limites de uint16", ErrValueConversion, v)
 			}
 			val = uint16(v)
 		default:
-			return fmt.Errorf("%w: esperado uint16, recebido %T", ErrValueConversion, value)
+			return nil, fmt.Errorf("%w: esperado uint16, recebido %T", ErrValueConversion, value)
 		}
 
 		binary.BigEndian.PutUint16(buf, val)
@@ -510,16 +674,16 @@ func (c *Client) WriteTag(dbNumber int, byteOffset int, dataType string, bitOffs
 			val = v
 		case int:
 			if v > 127 || v < -128 {
-				return fmt.Errorf("%w: valor %d está fora dos limites de int8 (-128 a 127)", ErrValueConversion, v)
+				return nil, fmt.Errorf("%w: valor %d está fora dos limites de int8 (-128 a 127)", ErrValueConversion, v)
 			}
 			val = int8(v)
 		case float64:
 			if v > 127 || v < -128 {
-				return fmt.Errorf("%w: valor %f está fora dos limites de int8", ErrValueConversion, v)
+				return nil, fmt.Errorf("%w: valor %f está fora dos limites de int8", ErrValueConversion, v)
 			}
 			val = int8(v)
 		default:
-			return fmt.Errorf("%w: esperado int8, recebido %T", ErrValueConversion, value)
+			return nil, fmt.Errorf("%w: esperado int8, recebido %T", ErrValueConversion, value)
 		}
 
 		buf[0] = byte(val)
@@ -533,59 +697,28 @@ func (c *Client) WriteTag(dbNumber int, byteOffset int, dataType string, bitOffs
 			val = v
 		case int:
 			if v < 0 || v > 255 {
-				return fmt.Errorf("%w: valor %d está fora dos limites de uint8 (0 a 255)", ErrValueConversion, v)
+				return nil, fmt.Errorf("%w: valor %d está fora dos limites de uint8 (0 a 255)", ErrValueConversion, v)
 			}
 			val = uint8(v)
 		case float64:
 			if v < 0 || v > 255 {
-				return fmt.Errorf("%w: valor %f está fora dos limites de uint8", ErrValueConversion, v)
+				return nil, fmt.Errorf("%w: valor %f está fora dos limites de uint8", ErrValueConversion, v)
 			}
 			val = uint8(v)
 		default:
-			return fmt.Errorf("%w: esperado uint8, recebido %T", ErrValueConversion, value)
+			return nil, fmt.Errorf("%w: esperado uint8, recebido %T", ErrValueConversion, value)
 		}
 
 		buf[0] = val
 
-	case "bool":
-		buf = make([]byte, 1)
-
-		// Primeiro ler o byte atual para preservar os outros bits
-		err := c.client.AGReadDB(dbNumber, byteOffset, 1, buf)
-		if err != nil {
-			return fmt.Errorf("erro ao ler byte atual para escrita de bit: %w", err)
+	case "char":
+		str, ok := value.(string)
+		if !ok || len(str) != 1 {
+			return nil, fmt.Errorf("%w: esperado string com um caractere ASCII, recebido %v (%T)", ErrValueConversion, value, value)
 		}
 
-		var val bool
-
-		switch v := value.(type) {
-		case bool:
-			val = v
-		case int:
-			val = v != 0
-		case float64:
-			val = v != 0
-		case string:
-			val = v == "true" || v == "1" || v == "yes" || v == "sim"
-		default:
-			return fmt.Errorf("%w: esperado valor convertível para bool, recebido %T", ErrValueConversion, value)
-		}
-
-		// Se temos uma posição de bit específica
-		if bitOffset >= 0 && bitOffset <= 7 {
-			if val {
-				buf[0] |= (1 << uint(bitOffset)) // set bit
-			} else {
-				buf[0] &= ^(1 << uint(bitOffset)) // clear bit
-			}
-		} else {
-			// Caso contrário, assume o primeiro bit
-			if val {
-				buf[0] |= 0x01 // set bit 0
-			} else {
-				buf[0] &= 0xFE // clear bit 0
-			}
-		}
+		buf = make([]byte, 1)
+		SetCharAt(buf, 0, str[0])
 
 	case "string":
 		var str string
@@ -607,19 +740,190 @@ func (c *Client) WriteTag(dbNumber int, byteOffset int, dataType string, bitOffs
 		buf[1] = byte(len(str))
 		copy(buf[2:], str)
 
+	case "date":
+		val, err := toTime(value)
+		if err != nil {
+			return nil, err
+		}
+
+		buf = make([]byte, 2)
+		SetDateAt(buf, 0, val)
+
+	case "time_of_day":
+		val, err := toTimeOfDay(value)
+		if err != nil {
+			return nil, err
+		}
+
+		buf = make([]byte, 4)
+		SetTimeOfDayAt(buf, 0, val)
+
+	case "date_and_time":
+		val, err := toTime(value)
+		if err != nil {
+			return nil, err
+		}
+
+		if year := val.UTC().Year(); year < dateAndTimeMinYear || year > dateAndTimeMaxYear {
+			return nil, fmt.Errorf("%w: ano %d fora do intervalo do DATE_AND_TIME (%d-%d)",
+				ErrValueConversion, year, dateAndTimeMinYear, dateAndTimeMaxYear)
+		}
+
+		buf = make([]byte, 8)
+		SetDateAndTimeAt(buf, 0, val)
+
+	case "timer":
+		val, err := toTimer(value)
+		if err != nil {
+			return nil, err
+		}
+
+		buf = make([]byte, 2)
+		SetTimerAt(buf, 0, val)
+
+	case "counter":
+		val, err := toCounter(value)
+		if err != nil {
+			return nil, err
+		}
+
+		buf = make([]byte, 2)
+		SetCounterAt(buf, 0, val)
+
 	default:
-		return fmt.Errorf("%w: %s", ErrInvalidDataType, dataType)
+		return nil, fmt.Errorf("%w: %s", ErrInvalidDataType, dataType)
 	}
 
-	// Escrever os bytes no PLC
-	err := c.client.AGWriteDB(dbNumber, byteOffset, len(buf), buf)
-	if err != nil {
-		if isNetworkError(err) {
-			c.isConnected = false
-			return fmt.Errorf("%w: DB%d.%d: %v", ErrNetworkFailure, dbNumber, byteOffset, err)
+	return buf, nil
+}
+
+// toBool converte o valor recebido para bool
+func toBool(value interface{}) (bool, error) {
+	switch v := value.(type) {
+	case bool:
+		return v, nil
+	case int:
+		return v != 0, nil
+	case float64:
+		return v != 0, nil
+	case string:
+		return v == "true" || v == "1" || v == "yes" || v == "sim", nil
+	default:
+		return false, fmt.Errorf("%w: esperado valor convertível para bool, recebido %T", ErrValueConversion, value)
+	}
+}
+
+// toTime converte o valor recebido para time.Time (aceita RFC3339 ou AAAA-MM-DD)
+func toTime(value interface{}) (time.Time, error) {
+	switch v := value.(type) {
+	case time.Time:
+		return v, nil
+	case string:
+		if t, err := time.Parse(time.RFC3339Nano, v); err == nil {
+			return t, nil
 		}
-		return fmt.Errorf("erro ao escrever dados no PLC (DB%d.%d): %w", dbNumber, byteOffset, err)
+		if t, err := time.Parse("2006-01-02", v); err == nil {
+			return t, nil
+		}
+		return time.Time{}, fmt.Errorf("%w: data inválida %q (use RFC3339 ou AAAA-MM-DD)", ErrValueConversion, v)
+	default:
+		return time.Time{}, fmt.Errorf("%w: esperado data, recebido %T", ErrValueConversion, value)
 	}
+}
 
-	return nil
+// toTimeOfDay converte o valor recebido para a duração desde a meia-noite.
+// Números são interpretados como milissegundos; strings como HH:MM:SS[.mmm]
+// ou no formato de time.ParseDuration.
+func toTimeOfDay(value interface{}) (time.Duration, error) {
+	var d time.Duration
+
+	switch v := value.(type) {
+	case time.Duration:
+		d = v
+	case int:
+		d = time.Duration(v) * time.Millisecond
+	case float64:
+		d = time.Duration(v) * time.Millisecond
+	case string:
+		if t, err := time.Parse("15:04:05.999", v); err == nil {
+			d = time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute +
+				time.Duration(t.Second())*time.Second + time.Duration(t.Nanosecond())
+		} else if parsed, err := time.ParseDuration(v); err == nil {
+			d = parsed
+		} else {
+			return 0, fmt.Errorf("%w: hora inválida %q (use HH:MM:SS.mmm)", ErrValueConversion, v)
+		}
+	default:
+		return 0, fmt.Errorf("%w: esperado hora do dia, recebido %T", ErrValueConversion, value)
+	}
+
+	if d < 0 || d >= 24*time.Hour {
+		return 0, fmt.Errorf("%w: hora do dia %v fora de 00:00:00 a 23:59:59.999", ErrValueConversion, d)
+	}
+	return d, nil
+}
+
+// toTimer converte o valor recebido para a duração de um S5TIME. Números são
+// interpretados como milissegundos; strings no formato de time.ParseDuration,
+// com ou sem o prefixo S5T# ou T# (ex.: "S5T#1M30S").
+func toTimer(value interface{}) (time.Duration, error) {
+	var d time.Duration
+
+	switch v := value.(type) {
+	case time.Duration:
+		d = v
+	case int:
+		d = time.Duration(v) * time.Millisecond
+	case int64:
+		d = time.Duration(v) * time.Millisecond
+	case float64:
+		d = time.Duration(v * float64(time.Millisecond))
+	case string:
+		str := strings.ToLower(strings.TrimSpace(v))
+		str = strings.TrimPrefix(strings.TrimPrefix(str, "s5t#"), "t#")
+		parsed, err := time.ParseDuration(str)
+		if err != nil {
+			return 0, fmt.Errorf("%w: temporizador inválido %q (use, por exemplo, S5T#1M30S ou 90s)", ErrValueConversion, v)
+		}
+		d = parsed
+	default:
+		return 0, fmt.Errorf("%w: esperado duração, recebido %T", ErrValueConversion, value)
+	}
+
+	if d < 0 || d > MaxTimerValue {
+		return 0, fmt.Errorf("%w: temporizador %v fora de 0 a %v", ErrValueConversion, d, MaxTimerValue)
+	}
+	return d, nil
+}
+
+// toCounter converte o valor recebido para o valor de um contador S7
+func toCounter(value interface{}) (int, error) {
+	var n int
+
+	switch v := value.(type) {
+	case int:
+		n = v
+	case int64:
+		n = int(v)
+	case uint16:
+		n = int(v)
+	case float64:
+		if v != math.Trunc(v) {
+			return 0, fmt.Errorf("%w: contador deve ser inteiro, recebido %v", ErrValueConversion, v)
+		}
+		n = int(v)
+	case string:
+		parsed, err := strconv.Atoi(strings.TrimSpace(v))
+		if err != nil {
+			return 0, fmt.Errorf("%w: contador inválido %q", ErrValueConversion, v)
+		}
+		n = parsed
+	default:
+		return 0, fmt.Errorf("%w: esperado inteiro, recebido %T", ErrValueConversion, value)
+	}
+
+	if n < 0 || n > MaxCounterValue {
+		return 0, fmt.Errorf("%w: contador %d fora de 0 a %d", ErrValueConversion, n, MaxCounterValue)
+	}
+	return n, nil
 }
@@ -0,0 +1,101 @@
+package plc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/robinson/gos7"
+)
+
+// newPingClient cria um cliente marcado como conectado apontando para address
+func newPingClient(address string) *Client {
+	return &Client{
+		handler:     gos7.NewTCPClientHandler(address, 0, 1),
+		isConnected: true,
+	}
+}
+
+// listen abre um socket local que aceita e fecha conexões
+func listen(t *testing.T) net.Listener {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("erro ao abrir socket de teste: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+	return ln
+}
+
+func TestPingContextSucceeds(t *testing.T) {
+	ln := listen(t)
+	c := newPingClient(ln.Addr().String())
+
+	if err := c.PingContext(context.Background()); err != nil {
+		t.Fatalf("PingContext() = %v, esperado nil", err)
+	}
+	if !c.IsConnected() {
+		t.Error("conexão deveria continuar ativa")
+	}
+}
+
+func TestPingContextExpiredWhileBusyKeepsConnection(t *testing.T) {
+	ln := listen(t)
+	c := newPingClient(ln.Addr().String())
+
+	// Uma leitura longa ocupa a conexão além do prazo do ping
+	c.mu.Lock()
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	result := make(chan error, 1)
+	go func() { result <- c.PingContext(ctx) }()
+
+	<-ctx.Done()
+	c.mu.Unlock()
+
+	err := <-result
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("PingContext() = %v, esperado context.DeadlineExceeded", err)
+	}
+	if !c.IsConnected() {
+		t.Error("ping expirado não deve marcar a conexão como perdida")
+	}
+}
+
+func TestPingContextNetworkFailureMarksDisconnected(t *testing.T) {
+	// Endereço de um socket já fechado: a conexão é recusada
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("erro ao abrir socket de teste: %v", err)
+	}
+	address := ln.Addr().String()
+	ln.Close()
+
+	c := newPingClient(address)
+	err = c.PingContext(context.Background())
+	if !errors.Is(err, ErrNetworkFailure) {
+		t.Fatalf("PingContext() = %v, esperado ErrNetworkFailure", err)
+	}
+	if c.IsConnected() {
+		t.Error("falha de rede deve marcar a conexão como perdida")
+	}
+}
+
+func TestPingContextWithoutHandler(t *testing.T) {
+	c := &Client{}
+	if err := c.PingContext(context.Background()); !errors.Is(err, ErrConnectionClosed) {
+		t.Fatalf("PingContext() = %v, esperado ErrConnectionClosed", err)
+	}
+}
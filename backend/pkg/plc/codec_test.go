@@ -0,0 +1,54 @@
+package plc
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEncodeDecodeRoundTrip(t *testing.T) {
+	tests := []struct {
+		dataType string
+		value    interface{}
+		want     interface{}
+	}{
+		{"real", float32(12.5), float32(12.5)},
+		{"dint", -70000, int32(-70000)},
+		{"dword", 70000, uint32(70000)},
+		{"int", -1234, int16(-1234)},
+		{"word", 65535, uint16(65535)},
+		{"sint", -12, int8(-12)},
+		{"byte", 200, uint8(200)},
+		{"char", "A", "A"},
+		{"string", "bomba 1", "bomba 1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.dataType, func(t *testing.T) {
+			buf, err := encodeValue(tt.dataType, tt.value)
+			if err != nil {
+				t.Fatalf("encodeValue(%v): %v", tt.value, err)
+			}
+
+			size, _ := DataTypeSize(tt.dataType)
+			if len(buf) < size {
+				buf = append(buf, make([]byte, size-len(buf))...)
+			}
+
+			got, err := DecodeValue(buf, tt.dataType, 0)
+			if err != nil {
+				t.Fatalf("DecodeValue: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ida e volta = %#v, esperado %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncodeCharRejectsInvalidValues(t *testing.T) {
+	for _, value := range []interface{}{"", "AB", 65} {
+		if _, err := encodeValue("char", value); err == nil {
+			t.Errorf("encodeValue(char, %#v) deveria falhar", value)
+		}
+	}
+}
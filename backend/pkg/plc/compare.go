@@ -4,6 +4,8 @@ import (
 	"log"
 	"math"
 	"reflect"
+	"strconv"
+	"strings"
 )
 
 // CompareValues compara dois valores de forma robusta, tratando números com tolerância.
@@ -29,8 +31,8 @@ func CompareValues(old, new interface{}) bool {
 	if reflect.TypeOf(old) == reflect.TypeOf(new) {
 		switch old.(type) {
 		case float32, float64:
-			oldNum, okOld := toFloat64(old)
-			newNum, okNew := toFloat64(new)
+			oldNum, okOld := ToFloat64(old)
+			newNum, okNew := ToFloat64(new)
 			if okOld && okNew {
 				// Usa tolerância aumentada para evitar falsas mudanças por arredondamento
 				return math.Abs(oldNum-newNum) < 1e-5
@@ -39,25 +41,29 @@ func CompareValues(old, new interface{}) bool {
 			// Comparação direta para booleanos
 			return old.(bool) == new.(bool)
 		}
-		// Para os demais tipos, pode usar comparação direta.
+		// Para os demais tipos, pode usar comparação direta. Slices e mapas
+		// (ex.: valores vindos de JSON) não são comparáveis com ==.
+		if !oldType.Comparable() {
+			return reflect.DeepEqual(old, new)
+		}
 		return old == new
 	}
 
 	// Se os tipos diferem, tenta converter ambos para float64 (para números).
-	oldNum, okOld := toFloat64(old)
-	newNum, okNew := toFloat64(new)
+	oldNum, okOld := ToFloat64(old)
+	newNum, okNew := ToFloat64(new)
 	if okOld && okNew {
 		return math.Abs(oldNum-newNum) < 1e-5
 	}
 
 	// Se um dos valores é booleano, tenta uma comparação especial
 	if oldBool, okOld := old.(bool); okOld {
-		if newNum, okNew := toFloat64(new); okNew {
+		if newNum, okNew := ToFloat64(new); okNew {
 			return (oldBool && newNum != 0) || (!oldBool && newNum == 0)
 		}
 	}
 	if newBool, okNew := new.(bool); okNew {
-		if oldNum, okOld := toFloat64(old); okOld {
+		if oldNum, okOld := ToFloat64(old); okOld {
 			return (newBool && oldNum != 0) || (!newBool && oldNum == 0)
 		}
 	}
@@ -71,8 +77,8 @@ func CompareValues(old, new interface{}) bool {
 	return result
 }
 
-// toFloat64 tenta converter um valor numérico para float64.
-func toFloat64(v interface{}) (float64, bool) {
+// ToFloat64 tenta converter um valor numérico para float64.
+func ToFloat64(v interface{}) (float64, bool) {
 	switch n := v.(type) {
 	case int:
 		return float64(n), true
@@ -117,3 +123,36 @@ func toFloat64(v interface{}) (float64, bool) {
 		return 0, false
 	}
 }
+
+// ExtractFloat64 converte um valor para float64 como ToFloat64 e, além disso,
+// aceita strings numéricas (ex.: "12.5"). Indicado para valores vindos de
+// fontes externas; CompareValues continua usando a conversão estrita.
+func ExtractFloat64(v interface{}) (float64, bool) {
+	if s, ok := v.(string); ok {
+		f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+		if err != nil {
+			return 0, false
+		}
+		return f, true
+	}
+	return ToFloat64(v)
+}
+
+// ExtractBool converte um valor para bool: números são verdadeiros quando
+// diferentes de zero e strings aceitam os formatos de strconv.ParseBool
+func ExtractBool(v interface{}) (bool, bool) {
+	switch b := v.(type) {
+	case bool:
+		return b, true
+	case string:
+		parsed, err := strconv.ParseBool(strings.TrimSpace(b))
+		if err != nil {
+			return false, false
+		}
+		return parsed, true
+	}
+	if f, ok := ToFloat64(v); ok {
+		return f != 0, true
+	}
+	return false, false
+}
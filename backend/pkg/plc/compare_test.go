@@ -0,0 +1,125 @@
+package plc
+
+import (
+	"math"
+	"testing"
+)
+
+// level é um tipo numérico nomeado, convertido por reflexão
+type level int16
+
+func TestCompareValues(t *testing.T) {
+	tests := []struct {
+		name     string
+		old, new interface{}
+		want     bool
+	}{
+		{"ambos nil", nil, nil, true},
+		{"um nil", nil, 0, false},
+		{"float32 iguais", float32(1.5), float32(1.5), true},
+		{"float32 dentro da tolerância", float32(1.0), float32(1.000001), true},
+		{"float32 fora da tolerância", float32(1.0), float32(1.0001), false},
+		{"float64 abaixo de 1e-5", 10.0, 10.000009, true},
+		{"float64 acima de 1e-5", 10.0, 10.00002, false},
+		{"float32 e float64 do mesmo valor", float32(0.1), 0.1, true},
+		{"float32 e float64 diferentes", float32(0.1), 0.2, false},
+		{"int16 e float64", int16(42), 42.0, true},
+		{"uint8 e int", uint8(200), 200, true},
+		{"int32 e int64 diferentes", int32(-1), int64(1), false},
+		{"tipo nomeado e int", level(7), 7, true},
+		{"bool iguais", true, true, true},
+		{"bool diferentes", true, false, false},
+		{"bool verdadeiro e 1.0", true, 1.0, true},
+		{"bool falso e 0", false, 0, true},
+		{"bool verdadeiro e 0", true, 0, false},
+		{"float e bool falso", 0.0, false, true},
+		{"strings iguais", "abc", "abc", true},
+		{"strings diferentes", "abc", "abd", false},
+		// CompareValues não converte strings, mesmo numéricas
+		{"string numérica e float", "12.5", 12.5, false},
+		{"slices iguais", []int{1, 2}, []int{1, 2}, true},
+		{"slices diferentes", []int{1, 2}, []int{1, 3}, false},
+		{"mapas iguais", map[string]interface{}{"a": 1.0}, map[string]interface{}{"a": 1.0}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CompareValues(tt.old, tt.new); got != tt.want {
+				t.Errorf("CompareValues(%#v, %#v) = %v, esperado %v", tt.old, tt.new, got, tt.want)
+			}
+			if got := CompareValues(tt.new, tt.old); got != tt.want {
+				t.Errorf("CompareValues(%#v, %#v) = %v, esperado %v (ordem invertida)", tt.new, tt.old, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractFloat64(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  interface{}
+		want   float64
+		wantOK bool
+	}{
+		{"int", -3, -3, true},
+		{"int8", int8(-128), -128, true},
+		{"uint16", uint16(65535), 65535, true},
+		{"uint64", uint64(1 << 40), 1 << 40, true},
+		{"float32", float32(0.25), 0.25, true},
+		{"float64", 3.75, 3.75, true},
+		{"bool verdadeiro", true, 1, true},
+		{"bool falso", false, 0, true},
+		{"tipo nomeado", level(-9), -9, true},
+		{"string numérica", "12.5", 12.5, true},
+		{"string com espaços", " -4 ", -4, true},
+		{"string em notação científica", "1e3", 1000, true},
+		{"string inválida", "doze", 0, false},
+		{"string vazia", "", 0, false},
+		{"nil", nil, 0, false},
+		{"struct", struct{}{}, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ExtractFloat64(tt.value)
+			if ok != tt.wantOK || (ok && math.Abs(got-tt.want) > 1e-9) {
+				t.Errorf("ExtractFloat64(%#v) = %v, %v; esperado %v, %v", tt.value, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+
+	// float32 → float64 preserva o valor representado em float32
+	if got, _ := ExtractFloat64(float32(0.1)); got != float64(float32(0.1)) || math.Abs(got-0.1) >= 1e-5 {
+		t.Errorf("ExtractFloat64(float32(0.1)) = %v", got)
+	}
+}
+
+func TestExtractBool(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  interface{}
+		want   bool
+		wantOK bool
+	}{
+		{"bool verdadeiro", true, true, true},
+		{"bool falso", false, false, true},
+		{"int diferente de zero", 5, true, true},
+		{"int zero", 0, false, true},
+		{"float diferente de zero", 0.5, true, true},
+		{"float32 zero", float32(0), false, true},
+		{"string true", "true", true, true},
+		{"string 1", "1", true, true},
+		{"string FALSE com espaços", " FALSE ", false, true},
+		{"string inválida", "sim", false, false},
+		{"nil", nil, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ExtractBool(tt.value)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("ExtractBool(%#v) = %v, %v; esperado %v, %v", tt.value, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
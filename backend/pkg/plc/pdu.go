@@ -0,0 +1,149 @@
+// pkg/plc/pdu.go
+package plc
+
+import (
+	"errors"
+	"fmt"
+)
+
+// PDUSizeConfig define manualmente o tamanho de PDU usado nas leituras.
+// Zero indica que o valor negociado na conexão deve ser usado.
+type PDUSizeConfig int
+
+const (
+	// defaultPDUSize é usado quando o PDU negociado não está disponível
+	defaultPDUSize = 240
+	// pduReadOverhead é o cabeçalho do telegrama de resposta de leitura
+	pduReadOverhead = 18
+)
+
+// MinReadPayload é quanto uma única leitura transfere com o menor PDU
+// negociado pelas CPUs S7 (240 bytes). Faixas até esse tamanho são lidas em
+// um só telegrama em qualquer PLC, portanto de forma consistente.
+const MinReadPayload = defaultPDUSize - pduReadOverhead
+
+// ErrInvalidReadRange indica parâmetros de leitura inconsistentes
+var ErrInvalidReadRange = errors.New("faixa de leitura inválida")
+
+// GetNegotiatedPDUSize retorna o tamanho de PDU em uso. A configuração manual
+// tem prioridade; sem ela, usa o valor negociado ou 240 bytes por segurança.
+func (c *Client) GetNegotiatedPDUSize() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.pduSizeLocked()
+}
+
+// pduSizeLocked deve ser chamado com c.mu travado. Uma configuração manual
+// que não comporta o cabeçalho da resposta é ignorada: o bloco de leitura
+// ficaria vazio ou negativo.
+func (c *Client) pduSizeLocked() int {
+	if c.config.PDUSize > pduReadOverhead {
+		return int(c.config.PDUSize)
+	}
+	if c.handler != nil && c.handler.PDULength > pduReadOverhead {
+		return c.handler.PDULength
+	}
+	return defaultPDUSize
+}
+
+// ReadLargeDB lê totalSize bytes de um DB a partir de startOffset, dividindo
+// a leitura em blocos que cabem em um PDU e remontando o resultado em buf.
+func (c *Client) ReadLargeDB(dbNumber, startOffset, totalSize int, buf []byte) error {
+	if startOffset < 0 || totalSize <= 0 || len(buf) < totalSize {
+		return fmt.Errorf("%w: início %d, tamanho %d, buffer %d", ErrInvalidReadRange, startOffset, totalSize, len(buf))
+	}
+
+	if err := c.ensureConnected(); err != nil {
+		return fmt.Errorf("erro de conexão: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.client == nil {
+		return ErrConnectionClosed
+	}
+
+	chunkSize := c.pduSizeLocked() - pduReadOverhead
+
+	for read := 0; read < totalSize; read += chunkSize {
+		size := chunkSize
+		if read+size > totalSize {
+			size = totalSize - read
+		}
+
+		if err := c.client.AGReadDB(dbNumber, startOffset+read, size, buf[read:read+size]); err != nil {
+			if isNetworkError(err) {
+				c.isConnected = false
+			}
+			return fmt.Errorf("erro ao ler DB%d.DBB%d (%d bytes): %w", dbNumber, startOffset+read, size, err)
+		}
+	}
+
+	return nil
+}
+
+// BatchReadDB lê uma faixa contínua de um DB. Faixas maiores que um PDU são
+// lidas em blocos através de ReadLargeDB.
+func (c *Client) BatchReadDB(dbNumber, startOffset, size int) ([]byte, error) {
+	if size <= 0 || startOffset < 0 {
+		return nil, fmt.Errorf("%w: início %d, tamanho %d", ErrInvalidReadRange, startOffset, size)
+	}
+
+	buf := make([]byte, size)
+
+	if size > c.GetNegotiatedPDUSize()-pduReadOverhead {
+		if err := c.ReadLargeDB(dbNumber, startOffset, size, buf); err != nil {
+			return nil, err
+		}
+		return buf, nil
+	}
+
+	if err := c.ensureConnected(); err != nil {
+		return nil, fmt.Errorf("erro de conexão: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.client == nil {
+		return nil, ErrConnectionClosed
+	}
+
+	if err := c.client.AGReadDB(dbNumber, startOffset, size, buf); err != nil {
+		if isNetworkError(err) {
+			c.isConnected = false
+		}
+		return nil, fmt.Errorf("erro ao ler DB%d.DBB%d (%d bytes): %w", dbNumber, startOffset, size, err)
+	}
+
+	return buf, nil
+}
+
+// WriteRawDB grava os bytes em um DB a partir de startOffset, sem conversão
+// de tipo. Usado na depuração de DBs sem tabela de símbolos.
+func (c *Client) WriteRawDB(dbNumber, startOffset int, data []byte) error {
+	if startOffset < 0 || len(data) == 0 {
+		return fmt.Errorf("%w: início %d, tamanho %d", ErrInvalidReadRange, startOffset, len(data))
+	}
+
+	if err := c.ensureConnected(); err != nil {
+		return fmt.Errorf("erro de conexão: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.client == nil {
+		return ErrConnectionClosed
+	}
+
+	if err := c.client.AGWriteDB(dbNumber, startOffset, len(data), data); err != nil {
+		if isNetworkError(err) {
+			c.isConnected = false
+		}
+		return fmt.Errorf("erro ao escrever DB%d.DBB%d (%d bytes): %w", dbNumber, startOffset, len(data), err)
+	}
+
+	return nil
+}
@@ -0,0 +1,213 @@
+package plc
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/robinson/gos7"
+)
+
+// readCall registra uma chamada a AGReadDB
+type readCall struct {
+	dbNumber, start, size int
+}
+
+// mockS7Client simula a leitura de um DB em memória e registra cada chamada.
+// Os demais métodos de gos7.Client não são usados nestes testes.
+type mockS7Client struct {
+	gos7.Client
+	db     []byte
+	calls  []readCall
+	failAt int // índice da chamada que falha (-1 para nunca)
+	err    error
+}
+
+func (m *mockS7Client) AGReadDB(dbNumber, start, size int, buffer []byte) error {
+	m.calls = append(m.calls, readCall{dbNumber, start, size})
+	if len(m.calls)-1 == m.failAt {
+		return m.err
+	}
+	copy(buffer, m.db[start:start+size])
+	return nil
+}
+
+// newMockClient cria um cliente conectado ao mock com o PDU negociado informado
+func newMockClient(negotiated int, override PDUSizeConfig) (*Client, *mockS7Client) {
+	db := make([]byte, 4096)
+	for i := range db {
+		db[i] = byte(i % 251)
+	}
+	mock := &mockS7Client{db: db, failAt: -1}
+
+	handler := gos7.NewTCPClientHandler("127.0.0.1:102", 0, 1)
+	handler.PDULength = negotiated
+
+	return &Client{
+		client:      mock,
+		handler:     handler,
+		config:      ClientConfig{PDUSize: override},
+		isConnected: true,
+	}, mock
+}
+
+func TestGetNegotiatedPDUSize(t *testing.T) {
+	tests := []struct {
+		name       string
+		negotiated int
+		override   PDUSizeConfig
+		want       int
+	}{
+		{"negociado", 480, 0, 480},
+		{"sem negociação usa o padrão", 0, 0, defaultPDUSize},
+		{"negociado menor que o cabeçalho usa o padrão", pduReadOverhead, 0, defaultPDUSize},
+		{"configuração manual tem prioridade", 960, 240, 240},
+		{"configuração igual ao cabeçalho é ignorada", 480, pduReadOverhead, 480},
+		{"configuração menor que o cabeçalho é ignorada", 480, 1, 480},
+		{"configuração inválida sem negociação usa o padrão", 0, 10, defaultPDUSize},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := newMockClient(tt.negotiated, tt.override)
+			if got := c.GetNegotiatedPDUSize(); got != tt.want {
+				t.Errorf("GetNegotiatedPDUSize() = %d, esperado %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadLargeDBSplitsByPDU(t *testing.T) {
+	tests := []struct {
+		name       string
+		negotiated int
+		start      int
+		size       int
+		want       []readCall
+	}{
+		{"cabe em um bloco", 480, 10, 462, []readCall{{7, 10, 462}}},
+		{"um byte além do bloco", 480, 0, 463, []readCall{{7, 0, 462}, {7, 462, 1}}},
+		{"PDU de 240", 240, 100, 500, []readCall{{7, 100, 222}, {7, 322, 222}, {7, 544, 56}}},
+		{"PDU padrão sem negociação", 0, 0, 444, []readCall{{7, 0, 222}, {7, 222, 222}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, mock := newMockClient(tt.negotiated, 0)
+
+			buf := make([]byte, tt.size)
+			if err := c.ReadLargeDB(7, tt.start, tt.size, buf); err != nil {
+				t.Fatalf("erro inesperado: %v", err)
+			}
+
+			if len(mock.calls) != len(tt.want) {
+				t.Fatalf("chamadas = %v, esperado %v", mock.calls, tt.want)
+			}
+			for i, call := range mock.calls {
+				if call != tt.want[i] {
+					t.Errorf("chamada %d = %+v, esperado %+v", i, call, tt.want[i])
+				}
+			}
+			for i, b := range buf {
+				if want := mock.db[tt.start+i]; b != want {
+					t.Fatalf("buf[%d] = %d, esperado %d", i, b, want)
+				}
+			}
+		})
+	}
+}
+
+func TestReadWithTooSmallPDUOverride(t *testing.T) {
+	for _, override := range []PDUSizeConfig{1, pduReadOverhead} {
+		c, mock := newMockClient(240, override)
+
+		done := make(chan error, 2)
+		go func() {
+			buf := make([]byte, 300)
+			done <- c.ReadLargeDB(7, 0, len(buf), buf)
+			_, err := c.BatchReadDB(7, 0, 300)
+			done <- err
+		}()
+
+		for i := 0; i < 2; i++ {
+			select {
+			case err := <-done:
+				if err != nil {
+					t.Fatalf("PDU %d: erro inesperado: %v", override, err)
+				}
+			case <-time.After(2 * time.Second):
+				t.Fatalf("PDU %d: leitura não terminou", override)
+			}
+		}
+
+		// As duas leituras usam blocos do PDU negociado
+		want := []readCall{{7, 0, 222}, {7, 222, 78}, {7, 0, 222}, {7, 222, 78}}
+		if len(mock.calls) != len(want) {
+			t.Fatalf("PDU %d: chamadas = %v, esperado %v", override, mock.calls, want)
+		}
+		for i, call := range mock.calls {
+			if call != want[i] {
+				t.Errorf("PDU %d: chamada %d = %+v, esperado %+v", override, i, call, want[i])
+			}
+		}
+	}
+}
+
+func TestReadLargeDBInvalidRange(t *testing.T) {
+	c, mock := newMockClient(480, 0)
+
+	cases := []struct {
+		start, size, bufLen int
+	}{
+		{-1, 10, 10},
+		{0, 0, 10},
+		{0, 20, 10},
+	}
+	for _, tc := range cases {
+		err := c.ReadLargeDB(1, tc.start, tc.size, make([]byte, tc.bufLen))
+		if !errors.Is(err, ErrInvalidReadRange) {
+			t.Errorf("ReadLargeDB(%d, %d, buf %d) = %v, esperado ErrInvalidReadRange", tc.start, tc.size, tc.bufLen, err)
+		}
+	}
+	if len(mock.calls) != 0 {
+		t.Errorf("nenhuma leitura esperada, houve %d", len(mock.calls))
+	}
+}
+
+func TestReadLargeDBStopsOnChunkError(t *testing.T) {
+	c, mock := newMockClient(240, 0)
+	mock.failAt = 1
+	mock.err = errors.New("item não disponível")
+
+	err := c.ReadLargeDB(3, 0, 600, make([]byte, 600))
+	if !errors.Is(err, mock.err) {
+		t.Fatalf("erro = %v, esperado %v", err, mock.err)
+	}
+	if len(mock.calls) != 2 {
+		t.Errorf("chamadas = %d, esperado 2 (parar no bloco com erro)", len(mock.calls))
+	}
+	if !c.IsConnected() {
+		t.Error("erro que não é de rede não deveria derrubar a conexão")
+	}
+}
+
+func TestBatchReadDBUsesChunksAbovePDU(t *testing.T) {
+	c, mock := newMockClient(480, 0)
+
+	data, err := c.BatchReadDB(2, 0, 100)
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if len(data) != 100 || len(mock.calls) != 1 {
+		t.Errorf("leitura pequena: %d bytes em %d chamadas, esperado 100 em 1", len(data), len(mock.calls))
+	}
+
+	mock.calls = nil
+	data, err = c.BatchReadDB(2, 0, 1000)
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if len(data) != 1000 || len(mock.calls) != 3 {
+		t.Errorf("leitura grande: %d bytes em %d chamadas, esperado 1000 em 3", len(data), len(mock.calls))
+	}
+}
@@ -0,0 +1,187 @@
+package plc
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDateRoundTrip(t *testing.T) {
+	for _, day := range []time.Time{
+		s7DateEpoch,
+		time.Date(2000, 2, 29, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC),
+		time.Date(2168, 12, 31, 0, 0, 0, 0, time.UTC),
+	} {
+		buf := make([]byte, 2)
+		SetDateAt(buf, 0, day)
+		if got := GetDateAt(buf, 0); !got.Equal(day) {
+			t.Errorf("ida e volta de %s = %s", day.Format("2006-01-02"), got.Format("2006-01-02"))
+		}
+	}
+}
+
+func TestSetDateAt(t *testing.T) {
+	tests := []struct {
+		name  string
+		value time.Time
+		want  []byte
+	}{
+		{"época", s7DateEpoch, []byte{0x00, 0x00}},
+		{"hora do dia descartada", time.Date(1990, 1, 2, 23, 59, 0, 0, time.UTC), []byte{0x00, 0x01}},
+		{"convertida para UTC", time.Date(1990, 1, 2, 22, 0, 0, 0, time.FixedZone("BRT", -3*3600)), []byte{0x00, 0x02}},
+		{"antes da época limitada a zero", time.Date(1980, 6, 1, 0, 0, 0, 0, time.UTC), []byte{0x00, 0x00}},
+		{"após o máximo limitada", time.Date(2200, 1, 1, 0, 0, 0, 0, time.UTC), []byte{0xFF, 0xFF}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := make([]byte, 2)
+			SetDateAt(buf, 0, tt.value)
+			if !bytes.Equal(buf, tt.want) {
+				t.Errorf("bytes = % X, esperado % X", buf, tt.want)
+			}
+		})
+	}
+}
+
+func TestTimeOfDayRoundTrip(t *testing.T) {
+	for _, tod := range []time.Duration{
+		0,
+		12*time.Hour + 30*time.Minute + 45*time.Second + 500*time.Millisecond,
+		24*time.Hour - time.Millisecond,
+	} {
+		buf := make([]byte, 4)
+		SetTimeOfDayAt(buf, 0, tod)
+		if got := GetTimeOfDayAt(buf, 0); got != tod {
+			t.Errorf("ida e volta de %v = %v", tod, got)
+		}
+	}
+
+	// Frações abaixo de 1 ms são truncadas
+	buf := make([]byte, 4)
+	SetTimeOfDayAt(buf, 0, time.Second+999*time.Microsecond)
+	if got := GetTimeOfDayAt(buf, 0); got != time.Second {
+		t.Errorf("TIME_OF_DAY truncado = %v, esperado 1s", got)
+	}
+}
+
+func TestDateAndTimeKnownEncoding(t *testing.T) {
+	// Exemplo do comentário de GetDateAndTimeAt: sexta-feira (6)
+	raw := []byte{0x24, 0x03, 0x15, 0x12, 0x30, 0x45, 0x50, 0x06}
+	want := time.Date(2024, 3, 15, 12, 30, 45, 500*int(time.Millisecond), time.UTC)
+
+	if got := GetDateAndTimeAt(raw, 0); !got.Equal(want) {
+		t.Errorf("GetDateAndTimeAt = %s, esperado %s", got, want)
+	}
+
+	buf := make([]byte, 8)
+	SetDateAndTimeAt(buf, 0, want)
+	if !bytes.Equal(buf, raw) {
+		t.Errorf("SetDateAndTimeAt = % X, esperado % X", buf, raw)
+	}
+}
+
+func TestDateAndTimeRoundTrip(t *testing.T) {
+	for _, value := range []time.Time{
+		time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(1999, 12, 31, 23, 59, 59, 999*int(time.Millisecond), time.UTC),
+		time.Date(2000, 1, 1, 0, 0, 0, 1*int(time.Millisecond), time.UTC),
+		time.Date(2089, 12, 31, 23, 59, 59, 0, time.UTC),
+	} {
+		buf := make([]byte, 8)
+		SetDateAndTimeAt(buf, 0, value)
+		if got := GetDateAndTimeAt(buf, 0); !got.Equal(value) {
+			t.Errorf("ida e volta de %s = %s", value, got)
+		}
+		if weekday := int(buf[7]&0x0F) - 1; time.Weekday(weekday) != value.Weekday() {
+			t.Errorf("%s: dia da semana = %d, esperado %d", value, weekday, value.Weekday())
+		}
+	}
+}
+
+func TestDateTimeHelpersShortBuffer(t *testing.T) {
+	short := []byte{0x01}
+	if got := GetDateAt(short, 0); !got.Equal(s7DateEpoch) {
+		t.Errorf("GetDateAt com buffer curto = %s, esperado a época", got)
+	}
+	if got := GetTimeOfDayAt(short, 0); got != 0 {
+		t.Errorf("GetTimeOfDayAt com buffer curto = %v, esperado 0", got)
+	}
+	if got := GetDateAndTimeAt(short, 0); !got.Equal(s7DateEpoch) {
+		t.Errorf("GetDateAndTimeAt com buffer curto = %s, esperado a época", got)
+	}
+
+	// Setters não escrevem fora do buffer
+	SetDateAt(short, 0, time.Now())
+	SetTimeOfDayAt(short, 0, time.Hour)
+	SetDateAndTimeAt(short, 0, time.Now())
+	if short[0] != 0x01 {
+		t.Errorf("buffer curto alterado: % X", short)
+	}
+}
+
+func TestEncodeDecodeDateTimeTypes(t *testing.T) {
+	dt := time.Date(2024, 3, 15, 12, 30, 45, 500*int(time.Millisecond), time.UTC)
+
+	tests := []struct {
+		dataType string
+		value    interface{}
+		want     interface{}
+	}{
+		{"date", "2024-03-15", time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)},
+		{"date", dt, time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)},
+		{"time_of_day", "12:30:45.500", 12*time.Hour + 30*time.Minute + 45*time.Second + 500*time.Millisecond},
+		{"time_of_day", 1500, 1500 * time.Millisecond},
+		{"time_of_day", 90 * time.Minute, 90 * time.Minute},
+		{"date_and_time", "2024-03-15T12:30:45.5Z", dt},
+		{"date_and_time", "2024-03-15T09:30:45.5-03:00", dt},
+	}
+
+	for _, tt := range tests {
+		buf, err := encodeValue(tt.dataType, tt.value)
+		if err != nil {
+			t.Errorf("encodeValue(%s, %v): %v", tt.dataType, tt.value, err)
+			continue
+		}
+		if size, _ := DataTypeSize(tt.dataType); len(buf) != size {
+			t.Errorf("%s: %d bytes, esperado %d", tt.dataType, len(buf), size)
+		}
+
+		got, err := DecodeValue(buf, tt.dataType, 0)
+		if err != nil {
+			t.Errorf("DecodeValue(%s): %v", tt.dataType, err)
+			continue
+		}
+		if gotTime, ok := got.(time.Time); ok {
+			if !gotTime.Equal(tt.want.(time.Time)) {
+				t.Errorf("%s %v: ida e volta = %s, esperado %s", tt.dataType, tt.value, gotTime, tt.want)
+			}
+		} else if got != tt.want {
+			t.Errorf("%s %v: ida e volta = %#v, esperado %#v", tt.dataType, tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestEncodeDateTimeTypesRejectsInvalidValues(t *testing.T) {
+	tests := []struct {
+		dataType string
+		value    interface{}
+	}{
+		{"date", "15/03/2024"},
+		{"date", 20240315},
+		{"time_of_day", "25:00:00"},
+		{"time_of_day", -1},
+		{"time_of_day", 24 * time.Hour},
+		{"time_of_day", true},
+		{"date_and_time", "2090-01-01T00:00:00Z"},
+		{"date_and_time", "1989-12-31T23:59:59Z"},
+	}
+
+	for _, tt := range tests {
+		if _, err := encodeValue(tt.dataType, tt.value); !errors.Is(err, ErrValueConversion) {
+			t.Errorf("encodeValue(%s, %v) = %v, esperado ErrValueConversion", tt.dataType, tt.value, err)
+		}
+	}
+}
@@ -2,7 +2,9 @@ package plc
 
 import (
 	"encoding/binary"
+	"fmt"
 	"math"
+	"time"
 )
 
 // GetFloat32At converte 4 bytes no formato S7 para float32
@@ -75,3 +77,220 @@ func SetBoolAt(bytes []byte, bytePos, bitPos int, value bool) {
 		bytes[bytePos] &= ^(1 << uint(bitPos)) // Desativar o bit
 	}
 }
+
+// GetCharAt obtém um caractere S7 CHAR (um byte ASCII)
+func GetCharAt(bytes []byte, pos int) byte {
+	if pos < 0 || pos >= len(bytes) {
+		return 0
+	}
+	return bytes[pos]
+}
+
+// SetCharAt define um caractere S7 CHAR (um byte ASCII)
+func SetCharAt(bytes []byte, pos int, c byte) {
+	if pos < 0 || pos >= len(bytes) {
+		return
+	}
+	bytes[pos] = c
+}
+
+// UnpackBitWord separa os 16 bits de uma word em sinais booleanos nomeados.
+// Bits sem rótulo recebem o nome "bit_N".
+func UnpackBitWord(word uint16, labels map[int]string) map[string]bool {
+	bits := make(map[string]bool, 16)
+	for pos := 0; pos < 16; pos++ {
+		name, ok := labels[pos]
+		if !ok || name == "" {
+			name = fmt.Sprintf("bit_%d", pos)
+		}
+		bits[name] = word&(1<<uint(pos)) != 0
+	}
+	return bits
+}
+
+// s7DateEpoch é a data base dos tipos DATE do S7
+var s7DateEpoch = time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// GetDateAt converte um S7 DATE (2 bytes, dias desde 1990-01-01) para time.Time
+func GetDateAt(bytes []byte, pos int) time.Time {
+	if pos+2 > len(bytes) {
+		return s7DateEpoch
+	}
+	days := binary.BigEndian.Uint16(bytes[pos : pos+2])
+	return s7DateEpoch.AddDate(0, 0, int(days))
+}
+
+// SetDateAt converte uma data para S7 DATE. Datas fora da faixa
+// 1990-01-01..2168-12-31 são limitadas aos extremos.
+func SetDateAt(bytes []byte, pos int, value time.Time) {
+	if pos+2 > len(bytes) {
+		return
+	}
+	value = value.UTC()
+	day := time.Date(value.Year(), value.Month(), value.Day(), 0, 0, 0, 0, time.UTC)
+	days := int64(day.Sub(s7DateEpoch).Hours() / 24)
+	if days < 0 {
+		days = 0
+	}
+	if days > math.MaxUint16 {
+		days = math.MaxUint16
+	}
+	binary.BigEndian.PutUint16(bytes[pos:pos+2], uint16(days))
+}
+
+// GetTimeOfDayAt converte um S7 TIME_OF_DAY (4 bytes, ms desde a meia-noite) para time.Duration
+func GetTimeOfDayAt(bytes []byte, pos int) time.Duration {
+	if pos+4 > len(bytes) {
+		return 0
+	}
+	ms := binary.BigEndian.Uint32(bytes[pos : pos+4])
+	return time.Duration(ms) * time.Millisecond
+}
+
+// SetTimeOfDayAt converte uma duração desde a meia-noite para S7 TIME_OF_DAY
+func SetTimeOfDayAt(bytes []byte, pos int, value time.Duration) {
+	if pos+4 > len(bytes) {
+		return
+	}
+	binary.BigEndian.PutUint32(bytes[pos:pos+4], uint32(value/time.Millisecond))
+}
+
+// Intervalo de anos representável em DATE_AND_TIME
+const (
+	dateAndTimeMinYear = 1990
+	dateAndTimeMaxYear = 2089
+)
+
+// GetDateAndTimeAt converte um S7 DATE_AND_TIME (8 bytes BCD) para time.Time
+// em UTC. Layout: ano, mês, dia, hora, minuto, segundo, ms (3 dígitos) e dia
+// da semana no último nibble, ignorado na leitura. Anos 90-99 são 1990-1999 e
+// 00-89 são 2000-2089. Ex.: 24 03 15 12 30 45 50 06 = 2024-03-15 12:30:45.500.
+func GetDateAndTimeAt(bytes []byte, pos int) time.Time {
+	if pos+8 > len(bytes) {
+		return s7DateEpoch
+	}
+	b := bytes[pos : pos+8]
+
+	year := DecodeBCDByte(b[0])
+	if year >= 90 {
+		year += 1900
+	} else {
+		year += 2000
+	}
+	ms := DecodeBCDByte(b[6])*10 + int(b[7]>>4)
+
+	return time.Date(year, time.Month(DecodeBCDByte(b[1])), DecodeBCDByte(b[2]),
+		DecodeBCDByte(b[3]), DecodeBCDByte(b[4]), DecodeBCDByte(b[5]), ms*int(time.Millisecond), time.UTC)
+}
+
+// SetDateAndTimeAt converte um time.Time para S7 DATE_AND_TIME, sempre em
+// UTC. Anos fora de 1990-2089 não são representáveis; encodeValue os rejeita
+// antes da escrita.
+func SetDateAndTimeAt(bytes []byte, pos int, value time.Time) {
+	if pos+8 > len(bytes) {
+		return
+	}
+	value = value.UTC()
+	ms := value.Nanosecond() / int(time.Millisecond)
+
+	bytes[pos] = EncodeBCDByte(value.Year() % 100)
+	bytes[pos+1] = EncodeBCDByte(int(value.Month()))
+	bytes[pos+2] = EncodeBCDByte(value.Day())
+	bytes[pos+3] = EncodeBCDByte(value.Hour())
+	bytes[pos+4] = EncodeBCDByte(value.Minute())
+	bytes[pos+5] = EncodeBCDByte(value.Second())
+	bytes[pos+6] = EncodeBCDByte(ms / 10)
+	// Dia da semana no S7: 1 = domingo ... 7 = sábado
+	bytes[pos+7] = byte(ms%10)<<4 | byte(int(value.Weekday())+1)
+}
+
+// s5TimeBases são as bases de tempo do S5TIME, indexadas pelos bits 12-13
+var s5TimeBases = [4]time.Duration{
+	10 * time.Millisecond,
+	100 * time.Millisecond,
+	time.Second,
+	10 * time.Second,
+}
+
+// MaxTimerValue é o maior valor representável em S5TIME (999 x 10 s)
+const MaxTimerValue = 999 * 10 * time.Second
+
+// MaxCounterValue é o maior valor de um contador S7 (3 dígitos BCD)
+const MaxCounterValue = 999
+
+// decodeBCD3 converte os 3 dígitos BCD dos bits 0-11 de uma word
+func decodeBCD3(w uint16) int {
+	return int(w>>8&0x0F)*100 + int(w>>4&0x0F)*10 + int(w&0x0F)
+}
+
+// encodeBCD3 converte um inteiro de 0 a 999 para 3 dígitos BCD nos bits 0-11
+func encodeBCD3(v int) uint16 {
+	return uint16(v/100%10)<<8 | uint16(v/10%10)<<4 | uint16(v%10)
+}
+
+// GetTimerAt converte um S7 TIMER no formato S5TIME (2 bytes: base de tempo
+// nos bits 12-13 e valor BCD de 3 dígitos nos bits 0-11) para time.Duration.
+// Bases: 0 = 10 ms, 1 = 100 ms, 2 = 1 s, 3 = 10 s. Ex.: 0x2127 = 127 s.
+func GetTimerAt(bytes []byte, pos int) time.Duration {
+	if pos+2 > len(bytes) {
+		return 0
+	}
+	w := binary.BigEndian.Uint16(bytes[pos : pos+2])
+	return time.Duration(decodeBCD3(w)) * s5TimeBases[w>>12&0x03]
+}
+
+// SetTimerAt converte uma duração para S5TIME usando a menor base de tempo em
+// que ela cabe, truncando a resolução. Valores fora de 0..MaxTimerValue são
+// limitados aos extremos.
+func SetTimerAt(bytes []byte, pos int, value time.Duration) {
+	if pos+2 > len(bytes) {
+		return
+	}
+	if value < 0 {
+		value = 0
+	}
+	if value > MaxTimerValue {
+		value = MaxTimerValue
+	}
+
+	base := 0
+	for base < len(s5TimeBases)-1 && value/s5TimeBases[base] > 999 {
+		base++
+	}
+	units := int(value / s5TimeBases[base])
+	binary.BigEndian.PutUint16(bytes[pos:pos+2], uint16(base)<<12|encodeBCD3(units))
+}
+
+// GetCounterAt converte um S7 COUNTER (2 bytes, valor BCD de 3 dígitos nos
+// bits 0-11) para inteiro. Ex.: 0x0127 = 127.
+func GetCounterAt(bytes []byte, pos int) int {
+	if pos+2 > len(bytes) {
+		return 0
+	}
+	return decodeBCD3(binary.BigEndian.Uint16(bytes[pos : pos+2]))
+}
+
+// SetCounterAt converte um inteiro para S7 COUNTER. Valores fora de
+// 0..MaxCounterValue são limitados aos extremos.
+func SetCounterAt(bytes []byte, pos int, value int) {
+	if pos+2 > len(bytes) {
+		return
+	}
+	if value < 0 {
+		value = 0
+	}
+	if value > MaxCounterValue {
+		value = MaxCounterValue
+	}
+	binary.BigEndian.PutUint16(bytes[pos:pos+2], encodeBCD3(value))
+}
+
+// DecodeBCDByte converte um byte BCD (dois dígitos) para inteiro: 0x45 -> 45
+func DecodeBCDByte(b byte) int {
+	return int(b>>4)*10 + int(b&0x0F)
+}
+
+// EncodeBCDByte converte um inteiro de 0 a 99 para um byte BCD: 45 -> 0x45
+func EncodeBCDByte(v int) byte {
+	return byte((v/10)%10<<4 | v%10)
+}
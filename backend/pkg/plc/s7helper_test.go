@@ -0,0 +1,82 @@
+package plc
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestBCDByteRoundTrip(t *testing.T) {
+	for v := 0; v <= 99; v++ {
+		b := EncodeBCDByte(v)
+		if hi, lo := int(b>>4), int(b&0x0F); hi != v/10 || lo != v%10 {
+			t.Errorf("EncodeBCDByte(%d) = %#02x, esperado dígitos %d e %d", v, b, v/10, v%10)
+		}
+		if got := DecodeBCDByte(b); got != v {
+			t.Errorf("DecodeBCDByte(%#02x) = %d, esperado %d", b, got, v)
+		}
+	}
+}
+
+func TestBCDByteKnownValues(t *testing.T) {
+	tests := []struct {
+		value int
+		bcd   byte
+	}{
+		{0, 0x00},
+		{9, 0x09},
+		{10, 0x10},
+		{45, 0x45},
+		{99, 0x99},
+	}
+
+	for _, tt := range tests {
+		if got := EncodeBCDByte(tt.value); got != tt.bcd {
+			t.Errorf("EncodeBCDByte(%d) = %#02x, esperado %#02x", tt.value, got, tt.bcd)
+		}
+		if got := DecodeBCDByte(tt.bcd); got != tt.value {
+			t.Errorf("DecodeBCDByte(%#02x) = %d, esperado %d", tt.bcd, got, tt.value)
+		}
+	}
+}
+
+func TestGetDateAndTimeAtSpecVector(t *testing.T) {
+	// DT#2024-03-15-12:30:45.500; o dia da semana no último nibble é ignorado
+	// na leitura, então o vetor decodifica igual com qualquer valor nele
+	want := time.Date(2024, 3, 15, 12, 30, 45, 500*int(time.Millisecond), time.UTC)
+	for _, weekday := range []byte{0x04, 0x06} {
+		raw := []byte{0x24, 0x03, 0x15, 0x12, 0x30, 0x45, 0x50, weekday}
+		got := GetDateAndTimeAt(raw, 0)
+		if !got.Equal(want) || got.Location() != time.UTC {
+			t.Errorf("% X = %s, esperado %s", raw, got, want)
+		}
+	}
+
+	// Leitura em uma posição dentro de um buffer maior
+	buf := append([]byte{0xAA, 0xBB}, 0x98, 0x12, 0x31, 0x23, 0x59, 0x59, 0x99, 0x95)
+	if got, want := GetDateAndTimeAt(buf, 2), time.Date(1998, 12, 31, 23, 59, 59, 999*int(time.Millisecond), time.UTC); !got.Equal(want) {
+		t.Errorf("posição 2 = %s, esperado %s", got, want)
+	}
+}
+
+func TestSetDateAndTimeAtConvertsToUTC(t *testing.T) {
+	// 09:30:45.500 em UTC-3 é 12:30:45.500 UTC, sexta-feira (6)
+	local := time.Date(2024, 3, 15, 9, 30, 45, 500*int(time.Millisecond), time.FixedZone("BRT", -3*3600))
+	want := []byte{0x24, 0x03, 0x15, 0x12, 0x30, 0x45, 0x50, 0x06}
+
+	buf := make([]byte, 8)
+	SetDateAndTimeAt(buf, 0, local)
+	if !bytes.Equal(buf, want) {
+		t.Errorf("SetDateAndTimeAt = % X, esperado % X", buf, want)
+	}
+	if got := GetDateAndTimeAt(buf, 0); !got.Equal(local) {
+		t.Errorf("ida e volta = %s, esperado o mesmo instante de %s", got, local)
+	}
+
+	// A virada de dia em UTC muda data e dia da semana
+	lateNight := time.Date(2024, 3, 15, 22, 0, 0, 0, time.FixedZone("BRT", -3*3600))
+	SetDateAndTimeAt(buf, 0, lateNight)
+	if want := []byte{0x24, 0x03, 0x16, 0x01, 0x00, 0x00, 0x00, 0x07}; !bytes.Equal(buf, want) {
+		t.Errorf("SetDateAndTimeAt após meia-noite UTC = % X, esperado % X", buf, want)
+	}
+}
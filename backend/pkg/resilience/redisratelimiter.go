@@ -0,0 +1,118 @@
+// pkg/resilience/redisratelimiter.go
+package resilience
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// Limiter é implementado pelos limitadores de taxa
+type Limiter interface {
+	AllowOperation(key string) bool
+	ResetKey(key string)
+}
+
+var (
+	_ Limiter = (*RateLimiter)(nil)
+	_ Limiter = (*RedisRateLimiter)(nil)
+)
+
+// redisOperationTimeout é a espera máxima pelo Redis antes de usar o limite local
+const redisOperationTimeout = 500 * time.Millisecond
+
+// slidingWindowScript registra a operação em um sorted set com o horário do
+// Redis como score, descartando as que saíram da janela. Usar o relógio do
+// Redis evita diferenças de horário entre instâncias.
+// ARGV: janela (µs), limite, membro único. Retorna 1 se permitida.
+var slidingWindowScript = redis.NewScript(`
+local t = redis.call("TIME")
+local now = tonumber(t[1]) * 1000000 + tonumber(t[2])
+local window = tonumber(ARGV[1])
+local limit = tonumber(ARGV[2])
+
+redis.call("ZREMRANGEBYSCORE", KEYS[1], "-inf", now - window)
+if redis.call("ZCARD", KEYS[1]) >= limit then
+	return 0
+end
+
+redis.call("ZADD", KEYS[1], now, ARGV[3])
+redis.call("PEXPIRE", KEYS[1], math.ceil(window / 1000))
+return 1
+`)
+
+// RedisRateLimiter limita operações por tempo com uma janela deslizante no
+// Redis, compartilhada entre todas as instâncias da aplicação. Se o Redis
+// falhar, usa um limitador local com os mesmos parâmetros.
+type RedisRateLimiter struct {
+	client   *redis.Client
+	key      string // Prefixo das chaves no Redis
+	limit    int
+	window   time.Duration
+	instance string
+	seq      uint64
+	fallback *RateLimiter
+}
+
+// NewRedisRateLimiter cria um limitador de limit operações por window. As
+// chaves ficam em key + ":" + chave da operação.
+func NewRedisRateLimiter(client *redis.Client, key string, limit int, window time.Duration) *RedisRateLimiter {
+	suffix := make([]byte, 4)
+	rand.Read(suffix)
+
+	return &RedisRateLimiter{
+		client:   client,
+		key:      key,
+		limit:    limit,
+		window:   window,
+		instance: hex.EncodeToString(suffix),
+		fallback: NewRateLimiter(limit, window),
+	}
+}
+
+// redisKey retorna a chave do Redis para a operação
+func (rl *RedisRateLimiter) redisKey(key string) string {
+	return rl.key + ":" + key
+}
+
+// AllowOperation verifica se uma operação é permitida
+func (rl *RedisRateLimiter) AllowOperation(key string) bool {
+	if rl.client == nil {
+		return rl.fallback.AllowOperation(key)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), redisOperationTimeout)
+	defer cancel()
+
+	member := fmt.Sprintf("%s:%d", rl.instance, atomic.AddUint64(&rl.seq, 1))
+	allowed, err := slidingWindowScript.Run(ctx, rl.client, []string{rl.redisKey(key)},
+		rl.window.Microseconds(), rl.limit, member).Int()
+	if err != nil {
+		log.Printf("Aviso: erro no limitador de taxa do Redis, usando limite local: %v", err)
+		return rl.fallback.AllowOperation(key)
+	}
+
+	return allowed == 1
+}
+
+// ResetKey limpa o contador para uma chave
+func (rl *RedisRateLimiter) ResetKey(key string) {
+	rl.fallback.ResetKey(key)
+
+	if rl.client == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), redisOperationTimeout)
+	defer cancel()
+
+	if err := rl.client.Del(ctx, rl.redisKey(key)).Err(); err != nil {
+		log.Printf("Aviso: erro ao limpar limitador de taxa no Redis: %v", err)
+	}
+}
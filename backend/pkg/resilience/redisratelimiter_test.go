@@ -0,0 +1,116 @@
+package resilience
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+)
+
+func newTestRedisClient(t *testing.T) (*redis.Client, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	mr.SetTime(time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC))
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return client, mr
+}
+
+func TestRedisRateLimiterSharedAcrossInstances(t *testing.T) {
+	client, _ := newTestRedisClient(t)
+
+	const limit = 5
+	a := NewRedisRateLimiter(client, "test:ratelimit", limit, time.Minute)
+	b := NewRedisRateLimiter(client, "test:ratelimit", limit, time.Minute)
+
+	var allowed atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 40; i++ {
+		limiter := a
+		if i%2 == 1 {
+			limiter = b
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if limiter.AllowOperation("plc:1") {
+				allowed.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := allowed.Load(); got != limit {
+		t.Errorf("operações permitidas nas duas instâncias = %d, esperado %d", got, limit)
+	}
+
+	// Outra chave de operação tem contador próprio
+	if !a.AllowOperation("plc:2") || !b.AllowOperation("plc:2") {
+		t.Error("chave diferente não deveria ser afetada pelo limite de plc:1")
+	}
+}
+
+func TestRedisRateLimiterWindowSlides(t *testing.T) {
+	client, mr := newTestRedisClient(t)
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	a := NewRedisRateLimiter(client, "test:ratelimit", 2, time.Second)
+	b := NewRedisRateLimiter(client, "test:ratelimit", 2, time.Second)
+
+	if !a.AllowOperation("op") {
+		t.Fatal("primeira operação deveria ser permitida")
+	}
+	mr.SetTime(start.Add(600 * time.Millisecond))
+	if !b.AllowOperation("op") {
+		t.Fatal("segunda operação deveria ser permitida")
+	}
+	if a.AllowOperation("op") || b.AllowOperation("op") {
+		t.Fatal("terceira operação na janela deveria ser negada")
+	}
+
+	// A primeira operação sai da janela; a segunda ainda conta
+	mr.SetTime(start.Add(1100 * time.Millisecond))
+	if !a.AllowOperation("op") {
+		t.Error("operação deveria ser permitida após a primeira sair da janela")
+	}
+	if b.AllowOperation("op") {
+		t.Error("limite deveria voltar a valer com duas operações na janela")
+	}
+}
+
+func TestRedisRateLimiterResetKeyIsShared(t *testing.T) {
+	client, _ := newTestRedisClient(t)
+
+	a := NewRedisRateLimiter(client, "test:ratelimit", 1, time.Minute)
+	b := NewRedisRateLimiter(client, "test:ratelimit", 1, time.Minute)
+
+	if !a.AllowOperation("op") || b.AllowOperation("op") {
+		t.Fatal("limite de 1 operação não respeitado")
+	}
+
+	a.ResetKey("op")
+	if !b.AllowOperation("op") {
+		t.Error("ResetKey em uma instância deveria liberar a outra")
+	}
+}
+
+func TestRedisRateLimiterFallsBackWhenRedisFails(t *testing.T) {
+	client, mr := newTestRedisClient(t)
+	limiter := NewRedisRateLimiter(client, "test:ratelimit", 2, time.Minute)
+
+	mr.Close()
+
+	allowed := 0
+	for i := 0; i < 5; i++ {
+		if limiter.AllowOperation("op") {
+			allowed++
+		}
+	}
+	if allowed != 2 {
+		t.Errorf("operações permitidas com o limite local = %d, esperado 2", allowed)
+	}
+}
@@ -215,6 +215,7 @@ const CreatePLCTag = () => {
       case 'int': return 'Números inteiros com sinal';
       case 'word': return 'Números inteiros sem sinal';
       case 'bool': return 'Valor lógico (verdadeiro/falso)';
+      case 'char': return 'Um caractere ASCII';
       case 'string': return 'Texto';
       default: return '';
     }
@@ -323,6 +324,7 @@ const CreatePLCTag = () => {
                 <Picker.Item label="Real (Float)" value="real" />
                 <Picker.Item label="Int (Inteiro)" value="int" />
                 <Picker.Item label="Word (Sem sinal)" value="word" />
+                <Picker.Item label="Char (Caractere)" value="char" />
                 <Picker.Item label="String (Texto)" value="string" />
               </Picker>
             </View>
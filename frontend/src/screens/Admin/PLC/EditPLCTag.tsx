@@ -419,7 +419,7 @@ const EditPLCTag = () => {
                   backgroundColor: isDarkMode ? theme.surfaceVariant : '#f5f5f5',
                 }
               ]}>
-                {['bool', 'real', 'int', 'word', 'string'].map(type => (
+                {['bool', 'real', 'int', 'word', 'char', 'string'].map(type => (
                   <TouchableOpacity
                     key={type}
                     style={[
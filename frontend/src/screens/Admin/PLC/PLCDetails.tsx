@@ -17,7 +17,7 @@ import { Feather } from '@expo/vector-icons';
 import { LinearGradient } from 'expo-linear-gradient';
 import { useTheme } from '../../../contexts/ThemeContext';
 import Button from '../../../components/Button';
-import plcApi, { PLC, PLCTag } from '../../../services/plcApi';
+import plcApi, { PLC, PLCTag, currentValue } from '../../../services/plcApi';
 
 const { width } = Dimensions.get('window');
 
@@ -504,8 +504,8 @@ const PLCDetails = () => {
                     
                     <View style={styles.tagValueContainer}>
                       <Text style={[styles.tagValue, { color: theme.primary }]}>
-                        {tag.current_value !== undefined ? 
-                          String(tag.current_value) : 
+                        {currentValue(tag) !== undefined ? 
+                          String(currentValue(tag)) : 
                           '-'
                         }
                       </Text>
@@ -21,7 +21,7 @@ import { Feather } from '@expo/vector-icons';
 import { useNavigation, useRoute } from '@react-navigation/native';
 import { useTheme } from '../../../contexts/ThemeContext';
 import Button from '../../../components/Button';
-import plcApi, { PLC, PLCTag } from '../../../services/plcApi';
+import plcApi, { PLC, PLCTag, currentValue } from '../../../services/plcApi';
 
 const { width } = Dimensions.get('window');
 
@@ -224,8 +224,8 @@ const PLCTagMonitor = () => {
         
         // If value has changed, mark it for animation and update history
         if (prevTag && 
-            JSON.stringify(prevTag.current_value) !== JSON.stringify(tag.current_value) && 
-            tag.current_value !== undefined) {
+            JSON.stringify(currentValue(prevTag)) !== JSON.stringify(currentValue(tag)) && 
+            currentValue(tag) !== undefined) {
           
           // Mark for animation
           changedValues.current[tag.id] = true;
@@ -256,7 +256,7 @@ const PLCTagMonitor = () => {
           
           // Only keep last 10 values
           const newHistory = {
-            values: [tag.current_value, ...history.values].slice(0, 10),
+            values: [currentValue(tag), ...history.values].slice(0, 10),
             timestamps: [new Date().toLocaleTimeString(), ...history.timestamps].slice(0, 10)
           };
           
@@ -405,7 +405,7 @@ const PLCTagMonitor = () => {
     
     // Apply value filter
     if (showOnlyWithValues) {
-      filtered = filtered.filter(tag => tag.current_value !== undefined && tag.current_value !== null);
+      filtered = filtered.filter(tag => currentValue(tag) !== undefined && currentValue(tag) !== null);
     }
     
     // Apply sorting
@@ -417,12 +417,12 @@ const PLCTagMonitor = () => {
           return a.data_type.localeCompare(b.data_type);
         case 'value':
           // For value sorting, handle undefined values and different types
-          if (a.current_value === undefined && b.current_value === undefined) return 0;
-          if (a.current_value === undefined) return 1;
-          if (b.current_value === undefined) return -1;
+          if (currentValue(a) === undefined && currentValue(b) === undefined) return 0;
+          if (currentValue(a) === undefined) return 1;
+          if (currentValue(b) === undefined) return -1;
           
           // Convert to string for comparison
-          return String(a.current_value).localeCompare(String(b.current_value));
+          return String(currentValue(a)).localeCompare(String(currentValue(b)));
       }
     });
     
@@ -452,7 +452,7 @@ const PLCTagMonitor = () => {
   };
 
   const formatTagValue = (tag: PLCTag) => {
-    if (tag.current_value === undefined || tag.current_value === null) {
+    if (currentValue(tag) === undefined || currentValue(tag) === null) {
       return '-';
     }
     
@@ -461,26 +461,26 @@ const PLCTagMonitor = () => {
       switch (tag.data_type.toLowerCase()) {
         case 'real':
           // Format floats with precision
-          const num = parseFloat(String(tag.current_value));
-          return isNaN(num) ? String(tag.current_value) : num.toFixed(2);
+          const num = parseFloat(String(currentValue(tag)));
+          return isNaN(num) ? String(currentValue(tag)) : num.toFixed(2);
           
         case 'bool':
           // Format booleans as TRUE/FALSE
-          if (typeof tag.current_value === 'boolean') {
-            return tag.current_value ? 'TRUE' : 'FALSE';
+          if (typeof currentValue(tag) === 'boolean') {
+            return currentValue(tag) ? 'TRUE' : 'FALSE';
           }
-          return String(tag.current_value);
+          return String(currentValue(tag));
           
         default:
-          return String(tag.current_value);
+          return String(currentValue(tag));
       }
     } catch (e) {
-      return String(tag.current_value);
+      return String(currentValue(tag));
     }
   };
 
   const getTagValueColor = (tag: PLCTag) => {
-    if (tag.current_value === undefined || tag.current_value === null) {
+    if (currentValue(tag) === undefined || currentValue(tag) === null) {
       return theme.textSecondary;
     }
     
@@ -490,8 +490,8 @@ const PLCTagMonitor = () => {
         return theme.primary;
       case 'bool':
         // Green for TRUE, red for FALSE
-        if (typeof tag.current_value === 'boolean') {
-          return tag.current_value ? '#4CAF50' : '#F44336';
+        if (typeof currentValue(tag) === 'boolean') {
+          return currentValue(tag) ? '#4CAF50' : '#F44336';
         }
         return theme.text;
       default:
@@ -728,7 +728,7 @@ const PLCTagMonitor = () => {
                 setSelectedTag(item);
                 if (item.can_write) {
                   setShowWriteModal(true);
-                  setNewValue(item.current_value !== undefined ? String(item.current_value) : '');
+                  setNewValue(currentValue(item) !== undefined ? String(currentValue(item)) : '');
                 } else {
                   // Show history if available
                   if (valueHistory[item.id] && valueHistory[item.id].values.length > 0) {
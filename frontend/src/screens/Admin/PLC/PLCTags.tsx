@@ -15,7 +15,7 @@ import { Feather } from '@expo/vector-icons';
 import { useNavigation, useRoute } from '@react-navigation/native';
 import { useTheme } from '../../../contexts/ThemeContext';
 import Button from '../../../components/Button';
-import plcApi, { PLCTag } from '../../../services/plcApi';
+import plcApi, { PLCTag, currentValue } from '../../../services/plcApi';
 import EmptyListSvg from '../../../components/EmptyListSvg';
 
 interface RouteParams {
@@ -183,13 +183,13 @@ const PLCTags = () => {
             )}
           </View>
           
-          {item.current_value !== undefined && (
+          {currentValue(item) !== undefined && (
             <View style={styles.tagValueContainer}>
               <Text style={[styles.tagValueLabel, { color: theme.textSecondary }]}>
                 Valor atual:
               </Text>
               <Text style={[styles.tagValue, { color: theme.primary }]}>
-                {String(item.current_value)}
+                {String(currentValue(item))}
               </Text>
             </View>
           )}
@@ -115,12 +115,19 @@ export default function Preferences() {
       
       if (response.data.profile) {
         const profile = response.data.profile;
+        // O backend retorna uma lista de canais; converter para o formato usado na tela
+        const channels: { channel: string; enabled: boolean }[] = Array.isArray(profile.notification_preferences)
+          ? profile.notification_preferences
+          : [];
+        const notifications: NotificationPreferences = channels.length > 0
+          ? {
+              email: channels.some(c => c.channel === 'email' && c.enabled),
+              push: channels.some(c => c.channel === 'push' && c.enabled),
+              sms: channels.some(c => c.channel === 'sms' && c.enabled),
+            }
+          : profile.notification_preferences || { email: true, push: true, sms: false };
         setPreferences({
-          notifications: profile.notification_preferences || {
-            email: true,
-            push: true,
-            sms: false,
-          },
+          notifications,
           language: profile.language || 'pt_BR',
           originalLanguage: profile.language || 'pt_BR', // Para rastrear mudanças
         });
@@ -28,9 +28,20 @@ export interface PLCTag {
   active: boolean;
   created_at: string;
   updated_at?: string;
-  current_value?: any;
+  current_value?: TagReading | null;
 }
 
+// Leitura atual de uma tag (PLCTag.current_value)
+export interface TagReading {
+  value: any;
+  raw_value?: any;  // valor antes da escala
+  quality: string;  // "good", "bad", "uncertain"
+  timestamp: string;
+}
+
+// currentValue retorna o valor lido da tag, ou undefined se não houver leitura
+export const currentValue = (tag: PLCTag): any => tag.current_value?.value;
+
 export interface TagValue {
   plc_id: number;
   tag_id: number;
@@ -82,10 +93,17 @@ export interface DiagnosticResult {
 
 export const plcApi = {
   // PLCs
+  // GET /api/plc/ é paginado; percorre todas as páginas
   getAllPLCs: async (): Promise<PLC[]> => {
     try {
-      const response = await api.get('/api/plc/');
-      return response.data.plcs || [];
+      const plcs: PLC[] = [];
+      for (let page = 1; ; page++) {
+        const response = await api.get('/api/plc/', { params: { page, pageSize: 100 } });
+        plcs.push(...(response.data.plcs || []));
+        if (!response.data.has_next) {
+          return plcs;
+        }
+      }
     } catch (error) {
       console.error('Erro ao buscar PLCs:', error);
       throw error;